@@ -5,6 +5,8 @@ import (
 	"log"
 
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/database"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/scheduler"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/ui/mcp"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/ui/rest/helpers"
 	"github.com/mark3labs/mcp-go/server"
@@ -30,6 +32,9 @@ func mcpServer(_ *cobra.Command, _ []string) {
 	go helpers.SetAutoConnectAfterBooting(appUsecase)
 	// Set auto reconnect checking
 	go helpers.SetAutoReconnectChecking(whatsappCli)
+	// Run the background jobs registered during initialization
+	scheduler.Default().SetLocker(scheduler.NewClusterLocker(database.GetDB()))
+	scheduler.Default().Start()
 
 	// Create MCP server with capabilities
 	mcpServer := server.NewMCPServer(
@@ -1,15 +1,22 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/database"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/broadcast"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/cluster"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/scheduler"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/tracing"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/ui/rest"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/ui/rest/helpers"
@@ -38,6 +45,19 @@ func init() {
 	rootCmd.AddCommand(restCmd)
 }
 func restServer(_ *cobra.Command, _ []string) {
+	tracing.Configure(config.OtelExporterEndpoint, config.OtelServiceName, tracing.ParseHeaders(config.OtelExporterHeaders))
+	
+	// Join the cluster before devices load so this replica only takes its share
+	var clusterNode *cluster.Cluster
+	if config.ClusterEnabled {
+		var err error
+		clusterNode, err = cluster.Start(database.GetDB())
+		if err != nil {
+			log.Fatalln("Failed to join cluster: ", err.Error())
+		}
+		clusterNode.OnChange(whatsapp.RebalanceDevices)
+	}
+	
 	engine := html.NewFileSystem(http.FS(EmbedViews), ".html")
 	engine.AddFunc("isEnableBasicAuth", func(token any) bool {
 		return token != nil
@@ -64,10 +84,10 @@ func restServer(_ *cobra.Command, _ []string) {
 	}))
 
 	app.Use(middleware.Recovery())
+	app.Use(middleware.Idempotency()) // Replays retried writes sent with an Idempotency-Key
 	
 	// IMPORTANT: Register public routes BEFORE auth middleware
-	rest.InitPublicDeviceRoutes(app, database.GetDB()) // Add public device view routes
-	rest.InitPublicDeviceAPI(app) // Add public device API endpoints
+	rest.InitPublicRoutes(app) // Public device views and API, Prometheus scrape endpoint
 	
 	// Now apply auth middleware - it won't affect routes registered above
 	app.Use(middleware.BasicAuth())
@@ -99,19 +119,15 @@ func restServer(_ *cobra.Command, _ []string) {
 	*/
 
 	// Rest
-	appRest := rest.InitRestApp(app, appUsecase)
-	appRest.SetSendService(sendUsecase) // Set send service for WhatsApp Web
-	rest.InitRestSend(app, sendUsecase)
-	rest.InitRestUser(app, userUsecase)
-	rest.InitRestMessage(app, messageUsecase)
-	rest.InitRestGroup(app, groupUsecase)
-	rest.InitRestNewsletter(app, newsletterUsecase)
-	rest.InitRestSequence(app, sequenceUsecase)
-	rest.InitRestMonitoring(app) // Add monitoring endpoints
-	rest.InitWorkerControlAPI(app) // Add worker control endpoints
-	rest.InitTeamRoutes(app, database.GetDB()) // Add team member routes
-	rest.InitRedisCleanupAPI(app) // Add Redis cleanup endpoints
-	rest.InitWebhookLead(app) // Add webhook endpoint for creating leads
+	rest.InitRoutes(app, rest.Services{
+		App:        appUsecase,
+		Send:       sendUsecase,
+		User:       userUsecase,
+		Message:    messageUsecase,
+		Group:      groupUsecase,
+		Newsletter: newsletterUsecase,
+		Sequence:   sequenceUsecase,
+	})
 
 	app.Get("/", func(c *fiber.Ctx) error {
 		return c.Render("views/index", fiber.Map{
@@ -161,33 +177,43 @@ func restServer(_ *cobra.Command, _ []string) {
 	// Start the ultra-optimized broadcast processor for 3000+ devices
 	// This processor creates broadcast-specific worker pools
 	logrus.Info("🚀 Starting UltraOptimizedBroadcastProcessor...")
-	go usecase.StartUltraOptimizedBroadcastProcessor()
+	usecase.StartUltraOptimizedBroadcastProcessor()
+	// Resume what the last run of this replica handed back before any
+	// message is claimed
+	nodeID := cluster.NodeID()
+	resumeFromLastShutdown(nodeID)
+	
 	logrus.Info("✅ Ultra-optimized broadcast processor started (3000+ device support)")
 	
 	// Start campaign trigger processor using optimized version
-	go func() {
-		db := database.GetDB()
-		campaignTrigger := usecase.NewOptimizedCampaignTrigger(db)
-		ticker := time.NewTicker(1 * time.Minute)
-		defer ticker.Stop()
-		
-		logrus.Info("Campaign trigger processor started (checks every minute)")
-		
-		// Process immediately on start
-		campaignTrigger.ProcessCampaigns()
-		
-		// Then process every minute
-		for range ticker.C {
-			campaignTrigger.ProcessCampaigns()
-		}
-	}()
+	campaignTrigger := usecase.NewOptimizedCampaignTrigger(database.GetDB())
+	scheduler.Default().MustRegister(scheduler.Job{
+		Name:       "campaign-trigger",
+		Interval:   time.Minute,
+		Singleton:  true,
+		RunOnStart: true,
+		Run: func(ctx context.Context) error {
+			return campaignTrigger.ProcessCampaigns()
+		},
+	})
+	logrus.Info("Campaign trigger processor started (checks every minute)")
 	
 	// Start sequence trigger processor - NOW HANDLES BOTH SEQUENCES AND CAMPAIGNS
-	go usecase.StartSequenceTriggerProcessor()
+	usecase.StartSequenceTriggerProcessor()
 	logrus.Info("Unified processor started (handles both sequences and campaigns)")
 	
+	// Queue each sequence step only after the previous one is sent
+	usecase.StartSequenceStepMaterializer()
+	usecase.StartSequenceFlowEngine()
+	
+	// Record failed messages in the dead-letter store
+	usecase.StartDeadLetterSync()
+	
+	// Check new leads' numbers with WhatsApp before they are targeted
+	usecase.StartNumberValidation()
+	
 	// Start campaign status monitor
-	go usecase.StartCampaignStatusMonitor()
+	usecase.StartCampaignStatusMonitor()
 	logrus.Info("Campaign status monitor started")
 	
 	// DISABLED: Queued message cleaner - causing deadlocks with broadcast processor
@@ -195,20 +221,44 @@ func restServer(_ *cobra.Command, _ []string) {
 	// logrus.Info("Queued message cleaner started")
 	
 	// Start broadcast coordinator
-	go usecase.StartBroadcastCoordinator()
+	usecase.StartBroadcastCoordinator()
 	logrus.Info("Broadcast coordinator started")
 	
 	// Start broadcast worker processor - CRITICAL FOR WORKER POOL
-	go usecase.StartBroadcastWorkerProcessor()
+	usecase.StartBroadcastWorkerProcessor()
 	
 	// Start cleanup worker for stuck messages
-	go repository.StartCleanupWorker()
+	repository.StartCleanupWorker()
+	repository.StartIdempotencyKeyCleanup()
 	logrus.Info("Broadcast worker processor started - using Worker Pool System")
 	
 	// Start campaign completion checker
-	go usecase.StartCampaignCompletionChecker()
+	usecase.StartCampaignCompletionChecker()
 	logrus.Info("Campaign completion checker started")
 	
+	// Run the registered background jobs; singleton jobs run on the cluster
+	// leader, or lock across replicas outside cluster mode
+	if clusterNode != nil {
+		scheduler.Default().SetLocker(clusterNode.Locker())
+	} else {
+		scheduler.Default().SetLocker(scheduler.NewClusterLocker(database.GetDB()))
+	}
+	scheduler.Default().Start()
+	
+	// Stop gracefully on SIGINT or SIGTERM: finish or hand back the sends in
+	// progress, disconnect devices without logging out and record the marker
+	shutdownDone := make(chan struct{})
+	go func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		<-signals
+		shutdownGracefully(app, clusterNode, nodeID)
+		close(shutdownDone)
+	}()
+	
+	// Apply safe config changes on SIGHUP without disconnecting devices
+	config.WatchReload()
+	
 	// Auto-reconnect devices on startup - DISABLED
 	// Using MonitorDeviceErrors instead for continuous monitoring
 	/*
@@ -222,4 +272,5 @@ func restServer(_ *cobra.Command, _ []string) {
 	if err := app.Listen(":" + config.AppPort); err != nil {
 		log.Fatalln("Failed to start: ", err.Error())
 	}
+	<-shutdownDone
 }
\ No newline at end of file
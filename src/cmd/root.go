@@ -4,7 +4,6 @@ import (
 	"context"
 	"embed"
 	"os"
-	"strings"
 	"time"
 
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
@@ -32,6 +31,9 @@ var (
 	EmbedIndex embed.FS
 	EmbedViews embed.FS
 
+	// configFile is the YAML or TOML file the configuration is loaded from
+	configFile string
+
 	// Whatsapp
 	whatsappCli *whatsmeow.Client
 	whatsappDB  *sqlstore.Container
@@ -68,58 +70,29 @@ func init() {
 	cobra.OnInitialize(initEnvConfig, initApp)
 }
 
-// initEnvConfig loads configuration from environment variables
+// initEnvConfig loads the config file, when one is given with --config or
+// CONFIG_FILE, and the environment on top of the defaults and flags. An
+// invalid configuration stops the app before anything starts.
 func initEnvConfig() {
-	// Application settings
-	// Railway provides PORT env var, so check it first
-	if envPort := viper.GetString("PORT"); envPort != "" {
-		config.AppPort = envPort
-	} else if envPort := viper.GetString("APP_PORT"); envPort != "" {
-		config.AppPort = envPort
-	}
-	if envDebug := viper.GetBool("APP_DEBUG"); envDebug {
-		config.AppDebug = envDebug
-	}
-	if envOs := viper.GetString("APP_OS"); envOs != "" {
-		config.AppOs = envOs
-	}
-	if envBasicAuth := viper.GetString("APP_BASIC_AUTH"); envBasicAuth != "" {
-		credential := strings.Split(envBasicAuth, ",")
-		config.AppBasicAuthCredential = credential
-	}
-	if envChatFlushInterval := viper.GetInt("APP_CHAT_FLUSH_INTERVAL"); envChatFlushInterval > 0 {
-		config.AppChatFlushIntervalDays = envChatFlushInterval
-	}
-
-	// Database settings
-	// DB_URI is used for WhatsApp (must be PostgreSQL or SQLite)
-	if envDBURI := viper.GetString("DB_URI"); envDBURI != "" {
-		config.DBURI = envDBURI
-	}
-	
-	// MYSQL_URI is set separately for application data
-	// This is handled in database/connection.go
-
-	// WhatsApp settings
-	if envAutoReply := viper.GetString("WHATSAPP_AUTO_REPLY"); envAutoReply != "" {
-		config.WhatsappAutoReplyMessage = envAutoReply
-	}
-	if envWebhook := viper.GetString("WHATSAPP_WEBHOOK"); envWebhook != "" {
-		webhook := strings.Split(envWebhook, ",")
-		config.WhatsappWebhook = webhook
-	}
-	if envWebhookSecret := viper.GetString("WHATSAPP_WEBHOOK_SECRET"); envWebhookSecret != "" {
-		config.WhatsappWebhookSecret = envWebhookSecret
+	if configFile == "" {
+		configFile = viper.GetString("CONFIG_FILE")
 	}
-	if envAccountValidation := viper.GetBool("WHATSAPP_ACCOUNT_VALIDATION"); envAccountValidation {
-		config.WhatsappAccountValidation = envAccountValidation
+	if err := config.Init(configFile); err != nil {
+		logrus.Fatalln(err)
 	}
-	if envChatStorage := viper.GetBool("WHATSAPP_CHAT_STORAGE"); envChatStorage {
-		config.WhatsappChatStorage = true
+	if configFile != "" {
+		logrus.Infof("Loaded configuration from %s", configFile)
 	}
 }
 
 func initFlags() {
+	rootCmd.PersistentFlags().StringVarP(
+		&configFile,
+		"config", "c",
+		"",
+		`YAML or TOML config file, overridden by environment variables --config <path> | example: --config=config.yaml`,
+	)
+
 	// Application flags
 	rootCmd.PersistentFlags().StringVarP(
 		&config.AppPort,
@@ -195,9 +168,6 @@ func initFlags() {
 }
 
 func initApp() {
-	if config.AppDebug {
-		config.WhatsappLogLevel = "DEBUG"
-	}
 	//preparing folder if not exist
 	err := utils.CreateFolder(config.PathQrCode, config.PathSendItems, config.PathStorages, config.PathMedia)
 	if err != nil {
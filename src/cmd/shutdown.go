@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainBroadcast "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/broadcast"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/broadcast"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/cluster"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/scheduler"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/models"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/tracing"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// resumeFromLastShutdown reads what the previous run of this replica left
+// behind, returns anything it could not hand back itself, and marks this
+// run as started
+func resumeFromLastShutdown(nodeID string) {
+	markers := repository.GetShutdownMarkerRepository()
+	previous, err := markers.Get(nodeID)
+	switch {
+	case err != nil:
+		logrus.Warnf("Failed to read the shutdown marker of %s: %v", nodeID, err)
+	case previous == nil:
+		logrus.Infof("No previous run of %s recorded", nodeID)
+	case !previous.Clean():
+		logrus.Warnf("The run of %s started at %s did not shut down cleanly; messages it held are reset by the stuck message cleanup",
+			nodeID, previous.StartedAt.Format(time.RFC3339))
+	default:
+		// The requeue is repeated in case it failed at shutdown; rows that
+		// moved on since are left alone
+		resumed, err := repository.GetBroadcastRepository().RequeueMessages(previous.Requeued)
+		if err != nil {
+			logrus.Warnf("Failed to requeue the messages handed back by the last run: %v", err)
+		}
+		logrus.Infof("The last run of %s stopped cleanly at %s: %d messages handed back (%d still needed requeueing), %d interrupted sends retried, %d devices to reconnect",
+			nodeID, previous.StoppedAt.Format(time.RFC3339), len(previous.Requeued), resumed, len(previous.Interrupted), len(previous.Devices))
+	}
+
+	if err := markers.MarkRunning(nodeID, time.Now()); err != nil {
+		logrus.Warnf("Failed to record the start of %s: %v", nodeID, err)
+	}
+}
+
+// shutdownGracefully stops this replica within SHUTDOWN_TIMEOUT_SECONDS:
+// it stops taking new work, lets sends in progress finish, hands unsent
+// messages back to their queue, closes device connections without logging
+// out, flushes traces and records the shutdown marker
+func shutdownGracefully(app *fiber.App, clusterNode *cluster.Cluster, nodeID string) {
+	timeout := time.Duration(config.Current().App.ShutdownTimeout) * time.Second
+	logrus.Infof("Shutting down %s, waiting up to %v for sends in progress", nodeID, timeout)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// Stop serving requests and claiming messages. Open connections may hold
+	// the HTTP server until the deadline, so the drain does not wait for it;
+	// a job run that ignores its context is left behind at the deadline.
+	httpStopped := make(chan error, 1)
+	go func() {
+		httpStopped <- app.ShutdownWithContext(ctx)
+	}()
+	jobsStopped := make(chan struct{})
+	go func() {
+		scheduler.Default().Stop()
+		close(jobsStopped)
+	}()
+	select {
+	case <-jobsStopped:
+	case <-ctx.Done():
+		logrus.Warn("Background jobs still running at the shutdown deadline")
+	}
+
+	drained := broadcast.Drain(ctx)
+	broadcastRepo := repository.GetBroadcastRepository()
+	requeued, err := broadcastRepo.RequeueMessages(drained.Unsent)
+	if err != nil {
+		logrus.Errorf("Failed to requeue %d unsent messages: %v", len(drained.Unsent), err)
+	}
+	// Whether an interrupted send went out is unknown, so it is retried as a
+	// failed send would be
+	for _, messageID := range drained.Interrupted {
+		sendErr := pkgError.NewSendError(pkgError.SendErrNetwork, nil, "send interrupted by shutdown")
+		if _, err := broadcastRepo.RetryOrFailMessage(messageID, sendErr, domainBroadcast.DeadLetterSourceSQL); err != nil {
+			logrus.Errorf("Failed to retry interrupted message %s: %v", messageID, err)
+		}
+	}
+	logrus.Infof("Broadcast drained: %d unsent messages requeued, %d interrupted sends retried", requeued, len(drained.Interrupted))
+
+	select {
+	case err := <-httpStopped:
+		if err != nil {
+			logrus.Warnf("Failed to stop the HTTP server: %v", err)
+		}
+	case <-ctx.Done():
+	}
+
+	devices := whatsapp.DisconnectAll()
+	if clusterNode != nil {
+		leaveCtx, leaveCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if err := clusterNode.Leave(leaveCtx); err != nil {
+			logrus.Errorf("Failed to leave cluster: %v", err)
+		}
+		leaveCancel()
+	}
+
+	// Pending spans are exported even when the deadline has passed
+	flushCtx, flushCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	tracing.Shutdown(flushCtx)
+	flushCancel()
+
+	stoppedAt := time.Now()
+	marker := models.ShutdownMarker{
+		NodeID:      nodeID,
+		StoppedAt:   &stoppedAt,
+		Requeued:    drained.Unsent,
+		Interrupted: drained.Interrupted,
+		Devices:     devices,
+	}
+	if err := repository.GetShutdownMarkerRepository().MarkStopped(marker); err != nil {
+		logrus.Errorf("Failed to record the shutdown of %s: %v", nodeID, err)
+		return
+	}
+	logrus.Infof("%s shut down cleanly", nodeID)
+}
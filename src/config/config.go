@@ -0,0 +1,566 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// Config is the whole configuration as one typed value. It is built from
+// the defaults and command-line flags, then a YAML or TOML file, then the
+// environment, and validated before anything starts. The package variables
+// remain what the rest of the code reads; Set writes a Config into them.
+//
+// Fields tagged reload are safe to change while devices are connected and
+// are applied by Reload; the others take effect on the next restart. Code
+// that runs while a reload may happen reads reloadable fields through
+// Current or an accessor rather than the package variables. Fields
+// tagged secret are redacted wherever the configuration is shown. The env
+// tag names the environment variables of a field, the first one set wins.
+type Config struct {
+	App              AppSection              `mapstructure:"app"`
+	Database         DatabaseSection         `mapstructure:"database"`
+	Whatsapp         WhatsappSection         `mapstructure:"whatsapp"`
+	Redis            RedisSection            `mapstructure:"redis"`
+	Planner          PlannerSection          `mapstructure:"planner"`
+	Media            MediaSection            `mapstructure:"media"`
+	InboundMedia     InboundMediaSection     `mapstructure:"inbound_media"`
+	Observability    ObservabilitySection    `mapstructure:"observability"`
+	ShareLinks       ShareLinksSection       `mapstructure:"share_links"`
+	Cluster          ClusterSection          `mapstructure:"cluster"`
+	NumberValidation NumberValidationSection `mapstructure:"number_validation"`
+	Worker           WorkerSection           `mapstructure:"worker"`
+	Stability        StabilitySection        `mapstructure:"stability"`
+}
+
+// The sections of Config follow, each named after its section of the file
+
+type AppSection struct {
+	Port                  string   `mapstructure:"port" env:"PORT,APP_PORT"`
+	Debug                 bool     `mapstructure:"debug" env:"APP_DEBUG"`
+	Os                    string   `mapstructure:"os" env:"APP_OS"`
+	BasicAuth             []string `mapstructure:"basic_auth" env:"APP_BASIC_AUTH" secret:"true"`
+	ChatFlushIntervalDays int      `mapstructure:"chat_flush_interval_days" env:"APP_CHAT_FLUSH_INTERVAL" min:"1"`
+	LogLevel              string   `mapstructure:"log_level" env:"LOG_LEVEL" reload:"true"`
+	ShutdownTimeout       int      `mapstructure:"shutdown_timeout_seconds" env:"SHUTDOWN_TIMEOUT_SECONDS" reload:"true" min:"1"`
+}
+
+type DatabaseSection struct {
+	URI string `mapstructure:"uri" env:"DB_URI" secret:"true"`
+}
+
+type WhatsappSection struct {
+	AutoReply         string   `mapstructure:"auto_reply" env:"WHATSAPP_AUTO_REPLY"`
+	Webhook           []string `mapstructure:"webhook" env:"WHATSAPP_WEBHOOK" reload:"true"`
+	WebhookSecret     string   `mapstructure:"webhook_secret" env:"WHATSAPP_WEBHOOK_SECRET" secret:"true" reload:"true"`
+	LogLevel          string   `mapstructure:"log_level" env:"WHATSAPP_LOG_LEVEL"`
+	AccountValidation bool     `mapstructure:"account_validation" env:"WHATSAPP_ACCOUNT_VALIDATION"`
+	ChatStorage       bool     `mapstructure:"chat_storage" env:"WHATSAPP_CHAT_STORAGE"`
+}
+
+type RedisSection struct {
+	URL      string `mapstructure:"url" env:"REDIS_URL" secret:"true"`
+	Password string `mapstructure:"password" env:"REDIS_PASSWORD" secret:"true"`
+	Host     string `mapstructure:"host" env:"REDIS_HOST,REDISHOST"`
+	Port     string `mapstructure:"port" env:"REDIS_PORT,REDISPORT"`
+}
+
+type PlannerSection struct {
+	Timezone         string `mapstructure:"timezone" env:"PLANNER_TIMEZONE"`
+	SendWindowStart  string `mapstructure:"send_window_start" env:"PLANNER_SEND_WINDOW_START"`
+	SendWindowEnd    string `mapstructure:"send_window_end" env:"PLANNER_SEND_WINDOW_END"`
+	DailyDeviceQuota int    `mapstructure:"daily_device_quota" env:"PLANNER_DAILY_DEVICE_QUOTA" reload:"true" min:"1"`
+	HorizonDays      int    `mapstructure:"horizon_days" env:"PLANNER_HORIZON_DAYS" reload:"true" min:"1"`
+}
+
+type MediaSection struct {
+	StorageDriver       string `mapstructure:"storage_driver" env:"MEDIA_STORAGE_DRIVER"`
+	StoragePath         string `mapstructure:"storage_path" env:"MEDIA_STORAGE_PATH"`
+	S3Endpoint          string `mapstructure:"s3_endpoint" env:"MEDIA_S3_ENDPOINT"`
+	S3Bucket            string `mapstructure:"s3_bucket" env:"MEDIA_S3_BUCKET"`
+	S3Region            string `mapstructure:"s3_region" env:"MEDIA_S3_REGION"`
+	S3AccessKey         string `mapstructure:"s3_access_key" env:"MEDIA_S3_ACCESS_KEY" secret:"true"`
+	S3SecretKey         string `mapstructure:"s3_secret_key" env:"MEDIA_S3_SECRET_KEY" secret:"true"`
+	FetchTimeoutSeconds int    `mapstructure:"fetch_timeout_seconds" env:"MEDIA_FETCH_TIMEOUT" reload:"true" min:"1"`
+	FetchMaxSize        int64  `mapstructure:"fetch_max_size" env:"MEDIA_FETCH_MAX_SIZE" reload:"true" min:"1"`
+	SourceCacheMinutes  int    `mapstructure:"source_cache_minutes" env:"MEDIA_SOURCE_CACHE_MINUTES" reload:"true" min:"1"`
+	UploadCacheHours    int    `mapstructure:"upload_cache_hours" env:"MEDIA_UPLOAD_CACHE_HOURS" reload:"true" min:"1"`
+}
+
+type InboundMediaSection struct {
+	Enabled         bool  `mapstructure:"enabled" env:"INBOUND_MEDIA_ENABLED"`
+	Workers         int   `mapstructure:"workers" env:"INBOUND_MEDIA_WORKERS" min:"1"`
+	MaxImageSize    int64 `mapstructure:"max_image_size" env:"INBOUND_MEDIA_MAX_IMAGE_SIZE" reload:"true" min:"1"`
+	MaxVideoSize    int64 `mapstructure:"max_video_size" env:"INBOUND_MEDIA_MAX_VIDEO_SIZE" reload:"true" min:"1"`
+	MaxAudioSize    int64 `mapstructure:"max_audio_size" env:"INBOUND_MEDIA_MAX_AUDIO_SIZE" reload:"true" min:"1"`
+	MaxDocumentSize int64 `mapstructure:"max_document_size" env:"INBOUND_MEDIA_MAX_DOCUMENT_SIZE" reload:"true" min:"1"`
+	MaxStickerSize  int64 `mapstructure:"max_sticker_size" env:"INBOUND_MEDIA_MAX_STICKER_SIZE" reload:"true" min:"1"`
+}
+
+type ObservabilitySection struct {
+	MetricsToken    string `mapstructure:"metrics_token" env:"METRICS_TOKEN" secret:"true"`
+	OtelEndpoint    string `mapstructure:"otel_endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+	OtelServiceName string `mapstructure:"otel_service_name" env:"OTEL_SERVICE_NAME"`
+	OtelHeaders     string `mapstructure:"otel_headers" env:"OTEL_EXPORTER_OTLP_HEADERS" secret:"true"`
+}
+
+type ShareLinksSection struct {
+	Secret        string `mapstructure:"secret" env:"SHARE_LINK_SECRET" secret:"true"`
+	MaxExpiryDays int    `mapstructure:"max_expiry_days" env:"SHARE_LINK_MAX_EXPIRY_DAYS" reload:"true" min:"1"`
+}
+
+type ClusterSection struct {
+	Enabled          bool   `mapstructure:"enabled" env:"CLUSTER_ENABLED"`
+	NodeID           string `mapstructure:"node_id" env:"CLUSTER_NODE_ID"`
+	AdvertiseURL     string `mapstructure:"advertise_url" env:"CLUSTER_ADVERTISE_URL"`
+	Secret           string `mapstructure:"secret" env:"CLUSTER_SECRET" secret:"true"`
+	HeartbeatSeconds int    `mapstructure:"heartbeat_seconds" env:"CLUSTER_HEARTBEAT_SECONDS" min:"1"`
+	NodeTTLSeconds   int    `mapstructure:"node_ttl_seconds" env:"CLUSTER_NODE_TTL_SECONDS" min:"1"`
+}
+
+type NumberValidationSection struct {
+	Enabled            bool   `mapstructure:"enabled" env:"NUMBER_VALIDATION_ENABLED"`
+	BatchSize          int    `mapstructure:"batch_size" env:"NUMBER_VALIDATION_BATCH_SIZE" reload:"true" min:"1"`
+	ValidTTLDays       int    `mapstructure:"valid_ttl_days" env:"NUMBER_VALIDATION_VALID_TTL_DAYS" reload:"true" min:"1"`
+	InvalidTTLDays     int    `mapstructure:"invalid_ttl_days" env:"NUMBER_VALIDATION_INVALID_TTL_DAYS" reload:"true" min:"1"`
+	DefaultCountryCode string `mapstructure:"default_country_code" env:"NUMBER_DEFAULT_COUNTRY_CODE"`
+}
+
+type WorkerSection struct {
+	MaxWorkersPerDevice  int `mapstructure:"max_workers_per_device" env:"WORKER_MAX_PER_DEVICE" min:"1"`
+	MaxConcurrentWorkers int `mapstructure:"max_concurrent_workers" env:"WORKER_MAX_CONCURRENT" min:"1"`
+	QueueSize            int `mapstructure:"queue_size" env:"WORKER_QUEUE_SIZE" min:"1"`
+	MinDelaySeconds      int `mapstructure:"min_delay_seconds" env:"WORKER_MIN_DELAY_SECONDS" reload:"true"`
+	MaxDelaySeconds      int `mapstructure:"max_delay_seconds" env:"WORKER_MAX_DELAY_SECONDS" reload:"true"`
+	BatchSize            int `mapstructure:"batch_size" env:"WORKER_BATCH_SIZE" reload:"true" min:"1"`
+	RetryAttempts        int `mapstructure:"retry_attempts" env:"WORKER_RETRY_ATTEMPTS"`
+	RetryDelaySeconds    int `mapstructure:"retry_delay_seconds" env:"WORKER_RETRY_DELAY_SECONDS"`
+}
+
+type StabilitySection struct {
+	UltraStableMode        bool `mapstructure:"ultra_stable_mode" env:"ULTRA_STABLE_MODE"`
+	ForceReconnectAttempts int  `mapstructure:"force_reconnect_attempts" env:"FORCE_RECONNECT_ATTEMPTS"`
+	KeepAliveInterval      int  `mapstructure:"keep_alive_interval" env:"KEEP_ALIVE_INTERVAL"`
+	IgnoreRateLimits       bool `mapstructure:"ignore_rate_limits" env:"IGNORE_RATE_LIMITS"`
+	MaxSpeedMode           bool `mapstructure:"max_speed_mode" env:"MAX_SPEED_MODE"`
+	DisableDelays          bool `mapstructure:"disable_delays" env:"DISABLE_DELAYS"`
+	ForceOnlineStatus      bool `mapstructure:"force_online_status" env:"FORCE_ONLINE_STATUS"`
+}
+
+// Current returns the configuration the package variables hold
+func Current() Config {
+	mu.RLock()
+	defer mu.RUnlock()
+	return Config{
+		App: AppSection{
+			Port:                  AppPort,
+			Debug:                 AppDebug,
+			Os:                    AppOs,
+			BasicAuth:             AppBasicAuthCredential,
+			ChatFlushIntervalDays: AppChatFlushIntervalDays,
+			LogLevel:              AppLogLevel,
+			ShutdownTimeout:       AppShutdownTimeoutSeconds,
+		},
+		Database: DatabaseSection{URI: DBURI},
+		Whatsapp: WhatsappSection{
+			AutoReply:         WhatsappAutoReplyMessage,
+			Webhook:           WhatsappWebhook,
+			WebhookSecret:     WhatsappWebhookSecret,
+			LogLevel:          WhatsappLogLevel,
+			AccountValidation: WhatsappAccountValidation,
+			ChatStorage:       WhatsappChatStorage,
+		},
+		Redis: RedisSection{URL: RedisURL, Password: RedisPassword, Host: RedisHost, Port: RedisPort},
+		Planner: PlannerSection{
+			Timezone:         PlannerTimezone,
+			SendWindowStart:  PlannerSendWindowStart,
+			SendWindowEnd:    PlannerSendWindowEnd,
+			DailyDeviceQuota: PlannerDailyDeviceQuota,
+			HorizonDays:      PlannerHorizonDays,
+		},
+		Media: MediaSection{
+			StorageDriver:       MediaStorageDriver,
+			StoragePath:         MediaStoragePath,
+			S3Endpoint:          MediaS3Endpoint,
+			S3Bucket:            MediaS3Bucket,
+			S3Region:            MediaS3Region,
+			S3AccessKey:         MediaS3AccessKey,
+			S3SecretKey:         MediaS3SecretKey,
+			FetchTimeoutSeconds: MediaFetchTimeoutSeconds,
+			FetchMaxSize:        MediaFetchMaxSize,
+			SourceCacheMinutes:  MediaSourceCacheMinutes,
+			UploadCacheHours:    MediaUploadCacheHours,
+		},
+		InboundMedia: InboundMediaSection{
+			Enabled:         InboundMediaEnabled,
+			Workers:         InboundMediaWorkers,
+			MaxImageSize:    InboundMediaMaxImageSize,
+			MaxVideoSize:    InboundMediaMaxVideoSize,
+			MaxAudioSize:    InboundMediaMaxAudioSize,
+			MaxDocumentSize: InboundMediaMaxDocumentSize,
+			MaxStickerSize:  InboundMediaMaxStickerSize,
+		},
+		Observability: ObservabilitySection{
+			MetricsToken:    MetricsToken,
+			OtelEndpoint:    OtelExporterEndpoint,
+			OtelServiceName: OtelServiceName,
+			OtelHeaders:     OtelExporterHeaders,
+		},
+		ShareLinks: ShareLinksSection{Secret: ShareLinkSecret, MaxExpiryDays: ShareLinkMaxExpiryDays},
+		Cluster: ClusterSection{
+			Enabled:          ClusterEnabled,
+			NodeID:           ClusterNodeID,
+			AdvertiseURL:     ClusterAdvertiseURL,
+			Secret:           ClusterSecret,
+			HeartbeatSeconds: ClusterHeartbeatSeconds,
+			NodeTTLSeconds:   ClusterNodeTTLSeconds,
+		},
+		NumberValidation: NumberValidationSection{
+			Enabled:            NumberValidationEnabled,
+			BatchSize:          NumberValidationBatchSize,
+			ValidTTLDays:       NumberValidationValidTTLDays,
+			InvalidTTLDays:     NumberValidationInvalidTTLDays,
+			DefaultCountryCode: NumberDefaultCountryCode,
+		},
+		Worker: WorkerSection{
+			MaxWorkersPerDevice:  MaxWorkersPerDevice,
+			MaxConcurrentWorkers: MaxConcurrentWorkers,
+			QueueSize:            WorkerQueueSize,
+			MinDelaySeconds:      DefaultMinDelaySeconds,
+			MaxDelaySeconds:      DefaultMaxDelaySeconds,
+			BatchSize:            BatchSize,
+			RetryAttempts:        RetryAttempts,
+			RetryDelaySeconds:    RetryDelaySeconds,
+		},
+		Stability: StabilitySection(stabilityConfig),
+	}
+}
+
+// Set writes c into the package variables and applies the log level. It is
+// for startup; once requests are served only setReloadable writes.
+func Set(c Config) {
+	mu.Lock()
+	AppPort = c.App.Port
+	AppDebug = c.App.Debug
+	AppOs = c.App.Os
+	AppBasicAuthCredential = c.App.BasicAuth
+	AppChatFlushIntervalDays = c.App.ChatFlushIntervalDays
+	DBURI = c.Database.URI
+	WhatsappAutoReplyMessage = c.Whatsapp.AutoReply
+	WhatsappLogLevel = c.Whatsapp.LogLevel
+	WhatsappAccountValidation = c.Whatsapp.AccountValidation
+	WhatsappChatStorage = c.Whatsapp.ChatStorage
+	RedisURL = c.Redis.URL
+	RedisPassword = c.Redis.Password
+	RedisHost = c.Redis.Host
+	RedisPort = c.Redis.Port
+	PlannerTimezone = c.Planner.Timezone
+	PlannerSendWindowStart = c.Planner.SendWindowStart
+	PlannerSendWindowEnd = c.Planner.SendWindowEnd
+	MediaStorageDriver = c.Media.StorageDriver
+	MediaStoragePath = c.Media.StoragePath
+	MediaS3Endpoint = c.Media.S3Endpoint
+	MediaS3Bucket = c.Media.S3Bucket
+	MediaS3Region = c.Media.S3Region
+	MediaS3AccessKey = c.Media.S3AccessKey
+	MediaS3SecretKey = c.Media.S3SecretKey
+	InboundMediaEnabled = c.InboundMedia.Enabled
+	InboundMediaWorkers = c.InboundMedia.Workers
+	MetricsToken = c.Observability.MetricsToken
+	OtelExporterEndpoint = c.Observability.OtelEndpoint
+	OtelServiceName = c.Observability.OtelServiceName
+	OtelExporterHeaders = c.Observability.OtelHeaders
+	ShareLinkSecret = c.ShareLinks.Secret
+	ClusterEnabled = c.Cluster.Enabled
+	ClusterNodeID = c.Cluster.NodeID
+	ClusterAdvertiseURL = c.Cluster.AdvertiseURL
+	ClusterSecret = c.Cluster.Secret
+	ClusterHeartbeatSeconds = c.Cluster.HeartbeatSeconds
+	ClusterNodeTTLSeconds = c.Cluster.NodeTTLSeconds
+	NumberValidationEnabled = c.NumberValidation.Enabled
+	NumberDefaultCountryCode = c.NumberValidation.DefaultCountryCode
+	MaxWorkersPerDevice = c.Worker.MaxWorkersPerDevice
+	MaxConcurrentWorkers = c.Worker.MaxConcurrentWorkers
+	WorkerQueueSize = c.Worker.QueueSize
+	RetryAttempts = c.Worker.RetryAttempts
+	RetryDelaySeconds = c.Worker.RetryDelaySeconds
+	stabilityConfig = StabilityConfig(c.Stability)
+	mu.Unlock()
+
+	setReloadable(c)
+}
+
+// setReloadable writes the settings tagged reload and applies the log level.
+// Code that may run during a reload reads these through Current or an
+// accessor such as Webhooks.
+func setReloadable(c Config) {
+	mu.Lock()
+	AppLogLevel = c.App.LogLevel
+	AppShutdownTimeoutSeconds = c.App.ShutdownTimeout
+	WhatsappWebhook = c.Whatsapp.Webhook
+	WhatsappWebhookSecret = c.Whatsapp.WebhookSecret
+	PlannerDailyDeviceQuota = c.Planner.DailyDeviceQuota
+	PlannerHorizonDays = c.Planner.HorizonDays
+	MediaFetchTimeoutSeconds = c.Media.FetchTimeoutSeconds
+	MediaFetchMaxSize = c.Media.FetchMaxSize
+	MediaSourceCacheMinutes = c.Media.SourceCacheMinutes
+	MediaUploadCacheHours = c.Media.UploadCacheHours
+	InboundMediaMaxImageSize = c.InboundMedia.MaxImageSize
+	InboundMediaMaxVideoSize = c.InboundMedia.MaxVideoSize
+	InboundMediaMaxAudioSize = c.InboundMedia.MaxAudioSize
+	InboundMediaMaxDocumentSize = c.InboundMedia.MaxDocumentSize
+	InboundMediaMaxStickerSize = c.InboundMedia.MaxStickerSize
+	ShareLinkMaxExpiryDays = c.ShareLinks.MaxExpiryDays
+	NumberValidationBatchSize = c.NumberValidation.BatchSize
+	NumberValidationValidTTLDays = c.NumberValidation.ValidTTLDays
+	NumberValidationInvalidTTLDays = c.NumberValidation.InvalidTTLDays
+	DefaultMinDelaySeconds = c.Worker.MinDelaySeconds
+	DefaultMaxDelaySeconds = c.Worker.MaxDelaySeconds
+	BatchSize = c.Worker.BatchSize
+	mu.Unlock()
+
+	if level, err := logrus.ParseLevel(c.App.LogLevel); err == nil {
+		logrus.SetLevel(level)
+	}
+}
+
+// Validate checks c and returns every problem found, each naming its key
+func (c Config) Validate() error {
+	var errs []error
+	fail := func(key, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+	}
+
+	if port, err := strconv.Atoi(c.App.Port); err != nil || port < 1 || port > 65535 {
+		fail("app.port", "want a port number, got %q", c.App.Port)
+	}
+	switch strings.ToUpper(c.Whatsapp.LogLevel) {
+	case "DEBUG", "INFO", "WARN", "ERROR":
+	default:
+		fail("whatsapp.log_level", "want DEBUG, INFO, WARN or ERROR, got %q", c.Whatsapp.LogLevel)
+	}
+	if _, err := logrus.ParseLevel(c.App.LogLevel); err != nil {
+		fail("app.log_level", "want one of panic, fatal, error, warn, info, debug or trace, got %q", c.App.LogLevel)
+	}
+	for _, credential := range c.App.BasicAuth {
+		if user, secret, ok := strings.Cut(credential, ":"); !ok || user == "" || secret == "" {
+			fail("app.basic_auth", "want user:secret pairs")
+			break
+		}
+	}
+	if c.Database.URI == "" {
+		fail("database.uri", "is required")
+	}
+	for _, hook := range c.Whatsapp.Webhook {
+		if u, err := url.Parse(hook); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			fail("whatsapp.webhook", "want http or https URLs, got %q", hook)
+		}
+	}
+
+	// Without a time zone database the planner falls back to UTC+8, so only
+	// a name the database does not know is an error
+	if _, err := time.LoadLocation(c.Planner.Timezone); err != nil {
+		if _, dbErr := time.LoadLocation("Europe/London"); dbErr == nil {
+			fail("planner.timezone", "unknown time zone %q", c.Planner.Timezone)
+		}
+	}
+	if _, err := time.Parse("15:04", c.Planner.SendWindowStart); err != nil {
+		fail("planner.send_window_start", "want HH:MM, got %q", c.Planner.SendWindowStart)
+	}
+	if _, err := time.Parse("15:04", c.Planner.SendWindowEnd); err != nil {
+		fail("planner.send_window_end", "want HH:MM, got %q", c.Planner.SendWindowEnd)
+	}
+
+	switch c.Media.StorageDriver {
+	case "local":
+	case "s3":
+		if c.Media.S3Bucket == "" {
+			fail("media.s3_bucket", "is required with the s3 driver")
+		}
+	default:
+		fail("media.storage_driver", "want local or s3, got %q", c.Media.StorageDriver)
+	}
+
+	if c.Cluster.Enabled && c.Cluster.Secret == "" {
+		fail("cluster.secret", "is required in cluster mode")
+	}
+	if c.Cluster.Enabled && c.Cluster.NodeTTLSeconds <= c.Cluster.HeartbeatSeconds {
+		fail("cluster.node_ttl_seconds", "must be longer than cluster.heartbeat_seconds (%d)", c.Cluster.HeartbeatSeconds)
+	}
+	if c.Worker.MinDelaySeconds < 0 || c.Worker.MaxDelaySeconds < c.Worker.MinDelaySeconds {
+		fail("worker.max_delay_seconds", "must be at least worker.min_delay_seconds (%d)", c.Worker.MinDelaySeconds)
+	}
+
+	for _, f := range fields(&c) {
+		if f.tag.Get("min") == "1" && f.value.Int() < 1 {
+			fail(f.key, "must be greater than 0, got %d", f.value.Int())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// mu guards the package variables a reload writes. Only readers that take
+// it, through Current, Webhooks or GetStabilityConfig, are safe while a
+// reload may run; reading a reloadable variable directly is a data race.
+var mu sync.RWMutex
+
+// Load builds a configuration on top of base from the file at path, when
+// there is one, and then the environment as seen through lookup
+func Load(path string, base Config, lookup func(key string) (string, bool)) (Config, error) {
+	c := base
+	for _, f := range fields(&c) {
+		if f.value.Kind() == reflect.Slice && !f.value.IsNil() {
+			f.value.Set(reflect.AppendSlice(reflect.MakeSlice(f.value.Type(), 0, f.value.Len()), f.value)) // so decoding never writes into base's lists
+		}
+	}
+	if path != "" {
+		v := viper.New()
+		v.SetConfigFile(path)
+		if err := v.ReadInConfig(); err != nil {
+			return c, fmt.Errorf("failed to read config file %s: %w", path, err)
+		}
+		if err := v.UnmarshalExact(&c); err != nil {
+			return c, fmt.Errorf("invalid config file %s: %w", path, err)
+		}
+	}
+	if err := applyEnv(&c, lookup); err != nil {
+		return c, err
+	}
+	if c.App.Debug {
+		c.Whatsapp.LogLevel = "DEBUG"
+	}
+	if err := c.Validate(); err != nil {
+		return c, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	return c, nil
+}
+
+// EnvLookup reads an environment variable, or a .env entry, through viper.
+// Unresolved platform templates such as ${{Redis.REDIS_URL}} count as unset.
+func EnvLookup(key string) (string, bool) {
+	if !viper.IsSet(key) {
+		return "", false
+	}
+	value := viper.GetString(key)
+	if strings.Contains(value, "${{") {
+		return "", false
+	}
+	return value, true
+}
+
+// Redacted returns c by section and key, with secrets that are set masked
+func (c Config) Redacted() map[string]map[string]any {
+	out := map[string]map[string]any{}
+	for _, f := range fields(&c) {
+		section, key, _ := strings.Cut(f.key, ".")
+		if out[section] == nil {
+			out[section] = map[string]any{}
+		}
+		value := f.value.Interface()
+		if f.tag.Get("secret") == "true" && !f.value.IsZero() {
+			value = "********"
+		}
+		out[section][key] = value
+	}
+	return out
+}
+
+// ReloadableKeys lists the keys Reload applies without a restart
+func ReloadableKeys() []string {
+	var keys []string
+	for _, f := range fields(&Config{}) {
+		if f.tag.Get("reload") == "true" {
+			keys = append(keys, f.key)
+		}
+	}
+	return keys
+}
+
+// field is one setting of a Config, keyed section.name as in the file
+type field struct {
+	key   string
+	value reflect.Value
+	tag   reflect.StructTag
+}
+
+// fields lists the settings of c in declaration order, addressable through c
+func fields(c *Config) []field {
+	var out []field
+	root := reflect.ValueOf(c).Elem()
+	for i := 0; i < root.NumField(); i++ {
+		section := root.Type().Field(i).Tag.Get("mapstructure")
+		sv := root.Field(i)
+		for j := 0; j < sv.NumField(); j++ {
+			f := sv.Type().Field(j)
+			out = append(out, field{key: section + "." + f.Tag.Get("mapstructure"), value: sv.Field(j), tag: f.Tag})
+		}
+	}
+	return out
+}
+
+// applyEnv overrides c with the environment variables its env tags name
+func applyEnv(c *Config, lookup func(key string) (string, bool)) error {
+	var errs []error
+	for _, f := range fields(c) {
+		for _, name := range strings.Split(f.tag.Get("env"), ",") {
+			if name == "" {
+				continue
+			}
+			raw, ok := lookup(name)
+			if !ok {
+				continue
+			}
+			if err := setFromString(f.value, raw); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			}
+			break
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// setFromString parses raw into v; lists are comma-separated
+func setFromString(v reflect.Value, raw string) error {
+	raw = strings.TrimSpace(raw)
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("want true or false, got %q", raw)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("want a whole number, got %q", raw)
+		}
+		v.SetInt(n)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	}
+	return nil
+}
+
+// Webhooks returns the webhook URLs and signing secret, which Reload can
+// change while events are being forwarded
+func Webhooks() ([]string, string) {
+	mu.RLock()
+	defer mu.RUnlock()
+	return WhatsappWebhook, WhatsappWebhookSecret
+}
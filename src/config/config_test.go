@@ -0,0 +1,135 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func envMap(env map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+}
+
+func writeConfig(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	base := Current()
+
+	t.Run("example file", func(t *testing.T) {
+		c, err := Load("../../docs/config.example.yaml", base, envMap(nil))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"https://example.com/whatsapp/webhook"}, c.Whatsapp.Webhook)
+		assert.Equal(t, "60", c.NumberValidation.DefaultCountryCode)
+		assert.Equal(t, base.Cluster, c.Cluster, "keys the file leaves out keep their value")
+	})
+
+	t.Run("environment overrides the file", func(t *testing.T) {
+		path := writeConfig(t, "config.toml", "[worker]\nmin_delay_seconds = 8\nmax_delay_seconds = 20\n")
+		c, err := Load(path, base, envMap(map[string]string{
+			"WORKER_MAX_DELAY_SECONDS": "30",
+			"WHATSAPP_WEBHOOK":         "https://a.example.com, https://b.example.com",
+			"REDISHOST":                "redis.internal",
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, 8, c.Worker.MinDelaySeconds)
+		assert.Equal(t, 30, c.Worker.MaxDelaySeconds)
+		assert.Equal(t, []string{"https://a.example.com", "https://b.example.com"}, c.Whatsapp.Webhook)
+		assert.Equal(t, "redis.internal", c.Redis.Host)
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		path := writeConfig(t, "config.yaml", "worker:\n  min_delay: 8\n")
+		_, err := Load(path, base, envMap(nil))
+		assert.ErrorContains(t, err, "min_delay")
+	})
+
+	t.Run("invalid values are all reported", func(t *testing.T) {
+		path := writeConfig(t, "config.yaml", "planner:\n  send_window_start: 8am\nworker:\n  queue_size: 0\n")
+		_, err := Load(path, base, envMap(map[string]string{"APP_PORT": "http"}))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "planner.send_window_start")
+		assert.Contains(t, err.Error(), "worker.queue_size")
+		assert.Contains(t, err.Error(), "app.port")
+	})
+
+	t.Run("malformed environment variable", func(t *testing.T) {
+		_, err := Load("", base, envMap(map[string]string{"PLANNER_DAILY_DEVICE_QUOTA": "lots"}))
+		assert.ErrorContains(t, err, "PLANNER_DAILY_DEVICE_QUOTA")
+	})
+}
+
+func TestRedacted(t *testing.T) {
+	c := Current()
+	c.Whatsapp.WebhookSecret = "s3cret"
+	c.Redis.URL = ""
+
+	redacted := c.Redacted()
+	assert.Equal(t, "********", redacted["whatsapp"]["webhook_secret"])
+	assert.Equal(t, "", redacted["redis"]["url"], "unset secrets show as unset")
+	assert.Equal(t, c.App.Port, redacted["app"]["port"])
+}
+
+func TestSetReloadableWritesOnlyReloadableKeys(t *testing.T) {
+	before := Current()
+	defer Set(before)
+
+	next := Current()
+	for _, f := range fields(&next) {
+		switch f.value.Kind() {
+		case reflect.String:
+			f.value.SetString(f.value.String() + "-changed")
+		case reflect.Bool:
+			f.value.SetBool(!f.value.Bool())
+		case reflect.Int, reflect.Int64:
+			f.value.SetInt(f.value.Int() + 1)
+		case reflect.Slice:
+			f.value.Set(reflect.ValueOf([]string{"changed"}))
+		}
+	}
+	setReloadable(next)
+
+	applied := Current()
+	nextFields, beforeFields := fields(&next), fields(&before)
+	for i, f := range fields(&applied) {
+		want := beforeFields[i]
+		if f.tag.Get("reload") == "true" {
+			want = nextFields[i]
+		}
+		assert.Equal(t, want.value.Interface(), f.value.Interface(), f.key)
+	}
+}
+
+func TestReloadAppliesWorkerDelaysTogether(t *testing.T) {
+	before := Current()
+	file, base := loadedFile, loadedBase
+	defer func() {
+		Set(before)
+		loadedFile, loadedBase = file, base
+	}()
+
+	loadedBase = before
+	loadedFile = writeConfig(t, "config.yaml", "worker:\n  min_delay_seconds: 40\n  max_delay_seconds: 50\n  batch_size: 20\n")
+	result, err := Reload()
+	require.NoError(t, err)
+	assert.Subset(t, result.Applied, []string{"worker.min_delay_seconds", "worker.max_delay_seconds", "worker.batch_size"})
+	worker := Current().Worker
+	assert.Equal(t, 40, worker.MinDelaySeconds)
+	assert.Equal(t, 50, worker.MaxDelaySeconds)
+	assert.Equal(t, 20, worker.BatchSize)
+
+	loadedFile = writeConfig(t, "config.yaml", "worker:\n  min_delay_seconds: 60\n")
+	_, err = Reload()
+	assert.ErrorContains(t, err, "worker.max_delay_seconds")
+	assert.Equal(t, 40, Current().Worker.MinDelaySeconds, "a rejected reload changes nothing")
+}
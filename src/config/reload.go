@@ -0,0 +1,116 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"syscall"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// What Init loaded the configuration from, kept so Reload can repeat it
+	loadedFile string
+	loadedBase Config
+	reloadMu   sync.Mutex
+
+	watchOnce sync.Once
+)
+
+// Init loads the configuration from the file at path and the environment
+// on top of what the defaults and flags set, and applies it
+func Init(path string) error {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	base := Current()
+	c, err := Load(path, base, EnvLookup)
+	if err != nil {
+		return err
+	}
+	loadedFile, loadedBase = path, base
+	Set(c)
+	return nil
+}
+
+// ReloadResult lists the keys whose values changed on a reload
+type ReloadResult struct {
+	Applied         []string `json:"applied"`
+	RestartRequired []string `json:"restart_required"`
+}
+
+// Reload loads the configuration again the way Init did and applies the
+// changed keys that are safe to change at runtime. Changes to other keys
+// are reported and wait for a restart; an invalid configuration changes
+// nothing.
+func Reload() (ReloadResult, error) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	var result ReloadResult
+	next, err := Load(loadedFile, loadedBase, EnvLookup)
+	if err != nil {
+		return result, err
+	}
+
+	applied := Current()
+	nextFields := fields(&next)
+	for i, f := range fields(&applied) {
+		if reflect.DeepEqual(f.value.Interface(), nextFields[i].value.Interface()) {
+			continue
+		}
+		if f.tag.Get("reload") != "true" {
+			result.RestartRequired = append(result.RestartRequired, f.key)
+			continue
+		}
+		f.value.Set(nextFields[i].value)
+		result.Applied = append(result.Applied, f.key)
+	}
+	// Settings that are checked against each other must still agree when
+	// only some of them could be applied
+	if err := applied.Validate(); err != nil {
+		return ReloadResult{}, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	if len(result.Applied) > 0 {
+		setReloadable(applied)
+	}
+	return result, nil
+}
+
+// ConfigFile returns the path of the loaded config file, empty when there is none
+func ConfigFile() string {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	return loadedFile
+}
+
+// WatchReload reloads the configuration whenever the process gets SIGHUP
+func WatchReload() {
+	watchOnce.Do(func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGHUP)
+		go func() {
+			for range signals {
+				reloadAndLog("SIGHUP")
+			}
+		}()
+	})
+}
+
+// reloadAndLog reloads the configuration and logs what came of it
+func reloadAndLog(reason string) {
+	result, err := Reload()
+	if err != nil {
+		logrus.Errorf("Configuration reload on %s rejected, keeping the current one: %v", reason, err)
+		return
+	}
+	if len(result.Applied) > 0 {
+		logrus.Infof("Configuration reloaded on %s: applied %v", reason, result.Applied)
+	}
+	if len(result.RestartRequired) > 0 {
+		logrus.Warnf("Configuration changes to %v take effect after a restart", result.RestartRequired)
+	}
+}
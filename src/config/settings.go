@@ -12,6 +12,8 @@ var (
 	AppPlatform              = waCompanionReg.DeviceProps_PlatformType(1)
 	AppBasicAuthCredential   []string
 	AppChatFlushIntervalDays = 7 // Number of days before flushing chat.csv
+	AppLogLevel              = "info"
+	AppShutdownTimeoutSeconds = 30 // Seconds a stopping replica waits for sends in progress
 
 	McpPort = "8080"
 	McpHost = "localhost"
@@ -42,4 +44,69 @@ var (
 	RedisPassword string
 	RedisHost     = "localhost"
 	RedisPort     = "6379"
+
+	// Broadcast planner - send window is local time in PlannerTimezone
+	PlannerTimezone         = "Asia/Kuala_Lumpur"
+	PlannerSendWindowStart  = "08:00"
+	PlannerSendWindowEnd    = "22:00"
+	PlannerDailyDeviceQuota = 1000 // Max messages per device per day
+	PlannerHorizonDays      = 14   // Default number of days forecast by /api/planner
+
+	// Media asset library - driver is "local" or "s3"
+	MediaStorageDriver       = "local"
+	MediaStoragePath         = "storages/media-assets"
+	MediaS3Endpoint          string
+	MediaS3Bucket            string
+	MediaS3Region            = "us-east-1"
+	MediaS3AccessKey         string
+	MediaS3SecretKey         string
+	MediaFetchTimeoutSeconds       = 60
+	MediaFetchMaxSize        int64 = 100000000 // 100MB
+	MediaSourceCacheMinutes        = 60        // Reuse media fetched from the same URL for this long
+	MediaUploadCacheHours          = 24        // Upper bound on reusing a WhatsApp upload
+
+	// Received media - downloaded into the media store for the web chat view.
+	// Media larger than its type's cap is left on WhatsApp.
+	InboundMediaEnabled               = true
+	InboundMediaWorkers               = 4
+	InboundMediaMaxImageSize    int64 = 16000000  // 16MB
+	InboundMediaMaxVideoSize    int64 = 64000000  // 64MB
+	InboundMediaMaxAudioSize    int64 = 16000000  // 16MB
+	InboundMediaMaxDocumentSize int64 = 100000000 // 100MB
+	InboundMediaMaxStickerSize  int64 = 1000000   // 1MB
+
+	// Observability - /metrics requires "Authorization: Bearer <MetricsToken>"
+	// when a token is set; tracing is off until an OTLP/HTTP endpoint is set
+	MetricsToken         string
+	OtelExporterEndpoint string
+	OtelServiceName      = "go-whatsapp-web-multidevice"
+	OtelExporterHeaders  string // comma-separated key=value pairs
+
+	// Device share links - tokens are signed with this key, or with one
+	// generated and stored in the database when it is empty
+	ShareLinkSecret        string
+	ShareLinkMaxExpiryDays = 90
+
+	// Cluster mode - replicas register in Redis (or the database), share the
+	// devices by consistent hashing and elect one leader for global jobs.
+	// ClusterAdvertiseURL is how other replicas reach this one, e.g.
+	// http://replica-1.internal:3000; it defaults to the hostname and AppPort.
+	// Every replica needs the same ClusterSecret, which signs the requests
+	// they forward to each other.
+	ClusterEnabled          bool
+	ClusterNodeID           string // Defaults to the hostname
+	ClusterAdvertiseURL     string
+	ClusterSecret           string
+	ClusterHeartbeatSeconds = 10
+	ClusterNodeTTLSeconds   = 30 // A replica silent for this long is dead
+
+	// Lead number validation - new leads are checked with WhatsApp through
+	// their own device, a batch per device per minute, and the result is
+	// reused for other leads with the number until it expires. National
+	// numbers starting with 0 get NumberDefaultCountryCode, e.g. 60.
+	NumberValidationEnabled        = true
+	NumberValidationBatchSize      = 50
+	NumberValidationValidTTLDays   = 30
+	NumberValidationInvalidTTLDays = 7
+	NumberDefaultCountryCode       string
 )
@@ -1,10 +1,5 @@
 package config
 
-import (
-	"os"
-	"strconv"
-)
-
 // StabilityConfig contains all stability-related settings
 type StabilityConfig struct {
 	// UltraStableMode - when true, devices NEVER disconnect
@@ -29,44 +24,22 @@ type StabilityConfig struct {
 	ForceOnlineStatus bool
 }
 
-var stabilityConfig *StabilityConfig
+// stabilityConfig holds the stability settings, set from the stability
+// section of Config
+var stabilityConfig = StabilityConfig{
+	UltraStableMode:        true,
+	ForceReconnectAttempts: 100,
+	KeepAliveInterval:      5,
+	IgnoreRateLimits:       true,
+	MaxSpeedMode:           true,
+	DisableDelays:          true,
+	ForceOnlineStatus:      true,
+}
 
 // GetStabilityConfig returns the stability configuration
 func GetStabilityConfig() *StabilityConfig {
-	if stabilityConfig == nil {
-		stabilityConfig = &StabilityConfig{
-			UltraStableMode:        getEnvBool("ULTRA_STABLE_MODE", true),
-			ForceReconnectAttempts: getEnvInt("FORCE_RECONNECT_ATTEMPTS", 100),
-			KeepAliveInterval:      getEnvInt("KEEP_ALIVE_INTERVAL", 5),
-			IgnoreRateLimits:       getEnvBool("IGNORE_RATE_LIMITS", true),
-			MaxSpeedMode:           getEnvBool("MAX_SPEED_MODE", true),
-			DisableDelays:          getEnvBool("DISABLE_DELAYS", true),
-			ForceOnlineStatus:      getEnvBool("FORCE_ONLINE_STATUS", true),
-		}
-	}
-	return stabilityConfig
-}
-
-func getEnvBool(key string, defaultValue bool) bool {
-	val := os.Getenv(key)
-	if val == "" {
-		return defaultValue
-	}
-	b, err := strconv.ParseBool(val)
-	if err != nil {
-		return defaultValue
-	}
-	return b
-}
-
-func getEnvInt(key string, defaultValue int) int {
-	val := os.Getenv(key)
-	if val == "" {
-		return defaultValue
-	}
-	i, err := strconv.Atoi(val)
-	if err != nil {
-		return defaultValue
-	}
-	return i
+	mu.RLock()
+	defer mu.RUnlock()
+	c := stabilityConfig
+	return &c
 }
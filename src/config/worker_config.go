@@ -1,22 +1,25 @@
 package config
 
+// Worker settings that config files and the environment can change, see Config.
+// The delays and the batch size can also change at runtime.
+var (
+	MaxWorkersPerDevice    = 5     // Increased from 1 to handle parallel processing
+	MaxConcurrentWorkers   = 2000  // Increased from 500 for better throughput
+	WorkerQueueSize        = 10000 // Increased from 1000 to handle 5K+ messages
+	DefaultMinDelaySeconds = 5     // Min delay between messages
+	DefaultMaxDelaySeconds = 15    // Max delay between messages
+	BatchSize              = 500   // Increased from 100 for bulk processing
+	RetryAttempts          = 3     // Retry failed messages
+	RetryDelaySeconds      = 60    // Delay between retries
+)
+
 // Worker configuration optimized for high-volume messaging (5K per device)
 const (
 	// Worker Pool Settings - OPTIMIZED FOR 5K MESSAGES PER DEVICE
-	MaxWorkersPerDevice   = 5      // Increased from 1 to handle parallel processing
-	MaxConcurrentWorkers  = 2000   // Increased from 500 for better throughput
-	WorkerQueueSize       = 10000  // Increased from 1000 to handle 5K+ messages
 	WorkerHealthCheckSec  = 60     // Increased from 30 to reduce overhead
 	WorkerIdleTimeoutMin  = 30     // Increased from 10 to keep workers active longer
 	MessageQueueTimeout   = 30     // Timeout for queueing messages (seconds)
 	
-	// Message Processing - OPTIMIZED FOR VOLUME
-	DefaultMinDelaySeconds = 5     // Min delay between messages
-	DefaultMaxDelaySeconds = 15    // Max delay between messages
-	BatchSize             = 500    // Increased from 100 for bulk processing
-	RetryAttempts         = 3      // Retry failed messages
-	RetryDelaySeconds     = 60     // Delay between retries
-	
 	// Campaign & Sequence Processing
 	CampaignTriggerIntervalSec = 60  // Check for campaigns every minute
 	SequenceTriggerIntervalSec = 300 // Process sequences every 5 minutes
@@ -50,6 +53,8 @@ const (
 
 // GetWorkerConfig returns optimized worker configuration
 func GetWorkerConfig() map[string]interface{} {
+	mu.RLock()
+	defer mu.RUnlock()
 	return map[string]interface{}{
 		"max_workers_per_device":   MaxWorkersPerDevice,
 		"max_concurrent_workers":   MaxConcurrentWorkers,
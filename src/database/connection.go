@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"encoding/base64"
 	"fmt"
@@ -13,6 +14,7 @@ import (
 	_ "github.com/go-sql-driver/mysql"
 	_ "github.com/lib/pq"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/scheduler"
 )
 
 var (
@@ -82,6 +84,16 @@ func GetDB() *sql.DB {
 	
 	return db
 }
+
+// SetDB installs conn as the application database in place of the configured
+// one. It must be called before the first GetDB, since repositories keep the
+// connection they were created with; the simulation harness uses it to run
+// against SQLite.
+func SetDB(conn *sql.DB) {
+	once.Do(func() {})
+	db = conn
+}
+
 // InitializeSchema creates tables if they don't exist
 func InitializeSchema() error {
 	schema := `
@@ -477,14 +489,7 @@ func InitializeSchema() error {
 	}
 	
 	// Run cleanup for expired sessions
-	go func() {
-		ticker := time.NewTicker(1 * time.Hour)
-		defer ticker.Stop()
-		
-		for range ticker.C {
-			db.Exec("DELETE FROM user_sessions WHERE expires_at < CURRENT_TIMESTAMP")
-		}
-	}()
+	scheduleSessionCleanup(db, "user-session-cleanup", "user_sessions")
 	
 	return nil
 }
@@ -552,14 +557,22 @@ func runTeamMembersMigration(db *sql.DB) error {
 	log.Println("✓ Team members migration completed successfully")
 	
 	// Run cleanup for expired team sessions
-	go func() {
-		ticker := time.NewTicker(1 * time.Hour)
-		defer ticker.Stop()
-		
-		for range ticker.C {
-			db.Exec("DELETE FROM team_sessions WHERE expires_at < CURRENT_TIMESTAMP")
-		}
-	}()
+	scheduleSessionCleanup(db, "team-session-cleanup", "team_sessions")
 	
 	return nil
 }
+
+// scheduleSessionCleanup deletes the expired rows of a session table every hour
+func scheduleSessionCleanup(db *sql.DB, job, table string) {
+	scheduler.Default().MustRegister(scheduler.Job{
+		Name:      job,
+		Interval:  time.Hour,
+		Singleton: true,
+		Run: func(ctx context.Context) error {
+			if _, err := db.ExecContext(ctx, "DELETE FROM "+table+" WHERE expires_at < CURRENT_TIMESTAMP"); err != nil {
+				return fmt.Errorf("failed to delete expired %s: %w", table, err)
+			}
+			return nil
+		},
+	})
+}
@@ -423,6 +423,396 @@ CREATE INDEX IF NOT EXISTS idx_sequence_steps_sequence_id ON sequence_steps(sequ
 `,
 	})
 	
+	// Visual-flow sequences: flow definitions, contact positions and lead tags
+	pendingMigrations = append(pendingMigrations, Migration{
+		Name: "Add sequence flow tables",
+		SQL: `
+CREATE TABLE IF NOT EXISTS sequence_flows (
+	sequence_id VARCHAR(36) PRIMARY KEY,
+	definition LONGTEXT NOT NULL,
+	version INT NOT NULL DEFAULT 1,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS sequence_flow_positions (
+	id VARCHAR(36) PRIMARY KEY,
+	sequence_id VARCHAR(36) NOT NULL,
+	user_id VARCHAR(36) NOT NULL,
+	contact_phone VARCHAR(50) NOT NULL,
+	contact_name VARCHAR(255),
+	device_id VARCHAR(255),
+	device_name VARCHAR(255),
+	node_id VARCHAR(100) NOT NULL,
+	status VARCHAR(20) NOT NULL DEFAULT 'active',
+	wait_until DATETIME NULL,
+	pending_message_id VARCHAR(36) NULL,
+	last_error TEXT,
+	entered_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	UNIQUE KEY uniq_flow_contact (sequence_id, contact_phone),
+	INDEX idx_flow_positions_due (status, wait_until),
+	INDEX idx_flow_positions_message (pending_message_id)
+);
+
+CREATE TABLE IF NOT EXISTS lead_tags (
+	user_id VARCHAR(36) NOT NULL,
+	phone VARCHAR(50) NOT NULL,
+	tag VARCHAR(100) NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (user_id, phone, tag)
+);
+`,
+	})
+	
+	// Unified dead-letter store for failed broadcast messages
+	pendingMigrations = append(pendingMigrations, Migration{
+		Name: "Add broadcast dead letters table",
+		SQL: `
+CREATE TABLE IF NOT EXISTS broadcast_dead_letters (
+	message_id VARCHAR(36) PRIMARY KEY,
+	user_id VARCHAR(36),
+	device_id VARCHAR(255),
+	device_name VARCHAR(255),
+	campaign_id INT NULL,
+	sequence_id VARCHAR(36) NULL,
+	recipient_phone VARCHAR(50),
+	recipient_name VARCHAR(255),
+	message_type VARCHAR(50),
+	error_class VARCHAR(32) NOT NULL DEFAULT 'unknown',
+	error_message TEXT,
+	source VARCHAR(20) NOT NULL DEFAULT 'sql',
+	retries INT NOT NULL DEFAULT 0,
+	failed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	INDEX idx_dead_letters_user_class (user_id, error_class),
+	INDEX idx_dead_letters_campaign (campaign_id),
+	INDEX idx_dead_letters_sequence (sequence_id),
+	INDEX idx_dead_letters_device (device_id)
+);
+`,
+	})
+	
+	// Typed send failures: error code and retry count per message
+	pendingMigrations = append(pendingMigrations, Migration{
+		Name: "Add send error code columns",
+		SQL: `
+ALTER TABLE broadcast_messages ADD COLUMN IF NOT EXISTS error_code VARCHAR(40) NULL;
+ALTER TABLE broadcast_messages ADD COLUMN IF NOT EXISTS retry_count INT NOT NULL DEFAULT 0;
+ALTER TABLE broadcast_dead_letters ADD COLUMN IF NOT EXISTS error_code VARCHAR(40) NULL;
+CREATE INDEX IF NOT EXISTS idx_broadcast_messages_error_code ON broadcast_messages(error_code);
+`,
+	})
+	
+	// Type-specific message fields (filename, mimetype, ptt, poll options, coordinates, contact)
+	pendingMigrations = append(pendingMigrations, Migration{
+		Name: "Add message payload columns",
+		SQL: `
+ALTER TABLE broadcast_messages ADD COLUMN IF NOT EXISTS message_payload TEXT NULL;
+ALTER TABLE sequence_steps ADD COLUMN IF NOT EXISTS message_payload TEXT NULL;
+ALTER TABLE campaigns ADD COLUMN IF NOT EXISTS message_type VARCHAR(20) NULL;
+ALTER TABLE campaigns ADD COLUMN IF NOT EXISTS message_payload TEXT NULL;
+`,
+	})
+	
+	// Media asset library and per-device cache of WhatsApp uploads
+	pendingMigrations = append(pendingMigrations, Migration{
+		Name: "Add media asset tables",
+		SQL: `
+CREATE TABLE IF NOT EXISTS media_assets (
+	id VARCHAR(36) PRIMARY KEY,
+	user_id VARCHAR(36) NOT NULL DEFAULT '',
+	sha256 CHAR(64) NOT NULL,
+	mimetype VARCHAR(100) NOT NULL,
+	size BIGINT NOT NULL DEFAULT 0,
+	width INT NOT NULL DEFAULT 0,
+	height INT NOT NULL DEFAULT 0,
+	file_name VARCHAR(255) NOT NULL DEFAULT '',
+	source_url TEXT NULL,
+	source_hash CHAR(64) NULL,
+	storage_key VARCHAR(255) NOT NULL,
+	thumbnail_key VARCHAR(255) NOT NULL DEFAULT '',
+	fetched_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE KEY uniq_media_assets_user_sha (user_id, sha256),
+	INDEX idx_media_assets_source (source_hash),
+	INDEX idx_media_assets_sha (sha256)
+);
+
+CREATE TABLE IF NOT EXISTS media_uploads (
+	device_id VARCHAR(255) NOT NULL,
+	sha256 CHAR(64) NOT NULL,
+	media_type VARCHAR(40) NOT NULL,
+	url TEXT NOT NULL,
+	direct_path TEXT NOT NULL,
+	media_key VARBINARY(64) NOT NULL,
+	file_enc_sha256 VARBINARY(64) NOT NULL,
+	file_sha256 VARBINARY(64) NOT NULL,
+	file_length BIGINT NOT NULL,
+	expires_at DATETIME NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (device_id, sha256, media_type),
+	INDEX idx_media_uploads_expires (expires_at)
+);
+`,
+	})
+	
+	// Trace context so a worker can continue the trace of the campaign that queued a message
+	pendingMigrations = append(pendingMigrations, Migration{
+		Name: "Add broadcast message trace context",
+		SQL: `ALTER TABLE broadcast_messages ADD COLUMN IF NOT EXISTS trace_context VARCHAR(64) NULL;`,
+	})
+	
+	// Signed, expiring share links for the public device views
+	pendingMigrations = append(pendingMigrations, Migration{
+		Name: "Add share link tables",
+		SQL: `
+CREATE TABLE IF NOT EXISTS share_links (
+	id VARCHAR(36) PRIMARY KEY,
+	user_id VARCHAR(36) NOT NULL,
+	device_id VARCHAR(36) NOT NULL,
+	name VARCHAR(255) NOT NULL DEFAULT '',
+	scopes VARCHAR(255) NOT NULL,
+	password_hash VARCHAR(255) NOT NULL DEFAULT '',
+	expires_at DATETIME NOT NULL,
+	revoked_at DATETIME NULL,
+	last_accessed_at DATETIME NULL,
+	access_count BIGINT NOT NULL DEFAULT 0,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	INDEX idx_share_links_user_device (user_id, device_id)
+);
+
+CREATE TABLE IF NOT EXISTS share_link_access_log (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	link_id VARCHAR(36) NOT NULL,
+	method VARCHAR(10) NOT NULL,
+	path VARCHAR(512) NOT NULL,
+	ip VARCHAR(64) NOT NULL DEFAULT '',
+	user_agent VARCHAR(512) NOT NULL DEFAULT '',
+	outcome VARCHAR(40) NOT NULL,
+	status INT NOT NULL DEFAULT 0,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	INDEX idx_share_link_access_link (link_id, created_at)
+);
+
+CREATE TABLE IF NOT EXISTS app_secrets (
+	name VARCHAR(64) PRIMARY KEY,
+	value VARCHAR(255) NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+`,
+	})
+	
+	// Audit trail of campaign pauses, resumes, cancels, throttles and status changes
+	pendingMigrations = append(pendingMigrations, Migration{
+		Name: "Add campaign events table",
+		SQL: `
+CREATE TABLE IF NOT EXISTS campaign_events (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	campaign_id INT NOT NULL,
+	actor VARCHAR(255) NOT NULL,
+	action VARCHAR(20) NOT NULL,
+	from_status VARCHAR(50) NOT NULL DEFAULT '',
+	to_status VARCHAR(50) NOT NULL DEFAULT '',
+	reason VARCHAR(500) NOT NULL DEFAULT '',
+	min_delay_seconds INT NULL,
+	max_delay_seconds INT NULL,
+	messages INT NOT NULL DEFAULT 0,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	INDEX idx_campaign_events_campaign (campaign_id, created_at)
+);
+`,
+	})
+	
+	// Received media downloaded into the media store
+	pendingMigrations = append(pendingMigrations, Migration{
+		Name: "Add whatsapp message media columns",
+		SQL: `
+ALTER TABLE whatsapp_messages ADD COLUMN IF NOT EXISTS media_sha256 CHAR(64) NULL;
+ALTER TABLE whatsapp_messages ADD COLUMN IF NOT EXISTS media_mimetype VARCHAR(100) NULL;
+ALTER TABLE whatsapp_messages ADD COLUMN IF NOT EXISTS media_size BIGINT NULL;
+ALTER TABLE whatsapp_messages ADD COLUMN IF NOT EXISTS media_file_name VARCHAR(255) NULL;
+ALTER TABLE whatsapp_messages ADD COLUMN IF NOT EXISTS media_storage_key VARCHAR(255) NULL;
+ALTER TABLE whatsapp_messages ADD COLUMN IF NOT EXISTS media_thumbnail_key VARCHAR(255) NULL;
+CREATE INDEX IF NOT EXISTS idx_whatsapp_messages_media_sha ON whatsapp_messages(media_sha256);
+`,
+	})
+
+	// Full-text search across conversation history
+	pendingMigrations = append(pendingMigrations, Migration{
+		Name: "Add whatsapp message full-text index",
+		SQL: `
+ALTER TABLE whatsapp_messages ADD FULLTEXT INDEX whatsapp_messages_fts (message_text);
+CREATE INDEX IF NOT EXISTS idx_whatsapp_messages_timestamp ON whatsapp_messages(timestamp);
+`,
+	})
+
+	// Lead timeline: receipts of sent broadcast messages and the lead change log
+	pendingMigrations = append(pendingMigrations, Migration{
+		Name: "Add broadcast receipts and lead events",
+		SQL: `
+ALTER TABLE broadcast_messages ADD COLUMN IF NOT EXISTS whatsapp_message_id VARCHAR(64) NULL;
+ALTER TABLE broadcast_messages ADD COLUMN IF NOT EXISTS delivered_at TIMESTAMP NULL;
+ALTER TABLE broadcast_messages ADD COLUMN IF NOT EXISTS read_at TIMESTAMP NULL;
+CREATE INDEX IF NOT EXISTS idx_broadcast_whatsapp_message ON broadcast_messages(whatsapp_message_id);
+
+CREATE TABLE IF NOT EXISTS lead_events (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	user_id VARCHAR(36) NOT NULL,
+	phone VARCHAR(50) NOT NULL,
+	kind VARCHAR(20) NOT NULL,
+	actor VARCHAR(255) NOT NULL,
+	from_value VARCHAR(255) NOT NULL DEFAULT '',
+	to_value VARCHAR(255) NOT NULL DEFAULT '',
+	text TEXT,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	INDEX idx_lead_events_lead (user_id, phone, created_at)
+);
+`,
+	})
+
+	// Per-device history-sync policy
+	pendingMigrations = append(pendingMigrations, Migration{
+		Name: "Add device history policies",
+		SQL: `
+CREATE TABLE IF NOT EXISTS device_history_policies (
+	device_id VARCHAR(36) PRIMARY KEY,
+	keep_days INT NOT NULL DEFAULT 180,
+	chat_types VARCHAR(64) NOT NULL DEFAULT 'personal',
+	auto_leads BOOLEAN NOT NULL DEFAULT FALSE,
+	lead_niche VARCHAR(255) NOT NULL DEFAULT '',
+	lead_status VARCHAR(20) NOT NULL DEFAULT 'prospect',
+	lead_trigger VARCHAR(255) NOT NULL DEFAULT '',
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
+`,
+	})
+
+	// WhatsApp group cache, suppression list and group campaigns
+	pendingMigrations = append(pendingMigrations, Migration{
+		Name: "Add WhatsApp group cache, suppression list and group campaigns",
+		SQL: `
+CREATE TABLE IF NOT EXISTS whatsapp_groups (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	device_id VARCHAR(36) NOT NULL,
+	group_jid VARCHAR(100) NOT NULL,
+	name VARCHAR(255) NOT NULL DEFAULT '',
+	participants INT NOT NULL DEFAULT 0,
+	is_admin BOOLEAN NOT NULL DEFAULT FALSE,
+	is_announce BOOLEAN NOT NULL DEFAULT FALSE,
+	refreshed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE KEY uniq_whatsapp_groups_device_group (device_id, group_jid)
+);
+
+CREATE TABLE IF NOT EXISTS lead_suppressions (
+	user_id VARCHAR(36) NOT NULL,
+	phone VARCHAR(50) NOT NULL,
+	reason VARCHAR(255) NOT NULL DEFAULT '',
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (user_id, phone)
+);
+
+ALTER TABLE campaigns ADD COLUMN IF NOT EXISTS target_groups TEXT NULL;
+`,
+	})
+
+	// Lead number validation
+	pendingMigrations = append(pendingMigrations, Migration{
+		Name: "Add lead number validation",
+		SQL: `
+CREATE TABLE IF NOT EXISTS number_validations (
+	phone VARCHAR(50) PRIMARY KEY,
+	status VARCHAR(20) NOT NULL,
+	jid VARCHAR(100) NOT NULL DEFAULT '',
+	is_business BOOLEAN NOT NULL DEFAULT FALSE,
+	verified_name VARCHAR(255) NOT NULL DEFAULT '',
+	checked_at TIMESTAMP NOT NULL,
+	expires_at TIMESTAMP NOT NULL
+);
+
+ALTER TABLE leads ADD COLUMN IF NOT EXISTS number_status VARCHAR(20) NULL;
+ALTER TABLE leads ADD COLUMN IF NOT EXISTS number_checked_at TIMESTAMP NULL;
+CREATE INDEX IF NOT EXISTS idx_leads_number_status ON leads(device_id, number_status);
+`,
+	})
+
+	// Shutdown markers
+	pendingMigrations = append(pendingMigrations, Migration{
+		Name: "Add shutdown markers",
+		SQL: `
+CREATE TABLE IF NOT EXISTS shutdown_markers (
+	node_id VARCHAR(255) PRIMARY KEY,
+	state VARCHAR(20) NOT NULL,
+	started_at TIMESTAMP NOT NULL,
+	stopped_at TIMESTAMP NULL,
+	requeued TEXT,
+	interrupted TEXT,
+	devices TEXT
+);
+`,
+	})
+
+	// Idempotency keys
+	pendingMigrations = append(pendingMigrations, Migration{
+		Name: "Add idempotency keys",
+		SQL: `
+ALTER TABLE broadcast_messages ADD COLUMN IF NOT EXISTS idempotency_key VARCHAR(191) NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS uniq_broadcast_idempotency_key ON broadcast_messages(idempotency_key);
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+	scope VARCHAR(64) NOT NULL,
+	idem_key VARCHAR(191) NOT NULL,
+	fingerprint VARCHAR(64) NOT NULL,
+	state VARCHAR(10) NOT NULL,
+	status_code INT NOT NULL DEFAULT 0,
+	content_type VARCHAR(100) NOT NULL DEFAULT '',
+	body MEDIUMTEXT,
+	created_at TIMESTAMP NOT NULL,
+	expires_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (scope, idem_key),
+	INDEX idx_idempotency_expires (expires_at)
+);
+`,
+	})
+
+	// Lead consents and the data subject audit log
+	pendingMigrations = append(pendingMigrations, Migration{
+		Name: "Add lead consents and data subject requests",
+		SQL: `
+CREATE TABLE IF NOT EXISTS lead_consents (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	user_id VARCHAR(36) NOT NULL,
+	phone VARCHAR(50) NOT NULL,
+	source VARCHAR(255) NOT NULL DEFAULT '',
+	channel VARCHAR(32) NOT NULL,
+	proof_text TEXT,
+	given_at TIMESTAMP NOT NULL,
+	recorded_at TIMESTAMP NOT NULL,
+	INDEX idx_lead_consents_lead (user_id, phone, given_at)
+);
+
+CREATE TABLE IF NOT EXISTS data_subject_requests (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	user_id VARCHAR(36) NOT NULL,
+	actor VARCHAR(255) NOT NULL,
+	action VARCHAR(10) NOT NULL,
+	subject_hash VARCHAR(64) NOT NULL,
+	pseudonym VARCHAR(50) NOT NULL DEFAULT '',
+	affected TEXT,
+	created_at TIMESTAMP NOT NULL,
+	INDEX idx_data_subject_requests_user (user_id, created_at),
+	INDEX idx_data_subject_requests_subject (subject_hash)
+);
+`,
+	})
+
+	// Sequence contact runs
+	pendingMigrations = append(pendingMigrations, Migration{
+		Name: "Add sequence contact enrollment time",
+		SQL: `
+ALTER TABLE sequence_contacts ADD COLUMN IF NOT EXISTS enrolled_at TIMESTAMP NULL;
+`,
+	})
+
 	return pendingMigrations
 }
 
@@ -0,0 +1,106 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Schema is the DDL a repository needs before its first query. Migrations
+// are not run at startup, so each repository registers its schema here and
+// ensures it from its getter, which applies it once per process.
+type Schema struct {
+	name  string
+	steps []SchemaStep
+	once  sync.Once
+}
+
+// SchemaStep is one change of a schema. It must be safe to repeat, since
+// every process applies it again.
+type SchemaStep func(db *sql.DB) error
+
+var (
+	schemasMu sync.Mutex
+	schemas   = map[string]*Schema{}
+)
+
+// RegisterSchema registers the steps of a schema, applied in order, under a
+// name no other schema uses
+func RegisterSchema(name string, steps ...SchemaStep) *Schema {
+	schemasMu.Lock()
+	defer schemasMu.Unlock()
+	if _, ok := schemas[name]; ok {
+		panic(fmt.Sprintf("database: schema %q registered twice", name))
+	}
+	s := &Schema{name: name, steps: steps}
+	schemas[name] = s
+	return s
+}
+
+// Ensure applies the schema to db the first time it is called. A step that
+// fails is logged and the rest still run; none is retried before a restart.
+func (s *Schema) Ensure(db *sql.DB) {
+	s.once.Do(func() {
+		for _, step := range s.steps {
+			if err := step(db); err != nil {
+				logrus.Errorf("Failed to apply %s schema: %v", s.name, err)
+			}
+		}
+	})
+}
+
+// Statement runs DDL that is a no-op when already applied, such as
+// CREATE TABLE IF NOT EXISTS
+func Statement(ddl string) SchemaStep {
+	return func(db *sql.DB) error {
+		_, err := db.Exec(ddl)
+		return err
+	}
+}
+
+// AddColumn adds a column when information_schema shows it is absent
+func AddColumn(table, column, definition string) SchemaStep {
+	return func(db *sql.DB) error {
+		var count int
+		err := db.QueryRow(`
+			SELECT COUNT(*) FROM information_schema.columns
+			WHERE table_schema = DATABASE() AND table_name = ? AND column_name = ?
+		`, table, column).Scan(&count)
+		if err != nil || count > 0 {
+			return nil
+		}
+		if _, err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)); err != nil {
+			return fmt.Errorf("add %s.%s: %w", table, column, err)
+		}
+		return nil
+	}
+}
+
+// AddIndex adds an index when information_schema shows it is absent
+func AddIndex(table, index, columns string) SchemaStep {
+	return createIndex("INDEX", table, index, columns)
+}
+
+// AddUniqueIndex adds a unique index when information_schema shows it is absent
+func AddUniqueIndex(table, index, columns string) SchemaStep {
+	return createIndex("UNIQUE INDEX", table, index, columns)
+}
+
+func createIndex(kind, table, index, columns string) SchemaStep {
+	return func(db *sql.DB) error {
+		var count int
+		err := db.QueryRow(`
+			SELECT COUNT(*) FROM information_schema.statistics
+			WHERE table_schema = DATABASE() AND table_name = ? AND index_name = ?
+		`, table, index).Scan(&count)
+		if err != nil || count > 0 {
+			return nil
+		}
+		if _, err := db.Exec(fmt.Sprintf("CREATE %s %s ON %s (%s)", kind, index, table, columns)); err != nil {
+			return fmt.Errorf("add index %s on %s: %w", index, table, err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,29 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaEnsureAppliesEachStepOnce(t *testing.T) {
+	var applied []string
+	step := func(name string, err error) SchemaStep {
+		return func(db *sql.DB) error {
+			applied = append(applied, name)
+			return err
+		}
+	}
+	schema := RegisterSchema("test.ensure_once", step("failing", errors.New("boom")), step("next", nil))
+
+	schema.Ensure(nil)
+	schema.Ensure(nil)
+	assert.Equal(t, []string{"failing", "next"}, applied, "a failed step does not stop the rest and nothing runs twice")
+}
+
+func TestRegisterSchemaRejectsDuplicateNames(t *testing.T) {
+	RegisterSchema("test.duplicate")
+	assert.Panics(t, func() { RegisterSchema("test.duplicate") })
+}
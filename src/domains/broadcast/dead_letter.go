@@ -0,0 +1,94 @@
+package broadcast
+
+import (
+	"time"
+
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+)
+
+// Error classes for failed messages
+const (
+	ErrorClassDeviceOffline = "device_offline"
+	ErrorClassInvalidNumber = "invalid_number"
+	ErrorClassMediaDownload = "media_download_failed"
+	ErrorClassRateLimited   = "rate_limited"
+	ErrorClassUnknown       = "unknown"
+)
+
+// ErrorClasses lists every error class in display order
+var ErrorClasses = []string{
+	ErrorClassDeviceOffline,
+	ErrorClassInvalidNumber,
+	ErrorClassMediaDownload,
+	ErrorClassRateLimited,
+	ErrorClassUnknown,
+}
+
+// Dead letter sources, i.e. which broadcast path gave up on the message
+const (
+	DeadLetterSourceSQL        = "sql"
+	DeadLetterSourceRedis      = "redis"
+	DeadLetterSourceUltraRedis = "ultra_redis"
+)
+
+// DeadLetter is a message that failed permanently and waits for an operator
+type DeadLetter struct {
+	MessageID      string    `json:"message_id"`
+	UserID         string    `json:"user_id"`
+	DeviceID       string    `json:"device_id"`
+	DeviceName     string    `json:"device_name"`
+	CampaignID     *int      `json:"campaign_id,omitempty"`
+	SequenceID     *string   `json:"sequence_id,omitempty"`
+	RecipientPhone string    `json:"recipient_phone"`
+	RecipientName  string    `json:"recipient_name"`
+	MessageType    string    `json:"message_type"`
+	ErrorClass     string    `json:"error_class"`
+	ErrorCode      string    `json:"error_code"`
+	ErrorMessage   string    `json:"error_message"`
+	Source         string    `json:"source"`
+	Retries        int       `json:"retries"`
+	FailedAt       time.Time `json:"failed_at"`
+}
+
+// DeadLetterFilter narrows dead letter queries
+type DeadLetterFilter struct {
+	UserID     string
+	CampaignID int
+	SequenceID string
+	DeviceID   string
+	ErrorClass string
+	ErrorCode  string
+	Limit      int
+	Offset     int
+}
+
+// ErrorClassForCode groups a send error code into a dead letter class
+func ErrorClassForCode(code pkgError.SendErrorCode) string {
+	switch code {
+	case pkgError.SendErrNotOnWhatsapp, pkgError.SendErrInvalidRecipient:
+		return ErrorClassInvalidNumber
+	case pkgError.SendErrDeviceLoggedOut, pkgError.SendErrDeviceUnavailable:
+		return ErrorClassDeviceOffline
+	case pkgError.SendErrMediaFetch, pkgError.SendErrMediaUpload:
+		return ErrorClassMediaDownload
+	case pkgError.SendErrRateLimited:
+		return ErrorClassRateLimited
+	default:
+		return ErrorClassUnknown
+	}
+}
+
+// ClassifyError maps a free-text send error to an error class
+func ClassifyError(errorMessage string) string {
+	return ErrorClassForCode(pkgError.ClassifySendError(errorMessage))
+}
+
+// IsErrorClass reports whether a value is a known error class
+func IsErrorClass(value string) bool {
+	for _, class := range ErrorClasses {
+		if class == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,27 @@
+package broadcast
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		message string
+		want    string
+	}{
+		{"device abc is not logged in", ErrorClassDeviceOffline},
+		{"device not connected: websocket closed", ErrorClassDeviceOffline},
+		{"recipient 60123 is not on WhatsApp", ErrorClassInvalidNumber},
+		{"failed to download image: 404", ErrorClassMediaDownload},
+		{"429 Too Many Requests", ErrorClassRateLimited},
+		{"Max retries exceeded", ErrorClassUnknown},
+		{"", ErrorClassUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			assert.Equal(t, tt.want, ClassifyError(tt.message))
+		})
+	}
+}
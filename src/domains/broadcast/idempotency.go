@@ -0,0 +1,27 @@
+package broadcast
+
+import "fmt"
+
+// IdempotencyKey derives the key of a campaign or sequence step message
+// from its recipient, the device's chat with the phone, its campaign or
+// step, and its variant, the message's place in its group. A step message
+// also carries its contact's run, so a re-enrolled contact is sent the step
+// again. Messages that belong to neither have no key unless the caller sets
+// one.
+func IdempotencyKey(msg BroadcastMessage) string {
+	variant := 0
+	if msg.GroupOrder != nil {
+		variant = *msg.GroupOrder
+	}
+	switch {
+	case msg.SequenceStepID != nil && *msg.SequenceStepID != "":
+		key := fmt.Sprintf("step:%s:%s:%s:%d", *msg.SequenceStepID, msg.DeviceID, msg.RecipientPhone, variant)
+		if !msg.EnrolledAt.IsZero() {
+			key += fmt.Sprintf(":%d", msg.EnrolledAt.Unix())
+		}
+		return key
+	case msg.CampaignID != nil && *msg.CampaignID > 0:
+		return fmt.Sprintf("campaign:%d:%s:%s:%d", *msg.CampaignID, msg.DeviceID, msg.RecipientPhone, variant)
+	}
+	return ""
+}
@@ -0,0 +1,29 @@
+package broadcast
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotencyKey(t *testing.T) {
+	campaignID, stepID, second := 12, "step-1", 2
+	enrolledAt := time.Unix(1700000000, 0)
+	tests := []struct {
+		name string
+		msg  BroadcastMessage
+		want string
+	}{
+		{"campaign", BroadcastMessage{CampaignID: &campaignID, DeviceID: "dev", RecipientPhone: "60111"}, "campaign:12:dev:60111:0"},
+		{"sequence step", BroadcastMessage{SequenceStepID: &stepID, DeviceID: "dev", RecipientPhone: "60111"}, "step:step-1:dev:60111:0"},
+		{"sequence run", BroadcastMessage{SequenceStepID: &stepID, DeviceID: "dev", RecipientPhone: "60111", EnrolledAt: enrolledAt}, "step:step-1:dev:60111:0:1700000000"},
+		{"variant", BroadcastMessage{CampaignID: &campaignID, DeviceID: "dev", RecipientPhone: "60111", GroupOrder: &second}, "campaign:12:dev:60111:2"},
+		{"neither", BroadcastMessage{DeviceID: "dev", RecipientPhone: "60111"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IdempotencyKey(tt.msg))
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package broadcast
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Message types a broadcast message, campaign or sequence step can carry
+const (
+	MessageTypeText     = "text"
+	MessageTypeImage    = "image"
+	MessageTypeVideo    = "video"
+	MessageTypeDocument = "document"
+	MessageTypeAudio    = "audio"
+	MessageTypeLocation = "location"
+	MessageTypePoll     = "poll"
+	MessageTypeContact  = "contact"
+)
+
+// MessageTypes lists every supported message type
+var MessageTypes = []string{
+	MessageTypeText,
+	MessageTypeImage,
+	MessageTypeVideo,
+	MessageTypeDocument,
+	MessageTypeAudio,
+	MessageTypeLocation,
+	MessageTypePoll,
+	MessageTypeContact,
+}
+
+// Poll limits enforced by WhatsApp
+const (
+	MinPollOptions = 2
+	MaxPollOptions = 12
+)
+
+// MessagePayload holds the type-specific fields of a message. Content is the
+// text, caption or poll question and MediaURL the file for media types; the
+// payload carries everything else. It is stored as JSON next to the message.
+type MessagePayload struct {
+	FileName      string   `json:"filename,omitempty"`
+	MimeType      string   `json:"mimetype,omitempty"`
+	PTT           bool     `json:"ptt,omitempty"`
+	Latitude      float64  `json:"latitude,omitempty"`
+	Longitude     float64  `json:"longitude,omitempty"`
+	LocationName  string   `json:"location_name,omitempty"`
+	Address       string   `json:"address,omitempty"`
+	PollOptions   []string `json:"poll_options,omitempty"`
+	PollMaxAnswer int      `json:"poll_max_answer,omitempty"`
+	ContactName   string   `json:"contact_name,omitempty"`
+	ContactPhone  string   `json:"contact_phone,omitempty"`
+}
+
+// IsMessageType reports whether a value is a supported message type
+func IsMessageType(value string) bool {
+	for _, messageType := range MessageTypes {
+		if messageType == value {
+			return true
+		}
+	}
+	return false
+}
+
+// IsMediaType reports whether the message type needs a media URL
+func IsMediaType(messageType string) bool {
+	switch messageType {
+	case MessageTypeImage, MessageTypeVideo, MessageTypeDocument, MessageTypeAudio:
+		return true
+	}
+	return false
+}
+
+// ResolveMessageType fills in the type of legacy rows, which only stored
+// text with an optional image
+func ResolveMessageType(messageType, mediaURL string) string {
+	if messageType == "" {
+		if mediaURL != "" {
+			return MessageTypeImage
+		}
+		return MessageTypeText
+	}
+	return messageType
+}
+
+// EncodePayload serializes a payload for storage; nil and empty payloads are stored as NULL
+func EncodePayload(payload *MessagePayload) *string {
+	if payload == nil {
+		return nil
+	}
+	data, err := json.Marshal(payload)
+	if err != nil || string(data) == "{}" {
+		return nil
+	}
+	encoded := string(data)
+	return &encoded
+}
+
+// DecodePayload parses a stored payload; empty or malformed values decode to nil
+func DecodePayload(value string) *MessagePayload {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+	var payload MessagePayload
+	if err := json.Unmarshal([]byte(value), &payload); err != nil {
+		return nil
+	}
+	return &payload
+}
+
+// ValidateMessage checks that a message of the given type has the fields it needs
+func ValidateMessage(messageType, content, mediaURL string, payload *MessagePayload) error {
+	if !IsMessageType(messageType) {
+		return fmt.Errorf("unsupported message type: %s", messageType)
+	}
+	if IsMediaType(messageType) && strings.TrimSpace(mediaURL) == "" {
+		return fmt.Errorf("%s messages need a media URL", messageType)
+	}
+	if payload == nil {
+		payload = &MessagePayload{}
+	}
+
+	switch messageType {
+	case MessageTypeText:
+		if strings.TrimSpace(content) == "" {
+			return errors.New("text messages need content")
+		}
+	case MessageTypeLocation:
+		if payload.Latitude < -90 || payload.Latitude > 90 || payload.Longitude < -180 || payload.Longitude > 180 {
+			return errors.New("location coordinates are out of range")
+		}
+		if payload.Latitude == 0 && payload.Longitude == 0 {
+			return errors.New("location messages need latitude and longitude")
+		}
+	case MessageTypePoll:
+		if strings.TrimSpace(content) == "" {
+			return errors.New("poll messages need a question in content")
+		}
+		if len(payload.PollOptions) < MinPollOptions || len(payload.PollOptions) > MaxPollOptions {
+			return fmt.Errorf("poll messages need %d to %d options", MinPollOptions, MaxPollOptions)
+		}
+		seen := make(map[string]bool, len(payload.PollOptions))
+		for _, option := range payload.PollOptions {
+			option = strings.TrimSpace(option)
+			if option == "" {
+				return errors.New("poll options cannot be empty")
+			}
+			if seen[option] {
+				return fmt.Errorf("duplicate poll option: %s", option)
+			}
+			seen[option] = true
+		}
+		if payload.PollMaxAnswer < 0 || payload.PollMaxAnswer > len(payload.PollOptions) {
+			return errors.New("poll max answer must be between 0 and the number of options")
+		}
+	case MessageTypeContact:
+		if strings.TrimSpace(payload.ContactName) == "" || strings.TrimSpace(payload.ContactPhone) == "" {
+			return errors.New("contact messages need a contact name and phone")
+		}
+	}
+	return nil
+}
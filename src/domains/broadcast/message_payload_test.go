@@ -0,0 +1,49 @@
+package broadcast
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateMessage(t *testing.T) {
+	tests := []struct {
+		name        string
+		messageType string
+		content     string
+		mediaURL    string
+		payload     *MessagePayload
+		wantErr     bool
+	}{
+		{"text", MessageTypeText, "hello", "", nil, false},
+		{"empty text", MessageTypeText, " ", "", nil, true},
+		{"video", MessageTypeVideo, "", "https://example.com/a.mp4", nil, false},
+		{"document without url", MessageTypeDocument, "", "", &MessagePayload{FileName: "a.pdf"}, true},
+		{"location", MessageTypeLocation, "", "", &MessagePayload{Latitude: 3.139, Longitude: 101.6869}, false},
+		{"location without coordinates", MessageTypeLocation, "", "", nil, true},
+		{"poll", MessageTypePoll, "Pick one", "", &MessagePayload{PollOptions: []string{"A", "B"}, PollMaxAnswer: 1}, false},
+		{"poll with one option", MessageTypePoll, "Pick one", "", &MessagePayload{PollOptions: []string{"A"}}, true},
+		{"poll with duplicate options", MessageTypePoll, "Pick one", "", &MessagePayload{PollOptions: []string{"A", "A"}}, true},
+		{"contact", MessageTypeContact, "", "", &MessagePayload{ContactName: "Ali", ContactPhone: "60123456789"}, false},
+		{"unknown type", "sticker", "", "", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateMessage(tt.messageType, tt.content, tt.mediaURL, tt.payload)
+			assert.Equal(t, tt.wantErr, err != nil, "%v", err)
+		})
+	}
+}
+
+func TestPayloadRoundTrip(t *testing.T) {
+	assert.Nil(t, EncodePayload(nil))
+	assert.Nil(t, EncodePayload(&MessagePayload{}))
+	assert.Nil(t, DecodePayload(""))
+	assert.Nil(t, DecodePayload("not json"))
+
+	payload := &MessagePayload{FileName: "menu.pdf", PTT: true, PollOptions: []string{"A", "B"}}
+	encoded := EncodePayload(payload)
+	if assert.NotNil(t, encoded) {
+		assert.Equal(t, payload, DecodePayload(*encoded))
+	}
+}
@@ -14,12 +14,13 @@ type BroadcastMessage struct {
 	RecipientPhone string
 	RecipientName  string  // Name of the recipient
 	RecipientJID   string  // WhatsApp JID format
-	Type           string  // text, image, video, document
+	Type           string  // text, image, video, document, audio, location, poll, contact
 	Content        string
 	Message        string  // Alias for Content
 	MediaURL       string
 	ImageURL       string  // Alias for MediaURL
 	Caption        string
+	Payload        *MessagePayload // Type-specific fields (filename, poll options, coordinates...)
 	ScheduledAt    time.Time
 	Status         string
 	GroupID        *string // For grouping related messages (pointer to allow null)
@@ -29,6 +30,20 @@ type BroadcastMessage struct {
 	// Delay settings from campaign/sequence
 	MinDelay       int
 	MaxDelay       int
+	// TraceContext is the W3C traceparent of the span that queued the message
+	TraceContext   string
+	// IdempotencyKey identifies the send; a second message with the same key
+	// is not queued while the first is live or sent
+	IdempotencyKey string
+	// LeaseToken is the claim the worker holds on the message; a worker whose
+	// claim was taken over does not send
+	LeaseToken     string
+	// WhatsAppMessageID is recorded before the first send and reused by every
+	// resend, so WhatsApp drops a copy of a message that already went out
+	WhatsAppMessageID string
+	// EnrolledAt starts the sequence contact's current run; steps sent in an
+	// earlier run do not count as duplicates
+	EnrolledAt     time.Time
 }
 
 // WorkerStatus represents the status of a device worker
@@ -48,4 +63,22 @@ type BroadcastRequest struct {
 	DeviceID   string
 	Recipients []string
 	Message    BroadcastMessage
-}
\ No newline at end of file
+}
+// MessageRecord is a queued or sent broadcast message as the message list shows it
+type MessageRecord struct {
+	ID             string     `json:"id"`
+	DeviceID       string     `json:"device_id"`
+	DeviceName     string     `json:"device_name"`
+	CampaignID     *int       `json:"campaign_id,omitempty"`
+	SequenceID     *string    `json:"sequence_id,omitempty"`
+	SequenceStepID *string    `json:"sequence_stepid,omitempty"`
+	RecipientPhone string     `json:"recipient_phone"`
+	RecipientName  string     `json:"recipient_name"`
+	MessageType    string     `json:"message_type"`
+	Content        string     `json:"content"`
+	Status         string     `json:"status"`
+	ErrorMessage   string     `json:"error_message,omitempty"`
+	ScheduledAt    *time.Time `json:"scheduled_at"`
+	SentAt         *time.Time `json:"sent_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
@@ -0,0 +1,143 @@
+package sequence
+
+import (
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/domains/broadcast"
+)
+
+// FlowVersion is the current flow definition format
+const FlowVersion = 1
+
+// Flow node types
+const (
+	FlowNodeSend       = "send"        // queue a WhatsApp message
+	FlowNodeWait       = "wait"        // wait a fixed duration
+	FlowNodeWaitUntil  = "wait_until"  // wait until the next time of day
+	FlowNodeCondition  = "condition"   // branch on replied/read/tag/field
+	FlowNodeSplit      = "split"       // random percentage split
+	FlowNodeAddTag     = "add_tag"     // tag the lead
+	FlowNodeRemoveTag  = "remove_tag"  // untag the lead
+	FlowNodeLeadStatus = "lead_status" // move the lead's target status
+	FlowNodeWebhook    = "webhook"     // POST the lead to a URL
+	FlowNodeEnd        = "end"         // finish, optionally continuing in another sequence
+)
+
+// Flow condition kinds
+const (
+	FlowConditionReplied = "replied"
+	FlowConditionRead    = "read"
+	FlowConditionTag     = "tag"
+	FlowConditionField   = "field"
+)
+
+// Flow is a graph-based sequence definition
+type Flow struct {
+	Version    int        `json:"version"`
+	SequenceID string     `json:"sequence_id,omitempty"`
+	Name       string     `json:"name,omitempty"`
+	Start      string     `json:"start"`
+	Nodes      []FlowNode `json:"nodes"`
+	Converted  bool       `json:"converted,omitempty"` // generated from linear steps, not saved yet
+	UpdatedAt  *time.Time `json:"updated_at,omitempty"`
+}
+
+// FlowNode is a single node in a flow. Only the fields relevant to its type are used.
+type FlowNode struct {
+	ID    string        `json:"id"`
+	Type  string        `json:"type"`
+	Label string        `json:"label,omitempty"`
+	Next  string        `json:"next,omitempty"`
+	Pos   *FlowPosition `json:"position,omitempty"` // editor layout only
+
+	// send
+	StepID          string                    `json:"step_id,omitempty"` // linked sequence_steps row; its content wins when set
+	MessageType     string                    `json:"message_type,omitempty"`
+	Content         string                    `json:"content,omitempty"`
+	MediaURL        string                    `json:"media_url,omitempty"`
+	Payload         *broadcast.MessagePayload `json:"payload,omitempty"`
+	MinDelaySeconds int                       `json:"min_delay_seconds,omitempty"`
+	MaxDelaySeconds int                       `json:"max_delay_seconds,omitempty"`
+
+	// wait
+	DelayHours   int `json:"delay_hours,omitempty"`
+	DelayMinutes int `json:"delay_minutes,omitempty"`
+
+	// wait_until
+	TimeOfDay string `json:"time_of_day,omitempty"` // HH:MM in the planner timezone
+
+	// condition
+	Condition *FlowCondition `json:"condition,omitempty"`
+	TrueNext  string         `json:"true_next,omitempty"`
+	FalseNext string         `json:"false_next,omitempty"`
+
+	// split
+	Branches []FlowSplitBranch `json:"branches,omitempty"`
+
+	// add_tag, remove_tag
+	Tag string `json:"tag,omitempty"`
+
+	// lead_status
+	Status string `json:"status,omitempty"`
+
+	// webhook
+	URL string `json:"url,omitempty"`
+
+	// end
+	NextSequenceID string `json:"next_sequence_id,omitempty"`
+}
+
+// FlowCondition describes what a condition node checks
+type FlowCondition struct {
+	Kind        string `json:"kind"`                   // replied, read, tag, field
+	WithinHours int    `json:"within_hours,omitempty"` // replied/read: look back this far instead of since enrollment
+	Tag         string `json:"tag,omitempty"`
+	Field       string `json:"field,omitempty"`
+	Operator    string `json:"operator,omitempty"` // equals, not_equals, contains, empty, not_empty
+	Value       string `json:"value,omitempty"`
+}
+
+// FlowSplitBranch is one arm of a split node
+type FlowSplitBranch struct {
+	Percent int    `json:"percent"`
+	Next    string `json:"next"`
+}
+
+// FlowPosition stores where the editor drew a node
+type FlowPosition struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// FlowValidationError points at a problem in a flow definition
+type FlowValidationError struct {
+	NodeID  string `json:"node_id,omitempty"`
+	Message string `json:"message"`
+}
+
+// Node returns the node with the given ID
+func (f *Flow) Node(id string) *FlowNode {
+	for i := range f.Nodes {
+		if f.Nodes[i].ID == id {
+			return &f.Nodes[i]
+		}
+	}
+	return nil
+}
+
+// Targets returns every node ID this node can move to
+func (n *FlowNode) Targets() []string {
+	var targets []string
+	switch n.Type {
+	case FlowNodeCondition:
+		targets = append(targets, n.TrueNext, n.FalseNext)
+	case FlowNodeSplit:
+		for _, branch := range n.Branches {
+			targets = append(targets, branch.Next)
+		}
+	case FlowNodeEnd:
+	default:
+		targets = append(targets, n.Next)
+	}
+	return targets
+}
@@ -2,12 +2,17 @@ package sequence
 
 import (
 	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/domains/broadcast"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/listing"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
 )
 
 // ISequenceUsecase interface for sequence operations
 type ISequenceUsecase interface {
 	CreateSequence(request CreateSequenceRequest) (SequenceResponse, error)
 	GetSequences(userID string) ([]SequenceResponse, error)
+	ListSequences(userID string, q listing.Query) ([]SequenceResponse, utils.Page, error)
 	GetSequenceByID(sequenceID string) (SequenceDetailResponse, error)
 	UpdateSequence(sequenceID string, request UpdateSequenceRequest) error
 	DeleteSequence(sequenceID string) error
@@ -16,6 +21,7 @@ type ISequenceUsecase interface {
 	AddContactsToSequence(sequenceID string, contacts []string) error
 	RemoveContactFromSequence(sequenceID string, contactID string) error
 	GetSequenceContacts(sequenceID string) ([]SequenceContactResponse, error)
+	ListSequenceContacts(sequenceID string, q listing.Query) ([]SequenceContactResponse, utils.Page, error)
 	
 	// Execution
 	StartSequence(sequenceID string) error
@@ -56,6 +62,7 @@ type CreateSequenceStepRequest struct {
 	ImageURL          string `json:"image_url"`
 	MediaURL          string `json:"media_url"`
 	Caption           string `json:"caption"`
+	Payload           *broadcast.MessagePayload `json:"payload,omitempty"` // filename, mimetype, ptt, poll options, coordinates...
 	MinDelaySeconds   int    `json:"min_delay_seconds"`
 	MaxDelaySeconds   int    `json:"max_delay_seconds"`
 }
@@ -116,6 +123,7 @@ type SequenceStepResponse struct {
 	Content           string `json:"content"`
 	MediaURL          string `json:"media_url"`
 	Caption           string `json:"caption"`
+	Payload           *broadcast.MessagePayload `json:"payload,omitempty"` // filename, mimetype, ptt, poll options, coordinates...
 	MinDelaySeconds   int    `json:"min_delay_seconds"`
 	MaxDelaySeconds   int    `json:"max_delay_seconds"`
 }
@@ -2,12 +2,8 @@ package broadcast
 
 import (
 	"context"
-	"encoding/base64"
 	"fmt"
-	"io"
 	"math/rand"
-	"net/http"
-	"strings"
 	"sync"
 	"time"
 
@@ -15,6 +11,7 @@ import (
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/database"
 	domainBroadcast "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/broadcast"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
 	"github.com/sirupsen/logrus"
 	"go.mau.fi/whatsmeow"
@@ -87,7 +84,7 @@ func (dw *DeviceWorker) QueueMessage(msg domainBroadcast.BroadcastMessage) error
 	case dw.messageQueue <- msg:
 		return nil
 	case <-time.After(time.Second * 5):
-		return fmt.Errorf("queue full for device %s", dw.deviceID)
+		return pkgError.NewSendError(pkgError.SendErrDeviceUnavailable, nil, "queue full for device %s", dw.deviceID)
 	}
 }
 
@@ -134,6 +131,19 @@ func (dw *DeviceWorker) processMessages() {
 				continue
 			}
 			
+			// The campaign may have been paused or cancelled since the message was claimed
+			held, holdErr := dw.broadcastRepo.HoldStoppedCampaignMessage(msg.ID)
+			if holdErr != nil {
+				logrus.Errorf("Failed to check campaign of message %s: %v", msg.ID, holdErr)
+			}
+			if held {
+				logrus.Infof("Message %s held, its campaign is paused or cancelled", msg.ID)
+				dw.mu.Lock()
+				dw.status = "idle"
+				dw.mu.Unlock()
+				continue
+			}
+			
 			// Process the message
 			err := dw.sendMessage(msg)
 			
@@ -143,9 +153,7 @@ func (dw *DeviceWorker) processMessages() {
 				logrus.Errorf("Failed to send message: %v", err)
 				// Update broadcast status to failed
 				if msg.ID != "" {
-					// Direct update like skipped
-					db := database.GetDB()
-					_, updateErr := db.Exec(`UPDATE broadcast_messages SET STATUS = 'failed', error_message = ?, updated_at = NOW() WHERE id = ?`, err.Error(), msg.ID)
+					_, updateErr := dw.broadcastRepo.RetryOrFailMessage(msg.ID, err, domainBroadcast.DeadLetterSourceSQL)
 					if updateErr != nil {
 						logrus.Errorf("Failed to update message status to failed: %v", updateErr)
 					}
@@ -154,9 +162,8 @@ func (dw *DeviceWorker) processMessages() {
 				dw.processedCount++
 				// Update broadcast status to sent
 				if msg.ID != "" {
-					// Direct update like skipped
 					db := database.GetDB()
-					_, updateErr := db.Exec(`UPDATE broadcast_messages SET status = 'sent', sent_at = NOW(), updated_at = NOW() WHERE id = ? AND status IN ('processing', 'pending', 'queued')`, msg.ID)
+					_, updateErr := dw.broadcastRepo.MarkMessageSent(msg.ID)
 					if updateErr != nil {
 						logrus.Errorf("Failed to update message status to sent: %v", updateErr)
 					}
@@ -205,7 +212,7 @@ func (dw *DeviceWorker) sendMessage(msg domainBroadcast.BroadcastMessage) error
 	// Parse recipient JID
 	recipient, err := whatsapp.ParseJID(msg.RecipientPhone)
 	if err != nil {
-		return fmt.Errorf("invalid recipient: %v", err)
+		return pkgError.NewSendError(pkgError.SendErrInvalidRecipient, err, "invalid recipient")
 	}
 	
 	switch msg.Type {
@@ -213,10 +220,8 @@ func (dw *DeviceWorker) sendMessage(msg domainBroadcast.BroadcastMessage) error
 		return dw.sendTextMessage(recipient, msg)
 	case "image":
 		return dw.sendImageMessage(recipient, msg)
-	case "video":
-		return dw.sendVideoMessage(recipient, msg)
-	case "document":
-		return dw.sendDocumentMessage(recipient, msg)
+	case "video", "document", "audio", "location", "poll", "contact":
+		return dw.sendRichMessage(recipient, msg)
 	default:
 		return fmt.Errorf("unsupported message type: %s", msg.Type)
 	}
@@ -228,7 +233,7 @@ func (dw *DeviceWorker) sendTextMessage(recipient types.JID, msg domainBroadcast
 	userRepo := repository.GetUserRepository()
 	device, err := userRepo.GetDeviceByID(dw.deviceID)
 	if err != nil {
-		return fmt.Errorf("failed to get device info: %v", err)
+		return pkgError.NewSendError(pkgError.SendErrDeviceUnavailable, err, "failed to get device info")
 	}
 	
 	// If platform device, just send raw content (platform sender will handle greeting/anti-spam)
@@ -238,7 +243,7 @@ func (dw *DeviceWorker) sendTextMessage(recipient types.JID, msg domainBroadcast
 			Conversation: proto.String(msg.Content),
 		}
 		_, err := dw.client.SendMessage(context.Background(), recipient, message)
-		return err
+		return whatsmeowSendError(err, "failed to send text message")
 	}
 	
 	// For regular WhatsApp Web devices, apply greeting and anti-spam
@@ -259,75 +264,71 @@ func (dw *DeviceWorker) sendTextMessage(recipient types.JID, msg domainBroadcast
 	}
 	
 	_, err = dw.client.SendMessage(context.Background(), recipient, message)
-	return err
+	return whatsmeowSendError(err, "failed to send text message")
 }
 
 // sendImageMessage sends image message with caption
 func (dw *DeviceWorker) sendImageMessage(recipient types.JID, msg domainBroadcast.BroadcastMessage) error {
-	// Download image from URL
-	imageData, err := downloadMedia(msg.MediaURL)
-	if err != nil {
-		return fmt.Errorf("failed to download image: %v", err)
-	}
-	
-	// Upload to WhatsApp servers
-	uploaded, err := dw.client.Upload(context.Background(), imageData, whatsmeow.MediaImage)
+	// Fetched and uploaded once per device, then reused for every recipient
+	media, err := prepareMedia(dw.client, msg.UserID, msg.MediaURL, whatsmeow.MediaImage)
 	if err != nil {
-		return fmt.Errorf("failed to upload image: %v", err)
+		return err
 	}
 	
 	// Process caption with spintax (same as text messages)
 	processedCaption := ""
 	if msg.Content != "" {
-		// Check if this is a platform device
-		userRepo := repository.GetUserRepository()
-		device, err := userRepo.GetDeviceByID(dw.deviceID)
-		if err == nil && device != nil && device.Platform != "" {
-			// Platform device - just use raw content
-			processedCaption = msg.Content
-		} else {
-			// Regular WhatsApp device - apply greeting and anti-spam
-			// STEP 1: Apply randomization to CAPTION ONLY
-			randomizedCaption := dw.messageRandomizer.RandomizeMessage(msg.Content)
-			
-			// STEP 2: Add greeting to the randomized caption
-			processedCaption = dw.greetingProcessor.PrepareMessageWithGreeting(
-				randomizedCaption,
-				msg.RecipientName,
-				dw.deviceID,
-				msg.RecipientPhone,
-			)
-		}
+		processedCaption = dw.prepareCaption(msg)
 	}
 	
 	// Create image message
 	message := &waProto.Message{
-		ImageMessage: &waProto.ImageMessage{
-			Caption:       &processedCaption,
-			URL:           &uploaded.URL,
-			DirectPath:    &uploaded.DirectPath,
-			MediaKey:      uploaded.MediaKey,
-			FileEncSHA256: uploaded.FileEncSHA256,
-			FileSHA256:    uploaded.FileSHA256,
-			FileLength:    &uploaded.FileLength,
-			Mimetype:      proto.String("image/jpeg"),
-		},
+		ImageMessage: media.imageMessage(processedCaption),
 	}
 	
 	_, err = dw.client.SendMessage(context.Background(), recipient, message)
-	return err
+	return whatsmeowSendError(err, "failed to send image message")
 }
 
-// sendVideoMessage sends video message
-func (dw *DeviceWorker) sendVideoMessage(recipient types.JID, msg domainBroadcast.BroadcastMessage) error {
-	// TODO: Implement video sending
-	return fmt.Errorf("video messages not yet implemented")
+// prepareCaption applies spintax and the greeting to a media caption
+func (dw *DeviceWorker) prepareCaption(msg domainBroadcast.BroadcastMessage) string {
+	// Check if this is a platform device
+	userRepo := repository.GetUserRepository()
+	device, err := userRepo.GetDeviceByID(dw.deviceID)
+	if err == nil && device != nil && device.Platform != "" {
+		// Platform device - just use raw content
+		return msg.Content
+	}
+	
+	// Regular WhatsApp device - apply greeting and anti-spam
+	// STEP 1: Apply randomization to CAPTION ONLY
+	randomizedCaption := dw.messageRandomizer.RandomizeMessage(msg.Content)
+	
+	// STEP 2: Add greeting to the randomized caption
+	return dw.greetingProcessor.PrepareMessageWithGreeting(
+		randomizedCaption,
+		msg.RecipientName,
+		dw.deviceID,
+		msg.RecipientPhone,
+	)
 }
 
-// sendDocumentMessage sends document message
-func (dw *DeviceWorker) sendDocumentMessage(recipient types.JID, msg domainBroadcast.BroadcastMessage) error {
-	// TODO: Implement document sending
-	return fmt.Errorf("document messages not yet implemented")
+// sendRichMessage sends video, document, audio, location, poll and contact messages
+func (dw *DeviceWorker) sendRichMessage(recipient types.JID, msg domainBroadcast.BroadcastMessage) error {
+	// Captions get the same greeting and anti-spam as image captions; poll
+	// questions, location names and contacts are sent as written
+	text := msg.Content
+	if (msg.Type == "video" || msg.Type == "document") && msg.Content != "" {
+		text = dw.prepareCaption(msg)
+	}
+	
+	message, err := buildRichMessage(dw.client, &msg, text)
+	if err != nil {
+		return err
+	}
+	
+	_, err = dw.client.SendMessage(context.Background(), recipient, message)
+	return whatsmeowSendError(err, "failed to send "+msg.Type+" message")
 }
 
 // getRandomDelay returns a random delay between min and max
@@ -412,44 +413,13 @@ func (dw *DeviceWorker) IsHealthy() bool {
 	return true
 }
 
-// downloadMedia downloads media from URL or data URL
-func downloadMedia(url string) ([]byte, error) {
-	// Check if it's a data URL
-	if strings.HasPrefix(url, "data:") {
-		// Parse data URL
-		parts := strings.SplitN(url, ",", 2)
-		if len(parts) != 2 {
-			return nil, fmt.Errorf("invalid data URL format")
-		}
-		
-		// Decode base64
-		data, err := base64.StdEncoding.DecodeString(parts[1])
-		if err != nil {
-			return nil, fmt.Errorf("failed to decode base64: %v", err)
-		}
-		
-		return data, nil
-	}
-	
-	// Regular HTTP/HTTPS URL
-	resp, err := http.Get(url)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("bad status: %s", resp.Status)
-	}
-	
-	return io.ReadAll(resp.Body)
-}
 // getRandomDelayBetween returns a random delay between min and max seconds
 func getRandomDelayBetween(minDelay, maxDelay int) time.Duration {
 	if minDelay <= 0 && maxDelay <= 0 {
-		// Default delays if not set
-		minDelay = 10
-		maxDelay = 30
+		// Configured worker delays if not set
+		worker := config.Current().Worker
+		minDelay = worker.MinDelaySeconds
+		maxDelay = worker.MaxDelaySeconds
 	}
 	
 	if minDelay == maxDelay || maxDelay <= minDelay {
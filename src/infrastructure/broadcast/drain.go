@@ -0,0 +1,93 @@
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// workersRunning counts broadcast workers whose process loop has not
+	// returned
+	workersRunning int64
+
+	// handedOver holds the IDs of messages handed to workers whose status
+	// no worker has settled yet
+	handedOver sync.Map
+
+	// sending holds the IDs of messages a worker is sending right now
+	sending sync.Map
+)
+
+// ErrDraining is returned when a message is queued to a pool whose workers
+// were stopped, as Drain does
+var ErrDraining = errors.New("broadcast workers are stopping")
+
+// DrainResult lists the messages a drain left behind
+type DrainResult struct {
+	// Unsent were handed to workers and never sent; they can be sent again
+	Unsent []string `json:"unsent"`
+	// Interrupted were still being sent when the deadline passed, so
+	// whether they went out is unknown
+	Interrupted []string `json:"interrupted"`
+}
+
+// Drain stops the broadcast workers taking messages and waits, until ctx is
+// done, for the sends in progress to finish. The messages it reports still
+// say queued in the database; the caller returns them to their queue.
+func Drain(ctx context.Context) DrainResult {
+	broadcastManagerMu.Lock()
+	manager := broadcastManager
+	broadcastManagerMu.Unlock()
+	if manager != nil {
+		// Pools refuse new messages once cancelled and workers check the
+		// context before each send, so none starts a new one
+		manager.cancel()
+	}
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for atomic.LoadInt64(&workersRunning) > 0 {
+		select {
+		case <-ctx.Done():
+			return pendingHandover()
+		case <-ticker.C:
+		}
+	}
+	return pendingHandover()
+}
+
+// settle forgets a message whose status a worker has written
+func settle(messageID string) {
+	handedOver.Delete(messageID)
+	sending.Delete(messageID)
+}
+
+// forgetHandover drops every message tracked for a drain
+func forgetHandover() {
+	handedOver.Range(func(key, _ interface{}) bool {
+		handedOver.Delete(key)
+		return true
+	})
+}
+
+// pendingHandover splits the messages not settled yet into those never sent
+// and those still being sent
+func pendingHandover() DrainResult {
+	result := DrainResult{Unsent: []string{}, Interrupted: []string{}}
+	handedOver.Range(func(key, _ interface{}) bool {
+		id := key.(string)
+		if _, ok := sending.Load(id); ok {
+			result.Interrupted = append(result.Interrupted, id)
+		} else {
+			result.Unsent = append(result.Unsent, id)
+		}
+		return true
+	})
+	sort.Strings(result.Unsent)
+	sort.Strings(result.Interrupted)
+	return result
+}
@@ -1,6 +1,8 @@
 package broadcast
 
 import (
+	"sync"
+
 	domainBroadcast "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/broadcast"
 )
 
@@ -14,4 +16,36 @@ type IBroadcastManager interface {
 	StopWorker(deviceID string) error
 	ResumeFailedWorkers() error
 	CheckWorkerHealth()
-}
\ No newline at end of file
+}
+
+// MessageSender delivers one broadcast message from a device. deviceID is
+// the device name stored on broadcast_messages.
+type MessageSender interface {
+	SendMessage(deviceID string, msg *domainBroadcast.BroadcastMessage) error
+}
+
+var (
+	senderFactoryMu sync.RWMutex
+	senderFactory   = func() MessageSender { return NewWhatsAppMessageSender() }
+)
+
+// SetMessageSenderFactory replaces the sender given to new broadcast workers
+// and returns a function that restores the previous one. Workers already
+// running keep their sender.
+func SetMessageSenderFactory(factory func() MessageSender) (restore func()) {
+	senderFactoryMu.Lock()
+	previous := senderFactory
+	senderFactory = factory
+	senderFactoryMu.Unlock()
+	return func() {
+		senderFactoryMu.Lock()
+		senderFactory = previous
+		senderFactoryMu.Unlock()
+	}
+}
+
+func newMessageSender() MessageSender {
+	senderFactoryMu.RLock()
+	defer senderFactoryMu.RUnlock()
+	return senderFactory()
+}
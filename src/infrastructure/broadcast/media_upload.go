@@ -0,0 +1,84 @@
+package broadcast
+
+import (
+	"context"
+	"errors"
+
+	infraMedia "github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/media"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"google.golang.org/protobuf/proto"
+)
+
+// preparedMedia is a media file uploaded by a device and ready to attach to a message
+type preparedMedia struct {
+	asset     *repository.MediaAsset
+	upload    whatsmeow.UploadResponse
+	thumbnail []byte
+}
+
+// prepareMedia resolves a media URL through the asset library and returns
+// the device's upload of it. The file is fetched and uploaded once, then
+// reused for every recipient until the upload expires.
+func prepareMedia(client *whatsmeow.Client, userID, mediaURL string, mediaType whatsmeow.MediaType) (*preparedMedia, error) {
+	library, err := infraMedia.GetLibrary()
+	if err != nil {
+		return nil, pkgError.NewSendError(pkgError.SendErrMediaFetch, err, "media library unavailable")
+	}
+
+	ctx := context.Background()
+	asset, data, err := library.Resolve(ctx, userID, mediaURL)
+	if err != nil {
+		if errors.Is(err, infraMedia.ErrAssetNotFound) {
+			return nil, pkgError.NewSendError(pkgError.SendErrProvider4xx, err, "failed to resolve media")
+		}
+		return nil, pkgError.NewSendError(pkgError.SendErrMediaFetch, err, "failed to download media")
+	}
+
+	uploaded, err := library.Upload(ctx, client, asset, data, mediaType)
+	if err != nil {
+		if errors.Is(err, infraMedia.ErrContentUnavailable) {
+			return nil, pkgError.NewSendError(pkgError.SendErrMediaFetch, err, "failed to load media")
+		}
+		return nil, whatsmeowSendError(err, "failed to upload media")
+	}
+
+	prepared := &preparedMedia{asset: asset, upload: uploaded}
+	if mediaType == whatsmeow.MediaImage {
+		prepared.thumbnail = library.Thumbnail(ctx, asset)
+	}
+	return prepared, nil
+}
+
+// fileLength prefers the length WhatsApp reported for the upload
+func (m *preparedMedia) fileLength() uint64 {
+	if m.upload.FileLength > 0 {
+		return m.upload.FileLength
+	}
+	return uint64(m.asset.Size)
+}
+
+// imageMessage builds an image message with the asset's real mimetype,
+// dimensions and thumbnail
+func (m *preparedMedia) imageMessage(caption string) *waE2E.ImageMessage {
+	image := &waE2E.ImageMessage{
+		URL:           proto.String(m.upload.URL),
+		DirectPath:    proto.String(m.upload.DirectPath),
+		MediaKey:      m.upload.MediaKey,
+		Mimetype:      proto.String(m.asset.MimeType),
+		FileEncSHA256: m.upload.FileEncSHA256,
+		FileSHA256:    m.upload.FileSHA256,
+		FileLength:    proto.Uint64(m.fileLength()),
+		JPEGThumbnail: m.thumbnail,
+	}
+	if caption != "" {
+		image.Caption = proto.String(caption)
+	}
+	if m.asset.Width > 0 && m.asset.Height > 0 {
+		image.Width = proto.Uint32(uint32(m.asset.Width))
+		image.Height = proto.Uint32(uint32(m.asset.Height))
+	}
+	return image
+}
@@ -3,6 +3,7 @@ package broadcast
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sync"
 	"sync/atomic"
@@ -11,6 +12,7 @@ import (
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
 	domainBroadcast "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/broadcast"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
 	"github.com/go-redis/redis/v8"
 	"github.com/sirupsen/logrus"
@@ -184,7 +186,7 @@ func (rm *RedisOptimizedBroadcastManager) processQueue(queueKey string) {
 		if err := rm.processMessage(redisMsg); err != nil {
 			logrus.Errorf("Failed to process message: %v", err)
 			// Add to retry queue
-			rm.retryMessage(redisMsg)
+			rm.retryMessage(redisMsg, err)
 		}
 	}
 }
@@ -310,18 +312,17 @@ func (rm *RedisOptimizedBroadcastManager) checkRateLimit(deviceID string) bool {
 */
 
 // retryMessage adds message back to queue with exponential backoff
-func (rm *RedisOptimizedBroadcastManager) retryMessage(redisMsg RedisMessage) {
-	redisMsg.Retries++
-	
-	// Max 3 retries
-	if redisMsg.Retries > 3 {
+func (rm *RedisOptimizedBroadcastManager) retryMessage(redisMsg RedisMessage, sendErr error) {
+	// Retry count and backoff depend on the error class
+	policy := pkgError.RetryPolicyFor(pkgError.SendErrorCodeOf(sendErr))
+	if !policy.ShouldRetry(redisMsg.Retries) {
 		// Move to dead letter queue
-		rm.moveToDeadLetter(redisMsg)
+		rm.moveToDeadLetter(redisMsg, sendErr)
 		return
 	}
 	
-	// Calculate backoff delay (1min, 4min, 9min)
-	delay := time.Duration(redisMsg.Retries*redisMsg.Retries) * time.Minute
+	redisMsg.Retries++
+	delay := policy.Backoff(redisMsg.Retries)
 	
 	// Re-add to queue with future timestamp
 	redisMsg.Timestamp = time.Now().Add(delay)
@@ -340,15 +341,19 @@ func (rm *RedisOptimizedBroadcastManager) retryMessage(redisMsg RedisMessage) {
 	})
 }
 
-// moveToDeadLetter moves failed message to dead letter queue
-func (rm *RedisOptimizedBroadcastManager) moveToDeadLetter(redisMsg RedisMessage) {
-	data, _ := json.Marshal(redisMsg)
-	rm.redisClient.LPush(rm.ctx, deadLetterKey, string(data))
+// moveToDeadLetter marks the message failed and records it in the dead letter store
+func (rm *RedisOptimizedBroadcastManager) moveToDeadLetter(redisMsg RedisMessage, sendErr error) {
 	rm.incrementMetric("messages:dead_letter")
 	
-	// Update message status in database
+	failure := errors.New("max retries exceeded")
+	if sendErr != nil {
+		failure = fmt.Errorf("max retries exceeded: %w", sendErr)
+	}
+	
 	repo := repository.GetBroadcastRepository()
-	repo.UpdateMessageStatus(redisMsg.Message.ID, "failed", "Max retries exceeded")
+	if err := repo.MarkMessageFailed(redisMsg.Message.ID, failure, domainBroadcast.DeadLetterSourceRedis, redisMsg.Retries); err != nil {
+		logrus.Errorf("Failed to mark message %s as failed: %v", redisMsg.Message.ID, err)
+	}
 }
 
 // monitorWorkers monitors worker health
@@ -397,7 +402,8 @@ func (rm *RedisOptimizedBroadcastManager) checkWorkerHealth() {
 	}
 }
 
-// cleanupDeadLetters periodically processes dead letter queue
+// cleanupDeadLetters periodically drains the legacy Redis dead letter list
+// into the dead letter store so those messages can be requeued
 func (rm *RedisOptimizedBroadcastManager) cleanupDeadLetters() {
 	ticker := time.NewTicker(5 * time.Minute)
 	defer ticker.Stop()
@@ -407,12 +413,26 @@ func (rm *RedisOptimizedBroadcastManager) cleanupDeadLetters() {
 		case <-rm.ctx.Done():
 			return
 		case <-ticker.C:
-			// Process old dead letters (could implement retry logic here)
-			count, _ := rm.redisClient.LLen(rm.ctx, deadLetterKey).Result()
-			if count > 1000 {
-				// Trim old messages
-				rm.redisClient.LTrim(rm.ctx, deadLetterKey, 0, 999)
-			}
+			rm.drainDeadLetters()
+		}
+	}
+}
+
+// drainDeadLetters moves entries from the Redis dead letter list to the store
+func (rm *RedisOptimizedBroadcastManager) drainDeadLetters() {
+	store := repository.GetDeadLetterRepository()
+	for i := 0; i < 1000; i++ {
+		data, err := rm.redisClient.RPop(rm.ctx, deadLetterKey).Result()
+		if err != nil {
+			return
+		}
+		
+		var redisMsg RedisMessage
+		if err := json.Unmarshal([]byte(data), &redisMsg); err != nil {
+			continue
+		}
+		if err := store.Record(redisMsg.Message.ID, "Max retries exceeded", "", domainBroadcast.DeadLetterSourceRedis, redisMsg.Retries); err != nil {
+			logrus.Warnf("Failed to record dead letter %s: %v", redisMsg.Message.ID, err)
 		}
 	}
 }
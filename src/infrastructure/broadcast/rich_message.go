@@ -0,0 +1,173 @@
+package broadcast
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+
+	domainBroadcast "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/broadcast"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	platform "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/external"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"google.golang.org/protobuf/proto"
+)
+
+// isRichMessageType reports whether the type is sent through buildRichMessage
+// rather than the senders' own text and image paths
+func isRichMessageType(messageType string) bool {
+	switch messageType {
+	case domainBroadcast.MessageTypeVideo, domainBroadcast.MessageTypeDocument, domainBroadcast.MessageTypeAudio,
+		domainBroadcast.MessageTypeLocation, domainBroadcast.MessageTypePoll, domainBroadcast.MessageTypeContact:
+		return true
+	}
+	return false
+}
+
+// mediaURLOf returns the message's media URL, whichever alias it was set on
+func mediaURLOf(msg *domainBroadcast.BroadcastMessage) string {
+	if msg.MediaURL != "" {
+		return msg.MediaURL
+	}
+	return msg.ImageURL
+}
+
+// buildRichMessage builds the WhatsApp message for video, document, audio,
+// location, poll and contact broadcasts. text is the already processed
+// content: the caption, poll question or fallback location name.
+func buildRichMessage(client *whatsmeow.Client, msg *domainBroadcast.BroadcastMessage, text string) (*waE2E.Message, error) {
+	payload := msg.Payload
+	if payload == nil {
+		payload = &domainBroadcast.MessagePayload{}
+	}
+	if err := domainBroadcast.ValidateMessage(msg.Type, text, mediaURLOf(msg), payload); err != nil {
+		return nil, pkgError.NewSendError(pkgError.SendErrProvider4xx, err, "invalid %s message", msg.Type)
+	}
+
+	switch msg.Type {
+	case domainBroadcast.MessageTypeVideo, domainBroadcast.MessageTypeDocument, domainBroadcast.MessageTypeAudio:
+		return buildMediaMessage(client, msg, text, payload)
+
+	case domainBroadcast.MessageTypeLocation:
+		name := payload.LocationName
+		if name == "" {
+			name = text
+		}
+		return &waE2E.Message{LocationMessage: &waE2E.LocationMessage{
+			DegreesLatitude:  proto.Float64(payload.Latitude),
+			DegreesLongitude: proto.Float64(payload.Longitude),
+			Name:             proto.String(name),
+			Address:          proto.String(payload.Address),
+		}}, nil
+
+	case domainBroadcast.MessageTypePoll:
+		// A max answer of 0 lets the recipient pick any number of options
+		return client.BuildPollCreation(text, payload.PollOptions, payload.PollMaxAnswer), nil
+
+	case domainBroadcast.MessageTypeContact:
+		vcard := fmt.Sprintf("BEGIN:VCARD\nVERSION:3.0\nN:;%v;;;\nFN:%v\nTEL;type=CELL;waid=%v:+%v\nEND:VCARD",
+			payload.ContactName, payload.ContactName, payload.ContactPhone, payload.ContactPhone)
+		return &waE2E.Message{ContactMessage: &waE2E.ContactMessage{
+			DisplayName: proto.String(payload.ContactName),
+			Vcard:       proto.String(vcard),
+		}}, nil
+	}
+	return nil, pkgError.NewSendError(pkgError.SendErrProvider4xx, nil, "unsupported message type: %s", msg.Type)
+}
+
+// buildMediaMessage attaches a video, document or audio file from the media library
+func buildMediaMessage(client *whatsmeow.Client, msg *domainBroadcast.BroadcastMessage, caption string, payload *domainBroadcast.MessagePayload) (*waE2E.Message, error) {
+	mediaType := whatsmeow.MediaDocument
+	switch msg.Type {
+	case domainBroadcast.MessageTypeVideo:
+		mediaType = whatsmeow.MediaVideo
+	case domainBroadcast.MessageTypeAudio:
+		mediaType = whatsmeow.MediaAudio
+	}
+
+	media, err := prepareMedia(client, msg.UserID, mediaURLOf(msg), mediaType)
+	if err != nil {
+		return nil, err
+	}
+	uploaded := media.upload
+
+	mimeType := payload.MimeType
+	if mimeType == "" {
+		mimeType = media.asset.MimeType
+		if msg.Type == domainBroadcast.MessageTypeAudio && payload.PTT {
+			mimeType = "audio/ogg; codecs=opus"
+		}
+	}
+
+	switch msg.Type {
+	case domainBroadcast.MessageTypeVideo:
+		return &waE2E.Message{VideoMessage: &waE2E.VideoMessage{
+			Caption:       proto.String(caption),
+			URL:           proto.String(uploaded.URL),
+			DirectPath:    proto.String(uploaded.DirectPath),
+			MediaKey:      uploaded.MediaKey,
+			Mimetype:      proto.String(mimeType),
+			FileEncSHA256: uploaded.FileEncSHA256,
+			FileSHA256:    uploaded.FileSHA256,
+			FileLength:    proto.Uint64(media.fileLength()),
+		}}, nil
+
+	case domainBroadcast.MessageTypeAudio:
+		return &waE2E.Message{AudioMessage: &waE2E.AudioMessage{
+			URL:           proto.String(uploaded.URL),
+			DirectPath:    proto.String(uploaded.DirectPath),
+			MediaKey:      uploaded.MediaKey,
+			Mimetype:      proto.String(mimeType),
+			FileEncSHA256: uploaded.FileEncSHA256,
+			FileSHA256:    uploaded.FileSHA256,
+			FileLength:    proto.Uint64(media.fileLength()),
+			PTT:           proto.Bool(payload.PTT),
+		}}, nil
+	}
+
+	fileName := payload.FileName
+	if fileName == "" {
+		fileName = media.asset.FileName
+	}
+	if fileName == "" {
+		fileName = fileNameFromURL(mediaURLOf(msg))
+	}
+	return &waE2E.Message{DocumentMessage: &waE2E.DocumentMessage{
+		URL:           proto.String(uploaded.URL),
+		DirectPath:    proto.String(uploaded.DirectPath),
+		MediaKey:      uploaded.MediaKey,
+		Mimetype:      proto.String(mimeType),
+		Title:         proto.String(fileName),
+		FileName:      proto.String(fileName),
+		Caption:       proto.String(caption),
+		FileEncSHA256: uploaded.FileEncSHA256,
+		FileSHA256:    uploaded.FileSHA256,
+		FileLength:    proto.Uint64(media.fileLength()),
+	}}, nil
+}
+
+// fileNameFromURL names a document after the last path segment of its URL
+func fileNameFromURL(mediaURL string) string {
+	parsed, err := url.Parse(mediaURL)
+	if err != nil || parsed.Scheme == "data" {
+		return "document"
+	}
+	name := path.Base(parsed.Path)
+	if name == "" || name == "." || name == "/" {
+		return "document"
+	}
+	return name
+}
+
+// platformRichMessage converts a broadcast message for the external platform sender
+func platformRichMessage(msg *domainBroadcast.BroadcastMessage) platform.RichMessage {
+	rich := platform.RichMessage{
+		Type:     msg.Type,
+		Caption:  msg.Message,
+		MediaURL: mediaURLOf(msg),
+	}
+	if msg.Payload != nil {
+		rich.FileName = msg.Payload.FileName
+	}
+	return rich
+}
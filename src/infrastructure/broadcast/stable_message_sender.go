@@ -2,10 +2,7 @@ package broadcast
 
 import (
 	"context"
-	"encoding/base64"
 	"fmt"
-	"io"
-	"net/http"
 	"strings"
 	"time"
 	
@@ -48,6 +45,9 @@ func (s *StableMessageSender) SendMessage(deviceID string, msg *broadcast.Broadc
 	// Platform devices - always stable
 	if device.Platform != "" {
 		logrus.Infof("Sending via platform %s (always stable)", device.Platform)
+		if isRichMessageType(msg.Type) {
+			return s.platformSender.SendRichMessage(device.Platform, device.JID, msg.RecipientPhone, platformRichMessage(msg))
+		}
 		return s.platformSender.SendMessage(
 			device.Platform,
 			device.JID,
@@ -90,18 +90,21 @@ func (s *StableMessageSender) SendMessage(deviceID string, msg *broadcast.Broadc
 		time.Sleep(1 * time.Second)
 	}
 	
-	// Parse recipient
-	recipientJID, err := types.ParseJID(msg.RecipientPhone + "@s.whatsapp.net")
+	// Parse recipient; group campaigns queue the group's JID as is
+	recipient := msg.RecipientPhone
+	if !strings.ContainsRune(recipient, '@') {
+		recipient += "@s.whatsapp.net"
+	}
+	recipientJID, err := types.ParseJID(recipient)
 	if err != nil {
-		recipientJID, err = types.ParseJID(msg.RecipientPhone)
-		if err != nil {
-			return fmt.Errorf("invalid recipient: %v", err)
-		}
+		return fmt.Errorf("invalid recipient: %v", err)
 	}
 	
 	// Send the message - NO DELAYS, MAXIMUM SPEED
 	if msg.Type == "image" && msg.ImageURL != "" {
 		return s.sendImageMaxSpeed(waClient, recipientJID, msg)
+	} else if isRichMessageType(msg.Type) {
+		return s.sendRichMaxSpeed(waClient, recipientJID, msg)
 	} else {
 		return s.sendTextMaxSpeed(waClient, recipientJID, msg)
 	}
@@ -124,44 +127,33 @@ func (s *StableMessageSender) sendTextMaxSpeed(waClient *whatsmeow.Client, recip
 	return nil
 }
 
-// sendImageMaxSpeed sends image at maximum speed
-func (s *StableMessageSender) sendImageMaxSpeed(waClient *whatsmeow.Client, recipient types.JID, msg *broadcast.BroadcastMessage) error {
-	// Quick image handling - no validation
-	var imageData []byte
+// sendRichMaxSpeed sends video, document, audio, location, poll and contact messages
+func (s *StableMessageSender) sendRichMaxSpeed(waClient *whatsmeow.Client, recipient types.JID, msg *broadcast.BroadcastMessage) error {
+	message, err := buildRichMessage(waClient, msg, msg.Message)
+	if err != nil {
+		return err
+	}
 	
-	if strings.HasPrefix(msg.ImageURL, "data:") {
-		parts := strings.SplitN(msg.ImageURL, ",", 2)
-		if len(parts) == 2 {
-			imageData, _ = base64.StdEncoding.DecodeString(parts[1])
-		}
-	} else {
-		resp, err := http.Get(msg.ImageURL)
-		if err != nil {
-			return err
-		}
-		defer resp.Body.Close()
-		imageData, _ = io.ReadAll(resp.Body)
+	resp, err := waClient.SendMessage(context.Background(), recipient, message)
+	if err != nil {
+		logrus.Errorf("Send failed for %s: %v - device might be banned", recipient, err)
+		return err
 	}
 	
-	// Upload fast
-	uploaded, err := waClient.Upload(context.Background(), imageData, whatsmeow.MediaImage)
+	logrus.Debugf("%s message sent at MAX SPEED to %s (ID: %s)", msg.Type, recipient, resp.ID)
+	return nil
+}
+
+// sendImageMaxSpeed sends image at maximum speed
+func (s *StableMessageSender) sendImageMaxSpeed(waClient *whatsmeow.Client, recipient types.JID, msg *broadcast.BroadcastMessage) error {
+	// Fetched and uploaded once per device, then reused for every recipient
+	media, err := prepareMedia(waClient, msg.UserID, msg.ImageURL, whatsmeow.MediaImage)
 	if err != nil {
 		return err
 	}
 	
 	// Send immediately
-	message := &waE2E.Message{
-		ImageMessage: &waE2E.ImageMessage{
-			Caption:       proto.String(msg.Message),
-			URL:           proto.String(uploaded.URL),
-			DirectPath:    proto.String(uploaded.DirectPath),
-			MediaKey:      uploaded.MediaKey,
-			FileEncSHA256: uploaded.FileEncSHA256,
-			FileSHA256:    uploaded.FileSHA256,
-			FileLength:    proto.Uint64(uint64(len(imageData))),
-			Mimetype:      proto.String("image/jpeg"),
-		},
-	}
+	message := &waE2E.Message{ImageMessage: media.imageMessage(msg.Message)}
 	
 	resp, err := waClient.SendMessage(context.Background(), recipient, message)
 	if err != nil {
@@ -2,6 +2,7 @@ package broadcast
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/rand"
 	"sync"
@@ -12,8 +13,14 @@ import (
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/database"
 	domainBroadcast "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/broadcast"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/antipattern"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/clock"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/metrics"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/tracing"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
 	"github.com/go-redis/redis/v8"
 	"github.com/sirupsen/logrus"
+	"go.mau.fi/whatsmeow"
 )
 
 // DeviceWorkerGroup manages multiple workers for a single device
@@ -22,6 +29,7 @@ type DeviceWorkerGroup struct {
 	workers       []*BroadcastWorker
 	messageQueue  chan *domainBroadcast.BroadcastMessage
 	currentWorker int32 // For round-robin distribution
+	inFlight      int64 // Messages queued to the group and not yet processed
 	mu            sync.RWMutex
 	
 	// Rate limiting - ensures sequential sending
@@ -55,7 +63,7 @@ type BroadcastWorker struct {
 	workerID      int // Worker number within device group
 	broadcastID   string
 	broadcastType string
-	messageSender *WhatsAppMessageSender // Real WhatsApp sender
+	messageSender MessageSender          // Real WhatsApp sender unless replaced
 	pool          *BroadcastWorkerPool   // Reference to parent pool
 	
 	// Message processing
@@ -72,8 +80,12 @@ type BroadcastWorker struct {
 
 // Global manager instance
 var (
-	broadcastManager     *UltraScaleBroadcastManager
-	broadcastManagerOnce sync.Once
+	broadcastManager   *UltraScaleBroadcastManager
+	broadcastManagerMu sync.Mutex
+	
+	// messagesInFlight counts messages handed to workers that have not
+	// finished processing
+	messagesInFlight int64
 )
 
 // UltraScaleBroadcastManager manages broadcast pools for 3000+ devices
@@ -121,7 +133,10 @@ func NewUltraScaleBroadcastManager(redisClient *redis.Client) *UltraScaleBroadca
 
 // GetBroadcastManager returns the singleton broadcast manager
 func GetBroadcastManager() *UltraScaleBroadcastManager {
-	broadcastManagerOnce.Do(func() {
+	broadcastManagerMu.Lock()
+	defer broadcastManagerMu.Unlock()
+	
+	if broadcastManager == nil {
 		// Try to get Redis client, but work without it
 		var redisClient *redis.Client
 		if config.RedisURL != "" {
@@ -140,11 +155,59 @@ func GetBroadcastManager() *UltraScaleBroadcastManager {
 		
 		broadcastManager = NewUltraScaleBroadcastManager(redisClient)
 		logrus.Info("Ultra-scale broadcast manager initialized for 3000+ devices")
-	})
+	}
 	
 	return broadcastManager
 }
 
+// ShutdownBroadcastManager stops every pool of the singleton manager and
+// drops it along with the messages a drain would hand back; the next
+// GetBroadcastManager starts a fresh one
+func ShutdownBroadcastManager() {
+	broadcastManagerMu.Lock()
+	manager := broadcastManager
+	broadcastManager = nil
+	broadcastManagerMu.Unlock()
+	
+	if manager == nil {
+		return
+	}
+	
+	manager.mu.Lock()
+	for poolKey, pool := range manager.pools {
+		pool.Shutdown()
+		delete(manager.pools, poolKey)
+	}
+	manager.mu.Unlock()
+	manager.cancel()
+	forgetHandover()
+}
+
+// MessagesInFlight returns the number of messages handed to workers that
+// have not finished processing
+func MessagesInFlight() int64 {
+	return atomic.LoadInt64(&messagesInFlight)
+}
+
+// ActiveDeviceGroups returns the number of device worker groups that still
+// have messages in flight
+func (m *UltraScaleBroadcastManager) ActiveDeviceGroups() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	
+	active := 0
+	for _, pool := range m.pools {
+		pool.mu.RLock()
+		for _, group := range pool.deviceGroups {
+			if atomic.LoadInt64(&group.inFlight) > 0 {
+				active++
+			}
+		}
+		pool.mu.RUnlock()
+	}
+	return active
+}
+
 // GetOrCreatePool gets or creates a broadcast-specific worker pool
 func (m *UltraScaleBroadcastManager) GetOrCreatePool(broadcastType string, broadcastID string) (*BroadcastWorkerPool, error) {
 	poolKey := fmt.Sprintf("%s:%s", broadcastType, broadcastID)
@@ -218,6 +281,9 @@ func (m *UltraScaleBroadcastManager) QueueMessage(msg *domainBroadcast.Broadcast
 
 // QueueMessage adds a message to the broadcast pool with better timeout handling
 func (bwp *BroadcastWorkerPool) QueueMessage(msg *domainBroadcast.BroadcastMessage) error {
+	if bwp.ctx.Err() != nil {
+		return ErrDraining
+	}
 	atomic.AddInt64(&bwp.totalMessages, 1)
 
 	// Get or create device worker group - use DeviceName (not DeviceID)
@@ -226,6 +292,9 @@ func (bwp *BroadcastWorkerPool) QueueMessage(msg *domainBroadcast.BroadcastMessa
 	// Queue to group with increased timeout (30 seconds instead of 5)
 	select {
 	case group.messageQueue <- msg:
+		atomic.AddInt64(&group.inFlight, 1)
+		atomic.AddInt64(&messagesInFlight, 1)
+		handedOver.Store(msg.ID, struct{}{})
 		// Update message status to queued
 		db := database.GetDB()
 		_, err := db.Exec(`UPDATE broadcast_messages SET STATUS = 'queued' WHERE id = ? AND status IN ('pending', 'processing')`, msg.ID)
@@ -276,7 +345,7 @@ func (bwp *BroadcastWorkerPool) getOrCreateWorkerGroup(deviceID string) *DeviceW
 			workerID:      i,
 			broadcastID:   bwp.broadcastID,
 			broadcastType: bwp.broadcastType,
-			messageSender: NewWhatsAppMessageSender(),
+			messageSender: newMessageSender(),
 			pool:          bwp,
 			status:        "idle",
 			ctx:           ctx,
@@ -287,6 +356,7 @@ func (bwp *BroadcastWorkerPool) getOrCreateWorkerGroup(deviceID string) *DeviceW
 		group.workers = append(group.workers, worker)
 		
 		// Start worker
+		atomic.AddInt64(&workersRunning, 1)
 		go worker.process(group.messageQueue)
 		
 		logrus.Infof("Started worker %d for device %s in %s %s", 
@@ -303,6 +373,7 @@ func (bwp *BroadcastWorkerPool) getOrCreateWorkerGroup(deviceID string) *DeviceW
 
 // process handles messages for this worker from the shared device queue
 func (bw *BroadcastWorker) process(messageQueue <-chan *domainBroadcast.BroadcastMessage) {
+	defer atomic.AddInt64(&workersRunning, -1)
 	logrus.Infof("Worker %d started for device %s in %s", 
 		bw.workerID, bw.deviceID, bw.poolID)
 	
@@ -325,6 +396,8 @@ func (bw *BroadcastWorker) process(messageQueue <-chan *domainBroadcast.Broadcas
 
 // processMessage sends a single message with rate limiting
 func (bw *BroadcastWorker) processMessage(msg *domainBroadcast.BroadcastMessage) {
+	defer atomic.AddInt64(&messagesInFlight, -1)
+	
 	bw.mu.Lock()
 	bw.status = "processing"
 	bw.lastActivity = time.Now()
@@ -339,6 +412,7 @@ func (bw *BroadcastWorker) processMessage(msg *domainBroadcast.BroadcastMessage)
 		logrus.Errorf("Worker %d: Device group not found for %s", bw.workerID, bw.deviceID)
 		return
 	}
+	defer atomic.AddInt64(&group.inFlight, -1)
 	
 	// Log which broadcast this message belongs to
 	broadcastInfo := "Unknown broadcast"
@@ -351,11 +425,12 @@ func (bw *BroadcastWorker) processMessage(msg *domainBroadcast.BroadcastMessage)
 	// CRITICAL: Acquire send permission (this enforces rate limiting)
 	minDelay := msg.MinDelay
 	maxDelay := msg.MaxDelay
+	worker := config.Current().Worker
 	if minDelay <= 0 {
-		minDelay = 5  // Default minimum
+		minDelay = worker.MinDelaySeconds // Configured minimum
 	}
 	if maxDelay <= 0 {
-		maxDelay = 15 // Default maximum
+		maxDelay = worker.MaxDelaySeconds // Configured maximum
 	}
 	
 	// SAFETY CHECK: Verify message wasn't already sent
@@ -364,18 +439,71 @@ func (bw *BroadcastWorker) processMessage(msg *domainBroadcast.BroadcastMessage)
 	err := db.QueryRow("SELECT status FROM broadcast_messages WHERE id = ?", msg.ID).Scan(&currentStatus)
 	if err == nil && currentStatus == "sent" {
 		logrus.Warnf("Worker %d: Message %s already sent, skipping duplicate send", bw.workerID, msg.ID)
+		settle(msg.ID)
 		return
 	}
 	
 	// This will block until it's this worker's turn to send
 	group.acquireSendPermission(minDelay, maxDelay)
 	
+	// A pool shut down while this worker waited leaves the message queued.
+	// The message counts as sending before the check, so a drain that
+	// cancelled the pool never hands back a message that goes out.
+	sending.Store(msg.ID, struct{}{})
+	if bw.ctx.Err() != nil {
+		sending.Delete(msg.ID)
+		group.sendMutex.Unlock()
+		return
+	}
+	
+	// The campaign may have been paused or cancelled while the message waited
+	held, err := repository.GetBroadcastRepository().HoldStoppedCampaignMessage(msg.ID)
+	if err != nil {
+		logrus.Errorf("Worker %d: failed to check campaign of message %s: %v", bw.workerID, msg.ID, err)
+	}
+	if held {
+		logrus.Infof("Worker %d: message %s held, its campaign is paused or cancelled", bw.workerID, msg.ID)
+		settle(msg.ID)
+		group.sendMutex.Unlock()
+		return
+	}
+	
+	// Record the WhatsApp message ID before sending; a resend after a crash
+	// reuses it, so WhatsApp drops the copy of a message that went out
+	whatsappMessageID, err := repository.GetBroadcastRepository().ReserveWhatsAppMessageID(msg.ID, msg.LeaseToken, string(whatsmeow.GenerateMessageID()))
+	if errors.Is(err, repository.ErrLeaseLost) {
+		logrus.Warnf("Worker %d: message %s was claimed again since this worker took it, not sending", bw.workerID, msg.ID)
+		settle(msg.ID)
+		group.sendMutex.Unlock()
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Worker %d: failed to record the WhatsApp message ID of %s: %v", bw.workerID, msg.ID, err)
+	} else {
+		msg.WhatsAppMessageID = whatsappMessageID
+	}
+	
 	// Now we have exclusive permission to send
 	logrus.Debugf("Worker %d on device %s sending message %s for %s to %s", 
 		bw.workerID, bw.deviceID, msg.ID, broadcastInfo, msg.RecipientPhone)
 	
-	// Send via WhatsApp
+	// Continue the trace started when the message was queued
+	ctx := tracing.ContextWithTraceparent(context.Background(), msg.TraceContext)
+	ctx, span := tracing.Start(ctx, "message.process", tracing.KindConsumer,
+		tracing.String("message.id", msg.ID), tracing.String("device.id", bw.deviceID),
+		tracing.String("message.type", msg.Type), tracing.Int("worker.id", int64(bw.workerID)))
+	defer span.End()
+	
+	// Send via WhatsApp; the sender links the WhatsApp message ID to this span for receipts
+	_, sendSpan := tracing.Start(ctx, "whatsapp.send", tracing.KindClient, tracing.String("device.id", bw.deviceID))
+	if sendSpan != nil {
+		msg.TraceContext = sendSpan.Traceparent()
+	}
+	started := time.Now()
 	sendErr := bw.sendWhatsAppMessage(msg)
+	metrics.SendDuration.Observe(time.Since(started).Seconds(), bw.deviceID, msg.Type)
+	sendSpan.RecordError(sendErr)
+	sendSpan.End()
 	
 	// IMPORTANT: Release permission after sending
 	group.releaseSendPermission()
@@ -387,9 +515,23 @@ func (bw *BroadcastWorker) processMessage(msg *domainBroadcast.BroadcastMessage)
 		if bw.pool != nil {
 			atomic.AddInt64(&bw.pool.failedCount, 1)
 		}
-		// Update status to failed
-		db2.Exec(`UPDATE broadcast_messages SET STATUS = 'failed', error_message = ?, updated_at = NOW() WHERE id = ?`, 
-			sendErr.Error(), msg.ID)
+		// Retry per the error class's policy, otherwise mark failed and record the dead letter
+		rescheduled, err := repository.GetBroadcastRepository().RetryOrFailMessage(msg.ID, sendErr, domainBroadcast.DeadLetterSourceSQL)
+		if err != nil {
+			logrus.Errorf("Failed to update message %s after send failure: %v", msg.ID, err)
+		}
+		code := pkgError.SendErrorCodeOf(sendErr)
+		if code == "" {
+			code = pkgError.SendErrUnknown
+		}
+		outcome := "failed"
+		if rescheduled {
+			outcome = "retry"
+		}
+		settle(msg.ID)
+		metrics.MessagesFailed.Inc(bw.deviceID, domainBroadcast.ErrorClassForCode(code), string(code), outcome)
+		span.RecordError(sendErr)
+		span.SetAttributes(tracing.String("error.code", string(code)), tracing.String("outcome", outcome))
 		logrus.Errorf("Failed to send message %s: %v", msg.ID, sendErr)
 	} else {
 		atomic.AddInt64(&bw.processedCount, 1)
@@ -398,7 +540,10 @@ func (bw *BroadcastWorker) processMessage(msg *domainBroadcast.BroadcastMessage)
 			atomic.AddInt64(&bw.pool.processedCount, 1)
 		}
 		// Update status to sent (preserve processing_worker_id for audit trail)
-		db2.Exec(`UPDATE broadcast_messages SET status = 'sent', sent_at = NOW() WHERE id = ? AND status IN ('queued', 'processing')`, msg.ID)
+		repository.GetBroadcastRepository().MarkMessageSent(msg.ID)
+		settle(msg.ID)
+		metrics.MessagesSent.Inc(bw.deviceID, msg.Type)
+		span.SetAttributes(tracing.String("outcome", "sent"))
 		
 		// Update sequence progress if this is a sequence message
 		if msg.SequenceID != nil {
@@ -428,6 +573,14 @@ func (bw *BroadcastWorker) sendWhatsAppMessage(msg *domainBroadcast.BroadcastMes
 		msg.Content = msg.Message
 	}
 	
+	// Poll questions, locations, contacts and voice notes are sent as written
+	switch msg.Type {
+	case domainBroadcast.MessageTypeAudio, domainBroadcast.MessageTypeLocation,
+		domainBroadcast.MessageTypePoll, domainBroadcast.MessageTypeContact:
+		msg.Message = msg.Content
+		return bw.messageSender.SendMessage(bw.deviceID, msg)
+	}
+	
 	// Apply anti-spam for ALL devices (both WhatsApp Web and Platform)
 	// Create message randomizer and greeting processor
 	messageRandomizer := antipattern.NewMessageRandomizer()
@@ -514,6 +667,11 @@ func (bwp *BroadcastWorkerPool) Shutdown() {
 		for _, worker := range group.workers {
 			worker.cancel()
 		}
+		// Messages no worker picked up stay queued in the database
+		for range group.messageQueue {
+			atomic.AddInt64(&group.inFlight, -1)
+			atomic.AddInt64(&messagesInFlight, -1)
+		}
 	}
 	
 	if bwp.completionTime == nil {
@@ -576,7 +734,7 @@ func (dwg *DeviceWorkerGroup) acquireSendPermission(minDelay, maxDelay int) {
 	// Don't unlock here - the worker will unlock after sending
 	
 	// Calculate time since last send
-	timeSinceLastSend := time.Since(dwg.lastSentTime)
+	timeSinceLastSend := clock.Since(dwg.lastSentTime)
 	
 	// Calculate required delay
 	requiredDelay := calculateRandomDelay(minDelay, maxDelay)
@@ -585,13 +743,13 @@ func (dwg *DeviceWorkerGroup) acquireSendPermission(minDelay, maxDelay int) {
 	if timeSinceLastSend < requiredDelay {
 		waitTime := requiredDelay - timeSinceLastSend
 		logrus.Debugf("Device %s: Waiting %v before next send (rate limiting)", dwg.deviceID, waitTime)
-		time.Sleep(waitTime)
+		clock.Sleep(waitTime)
 	}
 }
 
 // releaseSendPermission updates last sent time and releases the mutex
 func (dwg *DeviceWorkerGroup) releaseSendPermission() {
-	dwg.lastSentTime = time.Now()
+	dwg.lastSentTime = clock.Now()
 	dwg.sendMutex.Unlock()
 }
 
@@ -13,6 +13,7 @@ import (
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/database"
 	domainBroadcast "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/broadcast"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
 	"github.com/go-redis/redis/v8"
 	"github.com/sirupsen/logrus"
@@ -61,6 +62,19 @@ type UltraRedisMessage struct {
 	Priority  int                              `json:"priority"`
 	Timestamp time.Time                        `json:"timestamp"`
 	Retries   int                              `json:"retries"`
+	// LastError and ErrorCode keep the failure of a message parked in a dead
+	// letter list, so draining it records the same class
+	LastError string                 `json:"last_error,omitempty"`
+	ErrorCode pkgError.SendErrorCode `json:"error_code,omitempty"`
+}
+
+// deadLetterError rebuilds the failure of a message parked in a dead letter
+// list; entries parked before the class was kept count as unknown
+func (m UltraRedisMessage) deadLetterError() error {
+	if m.ErrorCode == "" {
+		return pkgError.NewSendError(pkgError.SendErrUnknown, nil, "max retries exceeded")
+	}
+	return pkgError.NewSendError(m.ErrorCode, nil, "max retries exceeded: %s", m.LastError)
 }
 
 // NewUltraScaleRedisManager creates a manager optimized for 3000+ devices
@@ -423,9 +437,7 @@ func (um *UltraScaleRedisManager) processMessage(worker *DeviceWorker, msg *Ultr
 		logrus.Errorf("Failed to queue message to worker: %v", err)
 		um.incrementMetricBatch("messages_failed")
 		
-		// Update database status
-		broadcastRepo := repository.GetBroadcastRepository()
-		broadcastRepo.UpdateMessageStatus(msg.Message.ID, "failed", err.Error())
+		// handleFailedMessage retries or marks the message failed
 		return err
 	}
 	
@@ -447,21 +459,34 @@ func (um *UltraScaleRedisManager) updateProcessingTime(deviceID string, duration
 
 // handleFailedMessage handles failed messages
 func (um *UltraScaleRedisManager) handleFailedMessage(msg *UltraRedisMessage, deadLetterQueue string, err error) {
-	msg.Retries++
+	// Retry count and backoff depend on the error class
+	policy := pkgError.RetryPolicyFor(pkgError.SendErrorCodeOf(err))
 	
-	// If retries exceeded, move to dead letter
-	if msg.Retries > 3 {
-		data, _ := json.Marshal(msg)
-		um.redisClient.LPush(um.ctx, deadLetterQueue, data)
+	// If retries exceeded, mark failed and record in the dead letter store
+	if !policy.ShouldRetry(msg.Retries) {
 		um.incrementMetricBatch("messages_dead_letter")
+		failure := fmt.Errorf("max retries exceeded: %w", err)
+		broadcastRepo := repository.GetBroadcastRepository()
+		if markErr := broadcastRepo.MarkMessageFailed(msg.Message.ID, failure, domainBroadcast.DeadLetterSourceUltraRedis, msg.Retries); markErr != nil {
+			// Keep it in Redis so the cleanup loop can record it later
+			msg.LastError = err.Error()
+			msg.ErrorCode = pkgError.SendErrorCodeOf(err)
+			data, _ := json.Marshal(msg)
+			um.redisClient.LPush(um.ctx, deadLetterQueue, data)
+		}
 		return
 	}
 	
-	// Otherwise, requeue with exponential backoff
-	backoff := time.Duration(msg.Retries) * time.Minute
-	time.Sleep(backoff)
+	// Otherwise, requeue with backoff, keeping the retry count
+	msg.Retries++
+	time.Sleep(policy.Backoff(msg.Retries))
 	
-	um.SendMessage(msg.Message)
+	queueKey := fmt.Sprintf("%s%s", ultraSequenceQueuePrefix, msg.Message.DeviceID)
+	if msg.Message.CampaignID != nil {
+		queueKey = fmt.Sprintf("%s%s", ultraCampaignQueuePrefix, msg.Message.DeviceID)
+	}
+	data, _ := json.Marshal(msg)
+	um.redisClient.LPush(um.ctx, queueKey, data)
 }
 
 // processQueues monitors all device queues
@@ -635,19 +660,36 @@ func (um *UltraScaleRedisManager) cleanupDeadLetters() {
 		case <-um.ctx.Done():
 			return
 		case <-ticker.C:
-			// Clean up old dead letters
+			// Drain legacy per-device dead letter lists into the dead letter store
 			deadLetterQueues, _ := um.redisClient.Keys(um.ctx, ultraDeadLetterPrefix+"*").Result()
 			for _, queue := range deadLetterQueues {
-				count, _ := um.redisClient.LLen(um.ctx, queue).Result()
-				if count > 1000 {
-					// Keep only recent 1000
-					um.redisClient.LTrim(um.ctx, queue, 0, 999)
-				}
+				um.drainDeadLetters(queue)
 			}
 		}
 	}
 }
 
+// drainDeadLetters marks entries of a Redis dead letter list failed and records them in the store
+func (um *UltraScaleRedisManager) drainDeadLetters(queue string) {
+	broadcastRepo := repository.GetBroadcastRepository()
+	for i := 0; i < 1000; i++ {
+		data, err := um.redisClient.RPop(um.ctx, queue).Result()
+		if err != nil {
+			return
+		}
+		
+		var msg UltraRedisMessage
+		if err := json.Unmarshal([]byte(data), &msg); err != nil {
+			continue
+		}
+		if err := broadcastRepo.MarkMessageFailed(msg.Message.ID, msg.deadLetterError(), domainBroadcast.DeadLetterSourceUltraRedis, msg.Retries); err != nil {
+			logrus.Warnf("Failed to record dead letter %s: %v", msg.Message.ID, err)
+			um.redisClient.LPush(um.ctx, queue, data)
+			return
+		}
+	}
+}
+
 // GetWorkerStatus returns status for a specific device
 func (um *UltraScaleRedisManager) GetWorkerStatus(deviceID string) (domainBroadcast.WorkerStatus, bool) {
 	// Check in-memory first
@@ -0,0 +1,26 @@
+package broadcast
+
+import (
+	"encoding/json"
+	"testing"
+
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeadLetterKeepsSendErrorClass(t *testing.T) {
+	sendErr := pkgError.NewSendError(pkgError.SendErrNotOnWhatsapp, nil, "60111 is not on WhatsApp")
+	parked := UltraRedisMessage{Retries: 3, LastError: sendErr.Error(), ErrorCode: pkgError.SendErrorCodeOf(sendErr)}
+	data, err := json.Marshal(parked)
+	require.NoError(t, err)
+
+	var drained UltraRedisMessage
+	require.NoError(t, json.Unmarshal(data, &drained))
+	failure := drained.deadLetterError()
+	assert.Equal(t, pkgError.SendErrNotOnWhatsapp, pkgError.SendErrorCodeOf(failure))
+	assert.Contains(t, failure.Error(), "60111 is not on WhatsApp")
+
+	legacy := UltraRedisMessage{Retries: 3}
+	assert.Equal(t, pkgError.SendErrUnknown, pkgError.SendErrorCodeOf(legacy.deadLetterError()))
+}
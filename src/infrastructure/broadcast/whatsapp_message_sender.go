@@ -2,16 +2,16 @@ package broadcast
 
 import (
 	"context"
-	"encoding/base64"
-	"fmt"
-	"io"
-	"net/http"
+	"errors"
 	"strings"
 
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/domains/broadcast"
+	infraMedia "github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/media"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp/multidevice"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/antipattern"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
 	platform "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/external"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/tracing"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
 	"github.com/sirupsen/logrus"
 	"go.mau.fi/whatsmeow"
@@ -44,7 +44,7 @@ func (w *WhatsAppMessageSender) SendMessage(deviceID string, msg *broadcast.Broa
 	device, err := userRepo.GetDeviceByName(deviceID)
 	if err != nil {
 		logrus.Errorf("Failed to get device by name %s: %v", deviceID, err)
-		return fmt.Errorf("device not found by name %s: %v", deviceID, err)
+		return pkgError.NewSendError(pkgError.SendErrDeviceUnavailable, err, "device not found by name %s", deviceID)
 	}
 	
 	// Only process line breaks, NO anti-spam here
@@ -58,6 +58,13 @@ func (w *WhatsAppMessageSender) SendMessage(deviceID string, msg *broadcast.Broa
 	// Check if it's a platform device
 	if device.Platform != "" {
 		// logrus.Infof("Sending via platform %s for device %s", device.Platform, device.DeviceName)
+		if _, ok := infraMedia.ParseAssetURL(mediaURLOf(msg)); ok {
+			// Platforms fetch media themselves and cannot read the asset library
+			return pkgError.NewSendError(pkgError.SendErrProvider4xx, nil, "asset media cannot be sent through platform %s", device.Platform)
+		}
+		if isRichMessageType(msg.Type) {
+			return w.platformSender.SendRichMessage(device.Platform, device.JID, msg.RecipientPhone, platformRichMessage(msg))
+		}
 		return w.platformSender.SendMessage(
 			device.Platform,
 			device.JID,  // JID contains the instance/token for platform devices
@@ -117,45 +124,47 @@ func (w *WhatsAppMessageSender) sendViaWhatsApp(deviceID string, msg *broadcast.
 	dm := multidevice.GetDeviceManager()
 	waClient, err := dm.GetOrRefreshClient(deviceID)
 	if err != nil {
-		return fmt.Errorf("failed to get/refresh client for device %s: %v", deviceID, err)
+		return pkgError.NewSendError(pkgError.SendErrDeviceUnavailable, err, "failed to get/refresh client for device %s", deviceID)
 	}
 	
 	// Double-check client health before sending
 	if !dm.IsClientHealthy(waClient) {
-		return fmt.Errorf("device %s client is not healthy after refresh", deviceID)
+		return pkgError.NewSendError(pkgError.SendErrDeviceUnavailable, nil, "device %s client is not healthy after refresh", deviceID)
 	}
 	
 	// No more keepalive or manual reconnection - client is guaranteed healthy
 	logrus.Debugf("📤 Sending message via healthy client for device %s", deviceID)
 	
 	if !waClient.IsLoggedIn() {
-		return fmt.Errorf("device %s is not logged in", deviceID)
+		return pkgError.NewSendError(pkgError.SendErrDeviceLoggedOut, nil, "device %s is not logged in", deviceID)
 	}
 	
-	// Parse recipient JID
-	recipientJID, err := types.ParseJID(msg.RecipientPhone + "@s.whatsapp.net")
+	// Parse recipient JID; group campaigns queue the group's JID as is
+	recipient := msg.RecipientPhone
+	if !strings.ContainsRune(recipient, '@') {
+		recipient += "@s.whatsapp.net"
+	}
+	recipientJID, err := types.ParseJID(recipient)
 	if err != nil {
-		// Try without suffix
-		recipientJID, err = types.ParseJID(msg.RecipientPhone)
-		if err != nil {
-			return fmt.Errorf("invalid recipient phone: %v", err)
-		}
+		return pkgError.NewSendError(pkgError.SendErrInvalidRecipient, err, "invalid recipient phone")
 	}
 	
-	// Validate recipient
-	if !strings.ContainsRune(recipientJID.User, '@') {
+	// Validate recipient; only phone numbers can be checked
+	if recipientJID.Server == types.DefaultUserServer {
 		info, err := waClient.IsOnWhatsApp([]string{recipientJID.User})
 		if err != nil {
-			return fmt.Errorf("failed to check WhatsApp: %v", err)
+			return whatsmeowSendError(err, "failed to check WhatsApp")
 		}
 		if len(info) == 0 || !info[0].IsIn {
-			return fmt.Errorf("recipient %s is not on WhatsApp", msg.RecipientPhone)
+			return pkgError.NewSendError(pkgError.SendErrNotOnWhatsapp, nil, "recipient %s is not on WhatsApp", msg.RecipientPhone)
 		}
 	}
 	
 	// Send based on message type
 	if msg.Type == "image" && msg.ImageURL != "" {
 		return w.sendImageMessage(waClient, recipientJID, msg)
+	} else if isRichMessageType(msg.Type) {
+		return w.sendRichMessage(waClient, recipientJID, msg)
 	} else {
 		return w.sendTextMessage(waClient, recipientJID, msg)
 	}
@@ -171,78 +180,99 @@ func (w *WhatsAppMessageSender) sendTextMessage(waClient *whatsmeow.Client, reci
 	}
 	
 	// Send message
-	resp, err := waClient.SendMessage(context.Background(), recipient, message)
+	resp, err := waClient.SendMessage(context.Background(), recipient, message, sendExtra(msg))
 	if err != nil {
-		return fmt.Errorf("failed to send text message: %v", err)
+		return whatsmeowSendError(err, "failed to send text message")
 	}
 	
+	rememberSent(msg, resp.ID)
 	logrus.Infof("Text message sent to %s (ID: %s)", recipient.String(), resp.ID)
 	return nil
 }
 
 // sendImageMessage sends an image message
 func (w *WhatsAppMessageSender) sendImageMessage(waClient *whatsmeow.Client, recipient types.JID, msg *broadcast.BroadcastMessage) error {
-	var imageData []byte
-	var err error
-	
-	// Check if it's a data URL or regular URL
-	if strings.HasPrefix(msg.ImageURL, "data:") {
-		// Handle data URL (base64 encoded image)
-		parts := strings.SplitN(msg.ImageURL, ",", 2)
-		if len(parts) != 2 {
-			return fmt.Errorf("invalid data URL format")
-		}
-		
-		// Decode base64 data
-		imageData, err = base64.StdEncoding.DecodeString(parts[1])
-		if err != nil {
-			return fmt.Errorf("failed to decode base64 image: %v", err)
-		}
-	} else {
-		// Handle regular URL
-		resp, err := http.Get(msg.ImageURL)
-		if err != nil {
-			return fmt.Errorf("failed to download image: %v", err)
-		}
-		defer resp.Body.Close()
-		
-		if resp.StatusCode != http.StatusOK {
-			return fmt.Errorf("failed to download image: %s", resp.Status)
-		}
-		
-		imageData, err = io.ReadAll(resp.Body)
-		if err != nil {
-			return fmt.Errorf("failed to read image data: %v", err)
-		}
-	}
-	
-	// Upload to WhatsApp servers
-	uploaded, err := waClient.Upload(context.Background(), imageData, whatsmeow.MediaImage)
+	// Fetched and uploaded once per device, then reused for every recipient
+	media, err := prepareMedia(waClient, msg.UserID, msg.ImageURL, whatsmeow.MediaImage)
 	if err != nil {
-		return fmt.Errorf("failed to upload image: %v", err)
+		return err
 	}
 	
 	// Create image message with processed caption
-	caption := msg.Message
-	message := &waE2E.Message{
-		ImageMessage: &waE2E.ImageMessage{
-			Caption:       proto.String(caption),
-			URL:           proto.String(uploaded.URL),
-			DirectPath:    proto.String(uploaded.DirectPath),
-			MediaKey:      uploaded.MediaKey,
-			FileEncSHA256: uploaded.FileEncSHA256,
-			FileSHA256:    uploaded.FileSHA256,
-			FileLength:    proto.Uint64(uint64(len(imageData))),
-			Mimetype:      proto.String("image/jpeg"),
-		},
-	}
+	message := &waE2E.Message{ImageMessage: media.imageMessage(msg.Message)}
 	
 	// Send message
-	resp, err := waClient.SendMessage(context.Background(), recipient, message)
+	resp, err := waClient.SendMessage(context.Background(), recipient, message, sendExtra(msg))
 	if err != nil {
-		return fmt.Errorf("failed to send image message: %v", err)
+		return whatsmeowSendError(err, "failed to send image message")
 	}
 	
+	rememberSent(msg, resp.ID)
 	logrus.Infof("Image message sent to %s (ID: %s)", recipient.String(), resp.ID)
 	return nil
 }
+
+// sendRichMessage sends a video, document, audio, location, poll or contact message
+func (w *WhatsAppMessageSender) sendRichMessage(waClient *whatsmeow.Client, recipient types.JID, msg *broadcast.BroadcastMessage) error {
+	message, err := buildRichMessage(waClient, msg, msg.Message)
+	if err != nil {
+		return err
+	}
+	
+	resp, err := waClient.SendMessage(context.Background(), recipient, message, sendExtra(msg))
+	if err != nil {
+		return whatsmeowSendError(err, "failed to send "+msg.Type+" message")
+	}
+	
+	rememberSent(msg, resp.ID)
+	logrus.Infof("%s message sent to %s (ID: %s)", msg.Type, recipient.String(), resp.ID)
+	return nil
+}
+
+// sendExtra sends with the WhatsApp message ID recorded for the broadcast
+// message, so a resend carries the ID of the first attempt
+func sendExtra(msg *broadcast.BroadcastMessage) whatsmeow.SendRequestExtra {
+	return whatsmeow.SendRequestExtra{ID: types.MessageID(msg.WhatsAppMessageID)}
+}
+
+// rememberSent links a sent WhatsApp message to its broadcast message and to
+// the send span in the message's trace context, so its delivery and read
+// receipts are recorded against both
+func rememberSent(msg *broadcast.BroadcastMessage, whatsappMessageID string) {
+	if err := repository.GetBroadcastRepository().SaveWhatsAppMessageID(msg.ID, whatsappMessageID); err != nil {
+		logrus.Warnf("Failed to save WhatsApp message ID of broadcast message %s: %v", msg.ID, err)
+	}
+	if sc, ok := tracing.ParseTraceparent(msg.TraceContext); ok {
+		tracing.Remember(whatsappMessageID, sc)
+	}
+}
+
+// whatsmeowSendError wraps an error returned by whatsmeow in a typed send error
+func whatsmeowSendError(err error, action string) error {
+	if err == nil {
+		return nil
+	}
+	return pkgError.NewSendError(whatsmeowErrorCode(err, action), err, "%s", action)
+}
+
+// whatsmeowErrorCode maps whatsmeow's sentinel errors to send error codes
+func whatsmeowErrorCode(err error, action string) pkgError.SendErrorCode {
+	switch {
+	case errors.Is(err, whatsmeow.ErrNoSession):
+		return pkgError.SendErrEncryption
+	case errors.Is(err, whatsmeow.ErrNotLoggedIn):
+		return pkgError.SendErrDeviceLoggedOut
+	case errors.Is(err, whatsmeow.ErrNotConnected), errors.Is(err, whatsmeow.ErrClientIsNil):
+		return pkgError.SendErrDeviceUnavailable
+	case errors.Is(err, whatsmeow.ErrIQTimedOut), errors.Is(err, whatsmeow.ErrMessageTimedOut):
+		return pkgError.SendErrNetwork
+	case errors.Is(err, whatsmeow.ErrRecipientADJID), errors.Is(err, whatsmeow.ErrUnknownServer):
+		return pkgError.SendErrInvalidRecipient
+	}
+	
+	code := pkgError.SendErrorCodeOf(err)
+	if code == pkgError.SendErrUnknown && strings.Contains(action, "upload") {
+		return pkgError.SendErrMediaUpload
+	}
+	return code
+}
@@ -2,14 +2,11 @@ package broadcast
 
 import (
 	"context"
-	"encoding/base64"
 	"fmt"
-	"io"
 	"math/rand"
-	"net/http"
-	"strings"
 	"time"
 	
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
 	domainBroadcast "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/broadcast"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
 	"github.com/sirupsen/logrus"
@@ -19,46 +16,17 @@ import (
 	"google.golang.org/protobuf/proto"
 )
 
-// DownloadMedia downloads media from URL or data URL
-func DownloadMedia(url string) ([]byte, error) {
-	// Check if it's a data URL
-	if strings.HasPrefix(url, "data:") {
-		// Parse data URL
-		parts := strings.SplitN(url, ",", 2)
-		if len(parts) != 2 {
-			return nil, fmt.Errorf("invalid data URL format")
-		}
-		
-		// Decode base64
-		data, err := base64.StdEncoding.DecodeString(parts[1])
-		if err != nil {
-			return nil, fmt.Errorf("failed to decode base64: %v", err)
-		}
-		
-		return data, nil
-	}
-	
-	// Regular HTTP/HTTPS URL
-	resp, err := http.Get(url)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("bad status: %s", resp.Status)
-	}
-	
-	return io.ReadAll(resp.Body)
-}
-
 // GetRandomDelay returns a random delay between min and max seconds
 func GetRandomDelay(minDelay, maxDelay int) time.Duration {
+	worker := config.Current().Worker
 	if minDelay <= 0 {
-		minDelay = 5
+		minDelay = worker.MinDelaySeconds
 	}
-	if maxDelay <= 0 || maxDelay < minDelay {
-		maxDelay = minDelay + 10
+	if maxDelay <= 0 {
+		maxDelay = worker.MaxDelaySeconds
+	}
+	if maxDelay <= minDelay {
+		return time.Duration(minDelay) * time.Second
 	}
 	
 	delay := rand.Intn(maxDelay-minDelay) + minDelay
@@ -86,28 +54,13 @@ func SendWhatsAppMessage(client *whatsmeow.Client, msg domainBroadcast.Broadcast
 			// Image with caption
 			logrus.Infof("Sending image with caption to %s", recipient.String())
 			
-			imageBytes, err := DownloadMedia(msg.MediaURL)
+			media, err := prepareMedia(client, msg.UserID, msg.MediaURL, whatsmeow.MediaImage)
 			if err != nil {
-				logrus.Errorf("Failed to download image: %v", err)
+				logrus.Errorf("Failed to prepare image: %v", err)
 				return err
 			}
 			
-			uploadResp, err := client.Upload(context.Background(), imageBytes, whatsmeow.MediaImage)
-			if err != nil {
-				logrus.Errorf("Failed to upload image: %v", err)
-				return err
-			}
-			
-			imageMsg := &waProto.ImageMessage{
-				Caption:       proto.String(msg.Content),
-				URL:           proto.String(uploadResp.URL),
-				DirectPath:    proto.String(uploadResp.DirectPath),
-				MediaKey:      uploadResp.MediaKey,
-				Mimetype:      proto.String("image/jpeg"),
-				FileEncSHA256: uploadResp.FileEncSHA256,
-				FileSHA256:    uploadResp.FileSHA256,
-				FileLength:    proto.Uint64(uint64(len(imageBytes))),
-			}
+			imageMsg := media.imageMessage(msg.Content)
 			
 			_, err = client.SendMessage(context.Background(), recipient, &waProto.Message{
 				ImageMessage: imageMsg,
@@ -139,27 +92,13 @@ func SendWhatsAppMessage(client *whatsmeow.Client, msg domainBroadcast.Broadcast
 		// Image only (no caption)
 		logrus.Infof("Sending image to %s", recipient.String())
 		
-		imageBytes, err := DownloadMedia(msg.MediaURL)
+		media, err := prepareMedia(client, msg.UserID, msg.MediaURL, whatsmeow.MediaImage)
 		if err != nil {
-			logrus.Errorf("Failed to download image: %v", err)
+			logrus.Errorf("Failed to prepare image: %v", err)
 			return err
 		}
 		
-		uploadResp, err := client.Upload(context.Background(), imageBytes, whatsmeow.MediaImage)
-		if err != nil {
-			logrus.Errorf("Failed to upload image: %v", err)
-			return err
-		}
-		
-		imageMsg := &waProto.ImageMessage{
-			URL:           proto.String(uploadResp.URL),
-			DirectPath:    proto.String(uploadResp.DirectPath),
-			MediaKey:      uploadResp.MediaKey,
-			Mimetype:      proto.String("image/jpeg"),
-			FileEncSHA256: uploadResp.FileEncSHA256,
-			FileSHA256:    uploadResp.FileSHA256,
-			FileLength:    proto.Uint64(uint64(len(imageBytes))),
-		}
+		imageMsg := media.imageMessage("")
 		
 		_, err = client.SendMessage(context.Background(), recipient, &waProto.Message{
 			ImageMessage: imageMsg,
@@ -172,6 +111,23 @@ func SendWhatsAppMessage(client *whatsmeow.Client, msg domainBroadcast.Broadcast
 		
 		logrus.Infof("Successfully sent image to %s", recipient.String())
 		
+	case "video", "document", "audio", "location", "poll", "contact":
+		logrus.Infof("Sending %s to %s", msg.Type, recipient.String())
+		
+		message, err := buildRichMessage(client, &msg, msg.Content)
+		if err != nil {
+			logrus.Errorf("Failed to build %s message: %v", msg.Type, err)
+			return err
+		}
+		
+		_, err = client.SendMessage(context.Background(), recipient, message)
+		if err != nil {
+			logrus.Errorf("Failed to send %s: %v", msg.Type, err)
+			return err
+		}
+		
+		logrus.Infof("Successfully sent %s to %s", msg.Type, recipient.String())
+		
 	default:
 		logrus.Warnf("Unknown message type: %s", msg.Type)
 		return fmt.Errorf("unknown message type: %s", msg.Type)
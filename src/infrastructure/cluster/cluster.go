@@ -0,0 +1,328 @@
+// Package cluster lets several replicas run side by side. Replicas register
+// in Redis or the database, each owns the devices that hash to it on a
+// consistent-hash ring, and one elected leader runs the global jobs. Outside
+// cluster mode every function answers as a single replica that owns
+// everything and leads.
+package cluster
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/scheduler"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/clock"
+	"github.com/sirupsen/logrus"
+)
+
+// leaderLock is the scheduler lock held by the cluster leader
+const leaderLock = "cluster-leader"
+
+// Cluster is this replica's view of the cluster
+type Cluster struct {
+	registry  Registry
+	locker    scheduler.Locker
+	heartbeat time.Duration
+	ttl       time.Duration
+	secret    []byte // signs the requests replicas forward to each other
+
+	mu        sync.RWMutex
+	self      Node
+	nodes     []Node
+	ring      *Ring
+	left      bool
+	listeners []func()
+}
+
+// Status describes the cluster as this replica sees it
+type Status struct {
+	Self   Node   `json:"self"`
+	Leader bool   `json:"leader"`
+	Nodes  []Node `json:"nodes"`
+}
+
+// New returns a cluster member that has not joined yet. The locker elects
+// the leader; heartbeat is how often the replica checks in and ttl how long
+// a silent replica keeps its devices and leadership.
+func New(self Node, registry Registry, locker scheduler.Locker, heartbeat, ttl time.Duration) *Cluster {
+	return &Cluster{
+		registry:  registry,
+		locker:    locker,
+		heartbeat: heartbeat,
+		ttl:       ttl,
+		self:      self,
+		nodes:     []Node{self},
+		ring:      NewRing(self.ID),
+	}
+}
+
+var (
+	current   *Cluster
+	currentMu sync.RWMutex
+)
+
+// Current returns the cluster this process has joined, or nil outside
+// cluster mode
+func Current() *Cluster {
+	currentMu.RLock()
+	defer currentMu.RUnlock()
+	return current
+}
+
+// SetCurrent makes c the cluster answered by the package functions
+func SetCurrent(c *Cluster) {
+	currentMu.Lock()
+	defer currentMu.Unlock()
+	current = c
+}
+
+// NodeID returns the ID this replica runs as: CLUSTER_NODE_ID, else the
+// hostname. It is the same in and outside cluster mode.
+func NodeID() string {
+	if config.ClusterNodeID != "" {
+		return config.ClusterNodeID
+	}
+	hostname, _ := os.Hostname()
+	return hostname
+}
+
+// Start joins the cluster described by the CLUSTER_* settings, schedules
+// the heartbeat and makes the cluster current
+func Start(db *sql.DB) (*Cluster, error) {
+	hostname, _ := os.Hostname()
+	id := NodeID()
+	address := config.ClusterAdvertiseURL
+	if address == "" {
+		address = fmt.Sprintf("http://%s:%s", hostname, config.AppPort)
+	}
+	heartbeat := time.Duration(config.ClusterHeartbeatSeconds) * time.Second
+	ttl := time.Duration(config.ClusterNodeTTLSeconds) * time.Second
+	if ttl <= heartbeat {
+		return nil, fmt.Errorf("CLUSTER_NODE_TTL_SECONDS must be longer than CLUSTER_HEARTBEAT_SECONDS")
+	}
+	if config.ClusterSecret == "" {
+		return nil, fmt.Errorf("CLUSTER_SECRET is required in cluster mode")
+	}
+
+	self := Node{ID: id, Address: strings.TrimRight(address, "/"), StartedAt: clock.Now().UTC()}
+	c := New(self, NewRegistry(db, ttl), scheduler.NewClusterLocker(db), heartbeat, ttl)
+	c.secret = []byte(config.ClusterSecret)
+	ctx, cancel := context.WithTimeout(context.Background(), heartbeat)
+	defer cancel()
+	if err := c.Sync(ctx); err != nil {
+		return nil, fmt.Errorf("failed to join cluster: %w", err)
+	}
+	SetCurrent(c)
+
+	scheduler.Default().MustRegister(scheduler.Job{
+		Name:     "cluster-heartbeat",
+		Interval: heartbeat,
+		Run:      c.Sync,
+	})
+	logrus.Infof("Joined cluster as %s (%s) with %d replicas", self.ID, self.Address, len(c.Nodes()))
+	return c, nil
+}
+
+// OnChange registers fn to be called after the replicas on the ring change,
+// including when this replica leaves
+func (c *Cluster) OnChange(fn func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.listeners = append(c.listeners, fn)
+}
+
+// Sync sends a heartbeat, reloads the live replicas and renews or contests
+// the leadership
+func (c *Cluster) Sync(ctx context.Context) error {
+	c.mu.RLock()
+	self, left := c.self, c.left
+	c.mu.RUnlock()
+	if left {
+		return nil
+	}
+
+	if err := c.registry.Heartbeat(ctx, self); err != nil {
+		return fmt.Errorf("failed to send heartbeat: %w", err)
+	}
+	nodes, err := c.registry.Nodes(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list replicas: %w", err)
+	}
+	leader, err := c.locker.Acquire(ctx, leaderLock, self.ID, c.ttl)
+	if err != nil {
+		// Step down rather than risk two leaders
+		leader = false
+		err = fmt.Errorf("failed to renew leadership: %w", err)
+	}
+	c.apply(nodes, leader)
+	return err
+}
+
+// apply installs a new membership and tells the listeners when the ring moved
+func (c *Cluster) apply(nodes []Node, leader bool) {
+	c.mu.Lock()
+	if c.left {
+		c.mu.Unlock()
+		return
+	}
+	if leader != c.self.Leader {
+		if leader {
+			logrus.Infof("Replica %s is now the cluster leader", c.self.ID)
+		} else {
+			logrus.Infof("Replica %s is no longer the cluster leader", c.self.ID)
+		}
+	}
+	c.self.Leader = leader
+
+	// This replica owns its share even when its own heartbeat is not listed yet
+	members := []Node{c.self}
+	ids := []string{c.self.ID}
+	for _, node := range nodes {
+		if node.ID != c.self.ID {
+			members = append(members, node)
+			ids = append(ids, node.ID)
+		}
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].ID < members[j].ID })
+	c.nodes = members
+
+	ring := NewRing(ids...)
+	changed := strings.Join(ring.Nodes(), ",") != strings.Join(c.ring.Nodes(), ",")
+	if changed {
+		c.ring = ring
+		logrus.Infof("Cluster replicas changed: %s", strings.Join(ring.Nodes(), ", "))
+	}
+	listeners := append([]func(){}, c.listeners...)
+	c.mu.Unlock()
+
+	if changed {
+		for _, fn := range listeners {
+			fn()
+		}
+	}
+}
+
+// Leave hands this replica's devices and leadership over. Listeners run
+// first so devices are released before another replica takes them.
+func (c *Cluster) Leave(ctx context.Context) error {
+	c.mu.Lock()
+	if c.left {
+		c.mu.Unlock()
+		return nil
+	}
+	c.left = true
+	c.self.Leader = false
+	c.ring = NewRing()
+	listeners := append([]func(){}, c.listeners...)
+	id := c.self.ID
+	c.mu.Unlock()
+
+	for _, fn := range listeners {
+		fn()
+	}
+	if releaser, ok := c.locker.(scheduler.Releaser); ok {
+		if err := releaser.Release(ctx, leaderLock, id); err != nil {
+			logrus.Warnf("Failed to release cluster leadership: %v", err)
+		}
+	}
+	if err := c.registry.Leave(ctx, id); err != nil {
+		return fmt.Errorf("failed to leave cluster: %w", err)
+	}
+	logrus.Infof("Replica %s left the cluster", id)
+	return nil
+}
+
+// Self returns this replica
+func (c *Cluster) Self() Node {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.self
+}
+
+// Nodes returns the live replicas ordered by ID
+func (c *Cluster) Nodes() []Node {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]Node(nil), c.nodes...)
+}
+
+// IsLeader reports whether this replica runs the global jobs
+func (c *Cluster) IsLeader() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.self.Leader
+}
+
+// Owner returns the replica that owns a device and whether it is this one
+func (c *Cluster) Owner(deviceID string) (Node, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.left {
+		return Node{}, false
+	}
+	id := c.ring.Owner(deviceID)
+	for _, node := range c.nodes {
+		if node.ID == id {
+			return node, id == c.self.ID
+		}
+	}
+	return c.self, true
+}
+
+// HandoverDelay is how long a replica waits before connecting devices it
+// has just gained, giving their previous owner one heartbeat to let go
+func (c *Cluster) HandoverDelay() time.Duration {
+	return c.heartbeat
+}
+
+// Status returns the cluster as this replica sees it
+func (c *Cluster) Status() Status {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return Status{Self: c.self, Leader: c.self.Leader, Nodes: append([]Node(nil), c.nodes...)}
+}
+
+// Locker returns a scheduler locker that lets only the leader run singleton
+// jobs
+func (c *Cluster) Locker() scheduler.Locker {
+	return leaderLocker{c}
+}
+
+type leaderLocker struct {
+	cluster *Cluster
+}
+
+func (l leaderLocker) Acquire(_ context.Context, _, _ string, _ time.Duration) (bool, error) {
+	return l.cluster.IsLeader(), nil
+}
+
+// Owns reports whether this replica should connect and send for a device
+func Owns(deviceID string) bool {
+	c := Current()
+	if c == nil {
+		return true
+	}
+	_, self := c.Owner(deviceID)
+	return self
+}
+
+// IsLeader reports whether this replica runs the global jobs
+func IsLeader() bool {
+	c := Current()
+	return c == nil || c.IsLeader()
+}
+
+// HandoverDelay is how long to wait before connecting newly owned devices
+func HandoverDelay() time.Duration {
+	c := Current()
+	if c == nil {
+		return 0
+	}
+	return c.HandoverDelay()
+}
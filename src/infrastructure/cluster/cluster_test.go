@@ -0,0 +1,144 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryRegistry and memoryLocker stand in for Redis or the database
+type memoryRegistry struct {
+	mu    sync.Mutex
+	nodes map[string]Node
+}
+
+func (r *memoryRegistry) Heartbeat(_ context.Context, node Node) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nodes[node.ID] = node
+	return nil
+}
+
+func (r *memoryRegistry) Leave(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.nodes, id)
+	return nil
+}
+
+func (r *memoryRegistry) Nodes(_ context.Context) ([]Node, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var nodes []Node
+	for _, node := range r.nodes {
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+type memoryLocker struct {
+	mu    sync.Mutex
+	owner string
+}
+
+func (l *memoryLocker) Acquire(_ context.Context, _, owner string, _ time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.owner == "" {
+		l.owner = owner
+	}
+	return l.owner == owner, nil
+}
+
+func (l *memoryLocker) Release(_ context.Context, _, owner string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.owner == owner {
+		l.owner = ""
+	}
+	return nil
+}
+
+func newReplica(id string, registry Registry, locker *memoryLocker) *Cluster {
+	return New(Node{ID: id, Address: "http://" + id}, registry, locker, 10*time.Second, 30*time.Second)
+}
+
+func TestReplicasSplitDevicesAndHandThemOver(t *testing.T) {
+	ctx := context.Background()
+	registry := &memoryRegistry{nodes: map[string]Node{}}
+	locker := &memoryLocker{}
+	a := newReplica("replica-a", registry, locker)
+	b := newReplica("replica-b", registry, locker)
+
+	changes := 0
+	a.OnChange(func() { changes++ })
+	require.NoError(t, a.Sync(ctx))
+	require.NoError(t, b.Sync(ctx))
+	require.NoError(t, a.Sync(ctx))
+	assert.Equal(t, 1, changes, "replica-a sees replica-b join")
+	assert.True(t, a.IsLeader())
+	assert.False(t, b.IsLeader())
+
+	owned := map[string]int{}
+	for i := 0; i < 100; i++ {
+		device := fmt.Sprintf("device-%d", i)
+		ownerA, selfA := a.Owner(device)
+		ownerB, selfB := b.Owner(device)
+		assert.Equal(t, ownerA.ID, ownerB.ID, "replicas agree on the owner")
+		assert.NotEqual(t, selfA, selfB, "exactly one replica owns a device")
+		owned[ownerA.ID]++
+	}
+	assert.Len(t, owned, 2)
+
+	require.NoError(t, a.Leave(ctx))
+	assert.Equal(t, 2, changes, "listeners run when the replica leaves")
+	_, self := a.Owner("device-1")
+	assert.False(t, self, "a replica that left owns nothing")
+
+	require.NoError(t, b.Sync(ctx))
+	assert.True(t, b.IsLeader(), "leadership passes on")
+	for i := 0; i < 100; i++ {
+		_, self := b.Owner(fmt.Sprintf("device-%d", i))
+		assert.True(t, self)
+	}
+}
+
+func TestForwardProof(t *testing.T) {
+	fake := clock.NewFake(time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC))
+	defer clock.Set(fake)()
+
+	registry := &memoryRegistry{nodes: map[string]Node{}}
+	locker := &memoryLocker{}
+	a, b := newReplica("a", registry, locker), newReplica("b", registry, locker)
+	a.secret, b.secret = []byte("shared"), []byte("shared")
+
+	proof := a.SignForward()
+	id, ok := b.VerifyForward(proof)
+	assert.True(t, ok)
+	assert.Equal(t, "a", id)
+
+	other := newReplica("c", registry, locker)
+	other.secret = []byte("other")
+	_, ok = b.VerifyForward(other.SignForward())
+	assert.False(t, ok, "signed with another secret")
+
+	_, ok = b.VerifyForward("b" + proof[1:])
+	assert.False(t, ok, "replica ID changed")
+
+	_, ok = b.VerifyForward("a")
+	assert.False(t, ok, "a bare replica ID, as a client would send")
+
+	fake.Advance(forwardMaxAge + time.Second)
+	_, ok = b.VerifyForward(proof)
+	assert.False(t, ok, "too old to replay")
+
+	b.secret = nil
+	_, ok = b.VerifyForward(b.SignForward())
+	assert.False(t, ok, "nothing is trusted without a secret")
+}
@@ -0,0 +1,66 @@
+package cluster
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/clock"
+)
+
+// forwardMaxAge is how old a forwarded request's signature may be, allowing
+// for the forwarding time and the replicas' clocks being apart
+const forwardMaxAge = 2 * time.Minute
+
+// SignForward returns the proof a replica attaches to a request it forwards:
+// its ID and the time, signed with the cluster secret
+func (c *Cluster) SignForward() string {
+	id := c.Self().ID
+	at := strconv.FormatInt(clock.Now().Unix(), 10)
+	return id + "," + at + "," + c.forwardSignature(id, at)
+}
+
+// VerifyForward checks a proof made by SignForward and returns the ID of the
+// replica that forwarded the request. Clients can send any header, so only a
+// recent proof signed with the cluster secret is trusted.
+func (c *Cluster) VerifyForward(proof string) (string, bool) {
+	if len(c.secret) == 0 {
+		return "", false
+	}
+	rest, signature, ok := cutLast(proof)
+	if !ok {
+		return "", false
+	}
+	id, at, ok := cutLast(rest)
+	if !ok || id == "" || !hmac.Equal([]byte(signature), []byte(c.forwardSignature(id, at))) {
+		return "", false
+	}
+	unix, err := strconv.ParseInt(at, 10, 64)
+	if err != nil {
+		return "", false
+	}
+	age := clock.Now().Sub(time.Unix(unix, 0))
+	if age > forwardMaxAge || age < -forwardMaxAge {
+		return "", false
+	}
+	return id, true
+}
+
+// forwardSignature is the HMAC of a replica ID and time under the cluster secret
+func (c *Cluster) forwardSignature(id, at string) string {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write([]byte(id + "," + at))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// cutLast splits s around its last comma; node IDs may contain commas
+func cutLast(s string) (before, after string, ok bool) {
+	i := strings.LastIndex(s, ",")
+	if i < 0 {
+		return "", "", false
+	}
+	return s[:i], s[i+1:], true
+}
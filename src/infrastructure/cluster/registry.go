@@ -0,0 +1,200 @@
+package cluster
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/clock"
+	"github.com/go-redis/redis/v8"
+	"github.com/sirupsen/logrus"
+)
+
+// Node is one replica of the application
+type Node struct {
+	ID        string    `json:"id"`
+	Address   string    `json:"address"`
+	Leader    bool      `json:"leader"`
+	StartedAt time.Time `json:"started_at"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// Registry keeps track of the live replicas. A replica is live while its
+// heartbeats are younger than the registry's TTL.
+type Registry interface {
+	Heartbeat(ctx context.Context, node Node) error
+	Leave(ctx context.Context, id string) error
+	Nodes(ctx context.Context) ([]Node, error)
+}
+
+// NewRegistry registers replicas in Redis when REDIS_URL is set and answers,
+// and in the database otherwise
+func NewRegistry(db *sql.DB, ttl time.Duration) Registry {
+	if config.RedisURL != "" {
+		opt, err := redis.ParseURL(config.RedisURL)
+		if err != nil {
+			opt = &redis.Options{Addr: config.RedisURL, Password: config.RedisPassword}
+		}
+		client := redis.NewClient(opt)
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+		if err := client.Ping(ctx).Err(); err != nil {
+			logrus.Warnf("Redis unavailable for cluster membership, using the database: %v", err)
+			client.Close()
+		} else {
+			return NewRedisRegistry(client, ttl)
+		}
+	}
+	return NewDBRegistry(db, ttl)
+}
+
+// RedisRegistry keeps each replica in a key that expires with its TTL
+type RedisRegistry struct {
+	client *redis.Client
+	prefix string
+	ttl    time.Duration
+}
+
+// NewRedisRegistry returns a registry using the given Redis client
+func NewRedisRegistry(client *redis.Client, ttl time.Duration) *RedisRegistry {
+	return &RedisRegistry{client: client, prefix: "cluster:node:", ttl: ttl}
+}
+
+// Heartbeat implements Registry
+func (r *RedisRegistry) Heartbeat(ctx context.Context, node Node) error {
+	node.LastSeen = clock.Now().UTC()
+	data, err := json.Marshal(node)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(ctx, r.prefix+node.ID, data, r.ttl).Err()
+}
+
+// Leave implements Registry
+func (r *RedisRegistry) Leave(ctx context.Context, id string) error {
+	return r.client.Del(ctx, r.prefix+id).Err()
+}
+
+// Nodes implements Registry
+func (r *RedisRegistry) Nodes(ctx context.Context) ([]Node, error) {
+	var keys []string
+	iter := r.client.Scan(ctx, 0, r.prefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	values, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	nodes := make([]Node, 0, len(values))
+	for _, value := range values {
+		// Keys expiring between SCAN and MGET come back nil
+		data, ok := value.(string)
+		if !ok {
+			continue
+		}
+		var node Node
+		if err := json.Unmarshal([]byte(data), &node); err != nil {
+			logrus.Warnf("Ignoring malformed cluster node entry: %v", err)
+			continue
+		}
+		nodes = append(nodes, node)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+	return nodes, nil
+}
+
+// DBRegistry keeps replicas in the cluster_nodes table
+type DBRegistry struct {
+	db        *sql.DB
+	ttl       time.Duration
+	setupOnce sync.Once
+	setupErr  error
+}
+
+// NewDBRegistry returns a registry using the application database
+func NewDBRegistry(db *sql.DB, ttl time.Duration) *DBRegistry {
+	return &DBRegistry{db: db, ttl: ttl}
+}
+
+func (r *DBRegistry) ensureTable() error {
+	r.setupOnce.Do(func() {
+		_, r.setupErr = r.db.Exec(`
+			CREATE TABLE IF NOT EXISTS cluster_nodes (
+				node_id VARCHAR(255) PRIMARY KEY,
+				address VARCHAR(512) NOT NULL,
+				is_leader BOOLEAN NOT NULL DEFAULT FALSE,
+				started_at DATETIME NOT NULL,
+				last_seen DATETIME NOT NULL,
+				INDEX idx_last_seen (last_seen)
+			)
+		`)
+	})
+	return r.setupErr
+}
+
+// Heartbeat implements Registry. It also drops replicas that have been dead
+// for an hour so the table does not grow with every deploy.
+func (r *DBRegistry) Heartbeat(ctx context.Context, node Node) error {
+	if err := r.ensureTable(); err != nil {
+		return fmt.Errorf("failed to create cluster_nodes: %w", err)
+	}
+	now := clock.Now().UTC()
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO cluster_nodes (node_id, address, is_leader, started_at, last_seen) VALUES (?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE address = VALUES(address), is_leader = VALUES(is_leader),
+			started_at = VALUES(started_at), last_seen = VALUES(last_seen)
+	`, node.ID, node.Address, node.Leader, node.StartedAt.UTC(), now)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, `DELETE FROM cluster_nodes WHERE last_seen < ?`, now.Add(-time.Hour))
+	return err
+}
+
+// Leave implements Registry
+func (r *DBRegistry) Leave(ctx context.Context, id string) error {
+	if err := r.ensureTable(); err != nil {
+		return fmt.Errorf("failed to create cluster_nodes: %w", err)
+	}
+	_, err := r.db.ExecContext(ctx, `DELETE FROM cluster_nodes WHERE node_id = ?`, id)
+	return err
+}
+
+// Nodes implements Registry
+func (r *DBRegistry) Nodes(ctx context.Context) ([]Node, error) {
+	if err := r.ensureTable(); err != nil {
+		return nil, fmt.Errorf("failed to create cluster_nodes: %w", err)
+	}
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT node_id, address, is_leader, started_at, last_seen FROM cluster_nodes
+		WHERE last_seen > ?
+		ORDER BY node_id
+	`, clock.Now().UTC().Add(-r.ttl))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var nodes []Node
+	for rows.Next() {
+		var node Node
+		if err := rows.Scan(&node.ID, &node.Address, &node.Leader, &node.StartedAt, &node.LastSeen); err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, rows.Err()
+}
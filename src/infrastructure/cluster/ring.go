@@ -0,0 +1,70 @@
+package cluster
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"sort"
+	"strconv"
+)
+
+// virtualNodes spreads each replica over the ring so devices split evenly
+// and a leaving replica's devices scatter over all the others
+const virtualNodes = 160
+
+// Ring is a consistent-hash ring of replica IDs. Adding or removing a
+// replica only moves the devices that hash next to it.
+type Ring struct {
+	points []uint64
+	owners map[uint64]string
+	nodes  []string
+}
+
+// NewRing builds a ring of the given replica IDs
+func NewRing(nodes ...string) *Ring {
+	r := &Ring{owners: make(map[uint64]string, len(nodes)*virtualNodes)}
+	seen := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		if node == "" || seen[node] {
+			continue
+		}
+		seen[node] = true
+		r.nodes = append(r.nodes, node)
+		for i := 0; i < virtualNodes; i++ {
+			point := hashKey(node + "#" + strconv.Itoa(i))
+			// On the rare collision the lower ID wins on every replica
+			if existing, taken := r.owners[point]; taken && existing < node {
+				continue
+			}
+			if _, taken := r.owners[point]; !taken {
+				r.points = append(r.points, point)
+			}
+			r.owners[point] = node
+		}
+	}
+	sort.Strings(r.nodes)
+	sort.Slice(r.points, func(i, j int) bool { return r.points[i] < r.points[j] })
+	return r
+}
+
+// Nodes returns the replica IDs on the ring in order
+func (r *Ring) Nodes() []string {
+	return append([]string(nil), r.nodes...)
+}
+
+// Owner returns the replica that owns key, or "" on an empty ring
+func (r *Ring) Owner(key string) string {
+	if len(r.points) == 0 {
+		return ""
+	}
+	point := hashKey(key)
+	i := sort.Search(len(r.points), func(i int) bool { return r.points[i] >= point })
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.owners[r.points[i]]
+}
+
+func hashKey(key string) uint64 {
+	sum := sha256.Sum256([]byte(key))
+	return binary.BigEndian.Uint64(sum[:8])
+}
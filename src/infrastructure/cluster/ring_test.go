@@ -0,0 +1,54 @@
+package cluster
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRingSpreadsDevicesEvenly(t *testing.T) {
+	ring := NewRing("replica-a", "replica-b", "replica-c")
+	counts := map[string]int{}
+	for i := 0; i < 3000; i++ {
+		counts[ring.Owner(fmt.Sprintf("device-%d", i))]++
+	}
+
+	assert.Len(t, counts, 3)
+	for node, count := range counts {
+		assert.InDelta(t, 1000, count, 200, "replica %s owns %d devices", node, count)
+	}
+}
+
+func TestRingMovesOnlyTheJoiningReplicasShare(t *testing.T) {
+	before := NewRing("replica-a", "replica-b", "replica-c")
+	after := NewRing("replica-c", "replica-a", "replica-b", "replica-d")
+
+	moved := 0
+	for i := 0; i < 3000; i++ {
+		device := fmt.Sprintf("device-%d", i)
+		if owner := after.Owner(device); owner != before.Owner(device) {
+			assert.Equal(t, "replica-d", owner, "devices only move to the new replica")
+			moved++
+		}
+	}
+	assert.InDelta(t, 750, moved, 200)
+}
+
+func TestRingOwner(t *testing.T) {
+	tests := []struct {
+		name  string
+		nodes []string
+		want  string
+	}{
+		{name: "empty ring", nodes: nil, want: ""},
+		{name: "single replica", nodes: []string{"replica-a"}, want: "replica-a"},
+		{name: "duplicates are ignored", nodes: []string{"replica-a", "replica-a", ""}, want: "replica-a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NewRing(tt.nodes...).Owner("device-1"))
+		})
+	}
+}
@@ -0,0 +1,491 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/scheduler"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/clock"
+	pkgMedia "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/media"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
+	"github.com/sirupsen/logrus"
+	"go.mau.fi/whatsmeow"
+)
+
+// AssetScheme prefixes media URLs that reference an asset in the library,
+// e.g. asset://0b6c1a2e-...
+const AssetScheme = "asset://"
+
+const (
+	thumbnailSuffix = ".thumb.jpg"
+	// uploadExpiryMargin keeps a cached upload from being used right before
+	// WhatsApp's CDN stops serving it
+	uploadExpiryMargin = time.Hour
+	janitorInterval    = time.Hour
+)
+
+var (
+	// ErrAssetNotFound is returned for asset:// URLs that match no asset of the user
+	ErrAssetNotFound = errors.New("media asset not found")
+	// ErrContentUnavailable is returned when an asset's content cannot be read from the store
+	ErrContentUnavailable = errors.New("media asset content unavailable")
+)
+
+// Library stores media once per content hash and caches each device's
+// WhatsApp upload of it, so a broadcast downloads and uploads a file once
+// instead of once per recipient. The fetch limits and cache times are read
+// from the configuration on use, so a reload applies them.
+type Library struct {
+	store pkgMedia.Store
+	repo  *repository.MediaAssetRepository
+
+	mu           sync.Mutex
+	fetcher      *pkgMedia.Fetcher
+	fetchTimeout time.Duration
+	fetchMaxSize int64
+	uploads      map[string]*repository.MediaUpload
+	locks        map[string]*keyLock
+}
+
+type keyLock struct {
+	sync.Mutex
+	refs int
+}
+
+var (
+	library     *Library
+	libraryErr  error
+	libraryOnce sync.Once
+)
+
+// GetLibrary returns the media library configured by the MEDIA_* settings
+func GetLibrary() (*Library, error) {
+	libraryOnce.Do(func() {
+		var store pkgMedia.Store
+		store, libraryErr = newStore()
+		if libraryErr != nil {
+			logrus.Errorf("Failed to open media store: %v", libraryErr)
+			return
+		}
+		library = &Library{
+			store:   store,
+			repo:    repository.GetMediaAssetRepository(),
+			uploads: make(map[string]*repository.MediaUpload),
+			locks:   make(map[string]*keyLock),
+		}
+		scheduler.Default().MustRegister(scheduler.Job{
+			Name:     "media-upload-janitor",
+			Interval: janitorInterval,
+			Run:      library.janitor,
+		})
+	})
+	return library, libraryErr
+}
+
+func newStore() (pkgMedia.Store, error) {
+	switch config.MediaStorageDriver {
+	case "", "local":
+		return pkgMedia.NewLocalStore(config.MediaStoragePath)
+	case "s3":
+		return pkgMedia.NewS3Store(pkgMedia.S3Config{
+			Endpoint:  config.MediaS3Endpoint,
+			Bucket:    config.MediaS3Bucket,
+			Region:    config.MediaS3Region,
+			AccessKey: config.MediaS3AccessKey,
+			SecretKey: config.MediaS3SecretKey,
+		})
+	}
+	return nil, fmt.Errorf("unknown media storage driver %q", config.MediaStorageDriver)
+}
+
+// Fetch downloads media with the library's size, time and address limits
+func (l *Library) Fetch(ctx context.Context, mediaURL string) ([]byte, string, error) {
+	return l.currentFetcher().Fetch(ctx, mediaURL)
+}
+
+// currentFetcher returns a fetcher with the configured limits, replacing
+// the one in use when a reload changed them
+func (l *Library) currentFetcher() *pkgMedia.Fetcher {
+	settings := config.Current().Media
+	timeout := time.Duration(settings.FetchTimeoutSeconds) * time.Second
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.fetcher == nil || l.fetchTimeout != timeout || l.fetchMaxSize != settings.FetchMaxSize {
+		l.fetcher = pkgMedia.NewFetcher(timeout, settings.FetchMaxSize)
+		l.fetchTimeout, l.fetchMaxSize = timeout, settings.FetchMaxSize
+	}
+	return l.fetcher
+}
+
+// Add stores content for a user and returns its asset. Adding content the
+// user already has returns the existing asset.
+func (l *Library) Add(ctx context.Context, userID string, data []byte, declaredType, fileName, sourceURL string) (*repository.MediaAsset, error) {
+	stored, err := l.Store(ctx, data, declaredType, nil)
+	if err != nil {
+		return nil, err
+	}
+	asset := &repository.MediaAsset{
+		UserID:       userID,
+		SHA256:       stored.SHA256,
+		MimeType:     stored.MimeType,
+		Size:         stored.Size,
+		Width:        stored.Width,
+		Height:       stored.Height,
+		FileName:     fileName,
+		SourceURL:    sourceURL,
+		StorageKey:   stored.StorageKey,
+		ThumbnailKey: stored.ThumbnailKey,
+	}
+	return l.repo.SaveAsset(asset)
+}
+
+// StoredContent is content put in the media store and its thumbnail, if any
+type StoredContent struct {
+	pkgMedia.Info
+	StorageKey   string
+	ThumbnailKey string
+}
+
+// Store puts content in the media store without adding it to anyone's
+// library. Images get a thumbnail rendered from the content; other media
+// use thumb, a JPEG preview supplied by the sender, when there is one.
+func (l *Library) Store(ctx context.Context, data []byte, declaredType string, thumb []byte) (*StoredContent, error) {
+	stored := &StoredContent{Info: pkgMedia.Inspect(data, declaredType)}
+	stored.StorageKey = pkgMedia.ContentKey(stored.SHA256, "")
+	if err := l.store.Put(ctx, stored.StorageKey, data, stored.MimeType); err != nil {
+		return nil, fmt.Errorf("failed to store media: %w", err)
+	}
+
+	if pkgMedia.IsImage(stored.MimeType) {
+		rendered, err := pkgMedia.Thumbnail(data)
+		if err != nil {
+			logrus.Debugf("No thumbnail for media %s: %v", stored.SHA256, err)
+		} else {
+			thumb = rendered
+		}
+	}
+	if len(thumb) > 0 {
+		key := pkgMedia.ContentKey(stored.SHA256, thumbnailSuffix)
+		if err := l.store.Put(ctx, key, thumb, "image/jpeg"); err != nil {
+			logrus.Warnf("Failed to store thumbnail for media %s: %v", stored.SHA256, err)
+		} else {
+			stored.ThumbnailKey = key
+		}
+	}
+	return stored, nil
+}
+
+// Read returns the stored object with the given key
+func (l *Library) Read(ctx context.Context, key string) ([]byte, error) {
+	data, err := l.store.Get(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrContentUnavailable, err)
+	}
+	return data, nil
+}
+
+// Import downloads media from a URL into the user's library
+func (l *Library) Import(ctx context.Context, userID, mediaURL string) (*repository.MediaAsset, []byte, error) {
+	data, contentType, err := l.currentFetcher().Fetch(ctx, mediaURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	sourceURL := mediaURL
+	if strings.HasPrefix(mediaURL, "data:") {
+		sourceURL = "" // inline content is its own source
+	}
+	asset, err := l.Add(ctx, userID, data, contentType, fileNameFromURL(mediaURL), sourceURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	return asset, data, nil
+}
+
+// Resolve returns the asset for a media URL: an asset:// reference, media
+// recently fetched from the same URL, or a fresh import. The content is
+// returned too when it had to be downloaded, and is nil otherwise.
+func (l *Library) Resolve(ctx context.Context, userID, mediaURL string) (*repository.MediaAsset, []byte, error) {
+	if id, ok := ParseAssetURL(mediaURL); ok {
+		asset, err := l.repo.GetAsset(id)
+		if err != nil {
+			return nil, nil, err
+		}
+		if asset == nil || (userID != "" && asset.UserID != userID) {
+			return nil, nil, fmt.Errorf("%w: %s", ErrAssetNotFound, id)
+		}
+		return asset, nil, nil
+	}
+
+	sourceTTL := time.Duration(config.Current().Media.SourceCacheMinutes) * time.Minute
+	if !strings.HasPrefix(mediaURL, "data:") && sourceTTL > 0 {
+		asset, err := l.repo.FindAssetBySource(mediaURL, sourceTTL)
+		if err != nil {
+			logrus.Warnf("Media source lookup failed for %s: %v", mediaURL, err)
+		} else if asset != nil {
+			return asset, nil, nil
+		}
+	}
+	return l.Import(ctx, userID, mediaURL)
+}
+
+// Content reads an asset's content from the store
+func (l *Library) Content(ctx context.Context, asset *repository.MediaAsset) ([]byte, error) {
+	return l.Read(ctx, asset.StorageKey)
+}
+
+// Thumbnail reads an asset's JPEG thumbnail, or returns nil when it has none
+func (l *Library) Thumbnail(ctx context.Context, asset *repository.MediaAsset) []byte {
+	if asset.ThumbnailKey == "" {
+		return nil
+	}
+	thumb, err := l.store.Get(ctx, asset.ThumbnailKey)
+	if err != nil {
+		logrus.Debugf("Thumbnail unavailable for media %s: %v", asset.SHA256, err)
+		return nil
+	}
+	return thumb
+}
+
+// Delete removes a user's asset, and its content once no asset references it
+func (l *Library) Delete(ctx context.Context, userID, id string) (bool, error) {
+	asset, err := l.repo.GetAsset(id)
+	if err != nil || asset == nil || asset.UserID != userID {
+		return false, err
+	}
+	deleted, shared, err := l.repo.DeleteAsset(id, userID)
+	if err != nil || !deleted || shared {
+		return deleted, err
+	}
+
+	if err := l.Remove(ctx, asset.SHA256, asset.StorageKey, asset.ThumbnailKey); err != nil {
+		logrus.Warnf("Failed to delete media %s: %v", asset.SHA256, err)
+	}
+	return true, nil
+}
+
+// Remove deletes the stored objects of some content and forgets its cached
+// uploads. The caller makes sure nothing uses the content any more.
+func (l *Library) Remove(ctx context.Context, sha string, keys ...string) error {
+	l.forgetUploads(sha)
+	var errs []error
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		if err := l.store.Delete(ctx, key); err != nil {
+			errs = append(errs, fmt.Errorf("object %s: %w", key, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Upload returns the device's WhatsApp upload of an asset, uploading it
+// only when no unexpired upload is cached. data may be nil, in which case
+// the content is read from the store if an upload is needed.
+func (l *Library) Upload(ctx context.Context, client *whatsmeow.Client, asset *repository.MediaAsset, data []byte, mediaType whatsmeow.MediaType) (whatsmeow.UploadResponse, error) {
+	if client.Store == nil || client.Store.ID == nil {
+		// Not logged in - nothing to key the cache by, and the upload will fail anyway
+		return l.upload(ctx, client, asset, data, mediaType)
+	}
+
+	deviceID := client.Store.ID.String()
+	key := deviceID + "|" + asset.SHA256 + "|" + string(mediaType)
+	unlock := l.lock(key)
+	defer unlock()
+
+	if cached := l.cachedUpload(key, deviceID, asset.SHA256, mediaType); cached != nil {
+		return whatsmeow.UploadResponse{
+			URL:           cached.URL,
+			DirectPath:    cached.DirectPath,
+			MediaKey:      cached.MediaKey,
+			FileEncSHA256: cached.FileEncSHA256,
+			FileSHA256:    cached.FileSHA256,
+			FileLength:    cached.FileLength,
+		}, nil
+	}
+
+	uploaded, err := l.upload(ctx, client, asset, data, mediaType)
+	if err != nil {
+		return uploaded, err
+	}
+
+	uploadTTL := time.Duration(config.Current().Media.UploadCacheHours) * time.Hour
+	expiresAt := uploadExpiry(uploaded.URL, time.Now(), uploadTTL)
+	if expiresAt.IsZero() {
+		return uploaded, nil
+	}
+	cached := &repository.MediaUpload{
+		DeviceID:      deviceID,
+		SHA256:        asset.SHA256,
+		MediaType:     string(mediaType),
+		URL:           uploaded.URL,
+		DirectPath:    uploaded.DirectPath,
+		MediaKey:      uploaded.MediaKey,
+		FileEncSHA256: uploaded.FileEncSHA256,
+		FileSHA256:    uploaded.FileSHA256,
+		FileLength:    uploaded.FileLength,
+		ExpiresAt:     expiresAt,
+	}
+	l.mu.Lock()
+	l.uploads[key] = cached
+	l.mu.Unlock()
+	if err := l.repo.SaveUpload(cached); err != nil {
+		logrus.Warnf("Failed to cache media upload for %s: %v", deviceID, err)
+	}
+	return uploaded, nil
+}
+
+func (l *Library) upload(ctx context.Context, client *whatsmeow.Client, asset *repository.MediaAsset, data []byte, mediaType whatsmeow.MediaType) (whatsmeow.UploadResponse, error) {
+	if data == nil {
+		var err error
+		if data, err = l.Content(ctx, asset); err != nil {
+			return whatsmeow.UploadResponse{}, err
+		}
+	}
+	return client.Upload(ctx, data, mediaType)
+}
+
+// cachedUpload looks in memory first and then in the database, which
+// survives restarts and is shared between instances
+func (l *Library) cachedUpload(key, deviceID, sha string, mediaType whatsmeow.MediaType) *repository.MediaUpload {
+	now := time.Now()
+	l.mu.Lock()
+	cached, ok := l.uploads[key]
+	if ok && !cached.ExpiresAt.After(now) {
+		delete(l.uploads, key)
+		cached = nil
+	}
+	l.mu.Unlock()
+	if cached != nil {
+		return cached
+	}
+
+	cached, err := l.repo.GetUpload(deviceID, sha, string(mediaType))
+	if err != nil {
+		logrus.Warnf("Failed to read cached media upload: %v", err)
+		return nil
+	}
+	if cached != nil {
+		l.mu.Lock()
+		l.uploads[key] = cached
+		l.mu.Unlock()
+	}
+	return cached
+}
+
+func (l *Library) forgetUploads(sha string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for key, upload := range l.uploads {
+		if upload.SHA256 == sha {
+			delete(l.uploads, key)
+		}
+	}
+}
+
+// lock serialises uploads of the same content by the same device so that
+// concurrent sends wait for the first upload instead of repeating it
+func (l *Library) lock(key string) func() {
+	l.mu.Lock()
+	kl, ok := l.locks[key]
+	if !ok {
+		kl = &keyLock{}
+		l.locks[key] = kl
+	}
+	kl.refs++
+	l.mu.Unlock()
+
+	kl.Lock()
+	return func() {
+		kl.Unlock()
+		l.mu.Lock()
+		kl.refs--
+		if kl.refs == 0 {
+			delete(l.locks, key)
+		}
+		l.mu.Unlock()
+	}
+}
+
+// janitor drops expired uploads from memory and the database
+func (l *Library) janitor(ctx context.Context) error {
+	now := clock.Now()
+	l.mu.Lock()
+	for key, upload := range l.uploads {
+		if !upload.ExpiresAt.After(now) {
+			delete(l.uploads, key)
+		}
+	}
+	l.mu.Unlock()
+
+	purged, err := l.repo.PurgeExpiredUploads()
+	if err != nil {
+		return fmt.Errorf("failed to purge expired media uploads: %w", err)
+	}
+	if purged > 0 {
+		logrus.Debugf("Purged %d expired media uploads", purged)
+	}
+	return nil
+}
+
+// uploadExpiry works out how long an upload can be reused. WhatsApp CDN
+// URLs carry their expiry as a hex unix timestamp in the "oe" parameter;
+// the result is capped at now+ttl and is zero when the upload should not
+// be cached at all.
+func uploadExpiry(uploadURL string, now time.Time, ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	expiresAt := now.Add(ttl)
+	if parsed, err := url.Parse(uploadURL); err == nil {
+		if oe := parsed.Query().Get("oe"); oe != "" {
+			if seconds, err := strconv.ParseInt(oe, 16, 64); err == nil {
+				if cdnExpiry := time.Unix(seconds, 0).Add(-uploadExpiryMargin); cdnExpiry.Before(expiresAt) {
+					expiresAt = cdnExpiry
+				}
+			}
+		}
+	}
+	if !expiresAt.After(now) {
+		return time.Time{}
+	}
+	return expiresAt
+}
+
+// ParseAssetURL returns the asset ID of an asset:// URL
+func ParseAssetURL(mediaURL string) (string, bool) {
+	if !strings.HasPrefix(mediaURL, AssetScheme) {
+		return "", false
+	}
+	id := strings.TrimPrefix(mediaURL, AssetScheme)
+	return id, id != ""
+}
+
+// AssetURL returns the asset:// URL that references an asset in messages
+func AssetURL(id string) string {
+	return AssetScheme + id
+}
+
+// fileNameFromURL returns the last path segment of an http(s) URL
+func fileNameFromURL(mediaURL string) string {
+	if strings.HasPrefix(mediaURL, "data:") {
+		return ""
+	}
+	parsed, err := url.Parse(mediaURL)
+	if err != nil {
+		return ""
+	}
+	name := parsed.Path[strings.LastIndex(parsed.Path, "/")+1:]
+	if unescaped, err := url.PathUnescape(name); err == nil {
+		name = unescaped
+	}
+	return name
+}
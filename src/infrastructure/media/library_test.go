@@ -0,0 +1,51 @@
+package media
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUploadExpiry(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	cdnURL := func(expires time.Time) string {
+		return "https://mmg.whatsapp.net/v/t62/abc.enc?ccb=11-4&oh=01&oe=" + strconv.FormatInt(expires.Unix(), 16)
+	}
+
+	tests := []struct {
+		name string
+		url  string
+		ttl  time.Duration
+		want time.Time
+	}{
+		{"no oe uses ttl", "https://mmg.whatsapp.net/v/t62/abc.enc", 24 * time.Hour, now.Add(24 * time.Hour)},
+		{"oe before ttl wins", cdnURL(now.Add(6 * time.Hour)), 24 * time.Hour, now.Add(5 * time.Hour)},
+		{"ttl before oe wins", cdnURL(now.Add(30 * 24 * time.Hour)), 24 * time.Hour, now.Add(24 * time.Hour)},
+		{"oe within margin is not cached", cdnURL(now.Add(30 * time.Minute)), 24 * time.Hour, time.Time{}},
+		{"invalid oe uses ttl", "https://mmg.whatsapp.net/x?oe=zz", time.Hour, now.Add(time.Hour)},
+		{"caching disabled", cdnURL(now.Add(6 * time.Hour)), 0, time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.True(t, tt.want.Equal(uploadExpiry(tt.url, now, tt.ttl)))
+		})
+	}
+}
+
+func TestParseAssetURL(t *testing.T) {
+	id, ok := ParseAssetURL(AssetURL("0b6c1a2e"))
+	assert.True(t, ok)
+	assert.Equal(t, "0b6c1a2e", id)
+
+	_, ok = ParseAssetURL("asset://")
+	assert.False(t, ok)
+	_, ok = ParseAssetURL("https://example.com/a.png")
+	assert.False(t, ok)
+}
+
+func TestFileNameFromURL(t *testing.T) {
+	assert.Equal(t, "price list.pdf", fileNameFromURL("https://example.com/files/price%20list.pdf?x=1"))
+	assert.Equal(t, "", fileNameFromURL("data:image/png;base64,AAAA"))
+}
@@ -0,0 +1,161 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/clock"
+	"github.com/go-redis/redis/v8"
+	"github.com/sirupsen/logrus"
+)
+
+// Locker hands a singleton job to one replica. A lock is held for ttl from
+// the moment it is taken and is not released early, so the job runs at most
+// once per interval across the cluster; the holder may take it again.
+type Locker interface {
+	Acquire(ctx context.Context, job, owner string, ttl time.Duration) (bool, error)
+}
+
+// Releaser is implemented by lockers that can give a lock up before it
+// expires, which the cluster leader does when it shuts down
+type Releaser interface {
+	Release(ctx context.Context, job, owner string) error
+}
+
+// NewClusterLocker locks through Redis when REDIS_URL is set and answers,
+// and through the database otherwise
+func NewClusterLocker(db *sql.DB) Locker {
+	if config.RedisURL != "" {
+		opt, err := redis.ParseURL(config.RedisURL)
+		if err != nil {
+			// Like the broadcast manager, accept a bare host:port
+			opt = &redis.Options{Addr: config.RedisURL, Password: config.RedisPassword}
+		}
+		client := redis.NewClient(opt)
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+		if err := client.Ping(ctx).Err(); err != nil {
+			logrus.Warnf("Redis unavailable for scheduler locks, using the database: %v", err)
+			client.Close()
+		} else {
+			logrus.Info("Scheduler singleton jobs lock through Redis")
+			return NewRedisLocker(client)
+		}
+	}
+	logrus.Info("Scheduler singleton jobs lock through the database")
+	return NewDBLocker(db)
+}
+
+// minLockTTL keeps very short intervals from expiring a lock mid-run
+const minLockTTL = time.Second
+
+// RedisLocker keeps locks in Redis keys that expire on their own
+type RedisLocker struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisLocker returns a locker using the given Redis client
+func NewRedisLocker(client *redis.Client) *RedisLocker {
+	return &RedisLocker{client: client, prefix: "scheduler:lock:"}
+}
+
+// renewScript extends a lock only when the caller still owns it
+var renewScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+// Acquire implements Locker
+func (l *RedisLocker) Acquire(ctx context.Context, job, owner string, ttl time.Duration) (bool, error) {
+	if ttl < minLockTTL {
+		ttl = minLockTTL
+	}
+	key := l.prefix + job
+	acquired, err := l.client.SetNX(ctx, key, owner, ttl).Result()
+	if err != nil || acquired {
+		return acquired, err
+	}
+	renewed, err := renewScript.Run(ctx, l.client, []string{key}, owner, ttl.Milliseconds()).Int()
+	return renewed == 1, err
+}
+
+// releaseScript deletes a lock only when the caller still owns it
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// Release implements Releaser
+func (l *RedisLocker) Release(ctx context.Context, job, owner string) error {
+	return releaseScript.Run(ctx, l.client, []string{l.prefix + job}, owner).Err()
+}
+
+// DBLocker keeps locks as leases in the scheduler_locks table
+type DBLocker struct {
+	db        *sql.DB
+	setupOnce sync.Once
+	setupErr  error
+}
+
+// NewDBLocker returns a locker using the application database
+func NewDBLocker(db *sql.DB) *DBLocker {
+	return &DBLocker{db: db}
+}
+
+func (l *DBLocker) ensureTable() error {
+	l.setupOnce.Do(func() {
+		_, l.setupErr = l.db.Exec(`
+			CREATE TABLE IF NOT EXISTS scheduler_locks (
+				job_name VARCHAR(100) PRIMARY KEY,
+				owner VARCHAR(255) NOT NULL,
+				expires_at DATETIME NOT NULL,
+				updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+			)
+		`)
+	})
+	return l.setupErr
+}
+
+// Acquire implements Locker. The row is taken over only once its lease has
+// expired; MySQL applies the assignments in order, so expires_at is renewed
+// exactly when owner ends up being the caller.
+func (l *DBLocker) Acquire(ctx context.Context, job, owner string, ttl time.Duration) (bool, error) {
+	if err := l.ensureTable(); err != nil {
+		return false, fmt.Errorf("failed to create scheduler_locks: %w", err)
+	}
+	if ttl < minLockTTL {
+		ttl = minLockTTL
+	}
+	now := clock.Now().UTC()
+	_, err := l.db.ExecContext(ctx, `
+		INSERT INTO scheduler_locks (job_name, owner, expires_at) VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			owner = IF(expires_at <= ? OR owner = VALUES(owner), VALUES(owner), owner),
+			expires_at = IF(owner = VALUES(owner), VALUES(expires_at), expires_at)
+	`, job, owner, now.Add(ttl), now)
+	if err != nil {
+		return false, err
+	}
+
+	var holder string
+	if err := l.db.QueryRowContext(ctx, `SELECT owner FROM scheduler_locks WHERE job_name = ?`, job).Scan(&holder); err != nil {
+		return false, err
+	}
+	return holder == owner, nil
+}
+
+// Release implements Releaser
+func (l *DBLocker) Release(ctx context.Context, job, owner string) error {
+	if err := l.ensureTable(); err != nil {
+		return fmt.Errorf("failed to create scheduler_locks: %w", err)
+	}
+	_, err := l.db.ExecContext(ctx, `DELETE FROM scheduler_locks WHERE job_name = ? AND owner = ?`, job, owner)
+	return err
+}
@@ -0,0 +1,326 @@
+// Package scheduler runs the application's periodic background jobs. Every
+// job is registered by name with an interval, optional jitter and optional
+// cluster-wide locking, and reports its last run for /api/scheduler.
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/clock"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/metrics"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	jobRuns = metrics.NewCounterVec("scheduler_job_runs_total",
+		"Scheduled job runs by result; skipped runs lost the singleton lock to another replica.", "job", "result")
+	jobDuration = metrics.NewHistogramVec("scheduler_job_duration_seconds",
+		"Time taken by one run of a scheduled job.", nil, "job")
+)
+
+// Job is a named periodic task
+type Job struct {
+	Name     string
+	Interval time.Duration
+	// Jitter adds a random delay of up to this much before every run
+	Jitter time.Duration
+	// Singleton jobs run on one replica per interval; the others skip the tick
+	Singleton bool
+	// RunOnStart runs the job as soon as it is scheduled
+	RunOnStart bool
+	// StartAfter delays the first run; zero waits one interval
+	StartAfter time.Duration
+	Run        func(ctx context.Context) error
+}
+
+// JobStatus reports how a job has been running
+type JobStatus struct {
+	Name         string     `json:"name"`
+	Interval     string     `json:"interval"`
+	Jitter       string     `json:"jitter"`
+	Singleton    bool       `json:"singleton"`
+	Running      bool       `json:"running"`
+	Runs         int64      `json:"runs"`
+	Failures     int64      `json:"failures"`
+	Skipped      int64      `json:"skipped"`
+	LastRun      *time.Time `json:"last_run"`
+	LastDuration string     `json:"last_duration"`
+	LastError    string     `json:"last_error"`
+	LastErrorAt  *time.Time `json:"last_error_at"`
+	NextRun      *time.Time `json:"next_run"`
+}
+
+type entry struct {
+	job    Job
+	cancel context.CancelFunc
+	done   chan struct{}
+	status JobStatus
+}
+
+// Scheduler runs registered jobs on their own goroutines. Intervals are
+// measured from the end of the previous run, so a slow run never overlaps
+// the next one.
+type Scheduler struct {
+	clock  clock.Clock
+	locker Locker
+	owner  string
+
+	mu      sync.Mutex
+	jobs    map[string]*entry
+	ctx     context.Context
+	cancel  context.CancelFunc
+	started bool
+	random  *rand.Rand
+}
+
+// New returns a stopped scheduler. A nil locker runs singleton jobs on
+// every replica.
+func New(c clock.Clock, locker Locker) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	hostname, _ := os.Hostname()
+	return &Scheduler{
+		clock:  c,
+		locker: locker,
+		owner:  fmt.Sprintf("%s/%s", hostname, uuid.New().String()[:8]),
+		jobs:   make(map[string]*entry),
+		ctx:    ctx,
+		cancel: cancel,
+		random: rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+var (
+	defaultScheduler *Scheduler
+	defaultMu        sync.Mutex
+)
+
+// Default returns the process-wide scheduler
+func Default() *Scheduler {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if defaultScheduler == nil {
+		defaultScheduler = New(clock.Get(), nil)
+	}
+	return defaultScheduler
+}
+
+// Owner identifies this scheduler in singleton locks
+func (s *Scheduler) Owner() string {
+	return s.owner
+}
+
+// SetLocker sets the lock used by singleton jobs; call it before Start
+func (s *Scheduler) SetLocker(locker Locker) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.locker = locker
+}
+
+// Register adds a job. Jobs registered after Start begin at once.
+func (s *Scheduler) Register(job Job) error {
+	if job.Name == "" || job.Run == nil {
+		return fmt.Errorf("scheduler job needs a name and a run function")
+	}
+	if job.Interval <= 0 {
+		return fmt.Errorf("scheduler job %s needs a positive interval", job.Name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.jobs[job.Name]; exists {
+		return fmt.Errorf("scheduler job %s is already registered", job.Name)
+	}
+	e := &entry{
+		job: job,
+		status: JobStatus{
+			Name:      job.Name,
+			Interval:  job.Interval.String(),
+			Jitter:    job.Jitter.String(),
+			Singleton: job.Singleton,
+		},
+	}
+	s.jobs[job.Name] = e
+	if s.started {
+		s.launch(e)
+	}
+	return nil
+}
+
+// MustRegister registers a job and logs instead of failing when it cannot
+func (s *Scheduler) MustRegister(job Job) {
+	if err := s.Register(job); err != nil {
+		logrus.Errorf("Failed to schedule job: %v", err)
+	}
+}
+
+// Unregister stops a job and forgets it, waiting for a running run to end
+func (s *Scheduler) Unregister(name string) {
+	s.mu.Lock()
+	e, exists := s.jobs[name]
+	delete(s.jobs, name)
+	s.mu.Unlock()
+
+	if exists && e.cancel != nil {
+		e.cancel()
+		<-e.done
+	}
+}
+
+// Start runs every registered job
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return
+	}
+	s.started = true
+	for _, e := range s.jobs {
+		s.launch(e)
+	}
+	logrus.Infof("Scheduler started with %d jobs", len(s.jobs))
+}
+
+// Stop cancels every job and waits for running runs to return
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	s.cancel()
+	var running []*entry
+	for _, e := range s.jobs {
+		if e.done != nil {
+			running = append(running, e)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, e := range running {
+		<-e.done
+	}
+}
+
+func (s *Scheduler) launch(e *entry) {
+	ctx, cancel := context.WithCancel(s.ctx)
+	e.cancel = cancel
+	e.done = make(chan struct{})
+	go s.loop(ctx, e)
+}
+
+func (s *Scheduler) loop(ctx context.Context, e *entry) {
+	defer close(e.done)
+
+	wait := e.job.Interval
+	if e.job.RunOnStart {
+		wait = 0
+	} else if e.job.StartAfter > 0 {
+		wait = e.job.StartAfter
+	}
+	for {
+		wait += s.jitter(e.job.Jitter)
+		next := s.clock.Now().Add(wait)
+		s.mu.Lock()
+		e.status.NextRun = &next
+		s.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.clock.After(wait):
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		s.run(ctx, e)
+		wait = e.job.Interval
+	}
+}
+
+func (s *Scheduler) jitter(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return time.Duration(s.random.Int63n(int64(max)))
+}
+
+// run executes one run of the job, taking its lock first when it is a singleton
+func (s *Scheduler) run(ctx context.Context, e *entry) {
+	s.mu.Lock()
+	locker := s.locker
+	s.mu.Unlock()
+
+	if e.job.Singleton && locker != nil {
+		acquired, err := locker.Acquire(ctx, e.job.Name, s.owner, e.job.Interval)
+		if err != nil {
+			s.record(e, s.clock.Now(), 0, fmt.Errorf("failed to take lock: %w", err))
+			return
+		}
+		if !acquired {
+			s.mu.Lock()
+			e.status.Skipped++
+			s.mu.Unlock()
+			jobRuns.Inc(e.job.Name, "skipped")
+			return
+		}
+	}
+
+	s.mu.Lock()
+	e.status.Running = true
+	s.mu.Unlock()
+
+	started := s.clock.Now()
+	err := runSafely(ctx, e.job)
+	s.record(e, started, s.clock.Now().Sub(started), err)
+}
+
+// runSafely turns a panicking run into an error so the job keeps its schedule
+func runSafely(ctx context.Context, job Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+			logrus.Errorf("Scheduled job %s panicked: %v\n%s", job.Name, r, debug.Stack())
+		}
+	}()
+	return job.Run(ctx)
+}
+
+func (s *Scheduler) record(e *entry, started time.Time, duration time.Duration, err error) {
+	s.mu.Lock()
+	e.status.Running = false
+	e.status.Runs++
+	e.status.LastRun = &started
+	e.status.LastDuration = duration.String()
+	if err != nil {
+		e.status.Failures++
+		e.status.LastError = err.Error()
+		e.status.LastErrorAt = &started
+	}
+	s.mu.Unlock()
+
+	jobDuration.Observe(duration.Seconds(), e.job.Name)
+	if err != nil {
+		jobRuns.Inc(e.job.Name, "error")
+		logrus.Errorf("Scheduled job %s failed: %v", e.job.Name, err)
+		return
+	}
+	jobRuns.Inc(e.job.Name, "success")
+}
+
+// Status returns the state of every job ordered by name
+func (s *Scheduler) Status() []JobStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	statuses := make([]JobStatus, 0, len(s.jobs))
+	for _, e := range s.jobs {
+		statuses = append(statuses, e.status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
@@ -0,0 +1,161 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var start = time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+
+// memoryLocker grants every lock to the first owner that asks for it
+type memoryLocker struct {
+	mu     sync.Mutex
+	owners map[string]string
+}
+
+func (l *memoryLocker) Acquire(_ context.Context, job, owner string, _ time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.owners == nil {
+		l.owners = map[string]string{}
+	}
+	if _, held := l.owners[job]; !held {
+		l.owners[job] = owner
+	}
+	return l.owners[job] == owner, nil
+}
+
+// tick advances the clock once the job is waiting, then waits for the job to
+// be waiting again so its status is recorded
+func tick(fake *clock.Fake, d time.Duration) {
+	fake.BlockUntil(1)
+	fake.Advance(d)
+	fake.BlockUntil(1)
+}
+
+func TestSchedulerRunsJobsOnInterval(t *testing.T) {
+	fake := clock.NewFake(start)
+	s := New(fake, nil)
+	runs := make(chan time.Time, 10)
+	require.NoError(t, s.Register(Job{
+		Name:     "every-minute",
+		Interval: time.Minute,
+		Run: func(ctx context.Context) error {
+			runs <- fake.Now()
+			return nil
+		},
+	}))
+	s.Start()
+	defer s.Stop()
+
+	tick(fake, 59*time.Second)
+	assert.Len(t, runs, 0, "nothing runs before the first interval")
+
+	tick(fake, time.Second)
+	tick(fake, time.Minute)
+	require.Len(t, runs, 2)
+	assert.Equal(t, start.Add(time.Minute), <-runs)
+	assert.Equal(t, start.Add(2*time.Minute), <-runs)
+
+	status := s.Status()
+	require.Len(t, status, 1)
+	assert.Equal(t, int64(2), status[0].Runs)
+	assert.Equal(t, start.Add(3*time.Minute), *status[0].NextRun)
+}
+
+func TestSchedulerRecordsFailures(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(ctx context.Context) error
+		want string
+	}{
+		{
+			name: "error",
+			run:  func(ctx context.Context) error { return errors.New("database is down") },
+			want: "database is down",
+		},
+		{
+			name: "panic",
+			run:  func(ctx context.Context) error { panic("nil map") },
+			want: "panic: nil map",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := clock.NewFake(start)
+			s := New(fake, nil)
+			require.NoError(t, s.Register(Job{Name: tt.name, Interval: time.Minute, RunOnStart: true, Run: tt.run}))
+			s.Start()
+			defer s.Stop()
+
+			tick(fake, 0)
+			tick(fake, time.Minute)
+
+			status := s.Status()
+			require.Len(t, status, 1)
+			assert.Equal(t, int64(2), status[0].Runs, "the job keeps its schedule")
+			assert.Equal(t, int64(2), status[0].Failures)
+			assert.Equal(t, tt.want, status[0].LastError)
+			assert.Equal(t, start.Add(time.Minute), *status[0].LastErrorAt)
+		})
+	}
+}
+
+func TestSingletonJobsRunOnOneReplica(t *testing.T) {
+	fake := clock.NewFake(start)
+	locker := &memoryLocker{}
+	var mu sync.Mutex
+	runs := map[string]int{}
+	replicas := []*Scheduler{New(fake, locker), New(fake, locker)}
+	for _, s := range replicas {
+		s := s
+		require.NoError(t, s.Register(Job{
+			Name:      "campaign-trigger",
+			Interval:  time.Minute,
+			Singleton: true,
+			Run: func(ctx context.Context) error {
+				mu.Lock()
+				runs[s.Owner()]++
+				mu.Unlock()
+				return nil
+			},
+		}))
+		s.Start()
+		defer s.Stop()
+	}
+
+	for i := 0; i < 3; i++ {
+		fake.BlockUntil(2)
+		fake.Advance(time.Minute)
+	}
+	fake.BlockUntil(2)
+
+	first, second := replicas[0].Status()[0], replicas[1].Status()[0]
+	assert.Equal(t, int64(3), first.Runs+second.Runs)
+	assert.Equal(t, int64(3), first.Skipped+second.Skipped)
+	mu.Lock()
+	assert.Len(t, runs, 1, "only the lock holder runs the job")
+	mu.Unlock()
+}
+
+func TestRegisterRejectsInvalidJobs(t *testing.T) {
+	s := New(clock.NewFake(start), nil)
+	run := func(ctx context.Context) error { return nil }
+
+	assert.Error(t, s.Register(Job{Interval: time.Minute, Run: run}))
+	assert.Error(t, s.Register(Job{Name: "no-run", Interval: time.Minute}))
+	assert.Error(t, s.Register(Job{Name: "no-interval", Run: run}))
+	require.NoError(t, s.Register(Job{Name: "job", Interval: time.Minute, Run: run}))
+	assert.Error(t, s.Register(Job{Name: "job", Interval: time.Minute, Run: run}))
+
+	s.Unregister("job")
+	assert.Empty(t, s.Status())
+}
@@ -128,6 +128,7 @@ func HandleHistorySyncForChats(deviceID string, client *whatsmeow.Client, evt *e
 	logrus.Infof("Processing history sync for chats - device %s", deviceID)
 	
 	chatCount := 0
+	keep := newHistoryKeep(deviceID, evt)
 	for _, conv := range evt.Data.GetConversations() {
 		if conv.GetId() == "" {
 			continue
@@ -139,8 +140,8 @@ func HandleHistorySyncForChats(deviceID string, client *whatsmeow.Client, evt *e
 			continue
 		}
 		
-		// Skip non-personal chats
-		if chatJID.Server != types.DefaultUserServer {
+		// Skip chat types the device's history policy does not keep
+		if !keep.chat(chatJID.String()) {
 			continue
 		}
 		
@@ -165,6 +166,11 @@ func HandleHistorySyncForChats(deviceID string, client *whatsmeow.Client, evt *e
 			lastMessageTime = time.Now()
 		}
 		
+		// Skip chats quiet for longer than the policy keeps history
+		if !keep.message(lastMessageTime.Unix()) {
+			continue
+		}
+		
 		// Store chat
 		err = StoreChat(deviceID, conv.GetId(), name, lastMessageTime)
 		if err == nil {
@@ -177,28 +183,19 @@ func HandleHistorySyncForChats(deviceID string, client *whatsmeow.Client, evt *e
 	// Also process messages
 	HandleHistorySyncForWebView(deviceID, evt)
 	
-	// DISABLED AUTO-SYNC: Now manual sync only via button
-	// Auto-save chats to leads after history sync
-	// cm := GetClientManager()
-	// if client, err := cm.GetClient(deviceID); err == nil && client.Store.ID != nil {
-	// 	// Get user ID from device
-	// 	userRepo := repository.GetUserRepository()
-	// 	devices, _ := userRepo.GetAllDevices()
-	// 	for _, device := range devices {
-	// 		if device.ID == deviceID {
-	// 			AutoSaveChatsToLeads(deviceID, device.UserID)
-	// 			break
-	// 		}
-	// 	}
-	// }
+	// Import chats as leads when the device's history policy asks for it;
+	// otherwise leads are imported with the 'Sync Contacts' button
+	autoImportLeads(deviceID, evt)
 	
-	logrus.Info("History sync complete. Use 'Sync Contacts' button to import contacts to leads.")
+	logrus.Info("History sync complete")
 }
 
-// GetChatsFromDatabase retrieves ONLY chats with actual messages
+// GetChatsFromDatabase retrieves ONLY chats with actual messages within the
+// device's history policy
 func GetChatsFromDatabase(deviceID string) ([]map[string]interface{}, error) {
 	userRepo := repository.GetUserRepository()
 	db := userRepo.DB()
+	cutoff := repository.HistoryPolicy(deviceID).Cutoff(time.Now())
 	
 	// Ensure table exists
 	CreateChatTable()
@@ -225,7 +222,7 @@ func GetChatsFromDatabase(deviceID string) ([]map[string]interface{}, error) {
 				AND chat_jid != 'status@broadcast'
 				AND message_text IS NOT NULL
 				AND message_text != ''
-				AND timestamp > UNIX_TIMESTAMP(DATE_SUB(NOW(), INTERVAL 6 MONTH))
+				AND timestamp >= ?
 			GROUP BY chat_jid
 		) latest
 		INNER JOIN whatsapp_messages wm ON wm.chat_jid = latest.chat_jid 
@@ -235,7 +232,7 @@ func GetChatsFromDatabase(deviceID string) ([]map[string]interface{}, error) {
 		ORDER BY wm.timestamp DESC
 	`
 	
-	rows, err := db.Query(query, deviceID, deviceID, deviceID)
+	rows, err := db.Query(query, deviceID, cutoff, deviceID, deviceID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query chats: %v", err)
 	}
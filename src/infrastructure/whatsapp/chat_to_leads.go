@@ -12,21 +12,24 @@ import (
 	"go.mau.fi/whatsmeow/types"
 )
 
-// AutoSaveChatsToLeads automatically saves chats as leads with duplicate prevention
+// AutoSaveChatsToLeads automatically saves chats as leads with duplicate prevention.
+// The device's history policy sets how far back chats count and the niche,
+// status and trigger the leads are created with.
 func AutoSaveChatsToLeads(deviceID string, userID string) error {
 	logrus.Infof("=== Starting auto-save chats to leads for device: %s ===", deviceID)
+	policy := repository.HistoryPolicy(deviceID)
 	
-	// Get chats from database with messages in last 6 months
+	// Get chats from database with messages within the policy's history
 	chats, err := GetChatsFromDatabase(deviceID)
 	if err != nil {
 		return fmt.Errorf("failed to get chats: %v", err)
 	}
 	
-	logrus.Infof("Retrieved %d chats with conversations in last 6 months for device %s", len(chats), deviceID)
+	logrus.Infof("Retrieved %d chats with conversations in the last %d days for device %s", len(chats), policy.KeepDays, deviceID)
 	
-	// If we have chats from database, use those (they already have 6-month filter)
+	// If we have chats from database, use those (they are already filtered by the policy)
 	if len(chats) > 0 {
-		return saveChatsAsLeads(chats, deviceID, userID)
+		return saveChatsAsLeads(chats, deviceID, userID, policy)
 	}
 	
 	// If no chats in database, try to get from WhatsApp store but only those with recent activity
@@ -51,7 +54,7 @@ func AutoSaveChatsToLeads(deviceID string, userID string) error {
 				}
 				
 				// Check if this contact has recent messages in database
-				hasRecentMessages, err := checkRecentMessages(deviceID, jid.String())
+				hasRecentMessages, err := checkRecentMessages(deviceID, jid.String(), policy.Cutoff(time.Now()))
 				if err == nil && hasRecentMessages {
 					recentContacts[jid] = contact
 				}
@@ -60,7 +63,7 @@ func AutoSaveChatsToLeads(deviceID string, userID string) error {
 			logrus.Infof("Filtered to %d contacts with recent conversations", len(recentContacts))
 			
 			if len(recentContacts) > 0 {
-				return saveContactsAsLeads(recentContacts, deviceID, userID)
+				return saveContactsAsLeads(recentContacts, deviceID, userID, policy)
 			}
 		}
 	}
@@ -69,8 +72,8 @@ func AutoSaveChatsToLeads(deviceID string, userID string) error {
 	return nil
 }
 
-// checkRecentMessages checks if a contact has messages in the last 6 months
-func checkRecentMessages(deviceID string, chatJID string) (bool, error) {
+// checkRecentMessages checks if a contact has messages since a Unix time
+func checkRecentMessages(deviceID string, chatJID string, since int64) (bool, error) {
 	userRepo := repository.GetUserRepository()
 	db := userRepo.DB()
 	
@@ -80,11 +83,11 @@ func checkRecentMessages(deviceID string, chatJID string) (bool, error) {
 		FROM whatsapp_messages 
 		WHERE device_id = ? 
 		AND chat_jid = ?
-		AND timestamp > UNIX_TIMESTAMP(DATE_SUB(NOW(), INTERVAL 6 MONTH))
+		AND timestamp >= ?
 		LIMIT 1
 	`
 	
-	err := db.QueryRow(query, deviceID, chatJID).Scan(&count)
+	err := db.QueryRow(query, deviceID, chatJID, since).Scan(&count)
 	if err != nil {
 		return false, err
 	}
@@ -93,7 +96,7 @@ func checkRecentMessages(deviceID string, chatJID string) (bool, error) {
 }
 
 // saveContactsAsLeads saves WhatsApp contacts directly as leads
-func saveContactsAsLeads(contacts map[types.JID]types.ContactInfo, deviceID string, userID string) error {
+func saveContactsAsLeads(contacts map[types.JID]types.ContactInfo, deviceID string, userID string, policy models.HistoryPolicy) error {
 	leadRepo := repository.GetLeadRepository()
 	userRepo := repository.GetUserRepository()
 	db := userRepo.DB()
@@ -147,12 +150,13 @@ func saveContactsAsLeads(contacts map[types.JID]types.ContactInfo, deviceID stri
 			DeviceID:     deviceID,
 			Name:         name,
 			Phone:        phone,
-			Niche:        "WHATSAPP_IMPORT", // Default niche for imported contacts
+			Niche:        policy.LeadNiche,
 			Status:       "new",
-			TargetStatus: "prospect",
-			Trigger:      "",
+			TargetStatus: policy.LeadStatus,
+			Trigger:      policy.LeadTrigger,
 			Platform:     "whatsapp",
 			Notes:        fmt.Sprintf("Auto-imported from WhatsApp on %s", time.Now().Format("2006-01-02")),
+			Consent:      &models.LeadConsent{Channel: models.ConsentChannelInboundChat, Source: "WhatsApp chat history"},
 			CreatedAt:    time.Now(),
 			UpdatedAt:    time.Now(),
 		}
@@ -172,7 +176,7 @@ func saveContactsAsLeads(contacts map[types.JID]types.ContactInfo, deviceID stri
 }
 
 // saveChatsAsLeads saves chats from database as leads (fallback method)
-func saveChatsAsLeads(chats []map[string]interface{}, deviceID string, userID string) error {
+func saveChatsAsLeads(chats []map[string]interface{}, deviceID string, userID string, policy models.HistoryPolicy) error {
 	
 	leadRepo := repository.GetLeadRepository()
 	userRepo := repository.GetUserRepository()
@@ -213,12 +217,13 @@ func saveChatsAsLeads(chats []map[string]interface{}, deviceID string, userID st
 			DeviceID:     deviceID,
 			Name:         name,
 			Phone:        phone,
-			Niche:        "WHATSAPP_IMPORT", // Default niche for imported contacts
+			Niche:        policy.LeadNiche,
 			Status:       "new",
-			TargetStatus: "prospect",
-			Trigger:      "",
+			TargetStatus: policy.LeadStatus,
+			Trigger:      policy.LeadTrigger,
 			Platform:     "whatsapp",
 			Notes:        fmt.Sprintf("Auto-imported from WhatsApp on %s", time.Now().Format("2006-01-02")),
+			Consent:      &models.LeadConsent{Channel: models.ConsentChannelInboundChat, Source: "WhatsApp chat history"},
 			CreatedAt:    time.Now(),
 			UpdatedAt:    time.Now(),
 		}
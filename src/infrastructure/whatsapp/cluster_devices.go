@@ -0,0 +1,58 @@
+package whatsapp
+
+import (
+	"sort"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/cluster"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp/multidevice"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/clock"
+	"github.com/sirupsen/logrus"
+)
+
+// RebalanceDevices follows a change of cluster membership: devices that now
+// belong to another replica are disconnected straight away, and devices
+// this replica gained are connected once their previous owner has had a
+// heartbeat to let them go.
+func RebalanceDevices() {
+	released := 0
+	for deviceID, conn := range multidevice.GetDeviceManager().GetAllDeviceConnections() {
+		if cluster.Owns(deviceID) {
+			continue
+		}
+		if conn.Client != nil {
+			conn.Client.Disconnect()
+		}
+		GetClientManager().RemoveClient(deviceID)
+		released++
+	}
+	if released > 0 {
+		logrus.Infof("Released %d devices to other replicas", released)
+	}
+
+	clock.AfterFunc(cluster.HandoverDelay(), func() {
+		loaded := multidevice.GetDeviceManager().GetAllDeviceConnections()
+		loadDevices(func(deviceID string) bool {
+			_, connected := loaded[deviceID]
+			return !connected && cluster.Owns(deviceID)
+		})
+	})
+}
+
+// DisconnectAll closes every device connection of this replica without
+// logging out, so the sessions stay paired for the next boot or the replica
+// that takes the devices over. It returns the devices it disconnected.
+func DisconnectAll() []string {
+	var disconnected []string
+	for deviceID, conn := range multidevice.GetDeviceManager().GetAllDeviceConnections() {
+		if conn.Client != nil {
+			conn.Client.Disconnect()
+		}
+		GetClientManager().RemoveClient(deviceID)
+		disconnected = append(disconnected, deviceID)
+	}
+	sort.Strings(disconnected)
+	if len(disconnected) > 0 {
+		logrus.Infof("Disconnected %d devices without logging out", len(disconnected))
+	}
+	return disconnected
+}
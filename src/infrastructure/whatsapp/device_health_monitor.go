@@ -6,6 +6,7 @@ import (
 	"sync"
 	"time"
 	
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/scheduler"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
 	"github.com/sirupsen/logrus"
 	"go.mau.fi/whatsmeow"
@@ -16,8 +17,6 @@ import (
 type DeviceHealthMonitor struct {
 	mu              sync.RWMutex
 	monitorInterval time.Duration
-	ctx             context.Context
-	cancel          context.CancelFunc
 	db              *sqlstore.Container
 	deviceStates    map[string]*DeviceState // Track device connection states
 }
@@ -37,11 +36,8 @@ var (
 // GetDeviceHealthMonitor returns singleton instance
 func GetDeviceHealthMonitor(db *sqlstore.Container) *DeviceHealthMonitor {
 	healthMonitorOnce.Do(func() {
-		ctx, cancel := context.WithCancel(context.Background())
 		healthMonitor = &DeviceHealthMonitor{
 			monitorInterval: 2 * time.Minute, // Increased for 3000 devices
-			ctx:             ctx,
-			cancel:          cancel,
 			db:              db,
 			deviceStates:    make(map[string]*DeviceState),
 		}
@@ -49,39 +45,29 @@ func GetDeviceHealthMonitor(db *sqlstore.Container) *DeviceHealthMonitor {
 	return healthMonitor
 }
 
+// deviceHealthJob names the scheduled health check
+const deviceHealthJob = "device-health-monitor"
+
 // Start begins monitoring device health
 func (dhm *DeviceHealthMonitor) Start() {
-	go dhm.monitorLoop()
+	scheduler.Default().MustRegister(scheduler.Job{
+		Name:       deviceHealthJob,
+		Interval:   dhm.monitorInterval,
+		StartAfter: 30 * time.Second, // Delayed start for 3000 devices
+		Run: func(ctx context.Context) error {
+			dhm.checkAllDevices()
+			return nil
+		},
+	})
 	logrus.Info("Device health monitor started")
 }
 
 // Stop stops the health monitor
 func (dhm *DeviceHealthMonitor) Stop() {
-	if dhm.cancel != nil {
-		dhm.cancel()
-	}
+	scheduler.Default().Unregister(deviceHealthJob)
 	logrus.Info("Device health monitor stopped")
 }
 
-// monitorLoop continuously checks device health
-func (dhm *DeviceHealthMonitor) monitorLoop() {
-	ticker := time.NewTicker(dhm.monitorInterval)
-	defer ticker.Stop()
-	
-	// Initial check after 10 seconds
-	time.Sleep(30 * time.Second) // Delayed start for 3000 devices
-	dhm.checkAllDevices()
-	
-	for {
-		select {
-		case <-dhm.ctx.Done():
-			return
-		case <-ticker.C:
-			dhm.checkAllDevices()
-		}
-	}
-}
-
 // checkAllDevices checks health of all registered devices
 func (dhm *DeviceHealthMonitor) checkAllDevices() {
 	cm := GetClientManager()
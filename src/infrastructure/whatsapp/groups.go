@@ -0,0 +1,342 @@
+package whatsapp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/models"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
+	"github.com/sirupsen/logrus"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types"
+)
+
+// Group lead import states
+const (
+	GroupImportRunning  = "running"
+	GroupImportComplete = "complete"
+	GroupImportFailed   = "failed"
+)
+
+// ErrGroupImportRunning is returned while the group's last import is still going
+var ErrGroupImportRunning = errors.New("an import of this group is already running")
+
+// connectedClient returns the client of a logged-in device
+func connectedClient(deviceID string) (*whatsmeow.Client, error) {
+	client, err := GetClientManager().GetClient(deviceID)
+	if err != nil || client == nil || !client.IsConnected() || client.Store.ID == nil {
+		return nil, fmt.Errorf("device is not connected")
+	}
+	return client, nil
+}
+
+// isSelf reports whether a group participant is the device itself
+func isSelf(client *whatsmeow.Client, participant types.GroupParticipant) bool {
+	own, ownLID := client.Store.ID.User, client.Store.GetLID().User
+	for _, jid := range []types.JID{participant.JID, participant.PhoneNumber, participant.LID} {
+		if jid.User == "" {
+			continue
+		}
+		if jid.User == own || (ownLID != "" && jid.User == ownLID) {
+			return true
+		}
+	}
+	return false
+}
+
+// cachedGroup turns a group's info into its cached metadata
+func cachedGroup(client *whatsmeow.Client, deviceID string, info *types.GroupInfo) models.WhatsAppGroup {
+	group := models.WhatsAppGroup{
+		DeviceID:     deviceID,
+		GroupJID:     info.JID.String(),
+		Name:         info.GroupName.Name,
+		Participants: len(info.Participants),
+		IsAnnounce:   info.GroupAnnounce.IsAnnounce,
+	}
+	for _, participant := range info.Participants {
+		if isSelf(client, participant) {
+			group.IsAdmin = participant.IsAdmin || participant.IsSuperAdmin
+			break
+		}
+	}
+	return group
+}
+
+// RefreshGroups caches the metadata of every group the device belongs to,
+// dropping the groups it left, and returns the groups
+func RefreshGroups(deviceID string) ([]models.WhatsAppGroup, error) {
+	client, err := connectedClient(deviceID)
+	if err != nil {
+		return nil, err
+	}
+	joined, err := client.GetJoinedGroups()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get joined groups: %w", err)
+	}
+
+	groups := make([]models.WhatsAppGroup, 0, len(joined))
+	for _, info := range joined {
+		groups = append(groups, cachedGroup(client, deviceID, info))
+	}
+	if err := repository.GetWhatsAppGroupRepository().ReplaceDeviceGroups(deviceID, groups); err != nil {
+		return nil, fmt.Errorf("failed to cache groups: %w", err)
+	}
+	logrus.Infof("Cached %d groups of device %s", len(groups), deviceID)
+	return groups, nil
+}
+
+// participantPhone returns a participant's phone number, or "" when the
+// group hides it behind a LID
+func participantPhone(participant types.GroupParticipant) string {
+	if participant.JID.Server == types.DefaultUserServer {
+		return participant.JID.User
+	}
+	if participant.PhoneNumber.Server == types.DefaultUserServer {
+		return participant.PhoneNumber.User
+	}
+	return ""
+}
+
+// participantName returns a participant's best known name
+func participantName(client *whatsmeow.Client, participant types.GroupParticipant, phone string) string {
+	if participant.DisplayName != "" {
+		return participant.DisplayName
+	}
+	if phone == "" {
+		return ""
+	}
+	contact, err := client.Store.Contacts.GetContact(context.Background(), types.NewJID(phone, types.DefaultUserServer))
+	if err != nil || !contact.Found {
+		return ""
+	}
+	for _, name := range []string{contact.FullName, contact.PushName, contact.BusinessName, contact.FirstName} {
+		if name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// GroupMembers returns the participants of one of the device's groups,
+// updating the group's cached metadata on the way
+func GroupMembers(deviceID string, groupJID types.JID) (models.WhatsAppGroup, []models.WhatsAppGroupMember, error) {
+	client, err := connectedClient(deviceID)
+	if err != nil {
+		return models.WhatsAppGroup{}, nil, err
+	}
+	info, err := client.GetGroupInfo(groupJID)
+	if err != nil {
+		return models.WhatsAppGroup{}, nil, fmt.Errorf("failed to get group info: %w", err)
+	}
+
+	group := cachedGroup(client, deviceID, info)
+	if err := repository.GetWhatsAppGroupRepository().SaveGroup(deviceID, group); err != nil {
+		logrus.Warnf("Failed to cache group %s of device %s: %v", group.GroupJID, deviceID, err)
+	}
+	group.RefreshedAt = time.Now()
+
+	members := make([]models.WhatsAppGroupMember, 0, len(info.Participants))
+	for _, participant := range info.Participants {
+		phone := participantPhone(participant)
+		members = append(members, models.WhatsAppGroupMember{
+			JID:          participant.JID.String(),
+			Phone:        phone,
+			Name:         participantName(client, participant, phone),
+			IsAdmin:      participant.IsAdmin,
+			IsSuperAdmin: participant.IsSuperAdmin,
+			IsSelf:       isSelf(client, participant),
+		})
+	}
+	return group, members, nil
+}
+
+// GroupLeadOptions are what the leads imported from a group are created with
+type GroupLeadOptions struct {
+	Niche        string `json:"niche"`
+	TargetStatus string `json:"target_status"`
+	Trigger      string `json:"trigger"`
+}
+
+// GroupImport is the progress of importing a group's participants as leads
+type GroupImport struct {
+	DeviceID   string    `json:"device_id"`
+	GroupJID   string    `json:"group_jid"`
+	GroupName  string    `json:"group_name"`
+	Tag        string    `json:"tag"` // the tag every imported lead is given
+	Status     string    `json:"status"`
+	Error      string    `json:"error,omitempty"`
+	Total      int       `json:"total"`
+	Created    int       `json:"created"`
+	Existing   int       `json:"existing"`   // already a lead of the device, only tagged
+	Suppressed int       `json:"suppressed"` // on the user's suppression list
+	Skipped    int       `json:"skipped"`    // the device itself, hidden numbers and repeats
+	StartedAt  time.Time `json:"started_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+var (
+	groupImportsMu sync.Mutex
+	groupImports   = map[string]*GroupImport{}
+	groupMembers   = GroupMembers // how imports fetch a group's participants
+)
+
+// SetGroupMembersSource replaces how group imports fetch a group's
+// participants and returns a function that restores the previous one
+func SetGroupMembersSource(source func(deviceID string, groupJID types.JID) (models.WhatsAppGroup, []models.WhatsAppGroupMember, error)) (restore func()) {
+	groupImportsMu.Lock()
+	previous := groupMembers
+	groupMembers = source
+	groupImportsMu.Unlock()
+	return func() {
+		groupImportsMu.Lock()
+		groupMembers = previous
+		groupImportsMu.Unlock()
+	}
+}
+
+// GroupLeadTag is the tag of the leads imported from a group
+func GroupLeadTag(groupJID types.JID) string {
+	return "group:" + groupJID.User
+}
+
+// GetGroupImport returns the progress of the last lead import of a group
+func GetGroupImport(deviceID, groupJID string) (GroupImport, bool) {
+	groupImportsMu.Lock()
+	defer groupImportsMu.Unlock()
+	job, ok := groupImports[backfillKey(deviceID, groupJID)]
+	if !ok {
+		return GroupImport{}, false
+	}
+	return *job, true
+}
+
+// ImportGroupLeads starts importing the participants of one of the device's
+// groups as leads of the user in the background. Suppressed phones are left
+// out, existing leads of the device are not duplicated, and every lead is
+// tagged with the group it came from.
+func ImportGroupLeads(deviceID, userID string, groupJID types.JID, options GroupLeadOptions) (GroupImport, error) {
+	key := backfillKey(deviceID, groupJID.String())
+	now := time.Now()
+	job := &GroupImport{
+		DeviceID:  deviceID,
+		GroupJID:  groupJID.String(),
+		Tag:       GroupLeadTag(groupJID),
+		Status:    GroupImportRunning,
+		StartedAt: now,
+		UpdatedAt: now,
+	}
+
+	// Reserve the group before fetching its members, so a second request
+	// for it is turned away rather than importing the same leads again
+	groupImportsMu.Lock()
+	if running, ok := groupImports[key]; ok && running.Status == GroupImportRunning {
+		groupImportsMu.Unlock()
+		return GroupImport{}, ErrGroupImportRunning
+	}
+	groupImports[key] = job
+	fetchMembers := groupMembers
+	groupImportsMu.Unlock()
+
+	group, members, err := fetchMembers(deviceID, groupJID)
+	groupImportsMu.Lock()
+	job.UpdatedAt = time.Now()
+	if err != nil {
+		job.Status = GroupImportFailed
+		job.Error = err.Error()
+		groupImportsMu.Unlock()
+		return GroupImport{}, err
+	}
+	job.GroupJID = group.GroupJID
+	job.GroupName = group.Name
+	job.Total = len(members)
+	view := *job
+	groupImportsMu.Unlock()
+
+	go importGroupMembers(job, userID, members, options)
+	return view, nil
+}
+
+// importGroupMembers creates the leads of a group import, counting each
+// participant against the job
+func importGroupMembers(job *GroupImport, userID string, members []models.WhatsAppGroupMember, options GroupLeadOptions) {
+	update := func(change func(*GroupImport)) {
+		groupImportsMu.Lock()
+		change(job)
+		job.UpdatedAt = time.Now()
+		groupImportsMu.Unlock()
+	}
+
+	suppressed, err := repository.GetSuppressionRepository().SuppressedPhones(userID)
+	if err != nil {
+		update(func(j *GroupImport) {
+			j.Status = GroupImportFailed
+			j.Error = fmt.Sprintf("failed to load suppression list: %v", err)
+		})
+		return
+	}
+
+	leadRepo := repository.GetLeadRepository()
+	tags := repository.GetSequenceFlowRepository()
+	seen := map[string]bool{}
+	for _, member := range members {
+		phone := member.Phone
+		switch {
+		case phone == "" || member.IsSelf || seen[phone]:
+			update(func(j *GroupImport) { j.Skipped++ })
+			continue
+		case suppressed[repository.SuppressionPhone(phone)]:
+			update(func(j *GroupImport) { j.Suppressed++ })
+			continue
+		}
+		seen[phone] = true
+
+		if existing, err := leadRepo.GetLeadByDeviceUserPhone(job.DeviceID, userID, phone); err == nil && existing != nil {
+			if err := tags.AddLeadTag(userID, phone, job.Tag, userID); err != nil {
+				logrus.Warnf("Failed to tag lead %s with %s: %v", phone, job.Tag, err)
+			}
+			update(func(j *GroupImport) { j.Existing++ })
+			continue
+		}
+
+		name := member.Name
+		if name == "" {
+			name = phone
+		}
+		lead := &models.Lead{
+			UserID:       userID,
+			DeviceID:     job.DeviceID,
+			Name:         name,
+			Phone:        phone,
+			Niche:        options.Niche,
+			Status:       "new",
+			TargetStatus: options.TargetStatus,
+			Trigger:      options.Trigger,
+			Platform:     "whatsapp",
+			Notes:        fmt.Sprintf("Imported from WhatsApp group %s on %s", job.GroupName, time.Now().Format("2006-01-02")),
+			Consent: &models.LeadConsent{
+				Channel: models.ConsentChannelGroup,
+				Source:  "WhatsApp group " + job.GroupName,
+			},
+		}
+		if err := leadRepo.CreateLead(lead); err != nil {
+			logrus.Warnf("Failed to create lead for %s from group %s: %v", phone, job.GroupJID, err)
+			update(func(j *GroupImport) { j.Skipped++ })
+			continue
+		}
+		if err := tags.AddLeadTag(userID, phone, job.Tag, userID); err != nil {
+			logrus.Warnf("Failed to tag lead %s with %s: %v", phone, job.Tag, err)
+		}
+		update(func(j *GroupImport) { j.Created++ })
+	}
+
+	var done GroupImport
+	update(func(j *GroupImport) {
+		j.Status = GroupImportComplete
+		done = *j
+	})
+	logrus.Infof("Imported group %s of device %s: %d created, %d existing, %d suppressed, %d skipped",
+		done.GroupJID, done.DeviceID, done.Created, done.Existing, done.Suppressed, done.Skipped)
+}
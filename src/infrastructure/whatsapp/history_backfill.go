@@ -0,0 +1,170 @@
+package whatsapp
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waHistorySync"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// Backfill states
+const (
+	BackfillRequested = "requested" // asked the phone, nothing received yet
+	BackfillReceiving = "receiving" // some messages arrived, more may follow
+	BackfillComplete  = "complete"
+	BackfillTimedOut  = "timed_out" // the phone stopped answering
+)
+
+// backfillTimeout is how long a backfill waits for the phone before it is
+// reported as timed out
+const backfillTimeout = 2 * time.Minute
+
+var (
+	// ErrNothingToBackfill is returned for a chat without stored messages,
+	// since the phone is asked for messages older than the oldest one
+	ErrNothingToBackfill = errors.New("chat has no stored messages to backfill from")
+	// ErrBackfillRunning is returned while the chat's last backfill is still going
+	ErrBackfillRunning = errors.New("a backfill of this chat is already running")
+)
+
+// Backfill is the progress of an on-demand request for a chat's older
+// messages. The phone answers with a history sync of type ON_DEMAND.
+type Backfill struct {
+	DeviceID    string    `json:"device_id"`
+	ChatJID     string    `json:"chat_jid"`
+	Status      string    `json:"status"`
+	Requested   int       `json:"requested"`
+	Received    int       `json:"received"`
+	Progress    int       `json:"progress"` // percent of the requested messages received
+	MoreOnPhone bool      `json:"more_on_phone"`
+	Before      time.Time `json:"before"` // the oldest message stored when it was requested
+	RequestedAt time.Time `json:"requested_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+var (
+	backfillsMu sync.Mutex
+	backfills   = map[string]*Backfill{}
+)
+
+func backfillKey(deviceID, chatJID string) string {
+	return deviceID + "|" + chatJID
+}
+
+// GetBackfill returns the progress of the last backfill of a chat
+func GetBackfill(deviceID, chatJID string) (Backfill, bool) {
+	backfillsMu.Lock()
+	defer backfillsMu.Unlock()
+	backfill, ok := backfills[backfillKey(deviceID, chatJID)]
+	if !ok {
+		return Backfill{}, false
+	}
+	return backfill.snapshot(time.Now()), true
+}
+
+// snapshot returns a copy with its timeout and progress worked out
+func (b *Backfill) snapshot(now time.Time) Backfill {
+	view := *b
+	if (view.Status == BackfillRequested || view.Status == BackfillReceiving) && now.Sub(view.UpdatedAt) > backfillTimeout {
+		view.Status = BackfillTimedOut
+	}
+	switch {
+	case view.Status == BackfillComplete:
+		view.Progress = 100
+	case view.Requested > 0:
+		view.Progress = min(99, view.Received*100/view.Requested)
+	}
+	return view
+}
+
+// RequestBackfill asks the device's phone for up to count messages of a chat
+// older than the oldest one stored
+func RequestBackfill(deviceID string, chat types.JID, count int) (Backfill, error) {
+	client, err := GetClientManager().GetClient(deviceID)
+	if err != nil || client == nil || !client.IsConnected() || client.Store.ID == nil {
+		return Backfill{}, fmt.Errorf("device is not connected")
+	}
+
+	key := backfillKey(deviceID, chat.String())
+	backfillsMu.Lock()
+	if running, ok := backfills[key]; ok {
+		status := running.snapshot(time.Now()).Status
+		if status == BackfillRequested || status == BackfillReceiving {
+			backfillsMu.Unlock()
+			return Backfill{}, ErrBackfillRunning
+		}
+	}
+	backfillsMu.Unlock()
+
+	var messageID, senderJID string
+	var timestamp int64
+	err = repository.GetUserRepository().DB().QueryRow(`
+		SELECT message_id, sender_jid, timestamp FROM whatsapp_messages
+		WHERE device_id = ? AND chat_jid = ?
+		ORDER BY timestamp ASC LIMIT 1
+	`, deviceID, chat.String()).Scan(&messageID, &senderJID, &timestamp)
+	if err == sql.ErrNoRows {
+		return Backfill{}, ErrNothingToBackfill
+	}
+	if err != nil {
+		return Backfill{}, err
+	}
+
+	sender, _ := types.ParseJID(senderJID)
+	oldest := &types.MessageInfo{
+		MessageSource: types.MessageSource{Chat: chat, Sender: sender, IsFromMe: sender.User == client.Store.ID.User},
+		ID:            messageID,
+		Timestamp:     time.Unix(timestamp, 0),
+	}
+	_, err = client.SendMessage(context.Background(), client.Store.ID.ToNonAD(),
+		client.BuildHistorySyncRequest(oldest, count), whatsmeow.SendRequestExtra{Peer: true})
+	if err != nil {
+		return Backfill{}, fmt.Errorf("failed to send backfill request: %w", err)
+	}
+
+	now := time.Now()
+	backfill := &Backfill{
+		DeviceID:    deviceID,
+		ChatJID:     chat.String(),
+		Status:      BackfillRequested,
+		Requested:   count,
+		Before:      oldest.Timestamp,
+		RequestedAt: now,
+		UpdatedAt:   now,
+	}
+	backfillsMu.Lock()
+	backfills[key] = backfill
+	view := backfill.snapshot(now)
+	backfillsMu.Unlock()
+	return view, nil
+}
+
+// recordBackfill counts the messages an on-demand history sync stored for a
+// conversation against the chat's backfill
+func recordBackfill(deviceID string, evt *events.HistorySync, conv *waHistorySync.Conversation, stored int) {
+	if evt.Data.GetSyncType() != waHistorySync.HistorySync_ON_DEMAND {
+		return
+	}
+	backfillsMu.Lock()
+	defer backfillsMu.Unlock()
+	backfill, ok := backfills[backfillKey(deviceID, conv.GetId())]
+	if !ok || backfill.Status == BackfillComplete {
+		return
+	}
+	backfill.Received += stored
+	backfill.Status = BackfillReceiving
+	if conv.GetEndOfHistoryTransfer() || backfill.Received >= backfill.Requested {
+		backfill.Status = BackfillComplete
+		backfill.MoreOnPhone = conv.GetEndOfHistoryTransferType() !=
+			waHistorySync.Conversation_COMPLETE_AND_NO_MORE_MESSAGE_REMAIN_ON_PRIMARY
+	}
+	backfill.UpdatedAt = time.Now()
+}
@@ -0,0 +1,70 @@
+package whatsapp
+
+import (
+	"sync"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/models"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
+	"github.com/sirupsen/logrus"
+	"go.mau.fi/whatsmeow/proto/waHistorySync"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// historyKeep decides which chats and messages of one history sync are
+// stored. Pushed history follows the device's policy; an on-demand backfill
+// is stored whole since the user asked for exactly that chat and period.
+type historyKeep struct {
+	policy   models.HistoryPolicy
+	onDemand bool
+	cutoff   int64
+}
+
+func newHistoryKeep(deviceID string, evt *events.HistorySync) historyKeep {
+	policy := repository.HistoryPolicy(deviceID)
+	return historyKeep{
+		policy:   policy,
+		onDemand: evt.Data.GetSyncType() == waHistorySync.HistorySync_ON_DEMAND,
+		cutoff:   policy.Cutoff(time.Now()),
+	}
+}
+
+// chat reports whether any history of a chat is stored
+func (k historyKeep) chat(chatJID string) bool {
+	return k.onDemand || k.policy.KeepsChat(chatJID)
+}
+
+// message reports whether a message of a kept chat sent at a Unix time is stored
+func (k historyKeep) message(timestamp int64) bool {
+	return k.onDemand || timestamp >= k.cutoff
+}
+
+// leadImports holds the devices whose chats are being imported as leads, so
+// the chunks of one sync do not start overlapping imports
+var leadImports sync.Map
+
+// autoImportLeads imports a device's chats as leads in the background when
+// its policy asks for it. Called after each pushed history sync chunk;
+// existing leads are skipped, so repeated imports only add new chats.
+func autoImportLeads(deviceID string, evt *events.HistorySync) {
+	if evt.Data.GetSyncType() == waHistorySync.HistorySync_ON_DEMAND {
+		return
+	}
+	if !repository.HistoryPolicy(deviceID).AutoLeads {
+		return
+	}
+	if _, running := leadImports.LoadOrStore(deviceID, true); running {
+		return
+	}
+	go func() {
+		defer leadImports.Delete(deviceID)
+		device, err := repository.GetUserRepository().GetDeviceByID(deviceID)
+		if err != nil {
+			logrus.Warnf("Failed to import leads of device %s: %v", deviceID, err)
+			return
+		}
+		if err := AutoSaveChatsToLeads(deviceID, device.UserID); err != nil {
+			logrus.Warnf("Failed to import leads of device %s: %v", deviceID, err)
+		}
+	}()
+}
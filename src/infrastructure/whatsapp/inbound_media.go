@@ -0,0 +1,191 @@
+package whatsapp
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/media"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
+	"github.com/sirupsen/logrus"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+)
+
+// inboundMedia is the downloadable part of a received media message
+type inboundMedia struct {
+	kind      string
+	file      whatsmeow.DownloadableMessage
+	mimeType  string
+	fileName  string
+	length    uint64
+	thumbnail []byte
+}
+
+var (
+	inboundMediaSlots     chan struct{}
+	inboundMediaSlotsOnce sync.Once
+	// inboundMediaPending stops a message handled by several event paths
+	// from being downloaded more than once at a time
+	inboundMediaPending sync.Map
+)
+
+// findInboundMedia returns the media of a message, or nil for messages without any
+func findInboundMedia(msg *waE2E.Message) *inboundMedia {
+	if msg == nil {
+		return nil
+	}
+	if img := msg.GetImageMessage(); img != nil {
+		return &inboundMedia{kind: "image", file: img, mimeType: img.GetMimetype(),
+			length: img.GetFileLength(), thumbnail: img.GetJPEGThumbnail()}
+	}
+	if video := msg.GetVideoMessage(); video != nil {
+		return &inboundMedia{kind: "video", file: video, mimeType: video.GetMimetype(),
+			length: video.GetFileLength(), thumbnail: video.GetJPEGThumbnail()}
+	}
+	if audio := msg.GetAudioMessage(); audio != nil {
+		return &inboundMedia{kind: "audio", file: audio, mimeType: audio.GetMimetype(),
+			length: audio.GetFileLength()}
+	}
+	doc := msg.GetDocumentMessage()
+	if doc == nil {
+		doc = msg.GetDocumentWithCaptionMessage().GetMessage().GetDocumentMessage()
+	}
+	if doc != nil {
+		return &inboundMedia{kind: "document", file: doc, mimeType: doc.GetMimetype(),
+			fileName: doc.GetFileName(), length: doc.GetFileLength(), thumbnail: doc.GetJPEGThumbnail()}
+	}
+	if sticker := msg.GetStickerMessage(); sticker != nil {
+		return &inboundMedia{kind: "sticker", file: sticker, mimeType: sticker.GetMimetype(),
+			length: sticker.GetFileLength()}
+	}
+	return nil
+}
+
+// inboundMediaLimit is the largest file of a kind that is downloaded
+func inboundMediaLimit(kind string) int64 {
+	limits := config.Current().InboundMedia
+	switch kind {
+	case "image":
+		return limits.MaxImageSize
+	case "video":
+		return limits.MaxVideoSize
+	case "audio":
+		return limits.MaxAudioSize
+	case "document":
+		return limits.MaxDocumentSize
+	case "sticker":
+		return limits.MaxStickerSize
+	}
+	return 0
+}
+
+// CaptureInboundMedia downloads a stored message's media into the media
+// store in the background. Downloads run on a bounded pool so a large
+// history sync cannot flood WhatsApp's CDN.
+func CaptureInboundMedia(deviceID, messageID string, msg *waE2E.Message) {
+	if !config.InboundMediaEnabled || messageID == "" {
+		return
+	}
+	found := findInboundMedia(msg)
+	if found == nil {
+		return
+	}
+	if limit := inboundMediaLimit(found.kind); found.length > 0 && int64(found.length) > limit {
+		logrus.Infof("Not downloading %s %s for device %s: %d bytes is over the %d byte limit",
+			found.kind, messageID, deviceID, found.length, limit)
+		return
+	}
+
+	key := deviceID + "|" + messageID
+	if _, busy := inboundMediaPending.LoadOrStore(key, struct{}{}); busy {
+		return
+	}
+	inboundMediaSlotsOnce.Do(func() {
+		workers := config.InboundMediaWorkers
+		if workers < 1 {
+			workers = 1
+		}
+		inboundMediaSlots = make(chan struct{}, workers)
+	})
+
+	go func() {
+		defer inboundMediaPending.Delete(key)
+		inboundMediaSlots <- struct{}{}
+		defer func() { <-inboundMediaSlots }()
+
+		if err := captureInboundMedia(deviceID, messageID, found); err != nil {
+			logrus.Warnf("Failed to capture %s %s for device %s: %v", found.kind, messageID, deviceID, err)
+		}
+	}()
+}
+
+func captureInboundMedia(deviceID, messageID string, found *inboundMedia) error {
+	repo := repository.GetWhatsAppRepository()
+	if existing, err := repo.GetMessageMedia(deviceID, messageID); err != nil {
+		return err
+	} else if existing != nil {
+		return nil
+	}
+
+	client, err := GetClientManager().GetClient(deviceID)
+	if err != nil {
+		return err
+	}
+	library, err := media.GetLibrary()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(config.Current().Media.FetchTimeoutSeconds)*time.Second)
+	defer cancel()
+	data, err := client.Download(ctx, found.file)
+	if err != nil {
+		return fmt.Errorf("download failed: %w", err)
+	}
+	if limit := inboundMediaLimit(found.kind); int64(len(data)) > limit {
+		return fmt.Errorf("%d bytes is over the %d byte limit", len(data), limit)
+	}
+
+	stored, err := library.Store(ctx, data, found.mimeType, found.thumbnail)
+	if err != nil {
+		return err
+	}
+	if err := repo.SaveMessageMedia(deviceID, messageID, &repository.MessageMedia{
+		SHA256:       stored.SHA256,
+		MimeType:     stored.MimeType,
+		Size:         stored.Size,
+		FileName:     found.fileName,
+		StorageKey:   stored.StorageKey,
+		ThumbnailKey: stored.ThumbnailKey,
+	}); err != nil {
+		return err
+	}
+	logrus.Debugf("Captured %s %s for device %s (%d bytes)", found.kind, messageID, deviceID, stored.Size)
+	return nil
+}
+
+// MessageMediaURL is where a message's stored media is served
+func MessageMediaURL(deviceID, messageID string) string {
+	return "/api/devices/" + url.PathEscape(deviceID) + "/media/" + url.PathEscape(messageID)
+}
+
+// messageMediaFields describes a message's stored media for the web view
+func messageMediaFields(deviceID, messageID, mimeType string, size int64, fileName string, hasThumbnail bool) map[string]interface{} {
+	mediaURL := MessageMediaURL(deviceID, messageID)
+	fields := map[string]interface{}{
+		"url":      mediaURL,
+		"mimetype": mimeType,
+		"size":     size,
+	}
+	if fileName != "" {
+		fields["file_name"] = fileName
+	}
+	if hasThumbnail {
+		fields["thumbnail"] = mediaURL + "/thumbnail"
+	}
+	return fields
+}
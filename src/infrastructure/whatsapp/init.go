@@ -11,6 +11,8 @@ import (
 
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
 	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/metrics"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/tracing"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/ui/websocket"
@@ -48,7 +50,7 @@ type evtMessage struct {
 // Global variables
 var (
 	cli           *whatsmeow.Client
-	log           waLog.Logger
+	log           waLog.Logger = waLog.Noop
 	historySyncID int32
 	startupTime   = time.Now().Unix()
 )
@@ -146,6 +148,12 @@ func handler(ctx context.Context, rawEvt interface{}) {
 	}
 }
 
+// HandleEvent dispatches an event as if the connected client had received
+// it; the simulation harness uses it to deliver fake receipts and replies
+func HandleEvent(ctx context.Context, rawEvt interface{}) {
+	handler(ctx, rawEvt)
+}
+
 // Event handler functions
 
 func handleDeleteForMe(_ context.Context, evt *events.DeleteForMe) {
@@ -265,9 +273,6 @@ func handleMessage(ctx context.Context, evt *events.Message) {
 		}
 	}
 
-	// Handle image message if present
-	handleImageMessage(ctx, evt)
-
 	// Handle auto-reply if configured
 	handleAutoReply(evt)
 
@@ -292,16 +297,6 @@ func buildMessageMetaParts(evt *events.Message) []string {
 	return metaParts
 }
 
-func handleImageMessage(ctx context.Context, evt *events.Message) {
-	if img := evt.Message.GetImageMessage(); img != nil {
-		if path, err := ExtractMedia(ctx, config.PathStorages, img); err != nil {
-			log.Errorf("Failed to download image: %v", err)
-		} else {
-			log.Infof("Image downloaded to %s", path)
-		}
-	}
-}
-
 func handleAutoReply(evt *events.Message) {
 	if config.WhatsappAutoReplyMessage != "" &&
 		!isGroupJid(evt.Info.Chat.String()) &&
@@ -317,7 +312,7 @@ func handleAutoReply(evt *events.Message) {
 }
 
 func handleWebhookForward(ctx context.Context, evt *events.Message) {
-	if len(config.WhatsappWebhook) > 0 &&
+	if urls, _ := config.Webhooks(); len(urls) > 0 &&
 		!strings.Contains(evt.Info.SourceString(), "broadcast") &&
 		!isFromMySelf(evt.Info.SourceString()) {
 		go func(evt *events.Message) {
@@ -331,6 +326,7 @@ func handleWebhookForward(ctx context.Context, evt *events.Message) {
 func handleReceipt(ctx context.Context, evt *events.Receipt) {
 	if evt.Type == types.ReceiptTypeRead || evt.Type == types.ReceiptTypeReadSelf {
 		log.Infof("%v was read by %s at %s", evt.MessageIDs, evt.SourceString(), evt.Timestamp)
+		recordReceipt(evt, "read")
 		// Update message status to "read" in database
 		if analyticsRepo := ctx.Value("analyticsRepo"); analyticsRepo != nil {
 			if repo, ok := analyticsRepo.(*repository.MessageAnalyticsRepository); ok {
@@ -341,6 +337,7 @@ func handleReceipt(ctx context.Context, evt *events.Receipt) {
 		}
 	} else if evt.Type == types.ReceiptTypeDelivered {
 		log.Infof("%s was delivered to %s at %s", evt.MessageIDs[0], evt.SourceString(), evt.Timestamp)
+		recordReceipt(evt, "delivered")
 		// Update message status to "delivered" in database
 		if analyticsRepo := ctx.Value("analyticsRepo"); analyticsRepo != nil {
 			if repo, ok := analyticsRepo.(*repository.MessageAnalyticsRepository); ok {
@@ -352,6 +349,30 @@ func handleReceipt(ctx context.Context, evt *events.Receipt) {
 	}
 }
 
+// recordReceipt counts a receipt, stamps it on the broadcast messages it
+// is for and, for messages sent by a traced broadcast, adds it to the
+// message's trace
+func recordReceipt(evt *events.Receipt, receiptType string) {
+	// A read receipt from one of our own devices says nothing about the recipient
+	if evt.Type != types.ReceiptTypeReadSelf {
+		if err := repository.GetBroadcastRepository().RecordReceipt(evt.MessageIDs, receiptType, evt.Timestamp); err != nil {
+			log.Warnf("Failed to record %s receipt: %v", receiptType, err)
+		}
+	}
+	for _, msgID := range evt.MessageIDs {
+		metrics.ReceiptsReceived.Inc(receiptType)
+		sc, ok := tracing.Recall(msgID)
+		if !ok {
+			continue
+		}
+		ctx := tracing.ContextWithTraceparent(context.Background(), sc.Traceparent())
+		_, span := tracing.Start(ctx, "whatsapp.receipt", tracing.KindConsumer,
+			tracing.String("receipt.type", receiptType), tracing.String("whatsapp.message_id", msgID),
+			tracing.String("receipt.from", evt.SourceString()))
+		span.End()
+	}
+}
+
 func handlePresence(_ context.Context, evt *events.Presence) {
 	if evt.Unavailable {
 		if evt.LastSeen.IsZero() {
@@ -385,6 +406,7 @@ func handleHistorySync(_ context.Context, evt *events.HistorySync) {
 				// Process each conversation
 				conversationCount := 0
 				messageCount := 0
+				keep := newHistoryKeep(deviceID, evt)
 				
 				for _, conv := range evt.Data.GetConversations() {
 					if conv.GetId() == "" {
@@ -397,8 +419,8 @@ func handleHistorySync(_ context.Context, evt *events.HistorySync) {
 						continue
 					}
 					
-					// Skip non-personal chats
-					if chatJID.Server != types.DefaultUserServer {
+					// Skip chat types the device's history policy does not keep
+					if !keep.chat(chatJID.String()) {
 						continue
 					}
 					
@@ -441,10 +463,15 @@ func handleHistorySync(_ context.Context, evt *events.HistorySync) {
 						messageID := webMsg.GetKey().GetId()
 						timestamp := webMsg.GetMessageTimestamp()
 						isFromMe := webMsg.GetKey().GetFromMe()
+						if !keep.message(int64(timestamp)) {
+							continue
+						}
 						
 						var senderJID string
 						if isFromMe {
 							senderJID = client.Store.ID.String()
+						} else if webMsg.GetParticipant() != "" {
+							senderJID = webMsg.GetParticipant()
 						} else {
 							senderJID = chatJID.String()
 						}
@@ -1,13 +1,10 @@
 package whatsapp
 
 import (
-	"context"
-	"path/filepath"
 	"github.com/sirupsen/logrus"
 	"go.mau.fi/whatsmeow/types"
 	"go.mau.fi/whatsmeow/types/events"
 	waProto "go.mau.fi/whatsmeow/binary/proto"
-	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
 )
 
 // HandleMessageForWebView stores messages for WhatsApp Web view
@@ -32,10 +29,9 @@ func HandleMessageForWebView(deviceID string, evt *events.Message) {
 	
 	logrus.Infof("=== Received message in chat %s from %s ===", evt.Info.Chat.String(), evt.Info.Sender.String())
 	
-	// Extract message text and handle images
+	// Extract message text; media is downloaded separately once stored
 	messageText := extractMessageText(evt)
 	messageType := "text"
-	mediaURL := ""
 	
 	// Check for different message types
 	if imageMsg := evt.Message.GetImageMessage(); imageMsg != nil {
@@ -43,16 +39,6 @@ func HandleMessageForWebView(deviceID string, evt *events.Message) {
 		if caption := imageMsg.GetCaption(); caption != "" {
 			messageText = caption
 		}
-		
-		// Download and store image
-		if media, err := ExtractMedia(context.Background(), config.PathStorages, imageMsg); err == nil {
-			// Convert to web-accessible URL - use just the filename
-			filename := filepath.Base(media.MediaPath)
-			mediaURL = "/media/" + filename
-			logrus.Infof("Downloaded image to: %s, URL: %s", media.MediaPath, mediaURL)
-		} else {
-			logrus.Errorf("Failed to download image: %v", err)
-		}
 	} else if evt.Message.GetVideoMessage() != nil {
 		messageType = "video"
 		if caption := evt.Message.GetVideoMessage().GetCaption(); caption != "" {
@@ -65,18 +51,20 @@ func HandleMessageForWebView(deviceID string, evt *events.Message) {
 		if fileName := evt.Message.GetDocumentMessage().GetFileName(); fileName != "" {
 			messageText = "📄 " + fileName
 		}
+	} else if evt.Message.GetStickerMessage() != nil {
+		messageType = "sticker"
 	}
 	
-	// Store message with media URL
-	StoreWhatsAppMessageWithMedia(
+	// Store message, then fetch its media into the media store
+	StoreWhatsAppMessage(
 		deviceID, 
 		evt.Info.Chat.String(), 
 		evt.Info.ID, 
 		evt.Info.Sender.String(), 
 		messageText, 
 		messageType,
-		mediaURL,
 	)
+	CaptureInboundMedia(deviceID, evt.Info.ID, evt.Message)
 	
 	// Send WebSocket notification for real-time update
 	NotifyMessageUpdate(deviceID, evt.Info.Chat.String(), messageText)
@@ -96,6 +84,7 @@ func HandleHistorySyncForWebView(deviceID string, evt *events.HistorySync) {
 	}
 	
 	messageCount := 0
+	keep := newHistoryKeep(deviceID, evt)
 	
 	// Process conversations from history sync
 	for _, conv := range evt.Data.GetConversations() {
@@ -110,12 +99,13 @@ func HandleHistorySyncForWebView(deviceID string, evt *events.HistorySync) {
 			continue
 		}
 		
-		// Skip groups and broadcasts for WhatsApp Web
-		if chatJID.Server != types.DefaultUserServer {
+		// Skip chat types the device's history policy does not keep
+		if !keep.chat(chatJID.String()) {
 			continue
 		}
 		
 		// Process messages in this conversation
+		stored := 0
 		for _, historyMsg := range conv.GetMessages() {
 			webMsg := historyMsg.GetMessage()
 			if webMsg == nil {
@@ -133,6 +123,9 @@ func HandleHistorySyncForWebView(deviceID string, evt *events.HistorySync) {
 			messageID := webMsg.GetKey().GetId()
 			senderJID := webMsg.GetKey().GetFromMe()
 			timestamp := webMsg.GetMessageTimestamp()
+			if !keep.message(int64(timestamp)) {
+				continue
+			}
 			
 			// Get sender JID string
 			var senderStr string
@@ -160,9 +153,14 @@ func HandleHistorySyncForWebView(deviceID string, evt *events.HistorySync) {
 				messageType,
 				int64(timestamp),
 			)
+			if !webMsg.GetKey().GetFromMe() {
+				CaptureInboundMedia(deviceID, messageID, parsedMsg.Message)
+			}
 			
 			messageCount++
+			stored++
 		}
+		recordBackfill(deviceID, evt, conv, stored)
 	}
 	
 	logrus.Infof("Processed %d messages from history sync for device %s", messageCount, deviceID)
@@ -1,6 +1,7 @@
 package whatsapp
 
 import (
+	"context"
 	"sync"
 	"time"
 	
@@ -9,6 +10,7 @@ import (
 	"go.mau.fi/whatsmeow/types"
 	"go.mau.fi/whatsmeow/types/events"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/scheduler"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
 )
 
@@ -49,27 +51,27 @@ func (rsm *RealtimeSyncManager) StartRealtimeSync() {
 	}
 	
 	rsm.isRunning = true
-	go rsm.syncLoop()
+	rsm.scheduleSync()
 	
 	logrus.Info("🚀 Started real-time sync manager for all devices")
 }
 
-// syncLoop runs the automatic sync check
-func (rsm *RealtimeSyncManager) syncLoop() {
-	ticker := time.NewTicker(rsm.syncInterval)
-	defer ticker.Stop()
-	
-	// Initial sync for all devices
-	rsm.syncAllDevices()
-	
-	for {
-		select {
-		case <-ticker.C:
-			if config.WhatsappChatStorage {
+// scheduleSync runs the sync check on every replica, once straight away and
+// then only while chat storage is enabled
+func (rsm *RealtimeSyncManager) scheduleSync() {
+	initial := true
+	scheduler.Default().MustRegister(scheduler.Job{
+		Name:       "realtime-sync",
+		Interval:   rsm.syncInterval,
+		RunOnStart: true,
+		Run: func(ctx context.Context) error {
+			if initial || config.WhatsappChatStorage {
 				rsm.syncAllDevices()
 			}
-		}
-	}
+			initial = false
+			return nil
+		},
+	})
 }
 
 // syncAllDevices syncs all online devices
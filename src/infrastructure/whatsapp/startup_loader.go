@@ -7,7 +7,9 @@ import (
 
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/database"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/cluster"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp/multidevice"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/clock"
 	"github.com/sirupsen/logrus"
 	"go.mau.fi/whatsmeow"
 	"go.mau.fi/whatsmeow/store"
@@ -15,10 +17,17 @@ import (
 	waLog "go.mau.fi/whatsmeow/util/log"
 )
 
-// LoadAllDevicesOnStartup loads all WhatsApp devices from database on server startup
+// LoadAllDevicesOnStartup loads all WhatsApp devices from database on server
+// startup. In cluster mode only the devices this replica owns are loaded,
+// after their previous owner has had a heartbeat to release them.
 func LoadAllDevicesOnStartup() {
+	clock.Sleep(cluster.HandoverDelay())
 	logrus.Info("=== LOADING ALL WHATSAPP DEVICES ON STARTUP ===")
-	
+	loadDevices(cluster.Owns)
+}
+
+// loadDevices connects the logged-in devices accepted by load
+func loadDevices(load func(deviceID string) bool) {
 	db := database.GetDB()
 	
 	// Get all non-platform devices with JID
@@ -61,6 +70,9 @@ func LoadAllDevicesOnStartup() {
 		if !deviceID.Valid || !userID.Valid || !jid.Valid {
 			continue
 		}
+		if !load(deviceID.String) {
+			continue
+		}
 		
 		logrus.Infof("Loading device: %s (JID: %s)", deviceName.String, jid.String)
 		
@@ -197,6 +197,9 @@ func isGroupJid(jid string) bool {
 
 // isFromMySelf is a helper function to check if the message is from my self (logged in account)
 func isFromMySelf(jid string) bool {
+	if cli == nil || cli.Store.ID == nil {
+		return false
+	}
 	return extractPhoneNumber(jid) == extractPhoneNumber(cli.Store.ID.String())
 }
 
@@ -13,19 +13,21 @@ import (
 
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
 	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/metrics"
 	"github.com/sirupsen/logrus"
 	"go.mau.fi/whatsmeow/types/events"
 )
 
 // forwardToWebhook is a helper function to forward event to webhook url
 func forwardToWebhook(ctx context.Context, evt *events.Message) error {
-	logrus.Info("Forwarding event to webhook:", config.WhatsappWebhook)
+	urls, _ := config.Webhooks()
+	logrus.Info("Forwarding event to webhook:", urls)
 	payload, err := createPayload(ctx, evt)
 	if err != nil {
 		return err
 	}
 
-	for _, url := range config.WhatsappWebhook {
+	for _, url := range urls {
 		if err = submitWebhook(payload, url); err != nil {
 			return err
 		}
@@ -190,7 +192,8 @@ func submitWebhook(payload map[string]interface{}, url string) error {
 		return pkgError.WebhookError(fmt.Sprintf("error when create http object %v", err))
 	}
 
-	secretKey := []byte(config.WhatsappWebhookSecret)
+	_, secret := config.Webhooks()
+	secretKey := []byte(secret)
 	signature, err := getMessageDigestOrSignature(postBody, secretKey)
 	if err != nil {
 		return pkgError.WebhookError(fmt.Sprintf("error when create signature %v", err))
@@ -202,16 +205,23 @@ func submitWebhook(payload map[string]interface{}, url string) error {
 	var attempt int
 	var maxAttempts = 5
 	var sleepDuration = 1 * time.Second
+	started := time.Now()
 
 	for attempt = 0; attempt < maxAttempts; attempt++ {
 		if _, err = client.Do(req); err == nil {
+			metrics.WebhookAttempts.Inc("success")
+			metrics.WebhookDeliveries.Inc("success")
+			metrics.WebhookDuration.Observe(time.Since(started).Seconds(), "success")
 			logrus.Infof("Successfully submitted webhook on attempt %d", attempt+1)
 			return nil
 		}
+		metrics.WebhookAttempts.Inc("error")
 		logrus.Warnf("Attempt %d to submit webhook failed: %v", attempt+1, err)
 		time.Sleep(sleepDuration)
 		sleepDuration *= 2
 	}
 
+	metrics.WebhookDeliveries.Inc("failed")
+	metrics.WebhookDuration.Observe(time.Since(started).Seconds(), "failed")
 	return pkgError.WebhookError(fmt.Sprintf("error when submit webhook after %d attempts: %v", attempt, err))
 }
@@ -50,6 +50,7 @@ func GetStoredMessagesFromDB(deviceID, chatJID string, limit int) ([]map[string]
 	`
 	
 	db.Exec(createTableQuery)
+	repository.MessageMediaSchema.Ensure(db)
 	
 	// Query messages
 	query := `
@@ -58,7 +59,11 @@ func GetStoredMessagesFromDB(deviceID, chatJID string, limit int) ([]map[string]
 			message_text,
 			message_type,
 			message_secrets,
-			timestamp
+			timestamp,
+			media_mimetype,
+			media_size,
+			media_file_name,
+			media_thumbnail_key
 		FROM whatsapp_messages
 		WHERE device_id = ? AND chat_jid = ?
 		ORDER BY timestamp DESC
@@ -90,8 +95,11 @@ func GetStoredMessagesFromDB(deviceID, chatJID string, limit int) ([]map[string]
 		var messageID, senderJID, messageType string
 		var messageText, messageSecrets sql.NullString
 		var timestamp int64
+		var mediaMimeType, mediaFileName, mediaThumbnailKey sql.NullString
+		var mediaSize sql.NullInt64
 		
-		err := rows.Scan(&messageID, &senderJID, &messageText, &messageType, &messageSecrets, &timestamp)
+		err := rows.Scan(&messageID, &senderJID, &messageText, &messageType, &messageSecrets, &timestamp,
+			&mediaMimeType, &mediaSize, &mediaFileName, &mediaThumbnailKey)
 		if err != nil {
 			continue
 		}
@@ -129,6 +137,16 @@ func GetStoredMessagesFromDB(deviceID, chatJID string, limit int) ([]map[string]
 			message["image"] = messageSecrets.String
 		}
 		
+		// Received media downloaded into the media store
+		if mediaMimeType.Valid {
+			media := messageMediaFields(deviceID, messageID, mediaMimeType.String, mediaSize.Int64,
+				mediaFileName.String, mediaThumbnailKey.String != "")
+			message["media"] = media
+			if messageType == "image" || messageType == "sticker" {
+				message["image"] = media["url"]
+			}
+		}
+		
 		messages = append(messages, message)
 	}
 	
@@ -181,6 +199,7 @@ func GetWhatsAppWebMessages(deviceID, chatJID string, limit int) ([]map[string]i
 	`
 	
 	db.Exec(createTableQuery)
+	repository.MessageMediaSchema.Ensure(db)
 	
 	// Query messages
 	query := `
@@ -189,7 +208,11 @@ func GetWhatsAppWebMessages(deviceID, chatJID string, limit int) ([]map[string]i
 			message_text,
 			message_type,
 			message_secrets,
-			timestamp
+			timestamp,
+			media_mimetype,
+			media_size,
+			media_file_name,
+			media_thumbnail_key
 		FROM whatsapp_messages
 		WHERE device_id = ? AND chat_jid = ?
 		ORDER BY timestamp DESC
@@ -209,8 +232,11 @@ func GetWhatsAppWebMessages(deviceID, chatJID string, limit int) ([]map[string]i
 		var messageID, senderJID, messageType string
 		var messageText, messageSecrets sql.NullString
 		var timestamp int64
+		var mediaMimeType, mediaFileName, mediaThumbnailKey sql.NullString
+		var mediaSize sql.NullInt64
 		
-		err := rows.Scan(&messageID, &senderJID, &messageText, &messageType, &messageSecrets, &timestamp)
+		err := rows.Scan(&messageID, &senderJID, &messageText, &messageType, &messageSecrets, &timestamp,
+			&mediaMimeType, &mediaSize, &mediaFileName, &mediaThumbnailKey)
 		if err != nil {
 			continue
 		}
@@ -241,6 +267,16 @@ func GetWhatsAppWebMessages(deviceID, chatJID string, limit int) ([]map[string]i
 			message["image"] = messageSecrets.String
 		}
 		
+		// Received media downloaded into the media store
+		if mediaMimeType.Valid {
+			media := messageMediaFields(deviceID, messageID, mediaMimeType.String, mediaSize.Int64,
+				mediaFileName.String, mediaThumbnailKey.String != "")
+			message["media"] = media
+			if messageType == "image" || messageType == "sticker" {
+				message["image"] = media["url"]
+			}
+		}
+		
 		messages = append(messages, message)
 	}
 	
@@ -2,6 +2,8 @@ package models
 
 import (
 	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/domains/broadcast"
 )
 
 // Campaign represents a marketing campaign
@@ -14,6 +16,9 @@ type Campaign struct {
 	TargetStatus    string    `json:"target_status" db:"target_status"` // prospect, customer, all
 	Message         string    `json:"message" db:"message"`
 	ImageURL        string    `json:"image_url" db:"image_url"`
+	MessageType     string    `json:"message_type" db:"message_type"` // text, image, video, document, audio, location, poll, contact
+	Payload         *broadcast.MessagePayload `json:"payload,omitempty" db:"message_payload"`
+	TargetGroups    []CampaignGroupTarget `json:"target_groups,omitempty" db:"target_groups"` // when set, the campaign goes to these groups instead of leads
 	CampaignDate    string    `json:"campaign_date" db:"campaign_date"`
 	ScheduledDate   string    `json:"scheduled_date" db:"scheduled_date"`
 	TimeSchedule    string    `json:"time_schedule" db:"time_schedule"`
@@ -25,3 +30,26 @@ type Campaign struct {
 	CreatedAt       time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
 }
+
+// CampaignEvent records one change to a campaign's lifecycle: a pause,
+// resume, cancel or throttle by a user, or a status change by the monitor
+type CampaignEvent struct {
+	ID              int64     `json:"id"`
+	CampaignID      int       `json:"campaign_id"`
+	Actor           string    `json:"actor"`  // user ID, or "system" for automatic transitions
+	Action          string    `json:"action"` // pause, resume, cancel, throttle, status
+	FromStatus      string    `json:"from_status"`
+	ToStatus        string    `json:"to_status"`
+	Reason          string    `json:"reason,omitempty"`
+	MinDelaySeconds *int      `json:"min_delay_seconds,omitempty"`
+	MaxDelaySeconds *int      `json:"max_delay_seconds,omitempty"`
+	Messages        int       `json:"messages"` // broadcast messages the change touched
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// CampaignGroupTarget is a WhatsApp group a campaign is posted to, from a
+// device that belongs to it
+type CampaignGroupTarget struct {
+	DeviceID string `json:"device_id"`
+	GroupJID string `json:"group_jid"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Data subject request actions
+const (
+	DataSubjectExport = "export"
+	DataSubjectErase  = "erase"
+)
+
+// DataSubjectData is everything one user stores about a phone number
+type DataSubjectData struct {
+	Phone      string                      `json:"phone"`
+	ExportedAt time.Time                   `json:"exported_at"`
+	Tables     map[string][]map[string]any `json:"tables"` // matching rows by table
+	Files      map[string][]map[string]any `json:"files"`  // matching records by storage file
+	Media      []DataSubjectMedia          `json:"media"`  // files received in the phone's messages
+}
+
+// DataSubjectMedia is a media file received in a data subject's messages
+type DataSubjectMedia struct {
+	SHA256       string `json:"sha256"`
+	MimeType     string `json:"mimetype"`
+	Size         int64  `json:"size"`
+	FileName     string `json:"file_name,omitempty"`
+	Content      []byte `json:"content,omitempty"` // base64 in JSON
+	StorageKey   string `json:"-"`
+	ThumbnailKey string `json:"-"`
+}
+
+// DataSubjectRequest is the audit record of an export or erasure. It keeps
+// a hash of the phone rather than the phone, so it outlives the erasure it
+// records and still answers whether a number was erased.
+type DataSubjectRequest struct {
+	ID          int64            `json:"id"`
+	UserID      string           `json:"user_id"`
+	Actor       string           `json:"actor"`
+	Action      string           `json:"action"` // export or erase
+	SubjectHash string           `json:"subject_hash"`
+	Pseudonym   string           `json:"pseudonym,omitempty"` // what the phone was replaced with in kept rows
+	Affected    map[string]int64 `json:"affected"`            // rows, records or files by table, storage file or media
+	CreatedAt   time.Time        `json:"created_at"`
+}
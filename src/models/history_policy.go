@@ -0,0 +1,99 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Chat types a history policy can keep
+const (
+	HistoryChatPersonal  = "personal"
+	HistoryChatGroup     = "group"
+	HistoryChatBroadcast = "broadcast"
+)
+
+// HistoryPolicy decides which of the history WhatsApp pushes to a device is
+// stored, and whether its chats become leads
+type HistoryPolicy struct {
+	DeviceID    string    `json:"device_id"`
+	KeepDays    int       `json:"keep_days"`  // 0 keeps all history
+	ChatTypes   []string  `json:"chat_types"` // personal, group and/or broadcast
+	AutoLeads   bool      `json:"auto_leads"` // create leads from personal chats after each sync
+	LeadNiche   string    `json:"lead_niche"`
+	LeadStatus  string    `json:"lead_status"` // target status of created leads: prospect or customer
+	LeadTrigger string    `json:"lead_trigger"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// DefaultHistoryPolicy is the policy of a device that has not set one: six
+// months of personal chats, with leads imported by hand
+func DefaultHistoryPolicy(deviceID string) HistoryPolicy {
+	return HistoryPolicy{
+		DeviceID:   deviceID,
+		KeepDays:   180,
+		ChatTypes:  []string{HistoryChatPersonal},
+		LeadNiche:  "WHATSAPP_IMPORT",
+		LeadStatus: "prospect",
+	}
+}
+
+// HistoryChatType returns the chat type of a chat JID, or "" for chats no
+// policy keeps such as status updates and newsletters
+func HistoryChatType(chatJID string) string {
+	switch {
+	case strings.HasSuffix(chatJID, "@s.whatsapp.net"), strings.HasSuffix(chatJID, "@lid"):
+		return HistoryChatPersonal
+	case strings.HasSuffix(chatJID, "@g.us"):
+		return HistoryChatGroup
+	case chatJID == "status@broadcast":
+		return ""
+	case strings.HasSuffix(chatJID, "@broadcast"):
+		return HistoryChatBroadcast
+	}
+	return ""
+}
+
+// Validate checks the policy's values
+func (p HistoryPolicy) Validate() error {
+	if p.KeepDays < 0 {
+		return fmt.Errorf("keep_days cannot be negative")
+	}
+	if len(p.ChatTypes) == 0 {
+		return fmt.Errorf("chat_types needs at least one of personal, group or broadcast")
+	}
+	for _, chatType := range p.ChatTypes {
+		if chatType != HistoryChatPersonal && chatType != HistoryChatGroup && chatType != HistoryChatBroadcast {
+			return fmt.Errorf("unknown chat type %q", chatType)
+		}
+	}
+	if p.LeadStatus != "prospect" && p.LeadStatus != "customer" {
+		return fmt.Errorf("lead_status must be prospect or customer")
+	}
+	return nil
+}
+
+// Cutoff returns the Unix time before which history is dropped, or 0 when
+// all history is kept
+func (p HistoryPolicy) Cutoff(now time.Time) int64 {
+	if p.KeepDays == 0 {
+		return 0
+	}
+	return now.AddDate(0, 0, -p.KeepDays).Unix()
+}
+
+// KeepsChat reports whether the policy stores history of a chat
+func (p HistoryPolicy) KeepsChat(chatJID string) bool {
+	chatType := HistoryChatType(chatJID)
+	for _, kept := range p.ChatTypes {
+		if chatType != "" && kept == chatType {
+			return true
+		}
+	}
+	return false
+}
+
+// Keeps reports whether the policy stores a message of a chat sent at a Unix time
+func (p HistoryPolicy) Keeps(chatJID string, timestamp int64, now time.Time) bool {
+	return p.KeepsChat(chatJID) && timestamp >= p.Cutoff(now)
+}
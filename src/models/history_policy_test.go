@@ -0,0 +1,54 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistoryPolicyKeeps(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	recent := now.AddDate(0, 0, -10).Unix()
+	old := now.AddDate(0, 0, -200).Unix()
+
+	personal := DefaultHistoryPolicy("device")
+	everything := HistoryPolicy{ChatTypes: []string{HistoryChatPersonal, HistoryChatGroup, HistoryChatBroadcast}}
+
+	tests := []struct {
+		name   string
+		policy HistoryPolicy
+		chat   string
+		at     int64
+		keeps  bool
+	}{
+		{"recent personal", personal, "60123456789@s.whatsapp.net", recent, true},
+		{"old personal", personal, "60123456789@s.whatsapp.net", old, false},
+		{"group not kept", personal, "120363025246125486@g.us", recent, false},
+		{"old group kept forever", everything, "120363025246125486@g.us", old, true},
+		{"broadcast list", everything, "1234567890@broadcast", recent, true},
+		{"status never kept", everything, "status@broadcast", recent, false},
+		{"newsletter never kept", everything, "120363144038483540@newsletter", recent, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.keeps, tt.policy.Keeps(tt.chat, tt.at, now))
+		})
+	}
+}
+
+func TestHistoryPolicyValidate(t *testing.T) {
+	assert.NoError(t, DefaultHistoryPolicy("device").Validate())
+
+	policy := DefaultHistoryPolicy("device")
+	policy.ChatTypes = []string{"channel"}
+	assert.EqualError(t, policy.Validate(), `unknown chat type "channel"`)
+
+	policy = DefaultHistoryPolicy("device")
+	policy.KeepDays = -1
+	assert.Error(t, policy.Validate())
+
+	policy = DefaultHistoryPolicy("device")
+	policy.LeadStatus = "new"
+	assert.Error(t, policy.Validate())
+}
@@ -20,6 +20,9 @@ type Lead struct {
 	Trigger      string    `json:"trigger" db:"trigger"` // New column: comma-separated sequence triggers
 	Notes        string    `json:"notes" db:"notes"`
 	Platform     string    `json:"platform" db:"platform"` // New column: Whacenter, etc.
+	NumberStatus string    `json:"number_status,omitempty" db:"number_status"` // unchecked, valid or invalid on WhatsApp
+	NumberValidation *NumberValidation `json:"number_validation,omitempty" db:"-"` // Cached check of the number, set by the lead list
+	Consent      *LeadConsent `json:"consent,omitempty" db:"-"` // Consent captured with the lead, recorded when it is created
 	CreatedAt    time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
 }
\ No newline at end of file
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Channels a lead's consent is captured through
+const (
+	ConsentChannelManual      = "manual"         // added by hand in the dashboard
+	ConsentChannelShareLink   = "share_link"     // added through a device share link
+	ConsentChannelWebhook     = "webhook"        // sent by a form or integration
+	ConsentChannelImport      = "import"         // CSV import
+	ConsentChannelGroup       = "whatsapp_group" // imported from WhatsApp group members
+	ConsentChannelInboundChat = "whatsapp_chat"  // created from a chat the lead started
+)
+
+// LeadConsent records how and when a lead agreed to be contacted. Consents
+// are keyed by phone like the lead's messages and kept as a history; a new
+// capture adds a record rather than replacing one.
+type LeadConsent struct {
+	ID         int64     `json:"id"`
+	UserID     string    `json:"user_id"`
+	Phone      string    `json:"phone"`
+	Source     string    `json:"source,omitempty"`     // where consent was given, such as a form or campaign page
+	Channel    string    `json:"channel"`              // how the lead reached us
+	ProofText  string    `json:"proof_text,omitempty"` // the wording agreed to, or a reference to the proof
+	GivenAt    time.Time `json:"given_at"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// Lead timeline entry kinds
+const (
+	LeadTimelineCreated           = "lead_created"
+	LeadTimelineCampaignSend      = "campaign_send"
+	LeadTimelineSequenceStep      = "sequence_step"
+	LeadTimelineDelivered         = "delivered"
+	LeadTimelineRead              = "read"
+	LeadTimelineMessageIn         = "message_in"  // reply or message from the lead
+	LeadTimelineMessageOut        = "message_out" // chat message sent to the lead
+	LeadTimelineSequenceCompleted = "sequence_completed"
+	LeadTimelineStatusChange      = "status_change"
+	LeadTimelineTagAdded          = "tag_added"
+	LeadTimelineTagRemoved        = "tag_removed"
+	LeadTimelineNote              = "note"
+)
+
+// LeadEvent records a change made to a lead: a status change, a tag added or
+// removed, or a note. Events are keyed by phone like the lead's messages.
+type LeadEvent struct {
+	ID        int64     `json:"id"`
+	UserID    string    `json:"user_id"`
+	Phone     string    `json:"phone"`
+	Kind      string    `json:"kind"`  // status_change, tag_added, tag_removed or note
+	Actor     string    `json:"actor"` // user ID, "flow:<sequence ID>" or "share_link"
+	From      string    `json:"from,omitempty"`
+	To        string    `json:"to,omitempty"`
+	Text      string    `json:"text,omitempty"` // note text, or the tag
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// LeadTimelineEntry is one thing that happened to a lead
+type LeadTimelineEntry struct {
+	ID         string    `json:"id"` // unique within the lead's timeline
+	Kind       string    `json:"kind"`
+	At         time.Time `json:"at"`
+	DeviceID   string    `json:"device_id,omitempty"`
+	Text       string    `json:"text,omitempty"`
+	Status     string    `json:"status,omitempty"` // status of a campaign or sequence send
+	Error      string    `json:"error,omitempty"`
+	Source     string    `json:"source,omitempty"` // campaign title or sequence name
+	CampaignID *int      `json:"campaign_id,omitempty"`
+	SequenceID string    `json:"sequence_id,omitempty"`
+	Step       int       `json:"step,omitempty"` // sequence day of a sequence send
+	From       string    `json:"from,omitempty"`
+	To         string    `json:"to,omitempty"`
+	Tag        string    `json:"tag,omitempty"`
+	Actor      string    `json:"actor,omitempty"`
+}
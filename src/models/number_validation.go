@@ -0,0 +1,62 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Number statuses of a lead's phone
+const (
+	NumberUnchecked = "unchecked" // not validated yet
+	NumberValid     = "valid"     // registered on WhatsApp
+	NumberInvalid   = "invalid"   // not on WhatsApp, or not a phone number at all
+)
+
+// NumberValidation is the cached result of checking a phone number with
+// WhatsApp. It is shared by every lead with the number until it expires.
+type NumberValidation struct {
+	Phone        string    `json:"phone"`
+	Status       string    `json:"status"`
+	JID          string    `json:"jid,omitempty"`
+	IsBusiness   bool      `json:"is_business"`
+	VerifiedName string    `json:"verified_name,omitempty"` // the business's verified name
+	CheckedAt    time.Time `json:"checked_at"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// Expired reports whether the result is too old to trust at now
+func (v NumberValidation) Expired(now time.Time) bool {
+	return !now.Before(v.ExpiresAt)
+}
+
+// NormalizePhone reduces a phone number to the international digits
+// WhatsApp knows it by: punctuation and a JID server are dropped, a 00
+// prefix is read as "+", and a national number with a leading 0 gets
+// countryCode when one is given. It reports false when what is left
+// cannot be a phone number.
+func NormalizePhone(phone, countryCode string) (string, bool) {
+	phone = strings.TrimSpace(phone)
+	if at := strings.IndexByte(phone, '@'); at >= 0 {
+		phone = phone[:at]
+	}
+	international := strings.HasPrefix(phone, "+")
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, phone)
+
+	if !international {
+		switch {
+		case strings.HasPrefix(digits, "00"):
+			digits = digits[2:]
+		case strings.HasPrefix(digits, "0") && countryCode != "":
+			digits = strings.TrimPrefix(countryCode, "+") + digits[1:]
+		}
+	}
+	if len(digits) < 8 || len(digits) > 15 || digits[0] == '0' {
+		return digits, false
+	}
+	return digits, true
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct {
+		name        string
+		phone       string
+		countryCode string
+		want        string
+		ok          bool
+	}{
+		{"international digits", "60123456789", "", "60123456789", true},
+		{"plus and punctuation", "+60 12-345 6789", "", "60123456789", true},
+		{"00 prefix", "0060123456789", "", "60123456789", true},
+		{"jid", "60123456789@s.whatsapp.net", "", "60123456789", true},
+		{"national with country code", "012-345 6789", "60", "60123456789", true},
+		{"national with plus country code", "0123456789", "+60", "60123456789", true},
+		{"national without country code", "0123456789", "", "0123456789", false},
+		{"plus keeps a leading zero invalid", "+0123456789", "60", "0123456789", false},
+		{"too short", "12345", "", "12345", false},
+		{"too long", "1234567890123456", "", "1234567890123456", false},
+		{"not a number", "n/a", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := NormalizePhone(tt.phone, tt.countryCode)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.ok, ok)
+		})
+	}
+}
@@ -2,6 +2,8 @@ package models
 
 import (
 	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/domains/broadcast"
 )
 
 // Sequence model for drip campaigns - simplified like campaigns
@@ -50,6 +52,7 @@ type SequenceStep struct {
 	Content          string    `json:"content" db:"content"`
 	MediaURL         string    `json:"media_url" db:"media_url"`
 	Caption          string    `json:"caption" db:"caption"`
+	Payload          *broadcast.MessagePayload `json:"payload,omitempty" db:"message_payload"` // filename, poll options, coordinates...
 	MinDelaySeconds  int       `json:"min_delay_seconds" db:"min_delay_seconds"`
 	MaxDelaySeconds  int       `json:"max_delay_seconds" db:"max_delay_seconds"`
 	DelayDays        int       `json:"delay_days" db:"delay_days"`
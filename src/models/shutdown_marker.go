@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Shutdown marker states
+const (
+	ShutdownStateRunning = "running" // the replica started and has not stopped cleanly since
+	ShutdownStateStopped = "stopped" // the replica drained and stopped
+)
+
+// ShutdownMarker is what a replica recorded about its last run, so the next
+// boot knows whether it stopped cleanly and what it handed back
+type ShutdownMarker struct {
+	NodeID    string     `json:"node_id"`
+	State     string     `json:"state"`
+	StartedAt time.Time  `json:"started_at"`
+	StoppedAt *time.Time `json:"stopped_at,omitempty"`
+	// Requeued messages were handed to workers and returned to pending unsent
+	Requeued []string `json:"requeued"`
+	// Interrupted messages were mid-send at the deadline and retried as
+	// failed sends, since whether they went out is unknown
+	Interrupted []string `json:"interrupted"`
+	// Devices were connected when the replica stopped
+	Devices []string `json:"devices"`
+}
+
+// Clean reports whether the run the marker describes stopped cleanly
+func (m ShutdownMarker) Clean() bool {
+	return m.State == ShutdownStateStopped
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// WhatsAppGroup is the cached metadata of a group a device belongs to
+type WhatsAppGroup struct {
+	DeviceID     string    `json:"device_id"`
+	GroupJID     string    `json:"group_jid"`
+	Name         string    `json:"name"`
+	Participants int       `json:"participants"`
+	IsAdmin      bool      `json:"is_admin"`    // the device is an admin of the group
+	IsAnnounce   bool      `json:"is_announce"` // only admins can post
+	RefreshedAt  time.Time `json:"refreshed_at"`
+}
+
+// CanPost reports whether the device may send messages to the group
+func (g WhatsAppGroup) CanPost() bool {
+	return !g.IsAnnounce || g.IsAdmin
+}
+
+// WhatsAppGroupMember is a participant of a group
+type WhatsAppGroupMember struct {
+	JID          string `json:"jid"`
+	Phone        string `json:"phone,omitempty"` // empty when the group hides participants' numbers
+	Name         string `json:"name,omitempty"`
+	IsAdmin      bool   `json:"is_admin"`
+	IsSuperAdmin bool   `json:"is_super_admin"`
+	IsSelf       bool   `json:"is_self"` // the device itself
+}
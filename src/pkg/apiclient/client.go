@@ -0,0 +1,220 @@
+// Package apiclient is a typed Go client for the REST API. The request types
+// and one method per operation are generated from docs/openapi.yaml into
+// client_gen.go; run `go test ./ui/rest -run TestOpenAPISpec -update` after
+// changing a route.
+package apiclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Client calls the API of one server
+type Client struct {
+	BaseURL    string
+	Token      string // Session token sent as a bearer token
+	HTTPClient *http.Client
+	Header     http.Header // Extra headers sent with every request
+}
+
+// New returns a client for the server at baseURL
+func New(baseURL, token string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		Token:      token,
+		HTTPClient: &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// File is an upload in a multipart request
+type File struct {
+	Name    string
+	Content io.Reader
+}
+
+// Response is the envelope every JSON reply uses. Replies that are not
+// JSON, such as exports, only fill StatusCode and Body.
+type Response struct {
+	StatusCode int             `json:"-"`
+	Code       string          `json:"code"`
+	Message    string          `json:"message"`
+	Results    json.RawMessage `json:"results,omitempty"`
+	Body       []byte          `json:"-"`
+}
+
+// Decode unmarshals the results of the reply into v
+func (r *Response) Decode(v any) error {
+	if len(r.Results) == 0 {
+		return fmt.Errorf("apiclient: response has no results")
+	}
+	return json.Unmarshal(r.Results, v)
+}
+
+// Error is returned for replies with a 4xx or 5xx status
+type Error struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+func (e *Error) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("apiclient: %d %s: %s", e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("apiclient: %d: %s", e.StatusCode, e.Message)
+}
+
+// request is what a generated method asks do to send
+type request struct {
+	method    string
+	path      string
+	query     any
+	body      any
+	multipart bool
+}
+
+func (c *Client) do(ctx context.Context, req request) (*Response, error) {
+	target := c.BaseURL + req.path
+	if values := encodeQuery(req.query); len(values) > 0 {
+		target += "?" + values.Encode()
+	}
+
+	var body io.Reader
+	contentType := ""
+	if req.body != nil && !reflect.ValueOf(req.body).IsNil() {
+		var buf bytes.Buffer
+		if req.multipart {
+			writer := multipart.NewWriter(&buf)
+			if err := encodeMultipart(writer, req.body); err != nil {
+				return nil, err
+			}
+			if err := writer.Close(); err != nil {
+				return nil, err
+			}
+			contentType = writer.FormDataContentType()
+		} else {
+			if err := json.NewEncoder(&buf).Encode(req.body); err != nil {
+				return nil, err
+			}
+			contentType = "application/json"
+		}
+		body = &buf
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range c.Header {
+		for _, value := range values {
+			httpReq.Header.Add(key, value)
+		}
+	}
+	if contentType != "" {
+		httpReq.Header.Set("Content-Type", contentType)
+	}
+	if c.Token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	httpReq.Header.Set("Accept", "application/json")
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+	raw, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &Response{StatusCode: httpResp.StatusCode, Body: raw}
+	if strings.HasPrefix(httpResp.Header.Get("Content-Type"), "application/json") {
+		// Some handlers reply with {"error": "..."} instead of the envelope
+		var reply struct {
+			Response
+			Error string `json:"error"`
+		}
+		if err := json.Unmarshal(raw, &reply); err == nil {
+			resp.Code, resp.Message, resp.Results = reply.Code, reply.Message, reply.Results
+			if resp.Message == "" {
+				resp.Message = reply.Error
+			}
+		}
+	}
+	if httpResp.StatusCode >= 400 {
+		message := resp.Message
+		if message == "" {
+			message = strings.TrimSpace(string(raw))
+		}
+		return resp, &Error{StatusCode: httpResp.StatusCode, Code: resp.Code, Message: message}
+	}
+	return resp, nil
+}
+
+// encodeQuery reads the query tags of a generated query struct
+func encodeQuery(query any) url.Values {
+	values := url.Values{}
+	v := reflect.ValueOf(query)
+	if !v.IsValid() || v.IsNil() {
+		return values
+	}
+	v = v.Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if field.IsZero() {
+			continue
+		}
+		if field.Kind() == reflect.Ptr {
+			field = field.Elem()
+		}
+		values.Set(v.Type().Field(i).Tag.Get("query"), fmt.Sprint(field.Interface()))
+	}
+	return values
+}
+
+// encodeMultipart writes a generated request struct as form fields and files
+func encodeMultipart(writer *multipart.Writer, body any) error {
+	v := reflect.ValueOf(body).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if field.IsZero() {
+			continue
+		}
+		name := strings.Split(v.Type().Field(i).Tag.Get("json"), ",")[0]
+		if file, ok := field.Interface().(*File); ok {
+			part, err := writer.CreateFormFile(name, file.Name)
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(part, file.Content); err != nil {
+				return err
+			}
+			continue
+		}
+		if field.Kind() == reflect.Ptr {
+			field = field.Elem()
+		}
+		value := fmt.Sprint(field.Interface())
+		if t, ok := field.Interface().(time.Time); ok {
+			value = t.Format(time.RFC3339)
+		}
+		if err := writer.WriteField(name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
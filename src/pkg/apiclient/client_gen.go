@@ -0,0 +1,2435 @@
+// Code generated by openapi.GenerateClient. DO NOT EDIT.
+
+package apiclient
+
+import (
+	"context"
+	"net/url"
+	"time"
+)
+
+// AddSuppressionRequest is the AddSuppressionRequest schema
+type AddSuppressionRequest struct {
+	Phone  string `json:"phone,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// AudioRequest is the AudioRequest schema
+type AudioRequest struct {
+	Audio       *File  `json:"audio,omitempty"`
+	IsForwarded bool   `json:"is_forwarded,omitempty"`
+	Phone       string `json:"phone,omitempty"`
+}
+
+// BackfillRequest is the BackfillRequest schema
+type BackfillRequest struct {
+	Count int `json:"count,omitempty"`
+}
+
+// CampaignGroupTarget is the CampaignGroupTarget schema
+type CampaignGroupTarget struct {
+	DeviceID string `json:"device_id,omitempty"`
+	GroupJID string `json:"group_jid,omitempty"`
+}
+
+// CancelCampaignRequest is the CancelCampaignRequest schema
+type CancelCampaignRequest struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+// ConsentRequest is the ConsentRequest schema
+type ConsentRequest struct {
+	ConsentAt     string `json:"consent_at,omitempty"`
+	ConsentProof  string `json:"consent_proof,omitempty"`
+	ConsentSource string `json:"consent_source,omitempty"`
+}
+
+// ContactRequest is the ContactRequest schema
+type ContactRequest struct {
+	ContactName  string `json:"contact_name,omitempty"`
+	ContactPhone string `json:"contact_phone,omitempty"`
+	IsForwarded  bool   `json:"is_forwarded,omitempty"`
+	Phone        string `json:"phone,omitempty"`
+}
+
+// CreateCampaignRequest is the CreateCampaignRequest schema
+type CreateCampaignRequest struct {
+	AI              *string               `json:"ai,omitempty"`
+	CampaignDate    string                `json:"campaign_date,omitempty"`
+	ImageURL        string                `json:"image_url,omitempty"`
+	Limit           int                   `json:"limit,omitempty"`
+	MaxDelaySeconds int                   `json:"max_delay_seconds,omitempty"`
+	Message         string                `json:"message,omitempty"`
+	MessageType     string                `json:"message_type,omitempty"`
+	MinDelaySeconds int                   `json:"min_delay_seconds,omitempty"`
+	Niche           string                `json:"niche,omitempty"`
+	Payload         *MessagePayload       `json:"payload,omitempty"`
+	TargetGroups    []CampaignGroupTarget `json:"target_groups,omitempty"`
+	TargetStatus    string                `json:"target_status,omitempty"`
+	TimeSchedule    string                `json:"time_schedule,omitempty"`
+	Title           string                `json:"title,omitempty"`
+}
+
+// CreateDeviceRequest is the CreateDeviceRequest schema
+type CreateDeviceRequest struct {
+	Name  string `json:"name,omitempty"`
+	Phone string `json:"phone,omitempty"`
+}
+
+// CreateGroupRequest is the CreateGroupRequest schema
+type CreateGroupRequest struct {
+	Participants []string `json:"participants,omitempty"`
+	Title        string   `json:"title,omitempty"`
+}
+
+// CreateSequenceRequest is the CreateSequenceRequest schema
+type CreateSequenceRequest struct {
+	Description     string                      `json:"description,omitempty"`
+	DeviceID        *string                     `json:"device_id,omitempty"`
+	EndTrigger      string                      `json:"end_trigger,omitempty"`
+	IsActive        bool                        `json:"is_active,omitempty"`
+	MaxDelaySeconds int                         `json:"max_delay_seconds,omitempty"`
+	MinDelaySeconds int                         `json:"min_delay_seconds,omitempty"`
+	Name            string                      `json:"name,omitempty"`
+	Niche           string                      `json:"niche,omitempty"`
+	StartTrigger    string                      `json:"start_trigger,omitempty"`
+	Status          string                      `json:"status,omitempty"`
+	Steps           []CreateSequenceStepRequest `json:"steps,omitempty"`
+	TimeSchedule    string                      `json:"time_schedule,omitempty"`
+	Trigger         string                      `json:"trigger,omitempty"`
+	UserID          string                      `json:"user_id,omitempty"`
+}
+
+// CreateSequenceStepRequest is the CreateSequenceStepRequest schema
+type CreateSequenceStepRequest struct {
+	Caption           string          `json:"caption,omitempty"`
+	Content           string          `json:"content,omitempty"`
+	Day               int             `json:"day,omitempty"`
+	DayNumber         int             `json:"day_number,omitempty"`
+	ImageURL          string          `json:"image_url,omitempty"`
+	IsEntryPoint      bool            `json:"is_entry_point,omitempty"`
+	MaxDelaySeconds   int             `json:"max_delay_seconds,omitempty"`
+	MediaURL          string          `json:"media_url,omitempty"`
+	MessageType       string          `json:"message_type,omitempty"`
+	MinDelaySeconds   int             `json:"min_delay_seconds,omitempty"`
+	NextTrigger       string          `json:"next_trigger,omitempty"`
+	Payload           *MessagePayload `json:"payload,omitempty"`
+	SendTime          string          `json:"send_time,omitempty"`
+	TimeSchedule      string          `json:"time_schedule,omitempty"`
+	Trigger           string          `json:"trigger,omitempty"`
+	TriggerDelayHours int             `json:"trigger_delay_hours,omitempty"`
+}
+
+// CreateShareLinkRequest is the CreateShareLinkRequest schema
+type CreateShareLinkRequest struct {
+	DeviceID       string     `json:"device_id,omitempty"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`
+	ExpiresInHours int        `json:"expires_in_hours,omitempty"`
+	Name           string     `json:"name,omitempty"`
+	Password       string     `json:"password,omitempty"`
+	Scopes         []string   `json:"scopes,omitempty"`
+}
+
+// CreateTeamMemberRequest is the CreateTeamMemberRequest schema
+type CreateTeamMemberRequest struct {
+	Password string `json:"password,omitempty"`
+	Username string `json:"username,omitempty"`
+}
+
+// DeadLetterBulkRequest is the DeadLetterBulkRequest schema
+type DeadLetterBulkRequest struct {
+	CampaignID     int      `json:"campaign_id,omitempty"`
+	DeviceID       string   `json:"device_id,omitempty"`
+	ErrorClass     string   `json:"error_class,omitempty"`
+	ErrorCode      string   `json:"error_code,omitempty"`
+	Ids            []string `json:"ids,omitempty"`
+	SequenceID     string   `json:"sequence_id,omitempty"`
+	TargetDeviceID string   `json:"target_device_id,omitempty"`
+}
+
+// DeleteRequest is the DeleteRequest schema
+type DeleteRequest struct {
+	MessageID string `json:"message_id,omitempty"`
+	Phone     string `json:"phone,omitempty"`
+}
+
+// FileRequest is the FileRequest schema
+type FileRequest struct {
+	Caption     string `json:"caption,omitempty"`
+	File        *File  `json:"file,omitempty"`
+	IsForwarded bool   `json:"is_forwarded,omitempty"`
+	Phone       string `json:"phone,omitempty"`
+}
+
+// GroupLeadOptions is the GroupLeadOptions schema
+type GroupLeadOptions struct {
+	Niche        string `json:"niche,omitempty"`
+	TargetStatus string `json:"target_status,omitempty"`
+	Trigger      string `json:"trigger,omitempty"`
+}
+
+// GroupRequestParticipantsRequest is the GroupRequestParticipantsRequest schema
+type GroupRequestParticipantsRequest struct {
+	Action       string   `json:"action,omitempty"`
+	GroupID      string   `json:"group_id,omitempty"`
+	Participants []string `json:"participants,omitempty"`
+}
+
+// HistoryPolicy is the HistoryPolicy schema
+type HistoryPolicy struct {
+	AutoLeads   bool       `json:"auto_leads,omitempty"`
+	ChatTypes   []string   `json:"chat_types,omitempty"`
+	DeviceID    string     `json:"device_id,omitempty"`
+	KeepDays    int        `json:"keep_days,omitempty"`
+	LeadNiche   string     `json:"lead_niche,omitempty"`
+	LeadStatus  string     `json:"lead_status,omitempty"`
+	LeadTrigger string     `json:"lead_trigger,omitempty"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+}
+
+// ImageRequest is the ImageRequest schema
+type ImageRequest struct {
+	Caption     string `json:"caption,omitempty"`
+	Compress    bool   `json:"compress,omitempty"`
+	DeviceID    string `json:"device_id,omitempty"`
+	Image       *File  `json:"image,omitempty"`
+	ImageB64    string `json:"image_b64,omitempty"`
+	ImageURL    string `json:"image_url,omitempty"`
+	IsForwarded bool   `json:"is_forwarded,omitempty"`
+	Phone       string `json:"phone,omitempty"`
+	ViewOnce    bool   `json:"view_once,omitempty"`
+}
+
+// JoinGroupWithLinkRequest is the JoinGroupWithLinkRequest schema
+type JoinGroupWithLinkRequest struct {
+	Link string `json:"link,omitempty"`
+}
+
+// LeadAIRequest is the LeadAIRequest schema
+type LeadAIRequest struct {
+	Email        string `json:"email,omitempty"`
+	Name         string `json:"name,omitempty"`
+	Niche        string `json:"niche,omitempty"`
+	Notes        string `json:"notes,omitempty"`
+	Phone        string `json:"phone,omitempty"`
+	TargetStatus string `json:"target_status,omitempty"`
+}
+
+// LeadNoteRequest is the LeadNoteRequest schema
+type LeadNoteRequest struct {
+	Text string `json:"text,omitempty"`
+}
+
+// LeadRequest is the LeadRequest schema
+type LeadRequest struct {
+	ConsentAt     string `json:"consent_at,omitempty"`
+	ConsentProof  string `json:"consent_proof,omitempty"`
+	ConsentSource string `json:"consent_source,omitempty"`
+	DeviceID      string `json:"device_id,omitempty"`
+	Journey       string `json:"journey,omitempty"`
+	Name          string `json:"name,omitempty"`
+	Niche         string `json:"niche,omitempty"`
+	Phone         string `json:"phone,omitempty"`
+	TargetStatus  string `json:"target_status,omitempty"`
+	Trigger       string `json:"trigger,omitempty"`
+}
+
+// LeaveGroupRequest is the LeaveGroupRequest schema
+type LeaveGroupRequest struct {
+	GroupID string `json:"group_id,omitempty"`
+}
+
+// LinkDeviceRequest is the LinkDeviceRequest schema
+type LinkDeviceRequest struct {
+	DeviceID string `json:"device_id,omitempty"`
+	Phone    string `json:"phone,omitempty"`
+}
+
+// LinkRequest is the LinkRequest schema
+type LinkRequest struct {
+	Caption     string `json:"caption,omitempty"`
+	IsForwarded bool   `json:"is_forwarded,omitempty"`
+	Link        string `json:"link,omitempty"`
+	Phone       string `json:"phone,omitempty"`
+}
+
+// LocationRequest is the LocationRequest schema
+type LocationRequest struct {
+	IsForwarded bool   `json:"is_forwarded,omitempty"`
+	Latitude    string `json:"latitude,omitempty"`
+	Longitude   string `json:"longitude,omitempty"`
+	Phone       string `json:"phone,omitempty"`
+}
+
+// LoginRequest is the LoginRequest schema
+type LoginRequest struct {
+	Email    string `json:"email,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// MarkAsReadRequest is the MarkAsReadRequest schema
+type MarkAsReadRequest struct {
+	MessageID string `json:"message_id,omitempty"`
+	Phone     string `json:"phone,omitempty"`
+}
+
+// MergeContactsRequest is the MergeContactsRequest schema
+type MergeContactsRequest struct {
+	NewDeviceID string `json:"new_device_id,omitempty"`
+	OldDeviceID string `json:"old_device_id,omitempty"`
+}
+
+// MessagePayload is the MessagePayload schema
+type MessagePayload struct {
+	Address       string   `json:"address,omitempty"`
+	ContactName   string   `json:"contact_name,omitempty"`
+	ContactPhone  string   `json:"contact_phone,omitempty"`
+	Filename      string   `json:"filename,omitempty"`
+	Latitude      float64  `json:"latitude,omitempty"`
+	LocationName  string   `json:"location_name,omitempty"`
+	Longitude     float64  `json:"longitude,omitempty"`
+	Mimetype      string   `json:"mimetype,omitempty"`
+	PollMaxAnswer int      `json:"poll_max_answer,omitempty"`
+	PollOptions   []string `json:"poll_options,omitempty"`
+	Ptt           bool     `json:"ptt,omitempty"`
+}
+
+// MessageRequest is the MessageRequest schema
+type MessageRequest struct {
+	DeviceID       string  `json:"device_id,omitempty"`
+	IsForwarded    bool    `json:"is_forwarded,omitempty"`
+	Message        string  `json:"message,omitempty"`
+	Phone          string  `json:"phone,omitempty"`
+	ReplyMessageID *string `json:"reply_message_id,omitempty"`
+}
+
+// Page is the Page schema
+type Page struct {
+	HasMore    bool   `json:"has_more,omitempty"`
+	Limit      int    `json:"limit,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Total      int    `json:"total,omitempty"`
+}
+
+// ParticipantRequest is the ParticipantRequest schema
+type ParticipantRequest struct {
+	Action       string   `json:"action,omitempty"`
+	GroupID      string   `json:"group_id,omitempty"`
+	Participants []string `json:"participants,omitempty"`
+}
+
+// PlannerRebalanceRequest is the PlannerRebalanceRequest schema
+type PlannerRebalanceRequest struct {
+	Apply bool   `json:"apply,omitempty"`
+	Mode  string `json:"mode,omitempty"`
+}
+
+// PollRequest is the PollRequest schema
+type PollRequest struct {
+	MaxAnswer int      `json:"max_answer,omitempty"`
+	Options   []string `json:"options,omitempty"`
+	Phone     string   `json:"phone,omitempty"`
+	Question  string   `json:"question,omitempty"`
+}
+
+// PresenceRequest is the PresenceRequest schema
+type PresenceRequest struct {
+	IsForwarded bool   `json:"is_forwarded,omitempty"`
+	Type        string `json:"type,omitempty"`
+}
+
+// PublicLeadImport is the PublicLeadImport schema
+type PublicLeadImport struct {
+	ConsentAt     string `json:"consent_at,omitempty"`
+	ConsentProof  string `json:"consent_proof,omitempty"`
+	ConsentSource string `json:"consent_source,omitempty"`
+	Name          string `json:"name,omitempty"`
+	Niche         string `json:"niche,omitempty"`
+	Phone         string `json:"phone,omitempty"`
+	Status        string `json:"status,omitempty"`
+}
+
+// PublicLeadRequest is the PublicLeadRequest schema
+type PublicLeadRequest struct {
+	ConsentAt     string `json:"consent_at,omitempty"`
+	ConsentProof  string `json:"consent_proof,omitempty"`
+	ConsentSource string `json:"consent_source,omitempty"`
+	Name          string `json:"name,omitempty"`
+	Niche         string `json:"niche,omitempty"`
+	Phone         string `json:"phone,omitempty"`
+	TargetStatus  string `json:"target_status,omitempty"`
+}
+
+// ReactionRequest is the ReactionRequest schema
+type ReactionRequest struct {
+	Emoji     string `json:"emoji,omitempty"`
+	MessageID string `json:"message_id,omitempty"`
+	Phone     string `json:"phone,omitempty"`
+}
+
+// RegisterRequest is the RegisterRequest schema
+type RegisterRequest struct {
+	Email    string `json:"email,omitempty"`
+	Fullname string `json:"fullname,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// ResendStepRequest is the ResendStepRequest schema
+type ResendStepRequest struct {
+	ResendFailed    bool `json:"resend_failed,omitempty"`
+	ResendRemaining bool `json:"resend_remaining,omitempty"`
+}
+
+// ResponseData is the ResponseData schema
+type ResponseData struct {
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+	Page    *Page  `json:"page,omitempty"`
+	Results any    `json:"results,omitempty"`
+}
+
+// RevokeRequest is the RevokeRequest schema
+type RevokeRequest struct {
+	MessageID string `json:"message_id,omitempty"`
+	Phone     string `json:"phone,omitempty"`
+}
+
+// SequenceContactsRequest is the SequenceContactsRequest schema
+type SequenceContactsRequest struct {
+	Contacts []string `json:"contacts,omitempty"`
+}
+
+// StarRequest is the StarRequest schema
+type StarRequest struct {
+	IsStarred bool   `json:"is_starred,omitempty"`
+	MessageID string `json:"message_id,omitempty"`
+	Phone     string `json:"phone,omitempty"`
+}
+
+// TeamLoginRequest is the TeamLoginRequest schema
+type TeamLoginRequest struct {
+	Password string `json:"password,omitempty"`
+	Username string `json:"username,omitempty"`
+}
+
+// ThrottleCampaignRequest is the ThrottleCampaignRequest schema
+type ThrottleCampaignRequest struct {
+	MaxDelaySeconds int `json:"max_delay_seconds,omitempty"`
+	MinDelaySeconds int `json:"min_delay_seconds,omitempty"`
+}
+
+// UnfollowRequest is the UnfollowRequest schema
+type UnfollowRequest struct {
+	NewsletterID string `json:"newsletter_id,omitempty"`
+}
+
+// UpdateCampaignRequest is the UpdateCampaignRequest schema
+type UpdateCampaignRequest struct {
+	CampaignDate    string                `json:"campaign_date,omitempty"`
+	ImageURL        string                `json:"image_url,omitempty"`
+	MaxDelaySeconds int                   `json:"max_delay_seconds,omitempty"`
+	Message         string                `json:"message,omitempty"`
+	MessageType     string                `json:"message_type,omitempty"`
+	MinDelaySeconds int                   `json:"min_delay_seconds,omitempty"`
+	Niche           string                `json:"niche,omitempty"`
+	Payload         *MessagePayload       `json:"payload,omitempty"`
+	Status          string                `json:"status,omitempty"`
+	TargetGroups    []CampaignGroupTarget `json:"target_groups,omitempty"`
+	TimeSchedule    string                `json:"time_schedule,omitempty"`
+	Title           string                `json:"title,omitempty"`
+}
+
+// UpdateDeviceJIDRequest is the UpdateDeviceJIDRequest schema
+type UpdateDeviceJIDRequest struct {
+	JID string `json:"jid,omitempty"`
+}
+
+// UpdateMessageRequest is the UpdateMessageRequest schema
+type UpdateMessageRequest struct {
+	Message   string `json:"message,omitempty"`
+	MessageID string `json:"message_id,omitempty"`
+	Phone     string `json:"phone,omitempty"`
+}
+
+// UpdateSequenceRequest is the UpdateSequenceRequest schema
+type UpdateSequenceRequest struct {
+	Description     string                      `json:"description,omitempty"`
+	EndTrigger      string                      `json:"end_trigger,omitempty"`
+	IsActive        bool                        `json:"is_active,omitempty"`
+	MaxDelaySeconds int                         `json:"max_delay_seconds,omitempty"`
+	MinDelaySeconds int                         `json:"min_delay_seconds,omitempty"`
+	Name            string                      `json:"name,omitempty"`
+	Niche           string                      `json:"niche,omitempty"`
+	StartTrigger    string                      `json:"start_trigger,omitempty"`
+	Status          string                      `json:"status,omitempty"`
+	Steps           []CreateSequenceStepRequest `json:"steps,omitempty"`
+	TimeSchedule    string                      `json:"time_schedule,omitempty"`
+	Trigger         string                      `json:"trigger,omitempty"`
+}
+
+// UpdateTeamMemberRequest is the UpdateTeamMemberRequest schema
+type UpdateTeamMemberRequest struct {
+	IsActive bool   `json:"is_active,omitempty"`
+	Password string `json:"password,omitempty"`
+	Username string `json:"username,omitempty"`
+}
+
+// VideoRequest is the VideoRequest schema
+type VideoRequest struct {
+	Caption     string `json:"caption,omitempty"`
+	Compress    bool   `json:"compress,omitempty"`
+	IsForwarded bool   `json:"is_forwarded,omitempty"`
+	Phone       string `json:"phone,omitempty"`
+	Video       *File  `json:"video,omitempty"`
+	ViewOnce    bool   `json:"view_once,omitempty"`
+}
+
+// WebMessageRequest is the WebMessageRequest schema
+type WebMessageRequest struct {
+	ChatID   string `json:"chatId,omitempty"`
+	ImageB64 string `json:"imageB64,omitempty"`
+	ImageURL string `json:"imageUrl,omitempty"`
+	Message  string `json:"message,omitempty"`
+}
+
+// WebhookLeadRequest is the WebhookLeadRequest schema
+type WebhookLeadRequest struct {
+	ConsentAt     string `json:"consent_at,omitempty"`
+	ConsentProof  string `json:"consent_proof,omitempty"`
+	ConsentSource string `json:"consent_source,omitempty"`
+	DeviceID      string `json:"device_id,omitempty"`
+	DeviceName    string `json:"device_name,omitempty"`
+	Name          string `json:"name,omitempty"`
+	Niche         string `json:"niche,omitempty"`
+	Phone         string `json:"phone,omitempty"`
+	Platform      string `json:"platform,omitempty"`
+	TargetStatus  string `json:"target_status,omitempty"`
+	Trigger       string `json:"trigger,omitempty"`
+	UserID        string `json:"user_id,omitempty"`
+}
+
+// GetCustomAnalyticsData calls GET /api/analytics/custom
+//
+// Get custom analytics data
+func (c *Client) GetCustomAnalyticsData(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/analytics/custom"})
+}
+
+// GetAnalyticsData calls GET /api/analytics/{days}
+//
+// Get analytics data
+func (c *Client) GetAnalyticsData(ctx context.Context, days string) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/analytics/" + url.PathEscape(days)})
+}
+
+// ListBroadcastMessagesQuery holds the query parameters of ListBroadcastMessages
+type ListBroadcastMessagesQuery struct {
+	DeviceID    string `query:"device_id"`
+	CampaignID  string `query:"campaign_id"`
+	SequenceID  string `query:"sequence_id"`
+	MessageType string `query:"message_type"`
+	Status      string `query:"status"`
+	Limit       int    `query:"limit"`
+	Cursor      string `query:"cursor"`
+	Sort        string `query:"sort"`
+	Q           string `query:"q"`
+}
+
+// ListBroadcastMessages calls GET /api/broadcast-messages
+//
+// List broadcast messages
+func (c *Client) ListBroadcastMessages(ctx context.Context, query *ListBroadcastMessagesQuery) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/broadcast-messages", query: query})
+}
+
+// GetCampaignsQuery holds the query parameters of GetCampaigns
+type GetCampaignsQuery struct {
+	Status       string `query:"status"`
+	Niche        string `query:"niche"`
+	TargetStatus string `query:"target_status"`
+	MessageType  string `query:"message_type"`
+	Limit        int    `query:"limit"`
+	Cursor       string `query:"cursor"`
+	Sort         string `query:"sort"`
+	Q            string `query:"q"`
+}
+
+// GetCampaigns calls GET /api/campaigns
+//
+// Get campaigns
+func (c *Client) GetCampaigns(ctx context.Context, query *GetCampaignsQuery) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/campaigns", query: query})
+}
+
+// CreateCampaign calls POST /api/campaigns
+//
+// Create campaign
+func (c *Client) CreateCampaign(ctx context.Context, body *CreateCampaignRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/campaigns", body: body})
+}
+
+// TriggerAICampaign calls POST /api/campaigns-ai/{id}/trigger
+//
+// Trigger AI campaign
+func (c *Client) TriggerAICampaign(ctx context.Context, id string) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/campaigns-ai/" + url.PathEscape(id) + "/trigger"})
+}
+
+// GetCampaignAnalytics calls GET /api/campaigns/analytics
+//
+// Get campaign analytics
+func (c *Client) GetCampaignAnalytics(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/campaigns/analytics"})
+}
+
+// GetCampaignSummaryQuery holds the query parameters of GetCampaignSummary
+type GetCampaignSummaryQuery struct {
+	StartDate string `query:"start_date"`
+	EndDate   string `query:"end_date"`
+}
+
+// GetCampaignSummary calls GET /api/campaigns/summary
+//
+// Get campaign summary
+func (c *Client) GetCampaignSummary(ctx context.Context, query *GetCampaignSummaryQuery) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/campaigns/summary", query: query})
+}
+
+// DeleteCampaign calls DELETE /api/campaigns/{id}
+//
+// Delete campaign
+func (c *Client) DeleteCampaign(ctx context.Context, id string) (*Response, error) {
+	return c.do(ctx, request{method: "DELETE", path: "/api/campaigns/" + url.PathEscape(id)})
+}
+
+// UpdateCampaign calls PUT /api/campaigns/{id}
+//
+// Update campaign
+func (c *Client) UpdateCampaign(ctx context.Context, id string, body *UpdateCampaignRequest) (*Response, error) {
+	return c.do(ctx, request{method: "PUT", path: "/api/campaigns/" + url.PathEscape(id), body: body})
+}
+
+// CancelCampaign calls POST /api/campaigns/{id}/cancel
+//
+// Cancel campaign
+func (c *Client) CancelCampaign(ctx context.Context, id string, body *CancelCampaignRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/campaigns/" + url.PathEscape(id) + "/cancel", body: body})
+}
+
+// GetCampaignDeviceReport calls GET /api/campaigns/{id}/device-report
+//
+// Get campaign device report
+func (c *Client) GetCampaignDeviceReport(ctx context.Context, id string) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/campaigns/" + url.PathEscape(id) + "/device-report"})
+}
+
+// GetCampaignDeviceLeadsQuery holds the query parameters of GetCampaignDeviceLeads
+type GetCampaignDeviceLeadsQuery struct {
+	Status    string `query:"status"`
+	StartDate string `query:"start_date"`
+	EndDate   string `query:"end_date"`
+}
+
+// GetCampaignDeviceLeads calls GET /api/campaigns/{id}/device/{deviceId}/leads
+//
+// Get campaign device leads
+func (c *Client) GetCampaignDeviceLeads(ctx context.Context, id string, deviceID string, query *GetCampaignDeviceLeadsQuery) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/campaigns/" + url.PathEscape(id) + "/device/" + url.PathEscape(deviceID) + "/leads", query: query})
+}
+
+// RetryCampaignFailedMessages calls POST /api/campaigns/{id}/device/{deviceId}/retry-failed
+//
+// Retry campaign failed messages
+func (c *Client) RetryCampaignFailedMessages(ctx context.Context, id string, deviceID string) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/campaigns/" + url.PathEscape(id) + "/device/" + url.PathEscape(deviceID) + "/retry-failed"})
+}
+
+// GetCampaignEvents calls GET /api/campaigns/{id}/events
+//
+// Get campaign events
+func (c *Client) GetCampaignEvents(ctx context.Context, id string) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/campaigns/" + url.PathEscape(id) + "/events"})
+}
+
+// PauseCampaign calls POST /api/campaigns/{id}/pause
+//
+// Pause campaign
+func (c *Client) PauseCampaign(ctx context.Context, id string) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/campaigns/" + url.PathEscape(id) + "/pause"})
+}
+
+// ResumeCampaign calls POST /api/campaigns/{id}/resume
+//
+// Resume campaign
+func (c *Client) ResumeCampaign(ctx context.Context, id string) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/campaigns/" + url.PathEscape(id) + "/resume"})
+}
+
+// ThrottleCampaign calls POST /api/campaigns/{id}/throttle
+//
+// Throttle campaign
+func (c *Client) ThrottleCampaign(ctx context.Context, id string, body *ThrottleCampaignRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/campaigns/" + url.PathEscape(id) + "/throttle", body: body})
+}
+
+// GetClusterStatus calls GET /api/cluster
+//
+// Get cluster status
+func (c *Client) GetClusterStatus(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/cluster"})
+}
+
+// ListDataSubjectRequestsQuery holds the query parameters of ListDataSubjectRequests
+type ListDataSubjectRequestsQuery struct {
+	Phone string `query:"phone"`
+}
+
+// ListDataSubjectRequests calls GET /api/data-subjects/requests
+//
+// List data subject requests
+func (c *Client) ListDataSubjectRequests(ctx context.Context, query *ListDataSubjectRequestsQuery) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/data-subjects/requests", query: query})
+}
+
+// EraseDataSubject calls DELETE /api/data-subjects/{phone}
+//
+// Erase data subject
+func (c *Client) EraseDataSubject(ctx context.Context, phone string) (*Response, error) {
+	return c.do(ctx, request{method: "DELETE", path: "/api/data-subjects/" + url.PathEscape(phone)})
+}
+
+// ExportDataSubject calls GET /api/data-subjects/{phone}/export
+//
+// Export data subject
+func (c *Client) ExportDataSubject(ctx context.Context, phone string) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/data-subjects/" + url.PathEscape(phone) + "/export"})
+}
+
+// ListDeadLettersQuery holds the query parameters of ListDeadLetters
+type ListDeadLettersQuery struct {
+	CampaignID int    `query:"campaign_id"`
+	SequenceID string `query:"sequence_id"`
+	DeviceID   string `query:"device_id"`
+	ErrorClass string `query:"error_class"`
+	ErrorCode  string `query:"error_code"`
+	Limit      int    `query:"limit"`
+	Offset     int    `query:"offset"`
+}
+
+// ListDeadLetters calls GET /api/dead-letters
+//
+// List dead letters
+func (c *Client) ListDeadLetters(ctx context.Context, query *ListDeadLettersQuery) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/dead-letters", query: query})
+}
+
+// DiscardDeadLetters calls POST /api/dead-letters/discard
+//
+// Discard dead letters
+func (c *Client) DiscardDeadLetters(ctx context.Context, body *DeadLetterBulkRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/dead-letters/discard", body: body})
+}
+
+// RequeueDeadLetters calls POST /api/dead-letters/requeue
+//
+// Requeue dead letters
+func (c *Client) RequeueDeadLetters(ctx context.Context, body *DeadLetterBulkRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/dead-letters/requeue", body: body})
+}
+
+// GetDeadLetterSummary calls GET /api/dead-letters/summary
+//
+// Get dead letter summary
+func (c *Client) GetDeadLetterSummary(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/dead-letters/summary"})
+}
+
+// TestDeviceClient calls GET /api/debug/device-client/{deviceId}
+//
+// Test device client
+func (c *Client) TestDeviceClient(ctx context.Context, deviceID string) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/debug/device-client/" + url.PathEscape(deviceID)})
+}
+
+// DebugWhatsAppClients calls GET /api/debug/whatsapp-clients
+//
+// Debug whats app clients
+func (c *Client) DebugWhatsAppClients(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/debug/whatsapp-clients"})
+}
+
+// GetConnectedDevices calls GET /api/devices
+//
+// Get connected devices
+func (c *Client) GetConnectedDevices(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/devices"})
+}
+
+// CreateDevice calls POST /api/devices
+//
+// Create device
+func (c *Client) CreateDevice(ctx context.Context, body *CreateDeviceRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/devices", body: body})
+}
+
+// SimpleCheckConnection calls GET /api/devices/check-connection
+//
+// Simple check connection
+func (c *Client) SimpleCheckConnection(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/devices/check-connection"})
+}
+
+// ClearAllSessions calls POST /api/devices/clear-all-sessions
+//
+// Clear all sessions
+func (c *Client) ClearAllSessions(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/devices/clear-all-sessions"})
+}
+
+// MergeDeviceContacts calls POST /api/devices/merge-contacts
+//
+// Merge device contacts
+func (c *Client) MergeDeviceContacts(ctx context.Context, body *MergeContactsRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/devices/merge-contacts", body: body})
+}
+
+// ReconnectAllOfflineDevices calls POST /api/devices/reconnect-offline
+//
+// Reconnect all offline devices
+func (c *Client) ReconnectAllOfflineDevices(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/devices/reconnect-offline"})
+}
+
+// ResetAllDevices calls POST /api/devices/reset-all
+//
+// Reset all devices
+func (c *Client) ResetAllDevices(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/devices/reset-all"})
+}
+
+// ClearDeviceData calls DELETE /api/devices/{deviceId}/clear
+//
+// Clear device data
+func (c *Client) ClearDeviceData(ctx context.Context, deviceID string) (*Response, error) {
+	return c.do(ctx, request{method: "DELETE", path: "/api/devices/" + url.PathEscape(deviceID) + "/clear"})
+}
+
+// ClearDeviceSession calls POST /api/devices/{deviceId}/clear-session
+//
+// Clear device session
+func (c *Client) ClearDeviceSession(ctx context.Context, deviceID string) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/devices/" + url.PathEscape(deviceID) + "/clear-session"})
+}
+
+// DeviceConnect calls POST /api/devices/{deviceId}/connect
+//
+// Device connect
+func (c *Client) DeviceConnect(ctx context.Context, deviceID string) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/devices/" + url.PathEscape(deviceID) + "/connect"})
+}
+
+// DisconnectDevice calls POST /api/devices/{deviceId}/disconnect
+//
+// Disconnect device
+func (c *Client) DisconnectDevice(ctx context.Context, deviceID string) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/devices/" + url.PathEscape(deviceID) + "/disconnect"})
+}
+
+// GetDeviceLeadsQuery holds the query parameters of GetDeviceLeads
+type GetDeviceLeadsQuery struct {
+	IncludeBroadcastHistory bool   `query:"include_broadcast_history"`
+	Status                  string `query:"status"`
+	TargetStatus            string `query:"target_status"`
+	Niche                   string `query:"niche"`
+	Trigger                 string `query:"trigger"`
+	NumberStatus            string `query:"number_status"`
+	Limit                   int    `query:"limit"`
+	Cursor                  string `query:"cursor"`
+	Sort                    string `query:"sort"`
+	Q                       string `query:"q"`
+}
+
+// GetDeviceLeads calls GET /api/devices/{deviceId}/leads
+//
+// Get device leads
+func (c *Client) GetDeviceLeads(ctx context.Context, deviceID string, query *GetDeviceLeadsQuery) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/devices/" + url.PathEscape(deviceID) + "/leads", query: query})
+}
+
+// ExportLeads calls GET /api/devices/{deviceId}/leads/export
+//
+// Export leads
+func (c *Client) ExportLeads(ctx context.Context, deviceID string) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/devices/" + url.PathEscape(deviceID) + "/leads/export"})
+}
+
+// ImportLeads calls POST /api/devices/{deviceId}/leads/import
+//
+// Import leads
+func (c *Client) ImportLeads(ctx context.Context, deviceID string) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/devices/" + url.PathEscape(deviceID) + "/leads/import"})
+}
+
+// SimpleLogout calls POST /api/devices/{deviceId}/logout
+//
+// Simple logout
+func (c *Client) SimpleLogout(ctx context.Context, deviceID string) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/devices/" + url.PathEscape(deviceID) + "/logout"})
+}
+
+// GetDeviceQR calls GET /api/devices/{deviceId}/qr
+//
+// Get device QR
+func (c *Client) GetDeviceQR(ctx context.Context, deviceID string) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/devices/" + url.PathEscape(deviceID) + "/qr"})
+}
+
+// ReconnectDeviceSession calls POST /api/devices/{deviceId}/reconnect
+//
+// Reconnect device session
+func (c *Client) ReconnectDeviceSession(ctx context.Context, deviceID string) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/devices/" + url.PathEscape(deviceID) + "/reconnect"})
+}
+
+// RefreshDevice calls POST /api/devices/{deviceId}/refresh
+//
+// Refresh device
+func (c *Client) RefreshDevice(ctx context.Context, deviceID string) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/devices/" + url.PathEscape(deviceID) + "/refresh"})
+}
+
+// ResetDevice calls POST /api/devices/{deviceId}/reset
+//
+// Reset device
+func (c *Client) ResetDevice(ctx context.Context, deviceID string) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/devices/" + url.PathEscape(deviceID) + "/reset"})
+}
+
+// DeleteDevice calls DELETE /api/devices/{id}
+//
+// Delete device
+func (c *Client) DeleteDevice(ctx context.Context, id string) (*Response, error) {
+	return c.do(ctx, request{method: "DELETE", path: "/api/devices/" + url.PathEscape(id)})
+}
+
+// GetDevice calls GET /api/devices/{id}
+//
+// Get device
+func (c *Client) GetDevice(ctx context.Context, id string) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/devices/" + url.PathEscape(id)})
+}
+
+// GetWhatsAppChatsQuery holds the query parameters of GetWhatsAppChats
+type GetWhatsAppChatsQuery struct {
+	Limit  int    `query:"limit"`
+	Cursor string `query:"cursor"`
+	Sort   string `query:"sort"`
+	Q      string `query:"q"`
+}
+
+// GetWhatsAppChats calls GET /api/devices/{id}/chats
+//
+// Get whats app chats
+func (c *Client) GetWhatsAppChats(ctx context.Context, id string, query *GetWhatsAppChatsQuery) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/devices/" + url.PathEscape(id) + "/chats", query: query})
+}
+
+// GetChatBackfill calls GET /api/devices/{id}/chats/{chatId}/backfill
+//
+// Get chat backfill
+func (c *Client) GetChatBackfill(ctx context.Context, id string, chatID string) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/devices/" + url.PathEscape(id) + "/chats/" + url.PathEscape(chatID) + "/backfill"})
+}
+
+// RequestChatBackfill calls POST /api/devices/{id}/chats/{chatId}/backfill
+//
+// Request chat backfill
+func (c *Client) RequestChatBackfill(ctx context.Context, id string, chatID string, body *BackfillRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/devices/" + url.PathEscape(id) + "/chats/" + url.PathEscape(chatID) + "/backfill", body: body})
+}
+
+// DiagnoseDevice calls GET /api/devices/{id}/diagnose
+//
+// Diagnose device
+func (c *Client) DiagnoseDevice(ctx context.Context, id string) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/devices/" + url.PathEscape(id) + "/diagnose"})
+}
+
+// RefreshWhatsAppGroups calls POST /api/devices/{id}/groups/refresh
+//
+// Refresh whats app groups
+func (c *Client) RefreshWhatsAppGroups(ctx context.Context, id string) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/devices/" + url.PathEscape(id) + "/groups/refresh"})
+}
+
+// GetWhatsAppGroupImport calls GET /api/devices/{id}/groups/{groupId}/import-leads
+//
+// Get whats app group import
+func (c *Client) GetWhatsAppGroupImport(ctx context.Context, id string, groupID string) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/devices/" + url.PathEscape(id) + "/groups/" + url.PathEscape(groupID) + "/import-leads"})
+}
+
+// ImportWhatsAppGroupLeads calls POST /api/devices/{id}/groups/{groupId}/import-leads
+//
+// Import whats app group leads
+func (c *Client) ImportWhatsAppGroupLeads(ctx context.Context, id string, groupID string, body *GroupLeadOptions) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/devices/" + url.PathEscape(id) + "/groups/" + url.PathEscape(groupID) + "/import-leads", body: body})
+}
+
+// GetWhatsAppGroupMembers calls GET /api/devices/{id}/groups/{groupId}/members
+//
+// Get whats app group members
+func (c *Client) GetWhatsAppGroupMembers(ctx context.Context, id string, groupID string) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/devices/" + url.PathEscape(id) + "/groups/" + url.PathEscape(groupID) + "/members"})
+}
+
+// GetHistoryPolicy calls GET /api/devices/{id}/history-policy
+//
+// Get history policy
+func (c *Client) GetHistoryPolicy(ctx context.Context, id string) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/devices/" + url.PathEscape(id) + "/history-policy"})
+}
+
+// UpdateHistoryPolicy calls PUT /api/devices/{id}/history-policy
+//
+// Update history policy
+func (c *Client) UpdateHistoryPolicy(ctx context.Context, id string, body *HistoryPolicy) (*Response, error) {
+	return c.do(ctx, request{method: "PUT", path: "/api/devices/" + url.PathEscape(id) + "/history-policy", body: body})
+}
+
+// GetMessageMedia calls GET /api/devices/{id}/media/{messageId}
+//
+// Get message media
+func (c *Client) GetMessageMedia(ctx context.Context, id string, messageID string) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/devices/" + url.PathEscape(id) + "/media/" + url.PathEscape(messageID)})
+}
+
+// GetMessageMediaThumbnail calls GET /api/devices/{id}/media/{messageId}/thumbnail
+//
+// Get message media thumbnail
+func (c *Client) GetMessageMediaThumbnail(ctx context.Context, id string, messageID string) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/devices/" + url.PathEscape(id) + "/media/" + url.PathEscape(messageID) + "/thumbnail"})
+}
+
+// GetWhatsAppMessages calls GET /api/devices/{id}/messages/{chatId}
+//
+// Get whats app messages
+func (c *Client) GetWhatsAppMessages(ctx context.Context, id string, chatID string) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/devices/" + url.PathEscape(id) + "/messages/" + url.PathEscape(chatID)})
+}
+
+// SendWhatsAppWebMessage calls POST /api/devices/{id}/send
+//
+// Send whats app web message
+func (c *Client) SendWhatsAppWebMessage(ctx context.Context, id string, body *WebMessageRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/devices/" + url.PathEscape(id) + "/send", body: body})
+}
+
+// SyncWhatsAppDevice calls POST /api/devices/{id}/sync
+//
+// Sync whats app device
+func (c *Client) SyncWhatsAppDevice(ctx context.Context, id string) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/devices/" + url.PathEscape(id) + "/sync"})
+}
+
+// SyncWhatsAppContacts calls POST /api/devices/{id}/sync-contacts
+//
+// Sync whats app contacts
+func (c *Client) SyncWhatsAppContacts(ctx context.Context, id string) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/devices/" + url.PathEscape(id) + "/sync-contacts"})
+}
+
+// UpdateDeviceJID calls PUT /api/devices/{id}/update-jid
+//
+// Update device JID
+func (c *Client) UpdateDeviceJID(ctx context.Context, id string, body *UpdateDeviceJIDRequest) (*Response, error) {
+	return c.do(ctx, request{method: "PUT", path: "/api/devices/" + url.PathEscape(id) + "/update-jid", body: body})
+}
+
+// ListWhatsAppGroupsQuery holds the query parameters of ListWhatsAppGroups
+type ListWhatsAppGroupsQuery struct {
+	DeviceID string `query:"device_id"`
+	Limit    int    `query:"limit"`
+	Cursor   string `query:"cursor"`
+	Sort     string `query:"sort"`
+	Q        string `query:"q"`
+}
+
+// ListWhatsAppGroups calls GET /api/groups
+//
+// List whats app groups
+func (c *Client) ListWhatsAppGroups(ctx context.Context, query *ListWhatsAppGroupsQuery) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/groups", query: query})
+}
+
+// HealthCheck calls GET /api/health
+//
+// Health check
+func (c *Client) HealthCheck(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/health"})
+}
+
+// CreateLead calls POST /api/leads
+//
+// Create lead
+func (c *Client) CreateLead(ctx context.Context, body *LeadRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/leads", body: body})
+}
+
+// GetLeadsAIQuery holds the query parameters of GetLeadsAI
+type GetLeadsAIQuery struct {
+	Status       string `query:"status"`
+	TargetStatus string `query:"target_status"`
+	Niche        string `query:"niche"`
+	Source       string `query:"source"`
+	Limit        int    `query:"limit"`
+	Cursor       string `query:"cursor"`
+	Sort         string `query:"sort"`
+	Q            string `query:"q"`
+}
+
+// GetLeadsAI calls GET /api/leads-ai
+//
+// Get leads AI
+func (c *Client) GetLeadsAI(ctx context.Context, query *GetLeadsAIQuery) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/leads-ai", query: query})
+}
+
+// CreateLeadAI calls POST /api/leads-ai
+//
+// Create lead AI
+func (c *Client) CreateLeadAI(ctx context.Context, body *LeadAIRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/leads-ai", body: body})
+}
+
+// DeleteLeadAI calls DELETE /api/leads-ai/{id}
+//
+// Delete lead AI
+func (c *Client) DeleteLeadAI(ctx context.Context, id string) (*Response, error) {
+	return c.do(ctx, request{method: "DELETE", path: "/api/leads-ai/" + url.PathEscape(id)})
+}
+
+// UpdateLeadAI calls PUT /api/leads-ai/{id}
+//
+// Update lead AI
+func (c *Client) UpdateLeadAI(ctx context.Context, id string, body *LeadAIRequest) (*Response, error) {
+	return c.do(ctx, request{method: "PUT", path: "/api/leads-ai/" + url.PathEscape(id), body: body})
+}
+
+// DeleteLead calls DELETE /api/leads/{id}
+//
+// Delete lead
+func (c *Client) DeleteLead(ctx context.Context, id string) (*Response, error) {
+	return c.do(ctx, request{method: "DELETE", path: "/api/leads/" + url.PathEscape(id)})
+}
+
+// UpdateLead calls PUT /api/leads/{id}
+//
+// Update lead
+func (c *Client) UpdateLead(ctx context.Context, id string, body *LeadRequest) (*Response, error) {
+	return c.do(ctx, request{method: "PUT", path: "/api/leads/" + url.PathEscape(id), body: body})
+}
+
+// GetLeadConsents calls GET /api/leads/{id}/consents
+//
+// Get lead consents
+func (c *Client) GetLeadConsents(ctx context.Context, id string) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/leads/" + url.PathEscape(id) + "/consents"})
+}
+
+// AddLeadConsent calls POST /api/leads/{id}/consents
+//
+// Add lead consent
+func (c *Client) AddLeadConsent(ctx context.Context, id string, body *ConsentRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/leads/" + url.PathEscape(id) + "/consents", body: body})
+}
+
+// AddLeadNote calls POST /api/leads/{id}/notes
+//
+// Add lead note
+func (c *Client) AddLeadNote(ctx context.Context, id string, body *LeadNoteRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/leads/" + url.PathEscape(id) + "/notes", body: body})
+}
+
+// GetLeadTimelineQuery holds the query parameters of GetLeadTimeline
+type GetLeadTimelineQuery struct {
+	Kind      string `query:"kind"`
+	DeviceID  string `query:"device_id"`
+	StartDate string `query:"start_date"`
+	EndDate   string `query:"end_date"`
+	Limit     int    `query:"limit"`
+	Cursor    string `query:"cursor"`
+	Sort      string `query:"sort"`
+	Q         string `query:"q"`
+}
+
+// GetLeadTimeline calls GET /api/leads/{id}/timeline
+//
+// Get lead timeline
+func (c *Client) GetLeadTimeline(ctx context.Context, id string, query *GetLeadTimelineQuery) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/leads/" + url.PathEscape(id) + "/timeline", query: query})
+}
+
+// ExportLeadTranscriptQuery holds the query parameters of ExportLeadTranscript
+type ExportLeadTranscriptQuery struct {
+	Format    string `query:"format"`
+	StartDate string `query:"start_date"`
+	EndDate   string `query:"end_date"`
+}
+
+// ExportLeadTranscript calls GET /api/leads/{id}/transcript
+//
+// Export lead transcript
+func (c *Client) ExportLeadTranscript(ctx context.Context, id string, query *ExportLeadTranscriptQuery) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/leads/" + url.PathEscape(id) + "/transcript", query: query})
+}
+
+// HandleLogin calls POST /api/login
+//
+// Handle login
+func (c *Client) HandleLogin(ctx context.Context, body *LoginRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/login", body: body})
+}
+
+// ListMediaAssetsQuery holds the query parameters of ListMediaAssets
+type ListMediaAssetsQuery struct {
+	Limit  int `query:"limit"`
+	Offset int `query:"offset"`
+}
+
+// ListMediaAssets calls GET /api/media-assets
+//
+// List media assets
+func (c *Client) ListMediaAssets(ctx context.Context, query *ListMediaAssetsQuery) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/media-assets", query: query})
+}
+
+// CreateMediaAsset calls POST /api/media-assets
+//
+// Create media asset
+func (c *Client) CreateMediaAsset(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/media-assets"})
+}
+
+// DeleteMediaAsset calls DELETE /api/media-assets/{id}
+//
+// Delete media asset
+func (c *Client) DeleteMediaAsset(ctx context.Context, id string) (*Response, error) {
+	return c.do(ctx, request{method: "DELETE", path: "/api/media-assets/" + url.PathEscape(id)})
+}
+
+// GetMediaAsset calls GET /api/media-assets/{id}
+//
+// Get media asset
+func (c *Client) GetMediaAsset(ctx context.Context, id string) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/media-assets/" + url.PathEscape(id)})
+}
+
+// GetMediaAssetContent calls GET /api/media-assets/{id}/content
+//
+// Get media asset content
+func (c *Client) GetMediaAssetContent(ctx context.Context, id string) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/media-assets/" + url.PathEscape(id) + "/content"})
+}
+
+// GetMediaAssetThumbnail calls GET /api/media-assets/{id}/thumbnail
+//
+// Get media asset thumbnail
+func (c *Client) GetMediaAssetThumbnail(ctx context.Context, id string) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/media-assets/" + url.PathEscape(id) + "/thumbnail"})
+}
+
+// SearchMessagesQuery holds the query parameters of SearchMessages
+type SearchMessagesQuery struct {
+	DeviceID    string `query:"device_id"`
+	ChatJID     string `query:"chat_jid"`
+	SenderJID   string `query:"sender_jid"`
+	MessageType string `query:"message_type"`
+	Direction   string `query:"direction"`
+	StartDate   string `query:"start_date"`
+	EndDate     string `query:"end_date"`
+	Limit       int    `query:"limit"`
+	Cursor      string `query:"cursor"`
+	Sort        string `query:"sort"`
+	Q           string `query:"q"`
+}
+
+// SearchMessages calls GET /api/messages/search
+//
+// Search messages
+func (c *Client) SearchMessages(ctx context.Context, query *SearchMessagesQuery) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/messages/search", query: query})
+}
+
+// ExpireOldMessagesQuery holds the query parameters of ExpireOldMessages
+type ExpireOldMessagesQuery struct {
+	Hours int `query:"hours"`
+}
+
+// ExpireOldMessages calls POST /api/monitoring/expire-messages
+//
+// Expire old messages
+func (c *Client) ExpireOldMessages(ctx context.Context, query *ExpireOldMessagesQuery) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/monitoring/expire-messages", query: query})
+}
+
+// ClearQueue calls DELETE /api/monitoring/queue/{queue}
+//
+// Clear queue
+func (c *Client) ClearQueue(ctx context.Context, queue string) (*Response, error) {
+	return c.do(ctx, request{method: "DELETE", path: "/api/monitoring/queue/" + url.PathEscape(queue)})
+}
+
+// GetQueueMessages calls GET /api/monitoring/queue/{queue}
+//
+// Get queue messages
+func (c *Client) GetQueueMessages(ctx context.Context, queue string) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/monitoring/queue/" + url.PathEscape(queue)})
+}
+
+// GetRedisMetrics calls GET /api/monitoring/redis
+//
+// Get redis metrics
+func (c *Client) GetRedisMetrics(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/monitoring/redis"})
+}
+
+// GetNiches calls GET /api/niches
+//
+// Get niches
+func (c *Client) GetNiches(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/niches"})
+}
+
+// GetOpenAPISpec calls GET /api/openapi.yaml
+//
+// Get open API spec
+func (c *Client) GetOpenAPISpec(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/openapi.yaml"})
+}
+
+// GetPlannerTimelineQuery holds the query parameters of GetPlannerTimeline
+type GetPlannerTimelineQuery struct {
+	Start string `query:"start"`
+	Days  int    `query:"days"`
+}
+
+// GetPlannerTimeline calls GET /api/planner
+//
+// Get planner timeline
+func (c *Client) GetPlannerTimeline(ctx context.Context, query *GetPlannerTimelineQuery) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/planner", query: query})
+}
+
+// RebalancePlanner calls POST /api/planner/rebalance
+//
+// Rebalance planner
+func (c *Client) RebalancePlanner(ctx context.Context, body *PlannerRebalanceRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/planner/rebalance", body: body})
+}
+
+// PublicCampaignSummaryQuery holds the query parameters of PublicCampaignSummary
+type PublicCampaignSummaryQuery struct {
+	StartDate string `query:"start_date"`
+	EndDate   string `query:"end_date"`
+}
+
+// PublicCampaignSummary calls GET /api/public/device/{deviceId}/campaign-summary
+//
+// Public campaign summary
+func (c *Client) PublicCampaignSummary(ctx context.Context, deviceID string, query *PublicCampaignSummaryQuery) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/public/device/" + url.PathEscape(deviceID) + "/campaign-summary", query: query})
+}
+
+// PublicDeviceCampaigns calls GET /api/public/device/{deviceId}/campaigns
+//
+// Public device campaigns
+func (c *Client) PublicDeviceCampaigns(ctx context.Context, deviceID string) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/public/device/" + url.PathEscape(deviceID) + "/campaigns"})
+}
+
+// PublicCampaignDeviceReport calls GET /api/public/device/{deviceId}/campaigns/{campaignId}/device-report
+//
+// Public campaign device report
+func (c *Client) PublicCampaignDeviceReport(ctx context.Context, deviceID string, campaignID string) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/public/device/" + url.PathEscape(deviceID) + "/campaigns/" + url.PathEscape(campaignID) + "/device-report"})
+}
+
+// PublicDeviceList calls GET /api/public/device/{deviceId}/devices
+//
+// Public device list
+func (c *Client) PublicDeviceList(ctx context.Context, deviceID string) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/public/device/" + url.PathEscape(deviceID) + "/devices"})
+}
+
+// PublicDeviceInfo calls GET /api/public/device/{deviceId}/info
+//
+// Public device info
+func (c *Client) PublicDeviceInfo(ctx context.Context, deviceID string) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/public/device/" + url.PathEscape(deviceID) + "/info"})
+}
+
+// PublicCreateLead calls POST /api/public/device/{deviceId}/lead
+//
+// Public create lead
+func (c *Client) PublicCreateLead(ctx context.Context, deviceID string, body *PublicLeadRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/public/device/" + url.PathEscape(deviceID) + "/lead", body: body})
+}
+
+// PublicDeleteLead calls DELETE /api/public/device/{deviceId}/lead/{leadId}
+//
+// Public delete lead
+func (c *Client) PublicDeleteLead(ctx context.Context, deviceID string, leadID string) (*Response, error) {
+	return c.do(ctx, request{method: "DELETE", path: "/api/public/device/" + url.PathEscape(deviceID) + "/lead/" + url.PathEscape(leadID)})
+}
+
+// PublicUpdateLead calls PUT /api/public/device/{deviceId}/lead/{leadId}
+//
+// Public update lead
+func (c *Client) PublicUpdateLead(ctx context.Context, deviceID string, leadID string, body *PublicLeadRequest) (*Response, error) {
+	return c.do(ctx, request{method: "PUT", path: "/api/public/device/" + url.PathEscape(deviceID) + "/lead/" + url.PathEscape(leadID), body: body})
+}
+
+// PublicListLeadsQuery holds the query parameters of PublicListLeads
+type PublicListLeadsQuery struct {
+	Niche  string `query:"niche"`
+	Status string `query:"status"`
+	Search string `query:"search"`
+}
+
+// PublicListLeads calls GET /api/public/device/{deviceId}/leads
+//
+// Public list leads
+func (c *Client) PublicListLeads(ctx context.Context, deviceID string, query *PublicListLeadsQuery) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/public/device/" + url.PathEscape(deviceID) + "/leads", query: query})
+}
+
+// PublicImportLeads calls POST /api/public/device/{deviceId}/leads/import
+//
+// Public import leads
+func (c *Client) PublicImportLeads(ctx context.Context, deviceID string, body []PublicLeadImport) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/public/device/" + url.PathEscape(deviceID) + "/leads/import", body: body})
+}
+
+// PublicDeviceMessages calls GET /api/public/device/{deviceId}/messages
+//
+// Public device messages
+func (c *Client) PublicDeviceMessages(ctx context.Context, deviceID string) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/public/device/" + url.PathEscape(deviceID) + "/messages"})
+}
+
+// PublicSequenceSummaryQuery holds the query parameters of PublicSequenceSummary
+type PublicSequenceSummaryQuery struct {
+	StartDate string `query:"start_date"`
+	EndDate   string `query:"end_date"`
+}
+
+// PublicSequenceSummary calls GET /api/public/device/{deviceId}/sequence-summary
+//
+// Public sequence summary
+func (c *Client) PublicSequenceSummary(ctx context.Context, deviceID string, query *PublicSequenceSummaryQuery) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/public/device/" + url.PathEscape(deviceID) + "/sequence-summary", query: query})
+}
+
+// PublicDeviceSequences calls GET /api/public/device/{deviceId}/sequences
+//
+// Public device sequences
+func (c *Client) PublicDeviceSequences(ctx context.Context, deviceID string) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/public/device/" + url.PathEscape(deviceID) + "/sequences"})
+}
+
+// PublicSequenceDeviceReportQuery holds the query parameters of PublicSequenceDeviceReport
+type PublicSequenceDeviceReportQuery struct {
+	StartDate string `query:"start_date"`
+	EndDate   string `query:"end_date"`
+}
+
+// PublicSequenceDeviceReport calls GET /api/public/device/{deviceId}/sequences/{sequenceId}/device-report
+//
+// Public sequence device report
+func (c *Client) PublicSequenceDeviceReport(ctx context.Context, deviceID string, sequenceID string, query *PublicSequenceDeviceReportQuery) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/public/device/" + url.PathEscape(deviceID) + "/sequences/" + url.PathEscape(sequenceID) + "/device-report", query: query})
+}
+
+// CleanupDeviceFromRedis calls POST /api/redis/cleanup-device/{deviceId}
+//
+// Cleanup device from redis
+func (c *Client) CleanupDeviceFromRedis(ctx context.Context, deviceID string) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/redis/cleanup-device/" + url.PathEscape(deviceID)})
+}
+
+// CleanupAllOldDevices calls POST /api/redis/cleanup-old-devices
+//
+// Cleanup all old devices
+func (c *Client) CleanupAllOldDevices(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/redis/cleanup-old-devices"})
+}
+
+// HandleRegister calls POST /api/register
+//
+// Handle register
+func (c *Client) HandleRegister(ctx context.Context, body *RegisterRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/register", body: body})
+}
+
+// GetSchedulerStatus calls GET /api/scheduler
+//
+// Get scheduler status
+func (c *Client) GetSchedulerStatus(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/scheduler"})
+}
+
+// GetSequencesQuery holds the query parameters of GetSequences
+type GetSequencesQuery struct {
+	Status string `query:"status"`
+	Niche  string `query:"niche"`
+	Limit  int    `query:"limit"`
+	Cursor string `query:"cursor"`
+	Sort   string `query:"sort"`
+	Q      string `query:"q"`
+}
+
+// GetSequences calls GET /api/sequences
+//
+// Get sequences
+func (c *Client) GetSequences(ctx context.Context, query *GetSequencesQuery) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/sequences", query: query})
+}
+
+// CreateSequence calls POST /api/sequences
+//
+// Create sequence
+func (c *Client) CreateSequence(ctx context.Context, body *CreateSequenceRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/sequences", body: body})
+}
+
+// GetSequenceAnalytics calls GET /api/sequences/analytics
+//
+// Get sequence analytics
+func (c *Client) GetSequenceAnalytics(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/sequences/analytics"})
+}
+
+// ConvertSequenceFlows calls POST /api/sequences/flows/convert
+//
+// Convert sequence flows
+func (c *Client) ConvertSequenceFlows(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/sequences/flows/convert"})
+}
+
+// ValidateSequenceFlow calls POST /api/sequences/flows/validate
+//
+// Validate sequence flow
+func (c *Client) ValidateSequenceFlow(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/sequences/flows/validate"})
+}
+
+// MigrateLazySteps calls POST /api/sequences/migrate-lazy
+//
+// Migrate lazy steps
+func (c *Client) MigrateLazySteps(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/sequences/migrate-lazy"})
+}
+
+// GetSequenceSummaryQuery holds the query parameters of GetSequenceSummary
+type GetSequenceSummaryQuery struct {
+	StartDate string `query:"start_date"`
+	EndDate   string `query:"end_date"`
+	Today     string `query:"today"`
+}
+
+// GetSequenceSummary calls GET /api/sequences/summary
+//
+// Get sequence summary
+func (c *Client) GetSequenceSummary(ctx context.Context, query *GetSequenceSummaryQuery) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/sequences/summary", query: query})
+}
+
+// DeleteSequence calls DELETE /api/sequences/{id}
+//
+// Delete sequence
+func (c *Client) DeleteSequence(ctx context.Context, id string) (*Response, error) {
+	return c.do(ctx, request{method: "DELETE", path: "/api/sequences/" + url.PathEscape(id)})
+}
+
+// GetSequenceByID calls GET /api/sequences/{id}
+//
+// Get sequence by ID
+func (c *Client) GetSequenceByID(ctx context.Context, id string) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/sequences/" + url.PathEscape(id)})
+}
+
+// UpdateSequence calls PUT /api/sequences/{id}
+//
+// Update sequence
+func (c *Client) UpdateSequence(ctx context.Context, id string, body *UpdateSequenceRequest) (*Response, error) {
+	return c.do(ctx, request{method: "PUT", path: "/api/sequences/" + url.PathEscape(id), body: body})
+}
+
+// GetContactsQuery holds the query parameters of GetContacts
+type GetContactsQuery struct {
+	Status      string `query:"status"`
+	CurrentStep string `query:"current_step"`
+	Limit       int    `query:"limit"`
+	Cursor      string `query:"cursor"`
+	Sort        string `query:"sort"`
+	Q           string `query:"q"`
+}
+
+// GetContacts calls GET /api/sequences/{id}/contacts
+//
+// Get contacts
+func (c *Client) GetContacts(ctx context.Context, id string, query *GetContactsQuery) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/sequences/" + url.PathEscape(id) + "/contacts", query: query})
+}
+
+// AddContacts calls POST /api/sequences/{id}/contacts
+//
+// Add contacts
+func (c *Client) AddContacts(ctx context.Context, id string, body *SequenceContactsRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/sequences/" + url.PathEscape(id) + "/contacts", body: body})
+}
+
+// RemoveContact calls DELETE /api/sequences/{id}/contacts/{contact_id}
+//
+// Remove contact
+func (c *Client) RemoveContact(ctx context.Context, id string, contactID string) (*Response, error) {
+	return c.do(ctx, request{method: "DELETE", path: "/api/sequences/" + url.PathEscape(id) + "/contacts/" + url.PathEscape(contactID)})
+}
+
+// GetSequenceDeviceReportQuery holds the query parameters of GetSequenceDeviceReport
+type GetSequenceDeviceReportQuery struct {
+	StartDate string `query:"start_date"`
+	EndDate   string `query:"end_date"`
+}
+
+// GetSequenceDeviceReport calls GET /api/sequences/{id}/device-report
+//
+// Get sequence device report
+func (c *Client) GetSequenceDeviceReport(ctx context.Context, id string, query *GetSequenceDeviceReportQuery) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/sequences/" + url.PathEscape(id) + "/device-report", query: query})
+}
+
+// GetSequenceDeviceLeadsQuery holds the query parameters of GetSequenceDeviceLeads
+type GetSequenceDeviceLeadsQuery struct {
+	Status    string `query:"status"`
+	StartDate string `query:"start_date"`
+	EndDate   string `query:"end_date"`
+}
+
+// GetSequenceDeviceLeads calls GET /api/sequences/{id}/device/{deviceId}/leads
+//
+// Get sequence device leads
+func (c *Client) GetSequenceDeviceLeads(ctx context.Context, id string, deviceID string, query *GetSequenceDeviceLeadsQuery) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/sequences/" + url.PathEscape(id) + "/device/" + url.PathEscape(deviceID) + "/leads", query: query})
+}
+
+// GetSequenceStepLeadsQuery holds the query parameters of GetSequenceStepLeads
+type GetSequenceStepLeadsQuery struct {
+	Status    string `query:"status"`
+	StartDate string `query:"start_date"`
+	EndDate   string `query:"end_date"`
+}
+
+// GetSequenceStepLeads calls GET /api/sequences/{id}/device/{deviceId}/step/{stepId}/leads
+//
+// Get sequence step leads
+func (c *Client) GetSequenceStepLeads(ctx context.Context, id string, deviceID string, stepID string, query *GetSequenceStepLeadsQuery) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/sequences/" + url.PathEscape(id) + "/device/" + url.PathEscape(deviceID) + "/step/" + url.PathEscape(stepID) + "/leads", query: query})
+}
+
+// ResendFailedSequenceStep calls POST /api/sequences/{id}/device/{deviceId}/step/{stepId}/resend-failed
+//
+// Resend failed sequence step
+func (c *Client) ResendFailedSequenceStep(ctx context.Context, id string, deviceID string, stepID string) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/sequences/" + url.PathEscape(id) + "/device/" + url.PathEscape(deviceID) + "/step/" + url.PathEscape(stepID) + "/resend-failed"})
+}
+
+// GetSequenceFlow calls GET /api/sequences/{id}/flow
+//
+// Get sequence flow
+func (c *Client) GetSequenceFlow(ctx context.Context, id string) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/sequences/" + url.PathEscape(id) + "/flow"})
+}
+
+// SaveSequenceFlow calls PUT /api/sequences/{id}/flow
+//
+// Save sequence flow
+func (c *Client) SaveSequenceFlow(ctx context.Context, id string) (*Response, error) {
+	return c.do(ctx, request{method: "PUT", path: "/api/sequences/" + url.PathEscape(id) + "/flow"})
+}
+
+// FlowUpdate calls POST /api/sequences/{id}/flow-update
+//
+// Flow update
+func (c *Client) FlowUpdate(ctx context.Context, id string) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/sequences/" + url.PathEscape(id) + "/flow-update"})
+}
+
+// ExportSequenceFlow calls GET /api/sequences/{id}/flow/export
+//
+// Export sequence flow
+func (c *Client) ExportSequenceFlow(ctx context.Context, id string) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/sequences/" + url.PathEscape(id) + "/flow/export"})
+}
+
+// GetSequenceLeadsQuery holds the query parameters of GetSequenceLeads
+type GetSequenceLeadsQuery struct {
+	DeviceID     string `query:"device_id"`
+	StatusFilter string `query:"status_filter"`
+	StartDate    string `query:"start_date"`
+	EndDate      string `query:"end_date"`
+}
+
+// GetSequenceLeads calls GET /api/sequences/{id}/leads
+//
+// Get sequence leads
+func (c *Client) GetSequenceLeads(ctx context.Context, id string, query *GetSequenceLeadsQuery) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/sequences/" + url.PathEscape(id) + "/leads", query: query})
+}
+
+// PauseSequence calls POST /api/sequences/{id}/pause
+//
+// Pause sequence
+func (c *Client) PauseSequence(ctx context.Context, id string) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/sequences/" + url.PathEscape(id) + "/pause"})
+}
+
+// GetSequenceProgressQuery holds the query parameters of GetSequenceProgress
+type GetSequenceProgressQuery struct {
+	StartDate string `query:"start_date"`
+	EndDate   string `query:"end_date"`
+}
+
+// GetSequenceProgress calls GET /api/sequences/{id}/progress
+//
+// Get sequence progress
+func (c *Client) GetSequenceProgress(ctx context.Context, id string, query *GetSequenceProgressQuery) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/sequences/" + url.PathEscape(id) + "/progress", query: query})
+}
+
+// GetSequenceProgressNewQuery holds the query parameters of GetSequenceProgressNew
+type GetSequenceProgressNewQuery struct {
+	StartDate string `query:"start_date"`
+	EndDate   string `query:"end_date"`
+}
+
+// GetSequenceProgressNew calls GET /api/sequences/{id}/progress-new
+//
+// Get sequence progress new
+func (c *Client) GetSequenceProgressNew(ctx context.Context, id string, query *GetSequenceProgressNewQuery) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/sequences/" + url.PathEscape(id) + "/progress-new", query: query})
+}
+
+// GetSequenceReportNewQuery holds the query parameters of GetSequenceReportNew
+type GetSequenceReportNewQuery struct {
+	StartDate string `query:"start_date"`
+	EndDate   string `query:"end_date"`
+}
+
+// GetSequenceReportNew calls GET /api/sequences/{id}/report-new
+//
+// Get sequence report new
+func (c *Client) GetSequenceReportNew(ctx context.Context, id string, query *GetSequenceReportNewQuery) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/sequences/" + url.PathEscape(id) + "/report-new", query: query})
+}
+
+// StartSequence calls POST /api/sequences/{id}/start
+//
+// Start sequence
+func (c *Client) StartSequence(ctx context.Context, id string) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/sequences/" + url.PathEscape(id) + "/start"})
+}
+
+// ResendSequenceStepAllDevices calls POST /api/sequences/{id}/step/{stepId}/resend
+//
+// Resend sequence step all devices
+func (c *Client) ResendSequenceStepAllDevices(ctx context.Context, id string, stepID string, body *ResendStepRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/sequences/" + url.PathEscape(id) + "/step/" + url.PathEscape(stepID) + "/resend", body: body})
+}
+
+// ToggleSequence calls POST /api/sequences/{id}/toggle
+//
+// Toggle sequence
+func (c *Client) ToggleSequence(ctx context.Context, id string) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/sequences/" + url.PathEscape(id) + "/toggle"})
+}
+
+// PreviewSequencePendingMessages calls GET /api/sequences/{id}/update-pending-messages
+//
+// Preview sequence pending messages
+func (c *Client) PreviewSequencePendingMessages(ctx context.Context, id string) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/sequences/" + url.PathEscape(id) + "/update-pending-messages"})
+}
+
+// UpdateSequencePendingMessagesQuery holds the query parameters of UpdateSequencePendingMessages
+type UpdateSequencePendingMessagesQuery struct {
+	Confirm bool `query:"confirm"`
+}
+
+// UpdateSequencePendingMessages calls POST /api/sequences/{id}/update-pending-messages
+//
+// Update sequence pending messages
+func (c *Client) UpdateSequencePendingMessages(ctx context.Context, id string, query *UpdateSequencePendingMessagesQuery) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/sequences/" + url.PathEscape(id) + "/update-pending-messages", query: query})
+}
+
+// ListShareLinksQuery holds the query parameters of ListShareLinks
+type ListShareLinksQuery struct {
+	DeviceID string `query:"device_id"`
+}
+
+// ListShareLinks calls GET /api/share-links
+//
+// List share links
+func (c *Client) ListShareLinks(ctx context.Context, query *ListShareLinksQuery) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/share-links", query: query})
+}
+
+// CreateShareLink calls POST /api/share-links
+//
+// Create share link
+func (c *Client) CreateShareLink(ctx context.Context, body *CreateShareLinkRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/share-links", body: body})
+}
+
+// RevokeShareLink calls DELETE /api/share-links/{id}
+//
+// Revoke share link
+func (c *Client) RevokeShareLink(ctx context.Context, id string) (*Response, error) {
+	return c.do(ctx, request{method: "DELETE", path: "/api/share-links/" + url.PathEscape(id)})
+}
+
+// ListShareLinkAccessQuery holds the query parameters of ListShareLinkAccess
+type ListShareLinkAccessQuery struct {
+	Limit  int `query:"limit"`
+	Offset int `query:"offset"`
+}
+
+// ListShareLinkAccess calls GET /api/share-links/{id}/access
+//
+// List share link access
+func (c *Client) ListShareLinkAccess(ctx context.Context, id string, query *ListShareLinkAccessQuery) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/share-links/" + url.PathEscape(id) + "/access", query: query})
+}
+
+// ListSuppressionsQuery holds the query parameters of ListSuppressions
+type ListSuppressionsQuery struct {
+	Limit  int    `query:"limit"`
+	Cursor string `query:"cursor"`
+	Sort   string `query:"sort"`
+	Q      string `query:"q"`
+}
+
+// ListSuppressions calls GET /api/suppressions
+//
+// List suppressions
+func (c *Client) ListSuppressions(ctx context.Context, query *ListSuppressionsQuery) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/suppressions", query: query})
+}
+
+// AddSuppression calls POST /api/suppressions
+//
+// Add suppression
+func (c *Client) AddSuppression(ctx context.Context, body *AddSuppressionRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/suppressions", body: body})
+}
+
+// RemoveSuppression calls DELETE /api/suppressions/{phone}
+//
+// Remove suppression
+func (c *Client) RemoveSuppression(ctx context.Context, phone string) (*Response, error) {
+	return c.do(ctx, request{method: "DELETE", path: "/api/suppressions/" + url.PathEscape(phone)})
+}
+
+// GetSystemConfig calls GET /api/system/config
+//
+// Get system config
+func (c *Client) GetSystemConfig(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/system/config"})
+}
+
+// ReloadSystemConfig calls POST /api/system/config/reload
+//
+// Reload system config
+func (c *Client) ReloadSystemConfig(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/system/config/reload"})
+}
+
+// CheckRedisStatus calls GET /api/system/redis-check
+//
+// Check redis status
+func (c *Client) CheckRedisStatus(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/system/redis-check"})
+}
+
+// GetSystemStatus calls GET /api/system/status
+//
+// Get system status
+func (c *Client) GetSystemStatus(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/system/status"})
+}
+
+// DeleteAPITeam calls DELETE /api/team
+//
+// Delete api team
+func (c *Client) DeleteAPITeam(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "DELETE", path: "/api/team"})
+}
+
+// GetAPITeam calls GET /api/team
+//
+// Get api team
+func (c *Client) GetAPITeam(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/team"})
+}
+
+// PatchAPITeam calls PATCH /api/team
+//
+// Patch api team
+func (c *Client) PatchAPITeam(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "PATCH", path: "/api/team"})
+}
+
+// PostAPITeam calls POST /api/team
+//
+// Post api team
+func (c *Client) PostAPITeam(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/team"})
+}
+
+// PutAPITeam calls PUT /api/team
+//
+// Put api team
+func (c *Client) PutAPITeam(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "PUT", path: "/api/team"})
+}
+
+// GetAllTeamMembers calls GET /api/team-members
+//
+// Get all team members
+func (c *Client) GetAllTeamMembers(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/team-members"})
+}
+
+// CreateTeamMember calls POST /api/team-members
+//
+// Create team member
+func (c *Client) CreateTeamMember(ctx context.Context, body *CreateTeamMemberRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/team-members", body: body})
+}
+
+// DeleteTeamMember calls DELETE /api/team-members/{id}
+//
+// Delete team member
+func (c *Client) DeleteTeamMember(ctx context.Context, id string) (*Response, error) {
+	return c.do(ctx, request{method: "DELETE", path: "/api/team-members/" + url.PathEscape(id)})
+}
+
+// UpdateTeamMember calls PUT /api/team-members/{id}
+//
+// Update team member
+func (c *Client) UpdateTeamMember(ctx context.Context, id string, body *UpdateTeamMemberRequest) (*Response, error) {
+	return c.do(ctx, request{method: "PUT", path: "/api/team-members/" + url.PathEscape(id), body: body})
+}
+
+// TeamCampaignAnalytics calls GET /api/team/campaigns/analytics
+//
+// Team campaign analytics
+func (c *Client) TeamCampaignAnalytics(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/team/campaigns/analytics"})
+}
+
+// TeamCampaignSummaryQuery holds the query parameters of TeamCampaignSummary
+type TeamCampaignSummaryQuery struct {
+	StartDate string `query:"start_date"`
+	EndDate   string `query:"end_date"`
+}
+
+// TeamCampaignSummary calls GET /api/team/campaigns/summary
+//
+// Team campaign summary
+func (c *Client) TeamCampaignSummary(ctx context.Context, query *TeamCampaignSummaryQuery) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/team/campaigns/summary", query: query})
+}
+
+// TeamDevices calls GET /api/team/devices
+//
+// Team devices
+func (c *Client) TeamDevices(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/team/devices"})
+}
+
+// TeamLogin calls POST /api/team/login
+//
+// Team login
+func (c *Client) TeamLogin(ctx context.Context, body *TeamLoginRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/team/login", body: body})
+}
+
+// TeamLogout calls POST /api/team/logout
+//
+// Team logout
+func (c *Client) TeamLogout(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/team/logout"})
+}
+
+// TeamMemberInfo calls GET /api/team/member-info
+//
+// Team member info
+func (c *Client) TeamMemberInfo(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/team/member-info"})
+}
+
+// TeamNiches calls GET /api/team/niches
+//
+// Team niches
+func (c *Client) TeamNiches(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/team/niches"})
+}
+
+// TeamSequences calls GET /api/team/sequences
+//
+// Team sequences
+func (c *Client) TeamSequences(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/team/sequences"})
+}
+
+// TeamSequenceAnalytics calls GET /api/team/sequences/analytics
+//
+// Team sequence analytics
+func (c *Client) TeamSequenceAnalytics(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/team/sequences/analytics"})
+}
+
+// TeamSequenceSummaryQuery holds the query parameters of TeamSequenceSummary
+type TeamSequenceSummaryQuery struct {
+	StartDate string `query:"start_date"`
+	EndDate   string `query:"end_date"`
+}
+
+// TeamSequenceSummary calls GET /api/team/sequences/summary
+//
+// Team sequence summary
+func (c *Client) TeamSequenceSummary(ctx context.Context, query *TeamSequenceSummaryQuery) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/team/sequences/summary", query: query})
+}
+
+// TeamTest calls GET /api/team/test
+//
+// Team test
+func (c *Client) TeamTest(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/team/test"})
+}
+
+// TestDatabaseConnection calls GET /api/test-db
+//
+// Test database connection
+func (c *Client) TestDatabaseConnection(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/test-db"})
+}
+
+// CheckDeviceWorkerStatus calls GET /api/workers/device/{deviceId}
+//
+// Check device worker status
+func (c *Client) CheckDeviceWorkerStatus(ctx context.Context, deviceID string) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/workers/device/" + url.PathEscape(deviceID)})
+}
+
+// HealthCheckAll calls POST /api/workers/health-check
+//
+// Health check all
+func (c *Client) HealthCheckAll(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/workers/health-check"})
+}
+
+// ResumeFailedWorkers calls POST /api/workers/resume-failed
+//
+// Resume failed workers
+func (c *Client) ResumeFailedWorkers(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/workers/resume-failed"})
+}
+
+// GetWorkerStatusQuery holds the query parameters of GetWorkerStatus
+type GetWorkerStatusQuery struct {
+	Filter string `query:"filter"`
+	ID     string `query:"id"`
+}
+
+// GetWorkerStatus calls GET /api/workers/status
+//
+// Get worker status
+func (c *Client) GetWorkerStatus(ctx context.Context, query *GetWorkerStatusQuery) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/api/workers/status", query: query})
+}
+
+// StopAllWorkers calls POST /api/workers/stop-all
+//
+// Stop all workers
+func (c *Client) StopAllWorkers(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/workers/stop-all"})
+}
+
+// RestartWorker calls POST /api/workers/{deviceId}/restart
+//
+// Restart worker
+func (c *Client) RestartWorker(ctx context.Context, deviceID string) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/workers/" + url.PathEscape(deviceID) + "/restart"})
+}
+
+// StartWorker calls POST /api/workers/{deviceId}/start
+//
+// Start worker
+func (c *Client) StartWorker(ctx context.Context, deviceID string) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/api/workers/" + url.PathEscape(deviceID) + "/start"})
+}
+
+// ListAppDevices calls GET /app/devices
+//
+// List app devices
+func (c *Client) ListAppDevices(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/app/devices"})
+}
+
+// LinkDevicePhone calls POST /app/link-device
+//
+// Link device phone
+func (c *Client) LinkDevicePhone(ctx context.Context, body *LinkDeviceRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/app/link-device", body: body})
+}
+
+// LoginDeviceQuery holds the query parameters of LoginDevice
+type LoginDeviceQuery struct {
+	DeviceID string `query:"deviceId"`
+}
+
+// LoginDevice calls GET /app/login
+//
+// Login device
+func (c *Client) LoginDevice(ctx context.Context, query *LoginDeviceQuery) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/app/login", query: query})
+}
+
+// LoginWithCodeQuery holds the query parameters of LoginWithCode
+type LoginWithCodeQuery struct {
+	Phone string `query:"phone"`
+}
+
+// LoginWithCode calls GET /app/login-with-code
+//
+// Login with code
+func (c *Client) LoginWithCode(ctx context.Context, query *LoginWithCodeQuery) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/app/login-with-code", query: query})
+}
+
+// LogoutDeviceQuery holds the query parameters of LogoutDevice
+type LogoutDeviceQuery struct {
+	DeviceID string `query:"deviceId"`
+}
+
+// LogoutDevice calls GET /app/logout
+//
+// Logout device
+func (c *Client) LogoutDevice(ctx context.Context, query *LogoutDeviceQuery) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/app/logout", query: query})
+}
+
+// GetQRCodeQuery holds the query parameters of GetQRCode
+type GetQRCodeQuery struct {
+	DeviceID string `query:"device_id"`
+}
+
+// GetQRCode calls GET /app/qr
+//
+// Get QR code
+func (c *Client) GetQRCode(ctx context.Context, query *GetQRCodeQuery) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/app/qr", query: query})
+}
+
+// ReconnectDeviceQuery holds the query parameters of ReconnectDevice
+type ReconnectDeviceQuery struct {
+	DeviceID string `query:"deviceId"`
+}
+
+// ReconnectDevice calls GET /app/reconnect
+//
+// Reconnect device
+func (c *Client) ReconnectDevice(ctx context.Context, query *ReconnectDeviceQuery) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/app/reconnect", query: query})
+}
+
+// CreateGroup calls POST /group
+//
+// Create group
+func (c *Client) CreateGroup(ctx context.Context, body *CreateGroupRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/group", body: body})
+}
+
+// JoinGroupWithLink calls POST /group/join-with-link
+//
+// Join group with link
+func (c *Client) JoinGroupWithLink(ctx context.Context, body *JoinGroupWithLinkRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/group/join-with-link", body: body})
+}
+
+// LeaveGroup calls POST /group/leave
+//
+// Leave group
+func (c *Client) LeaveGroup(ctx context.Context, body *LeaveGroupRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/group/leave", body: body})
+}
+
+// ListParticipantRequestsQuery holds the query parameters of ListParticipantRequests
+type ListParticipantRequestsQuery struct {
+	GroupID string `query:"group_id"`
+}
+
+// ListParticipantRequests calls GET /group/participant-requests
+//
+// List participant requests
+func (c *Client) ListParticipantRequests(ctx context.Context, query *ListParticipantRequestsQuery) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/group/participant-requests", query: query})
+}
+
+// ApproveParticipantRequests calls POST /group/participant-requests/approve
+//
+// Approve participant requests
+func (c *Client) ApproveParticipantRequests(ctx context.Context, body *GroupRequestParticipantsRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/group/participant-requests/approve", body: body})
+}
+
+// RejectParticipantRequests calls POST /group/participant-requests/reject
+//
+// Reject participant requests
+func (c *Client) RejectParticipantRequests(ctx context.Context, body *GroupRequestParticipantsRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/group/participant-requests/reject", body: body})
+}
+
+// AddParticipants calls POST /group/participants
+//
+// Add participants
+func (c *Client) AddParticipants(ctx context.Context, body *ParticipantRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/group/participants", body: body})
+}
+
+// DemoteParticipants calls POST /group/participants/demote
+//
+// Demote participants
+func (c *Client) DemoteParticipants(ctx context.Context, body *ParticipantRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/group/participants/demote", body: body})
+}
+
+// PromoteParticipants calls POST /group/participants/promote
+//
+// Promote participants
+func (c *Client) PromoteParticipants(ctx context.Context, body *ParticipantRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/group/participants/promote", body: body})
+}
+
+// DeleteParticipants calls POST /group/participants/remove
+//
+// Delete participants
+func (c *Client) DeleteParticipants(ctx context.Context, body *ParticipantRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/group/participants/remove", body: body})
+}
+
+// DeleteMessage calls POST /message/{message_id}/delete
+//
+// Delete message
+func (c *Client) DeleteMessage(ctx context.Context, messageID string, body *DeleteRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/message/" + url.PathEscape(messageID) + "/delete", body: body})
+}
+
+// ReactMessage calls POST /message/{message_id}/reaction
+//
+// React message
+func (c *Client) ReactMessage(ctx context.Context, messageID string, body *ReactionRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/message/" + url.PathEscape(messageID) + "/reaction", body: body})
+}
+
+// MarkAsRead calls POST /message/{message_id}/read
+//
+// Mark as read
+func (c *Client) MarkAsRead(ctx context.Context, messageID string, body *MarkAsReadRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/message/" + url.PathEscape(messageID) + "/read", body: body})
+}
+
+// RevokeMessage calls POST /message/{message_id}/revoke
+//
+// Revoke message
+func (c *Client) RevokeMessage(ctx context.Context, messageID string, body *RevokeRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/message/" + url.PathEscape(messageID) + "/revoke", body: body})
+}
+
+// StarMessage calls POST /message/{message_id}/star
+//
+// Star message
+func (c *Client) StarMessage(ctx context.Context, messageID string, body *StarRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/message/" + url.PathEscape(messageID) + "/star", body: body})
+}
+
+// UnstarMessage calls POST /message/{message_id}/unstar
+//
+// Unstar message
+func (c *Client) UnstarMessage(ctx context.Context, messageID string, body *StarRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/message/" + url.PathEscape(messageID) + "/unstar", body: body})
+}
+
+// UpdateMessage calls POST /message/{message_id}/update
+//
+// Update message
+func (c *Client) UpdateMessage(ctx context.Context, messageID string, body *UpdateMessageRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/message/" + url.PathEscape(messageID) + "/update", body: body})
+}
+
+// Unfollow calls POST /newsletter/unfollow
+//
+// Unfollow
+func (c *Client) Unfollow(ctx context.Context, body *UnfollowRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/newsletter/unfollow", body: body})
+}
+
+// SendAudio calls POST /send/audio
+//
+// Send audio
+func (c *Client) SendAudio(ctx context.Context, body *AudioRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/send/audio", body: body, multipart: true})
+}
+
+// SendContact calls POST /send/contact
+//
+// Send contact
+func (c *Client) SendContact(ctx context.Context, body *ContactRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/send/contact", body: body})
+}
+
+// SendFile calls POST /send/file
+//
+// Send file
+func (c *Client) SendFile(ctx context.Context, body *FileRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/send/file", body: body, multipart: true})
+}
+
+// SendImage calls POST /send/image
+//
+// Send image
+func (c *Client) SendImage(ctx context.Context, body *ImageRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/send/image", body: body, multipart: true})
+}
+
+// SendLink calls POST /send/link
+//
+// Send link
+func (c *Client) SendLink(ctx context.Context, body *LinkRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/send/link", body: body})
+}
+
+// SendLocation calls POST /send/location
+//
+// Send location
+func (c *Client) SendLocation(ctx context.Context, body *LocationRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/send/location", body: body})
+}
+
+// SendText calls POST /send/message
+//
+// Send text
+func (c *Client) SendText(ctx context.Context, body *MessageRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/send/message", body: body})
+}
+
+// SendPoll calls POST /send/poll
+//
+// Send poll
+func (c *Client) SendPoll(ctx context.Context, body *PollRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/send/poll", body: body})
+}
+
+// SendPresence calls POST /send/presence
+//
+// Send presence
+func (c *Client) SendPresence(ctx context.Context, body *PresenceRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/send/presence", body: body})
+}
+
+// SendVideo calls POST /send/video
+//
+// Send video
+func (c *Client) SendVideo(ctx context.Context, body *VideoRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/send/video", body: body, multipart: true})
+}
+
+// GetUserAvatar calls GET /user/avatar
+//
+// Get user avatar
+func (c *Client) GetUserAvatar(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/user/avatar"})
+}
+
+// ChangeUserAvatar calls POST /user/avatar
+//
+// Change user avatar
+func (c *Client) ChangeUserAvatar(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/user/avatar"})
+}
+
+// UserCheckQuery holds the query parameters of UserCheck
+type UserCheckQuery struct {
+	Phone string `query:"phone"`
+}
+
+// UserCheck calls GET /user/check
+//
+// User check
+func (c *Client) UserCheck(ctx context.Context, query *UserCheckQuery) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/user/check", query: query})
+}
+
+// GetUserInfo calls GET /user/info
+//
+// Get user info
+func (c *Client) GetUserInfo(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/user/info"})
+}
+
+// UserMyListContacts calls GET /user/my/contacts
+//
+// User my list contacts
+func (c *Client) UserMyListContacts(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/user/my/contacts"})
+}
+
+// UserMyListGroups calls GET /user/my/groups
+//
+// User my list groups
+func (c *Client) UserMyListGroups(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/user/my/groups"})
+}
+
+// UserMyListNewsletter calls GET /user/my/newsletters
+//
+// User my list newsletter
+func (c *Client) UserMyListNewsletter(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/user/my/newsletters"})
+}
+
+// UserMyPrivacySetting calls GET /user/my/privacy
+//
+// User my privacy setting
+func (c *Client) UserMyPrivacySetting(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "GET", path: "/user/my/privacy"})
+}
+
+// ChangeUserPushName calls POST /user/pushname
+//
+// Change user push name
+func (c *Client) ChangeUserPushName(ctx context.Context) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/user/pushname"})
+}
+
+// CreateLeadWebhook calls POST /webhook/lead/create
+//
+// Create lead webhook
+func (c *Client) CreateLeadWebhook(ctx context.Context, body *WebhookLeadRequest) (*Response, error) {
+	return c.do(ctx, request{method: "POST", path: "/webhook/lead/create", body: body})
+}
@@ -0,0 +1,119 @@
+package apiclient_test
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/database"
+	domainSend "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/send"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/apiclient"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/openapi"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/ui/rest"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSend records what the send handlers pass to the usecase
+type fakeSend struct {
+	domainSend.ISendUsecase
+	text      domainSend.MessageRequest
+	image     domainSend.ImageRequest
+	imageData string
+}
+
+func (f *fakeSend) SendText(_ context.Context, request domainSend.MessageRequest) (domainSend.GenericResponse, error) {
+	f.text = request
+	return domainSend.GenericResponse{MessageID: "text-1", Status: "sent"}, nil
+}
+
+func (f *fakeSend) SendImage(_ context.Context, request domainSend.ImageRequest) (domainSend.GenericResponse, error) {
+	f.image = request
+	file, err := request.Image.Open()
+	if err != nil {
+		return domainSend.GenericResponse{}, err
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	f.imageData = string(data)
+	return domainSend.GenericResponse{MessageID: "image-1", Status: "sent"}, err
+}
+
+// newServer serves the real route table; a bearer token stands in for the
+// session middleware
+func newServer(t *testing.T, send domainSend.ISendUsecase) *httptest.Server {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	database.SetDB(db)
+
+	app := fiber.New()
+	rest.InitPublicRoutes(app)
+	app.Use(func(c *fiber.Ctx) error {
+		if c.Get(fiber.HeaderAuthorization) != "Bearer secret" {
+			return c.Status(401).JSON(utils.ResponseData{Status: 401, Code: "UNAUTHORIZED", Message: "Unauthorized"})
+		}
+		c.Locals("userID", "user-1")
+		return c.Next()
+	})
+	rest.InitRoutes(app, rest.Services{Send: send})
+
+	server := httptest.NewServer(adaptor.FiberApp(app))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClientSendsJSONAndMultipart(t *testing.T) {
+	send := &fakeSend{}
+	client := apiclient.New(newServer(t, send).URL, "secret")
+	ctx := context.Background()
+
+	resp, err := client.SendText(ctx, &apiclient.MessageRequest{Phone: "6281234", Message: "hello"})
+	require.NoError(t, err)
+	assert.Equal(t, "SUCCESS", resp.Code)
+	var sent domainSend.GenericResponse
+	require.NoError(t, resp.Decode(&sent))
+	assert.Equal(t, "text-1", sent.MessageID)
+	assert.Equal(t, "hello", send.text.Message)
+	assert.True(t, strings.HasPrefix(send.text.Phone, "6281234@"), "handler sanitized %q", send.text.Phone)
+
+	resp, err = client.SendImage(ctx, &apiclient.ImageRequest{
+		Phone:   "6281234",
+		Caption: "look",
+		Image:   &apiclient.File{Name: "cat.jpg", Content: strings.NewReader("jpeg bytes")},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "sent", resp.Message)
+	assert.Equal(t, "look", send.image.Caption)
+	assert.Equal(t, "cat.jpg", send.image.Image.Filename)
+	assert.Equal(t, "jpeg bytes", send.imageData)
+}
+
+func TestClientReturnsAPIErrors(t *testing.T) {
+	client := apiclient.New(newServer(t, &fakeSend{}).URL, "wrong")
+
+	resp, err := client.SendText(context.Background(), &apiclient.MessageRequest{Phone: "6281234"})
+	var apiErr *apiclient.Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, 401, apiErr.StatusCode)
+	assert.Equal(t, "UNAUTHORIZED", apiErr.Code)
+	assert.Equal(t, 401, resp.StatusCode)
+}
+
+func TestClientFetchesTheServedSpec(t *testing.T) {
+	client := apiclient.New(newServer(t, &fakeSend{}).URL, "secret")
+
+	resp, err := client.GetOpenAPISpec(context.Background())
+	require.NoError(t, err)
+	doc, err := openapi.Parse(resp.Body)
+	require.NoError(t, err)
+	assert.Contains(t, doc.Paths, "/send/image")
+	assert.Equal(t, "sendImage", doc.Paths["/send/image"]["post"].OperationID)
+	assert.Contains(t, doc.Paths["/send/image"]["post"].RequestBody.Content, "multipart/form-data")
+}
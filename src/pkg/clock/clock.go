@@ -0,0 +1,73 @@
+// Package clock lets time-dependent code run against a fake clock in tests
+// and simulations. Production code calls the package functions, which use the
+// real clock unless another one has been installed with Set.
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells the time and waits
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+	After(d time.Duration) <-chan time.Time
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a pending AfterFunc call
+type Timer interface {
+	Stop() bool
+}
+
+// Real is the system clock
+type Real struct{}
+
+func (Real) Now() time.Time                         { return time.Now() }
+func (Real) Sleep(d time.Duration)                  { time.Sleep(d) }
+func (Real) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (Real) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+var (
+	mu      sync.RWMutex
+	current Clock = Real{}
+)
+
+// Set installs c as the clock used by the package functions and returns a
+// function that restores the previous one
+func Set(c Clock) (restore func()) {
+	mu.Lock()
+	previous := current
+	current = c
+	mu.Unlock()
+	return func() {
+		mu.Lock()
+		current = previous
+		mu.Unlock()
+	}
+}
+
+// Get returns the installed clock
+func Get() Clock {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// Now returns the current time of the installed clock
+func Now() time.Time { return Get().Now() }
+
+// Since returns the time elapsed since t on the installed clock
+func Since(t time.Time) time.Duration { return Get().Now().Sub(t) }
+
+// Sleep pauses for d on the installed clock
+func Sleep(d time.Duration) { Get().Sleep(d) }
+
+// After waits for d on the installed clock and then sends the time
+func After(d time.Duration) <-chan time.Time { return Get().After(d) }
+
+// AfterFunc calls f in its own goroutine after d on the installed clock
+func AfterFunc(d time.Duration, f func()) Timer { return Get().AfterFunc(d, f) }
@@ -0,0 +1,52 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeWakesInDeadlineOrder(t *testing.T) {
+	start := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+	f := NewFake(start)
+
+	var fired []string
+	f.AfterFunc(2*time.Second, func() { fired = append(fired, "b") })
+	f.AfterFunc(time.Second, func() { fired = append(fired, "a") })
+	stopped := f.AfterFunc(time.Second, func() { fired = append(fired, "stopped") })
+	assert.True(t, stopped.Stop())
+
+	woke := f.After(3 * time.Second)
+	slept := make(chan struct{})
+	go func() {
+		f.Sleep(3 * time.Second)
+		close(slept)
+	}()
+	f.BlockUntil(4)
+
+	next, ok := f.Next()
+	assert.True(t, ok)
+	assert.Equal(t, start.Add(time.Second), next)
+
+	f.Advance(2 * time.Second)
+	assert.Equal(t, []string{"a", "b"}, fired)
+	assert.Equal(t, 2, f.Waiters())
+
+	f.Advance(5 * time.Second)
+	assert.Equal(t, start.Add(3*time.Second), <-woke)
+	<-slept
+	assert.Equal(t, start.Add(7*time.Second), f.Now())
+	assert.False(t, stopped.Stop())
+}
+
+func TestSetRestoresPreviousClock(t *testing.T) {
+	start := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+	restore := Set(NewFake(start))
+	assert.Equal(t, start, Now())
+	assert.Equal(t, time.Duration(0), Since(start))
+
+	restore()
+	_, isReal := Get().(Real)
+	assert.True(t, isReal)
+}
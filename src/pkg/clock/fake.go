@@ -0,0 +1,175 @@
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Fake is a clock that only moves when told to. Sleepers and timers fire in
+// deadline order as it is advanced; AfterFunc callbacks run on the goroutine
+// that advances the clock.
+type Fake struct {
+	mu      sync.Mutex
+	changed *sync.Cond
+	now     time.Time
+	seq     int
+	waiters []*fakeWaiter
+}
+
+type fakeWaiter struct {
+	clock *Fake
+	at    time.Time
+	seq   int
+	ch    chan time.Time
+	fn    func()
+}
+
+// NewFake returns a fake clock stopped at now
+func NewFake(now time.Time) *Fake {
+	f := &Fake{now: now}
+	f.changed = sync.NewCond(&f.mu)
+	return f
+}
+
+// Now returns the fake time
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Sleep blocks until the clock has been advanced by d
+func (f *Fake) Sleep(d time.Duration) {
+	<-f.After(d)
+}
+
+// After returns a channel that receives the fake time once the clock has
+// been advanced by d
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if d <= 0 {
+		ch <- f.now
+		return ch
+	}
+	f.add(&fakeWaiter{at: f.now.Add(d), ch: ch})
+	return ch
+}
+
+// AfterFunc calls fn once the clock has been advanced by d
+func (f *Fake) AfterFunc(d time.Duration, fn func()) Timer {
+	f.mu.Lock()
+	w := &fakeWaiter{at: f.now.Add(d), fn: fn}
+	f.add(w)
+	f.mu.Unlock()
+	if d <= 0 {
+		f.AdvanceTo(f.Now())
+	}
+	return w
+}
+
+func (f *Fake) add(w *fakeWaiter) {
+	w.clock = f
+	f.seq++
+	w.seq = f.seq
+	f.waiters = append(f.waiters, w)
+	sort.Slice(f.waiters, func(i, j int) bool {
+		if f.waiters[i].at.Equal(f.waiters[j].at) {
+			return f.waiters[i].seq < f.waiters[j].seq
+		}
+		return f.waiters[i].at.Before(f.waiters[j].at)
+	})
+	f.changed.Broadcast()
+}
+
+// Stop cancels the timer; it reports false if it already fired
+func (w *fakeWaiter) Stop() bool {
+	f := w.clock
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, other := range f.waiters {
+		if other == w {
+			f.waiters = append(f.waiters[:i], f.waiters[i+1:]...)
+			f.changed.Broadcast()
+			return true
+		}
+	}
+	return false
+}
+
+// Advance moves the clock forward by d
+func (f *Fake) Advance(d time.Duration) {
+	f.AdvanceTo(f.Now().Add(d))
+}
+
+// AdvanceTo moves the clock forward to t, waking every sleeper and timer due
+// by then in deadline order. The clock never moves backwards.
+func (f *Fake) AdvanceTo(t time.Time) {
+	for {
+		f.mu.Lock()
+		if len(f.waiters) == 0 || f.waiters[0].at.After(t) {
+			if t.After(f.now) {
+				f.now = t
+			}
+			f.changed.Broadcast()
+			f.mu.Unlock()
+			return
+		}
+		w := f.waiters[0]
+		f.waiters = f.waiters[1:]
+		if w.at.After(f.now) {
+			f.now = w.at
+		}
+		now := f.now
+		f.changed.Broadcast()
+		f.mu.Unlock()
+
+		if w.fn != nil {
+			w.fn()
+		} else {
+			w.ch <- now
+		}
+	}
+}
+
+// Next returns the deadline of the earliest sleeper or timer
+func (f *Fake) Next() (time.Time, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.waiters) == 0 {
+		return time.Time{}, false
+	}
+	return f.waiters[0].at, true
+}
+
+// Waiters returns the number of pending sleepers and timers
+func (f *Fake) Waiters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.waiters)
+}
+
+// Sleepers returns the number of pending Sleep and After calls, leaving out
+// AfterFunc timers
+func (f *Fake) Sleepers() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	sleepers := 0
+	for _, w := range f.waiters {
+		if w.fn == nil {
+			sleepers++
+		}
+	}
+	return sleepers
+}
+
+// BlockUntil waits until at least n sleepers and timers are pending
+func (f *Fake) BlockUntil(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for len(f.waiters) < n {
+		f.changed.Wait()
+	}
+}
@@ -0,0 +1,216 @@
+package error
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SendErrorCode classifies why a message could not be delivered. The code is
+// persisted with failed messages so retries, reports and alerts can act on it.
+type SendErrorCode string
+
+const (
+	SendErrNotOnWhatsapp     SendErrorCode = "NOT_ON_WHATSAPP"
+	SendErrInvalidRecipient  SendErrorCode = "INVALID_RECIPIENT"
+	SendErrDeviceLoggedOut   SendErrorCode = "DEVICE_LOGGED_OUT"
+	SendErrDeviceUnavailable SendErrorCode = "DEVICE_UNAVAILABLE"
+	SendErrMediaFetch        SendErrorCode = "MEDIA_FETCH_FAILED"
+	SendErrMediaUpload       SendErrorCode = "MEDIA_UPLOAD_FAILED"
+	SendErrProvider4xx       SendErrorCode = "PROVIDER_4XX"
+	SendErrProvider5xx       SendErrorCode = "PROVIDER_5XX"
+	SendErrRateLimited       SendErrorCode = "RATE_LIMITED"
+	SendErrEncryption        SendErrorCode = "ENCRYPTION_FAILED"
+	SendErrNetwork           SendErrorCode = "NETWORK_ERROR"
+	SendErrUnknown           SendErrorCode = "UNKNOWN"
+)
+
+// SendErrorCodes lists every send error code
+var SendErrorCodes = []SendErrorCode{
+	SendErrNotOnWhatsapp,
+	SendErrInvalidRecipient,
+	SendErrDeviceLoggedOut,
+	SendErrDeviceUnavailable,
+	SendErrMediaFetch,
+	SendErrMediaUpload,
+	SendErrProvider4xx,
+	SendErrProvider5xx,
+	SendErrRateLimited,
+	SendErrEncryption,
+	SendErrNetwork,
+	SendErrUnknown,
+}
+
+// SendError is a typed message delivery failure
+type SendError struct {
+	Code    SendErrorCode
+	Message string
+	Cause   error
+}
+
+// NewSendError creates a send error, optionally wrapping the underlying cause
+func NewSendError(code SendErrorCode, cause error, format string, args ...interface{}) *SendError {
+	return &SendError{
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+		Cause:   cause,
+	}
+}
+
+// Error for complying the error interface
+func (e *SendError) Error() string {
+	if e.Cause != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Cause)
+	}
+	return e.Message
+}
+
+// Unwrap returns the underlying cause
+func (e *SendError) Unwrap() error {
+	return e.Cause
+}
+
+// ErrCode will return the error code based on the error data type
+func (e *SendError) ErrCode() string {
+	return string(e.Code)
+}
+
+// StatusCode will return the HTTP status code based on the error data type
+func (e *SendError) StatusCode() int {
+	switch e.Code {
+	case SendErrNotOnWhatsapp, SendErrInvalidRecipient, SendErrProvider4xx:
+		return http.StatusBadRequest
+	case SendErrRateLimited:
+		return http.StatusTooManyRequests
+	case SendErrDeviceLoggedOut, SendErrDeviceUnavailable, SendErrProvider5xx:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// ProviderStatusError maps an HTTP response from an external platform to a send error
+func ProviderStatusError(provider string, status int, body string) *SendError {
+	code := SendErrProvider4xx
+	switch {
+	case status == http.StatusTooManyRequests:
+		code = SendErrRateLimited
+	case status >= 500:
+		code = SendErrProvider5xx
+	}
+	return NewSendError(code, nil, "%s returned HTTP %d: %s", provider, status, body)
+}
+
+// ProviderRejectedError is used when a platform accepts the request but reports
+// a failure in its response body; the message decides the class
+func ProviderRejectedError(provider, message string) *SendError {
+	code := ClassifySendError(message)
+	if code == SendErrUnknown {
+		code = SendErrProvider4xx
+	}
+	return NewSendError(code, nil, "%s error: %s", strings.ToLower(provider), message)
+}
+
+// RetryPolicy describes how a class of send failures is retried
+type RetryPolicy struct {
+	Retryable  bool
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+}
+
+// Backoff returns the delay before the given retry (1-based), doubling each time
+func (p RetryPolicy) Backoff(retry int) time.Duration {
+	if retry < 1 {
+		retry = 1
+	}
+	delay := p.BaseDelay
+	for i := 1; i < retry && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+// ShouldRetry reports whether another attempt is allowed after the given number of retries
+func (p RetryPolicy) ShouldRetry(retries int) bool {
+	return p.Retryable && retries < p.MaxRetries
+}
+
+var sendRetryPolicies = map[SendErrorCode]RetryPolicy{
+	SendErrNotOnWhatsapp:     {Retryable: false},
+	SendErrInvalidRecipient:  {Retryable: false},
+	SendErrDeviceLoggedOut:   {Retryable: false},
+	SendErrProvider4xx:       {Retryable: false},
+	SendErrDeviceUnavailable: {Retryable: true, MaxRetries: 3, BaseDelay: time.Minute, MaxDelay: 10 * time.Minute},
+	SendErrMediaFetch:        {Retryable: true, MaxRetries: 2, BaseDelay: 30 * time.Second, MaxDelay: 5 * time.Minute},
+	SendErrMediaUpload:       {Retryable: true, MaxRetries: 3, BaseDelay: 30 * time.Second, MaxDelay: 5 * time.Minute},
+	SendErrProvider5xx:       {Retryable: true, MaxRetries: 3, BaseDelay: 5 * time.Second, MaxDelay: time.Minute},
+	SendErrRateLimited:       {Retryable: true, MaxRetries: 5, BaseDelay: time.Minute, MaxDelay: 30 * time.Minute},
+	SendErrEncryption:        {Retryable: true, MaxRetries: 1, BaseDelay: 10 * time.Second, MaxDelay: 10 * time.Second},
+	SendErrNetwork:           {Retryable: true, MaxRetries: 3, BaseDelay: 2 * time.Second, MaxDelay: time.Minute},
+	SendErrUnknown:           {Retryable: true, MaxRetries: 1, BaseDelay: time.Minute, MaxDelay: time.Minute},
+}
+
+// RetryPolicyFor returns the retry policy of a send error code
+func RetryPolicyFor(code SendErrorCode) RetryPolicy {
+	if policy, ok := sendRetryPolicies[code]; ok {
+		return policy
+	}
+	return sendRetryPolicies[SendErrUnknown]
+}
+
+// SendErrorCodeOf returns the code of a typed send error anywhere in the
+// chain, falling back to classifying the message of untyped errors
+func SendErrorCodeOf(err error) SendErrorCode {
+	if err == nil {
+		return ""
+	}
+	var sendErr *SendError
+	if errors.As(err, &sendErr) {
+		return sendErr.Code
+	}
+	return ClassifySendError(err.Error())
+}
+
+// IsRetryableSendError reports whether the error's class is retried at all
+func IsRetryableSendError(err error) bool {
+	return RetryPolicyFor(SendErrorCodeOf(err)).Retryable
+}
+
+// sendErrorPatterns maps lower-cased fragments of untyped errors (library
+// errors, legacy rows) to a code. Order matters: the first match wins, so the
+// recipient checks come first and fragments are specific enough not to match
+// phone numbers, captions or file names quoted in a message.
+var sendErrorPatterns = []struct {
+	code      SendErrorCode
+	fragments []string
+}{
+	{SendErrNotOnWhatsapp, []string{"not on whatsapp", "not registered", "not a whatsapp"}},
+	{SendErrInvalidRecipient, []string{"invalid phone", "invalid number", "invalid jid", "no jid", "invalid recipient"}},
+	{SendErrRateLimited, []string{"rate limit", "rate-limit", "ratelimit", "too many requests", "rate-overlimit", "status 429", "status code 429", "http 429"}},
+	{SendErrDeviceLoggedOut, []string{"logged out", "not logged in", "logout"}},
+	{SendErrDeviceUnavailable, []string{"not connect", "disconnected", "offline", "no client", "client not found", "device not found", "websocket", "not healthy"}},
+	{SendErrEncryption, []string{"encrypt", "signal session", "no session", "prekey", "identity key"}},
+	{SendErrMediaUpload, []string{"failed to upload", "upload failed", "media upload"}},
+	{SendErrMediaFetch, []string{"failed to download", "download failed", "failed to fetch", "failed to load media", "media url", "maximum download size"}},
+	{SendErrProvider5xx, []string{"internal server error", "bad gateway", "service unavailable", "gateway timeout"}},
+	{SendErrNetwork, []string{"timeout", "tls handshake", "unexpected eof", ": eof", "connection reset", "connection refused", "broken pipe", "no such host"}},
+}
+
+// ClassifySendError maps a free-text error message to a send error code
+func ClassifySendError(message string) SendErrorCode {
+	lower := strings.ToLower(message)
+	for _, pattern := range sendErrorPatterns {
+		for _, fragment := range pattern.fragments {
+			if strings.Contains(lower, fragment) {
+				return pattern.code
+			}
+		}
+	}
+	return SendErrUnknown
+}
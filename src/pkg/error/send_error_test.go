@@ -0,0 +1,58 @@
+package error
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSendErrorCodeOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want SendErrorCode
+	}{
+		{"nil", nil, ""},
+		{"typed", NewSendError(SendErrNotOnWhatsapp, nil, "recipient is not on WhatsApp"), SendErrNotOnWhatsapp},
+		{"wrapped typed", fmt.Errorf("max retries exceeded: %w", NewSendError(SendErrMediaFetch, nil, "failed to download image")), SendErrMediaFetch},
+		{"provider 429", ProviderStatusError("Wablas", 429, ""), SendErrRateLimited},
+		{"provider 503", ProviderStatusError("Wablas", 503, ""), SendErrProvider5xx},
+		{"provider 401", ProviderStatusError("Wablas", 401, ""), SendErrProvider4xx},
+		{"provider rejected number", ProviderRejectedError("Whacenter", "number not registered"), SendErrNotOnWhatsapp},
+		{"untyped timeout", errors.New("net/http: TLS handshake timeout"), SendErrNetwork},
+		{"untyped encryption", errors.New("can't encrypt message for device: no signal session established"), SendErrEncryption},
+		{"untyped eof", errors.New(`Post "https://api.example.com/send": EOF`), SendErrNetwork},
+		{"untyped rate limit status", errors.New("server responded with status 429"), SendErrRateLimited},
+		{"untyped media upload", errors.New("failed to upload image: context canceled"), SendErrMediaUpload},
+		{"untyped media download", errors.New("failed to download image: bad status: 404 Not Found"), SendErrMediaFetch},
+		{"untyped other", errors.New("something odd"), SendErrUnknown},
+		// Phone numbers, captions and file names in a message don't decide the class
+		{"phone with 429", errors.New("failed to send to 60124291234: something odd"), SendErrUnknown},
+		{"recipient before rate limit", errors.New("60124291234 is not on WhatsApp"), SendErrNotOnWhatsapp},
+		{"invalid recipient before other fragments", errors.New("invalid phone 60123 in media upload"), SendErrInvalidRecipient},
+		{"word media", errors.New("caption of image media.jpg rejected"), SendErrUnknown},
+		{"word eof", errors.New("geofence check rejected"), SendErrUnknown},
+		{"word upload", errors.New("uploaded contact card rejected"), SendErrUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, SendErrorCodeOf(tt.err))
+		})
+	}
+}
+
+func TestRetryPolicy(t *testing.T) {
+	network := RetryPolicyFor(SendErrNetwork)
+	assert.Equal(t, 2*time.Second, network.Backoff(1))
+	assert.Equal(t, 4*time.Second, network.Backoff(2))
+	assert.Equal(t, 8*time.Second, network.Backoff(3))
+	assert.True(t, network.ShouldRetry(2))
+	assert.False(t, network.ShouldRetry(3))
+
+	assert.Equal(t, 30*time.Minute, RetryPolicyFor(SendErrRateLimited).Backoff(10))
+	assert.False(t, RetryPolicyFor(SendErrNotOnWhatsapp).ShouldRetry(0))
+	assert.Equal(t, RetryPolicyFor(SendErrUnknown), RetryPolicyFor("SOMETHING_NEW"))
+}
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/antipattern"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
 	"github.com/sirupsen/logrus"
 )
 
@@ -39,7 +40,10 @@ func NewPlatformSender() *PlatformSender {
 	}
 }
 
-// retryWithBackoff retries a function with exponential backoff
+// maxInlineBackoff is the longest backoff retryWithBackoff sleeps through
+const maxInlineBackoff = 30 * time.Second
+
+// retryWithBackoff retries a function with the backoff of the error's retry policy
 func (ps *PlatformSender) retryWithBackoff(fn func() error, maxRetries int, platform string) error {
 	var lastErr error
 
@@ -62,22 +66,20 @@ func (ps *PlatformSender) retryWithBackoff(fn func() error, maxRetries int, plat
 			return lastErr
 		}
 
-		// Check if error is retryable
-		errStr := err.Error()
-		isRetryable := strings.Contains(errStr, "timeout") ||
-			strings.Contains(errStr, "TLS handshake") ||
-			strings.Contains(errStr, "EOF") ||
-			strings.Contains(errStr, "connection reset") ||
-			strings.Contains(errStr, "broken pipe")
-
-		if !isRetryable {
-			// Don't retry for non-network errors
-			logrus.Warnf("[%s] Non-retryable error: %v", platform, err)
+		// The error class decides whether and how soon to retry
+		code := pkgError.SendErrorCodeOf(err)
+		policy := pkgError.RetryPolicyFor(code)
+		if !policy.ShouldRetry(attempt) {
+			logrus.Warnf("[%s] Non-retryable error (%s): %v", platform, code, err)
 			return err
 		}
 
-		// Calculate backoff delay: 2^attempt seconds (2s, 4s, 8s)
-		backoffDelay := time.Duration(1<<uint(attempt)) * 2 * time.Second
+		backoffDelay := policy.Backoff(attempt + 1)
+		if backoffDelay > maxInlineBackoff {
+			// Long waits (rate limits, device outages) are left to the queue's retry
+			logrus.Warnf("[%s] %s error, deferring retry to the queue: %v", platform, code, err)
+			return err
+		}
 		logrus.Warnf("[%s] Attempt %d failed: %v. Retrying in %v...", platform, attempt+1, err, backoffDelay)
 		time.Sleep(backoffDelay)
 	}
@@ -112,6 +114,93 @@ func (ps *PlatformSender) SendMessage(platform, instance, phone, recipientName,
 	return nil
 }
 
+// RichMessage is a video, document, audio, location, poll or contact message
+// sent through an external platform
+type RichMessage struct {
+	Type     string
+	Caption  string
+	MediaURL string
+	FileName string
+}
+
+// SendRichMessage sends a non-text message via external platform. Platforms
+// only relay media files; other types are rejected as unsupported.
+func (ps *PlatformSender) SendRichMessage(platform, instance, phone string, msg RichMessage) error {
+	switch platform {
+	case "Wablas":
+		return ps.sendWablasMedia(instance, phone, msg)
+	case "Whacenter":
+		if msg.Type != "video" && msg.Type != "document" && msg.Type != "audio" {
+			return pkgError.NewSendError(pkgError.SendErrProvider4xx, nil, "whacenter does not support %s messages", msg.Type)
+		}
+		// Whacenter attaches any file URL to the message
+		return ps.retryWithBackoff(func() error {
+			return ps.sendViaWhacenter(instance, phone, msg.Caption, msg.MediaURL)
+		}, 3, "Whacenter")
+	default:
+		return fmt.Errorf("unknown platform: %s", platform)
+	}
+}
+
+// sendWablasMedia sends a video, document or audio file via Wablas
+func (ps *PlatformSender) sendWablasMedia(token, phone string, msg RichMessage) error {
+	data := url.Values{}
+	data.Set("phone", phone)
+
+	var apiURL string
+	switch msg.Type {
+	case "video":
+		apiURL = "https://my.wablas.com/api/send-video"
+		data.Set("video", msg.MediaURL)
+		data.Set("caption", formatWhatsAppMessage(msg.Caption))
+	case "document":
+		apiURL = "https://my.wablas.com/api/send-document"
+		data.Set("document", msg.MediaURL)
+		if msg.FileName != "" {
+			data.Set("filename", msg.FileName)
+		}
+	case "audio":
+		apiURL = "https://my.wablas.com/api/send-audio"
+		data.Set("audio", msg.MediaURL)
+	default:
+		return pkgError.NewSendError(pkgError.SendErrProvider4xx, nil, "wablas does not support %s messages", msg.Type)
+	}
+
+	req, err := http.NewRequest("POST", apiURL, strings.NewReader(data.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", token)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := ps.client.Do(req)
+	if err != nil {
+		return pkgError.NewSendError(pkgError.SendErrNetwork, err, "failed to send request")
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return pkgError.NewSendError(pkgError.SendErrNetwork, err, "failed to read response")
+	}
+	if resp.StatusCode >= 400 {
+		return pkgError.ProviderStatusError("Wablas", resp.StatusCode, truncateString(string(body), 200))
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return pkgError.NewSendError(pkgError.SendErrProvider5xx, err, "failed to parse response")
+	}
+	if status, ok := result["status"].(bool); ok && !status {
+		if message, ok := result["message"].(string); ok {
+			return pkgError.ProviderRejectedError("Wablas", message)
+		}
+		return pkgError.NewSendError(pkgError.SendErrProvider4xx, nil, "wablas returned false status")
+	}
+
+	return nil
+}
+
 // sendViaWablas sends message via Wablas API
 func (ps *PlatformSender) sendViaWablas(token, phone, message, imageURL string) error {
 	if imageURL != "" {
@@ -146,28 +235,31 @@ func (ps *PlatformSender) sendWablasText(token, phone, message string) error {
 	// Send request
 	resp, err := ps.client.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
+		return pkgError.NewSendError(pkgError.SendErrNetwork, err, "failed to send request")
 	}
 	defer resp.Body.Close()
 	
 	// Read response
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("failed to read response: %w", err)
+		return pkgError.NewSendError(pkgError.SendErrNetwork, err, "failed to read response")
+	}
+	if resp.StatusCode >= 400 {
+		return pkgError.ProviderStatusError("Wablas", resp.StatusCode, truncateString(string(body), 200))
 	}
 	
 	// Parse response
 	var result map[string]interface{}
 	if err := json.Unmarshal(body, &result); err != nil {
-		return fmt.Errorf("failed to parse response: %w", err)
+		return pkgError.NewSendError(pkgError.SendErrProvider5xx, err, "failed to parse response")
 	}
 	
 	// Check status
 	if status, ok := result["status"].(bool); ok && !status {
 		if msg, ok := result["message"].(string); ok {
-			return fmt.Errorf("wablas error: %s", msg)
+			return pkgError.ProviderRejectedError("Wablas", msg)
 		}
-		return fmt.Errorf("wablas returned false status: %s", string(body))
+		return pkgError.NewSendError(pkgError.SendErrProvider4xx, nil, "wablas returned false status: %s", string(body))
 	}
 	
 	return nil
@@ -198,28 +290,31 @@ func (ps *PlatformSender) sendWablasImage(token, phone, caption, imageURL string
 	// Send request
 	resp, err := ps.client.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
+		return pkgError.NewSendError(pkgError.SendErrNetwork, err, "failed to send request")
 	}
 	defer resp.Body.Close()
 	
 	// Read response
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("failed to read response: %w", err)
+		return pkgError.NewSendError(pkgError.SendErrNetwork, err, "failed to read response")
+	}
+	if resp.StatusCode >= 400 {
+		return pkgError.ProviderStatusError("Wablas", resp.StatusCode, truncateString(string(body), 200))
 	}
 	
 	// Parse response
 	var result map[string]interface{}
 	if err := json.Unmarshal(body, &result); err != nil {
-		return fmt.Errorf("failed to parse response: %w", err)
+		return pkgError.NewSendError(pkgError.SendErrProvider5xx, err, "failed to parse response")
 	}
 	
 	// Check status
 	if status, ok := result["status"].(bool); ok && !status {
 		if msg, ok := result["message"].(string); ok {
-			return fmt.Errorf("wablas error: %s", msg)
+			return pkgError.ProviderRejectedError("Wablas", msg)
 		}
-		return fmt.Errorf("wablas returned false status")
+		return pkgError.NewSendError(pkgError.SendErrProvider4xx, nil, "wablas returned false status")
 	}
 	
 	return nil
@@ -268,14 +363,17 @@ func (ps *PlatformSender) sendViaWhacenter(deviceID, phone, message, imageURL st
 	// Send request
 	resp, err := ps.client.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
+		return pkgError.NewSendError(pkgError.SendErrNetwork, err, "failed to send request")
 	}
 	defer resp.Body.Close()
 	
 	// Read response
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("failed to read response: %w", err)
+		return pkgError.NewSendError(pkgError.SendErrNetwork, err, "failed to read response")
+	}
+	if resp.StatusCode >= 400 {
+		return pkgError.ProviderStatusError("Whacenter", resp.StatusCode, truncateString(string(respBody), 200))
 	}
 	
 	// Log the raw response for debugging
@@ -284,7 +382,7 @@ func (ps *PlatformSender) sendViaWhacenter(deviceID, phone, message, imageURL st
 	// Parse response
 	var result map[string]interface{}
 	if err := json.Unmarshal(respBody, &result); err != nil {
-		return fmt.Errorf("failed to parse response: %w, body: %s", err, string(respBody))
+		return pkgError.NewSendError(pkgError.SendErrProvider5xx, err, "failed to parse response, body: %s", string(respBody))
 	}
 	
 	// Log parsed response
@@ -294,10 +392,10 @@ func (ps *PlatformSender) sendViaWhacenter(deviceID, phone, message, imageURL st
 	if status, ok := result["status"].(bool); ok && !status {
 		if msg, ok := result["msg"].(string); ok {
 			logrus.Errorf("WhatsCenter error - status: false, msg: %s, full response: %+v", msg, result)
-			return fmt.Errorf("whacenter error: %s", msg)
+			return pkgError.ProviderRejectedError("Whacenter", msg)
 		}
 		logrus.Errorf("WhatsCenter error - status: false, no msg field, full response: %+v", result)
-		return fmt.Errorf("whacenter returned false status, response: %+v", result)
+		return pkgError.NewSendError(pkgError.SendErrProvider4xx, nil, "whacenter returned false status, response: %+v", result)
 	}
 	
 	return nil
@@ -0,0 +1,236 @@
+// Package fulltext builds full-text search over a text column with the
+// native index of the database engine in use: a MySQL FULLTEXT index, a
+// Postgres tsvector GIN index or an SQLite FTS5 table. Queries are written
+// with ? placeholders and rebound for engines that number them.
+package fulltext
+
+import (
+	"database/sql"
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+)
+
+// Dialect is a database engine
+type Dialect string
+
+// Supported dialects
+const (
+	MySQL    Dialect = "mysql"
+	Postgres Dialect = "postgres"
+	SQLite   Dialect = "sqlite"
+)
+
+// MaxTerms caps the words of a search; the rest are ignored
+const MaxTerms = 10
+
+// DialectOf works out the engine behind a connection from its driver
+func DialectOf(db *sql.DB) Dialect {
+	driver := strings.ToLower(fmt.Sprintf("%T", db.Driver()))
+	switch {
+	case strings.Contains(driver, "sqlite"):
+		return SQLite
+	case strings.Contains(driver, "pq."), strings.Contains(driver, "pgx"):
+		return Postgres
+	}
+	return MySQL
+}
+
+// Rebind rewrites ? placeholders for the dialect. Postgres numbers them;
+// question marks inside quoted literals are left alone.
+func Rebind(d Dialect, query string) string {
+	if d != Postgres {
+		return query
+	}
+	var out strings.Builder
+	n, quoted := 0, false
+	for _, r := range query {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+		case r == '?' && !quoted:
+			n++
+			fmt.Fprintf(&out, "$%d", n)
+			continue
+		}
+		out.WriteRune(r)
+	}
+	return out.String()
+}
+
+// Terms splits a search into words. Anything that is not a letter or digit
+// separates words, so no engine's query operators survive.
+func Terms(search string) []string {
+	terms := strings.FieldsFunc(search, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(terms) > MaxTerms {
+		terms = terms[:MaxTerms]
+	}
+	return terms
+}
+
+// Index is a full-text index over one text column of a table with an
+// integer primary key
+type Index struct {
+	Table  string
+	Column string
+	Key    string // Integer primary key, used as the FTS5 rowid
+}
+
+// name is the index name, and for SQLite the FTS5 table
+func (ix Index) name() string {
+	return ix.Table + "_fts"
+}
+
+// Ensure creates the index when it does not exist. SQLite keeps an external
+// content FTS5 table in step with the table through triggers.
+func (ix Index) Ensure(db *sql.DB, d Dialect) error {
+	switch d {
+	case Postgres:
+		_, err := db.Exec(fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s ON %s USING GIN (%s)`,
+			ix.name(), ix.Table, ix.vector("")))
+		return err
+
+	case SQLite:
+		var exists int
+		err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, ix.name()).Scan(&exists)
+		if err != nil || exists > 0 {
+			return err
+		}
+		fts, t, col, key := ix.name(), ix.Table, ix.Column, ix.Key
+		statements := []string{
+			fmt.Sprintf(`CREATE VIRTUAL TABLE %s USING fts5(%s, content='%s', content_rowid='%s')`, fts, col, t, key),
+			fmt.Sprintf(`CREATE TRIGGER %s_ai AFTER INSERT ON %s BEGIN
+				INSERT INTO %s(rowid, %s) VALUES (new.%s, new.%s); END`, fts, t, fts, col, key, col),
+			fmt.Sprintf(`CREATE TRIGGER %s_ad AFTER DELETE ON %s BEGIN
+				INSERT INTO %s(%s, rowid, %s) VALUES ('delete', old.%s, old.%s); END`, fts, t, fts, fts, col, key, col),
+			fmt.Sprintf(`CREATE TRIGGER %s_au AFTER UPDATE OF %s ON %s BEGIN
+				INSERT INTO %s(%s, rowid, %s) VALUES ('delete', old.%s, old.%s);
+				INSERT INTO %s(rowid, %s) VALUES (new.%s, new.%s); END`, fts, col, t, fts, fts, col, key, col, fts, col, key, col),
+			fmt.Sprintf(`INSERT INTO %s(%s) VALUES ('rebuild')`, fts, fts),
+		}
+		for _, statement := range statements {
+			if _, err := db.Exec(statement); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var exists int
+	err := db.QueryRow(`
+		SELECT COUNT(*) FROM information_schema.statistics
+		WHERE table_schema = DATABASE() AND table_name = ? AND index_name = ?
+	`, ix.Table, ix.name()).Scan(&exists)
+	if err != nil || exists > 0 {
+		return err
+	}
+	_, err = db.Exec(fmt.Sprintf(`ALTER TABLE %s ADD FULLTEXT INDEX %s (%s)`, ix.Table, ix.name(), ix.Column))
+	return err
+}
+
+// vector is the Postgres tsvector expression the GIN index is built on;
+// queries must repeat it exactly for the index to be used
+func (ix Index) vector(alias string) string {
+	column := ix.Column
+	if alias != "" {
+		column = alias + "." + column
+	}
+	return fmt.Sprintf("to_tsvector('simple', COALESCE(%s, ''))", column)
+}
+
+// Match returns a condition, with one ? placeholder and its argument, that
+// holds for rows of the table (aliased as alias) whose column has words
+// starting with every term
+func (ix Index) Match(d Dialect, alias string, terms []string) (string, any) {
+	switch d {
+	case Postgres:
+		words := make([]string, len(terms))
+		for i, term := range terms {
+			words[i] = strings.ToLower(term) + ":*"
+		}
+		return fmt.Sprintf("%s @@ to_tsquery('simple', ?)", ix.vector(alias)), strings.Join(words, " & ")
+
+	case SQLite:
+		words := make([]string, len(terms))
+		for i, term := range terms {
+			words[i] = `"` + term + `"*`
+		}
+		return fmt.Sprintf("%s.%s IN (SELECT rowid FROM %s WHERE %s MATCH ?)", alias, ix.Key, ix.name(), ix.name()),
+			strings.Join(words, " ")
+	}
+
+	words := make([]string, len(terms))
+	for i, term := range terms {
+		words[i] = "+" + term + "*"
+	}
+	return fmt.Sprintf("MATCH(%s.%s) AGAINST (? IN BOOLEAN MODE)", alias, ix.Column), strings.Join(words, " ")
+}
+
+// Highlight returns an HTML snippet of text around the first term found,
+// at most width characters long, with every term occurrence wrapped in
+// <mark>. Text without any term is cut to width from its start.
+func Highlight(text string, terms []string, width int) string {
+	runes := []rune(text)
+	lower := []rune(strings.ToLower(text))
+	if len(lower) != len(runes) {
+		// Lower-casing changed the length; fall back to exact-case matching
+		lower = runes
+	}
+
+	marked := make([]bool, len(runes))
+	first := -1
+	for _, term := range terms {
+		needle := []rune(strings.ToLower(term))
+		if len(needle) == 0 {
+			continue
+		}
+		for i := 0; i+len(needle) <= len(lower); i++ {
+			if string(lower[i:i+len(needle)]) != string(needle) {
+				continue
+			}
+			for j := i; j < i+len(needle); j++ {
+				marked[j] = true
+			}
+			if first < 0 || i < first {
+				first = i
+			}
+		}
+	}
+
+	start, end := 0, len(runes)
+	if width > 0 && len(runes) > width {
+		if first > width/3 {
+			start = first - width/3
+		}
+		end = start + width
+		if end > len(runes) {
+			end, start = len(runes), len(runes)-width
+		}
+	}
+
+	var out strings.Builder
+	if start > 0 {
+		out.WriteString("…")
+	}
+	open := false
+	for i := start; i < end; i++ {
+		if marked[i] && !open {
+			out.WriteString("<mark>")
+			open = true
+		} else if !marked[i] && open {
+			out.WriteString("</mark>")
+			open = false
+		}
+		out.WriteString(html.EscapeString(string(runes[i])))
+	}
+	if open {
+		out.WriteString("</mark>")
+	}
+	if end < len(runes) {
+		out.WriteString("…")
+	}
+	return out.String()
+}
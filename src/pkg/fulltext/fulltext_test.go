@@ -0,0 +1,63 @@
+package fulltext
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRebind(t *testing.T) {
+	query := "SELECT * FROM t WHERE a = ? AND b = '?' AND c IN (?, ?)"
+	assert.Equal(t, query, Rebind(MySQL, query))
+	assert.Equal(t, "SELECT * FROM t WHERE a = $1 AND b = '?' AND c IN ($2, $3)", Rebind(Postgres, query))
+}
+
+func TestTerms(t *testing.T) {
+	assert.Equal(t, []string{"price", "list", "2025"}, Terms(`+price -"list" 2025*`))
+	assert.Equal(t, []string{"harga", "murah"}, Terms("harga, murah!"))
+	assert.Empty(t, Terms(` "*" `))
+	assert.Len(t, Terms("a b c d e f g h i j k l"), MaxTerms)
+}
+
+func TestMatch(t *testing.T) {
+	ix := Index{Table: "whatsapp_messages", Column: "message_text", Key: "id"}
+	terms := []string{"Price", "list"}
+
+	tests := []struct {
+		dialect Dialect
+		cond    string
+		arg     any
+	}{
+		{MySQL, "MATCH(m.message_text) AGAINST (? IN BOOLEAN MODE)", "+Price* +list*"},
+		{Postgres, "to_tsvector('simple', COALESCE(m.message_text, '')) @@ to_tsquery('simple', ?)", "price:* & list:*"},
+		{SQLite, "m.id IN (SELECT rowid FROM whatsapp_messages_fts WHERE whatsapp_messages_fts MATCH ?)", `"Price"* "list"*`},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.dialect), func(t *testing.T) {
+			cond, arg := ix.Match(tt.dialect, "m", terms)
+			assert.Equal(t, tt.cond, cond)
+			assert.Equal(t, tt.arg, arg)
+		})
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		terms []string
+		width int
+		want  string
+	}{
+		{"marks every occurrence", "Price list and price", []string{"price"}, 0, "<mark>Price</mark> list and <mark>price</mark>"},
+		{"escapes html", "<b>promo</b> now", []string{"promo"}, 0, "&lt;b&gt;<mark>promo</mark>&lt;/b&gt; now"},
+		{"adjacent terms merge", "pricelist", []string{"price", "list"}, 0, "<mark>pricelist</mark>"},
+		{"snippet around match", "one two three four five six seven", []string{"six"}, 12, "…ive <mark>six</mark> seve…"},
+		{"no match keeps start", "one two three", []string{"zzz"}, 7, "one two…"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Highlight(tt.text, tt.terms, tt.width))
+		})
+	}
+}
@@ -0,0 +1,285 @@
+// Package listing parses the pagination, sorting, filter and search
+// parameters of list endpoints. SQL lists page with a keyset cursor; lists
+// held in memory page with an offset cursor. Cursors are opaque to clients.
+package listing
+
+import (
+	"cmp"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+)
+
+// Page sizes
+const (
+	DefaultLimit = 100
+	MaxLimit     = 1000
+)
+
+// ErrInvalid wraps every error Parse returns
+var ErrInvalid = errors.New("invalid list query")
+
+// Fields describes what a list endpoint can be sorted, filtered and searched by.
+// Field names are the JSON names clients use.
+type Fields struct {
+	Columns     map[string]string // SQL column or expression of each field; nil for lists held in memory
+	Sort        []string          // Fields accepted by the sort parameter
+	Filter      []string          // Fields accepted as exact-match parameters; a comma matches any of several values
+	Search      []string          // Fields the q parameter searches
+	Key         string            // Unique field that breaks ties in the sort
+	DefaultSort string            // Sort when none is given, e.g. "-created_at"
+}
+
+// Query is a parsed list request
+type Query struct {
+	Limit   int
+	Sort    string // Field to sort by
+	Desc    bool
+	Search  string
+	Filters map[string][]string
+
+	fields Fields
+	after  *cursor
+}
+
+// cursor is where the previous page ended. SQL lists record the sort value
+// and key of its last row; lists held in memory record an offset.
+type cursor struct {
+	Sort   string `json:"s"`
+	Value  string `json:"v,omitempty"`
+	Key    string `json:"k,omitempty"`
+	Offset int    `json:"o,omitempty"`
+}
+
+// Parse reads limit, cursor, sort (prefix "-" for descending), q and the
+// filter fields from the query parameters of a request
+func Parse(args map[string]string, fields Fields) (Query, error) {
+	q := Query{Limit: DefaultLimit, Filters: map[string][]string{}, fields: fields}
+
+	if raw := args["limit"]; raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > MaxLimit {
+			return q, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalid, MaxLimit)
+		}
+		q.Limit = limit
+	}
+
+	sort := args["sort"]
+	if sort == "" {
+		sort = fields.DefaultSort
+	}
+	q.Sort, q.Desc = strings.TrimPrefix(sort, "-"), strings.HasPrefix(sort, "-")
+	if !slices.Contains(fields.Sort, q.Sort) && q.Sort != fields.Key {
+		return q, fmt.Errorf("%w: cannot sort by %q", ErrInvalid, q.Sort)
+	}
+
+	if raw := args["cursor"]; raw != "" {
+		data, err := base64.RawURLEncoding.DecodeString(raw)
+		var after cursor
+		if err == nil {
+			err = json.Unmarshal(data, &after)
+		}
+		if err != nil {
+			return q, fmt.Errorf("%w: malformed cursor", ErrInvalid)
+		}
+		if after.Sort != sort {
+			return q, fmt.Errorf("%w: cursor belongs to another sort order", ErrInvalid)
+		}
+		q.after = &after
+	}
+
+	if len(fields.Search) > 0 {
+		q.Search = strings.TrimSpace(args["q"])
+	}
+	for _, field := range fields.Filter {
+		if raw := args[field]; raw != "" {
+			q.Filters[field] = strings.Split(raw, ",")
+		}
+	}
+	return q, nil
+}
+
+// sortParam is the sort parameter the query was parsed from
+func (q Query) sortParam() string {
+	if q.Desc {
+		return "-" + q.Sort
+	}
+	return q.Sort
+}
+
+// Where returns the filter and search conditions, each prefixed with AND,
+// for both the page and its count
+func (q Query) Where() (string, []any) {
+	var where strings.Builder
+	var args []any
+
+	for _, field := range q.fields.Filter {
+		values := q.Filters[field]
+		if len(values) == 0 {
+			continue
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ")
+		fmt.Fprintf(&where, " AND %s IN (%s)", q.fields.Columns[field], placeholders)
+		for _, value := range values {
+			args = append(args, value)
+		}
+	}
+
+	if q.Search != "" {
+		terms := make([]string, len(q.fields.Search))
+		for i, field := range q.fields.Search {
+			terms[i] = q.fields.Columns[field] + " LIKE ?"
+			args = append(args, "%"+q.Search+"%")
+		}
+		fmt.Fprintf(&where, " AND (%s)", strings.Join(terms, " OR "))
+	}
+	return where.String(), args
+}
+
+// Seek returns Where plus the cursor position, followed by the ORDER BY and
+// LIMIT clauses. It fetches one row more than the page so Trim can tell
+// whether another page follows.
+func (q Query) Seek() (string, []any) {
+	where, args := q.Where()
+	sortColumn, keyColumn := q.fields.Columns[q.Sort], q.fields.Columns[q.fields.Key]
+	op, dir := ">", "ASC"
+	if q.Desc {
+		op, dir = "<", "DESC"
+	}
+
+	if q.after != nil {
+		if q.Sort == q.fields.Key {
+			where += fmt.Sprintf(" AND %s %s ?", keyColumn, op)
+			args = append(args, q.after.Key)
+		} else {
+			where += fmt.Sprintf(" AND (%s %s ? OR (%s = ? AND %s %s ?))", sortColumn, op, sortColumn, keyColumn, op)
+			args = append(args, q.after.Value, q.after.Value, q.after.Key)
+		}
+	}
+
+	order := fmt.Sprintf(" ORDER BY %s %s", keyColumn, dir)
+	if q.Sort != q.fields.Key {
+		order = fmt.Sprintf(" ORDER BY %s %s, %s %s", sortColumn, dir, keyColumn, dir)
+	}
+	return where + order + fmt.Sprintf(" LIMIT %d", q.Limit+1), args
+}
+
+// Trim cuts rows fetched with Seek down to the page and describes the page.
+// value returns a row's value of a field; it is read for the sort field and
+// the key of the last row.
+func Trim[T any](rows []T, q Query, total int, value func(row T, field string) any) ([]T, utils.Page) {
+	page := utils.Page{Limit: q.Limit, Total: total}
+	if len(rows) > q.Limit {
+		rows = rows[:q.Limit]
+		last := rows[len(rows)-1]
+		page.HasMore = true
+		page.NextCursor = encode(cursor{
+			Sort:  q.sortParam(),
+			Value: format(value(last, q.Sort)),
+			Key:   format(value(last, q.fields.Key)),
+		})
+	}
+	if rows == nil {
+		rows = []T{}
+	}
+	return rows, page
+}
+
+// Slice filters, searches, sorts and pages a list held in memory
+func Slice[T any](items []T, q Query, value func(item T, field string) any) ([]T, utils.Page) {
+	matched := []T{}
+	for _, item := range items {
+		if matches(item, q, value) {
+			matched = append(matched, item)
+		}
+	}
+	slices.SortStableFunc(matched, func(a, b T) int {
+		c := compare(value(a, q.Sort), value(b, q.Sort))
+		if q.Desc {
+			return -c
+		}
+		return c
+	})
+
+	offset := 0
+	if q.after != nil {
+		offset = min(q.after.Offset, len(matched))
+	}
+	end := min(offset+q.Limit, len(matched))
+	page := utils.Page{Limit: q.Limit, Total: len(matched), HasMore: end < len(matched)}
+	if page.HasMore {
+		page.NextCursor = encode(cursor{Sort: q.sortParam(), Offset: end})
+	}
+	return matched[offset:end], page
+}
+
+// matches applies the filters and search of a query to an in-memory item
+func matches[T any](item T, q Query, value func(item T, field string) any) bool {
+	for field, values := range q.Filters {
+		if !slices.Contains(values, format(value(item, field))) {
+			return false
+		}
+	}
+	if q.Search == "" {
+		return true
+	}
+	search := strings.ToLower(q.Search)
+	for _, field := range q.fields.Search {
+		if strings.Contains(strings.ToLower(format(value(item, field))), search) {
+			return true
+		}
+	}
+	return false
+}
+
+func encode(c cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// format renders a value the way SQL compares it with the column it came from
+func format(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		return v.Format("2006-01-02 15:04:05.999999")
+	case *time.Time:
+		if v == nil {
+			return ""
+		}
+		return format(*v)
+	case *string:
+		if v == nil {
+			return ""
+		}
+		return *v
+	}
+	return fmt.Sprint(v)
+}
+
+// compare orders two values of the same field of in-memory items
+func compare(a, b any) int {
+	switch a := a.(type) {
+	case int:
+		if b, ok := b.(int); ok {
+			return cmp.Compare(a, b)
+		}
+	case int64:
+		if b, ok := b.(int64); ok {
+			return cmp.Compare(a, b)
+		}
+	case time.Time:
+		if b, ok := b.(time.Time); ok {
+			return a.Compare(b)
+		}
+	}
+	return strings.Compare(strings.ToLower(format(a)), strings.ToLower(format(b)))
+}
@@ -0,0 +1,157 @@
+package listing
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type lead struct {
+	ID        string
+	Name      string
+	Status    string
+	CreatedAt string
+}
+
+func leadValue(l lead, field string) any {
+	switch field {
+	case "id":
+		return l.ID
+	case "name":
+		return l.Name
+	case "status":
+		return l.Status
+	}
+	return l.CreatedAt
+}
+
+var leadFields = Fields{
+	Columns:     map[string]string{"id": "id", "name": "name", "status": "status", "created_at": "created_at"},
+	Sort:        []string{"name", "created_at"},
+	Filter:      []string{"status"},
+	Search:      []string{"name"},
+	Key:         "id",
+	DefaultSort: "-created_at",
+}
+
+func TestParseRejectsBadQueries(t *testing.T) {
+	tests := []struct {
+		name string
+		args map[string]string
+		err  string
+	}{
+		{name: "limit not a number", args: map[string]string{"limit": "ten"}, err: "limit must be between 1 and 1000"},
+		{name: "limit too large", args: map[string]string{"limit": "5000"}, err: "limit must be between 1 and 1000"},
+		{name: "unknown sort field", args: map[string]string{"sort": "phone"}, err: `cannot sort by "phone"`},
+		{name: "malformed cursor", args: map[string]string{"cursor": "%%%"}, err: "malformed cursor"},
+		{name: "cursor of another sort", args: map[string]string{"sort": "name", "cursor": encode(cursor{Sort: "-created_at"})}, err: "another sort order"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.args, leadFields)
+			require.ErrorIs(t, err, ErrInvalid)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+// TestSeekPagesThroughTies walks a table sorted by a column with repeated
+// values and checks every row is seen exactly once
+func TestSeekPagesThroughTies(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec(`CREATE TABLE leads (id TEXT PRIMARY KEY, name TEXT, status TEXT, created_at TEXT)`)
+	require.NoError(t, err)
+	for i := 0; i < 25; i++ {
+		status := "prospect"
+		if i%5 == 0 {
+			status = "customer"
+		}
+		_, err = db.Exec(`INSERT INTO leads VALUES (?, ?, ?, ?)`,
+			fmt.Sprintf("lead-%02d", i), fmt.Sprintf("Ali %d", i), status, fmt.Sprintf("2025-01-0%d 09:00:00", i%3+1))
+		require.NoError(t, err)
+	}
+
+	list := func(args map[string]string) ([]lead, string, int) {
+		q, err := Parse(args, leadFields)
+		require.NoError(t, err)
+		where, whereArgs := q.Where()
+		var total int
+		require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM leads WHERE 1 = 1`+where, whereArgs...).Scan(&total))
+
+		seek, seekArgs := q.Seek()
+		rows, err := db.Query(`SELECT id, name, status, created_at FROM leads WHERE 1 = 1`+seek, seekArgs...)
+		require.NoError(t, err)
+		defer rows.Close()
+		var leads []lead
+		for rows.Next() {
+			var l lead
+			require.NoError(t, rows.Scan(&l.ID, &l.Name, &l.Status, &l.CreatedAt))
+			leads = append(leads, l)
+		}
+		leads, page := Trim(leads, q, total, leadValue)
+		assert.Equal(t, page.NextCursor != "", page.HasMore)
+		return leads, page.NextCursor, page.Total
+	}
+
+	seen := map[string]bool{}
+	args := map[string]string{"limit": "4"}
+	previous := "9999"
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 10, "paging does not end")
+		leads, next, total := list(args)
+		assert.Equal(t, 25, total)
+		for _, l := range leads {
+			assert.False(t, seen[l.ID], "%s seen twice", l.ID)
+			assert.LessOrEqual(t, l.CreatedAt, previous)
+			seen[l.ID], previous = true, l.CreatedAt
+		}
+		if next == "" {
+			break
+		}
+		args = map[string]string{"limit": "4", "cursor": next}
+	}
+	assert.Len(t, seen, 25)
+
+	customers, next, total := list(map[string]string{"status": "customer", "q": "ali 1", "sort": "name"})
+	assert.Equal(t, []lead{
+		{ID: "lead-10", Name: "Ali 10", Status: "customer", CreatedAt: "2025-01-02 09:00:00"},
+		{ID: "lead-15", Name: "Ali 15", Status: "customer", CreatedAt: "2025-01-01 09:00:00"},
+	}, customers)
+	assert.Empty(t, next)
+	assert.Equal(t, 2, total)
+}
+
+func TestSlice(t *testing.T) {
+	chats := []lead{
+		{ID: "a", Name: "Siti", Status: "prospect", CreatedAt: "2025-01-03"},
+		{ID: "b", Name: "Ahmad", Status: "customer", CreatedAt: "2025-01-01"},
+		{ID: "c", Name: "Aminah", Status: "prospect", CreatedAt: "2025-01-02"},
+	}
+
+	q, err := Parse(map[string]string{"limit": "2", "sort": "name"}, leadFields)
+	require.NoError(t, err)
+	page1, info := Slice(chats, q, leadValue)
+	assert.Equal(t, []string{"b", "c"}, []string{page1[0].ID, page1[1].ID})
+	assert.True(t, info.HasMore)
+	assert.Equal(t, 3, info.Total)
+
+	q, err = Parse(map[string]string{"limit": "2", "sort": "name", "cursor": info.NextCursor}, leadFields)
+	require.NoError(t, err)
+	page2, info := Slice(chats, q, leadValue)
+	assert.Equal(t, "a", page2[0].ID)
+	assert.False(t, info.HasMore)
+
+	q, err = Parse(map[string]string{"status": "prospect", "q": "AMI"}, leadFields)
+	require.NoError(t, err)
+	found, info := Slice(chats, q, leadValue)
+	assert.Len(t, found, 1)
+	assert.Equal(t, "c", found[0].ID)
+	assert.Equal(t, 1, info.Total)
+}
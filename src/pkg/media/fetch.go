@@ -0,0 +1,196 @@
+package media
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var (
+	// ErrBlockedAddress is returned when a media URL resolves to a private,
+	// loopback, link-local or otherwise non-public address
+	ErrBlockedAddress = errors.New("media URL resolves to a blocked address")
+	// ErrUnsupportedScheme is returned for URLs that are not http, https or data
+	ErrUnsupportedScheme = errors.New("media URL must be http, https or data")
+	// ErrTooLarge is returned when media exceeds the fetcher's size limit
+	ErrTooLarge = errors.New("media exceeds the maximum download size")
+)
+
+const maxRedirects = 5
+
+// blockedNetworks are special-purpose ranges not covered by the net.IP helpers
+var blockedNetworks = mustParseCIDRs(
+	"0.0.0.0/8",       // "this" network
+	"100.64.0.0/10",   // carrier-grade NAT
+	"192.0.0.0/24",    // IETF protocol assignments
+	"192.0.2.0/24",    // documentation
+	"198.18.0.0/15",   // benchmarking
+	"198.51.100.0/24", // documentation
+	"203.0.113.0/24",  // documentation
+	"240.0.0.0/4",     // reserved
+	"64:ff9b::/96",    // NAT64, may map onto private IPv4
+	"2001:db8::/32",   // documentation
+)
+
+// Fetcher downloads media with size and time limits. Addresses are checked
+// when the connection is dialled, after DNS resolution, so neither redirects
+// nor DNS rebinding can reach internal services.
+type Fetcher struct {
+	maxSize      int64
+	client       *http.Client
+	allowPrivate bool
+}
+
+// NewFetcher creates a fetcher that gives up after timeout and rejects media larger than maxSize bytes
+func NewFetcher(timeout time.Duration, maxSize int64) *Fetcher {
+	f := &Fetcher{maxSize: maxSize}
+	f.client = newPublicClient(timeout, func() bool { return f.allowPrivate })
+	return f
+}
+
+// NewPublicClient creates an HTTP client that, like a Fetcher, only
+// connects to public addresses. Use it for any user-supplied URL, such as
+// a webhook.
+func NewPublicClient(timeout time.Duration) *http.Client {
+	return newPublicClient(timeout, func() bool { return false })
+}
+
+// newPublicClient checks every dialled address unless allowPrivate reports true
+func newPublicClient(timeout time.Duration, allowPrivate func() bool) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			if allowPrivate() {
+				return nil
+			}
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !IsPublicIP(ip) {
+				return fmt.Errorf("%w: %s", ErrBlockedAddress, host)
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy:                 nil, // a proxy would dial on our behalf and bypass the address check
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ResponseHeaderTimeout: timeout,
+			MaxIdleConnsPerHost:   4,
+			IdleConnTimeout:       90 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return ErrUnsupportedScheme
+			}
+			return nil
+		},
+	}
+}
+
+// Fetch downloads media from an http(s) or data URL and returns the content
+// with the content type reported by the source, if any
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) ([]byte, string, error) {
+	if strings.HasPrefix(rawURL, "data:") {
+		return f.decodeDataURL(rawURL)
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid media URL: %w", err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return nil, "", ErrUnsupportedScheme
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, parsed.String(), nil)
+	if err != nil {
+		return nil, "", err
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("bad status: %s", resp.Status)
+	}
+	if f.maxSize > 0 && resp.ContentLength > f.maxSize {
+		return nil, "", fmt.Errorf("%w: %d bytes", ErrTooLarge, resp.ContentLength)
+	}
+
+	reader := io.Reader(resp.Body)
+	if f.maxSize > 0 {
+		reader = io.LimitReader(resp.Body, f.maxSize+1)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, "", err
+	}
+	if f.maxSize > 0 && int64(len(data)) > f.maxSize {
+		return nil, "", ErrTooLarge
+	}
+	return data, resp.Header.Get("Content-Type"), nil
+}
+
+// decodeDataURL decodes a base64 data URL, e.g. data:image/png;base64,...
+func (f *Fetcher) decodeDataURL(rawURL string) ([]byte, string, error) {
+	parts := strings.SplitN(strings.TrimPrefix(rawURL, "data:"), ",", 2)
+	if len(parts) != 2 {
+		return nil, "", fmt.Errorf("invalid data URL format")
+	}
+	if f.maxSize > 0 && int64(base64.StdEncoding.DecodedLen(len(parts[1]))) > f.maxSize+2 {
+		return nil, "", ErrTooLarge
+	}
+
+	data, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decode base64: %v", err)
+	}
+	if f.maxSize > 0 && int64(len(data)) > f.maxSize {
+		return nil, "", ErrTooLarge
+	}
+	return data, strings.TrimSuffix(parts[0], ";base64"), nil
+}
+
+// IsPublicIP reports whether an address is publicly routable
+func IsPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return false
+	}
+	for _, network := range blockedNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
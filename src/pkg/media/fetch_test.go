@@ -0,0 +1,94 @@
+package media
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsPublicIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"8.8.8.8", true},
+		{"2606:4700:4700::1111", true},
+		{"127.0.0.1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"::1", false},
+		{"fd00::1", false},
+		{"::ffff:127.0.0.1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsPublicIP(net.ParseIP(tt.ip)))
+		})
+	}
+}
+
+func TestFetcher(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/small":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte("small"))
+		case "/large":
+			w.Write([]byte(strings.Repeat("x", 2048)))
+		case "/redirect":
+			http.Redirect(w, r, "/small", http.StatusFound)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	t.Run("blocks loopback", func(t *testing.T) {
+		_, _, err := NewFetcher(5*time.Second, 1024).Fetch(ctx, server.URL+"/small")
+		assert.ErrorIs(t, err, ErrBlockedAddress)
+	})
+
+	t.Run("public client blocks loopback", func(t *testing.T) {
+		_, err := NewPublicClient(5*time.Second).Post(server.URL+"/small", "application/json", nil)
+		assert.ErrorIs(t, err, ErrBlockedAddress)
+	})
+
+	t.Run("rejects other schemes", func(t *testing.T) {
+		_, _, err := NewFetcher(5*time.Second, 1024).Fetch(ctx, "file:///etc/passwd")
+		assert.ErrorIs(t, err, ErrUnsupportedScheme)
+	})
+
+	fetcher := NewFetcher(5*time.Second, 1024)
+	fetcher.allowPrivate = true
+
+	t.Run("downloads and follows redirects", func(t *testing.T) {
+		data, contentType, err := fetcher.Fetch(ctx, server.URL+"/redirect")
+		require.NoError(t, err)
+		assert.Equal(t, "small", string(data))
+		assert.Equal(t, "image/png", contentType)
+	})
+
+	t.Run("enforces the size limit", func(t *testing.T) {
+		_, _, err := fetcher.Fetch(ctx, server.URL+"/large")
+		assert.ErrorIs(t, err, ErrTooLarge)
+	})
+
+	t.Run("decodes data URLs", func(t *testing.T) {
+		data, contentType, err := fetcher.Fetch(ctx, "data:text/plain;base64,aGVsbG8=")
+		require.NoError(t, err)
+		assert.Equal(t, "hello", string(data))
+		assert.Equal(t, "text/plain", contentType)
+	})
+}
@@ -0,0 +1,73 @@
+package media
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/disintegration/imaging"
+	_ "golang.org/x/image/webp" // register the webp decoder for image.DecodeConfig
+)
+
+// ThumbnailSize is the longest side, in pixels, of generated thumbnails
+const ThumbnailSize = 100
+
+// Info describes a piece of media content
+type Info struct {
+	SHA256   string
+	MimeType string
+	Size     int64
+	Width    int
+	Height   int
+}
+
+// Inspect hashes the content and works out its mimetype and, for images,
+// its dimensions. declaredType is the type reported by the source and is
+// only used when sniffing the content is inconclusive.
+func Inspect(data []byte, declaredType string) Info {
+	sum := sha256.Sum256(data)
+	info := Info{
+		SHA256:   hex.EncodeToString(sum[:]),
+		MimeType: http.DetectContentType(data),
+		Size:     int64(len(data)),
+	}
+
+	if declared, _, err := mime.ParseMediaType(declaredType); err == nil && declared != "" {
+		sniffed := strings.SplitN(info.MimeType, ";", 2)[0]
+		if sniffed == "application/octet-stream" || (sniffed == "text/plain" && !strings.HasPrefix(declared, "text/")) {
+			info.MimeType = declared
+		}
+	}
+
+	if IsImage(info.MimeType) {
+		if config, _, err := image.DecodeConfig(bytes.NewReader(data)); err == nil {
+			info.Width, info.Height = config.Width, config.Height
+		}
+	}
+	return info
+}
+
+// IsImage reports whether a mimetype is an image
+func IsImage(mimeType string) bool {
+	return strings.HasPrefix(mimeType, "image/")
+}
+
+// Thumbnail renders a small JPEG preview of an image
+func Thumbnail(data []byte) ([]byte, error) {
+	img, err := imaging.Decode(bytes.NewReader(data), imaging.AutoOrientation(true))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	thumb := imaging.Fit(img, ThumbnailSize, ThumbnailSize, imaging.Lanczos)
+	var buf bytes.Buffer
+	if err := imaging.Encode(&buf, thumb, imaging.JPEG, imaging.JPEGQuality(70)); err != nil {
+		return nil, fmt.Errorf("failed to encode thumbnail: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,59 @@
+package media
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ErrRangeNotSatisfiable is returned for a Range that lies outside the content
+var ErrRangeNotSatisfiable = errors.New("range not satisfiable")
+
+// ParseRange reads a single "bytes=" range from a Range header for content of
+// the given size and returns the inclusive first and last byte offsets. ok is
+// false when the whole content should be served instead: no header, another
+// unit, several ranges or a malformed value, all of which a server may ignore.
+func ParseRange(header string, size int64) (start, end int64, ok bool, err error) {
+	spec, found := strings.CutPrefix(header, "bytes=")
+	if !found || strings.Contains(spec, ",") {
+		return 0, 0, false, nil
+	}
+	first, last, found := strings.Cut(strings.TrimSpace(spec), "-")
+	if !found {
+		return 0, 0, false, nil
+	}
+
+	if first == "" {
+		// Suffix range: the last n bytes
+		n, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || n < 0 {
+			return 0, 0, false, nil
+		}
+		if n == 0 || size == 0 {
+			return 0, 0, false, ErrRangeNotSatisfiable
+		}
+		if n > size {
+			n = size
+		}
+		return size - n, size - 1, true, nil
+	}
+
+	start, err = strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 {
+		return 0, 0, false, nil
+	}
+	if start >= size {
+		return 0, 0, false, ErrRangeNotSatisfiable
+	}
+	end = size - 1
+	if last != "" {
+		parsed, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || parsed < start {
+			return 0, 0, false, nil
+		}
+		if parsed < end {
+			end = parsed
+		}
+	}
+	return start, end, true, nil
+}
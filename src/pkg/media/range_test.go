@@ -0,0 +1,41 @@
+package media
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		name       string
+		header     string
+		start, end int64
+		ok         bool
+		err        error
+	}{
+		{"no header", "", 0, 0, false, nil},
+		{"open ended", "bytes=100-", 100, 999, true, nil},
+		{"bounded", "bytes=0-499", 0, 499, true, nil},
+		{"end past size is clamped", "bytes=900-2000", 900, 999, true, nil},
+		{"suffix", "bytes=-200", 800, 999, true, nil},
+		{"suffix longer than content", "bytes=-5000", 0, 999, true, nil},
+		{"start past size", "bytes=1000-", 0, 0, false, ErrRangeNotSatisfiable},
+		{"empty suffix", "bytes=-0", 0, 0, false, ErrRangeNotSatisfiable},
+		{"several ranges are ignored", "bytes=0-1,5-9", 0, 0, false, nil},
+		{"other unit is ignored", "items=0-1", 0, 0, false, nil},
+		{"end before start is ignored", "bytes=500-100", 0, 0, false, nil},
+		{"malformed is ignored", "bytes=abc-", 0, 0, false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, ok, err := ParseRange(tt.header, 1000)
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.ok, ok)
+			if tt.ok {
+				assert.Equal(t, tt.start, start)
+				assert.Equal(t, tt.end, end)
+			}
+		})
+	}
+}
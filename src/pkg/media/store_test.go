@@ -0,0 +1,80 @@
+package media
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testStore(t *testing.T, store Store) {
+	ctx := context.Background()
+	key := ContentKey("abcdef", ".jpg")
+	assert.Equal(t, "ab/abcdef.jpg", key)
+
+	_, err := store.Get(ctx, key)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, store.Put(ctx, key, []byte("content"), "image/jpeg"))
+	data, err := store.Get(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, "content", string(data))
+
+	require.NoError(t, store.Delete(ctx, key))
+	_, err = store.Get(ctx, key)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.Error(t, store.Put(ctx, "../escape", []byte("x"), ""))
+}
+
+func TestLocalStore(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	testStore(t, store)
+}
+
+func TestS3Store(t *testing.T) {
+	// In-memory stand-in for an S3-compatible bucket
+	var mu sync.Mutex
+	objects := map[string][]byte{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=key/") ||
+			r.Header.Get("X-Amz-Content-Sha256") != sha256Hex(body) {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if !strings.HasPrefix(r.URL.Path, "/media/") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case http.MethodPut:
+			objects[r.URL.Path] = body
+		case http.MethodGet:
+			data, ok := objects[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write(data)
+		case http.MethodDelete:
+			delete(objects, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	store, err := NewS3Store(S3Config{Endpoint: server.URL, Bucket: "media", AccessKey: "key", SecretKey: "secret"})
+	require.NoError(t, err)
+	testStore(t, store)
+}
@@ -0,0 +1,340 @@
+// Package metrics implements the small subset of Prometheus instrumentation
+// this service needs: labelled counters, gauges and histograms, plus
+// collectors evaluated at scrape time, exposed in the text format.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets suit latencies measured in seconds
+var DefaultBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// Registry holds metrics in registration order
+type Registry struct {
+	mu      sync.RWMutex
+	metrics []metric
+	names   map[string]bool
+}
+
+type metric interface {
+	name() string
+	write(w *bufio.Writer)
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{names: make(map[string]bool)}
+}
+
+// Default is the registry served on /metrics
+var Default = NewRegistry()
+
+func (r *Registry) register(m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.names[m.name()] {
+		panic(fmt.Sprintf("metric %s registered twice", m.name()))
+	}
+	r.names[m.name()] = true
+	r.metrics = append(r.metrics, m)
+}
+
+// WriteText writes every metric in the Prometheus text exposition format
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.RLock()
+	metrics := append([]metric(nil), r.metrics...)
+	r.mu.RUnlock()
+
+	buf := bufio.NewWriter(w)
+	for _, m := range metrics {
+		m.write(buf)
+	}
+	return buf.Flush()
+}
+
+// ContentType is the content type of WriteText's output
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+type desc struct {
+	metricName string
+	help       string
+	kind       string
+	labels     []string
+}
+
+func (d *desc) name() string { return d.metricName }
+
+func (d *desc) header(w *bufio.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", d.metricName, escapeHelp(d.help), d.metricName, d.kind)
+}
+
+// series is one labelled value of a vector
+type series struct {
+	labelValues []string
+	value       float64
+	// histogram state
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+type vec struct {
+	desc
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+func newVec(name, help, kind string, labels []string) vec {
+	return vec{desc: desc{metricName: name, help: help, kind: kind, labels: labels}, series: make(map[string]*series)}
+}
+
+// get returns the series for the label values; callers hold v.mu
+func (v *vec) get(labelValues []string) *series {
+	if len(labelValues) != len(v.labels) {
+		panic(fmt.Sprintf("metric %s expects %d label values, got %d", v.metricName, len(v.labels), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+	s, ok := v.series[key]
+	if !ok {
+		s = &series{labelValues: append([]string(nil), labelValues...)}
+		v.series[key] = s
+	}
+	return s
+}
+
+// sorted returns a snapshot of the series ordered by label values
+func (v *vec) sorted() []series {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	out := make([]series, 0, len(v.series))
+	for _, s := range v.series {
+		copied := *s
+		copied.counts = append([]uint64(nil), s.counts...)
+		out = append(out, copied)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return strings.Join(out[i].labelValues, "\xff") < strings.Join(out[j].labelValues, "\xff")
+	})
+	return out
+}
+
+// CounterVec is a counter partitioned by labels
+type CounterVec struct{ vec }
+
+// NewCounterVec registers a counter on the default registry
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	return Default.NewCounterVec(name, help, labels...)
+}
+
+// NewCounterVec registers a counter
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{newVec(name, help, "counter", labels)}
+	r.register(c)
+	return c
+}
+
+// Add increases the counter for the label values; negative deltas are ignored
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		return
+	}
+	c.mu.Lock()
+	c.get(labelValues).value += delta
+	c.mu.Unlock()
+}
+
+// Inc increases the counter for the label values by one
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+func (c *CounterVec) write(w *bufio.Writer) {
+	c.header(w)
+	for _, s := range c.sorted() {
+		writeSample(w, c.metricName, c.labels, s.labelValues, "", "", s.value)
+	}
+}
+
+// GaugeVec is a gauge partitioned by labels
+type GaugeVec struct{ vec }
+
+// NewGaugeVec registers a gauge on the default registry
+func NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	return Default.NewGaugeVec(name, help, labels...)
+}
+
+// NewGaugeVec registers a gauge
+func (r *Registry) NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	g := &GaugeVec{newVec(name, help, "gauge", labels)}
+	r.register(g)
+	return g
+}
+
+// Set sets the gauge for the label values
+func (g *GaugeVec) Set(value float64, labelValues ...string) {
+	g.mu.Lock()
+	g.get(labelValues).value = value
+	g.mu.Unlock()
+}
+
+// Add changes the gauge for the label values by delta
+func (g *GaugeVec) Add(delta float64, labelValues ...string) {
+	g.mu.Lock()
+	g.get(labelValues).value += delta
+	g.mu.Unlock()
+}
+
+func (g *GaugeVec) write(w *bufio.Writer) {
+	g.header(w)
+	for _, s := range g.sorted() {
+		writeSample(w, g.metricName, g.labels, s.labelValues, "", "", s.value)
+	}
+}
+
+// HistogramVec is a histogram partitioned by labels
+type HistogramVec struct {
+	vec
+	buckets []float64
+}
+
+// NewHistogramVec registers a histogram on the default registry; nil
+// buckets means DefaultBuckets
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	return Default.NewHistogramVec(name, help, buckets, labels...)
+}
+
+// NewHistogramVec registers a histogram; nil buckets means DefaultBuckets
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	h := &HistogramVec{vec: newVec(name, help, "histogram", labels), buckets: buckets}
+	r.register(h)
+	return h
+}
+
+// Observe records a value for the label values
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s := h.get(labelValues)
+	if s.counts == nil {
+		s.counts = make([]uint64, len(h.buckets))
+	}
+	for i, upper := range h.buckets {
+		if value <= upper {
+			s.counts[i]++
+		}
+	}
+	s.sum += value
+	s.count++
+}
+
+func (h *HistogramVec) write(w *bufio.Writer) {
+	h.header(w)
+	for _, s := range h.sorted() {
+		for i, upper := range h.buckets {
+			writeSample(w, h.metricName+"_bucket", h.labels, s.labelValues, "le", formatFloat(upper), float64(s.counts[i]))
+		}
+		writeSample(w, h.metricName+"_bucket", h.labels, s.labelValues, "le", "+Inf", float64(s.count))
+		writeSample(w, h.metricName+"_sum", h.labels, s.labelValues, "", "", s.sum)
+		writeSample(w, h.metricName+"_count", h.labels, s.labelValues, "", "", float64(s.count))
+	}
+}
+
+// Sample is one value reported by a collector
+type Sample struct {
+	LabelValues []string
+	Value       float64
+}
+
+// CollectorFunc reports gauge or counter values computed at scrape time,
+// e.g. queue depths read from the database
+type CollectorFunc struct {
+	desc
+	collect func() []Sample
+}
+
+// NewGaugeFunc registers a gauge on the default registry whose values are
+// computed by collect on every scrape
+func NewGaugeFunc(name, help string, collect func() []Sample, labels ...string) *CollectorFunc {
+	return Default.NewCollectorFunc(name, help, "gauge", collect, labels...)
+}
+
+// NewCounterFunc registers a counter on the default registry whose values
+// are read by collect on every scrape
+func NewCounterFunc(name, help string, collect func() []Sample, labels ...string) *CollectorFunc {
+	return Default.NewCollectorFunc(name, help, "counter", collect, labels...)
+}
+
+// NewCollectorFunc registers a gauge or counter computed by collect on every scrape
+func (r *Registry) NewCollectorFunc(name, help, kind string, collect func() []Sample, labels ...string) *CollectorFunc {
+	c := &CollectorFunc{desc: desc{metricName: name, help: help, kind: kind, labels: labels}, collect: collect}
+	r.register(c)
+	return c
+}
+
+func (c *CollectorFunc) write(w *bufio.Writer) {
+	samples := c.collect()
+	c.header(w)
+	for _, s := range samples {
+		if len(s.LabelValues) != len(c.labels) {
+			continue
+		}
+		writeSample(w, c.metricName, c.labels, s.LabelValues, "", "", s.Value)
+	}
+}
+
+func writeSample(w *bufio.Writer, name string, labels, values []string, extraLabel, extraValue string, value float64) {
+	w.WriteString(name)
+	if len(labels) > 0 || extraLabel != "" {
+		w.WriteByte('{')
+		for i, label := range labels {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, "%s=\"%s\"", label, escapeLabel(values[i]))
+		}
+		if extraLabel != "" {
+			if len(labels) > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, "%s=\"%s\"", extraLabel, extraValue)
+		}
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(value))
+	w.WriteByte('\n')
+}
+
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabel(value string) string { return labelEscaper.Replace(value) }
+
+func escapeHelp(value string) string { return helpEscaper.Replace(value) }
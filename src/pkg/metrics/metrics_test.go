@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryWriteText(t *testing.T) {
+	registry := NewRegistry()
+	sent := registry.NewCounterVec("messages_sent_total", "Messages sent.", "device")
+	depth := registry.NewGaugeVec("queue_depth", "Queued messages.", "device", "status")
+	latency := registry.NewHistogramVec("send_seconds", "Send latency.", []float64{1, 0.5}, "type")
+	registry.NewCollectorFunc("db_connections", "Open connections.", "gauge", func() []Sample {
+		return []Sample{{LabelValues: []string{"idle"}, Value: 3}, {LabelValues: []string{"bad", "arity"}, Value: 1}}
+	}, "state")
+
+	sent.Inc("dev-b")
+	sent.Add(2, "dev-a")
+	sent.Add(-5, "dev-a")
+	depth.Set(7, `dev "1"`, "pending")
+	latency.Observe(0.2, "text")
+	latency.Observe(0.7, "text")
+	latency.Observe(3, "text")
+
+	var out bytes.Buffer
+	require.NoError(t, registry.WriteText(&out))
+	assert.Equal(t, `# HELP messages_sent_total Messages sent.
+# TYPE messages_sent_total counter
+messages_sent_total{device="dev-a"} 2
+messages_sent_total{device="dev-b"} 1
+# HELP queue_depth Queued messages.
+# TYPE queue_depth gauge
+queue_depth{device="dev \"1\"",status="pending"} 7
+# HELP send_seconds Send latency.
+# TYPE send_seconds histogram
+send_seconds_bucket{type="text",le="0.5"} 1
+send_seconds_bucket{type="text",le="1"} 2
+send_seconds_bucket{type="text",le="+Inf"} 3
+send_seconds_sum{type="text"} 3.9
+send_seconds_count{type="text"} 3
+# HELP db_connections Open connections.
+# TYPE db_connections gauge
+db_connections{state="idle"} 3
+`, out.String())
+}
+
+func TestRegistryRejectsDuplicates(t *testing.T) {
+	registry := NewRegistry()
+	registry.NewCounterVec("dup_total", "Duplicate.")
+	assert.Panics(t, func() { registry.NewGaugeVec("dup_total", "Duplicate.") })
+}
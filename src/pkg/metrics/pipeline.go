@@ -0,0 +1,23 @@
+package metrics
+
+// Send pipeline metrics. Values that live in the database, such as queue
+// depth and device state, are registered as collectors by the usecase layer.
+var (
+	MessagesSent = NewCounterVec("broadcast_messages_sent_total",
+		"Messages sent successfully.", "device", "type")
+	MessagesFailed = NewCounterVec("broadcast_messages_failed_total",
+		"Failed send attempts by error class and code; outcome is retry when the message was rescheduled.",
+		"device", "error_class", "error_code", "outcome")
+	SendDuration = NewHistogramVec("broadcast_send_duration_seconds",
+		"Time taken to hand one message to WhatsApp or a platform provider.", nil, "device", "type")
+
+	ReceiptsReceived = NewCounterVec("whatsapp_receipts_total",
+		"Delivery and read receipts received for sent messages.", "type")
+
+	WebhookDeliveries = NewCounterVec("webhook_deliveries_total",
+		"Outgoing webhook deliveries by result after retries.", "result")
+	WebhookAttempts = NewCounterVec("webhook_delivery_attempts_total",
+		"Individual outgoing webhook HTTP attempts by result.", "result")
+	WebhookDuration = NewHistogramVec("webhook_delivery_duration_seconds",
+		"Time taken to deliver a webhook, including retries.", nil, "result")
+)
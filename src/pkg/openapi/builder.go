@@ -0,0 +1,268 @@
+package openapi
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Route is a registered route. Path uses Fiber syntax (/api/leads/:id) and
+// Handler is the runtime name of the function that serves it. A public
+// route takes the listed security instead of the document's; none means
+// no credentials at all.
+type Route struct {
+	Method   string
+	Path     string
+	Handler  string
+	Public   bool
+	Security []SecurityRequirement
+}
+
+// OperationInfo adds what the route table cannot tell about an operation
+type OperationInfo struct {
+	ID       string // Overrides the operation ID taken from the handler name
+	Summary  string
+	Body     any    // Request body; a struct with a file field is sent as multipart
+	Query    any    // Struct whose fields are the query parameters
+	Produces string // Media type of a successful response that is not JSON
+}
+
+// Spec describes the document to build
+type Spec struct {
+	Info            Info
+	Servers         []Server
+	Security        []SecurityRequirement
+	SecuritySchemes map[string]SecurityScheme
+	Envelope        any                      // Body of every JSON response
+	Operations      map[string]OperationInfo // Keyed by "METHOD /path"
+}
+
+// Build describes routes as an OpenAPI document. When a method and path are
+// registered twice, even under other parameter names, the first route wins
+// as it does in Fiber. It fails on
+// operation IDs that are not unique and on operations that match no route,
+// so the metadata cannot drift from the route table.
+func Build(spec Spec, routes []Route) (*Document, error) {
+	types := newSchemas()
+	envelope := types.For(spec.Envelope)
+
+	doc := &Document{
+		OpenAPI:  Version,
+		Info:     spec.Info,
+		Servers:  spec.Servers,
+		Security: spec.Security,
+		Paths:    map[string]PathItem{},
+	}
+
+	var problems []string
+	ids := map[string]string{}
+	tags := map[string]bool{}
+	seen := map[string]bool{}
+	served := map[string]bool{}
+	for _, route := range routes {
+		method := strings.ToUpper(route.Method)
+		key := method + " " + route.Path
+		path, params := convertPath(route.Path)
+		if served[method+" "+pattern(path)] {
+			continue
+		}
+		served[method+" "+pattern(path)] = true
+		seen[key] = true
+
+		info := spec.Operations[key]
+		op := &Operation{
+			OperationID: info.ID,
+			Summary:     info.Summary,
+			Responses:   responses(info.Produces),
+		}
+		if op.OperationID == "" {
+			op.OperationID = operationID(method, route.Path, route.Handler)
+		}
+		if other, ok := ids[op.OperationID]; ok {
+			problems = append(problems, fmt.Sprintf("operation ID %s is used by %s and %s", op.OperationID, other, key))
+		}
+		ids[op.OperationID] = key
+		if op.Summary == "" {
+			op.Summary = sentence(op.OperationID)
+		}
+		if tag := tagFor(route.Path); tag != "" {
+			op.Tags = []string{tag}
+			tags[tag] = true
+		}
+		if route.Public {
+			security := append([]SecurityRequirement{}, route.Security...)
+			op.Security = &security
+		}
+
+		op.Parameters = params
+		if info.Query != nil {
+			for _, field := range Fields(reflect.TypeOf(info.Query)) {
+				op.Parameters = append(op.Parameters, Parameter{Name: field.Name, In: "query", Schema: types.schema(field.Type)})
+			}
+		}
+		if info.Body != nil {
+			mediaType := "application/json"
+			if hasFile(reflect.TypeOf(info.Body)) {
+				mediaType = "multipart/form-data"
+			}
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content:  map[string]MediaType{mediaType: {Schema: types.For(info.Body)}},
+			}
+		}
+
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = PathItem{}
+		}
+		doc.Paths[path][strings.ToLower(method)] = op
+	}
+
+	for key := range spec.Operations {
+		if !seen[key] {
+			problems = append(problems, fmt.Sprintf("operation %s matches no route", key))
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return nil, fmt.Errorf("openapi: %s", strings.Join(problems, "; "))
+	}
+
+	for tag := range tags {
+		doc.Tags = append(doc.Tags, Tag{Name: tag})
+	}
+	sort.Slice(doc.Tags, func(i, j int) bool { return doc.Tags[i].Name < doc.Tags[j].Name })
+	doc.Components = Components{
+		Schemas: types.components,
+		Responses: map[string]Response{
+			"Success": {Description: "Success", Content: map[string]MediaType{"application/json": {Schema: envelope}}},
+			"Error":   {Description: "Error", Content: map[string]MediaType{"application/json": {Schema: envelope}}},
+		},
+		SecuritySchemes: spec.SecuritySchemes,
+	}
+	return doc, nil
+}
+
+// responses refers JSON replies to the shared Success and Error responses
+func responses(produces string) map[string]Response {
+	success := Response{Ref: "#/components/responses/Success"}
+	if produces != "" {
+		success = Response{Description: "Success", Content: map[string]MediaType{produces: {Schema: &Schema{Type: "string", Format: "binary"}}}}
+	}
+	return map[string]Response{"200": success, "default": {Ref: "#/components/responses/Error"}}
+}
+
+// operationID names an operation after its handler, e.g.
+// ".../ui/rest.(*App).GetCampaigns-fm" becomes getCampaigns. Closures have
+// no useful name, so their operations are named after the method and path.
+func operationID(method, path, handler string) string {
+	name := strings.TrimSuffix(handler[strings.LastIndex(handler, "/")+1:], "-fm")
+	name = name[strings.LastIndex(name, ".")+1:]
+	if name != "" && !strings.HasPrefix(name, "func") {
+		return lowerFirst(name)
+	}
+
+	words := []string{strings.ToLower(method)}
+	for _, segment := range strings.Split(path, "/") {
+		segment = strings.TrimPrefix(segment, ":")
+		for _, word := range strings.FieldsFunc(segment, func(r rune) bool { return r == '-' || r == '_' }) {
+			words = append(words, exported(word))
+		}
+	}
+	return strings.Join(words, "")
+}
+
+// convertPath turns Fiber path parameters into OpenAPI ones
+func convertPath(path string) (string, []Parameter) {
+	segments := strings.Split(path, "/")
+	var params []Parameter
+	for i, segment := range segments {
+		if !strings.HasPrefix(segment, ":") {
+			continue
+		}
+		name := strings.TrimSuffix(segment[1:], "?")
+		segments[i] = "{" + name + "}"
+		params = append(params, Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}})
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// pattern blanks out parameter names: /a/{id} and /a/{name} match alike
+func pattern(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, "{") {
+			segments[i] = "{}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// tagFor groups operations by the first path segment after /api
+func tagFor(path string) string {
+	segments := strings.Split(strings.TrimPrefix(path, "/api"), "/")
+	if len(segments) < 2 || strings.HasPrefix(segments[1], ":") || strings.Contains(segments[1], ".") {
+		return ""
+	}
+	return segments[1]
+}
+
+func hasFile(t reflect.Type) bool {
+	for _, field := range Fields(t) {
+		ft := field.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if ft == fileHeaderType {
+			return true
+		}
+	}
+	return false
+}
+
+// sentence turns getCampaignDeviceLeads into "Get campaign device leads"
+func sentence(id string) string {
+	words := splitWords(id)
+	for i, word := range words {
+		if strings.ToUpper(word) != word || len(word) == 1 {
+			words[i] = strings.ToLower(word)
+		}
+	}
+	if len(words) > 0 {
+		words[0] = exported(words[0])
+	}
+	return strings.Join(words, " ")
+}
+
+func lowerFirst(name string) string {
+	words := splitWords(name)
+	if len(words) == 0 {
+		return name
+	}
+	words[0] = strings.ToLower(words[0])
+	return strings.Join(words, "")
+}
+
+// splitWords splits a camel-case name, keeping acronyms together:
+// getLeadsAIByID becomes get, Leads, AI, By, ID
+func splitWords(name string) []string {
+	runes := []rune(name)
+	var words []string
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		if !unicode.IsUpper(runes[i]) {
+			continue
+		}
+		prevUpper := unicode.IsUpper(runes[i-1])
+		nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		if !prevUpper || nextLower {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	if start < len(runes) {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
@@ -0,0 +1,100 @@
+package openapi
+
+import (
+	"mime/multipart"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type uploadRequest struct {
+	DeviceID string                `json:"device_id" form:"device_id"`
+	File     *multipart.FileHeader `form:"file"`
+	Internal []byte                `json:"-"`
+}
+
+type envelope struct {
+	Code    string `json:"code"`
+	Results any    `json:"results"`
+}
+
+func TestBuild(t *testing.T) {
+	routes := []Route{
+		{Method: "GET", Path: "/api/leads/:id", Handler: "example.com/ui/rest.(*App).GetLead-fm"},
+		{Method: "GET", Path: "/api/leads/:leadId", Handler: "example.com/ui/rest.(*App).GetLeadAgain-fm"},
+		{Method: "POST", Path: "/api/uploads", Handler: "example.com/ui/rest.InitRoutes.func3"},
+		{Method: "GET", Path: "/api/public/status", Handler: "example.com/ui/rest.GetStatus", Public: true},
+	}
+	doc, err := Build(Spec{
+		Envelope:   envelope{},
+		Operations: map[string]OperationInfo{"POST /api/uploads": {Body: uploadRequest{}}},
+	}, routes)
+	require.NoError(t, err)
+
+	lead := doc.Paths["/api/leads/{id}"]["get"]
+	assert.Equal(t, "getLead", lead.OperationID)
+	assert.Equal(t, "Get lead", lead.Summary)
+	assert.Equal(t, []string{"leads"}, lead.Tags)
+	assert.Equal(t, []Parameter{{Name: "id", In: "path", Required: true, Schema: &Schema{Type: "string"}}}, lead.Parameters)
+	assert.NotContains(t, doc.Paths, "/api/leads/{leadId}", "the first of two equal routes wins")
+
+	upload := doc.Paths["/api/uploads"]["post"]
+	assert.Equal(t, "postApiUploads", upload.OperationID)
+	body := upload.RequestBody.Content["multipart/form-data"].Schema
+	assert.Equal(t, "#/components/schemas/UploadRequest", body.Ref)
+	assert.Equal(t, map[string]*Schema{
+		"device_id": {Type: "string"},
+		"file":      {Type: "string", Format: "binary"},
+	}, doc.Components.Schemas["UploadRequest"].Properties)
+
+	assert.Nil(t, lead.Security)
+	assert.Equal(t, &[]SecurityRequirement{}, doc.Paths["/api/public/status"]["get"].Security)
+}
+
+func TestBuildRejectsDrift(t *testing.T) {
+	tests := []struct {
+		name   string
+		routes []Route
+		ops    map[string]OperationInfo
+		err    string
+	}{
+		{
+			name:   "operation without a route",
+			routes: []Route{{Method: "GET", Path: "/api/a", Handler: "rest.GetA"}},
+			ops:    map[string]OperationInfo{"GET /api/b": {}},
+			err:    "operation GET /api/b matches no route",
+		},
+		{
+			name: "duplicate operation IDs",
+			routes: []Route{
+				{Method: "GET", Path: "/api/a", Handler: "rest.(*App).List-fm"},
+				{Method: "GET", Path: "/api/b", Handler: "rest.(*Public).List-fm"},
+			},
+			err: "operation ID list is used by GET /api/a and GET /api/b",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Build(Spec{Operations: tt.ops}, tt.routes)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestGoName(t *testing.T) {
+	tests := map[string]string{
+		"device_id":        "DeviceID",
+		"deviceId":         "DeviceID",
+		"getLeadsAI":       "GetLeadsAI",
+		"image_url":        "ImageURL",
+		"imageB64":         "ImageB64",
+		"2fa":              "N2fa",
+		"expires_in_hours": "ExpiresInHours",
+	}
+	for name, want := range tests {
+		assert.Equal(t, want, GoName(name), name)
+	}
+}
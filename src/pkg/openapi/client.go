@@ -0,0 +1,213 @@
+package openapi
+
+import (
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// GenerateClient renders Go source for a typed client of the document: a
+// struct per component schema, a query struct per operation with query
+// parameters and a Client method per operation. The methods call
+// c.do(ctx, request{...}), which the package provides by hand along with
+// the Client, Response and File types.
+func GenerateClient(doc *Document, pkg string) ([]byte, error) {
+	g := &generator{imports: map[string]bool{"context": true}}
+
+	names := make([]string, 0, len(doc.Components.Schemas))
+	for name := range doc.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&g.body, "\n// %s is the %s schema\n", name, name)
+		fmt.Fprintf(&g.body, "type %s %s\n", name, g.goType(doc.Components.Schemas[name], false))
+	}
+
+	paths := make([]string, 0, len(doc.Paths))
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		methods := make([]string, 0, len(doc.Paths[path]))
+		for method := range doc.Paths[path] {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+		for _, method := range methods {
+			if err := g.operation(strings.ToUpper(method), path, doc.Paths[path][method]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var out strings.Builder
+	out.WriteString("// Code generated by openapi.GenerateClient. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&out, "package %s\n\nimport (\n", pkg)
+	imports := make([]string, 0, len(g.imports))
+	for path := range g.imports {
+		imports = append(imports, path)
+	}
+	sort.Strings(imports)
+	for _, path := range imports {
+		fmt.Fprintf(&out, "\t%q\n", path)
+	}
+	out.WriteString(")\n")
+	out.WriteString(g.body.String())
+
+	source, err := format.Source([]byte(out.String()))
+	if err != nil {
+		return nil, fmt.Errorf("openapi: format client: %w", err)
+	}
+	return source, nil
+}
+
+type generator struct {
+	body    strings.Builder
+	imports map[string]bool
+}
+
+func (g *generator) operation(method, path string, op *Operation) error {
+	name := GoName(op.OperationID)
+	if name == "" {
+		return fmt.Errorf("openapi: %s %s has no operation ID", method, path)
+	}
+
+	args := []string{"ctx context.Context"}
+	pathExpr := fmt.Sprintf("%q", path)
+	var query []Parameter
+	for _, param := range op.Parameters {
+		switch param.In {
+		case "path":
+			arg := lowerFirst(GoName(param.Name))
+			args = append(args, arg+" string")
+			g.imports["net/url"] = true
+			pathExpr = strings.Replace(pathExpr, "{"+param.Name+"}", `" + url.PathEscape(`+arg+`) + "`, 1)
+		case "query":
+			query = append(query, param)
+		}
+	}
+	pathExpr = strings.TrimSuffix(strings.TrimPrefix(pathExpr, `"" + `), ` + ""`)
+
+	fields := []string{fmt.Sprintf("method: %q", method), "path: " + pathExpr}
+	if len(query) > 0 {
+		queryType := name + "Query"
+		fmt.Fprintf(&g.body, "\n// %s holds the query parameters of %s\ntype %s struct {\n", queryType, name, queryType)
+		for _, param := range query {
+			fmt.Fprintf(&g.body, "\t%s %s `query:%q`\n", GoName(param.Name), g.goType(param.Schema, true), param.Name)
+		}
+		g.body.WriteString("}\n")
+		args = append(args, "query *"+queryType)
+		fields = append(fields, "query: query")
+	}
+	if op.RequestBody != nil {
+		for mediaType, content := range op.RequestBody.Content {
+			args = append(args, "body "+g.goType(content.Schema, true))
+			fields = append(fields, "body: body")
+			if mediaType == "multipart/form-data" {
+				fields = append(fields, "multipart: true")
+			}
+		}
+	}
+
+	fmt.Fprintf(&g.body, "\n// %s calls %s %s\n", name, method, path)
+	if op.Summary != "" {
+		fmt.Fprintf(&g.body, "//\n// %s\n", op.Summary)
+	}
+	fmt.Fprintf(&g.body, "func (c *Client) %s(%s) (*Response, error) {\n", name, strings.Join(args, ", "))
+	fmt.Fprintf(&g.body, "\treturn c.do(ctx, request{%s})\n}\n", strings.Join(fields, ", "))
+	return nil
+}
+
+// goType maps a schema to a Go type; field types point to structs, times
+// and nullable values so unset fields are left out of requests
+func (g *generator) goType(schema *Schema, field bool) string {
+	pointer := ""
+	if field {
+		pointer = "*"
+	}
+	if schema == nil {
+		return "any"
+	}
+	if schema.Ref != "" {
+		return pointer + schema.Ref[strings.LastIndex(schema.Ref, "/")+1:]
+	}
+	if !schema.Nullable {
+		pointer = ""
+	}
+
+	switch schema.Type {
+	case "string":
+		switch schema.Format {
+		case "date-time":
+			g.imports["time"] = true
+			if field {
+				return "*time.Time"
+			}
+			return "time.Time"
+		case "binary":
+			return "*File"
+		case "byte":
+			return "[]byte"
+		}
+		return pointer + "string"
+	case "integer":
+		if schema.Format == "int64" {
+			return pointer + "int64"
+		}
+		return pointer + "int"
+	case "number":
+		return pointer + "float64"
+	case "boolean":
+		return pointer + "bool"
+	case "array":
+		return "[]" + g.goType(schema.Items, false)
+	case "object":
+		if schema.AdditionalProperties != nil {
+			return "map[string]" + g.goType(schema.AdditionalProperties, false)
+		}
+		props := make([]string, 0, len(schema.Properties))
+		for prop := range schema.Properties {
+			props = append(props, prop)
+		}
+		sort.Strings(props)
+		var b strings.Builder
+		b.WriteString("struct {\n")
+		for _, prop := range props {
+			fmt.Fprintf(&b, "\t%s %s `json:\"%s,omitempty\"`\n", GoName(prop), g.goType(schema.Properties[prop], true), prop)
+		}
+		b.WriteString("}")
+		return b.String()
+	}
+	return "any"
+}
+
+// initialisms are upper-cased whole in Go names
+var initialisms = map[string]bool{
+	"AI": true, "API": true, "B64": true, "HTML": true, "HTTP": true, "ID": true, "IP": true,
+	"JID": true, "JSON": true, "QR": true, "SQL": true, "URL": true, "UUID": true,
+}
+
+// GoName turns a JSON or operation name such as device_id or
+// getLeadsAI into an exported Go identifier such as DeviceID or GetLeadsAI
+func GoName(name string) string {
+	var b strings.Builder
+	parts := strings.FieldsFunc(name, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+	for _, part := range parts {
+		for _, word := range splitWords(part) {
+			if upper := strings.ToUpper(word); initialisms[upper] {
+				b.WriteString(upper)
+				continue
+			}
+			b.WriteString(exported(word))
+		}
+	}
+	result := b.String()
+	if result != "" && unicode.IsDigit([]rune(result)[0]) {
+		result = "N" + result
+	}
+	return result
+}
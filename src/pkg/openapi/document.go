@@ -0,0 +1,128 @@
+// Package openapi builds an OpenAPI 3.0 document from a route table and the
+// Go types the handlers parse, and generates a typed Go client from it.
+package openapi
+
+import (
+	"bytes"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Version is the OpenAPI version the documents declare
+const Version = "3.0.3"
+
+// Document is an OpenAPI 3.0 document. Maps are rendered with sorted keys,
+// so the same routes always produce the same YAML.
+type Document struct {
+	OpenAPI    string                `yaml:"openapi"`
+	Info       Info                  `yaml:"info"`
+	Servers    []Server              `yaml:"servers,omitempty"`
+	Security   []SecurityRequirement `yaml:"security,omitempty"`
+	Tags       []Tag                 `yaml:"tags,omitempty"`
+	Paths      map[string]PathItem   `yaml:"paths"`
+	Components Components            `yaml:"components"`
+}
+
+type Info struct {
+	Title       string `yaml:"title"`
+	Description string `yaml:"description,omitempty"`
+	Version     string `yaml:"version"`
+}
+
+type Server struct {
+	URL         string `yaml:"url"`
+	Description string `yaml:"description,omitempty"`
+}
+
+type Tag struct {
+	Name string `yaml:"name"`
+}
+
+// SecurityRequirement maps a security scheme name to its scopes
+type SecurityRequirement map[string][]string
+
+// PathItem maps a lower-case HTTP method to its operation
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string                 `yaml:"operationId"`
+	Tags        []string               `yaml:"tags,omitempty"`
+	Summary     string                 `yaml:"summary,omitempty"`
+	Description string                 `yaml:"description,omitempty"`
+	Parameters  []Parameter            `yaml:"parameters,omitempty"`
+	RequestBody *RequestBody           `yaml:"requestBody,omitempty"`
+	Responses   map[string]Response    `yaml:"responses"`
+	Security    *[]SecurityRequirement `yaml:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `yaml:"name"`
+	In          string  `yaml:"in"`
+	Description string  `yaml:"description,omitempty"`
+	Required    bool    `yaml:"required,omitempty"`
+	Schema      *Schema `yaml:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `yaml:"required,omitempty"`
+	Content  map[string]MediaType `yaml:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `yaml:"schema,omitempty"`
+}
+
+type Response struct {
+	Ref         string               `yaml:"$ref,omitempty"`
+	Description string               `yaml:"description,omitempty"`
+	Content     map[string]MediaType `yaml:"content,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `yaml:"schemas,omitempty"`
+	Responses       map[string]Response       `yaml:"responses,omitempty"`
+	SecuritySchemes map[string]SecurityScheme `yaml:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type        string `yaml:"type"`
+	Scheme      string `yaml:"scheme,omitempty"`
+	In          string `yaml:"in,omitempty"`
+	Name        string `yaml:"name,omitempty"`
+	Description string `yaml:"description,omitempty"`
+}
+
+// Schema is the subset of JSON Schema the builder emits
+type Schema struct {
+	Ref                  string             `yaml:"$ref,omitempty"`
+	Type                 string             `yaml:"type,omitempty"`
+	Format               string             `yaml:"format,omitempty"`
+	Description          string             `yaml:"description,omitempty"`
+	Nullable             bool               `yaml:"nullable,omitempty"`
+	Items                *Schema            `yaml:"items,omitempty"`
+	Properties           map[string]*Schema `yaml:"properties,omitempty"`
+	AdditionalProperties *Schema            `yaml:"additionalProperties,omitempty"`
+}
+
+// YAML renders the document
+func (d *Document) YAML() ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(d); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Parse reads a YAML document
+func Parse(data []byte) (*Document, error) {
+	var doc Document
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
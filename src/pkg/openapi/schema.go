@@ -0,0 +1,175 @@
+package openapi
+
+import (
+	"encoding/json"
+	"mime/multipart"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	fileHeaderType = reflect.TypeOf(multipart.FileHeader{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// schemas turns Go types into component schemas. Named structs become
+// components referenced by $ref; a name used by two packages is prefixed
+// with the package name.
+type schemas struct {
+	components map[string]*Schema
+	names      map[reflect.Type]string
+}
+
+func newSchemas() *schemas {
+	return &schemas{components: map[string]*Schema{}, names: map[reflect.Type]string{}}
+}
+
+// For returns the schema of a value's type
+func (s *schemas) For(v any) *Schema {
+	return s.schema(reflect.TypeOf(v))
+}
+
+func (s *schemas) schema(t reflect.Type) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+	nullable := false
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		nullable = true
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time", Nullable: nullable}
+	case t == fileHeaderType:
+		return &Schema{Type: "string", Format: "binary"}
+	case t == rawMessageType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean", Nullable: nullable}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Nullable: nullable}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64", Nullable: nullable}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number", Nullable: nullable}
+	case reflect.String:
+		return &Schema{Type: "string", Nullable: nullable}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte", Nullable: nullable}
+		}
+		return &Schema{Type: "array", Items: s.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + s.component(t)}
+	}
+	return &Schema{}
+}
+
+// component registers a named struct and returns its component name
+func (s *schemas) component(t reflect.Type) string {
+	if name, ok := s.names[t]; ok {
+		return name
+	}
+	name := exported(t.Name())
+	if _, taken := s.components[name]; taken {
+		pkg := t.PkgPath()
+		name = exported(pkg[strings.LastIndex(pkg, "/")+1:]) + name
+	}
+	s.names[t] = name
+	// Reserve the name before recursing so self-references terminate
+	s.components[name] = &Schema{Type: "object"}
+	*s.components[name] = *s.object(t)
+	return name
+}
+
+// object describes a struct's JSON fields; embedded structs are flattened
+func (s *schemas) object(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for _, field := range Fields(t) {
+		schema.Properties[field.Name] = s.schema(field.Type)
+	}
+	return schema
+}
+
+// Field is a struct field as it appears in a request
+type Field struct {
+	Name string
+	Type reflect.Type
+}
+
+// Fields lists the fields of a struct under their json names, falling back
+// to the form and query tags the handlers' parsers also read. Other types
+// have no fields.
+func Fields(t reflect.Type) []Field {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	var fields []Field
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, skip := fieldName(f)
+		if skip {
+			continue
+		}
+		if f.Anonymous && name == "" {
+			embedded := f.Type
+			for embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				fields = append(fields, Fields(embedded)...)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields = append(fields, Field{Name: name, Type: f.Type})
+	}
+	return fields
+}
+
+func fieldName(f reflect.StructField) (string, bool) {
+	for _, key := range []string{"json", "form", "query"} {
+		tag, ok := f.Tag.Lookup(key)
+		if !ok {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if name == "-" {
+			return "", true
+		}
+		if name != "" {
+			return name, false
+		}
+	}
+	return "", false
+}
+
+func exported(name string) string {
+	if name == "" {
+		return name
+	}
+	runes := []rune(name)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
@@ -0,0 +1,96 @@
+// Package sharelink signs and verifies the tokens in device share links.
+// A token names a link and its expiry; revocation, scopes and passwords are
+// checked against the stored link.
+package sharelink
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Scopes a link can grant
+const (
+	ScopeStats      = "stats:read"
+	ScopeLeadsRead  = "leads:read"
+	ScopeLeadsWrite = "leads:write"
+)
+
+var (
+	ErrMalformed = errors.New("malformed share token")
+	ErrSignature = errors.New("invalid share token signature")
+	ErrExpired   = errors.New("share link expired")
+)
+
+// IsScope reports whether s is a known scope
+func IsScope(s string) bool {
+	return s == ScopeStats || s == ScopeLeadsRead || s == ScopeLeadsWrite
+}
+
+// Allows reports whether granted covers scope; write access to leads
+// includes reading them, and an empty scope only requires a valid link
+func Allows(granted []string, scope string) bool {
+	if scope == "" {
+		return true
+	}
+	for _, g := range granted {
+		if g == scope || (scope == ScopeLeadsRead && g == ScopeLeadsWrite) {
+			return true
+		}
+	}
+	return false
+}
+
+// Sign returns the token for a link: "<linkID>.<expiry>.<signature>"
+func Sign(secret []byte, linkID string, expiresAt time.Time) string {
+	payload := linkID + "." + strconv.FormatInt(expiresAt.Unix(), 36)
+	return payload + "." + mac(secret, "link", payload)
+}
+
+// Verify checks a token's signature and expiry and returns the link it
+// names. The link is returned with ErrExpired too.
+func Verify(secret []byte, token string, now time.Time) (linkID string, expiresAt time.Time, err error) {
+	cut := strings.LastIndexByte(token, '.')
+	if cut < 0 {
+		return "", time.Time{}, ErrMalformed
+	}
+	payload, signature := token[:cut], token[cut+1:]
+	dot := strings.LastIndexByte(payload, '.')
+	if dot <= 0 {
+		return "", time.Time{}, ErrMalformed
+	}
+	expiry, parseErr := strconv.ParseInt(payload[dot+1:], 36, 64)
+	if parseErr != nil {
+		return "", time.Time{}, ErrMalformed
+	}
+	if !hmac.Equal([]byte(signature), []byte(mac(secret, "link", payload))) {
+		return "", time.Time{}, ErrSignature
+	}
+	expiresAt = time.Unix(expiry, 0)
+	if !now.Before(expiresAt) {
+		// The link is still named so the refusal can be logged against it
+		return payload[:dot], expiresAt, ErrExpired
+	}
+	return payload[:dot], expiresAt, nil
+}
+
+// UnlockProof is the cookie value that shows a link's password was entered.
+// It is bound to the password hash so changing the password locks it again.
+func UnlockProof(secret []byte, linkID, passwordHash string) string {
+	return mac(secret, "unlock", linkID+"."+passwordHash)
+}
+
+// CheckUnlockProof reports whether proof was issued for the link and password
+func CheckUnlockProof(secret []byte, linkID, passwordHash, proof string) bool {
+	return hmac.Equal([]byte(proof), []byte(UnlockProof(secret, linkID, passwordHash)))
+}
+
+func mac(secret []byte, purpose, payload string) string {
+	h := hmac.New(sha256.New, secret)
+	h.Write([]byte(purpose + ":" + payload))
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
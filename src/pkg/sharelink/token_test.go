@@ -0,0 +1,58 @@
+package sharelink
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	secret := []byte("secret")
+	now := time.Unix(1700000000, 0)
+	token := Sign(secret, "8f2c0d5e-1b7a-4c3e-9d2f-6a5b4c3d2e1f", now.Add(time.Hour))
+
+	linkID, expiresAt, err := Verify(secret, token, now)
+	require.NoError(t, err)
+	assert.Equal(t, "8f2c0d5e-1b7a-4c3e-9d2f-6a5b4c3d2e1f", linkID)
+	assert.Equal(t, now.Add(time.Hour), expiresAt)
+
+	tests := []struct {
+		name  string
+		token string
+		now   time.Time
+		err   error
+	}{
+		{"expired", token, now.Add(time.Hour), ErrExpired},
+		{"other secret", Sign([]byte("other"), "id", now.Add(time.Hour)), now, ErrSignature},
+		{"tampered link", "other-id" + token[len("8f2c0d5e-1b7a-4c3e-9d2f-6a5b4c3d2e1f"):], now, ErrSignature},
+		{"no signature", "id", now, ErrMalformed},
+		{"no expiry", "id.sig", now, ErrMalformed},
+		{"bad expiry", "id.!!.sig", now, ErrMalformed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			linkID, _, err := Verify(secret, tt.token, tt.now)
+			assert.Equal(t, tt.err, err)
+			if err != ErrExpired {
+				assert.Empty(t, linkID)
+			}
+		})
+	}
+}
+
+func TestUnlockProof(t *testing.T) {
+	secret := []byte("secret")
+	proof := UnlockProof(secret, "link", "hash-1")
+	assert.True(t, CheckUnlockProof(secret, "link", "hash-1", proof))
+	assert.False(t, CheckUnlockProof(secret, "link", "hash-2", proof))
+	assert.False(t, CheckUnlockProof(secret, "other", "hash-1", proof))
+}
+
+func TestAllows(t *testing.T) {
+	assert.True(t, Allows(nil, ""))
+	assert.True(t, Allows([]string{ScopeLeadsWrite}, ScopeLeadsRead))
+	assert.False(t, Allows([]string{ScopeLeadsRead}, ScopeLeadsWrite))
+	assert.False(t, Allows([]string{ScopeStats}, ScopeLeadsRead))
+}
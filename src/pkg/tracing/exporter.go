@@ -0,0 +1,269 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	queueSize     = 4096
+	maxBatchSize  = 512
+	flushInterval = 5 * time.Second
+	scopeName     = "github.com/aldinokemal/go-whatsapp-web-multidevice"
+)
+
+// exporter batches finished spans and posts them to the collector
+type exporter struct {
+	url         string
+	serviceName string
+	headers     map[string]string
+	client      *http.Client
+
+	queue   chan *Span
+	flush   chan chan struct{}
+	done    chan struct{}
+	stopped sync.WaitGroup
+	dropped int64
+}
+
+func newExporter(endpoint, serviceName string, headers map[string]string) *exporter {
+	url := strings.TrimRight(endpoint, "/")
+	if !strings.HasSuffix(url, "/v1/traces") {
+		url += "/v1/traces"
+	}
+	e := &exporter{
+		url:         url,
+		serviceName: serviceName,
+		headers:     headers,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		queue:       make(chan *Span, queueSize),
+		flush:       make(chan chan struct{}),
+		done:        make(chan struct{}),
+	}
+	e.stopped.Add(1)
+	go e.run()
+	return e
+}
+
+// enqueue drops spans rather than blocking the send pipeline when the
+// collector cannot keep up
+func (e *exporter) enqueue(span *Span) {
+	select {
+	case e.queue <- span:
+	default:
+		if atomic.AddInt64(&e.dropped, 1)%1000 == 1 {
+			logrus.Warnf("Trace export queue full, dropped %d spans so far", atomic.LoadInt64(&e.dropped))
+		}
+	}
+}
+
+func (e *exporter) run() {
+	defer e.stopped.Done()
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*Span, 0, maxBatchSize)
+	send := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := e.export(batch); err != nil {
+			logrus.Warnf("Failed to export %d spans: %v", len(batch), err)
+		}
+		batch = batch[:0]
+	}
+	drain := func() {
+		for {
+			select {
+			case span := <-e.queue:
+				batch = append(batch, span)
+				if len(batch) >= maxBatchSize {
+					send()
+				}
+			default:
+				return
+			}
+		}
+	}
+
+	for {
+		select {
+		case span := <-e.queue:
+			batch = append(batch, span)
+			if len(batch) >= maxBatchSize {
+				send()
+			}
+		case <-ticker.C:
+			send()
+		case ack := <-e.flush:
+			drain()
+			send()
+			close(ack)
+		case <-e.done:
+			drain()
+			send()
+			return
+		}
+	}
+}
+
+// forceFlush exports everything queued so far
+func (e *exporter) forceFlush(ctx context.Context) {
+	ack := make(chan struct{})
+	select {
+	case e.flush <- ack:
+	case <-ctx.Done():
+		return
+	}
+	select {
+	case <-ack:
+	case <-ctx.Done():
+	}
+}
+
+func (e *exporter) shutdown(ctx context.Context) {
+	close(e.done)
+	finished := make(chan struct{})
+	go func() {
+		e.stopped.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-ctx.Done():
+	}
+}
+
+func (e *exporter) export(spans []*Span) error {
+	body, err := json.Marshal(e.payload(spans))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range e.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+		return fmt.Errorf("collector returned %s: %s", resp.Status, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
+
+// OTLP JSON encoding, see opentelemetry-proto's trace/v1/trace.proto
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              Kind            `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            otlpStatus      `json:"status"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpAttribute struct {
+	Key   string                 `json:"key"`
+	Value map[string]interface{} `json:"value"`
+}
+
+func (e *exporter) payload(spans []*Span) otlpRequest {
+	encoded := make([]otlpSpan, 0, len(spans))
+	for _, span := range spans {
+		span.mu.Lock()
+		s := otlpSpan{
+			TraceID:           hex.EncodeToString(span.context.TraceID[:]),
+			SpanID:            hex.EncodeToString(span.context.SpanID[:]),
+			Name:              span.name,
+			Kind:              span.kind,
+			StartTimeUnixNano: strconv.FormatInt(span.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.end.UnixNano(), 10),
+			Attributes:        encodeAttributes(span.attributes),
+		}
+		if span.parent != (SpanID{}) {
+			s.ParentSpanID = hex.EncodeToString(span.parent[:])
+		}
+		if span.failed {
+			s.Status = otlpStatus{Code: 2, Message: span.errMessage}
+		}
+		span.mu.Unlock()
+		encoded = append(encoded, s)
+	}
+
+	return otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource:   otlpResource{Attributes: encodeAttributes([]Attribute{String("service.name", e.serviceName)})},
+		ScopeSpans: []otlpScopeSpans{{Scope: otlpScope{Name: scopeName}, Spans: encoded}},
+	}}}
+}
+
+func encodeAttributes(attributes []Attribute) []otlpAttribute {
+	encoded := make([]otlpAttribute, 0, len(attributes))
+	for _, attribute := range attributes {
+		var value map[string]interface{}
+		switch v := attribute.Value.(type) {
+		case string:
+			value = map[string]interface{}{"stringValue": v}
+		case int64:
+			value = map[string]interface{}{"intValue": strconv.FormatInt(v, 10)}
+		case bool:
+			value = map[string]interface{}{"boolValue": v}
+		case float64:
+			value = map[string]interface{}{"doubleValue": v}
+		default:
+			value = map[string]interface{}{"stringValue": fmt.Sprint(v)}
+		}
+		encoded = append(encoded, otlpAttribute{Key: attribute.Key, Value: value})
+	}
+	return encoded
+}
@@ -0,0 +1,57 @@
+package tracing
+
+import (
+	"sync"
+	"time"
+)
+
+// linkTTL is how long a sent message's span is remembered for its receipts
+const linkTTL = 24 * time.Hour
+
+// maxLinks bounds memory; the oldest links are forgotten first
+const maxLinks = 100000
+
+type link struct {
+	key       string
+	context   SpanContext
+	expiresAt time.Time
+}
+
+// links remembers the span that sent a WhatsApp message so that delivery
+// and read receipts, which arrive later on another goroutine, join its trace
+var links = struct {
+	sync.Mutex
+	byKey map[string]link
+	order []string
+}{byKey: make(map[string]link)}
+
+// Remember associates a key, e.g. a WhatsApp message ID, with a span context
+func Remember(key string, sc SpanContext) {
+	if key == "" || !sc.IsValid() {
+		return
+	}
+	links.Lock()
+	defer links.Unlock()
+
+	if _, exists := links.byKey[key]; !exists {
+		links.order = append(links.order, key)
+	}
+	links.byKey[key] = link{key: key, context: sc, expiresAt: time.Now().Add(linkTTL)}
+
+	for len(links.order) > maxLinks {
+		delete(links.byKey, links.order[0])
+		links.order = links.order[1:]
+	}
+}
+
+// Recall returns the span context remembered for a key
+func Recall(key string) (SpanContext, bool) {
+	links.Lock()
+	defer links.Unlock()
+
+	l, ok := links.byKey[key]
+	if !ok || time.Now().After(l.expiresAt) {
+		return SpanContext{}, false
+	}
+	return l.context, true
+}
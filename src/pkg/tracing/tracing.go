@@ -0,0 +1,281 @@
+// Package tracing records spans and exports them to an OpenTelemetry
+// collector over OTLP/HTTP with JSON encoding. Span contexts travel between
+// processes and through the message queue as W3C traceparent strings.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TraceID identifies a trace
+type TraceID [16]byte
+
+// SpanID identifies a span within a trace
+type SpanID [8]byte
+
+// SpanContext is the part of a span that is propagated to its children
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+}
+
+// IsValid reports whether the context has non-zero IDs
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != TraceID{} && sc.SpanID != SpanID{}
+}
+
+// Traceparent formats the context as a W3C traceparent header value, or
+// returns "" for an invalid context
+func (sc SpanContext) Traceparent() string {
+	if !sc.IsValid() {
+		return ""
+	}
+	return "00-" + hex.EncodeToString(sc.TraceID[:]) + "-" + hex.EncodeToString(sc.SpanID[:]) + "-01"
+}
+
+// ParseTraceparent parses a W3C traceparent header value
+func ParseTraceparent(value string) (SpanContext, bool) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return SpanContext{}, false
+	}
+	var sc SpanContext
+	if _, err := hex.Decode(sc.TraceID[:], []byte(parts[1])); err != nil {
+		return SpanContext{}, false
+	}
+	if _, err := hex.Decode(sc.SpanID[:], []byte(parts[2])); err != nil {
+		return SpanContext{}, false
+	}
+	return sc, sc.IsValid()
+}
+
+// Kind is the OTLP span kind
+type Kind int
+
+const (
+	KindInternal Kind = 1
+	KindServer   Kind = 2
+	KindClient   Kind = 3
+	KindProducer Kind = 4
+	KindConsumer Kind = 5
+)
+
+// Attribute is a span attribute
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// String returns a string attribute
+func String(key, value string) Attribute { return Attribute{key, value} }
+
+// Int returns an integer attribute
+func Int(key string, value int64) Attribute { return Attribute{key, value} }
+
+// Bool returns a boolean attribute
+func Bool(key string, value bool) Attribute { return Attribute{key, value} }
+
+// Float returns a floating point attribute
+func Float(key string, value float64) Attribute { return Attribute{key, value} }
+
+// Span is an operation in a trace. A nil span is valid and records nothing,
+// which is what Start returns while tracing is disabled.
+type Span struct {
+	name     string
+	kind     Kind
+	context  SpanContext
+	parent   SpanID
+	start    time.Time
+	exporter *exporter
+
+	mu         sync.Mutex
+	end        time.Time
+	attributes []Attribute
+	errMessage string
+	failed     bool
+	ended      bool
+}
+
+// Context returns the span's context, which is invalid for a nil span
+func (s *Span) Context() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.context
+}
+
+// Traceparent returns the span's context as a traceparent string
+func (s *Span) Traceparent() string {
+	return s.Context().Traceparent()
+}
+
+// SetAttributes adds attributes to the span
+func (s *Span) SetAttributes(attributes ...Attribute) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.attributes = append(s.attributes, attributes...)
+	s.mu.Unlock()
+}
+
+// RecordError marks the span as failed; nil errors are ignored
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	s.failed = true
+	s.errMessage = err.Error()
+	s.mu.Unlock()
+}
+
+// End finishes the span and queues it for export; later calls do nothing
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.end = time.Now()
+	s.mu.Unlock()
+	s.exporter.enqueue(s)
+}
+
+type spanKey struct{}
+type remoteKey struct{}
+
+// ContextWithSpan returns a context carrying the span as the parent of spans started from it
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// SpanFromContext returns the span carried by ctx, or nil
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// ContextWithTraceparent returns a context whose next span continues the
+// trace in a traceparent string, e.g. one stored with a queued message.
+// Invalid or empty values leave the context unchanged.
+func ContextWithTraceparent(ctx context.Context, traceparent string) context.Context {
+	sc, ok := ParseTraceparent(traceparent)
+	if !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, remoteKey{}, sc)
+}
+
+// parentOf returns the span context new spans in ctx are children of
+func parentOf(ctx context.Context) SpanContext {
+	if span := SpanFromContext(ctx); span != nil {
+		return span.context
+	}
+	sc, _ := ctx.Value(remoteKey{}).(SpanContext)
+	return sc
+}
+
+var (
+	globalMu       sync.RWMutex
+	globalExporter *exporter
+)
+
+// Enabled reports whether spans are being exported
+func Enabled() bool {
+	globalMu.RLock()
+	defer globalMu.RUnlock()
+	return globalExporter != nil
+}
+
+// Start begins a span that is a child of the span or traceparent carried by
+// ctx, or a new trace when there is neither. It returns nil while tracing is
+// disabled.
+func Start(ctx context.Context, name string, kind Kind, attributes ...Attribute) (context.Context, *Span) {
+	globalMu.RLock()
+	exp := globalExporter
+	globalMu.RUnlock()
+	if exp == nil {
+		return ctx, nil
+	}
+
+	parent := parentOf(ctx)
+	span := &Span{
+		name:       name,
+		kind:       kind,
+		start:      time.Now(),
+		exporter:   exp,
+		attributes: attributes,
+	}
+	if parent.IsValid() {
+		span.context.TraceID = parent.TraceID
+		span.parent = parent.SpanID
+	} else {
+		rand.Read(span.context.TraceID[:])
+	}
+	rand.Read(span.context.SpanID[:])
+	return ContextWithSpan(ctx, span), span
+}
+
+// ParseHeaders parses OTEL_EXPORTER_OTLP_HEADERS style "key=value,key=value"
+// lists, skipping malformed entries
+func ParseHeaders(value string) map[string]string {
+	headers := map[string]string{}
+	for _, pair := range strings.Split(value, ",") {
+		key, val, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			continue
+		}
+		headers[key] = strings.TrimSpace(val)
+	}
+	return headers
+}
+
+// Configure starts exporting spans to an OTLP/HTTP collector, e.g.
+// http://otel-collector:4318. An empty endpoint disables tracing.
+func Configure(endpoint, serviceName string, headers map[string]string) {
+	globalMu.Lock()
+	previous := globalExporter
+	globalExporter = nil
+	if endpoint != "" {
+		globalExporter = newExporter(endpoint, serviceName, headers)
+	}
+	globalMu.Unlock()
+
+	if previous != nil {
+		previous.shutdown(context.Background())
+	}
+}
+
+// Shutdown flushes pending spans and stops exporting
+func Shutdown(ctx context.Context) {
+	globalMu.Lock()
+	exp := globalExporter
+	globalExporter = nil
+	globalMu.Unlock()
+
+	if exp != nil {
+		exp.shutdown(ctx)
+	}
+}
+
+// Flush exports every span ended so far
+func Flush(ctx context.Context) {
+	globalMu.RLock()
+	exp := globalExporter
+	globalMu.RUnlock()
+
+	if exp != nil {
+		exp.forceFlush(ctx)
+	}
+}
@@ -0,0 +1,126 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		valid bool
+	}{
+		{"valid", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", true},
+		{"future version", "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-extra", true},
+		{"invalid version", "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false},
+		{"zero trace", "00-00000000000000000000000000000000-00f067aa0ba902b7-01", false},
+		{"short span", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa-01", false},
+		{"not hex", "00-4bf92f3577b34da6a3ce929d0e0e473z-00f067aa0ba902b7-01", false},
+		{"empty", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc, ok := ParseTraceparent(tt.value)
+			assert.Equal(t, tt.valid, ok)
+			if ok && tt.name == "valid" {
+				assert.Equal(t, tt.value, sc.Traceparent())
+			}
+		})
+	}
+}
+
+func TestDisabledTracingIsNoop(t *testing.T) {
+	Shutdown(context.Background())
+	ctx, span := Start(context.Background(), "noop", KindInternal)
+	assert.Nil(t, span)
+	assert.Equal(t, "", span.Traceparent())
+	span.SetAttributes(String("k", "v"))
+	span.RecordError(errors.New("ignored"))
+	span.End()
+	assert.Nil(t, SpanFromContext(ctx))
+}
+
+func TestExportFollowsQueuedMessage(t *testing.T) {
+	var mu sync.Mutex
+	var received []otlpRequest
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/traces", r.URL.Path)
+		assert.Equal(t, "secret", r.Header.Get("X-Api-Key"))
+		var req otlpRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		mu.Lock()
+		received = append(received, req)
+		mu.Unlock()
+	}))
+	defer collector.Close()
+
+	Configure(collector.URL, "test-service", map[string]string{"X-Api-Key": "secret"})
+	defer Shutdown(context.Background())
+
+	// The producer stores its span's traceparent with the message...
+	_, enqueue := Start(context.Background(), "message.enqueue", KindProducer, String("message.id", "m1"))
+	stored := enqueue.Traceparent()
+	enqueue.End()
+
+	// ...and the worker continues the trace from it
+	ctx := ContextWithTraceparent(context.Background(), stored)
+	ctx, process := Start(ctx, "message.process", KindConsumer, Int("retry", 2))
+	_, send := Start(ctx, "whatsapp.send", KindClient, Bool("rich", true), Float("size", 1.5))
+	send.RecordError(errors.New("timeout"))
+	send.End()
+	process.End()
+
+	flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	Flush(flushCtx)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, received, 1)
+	resource := received[0].ResourceSpans[0]
+	assert.Equal(t, "service.name", resource.Resource.Attributes[0].Key)
+	assert.Equal(t, "test-service", resource.Resource.Attributes[0].Value["stringValue"])
+
+	spans := resource.ScopeSpans[0].Spans
+	require.Len(t, spans, 3)
+	byName := map[string]otlpSpan{}
+	for _, span := range spans {
+		byName[span.Name] = span
+		assert.Equal(t, spans[0].TraceID, span.TraceID, "all spans share the trace")
+	}
+	assert.Empty(t, byName["message.enqueue"].ParentSpanID)
+	assert.Equal(t, byName["message.enqueue"].SpanID, byName["message.process"].ParentSpanID)
+	assert.Equal(t, byName["message.process"].SpanID, byName["whatsapp.send"].ParentSpanID)
+	assert.Equal(t, 2, byName["whatsapp.send"].Status.Code)
+	assert.Equal(t, "timeout", byName["whatsapp.send"].Status.Message)
+	assert.Equal(t, "2", byName["message.process"].Attributes[0].Value["intValue"])
+}
+
+func TestRememberAndRecall(t *testing.T) {
+	sc, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	Remember("3EB0ABC", sc)
+	Remember("", sc)
+	Remember("invalid", SpanContext{})
+
+	got, ok := Recall("3EB0ABC")
+	assert.True(t, ok)
+	assert.Equal(t, sc, got)
+	_, ok = Recall("invalid")
+	assert.False(t, ok)
+}
+
+func TestParseHeaders(t *testing.T) {
+	assert.Equal(t, map[string]string{"api-key": "abc", "x-team": "a=b"},
+		ParseHeaders(" api-key = abc ,invalid,=empty,x-team=a=b"))
+	assert.Empty(t, ParseHeaders(""))
+}
@@ -0,0 +1,150 @@
+package transcript
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// A4 page in points, and the layout of the text on it
+const (
+	pageWidth   = 595
+	pageHeight  = 842
+	pageMargin  = 50
+	bodySize    = 10
+	bodyLeading = 14
+	// bodyWidth is how many characters of body text fit across the page;
+	// Helvetica averages about half its size per character
+	bodyWidth = 90
+	// wrapIndent indents the continuation lines of a wrapped line
+	wrapIndent = 20
+)
+
+// pdfLine is one line of text placed on a page
+type pdfLine struct {
+	font    string // F1 is Helvetica, F2 Helvetica-Bold
+	size    int
+	leading int // distance from the line above
+	indent  int
+	text    string
+}
+
+// PDF writes the transcript as a PDF in the standard Helvetica font, so
+// nothing is embedded. The font covers Western European text; any other
+// character prints as "?".
+func PDF(w io.Writer, t Transcript) error {
+	lines := []pdfLine{
+		{font: "F2", size: 16, leading: 16, text: t.Title},
+		{font: "F1", size: 9, leading: 22, text: t.Subject},
+		{font: "F1", size: 9, leading: 12, text: fmt.Sprintf("%s to %s, generated %s",
+			t.From.Format(timeLayout), t.To.Format(timeLayout), t.Generated.Format(timeLayout))},
+	}
+	leading := 28 // the first body line keeps clear of the header
+	if len(t.Lines) == 0 {
+		lines = append(lines, pdfLine{font: "F1", size: bodySize, leading: leading, text: "Nothing happened in this period."})
+	}
+	for _, line := range t.Lines {
+		for i, text := range wrap(line.text(), bodyWidth) {
+			l := pdfLine{font: "F1", size: bodySize, leading: bodyLeading, text: text}
+			if i > 0 {
+				l.indent = wrapIndent
+			}
+			if leading > 0 {
+				l.leading, leading = leading, 0
+			}
+			lines = append(lines, l)
+		}
+	}
+
+	// Lay the lines out top to bottom, starting a page when one is full
+	var pages []string
+	var page strings.Builder
+	y := pageHeight - pageMargin
+	for _, line := range lines {
+		y -= line.leading
+		if y < pageMargin+bodyLeading {
+			pages = append(pages, page.String())
+			page.Reset()
+			y = pageHeight - pageMargin - line.size
+		}
+		fmt.Fprintf(&page, "BT /%s %d Tf %d %d Td %s Tj ET\n", line.font, line.size, pageMargin+line.indent, y,
+			pdfString(line.text))
+	}
+	pages = append(pages, page.String())
+
+	return writePDF(w, pages)
+}
+
+// writePDF writes a document whose pages have the given content streams.
+// Objects 1 to 4 are the catalog, page tree and two fonts; each page then
+// takes a page object and its content stream.
+func writePDF(w io.Writer, pages []string) error {
+	var buf bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	for i, content := range pages {
+		footer := fmt.Sprintf("BT /F1 8 Tf %d %d Td %s Tj ET\n", pageWidth-pageMargin-40, pageMargin/2,
+			pdfString(fmt.Sprintf("Page %d of %d", i+1, len(pages))))
+		content += footer
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>", pageWidth, pageHeight, 6+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", len(content), content))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// winAnsi maps the characters WinAnsiEncoding places in 0x80-0x9F
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87, 'ˆ': 0x88,
+	'‰': 0x89, 'Š': 0x8A, '‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E, '‘': 0x91, '’': 0x92, '“': 0x93,
+	'”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '˜': 0x98, '™': 0x99, 'š': 0x9A, '›': 0x9B,
+	'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
+
+// pdfString encodes text as a PDF literal string in WinAnsiEncoding
+func pdfString(text string) string {
+	var b strings.Builder
+	b.WriteByte('(')
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(byte(r))
+		case r == '\t':
+			b.WriteByte(' ')
+		case r >= 0x20 && r < 0x7F, r >= 0xA0 && r <= 0xFF:
+			b.WriteByte(byte(r))
+		default:
+			if c, ok := winAnsi[r]; ok {
+				b.WriteByte(c)
+			} else {
+				b.WriteByte('?')
+			}
+		}
+	}
+	b.WriteByte(')')
+	return b.String()
+}
@@ -0,0 +1,90 @@
+// Package transcript renders a conversation with a lead, and what happened
+// around it, as a document to hand over: HTML for the browser, or PDF.
+package transcript
+
+import (
+	"html/template"
+	"io"
+	"strings"
+	"time"
+)
+
+// Transcript is a conversation over a period
+type Transcript struct {
+	Title     string    `json:"title"`
+	Subject   string    `json:"subject"` // who the conversation is with, e.g. name and phone
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+	Generated time.Time `json:"generated"`
+	Lines     []Line    `json:"lines"`
+}
+
+// Line is a message, or an event between messages when Speaker is empty
+type Line struct {
+	At      time.Time `json:"at"`
+	Speaker string    `json:"speaker,omitempty"`
+	Text    string    `json:"text"`
+}
+
+// timeLayout formats every time in a rendered transcript
+const timeLayout = "2006-01-02 15:04"
+
+var htmlTemplate = template.Must(template.New("transcript").Funcs(template.FuncMap{
+	"time": func(t time.Time) string { return t.Format(timeLayout) },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; max-width: 800px; margin: 2em auto; color: #222; }
+.meta { color: #666; margin-bottom: 2em; }
+.line { margin: 0.4em 0; white-space: pre-wrap; }
+.at { color: #888; font-size: 0.85em; margin-right: 0.5em; }
+.speaker { font-weight: bold; }
+.event { color: #666; font-style: italic; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<div class="meta">{{.Subject}}<br>{{time .From}} to {{time .To}} &middot; generated {{time .Generated}}</div>
+{{range .Lines}}<div class="line{{if not .Speaker}} event{{end}}"><span class="at">{{time .At}}</span>{{if .Speaker}}<span class="speaker">{{.Speaker}}:</span> {{end}}{{.Text}}</div>
+{{else}}<p class="event">Nothing happened in this period.</p>
+{{end}}</body>
+</html>
+`))
+
+// HTML writes the transcript as a standalone HTML page
+func HTML(w io.Writer, t Transcript) error {
+	return htmlTemplate.Execute(w, t)
+}
+
+// text renders a line as the plain text the PDF prints
+func (l Line) text() string {
+	if l.Speaker == "" {
+		return l.At.Format(timeLayout) + "  * " + l.Text
+	}
+	return l.At.Format(timeLayout) + "  " + l.Speaker + ": " + l.Text
+}
+
+// wrap breaks text into lines of at most width characters, at spaces where
+// it can; line breaks in the text are kept
+func wrap(text string, width int) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		runes := []rune(strings.TrimRight(paragraph, " \r"))
+		for len(runes) > width {
+			cut := width
+			for i := width; i > width/2; i-- {
+				if runes[i] == ' ' {
+					cut = i
+					break
+				}
+			}
+			lines = append(lines, string(runes[:cut]))
+			runes = []rune(strings.TrimLeft(string(runes[cut:]), " "))
+		}
+		lines = append(lines, string(runes))
+	}
+	return lines
+}
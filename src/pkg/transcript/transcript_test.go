@@ -0,0 +1,77 @@
+package transcript
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sample(lines int) Transcript {
+	at := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	t := Transcript{Title: "Transcript", Subject: "Aisyah (60123456789)", From: at, To: at.AddDate(0, 0, 7), Generated: at}
+	for i := 0; i < lines; i++ {
+		t.Lines = append(t.Lines, Line{At: at.Add(time.Duration(i) * time.Minute), Speaker: "Aisyah", Text: fmt.Sprintf("message %d", i)})
+	}
+	return t
+}
+
+func TestWrap(t *testing.T) {
+	assert.Equal(t, []string{"one two", "three"}, wrap("one two three", 9))
+	assert.Equal(t, []string{"abcdefgh", "ij"}, wrap("abcdefghij", 8))
+	assert.Equal(t, []string{"first", "", "second"}, wrap("first\n\nsecond", 20))
+}
+
+func TestPDFString(t *testing.T) {
+	assert.Equal(t, `(a \(b\) \\ c)`, pdfString(`a (b) \ c`))
+	assert.Equal(t, "(caf\xe9 \x85 ?)", pdfString("café … 🙂"))
+}
+
+func TestPDF(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines int
+		pages int
+	}{
+		{"empty", 0, 1},
+		{"one page", 10, 1},
+		{"several pages", 120, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, PDF(&buf, sample(tt.lines)))
+			doc := buf.String()
+
+			assert.True(t, strings.HasPrefix(doc, "%PDF-1.4\n"))
+			assert.Contains(t, doc, fmt.Sprintf("/Count %d", tt.pages))
+
+			// Every cross-reference entry points at its object
+			start, err := strconv.Atoi(regexp.MustCompile(`startxref\n(\d+)`).FindStringSubmatch(doc)[1])
+			require.NoError(t, err)
+			require.True(t, strings.HasPrefix(doc[start:], "xref\n"))
+			entries := regexp.MustCompile(`(\d{10}) 00000 n`).FindAllStringSubmatch(doc[start:], -1)
+			assert.Len(t, entries, 4+2*tt.pages)
+			for i, entry := range entries {
+				offset, _ := strconv.Atoi(entry[1])
+				assert.True(t, strings.HasPrefix(doc[offset:], fmt.Sprintf("%d 0 obj", i+1)), "object %d", i+1)
+			}
+		})
+	}
+}
+
+func TestHTML(t *testing.T) {
+	tr := sample(1)
+	tr.Lines = append(tr.Lines, Line{At: tr.From, Text: "Status changed <prospect> to customer"})
+	var buf bytes.Buffer
+	require.NoError(t, HTML(&buf, tr))
+	assert.Contains(t, buf.String(), `<span class="speaker">Aisyah:</span> message 0`)
+	assert.Contains(t, buf.String(), `<div class="line event">`)
+	assert.Contains(t, buf.String(), "&lt;prospect&gt;")
+}
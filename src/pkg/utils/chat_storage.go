@@ -98,3 +98,74 @@ func RecordMessage(messageID string, senderJID string, messageContent string) er
 
 	return nil
 }
+
+// FindStoredRecords returns the stored messages whose JID matches
+func FindStoredRecords(match func(jid string) bool) ([]RecordedMessage, error) {
+	fileMutex.Lock()
+	defer fileMutex.Unlock()
+
+	records, err := readStoredRecords()
+	if err != nil {
+		return nil, err
+	}
+	var found []RecordedMessage
+	for _, record := range records {
+		if len(record) == 3 && match(record[1]) {
+			found = append(found, RecordedMessage{MessageID: record[0], JID: record[1], MessageContent: record[2]})
+		}
+	}
+	return found, nil
+}
+
+// EraseStoredRecords removes the stored messages whose JID matches and
+// returns how many were removed
+func EraseStoredRecords(match func(jid string) bool) (int, error) {
+	fileMutex.Lock()
+	defer fileMutex.Unlock()
+
+	records, err := readStoredRecords()
+	if err != nil {
+		return 0, err
+	}
+	kept := records[:0]
+	for _, record := range records {
+		if len(record) == 3 && match(record[1]) {
+			continue
+		}
+		kept = append(kept, record)
+	}
+	erased := len(records) - len(kept)
+	if erased == 0 {
+		return 0, nil
+	}
+
+	file, err := os.OpenFile(config.PathChatStorage, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open file for writing: %w", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.WriteAll(kept); err != nil {
+		return 0, fmt.Errorf("failed to write CSV records: %w", err)
+	}
+	return erased, nil
+}
+
+// readStoredRecords reads the chat storage file; the caller holds fileMutex
+func readStoredRecords() ([][]string, error) {
+	if _, err := os.Stat(config.PathChatStorage); os.IsNotExist(err) {
+		return nil, nil
+	}
+	file, err := os.Open(config.PathChatStorage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open storage file: %w", err)
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV records: %w", err)
+	}
+	return records, nil
+}
@@ -334,4 +334,37 @@ func saveAllMessageRecordsToCSV() error {
 	}
 	
 	return nil
-}
\ No newline at end of file
+}
+// FindMessageRecords returns the analytics records that match
+func FindMessageRecords(match func(MessageRecord) bool) []MessageRecord {
+	messageRecordsMux.RLock()
+	defer messageRecordsMux.RUnlock()
+
+	var found []MessageRecord
+	for _, record := range messageRecords {
+		if match(record) {
+			found = append(found, record)
+		}
+	}
+	return found
+}
+
+// EraseMessageRecords removes the analytics records that match, rewriting
+// the CSV file, and returns how many were removed
+func EraseMessageRecords(match func(MessageRecord) bool) (int, error) {
+	messageRecordsMux.Lock()
+	defer messageRecordsMux.Unlock()
+
+	kept := messageRecords[:0]
+	for _, record := range messageRecords {
+		if !match(record) {
+			kept = append(kept, record)
+		}
+	}
+	erased := len(messageRecords) - len(kept)
+	if erased == 0 {
+		return 0, nil
+	}
+	messageRecords = kept
+	return erased, saveAllMessageRecordsToCSV()
+}
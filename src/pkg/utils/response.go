@@ -5,4 +5,14 @@ type ResponseData struct {
 	Code    string `json:"code"`
 	Message string `json:"message"`
 	Results any    `json:"results,omitempty"`
+	Page    *Page  `json:"page,omitempty"`
+}
+
+// Page describes one page of a list endpoint. Results holds the items; pass
+// NextCursor back as the cursor parameter to fetch the next page.
+type Page struct {
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+	Total      int    `json:"total"`
 }
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/database"
+)
+
+// ErrLeaseLost is returned when a worker's claim on a message was reset and
+// possibly taken by another worker, so the worker must not send it
+var ErrLeaseLost = errors.New("message claim was taken over")
+
+// idempotencyColumnSchema adds the unique key that keeps a message from
+// being queued twice
+var idempotencyColumnSchema = database.RegisterSchema("broadcast_messages.idempotency_key",
+	database.AddColumn("broadcast_messages", "idempotency_key", "VARCHAR(191) NULL"),
+	database.AddUniqueIndex("broadcast_messages", "uniq_broadcast_idempotency_key", "idempotency_key"),
+)
+
+// releaseIdempotencyKey frees a key held by a message that failed or was
+// cancelled, so the send can be queued again as the duplicate checks allow.
+// It reports whether the key was freed; a key held by a message that is
+// live or sent is kept.
+func (r *BroadcastRepository) releaseIdempotencyKey(key string) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE broadcast_messages SET idempotency_key = NULL
+		WHERE idempotency_key = ? AND status NOT IN ('pending', 'queued', 'processing', 'sent')
+	`, key)
+	if err != nil {
+		return false, err
+	}
+	affected, _ := result.RowsAffected()
+	return affected > 0, nil
+}
+
+// ReserveWhatsAppMessageID records the WhatsApp message ID a claimed message
+// is sent with before the send, keeping the one an earlier attempt recorded,
+// and returns the ID to send with. A resend after a crash therefore carries
+// the same ID and WhatsApp drops it if the first send went out. The worker
+// must hold the claim leaseToken names; an empty token skips the check.
+func (r *BroadcastRepository) ReserveWhatsAppMessageID(messageID, leaseToken, candidate string) (string, error) {
+	_, err := r.db.Exec(`
+		UPDATE broadcast_messages SET whatsapp_message_id = COALESCE(whatsapp_message_id, ?)
+		WHERE id = ? AND status IN ('processing', 'queued') AND (? = '' OR processing_worker_id = ?)
+	`, candidate, messageID, leaseToken, leaseToken)
+	if err != nil {
+		return "", err
+	}
+
+	var status string
+	var owner, whatsappMessageID sql.NullString
+	err = r.db.QueryRow(`
+		SELECT status, processing_worker_id, whatsapp_message_id FROM broadcast_messages WHERE id = ?
+	`, messageID).Scan(&status, &owner, &whatsappMessageID)
+	if err == sql.ErrNoRows {
+		return "", ErrLeaseLost
+	}
+	if err != nil {
+		return "", err
+	}
+	if status != "processing" && status != "queued" {
+		return "", ErrLeaseLost
+	}
+	if leaseToken != "" && owner.String != leaseToken {
+		return "", ErrLeaseLost
+	}
+	return whatsappMessageID.String, nil
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"strings"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/database"
+)
+
+// Receipt types recorded against sent broadcast messages
+const (
+	ReceiptDelivered = "delivered"
+	ReceiptRead      = "read"
+)
+
+// receiptSchema adds the columns linking a sent message to its receipts
+var receiptSchema = database.RegisterSchema("broadcast_messages.receipts",
+	database.AddColumn("broadcast_messages", "whatsapp_message_id", "VARCHAR(64) NULL"),
+	database.AddColumn("broadcast_messages", "delivered_at", "TIMESTAMP NULL"),
+	database.AddColumn("broadcast_messages", "read_at", "TIMESTAMP NULL"),
+	database.AddIndex("broadcast_messages", "idx_broadcast_whatsapp_message", "whatsapp_message_id"),
+)
+
+// SaveWhatsAppMessageID records the ID WhatsApp gave a sent broadcast message
+// so its receipts can be matched to it
+func (r *BroadcastRepository) SaveWhatsAppMessageID(id, whatsappMessageID string) error {
+	_, err := r.db.Exec(`UPDATE broadcast_messages SET whatsapp_message_id = ? WHERE id = ?`, whatsappMessageID, id)
+	return err
+}
+
+// RecordReceipt stamps the broadcast messages WhatsApp sent with these IDs as
+// delivered or read. Only the first receipt of each type is kept, and a read
+// message counts as delivered.
+func (r *BroadcastRepository) RecordReceipt(whatsappMessageIDs []string, receipt string, at time.Time) error {
+	if len(whatsappMessageIDs) == 0 {
+		return nil
+	}
+	set := "delivered_at = COALESCE(delivered_at, ?)"
+	args := []any{at}
+	if receipt == ReceiptRead {
+		set += ", read_at = COALESCE(read_at, ?)"
+		args = append(args, at)
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(whatsappMessageIDs)), ", ")
+	for _, id := range whatsappMessageIDs {
+		args = append(args, id)
+	}
+	_, err := r.db.Exec(`UPDATE broadcast_messages SET `+set+` WHERE whatsapp_message_id IN (`+placeholders+`)`, args...)
+	return err
+}
@@ -3,10 +3,17 @@ package repository
 import (
 	"database/sql"
 	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/database"
 	domainBroadcast "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/broadcast"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/clock"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/listing"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
@@ -17,6 +24,44 @@ type BroadcastRepository struct {
 
 var broadcastRepo *BroadcastRepository
 
+// MessageSentHook is called after a broadcast message is confirmed sent
+type MessageSentHook func(messageID string)
+
+var (
+	messageSentHooks        []MessageSentHook
+	messageSentHooksMu      sync.RWMutex
+	messageSentHooksRunning int64
+)
+
+// OnMessageSent registers a hook that runs after every confirmed send
+func OnMessageSent(hook MessageSentHook) {
+	messageSentHooksMu.Lock()
+	defer messageSentHooksMu.Unlock()
+	messageSentHooks = append(messageSentHooks, hook)
+}
+
+// notifyMessageSent runs the registered hooks in the background so a slow
+// hook never delays the worker that sent the message
+func notifyMessageSent(messageID string) {
+	messageSentHooksMu.RLock()
+	hooks := make([]MessageSentHook, len(messageSentHooks))
+	copy(hooks, messageSentHooks)
+	messageSentHooksMu.RUnlock()
+
+	for _, hook := range hooks {
+		atomic.AddInt64(&messageSentHooksRunning, 1)
+		go func(hook MessageSentHook) {
+			defer atomic.AddInt64(&messageSentHooksRunning, -1)
+			hook(messageID)
+		}(hook)
+	}
+}
+
+// MessageSentHooksRunning returns the number of sent hooks still running
+func MessageSentHooksRunning() int64 {
+	return atomic.LoadInt64(&messageSentHooksRunning)
+}
+
 // GetBroadcastRepository returns broadcast repository instance
 func GetBroadcastRepository() *BroadcastRepository {
 	if broadcastRepo == nil {
@@ -24,17 +69,47 @@ func GetBroadcastRepository() *BroadcastRepository {
 			db: database.GetDB(),
 		}
 	}
+	sendErrorSchema.Ensure(broadcastRepo.db)
+	messagePayloadSchema.Ensure(broadcastRepo.db)
+	traceContextSchema.Ensure(broadcastRepo.db)
+	receiptSchema.Ensure(broadcastRepo.db)
+	idempotencyColumnSchema.Ensure(broadcastRepo.db)
 	return broadcastRepo
 }
 
+// sendErrorSchema adds the typed failure columns
+var sendErrorSchema = database.RegisterSchema("broadcast_messages.send_errors",
+	database.AddColumn("broadcast_messages", "error_code", "VARCHAR(40) NULL"),
+	database.AddColumn("broadcast_messages", "retry_count", "INT NOT NULL DEFAULT 0"),
+)
+
+// traceContextSchema adds the column that carries a queued message's
+// trace from the producer to the worker that sends it
+var traceContextSchema = database.RegisterSchema("broadcast_messages.trace_context",
+	database.AddColumn("broadcast_messages", "trace_context", "VARCHAR(64) NULL"),
+)
+
+// messagePayloadSchema adds the columns carrying type-specific message
+// fields to every table that feeds the broadcast queue
+var messagePayloadSchema = database.RegisterSchema("message_payload",
+	database.AddColumn("broadcast_messages", "message_payload", "TEXT NULL"),
+	database.AddColumn("sequence_steps", "message_payload", "TEXT NULL"),
+	database.AddColumn("campaigns", "message_type", "VARCHAR(20) NULL"),
+	database.AddColumn("campaigns", "message_payload", "TEXT NULL"),
+)
+
 // QueueMessage adds a message to the queue
 func (r *BroadcastRepository) QueueMessage(msg domainBroadcast.BroadcastMessage) error {
 	if msg.ID == "" {
 		msg.ID = uuid.New().String()
 	}
+	if msg.IdempotencyKey == "" {
+		msg.IdempotencyKey = domainBroadcast.IdempotencyKey(msg)
+	}
 	
 	// ISSUE 3 FIX: Check for duplicates before inserting
 	// For SEQUENCES: Check based on sequence_stepid, recipient_phone, and device_id
+	// within the contact's current run
 	if msg.SequenceStepID != nil && *msg.SequenceStepID != "" {
 		duplicateCheck := `
 			SELECT COUNT(*) 
@@ -43,10 +118,11 @@ func (r *BroadcastRepository) QueueMessage(msg domainBroadcast.BroadcastMessage)
 			AND recipient_phone = ? 
 			AND device_id = ?
 			AND status IN ('pending', 'sent', 'queued', 'processing')
+			AND created_at >= ?
 		`
 		
 		var count int
-		err := r.db.QueryRow(duplicateCheck, *msg.SequenceStepID, msg.RecipientPhone, msg.DeviceID).Scan(&count)
+		err := r.db.QueryRow(duplicateCheck, *msg.SequenceStepID, msg.RecipientPhone, msg.DeviceID, msg.EnrolledAt).Scan(&count)
 		if err != nil {
 			logrus.Warnf("Error checking sequence duplicates: %v", err)
 		} else if count > 0 {
@@ -78,10 +154,14 @@ func (r *BroadcastRepository) QueueMessage(msg domainBroadcast.BroadcastMessage)
 		}
 	}
 	
+	// The unique idempotency key stops a message queued twice at once, which
+	// the checks above miss. The no-op update skips only a duplicate key;
+	// other bad values still fail the insert.
 	query := `
 		INSERT INTO broadcast_messages(id, user_id, device_id, device_name, campaign_id, sequence_id, sequence_stepid, recipient_phone, recipient_name,
-		 message_type, content, media_url, status, scheduled_at, created_at, group_id, group_order)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		 message_type, content, media_url, message_payload, status, scheduled_at, created_at, group_id, group_order, trace_context, idempotency_key)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE id = id
 	`
 	// Get user_id and device_name from user_devices table
 	var userID, deviceName string
@@ -157,11 +237,34 @@ func (r *BroadcastRepository) QueueMessage(msg domainBroadcast.BroadcastMessage)
 		groupOrder = nil
 	}
 	
-	_, err := r.db.Exec(query, msg.ID, userID, msg.DeviceID, deviceName, campaignID,
-		sequenceID, sequenceStepID, msg.RecipientPhone, msg.RecipientName, msg.Type, msg.Content,
-		msg.MediaURL, "pending", msg.ScheduledAt, time.Now(), groupID, groupOrder)
+	insert := func() (bool, error) {
+		result, err := r.db.Exec(query, msg.ID, userID, msg.DeviceID, deviceName, campaignID,
+			sequenceID, sequenceStepID, msg.RecipientPhone, msg.RecipientName, msg.Type, msg.Content,
+			msg.MediaURL, domainBroadcast.EncodePayload(msg.Payload), "pending", msg.ScheduledAt, clock.Now(), groupID, groupOrder,
+			sql.NullString{String: msg.TraceContext, Valid: msg.TraceContext != ""},
+			sql.NullString{String: msg.IdempotencyKey, Valid: msg.IdempotencyKey != ""})
+		if err != nil {
+			return false, err
+		}
+		affected, _ := result.RowsAffected()
+		return affected > 0, nil
+	}
 
-	return err
+	inserted, err := insert()
+	if err != nil || inserted || msg.IdempotencyKey == "" {
+		return err
+	}
+	freed, err := r.releaseIdempotencyKey(msg.IdempotencyKey)
+	if err != nil {
+		return err
+	}
+	if freed {
+		_, err = insert()
+		return err
+	}
+	logrus.Infof("Skipping duplicate message for %s - idempotency key %s already queued or sent",
+		msg.RecipientPhone, msg.IdempotencyKey)
+	return nil
 }
 
 // GetPendingMessages gets pending messages for a device with campaign/sequence delays
@@ -169,7 +272,7 @@ func (r *BroadcastRepository) GetPendingMessages(deviceID string, limit int) ([]
 	query := `
 		SELECT bm.id, bm.user_id, bm.device_id, bm.device_name, bm.campaign_id, bm.sequence_id,
 			bm.recipient_phone, bm.recipient_name, bm.message_type, bm.content AS message, bm.media_url,
-			bm.scheduled_at, bm.group_id, bm.group_order,
+			bm.message_payload, bm.scheduled_at, bm.group_id, bm.group_order,
 			COALESCE(
 				c.min_delay_seconds,
 				ss.min_delay_seconds,
@@ -188,6 +291,7 @@ func (r *BroadcastRepository) GetPendingMessages(deviceID string, limit int) ([]
 		LEFT JOIN sequence_steps ss ON bm.sequence_stepid = ss.id
 		WHERE bm.device_id = ?
 		AND bm.status = 'pending'
+		AND (c.id IS NULL OR c.status NOT IN ('paused', 'cancelled'))
 		AND bm.scheduled_at IS NOT NULL
 		AND bm.scheduled_at <= ?
 		AND bm.scheduled_at >= DATE_SUB(?, INTERVAL 3 HOUR)
@@ -206,12 +310,12 @@ func (r *BroadcastRepository) GetPendingMessages(deviceID string, limit int) ([]
 		var msg domainBroadcast.BroadcastMessage
 		var userID, deviceName sql.NullString
 		var campaignID sql.NullInt64
-		var sequenceID, groupID sql.NullString
+		var sequenceID, groupID, payload sql.NullString
 		var groupOrder sql.NullInt64
 		var scheduledAt sql.NullTime
 
 		err := rows.Scan(&msg.ID, &userID, &msg.DeviceID, &deviceName, &campaignID, &sequenceID,
-			&msg.RecipientPhone, &msg.RecipientName, &msg.Type, &msg.Content, &msg.MediaURL, &scheduledAt,
+			&msg.RecipientPhone, &msg.RecipientName, &msg.Type, &msg.Content, &msg.MediaURL, &payload, &scheduledAt,
 			&groupID, &groupOrder, &msg.MinDelay, &msg.MaxDelay)
 		if err != nil {
 			continue
@@ -245,6 +349,7 @@ func (r *BroadcastRepository) GetPendingMessages(deviceID string, limit int) ([]
 		// Set ImageURL for backward compatibility
 		msg.ImageURL = msg.MediaURL
 		msg.Message = msg.Content
+		msg.Payload = domainBroadcast.DecodePayload(payload.String)
 		
 		messages = append(messages, msg)
 	}
@@ -252,17 +357,24 @@ func (r *BroadcastRepository) GetPendingMessages(deviceID string, limit int) ([]
 	return messages, nil
 }
 
-// UpdateMessageStatus updates message status
+// UpdateMessageStatus updates message status. Failed messages get an error
+// code classified from errorMsg; use MarkMessageFailed when a typed error is at hand.
 func (r *BroadcastRepository) UpdateMessageStatus(messageID, status, errorMsg string) error {
 	query := `
 		UPDATE broadcast_messages SET status = ?, 
 		    error_message = ?, 
+		    error_code = ?,
 		    sent_at = CASE WHEN ? = 'sent' THEN NOW() ELSE sent_at END,
 		    updated_at = NOW()
 		WHERE id = ?
 	`
 	
-	result, err := r.db.Exec(query, status, errorMsg, status, messageID)
+	var errorCode sql.NullString
+	if status == "failed" {
+		errorCode = sql.NullString{String: string(pkgError.ClassifySendError(errorMsg)), Valid: true}
+	}
+	
+	result, err := r.db.Exec(query, status, errorMsg, errorCode, status, messageID)
 	if err != nil {
 		logrus.Errorf("Failed to update message status: %v", err)
 		return err
@@ -273,11 +385,166 @@ func (r *BroadcastRepository) UpdateMessageStatus(messageID, status, errorMsg st
 		logrus.Warnf("No rows updated for message ID: %s", messageID)
 	} else {
 		logrus.Infof("Updated message %s status to %s", messageID, status)
+		if status == "sent" {
+			notifyMessageSent(messageID)
+		}
+		if status == "failed" {
+			r.recordDeadLetter(messageID, errorMsg, pkgError.SendErrorCode(errorCode.String), domainBroadcast.DeadLetterSourceSQL, 0)
+		}
 	}
 	
 	return nil
 }
 
+// MarkMessageSent marks an in-flight message as sent and notifies sent hooks.
+// It returns false when the message was not in a sendable state.
+func (r *BroadcastRepository) MarkMessageSent(messageID string) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE broadcast_messages 
+		SET status = 'sent', sent_at = NOW(), updated_at = NOW() 
+		WHERE id = ? AND status IN ('processing', 'pending', 'queued')
+	`, messageID)
+	if err != nil {
+		return false, err
+	}
+	
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return false, nil
+	}
+	
+	notifyMessageSent(messageID)
+	return true, nil
+}
+
+// HoldStoppedCampaignMessage keeps a claimed message from going out when its
+// campaign was paused or cancelled after the claim. A paused campaign's
+// message goes back to pending for the resume; a cancelled one's is marked
+// cancelled. It reports whether the message was held.
+func (r *BroadcastRepository) HoldStoppedCampaignMessage(messageID string) (bool, error) {
+	var status string
+	err := r.db.QueryRow(`
+		SELECT c.status FROM broadcast_messages bm
+		JOIN campaigns c ON c.id = bm.campaign_id
+		WHERE bm.id = ?
+	`, messageID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	switch status {
+	case "paused":
+		_, err = r.db.Exec(`
+			UPDATE broadcast_messages
+			SET status = 'pending', processing_worker_id = NULL, processing_started_at = NULL, updated_at = NOW()
+			WHERE id = ? AND status IN ('processing', 'queued')
+		`, messageID)
+	case "cancelled":
+		_, err = r.db.Exec(`
+			UPDATE broadcast_messages
+			SET status = 'cancelled', error_message = 'Campaign cancelled', processing_worker_id = NULL, updated_at = NOW()
+			WHERE id = ? AND status IN ('pending', 'processing', 'queued')
+		`, messageID)
+	default:
+		return false, nil
+	}
+	return true, err
+}
+
+// RequeueMessages returns messages handed to workers but never sent to
+// pending, so the next claim picks them up. It returns how many it moved.
+func (r *BroadcastRepository) RequeueMessages(messageIDs []string) (int64, error) {
+	if len(messageIDs) == 0 {
+		return 0, nil
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(messageIDs)), ", ")
+	args := make([]interface{}, len(messageIDs))
+	for i, id := range messageIDs {
+		args[i] = id
+	}
+	result, err := r.db.Exec(`
+		UPDATE broadcast_messages
+		SET status = 'pending', processing_worker_id = NULL, processing_started_at = NULL, updated_at = NOW()
+		WHERE id IN (`+placeholders+`) AND status IN ('processing', 'queued')
+	`, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// MarkMessageFailed marks a message as failed with the send error's code and
+// records it in the dead letter store
+func (r *BroadcastRepository) MarkMessageFailed(messageID string, sendErr error, source string, retries int) error {
+	errorMsg := ""
+	if sendErr != nil {
+		errorMsg = sendErr.Error()
+	}
+	code := pkgError.SendErrorCodeOf(sendErr)
+	if code == "" {
+		code = pkgError.SendErrUnknown
+	}
+	
+	_, err := r.db.Exec(`
+		UPDATE broadcast_messages 
+		SET status = 'failed', error_message = ?, error_code = ?, 
+		    retry_count = GREATEST(retry_count, ?), updated_at = NOW() 
+		WHERE id = ?
+	`, errorMsg, string(code), retries, messageID)
+	if err != nil {
+		return err
+	}
+	
+	r.recordDeadLetter(messageID, errorMsg, code, source, retries)
+	return nil
+}
+
+// RetryOrFailMessage applies the retry policy of the send error's class: the
+// message is rescheduled with backoff while retries remain, otherwise it is
+// marked failed. It returns true when the message was rescheduled.
+func (r *BroadcastRepository) RetryOrFailMessage(messageID string, sendErr error, source string) (bool, error) {
+	var retries int
+	if err := r.db.QueryRow(`SELECT retry_count FROM broadcast_messages WHERE id = ?`, messageID).Scan(&retries); err != nil {
+		return false, err
+	}
+	
+	code := pkgError.SendErrorCodeOf(sendErr)
+	policy := pkgError.RetryPolicyFor(code)
+	if !policy.ShouldRetry(retries) {
+		return false, r.MarkMessageFailed(messageID, sendErr, source, retries)
+	}
+	
+	backoff := policy.Backoff(retries + 1)
+	result, err := r.db.Exec(`
+		UPDATE broadcast_messages 
+		SET status = 'pending', processing_worker_id = NULL, error_message = ?, error_code = ?,
+		    retry_count = retry_count + 1,
+		    scheduled_at = DATE_ADD(DATE_ADD(NOW(), INTERVAL 8 HOUR), INTERVAL ? SECOND),
+		    updated_at = NOW()
+		WHERE id = ? AND status IN ('processing', 'pending', 'queued')
+	`, sendErr.Error(), string(code), int(backoff.Seconds()), messageID)
+	if err != nil {
+		return false, err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return false, nil
+	}
+	
+	logrus.Infof("Message %s failed with %s, retry %d/%d in %v", messageID, code, retries+1, policy.MaxRetries, backoff)
+	return true, nil
+}
+
+// recordDeadLetter stores a failed message; failures are logged since the
+// periodic sync picks up anything missed here
+func (r *BroadcastRepository) recordDeadLetter(messageID, errorMsg string, code pkgError.SendErrorCode, source string, retries int) {
+	if err := GetDeadLetterRepository().Record(messageID, errorMsg, code, source, retries); err != nil {
+		logrus.Warnf("Failed to record dead letter for message %s: %v", messageID, err)
+	}
+}
+
 // GetBroadcastStats gets broadcast statistics
 func (r *BroadcastRepository) GetBroadcastStats(deviceID string) (map[string]interface{}, error) {
 	stats := make(map[string]interface{})
@@ -311,6 +578,104 @@ func (r *BroadcastRepository) GetBroadcastStats(deviceID string) (map[string]int
 	return stats, nil
 }
 
+// BroadcastMessageListFields are the sort, filter and search fields of the broadcast message list
+var BroadcastMessageListFields = listing.Fields{
+	Columns: map[string]string{
+		"id":              "id",
+		"device_id":       "device_id",
+		"campaign_id":     "campaign_id",
+		"sequence_id":     "sequence_id",
+		"recipient_phone": "recipient_phone",
+		"recipient_name":  "COALESCE(recipient_name, '')",
+		"message_type":    "message_type",
+		"content":         "COALESCE(content, '')",
+		"status":          "status",
+		"scheduled_at":    "COALESCE(scheduled_at, '')",
+		"sent_at":         "COALESCE(sent_at, '')",
+		"created_at":      "created_at",
+	},
+	Sort:        []string{"recipient_phone", "status", "scheduled_at", "sent_at", "created_at"},
+	Filter:      []string{"device_id", "campaign_id", "sequence_id", "message_type", "status"},
+	Search:      []string{"recipient_phone", "recipient_name", "content"},
+	Key:         "id",
+	DefaultSort: "-created_at",
+}
+
+// ListMessages returns one page of a user's broadcast messages
+func (r *BroadcastRepository) ListMessages(userID string, q listing.Query) ([]domainBroadcast.MessageRecord, utils.Page, error) {
+	where, args := q.Where()
+	var total int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM broadcast_messages WHERE user_id = ?`+where, append([]any{userID}, args...)...).Scan(&total)
+	if err != nil {
+		return nil, utils.Page{}, err
+	}
+	
+	seek, args := q.Seek()
+	rows, err := r.db.Query(`
+		SELECT id, device_id, COALESCE(device_name, ''), campaign_id, sequence_id, sequence_stepid,
+			recipient_phone, COALESCE(recipient_name, ''), message_type, COALESCE(content, ''), status,
+			COALESCE(error_message, ''), scheduled_at, sent_at, created_at
+		FROM broadcast_messages
+		WHERE user_id = ?`+seek, append([]any{userID}, args...)...)
+	if err != nil {
+		return nil, utils.Page{}, err
+	}
+	defer rows.Close()
+	
+	var messages []domainBroadcast.MessageRecord
+	for rows.Next() {
+		var msg domainBroadcast.MessageRecord
+		var campaignID sql.NullInt64
+		var sequenceID, stepID sql.NullString
+		var scheduledAt, sentAt sql.NullTime
+		if err := rows.Scan(&msg.ID, &msg.DeviceID, &msg.DeviceName, &campaignID, &sequenceID, &stepID,
+			&msg.RecipientPhone, &msg.RecipientName, &msg.MessageType, &msg.Content, &msg.Status,
+			&msg.ErrorMessage, &scheduledAt, &sentAt, &msg.CreatedAt); err != nil {
+			return nil, utils.Page{}, err
+		}
+		if campaignID.Valid {
+			id := int(campaignID.Int64)
+			msg.CampaignID = &id
+		}
+		if sequenceID.Valid {
+			msg.SequenceID = &sequenceID.String
+		}
+		if stepID.Valid {
+			msg.SequenceStepID = &stepID.String
+		}
+		if scheduledAt.Valid {
+			msg.ScheduledAt = &scheduledAt.Time
+		}
+		if sentAt.Valid {
+			msg.SentAt = &sentAt.Time
+		}
+		messages = append(messages, msg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, utils.Page{}, err
+	}
+	
+	messages, page := listing.Trim(messages, q, total, broadcastMessageValue)
+	return messages, page, nil
+}
+
+// broadcastMessageValue returns a message's value of a BroadcastMessageListFields field
+func broadcastMessageValue(msg domainBroadcast.MessageRecord, field string) any {
+	switch field {
+	case "recipient_phone":
+		return msg.RecipientPhone
+	case "status":
+		return msg.Status
+	case "scheduled_at":
+		return msg.ScheduledAt
+	case "sent_at":
+		return msg.SentAt
+	case "created_at":
+		return msg.CreatedAt
+	}
+	return msg.ID
+}
+
 // GetUserBroadcastStats gets broadcast statistics for a user
 func (r *BroadcastRepository) GetUserBroadcastStats(userID string) (map[string]interface{}, error) {
 	query := `
@@ -349,7 +714,7 @@ func max(a, b int) int {
 func (r *BroadcastRepository) GetAllPendingMessages(limit int) ([]domainBroadcast.BroadcastMessage, error) {
 	query := `
 		SELECT id, user_id, device_id, device_name, campaign_id, sequence_id, recipient_phone,
-		       recipient_name, message_type, content, media_url, status, scheduled_at,
+		       recipient_name, message_type, content, media_url, message_payload, status, scheduled_at,
 		       created_at, group_id, group_order
 		FROM broadcast_messages
 		WHERE status = 'pending'
@@ -371,12 +736,13 @@ func (r *BroadcastRepository) GetAllPendingMessages(limit int) ([]domainBroadcas
 		var campaignID sql.NullInt64
 		var sequenceID sql.NullString
 		var recipientName sql.NullString
+		var payload sql.NullString
 		var scheduledAt sql.NullTime
 		var groupID sql.NullString
 		var groupOrder sql.NullInt64
 
 		err := rows.Scan(&msg.ID, &msg.UserID, &msg.DeviceID, &deviceName, &campaignID, &sequenceID,
-			&msg.RecipientPhone, &recipientName, &msg.Type, &msg.Content, &msg.MediaURL,
+			&msg.RecipientPhone, &recipientName, &msg.Type, &msg.Content, &msg.MediaURL, &payload,
 			&msg.Status, &scheduledAt, &msg.CreatedAt, &groupID, &groupOrder)
 		if err != nil {
 			continue
@@ -413,6 +779,7 @@ func (r *BroadcastRepository) GetAllPendingMessages(limit int) ([]domainBroadcas
 		// Set ImageURL for backward compatibility
 		msg.ImageURL = msg.MediaURL
 		msg.Message = msg.Content
+		msg.Payload = domainBroadcast.DecodePayload(payload.String)
 		
 		messages = append(messages, msg)
 	}
@@ -506,6 +873,7 @@ func (r *BroadcastRepository) GetPendingMessagesAndLock(deviceID string, limit i
 		AND processing_worker_id IS NULL
 		AND scheduled_at IS NOT NULL
 		AND scheduled_at <= DATE_ADD(NOW(), INTERVAL 8 HOUR)
+		AND (campaign_id IS NULL OR campaign_id NOT IN (SELECT id FROM campaigns WHERE status IN ('paused', 'cancelled')))
 		ORDER BY scheduled_at ASC, group_id, group_order
 		LIMIT ?
 	`, workerID, deviceID, limit)
@@ -525,8 +893,13 @@ func (r *BroadcastRepository) GetPendingMessagesAndLock(deviceID string, limit i
 	// STEP 2: Fetch the messages we just claimed
 	query := `
 		SELECT bm.id, bm.user_id, bm.device_id, bm.device_name, bm.campaign_id, bm.sequence_id,
-			bm.recipient_phone, bm.recipient_name, bm.message_type, bm.content AS message, bm.media_url,
-			bm.scheduled_at, bm.group_id, bm.group_order, bm.sequence_stepid,
+			bm.recipient_phone, bm.recipient_name,
+			-- Sequence steps are read at claim time so step edits apply to rows already queued
+			CASE WHEN ss.id IS NOT NULL THEN COALESCE(ss.message_type, bm.message_type) ELSE bm.message_type END AS message_type,
+			CASE WHEN ss.id IS NOT NULL THEN COALESCE(ss.content, bm.content) ELSE bm.content END AS message,
+			CASE WHEN ss.id IS NOT NULL THEN COALESCE(ss.media_url, '') ELSE bm.media_url END AS media_url,
+			CASE WHEN ss.id IS NOT NULL THEN ss.message_payload ELSE bm.message_payload END AS message_payload,
+			bm.scheduled_at, bm.group_id, bm.group_order, bm.sequence_stepid, bm.trace_context, bm.whatsapp_message_id,
 			COALESCE(
 				c.min_delay_seconds,
 				ss.min_delay_seconds,
@@ -559,13 +932,13 @@ func (r *BroadcastRepository) GetPendingMessagesAndLock(deviceID string, limit i
 		var msg domainBroadcast.BroadcastMessage
 		var userID, deviceName sql.NullString
 		var campaignID sql.NullInt64
-		var sequenceID, groupID, sequenceStepID sql.NullString
+		var sequenceID, groupID, sequenceStepID, payload, traceContext, whatsappMessageID sql.NullString
 		var groupOrder sql.NullInt64
 		var scheduledAt sql.NullTime
 
 		err := rows.Scan(&msg.ID, &userID, &msg.DeviceID, &deviceName, &campaignID, &sequenceID,
-			&msg.RecipientPhone, &msg.RecipientName, &msg.Type, &msg.Content, &msg.MediaURL, &scheduledAt,
-			&groupID, &groupOrder, &sequenceStepID, &msg.MinDelay, &msg.MaxDelay)
+			&msg.RecipientPhone, &msg.RecipientName, &msg.Type, &msg.Content, &msg.MediaURL, &payload, &scheduledAt,
+			&groupID, &groupOrder, &sequenceStepID, &traceContext, &whatsappMessageID, &msg.MinDelay, &msg.MaxDelay)
 		if err != nil {
 			continue
 		}
@@ -601,6 +974,10 @@ func (r *BroadcastRepository) GetPendingMessagesAndLock(deviceID string, limit i
 		// Set ImageURL for backward compatibility
 		msg.ImageURL = msg.MediaURL
 		msg.Message = msg.Content
+		msg.Payload = domainBroadcast.DecodePayload(payload.String)
+		msg.TraceContext = traceContext.String
+		msg.WhatsAppMessageID = whatsappMessageID.String
+		msg.LeaseToken = workerID
 		
 		messages = append(messages, msg)
 	}
@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/database"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/models"
+)
+
+// Campaign lifecycle actions recorded in campaign_events
+const (
+	CampaignActionPause    = "pause"
+	CampaignActionResume   = "resume"
+	CampaignActionCancel   = "cancel"
+	CampaignActionThrottle = "throttle"
+	CampaignActionStatus   = "status"
+)
+
+// CampaignActorSystem is the actor of transitions no user asked for
+const CampaignActorSystem = "system"
+
+// ErrCampaignTransition is returned when a campaign's status does not allow a control
+var ErrCampaignTransition = errors.New("campaign status does not allow this change")
+
+// campaignEventsSchema creates the campaign audit table
+var campaignEventsSchema = database.RegisterSchema("campaign_events", database.Statement(`
+			CREATE TABLE IF NOT EXISTS campaign_events (
+				id BIGINT AUTO_INCREMENT PRIMARY KEY,
+				campaign_id INT NOT NULL,
+				actor VARCHAR(255) NOT NULL,
+				action VARCHAR(20) NOT NULL,
+				from_status VARCHAR(50) NOT NULL DEFAULT '',
+				to_status VARCHAR(50) NOT NULL DEFAULT '',
+				reason VARCHAR(500) NOT NULL DEFAULT '',
+				min_delay_seconds INT NULL,
+				max_delay_seconds INT NULL,
+				messages INT NOT NULL DEFAULT 0,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				INDEX idx_campaign_events_campaign (campaign_id, created_at)
+			)
+		`))
+
+// campaignLiveStatuses are the statuses of campaigns that still have messages to send
+var campaignLiveStatuses = []string{"pending", "triggered", "processing"}
+
+// PauseCampaign stops workers from claiming the campaign's messages. Messages
+// already claimed are returned to pending by the worker before they are sent.
+func (r *campaignRepository) PauseCampaign(id int, actor string) (*models.CampaignEvent, error) {
+	event := models.CampaignEvent{Action: CampaignActionPause, Actor: actor}
+	return r.transitionCampaign(id, &event, campaignLiveStatuses, func(tx *sql.Tx) error {
+		event.ToStatus = "paused"
+		return countPendingMessages(tx, id, &event.Messages)
+	})
+}
+
+// ResumeCampaign lets workers claim a paused campaign's messages again. A
+// campaign paused before it was triggered goes back to waiting for its schedule.
+func (r *campaignRepository) ResumeCampaign(id int, actor string) (*models.CampaignEvent, error) {
+	event := models.CampaignEvent{Action: CampaignActionResume, Actor: actor}
+	return r.transitionCampaign(id, &event, []string{"paused"}, func(tx *sql.Tx) error {
+		var queued int
+		if err := tx.QueryRow(`SELECT COUNT(*) FROM broadcast_messages WHERE campaign_id = ?`, id).Scan(&queued); err != nil {
+			return err
+		}
+		event.ToStatus = "pending"
+		if queued > 0 {
+			event.ToStatus = "triggered"
+		}
+		return countPendingMessages(tx, id, &event.Messages)
+	})
+}
+
+// CancelCampaign stops a campaign for good and marks its unsent messages
+// cancelled with the reason
+func (r *campaignRepository) CancelCampaign(id int, actor, reason string) (*models.CampaignEvent, error) {
+	event := models.CampaignEvent{Action: CampaignActionCancel, Actor: actor, Reason: reason}
+	allowed := append(slices.Clone(campaignLiveStatuses), "paused")
+	return r.transitionCampaign(id, &event, allowed, func(tx *sql.Tx) error {
+		event.ToStatus = "cancelled"
+		result, err := tx.Exec(`
+			UPDATE broadcast_messages
+			SET status = 'cancelled', error_message = ?, processing_worker_id = NULL, updated_at = NOW()
+			WHERE campaign_id = ? AND status IN ('pending', 'queued', 'processing')
+		`, "Campaign cancelled: "+reason, id)
+		if err != nil {
+			return err
+		}
+		affected, _ := result.RowsAffected()
+		event.Messages = int(affected)
+		return nil
+	})
+}
+
+// ThrottleCampaign changes the delay between sends. Workers read a campaign's
+// delays when they claim its messages, so the change applies to every message
+// not yet claimed.
+func (r *campaignRepository) ThrottleCampaign(id int, actor string, minDelay, maxDelay int) (*models.CampaignEvent, error) {
+	event := models.CampaignEvent{Action: CampaignActionThrottle, Actor: actor,
+		MinDelaySeconds: &minDelay, MaxDelaySeconds: &maxDelay}
+	allowed := append(slices.Clone(campaignLiveStatuses), "paused")
+	return r.transitionCampaign(id, &event, allowed, func(tx *sql.Tx) error {
+		event.ToStatus = event.FromStatus
+		if _, err := tx.Exec(`
+			UPDATE campaigns SET min_delay_seconds = ?, max_delay_seconds = ? WHERE id = ?
+		`, minDelay, maxDelay, id); err != nil {
+			return err
+		}
+		return countPendingMessages(tx, id, &event.Messages)
+	})
+}
+
+// countPendingMessages counts the campaign messages a control affects
+func countPendingMessages(tx *sql.Tx, id int, count *int) error {
+	return tx.QueryRow(`
+		SELECT COUNT(*) FROM broadcast_messages WHERE campaign_id = ? AND status = 'pending'
+	`, id).Scan(count)
+}
+
+// transitionCampaign locks the campaign, checks its status allows the change,
+// applies it and records the event in one transaction. change sets the
+// event's target status.
+func (r *campaignRepository) transitionCampaign(id int, event *models.CampaignEvent, allowed []string, change func(tx *sql.Tx) error) (*models.CampaignEvent, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(`SELECT status FROM campaigns WHERE id = ? FOR UPDATE`, id).Scan(&event.FromStatus)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(allowed, event.FromStatus) {
+		return nil, fmt.Errorf("%w: cannot %s a %s campaign", ErrCampaignTransition, event.Action, event.FromStatus)
+	}
+	if err := change(tx); err != nil {
+		return nil, err
+	}
+
+	if event.ToStatus != event.FromStatus {
+		if _, err := tx.Exec(`UPDATE campaigns SET status = ?, updated_at = NOW() WHERE id = ?`, event.ToStatus, id); err != nil {
+			return nil, err
+		}
+	}
+	event.CampaignID = id
+	if err := insertCampaignEvent(tx, event); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// RecordCampaignEvent audits a transition made outside the lifecycle controls
+func (r *campaignRepository) RecordCampaignEvent(event models.CampaignEvent) error {
+	return insertCampaignEvent(r.db, &event)
+}
+
+func insertCampaignEvent(db interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}, event *models.CampaignEvent) error {
+	event.CreatedAt = time.Now()
+	result, err := db.Exec(`
+		INSERT INTO campaign_events (campaign_id, actor, action, from_status, to_status, reason,
+			min_delay_seconds, max_delay_seconds, messages, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, event.CampaignID, event.Actor, event.Action, event.FromStatus, event.ToStatus, event.Reason,
+		event.MinDelaySeconds, event.MaxDelaySeconds, event.Messages, event.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record campaign event: %w", err)
+	}
+	event.ID, _ = result.LastInsertId()
+	return nil
+}
+
+// GetCampaignEvents returns a campaign's audit trail, oldest first
+func (r *campaignRepository) GetCampaignEvents(id int) ([]models.CampaignEvent, error) {
+	rows, err := r.db.Query(`
+		SELECT id, campaign_id, actor, action, from_status, to_status, reason,
+			min_delay_seconds, max_delay_seconds, messages, created_at
+		FROM campaign_events
+		WHERE campaign_id = ?
+		ORDER BY created_at, id
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.CampaignEvent{}
+	for rows.Next() {
+		var event models.CampaignEvent
+		var minDelay, maxDelay sql.NullInt64
+		if err := rows.Scan(&event.ID, &event.CampaignID, &event.Actor, &event.Action, &event.FromStatus,
+			&event.ToStatus, &event.Reason, &minDelay, &maxDelay, &event.Messages, &event.CreatedAt); err != nil {
+			return nil, err
+		}
+		if minDelay.Valid {
+			delay := int(minDelay.Int64)
+			event.MinDelaySeconds = &delay
+		}
+		if maxDelay.Valid {
+			delay := int(maxDelay.Int64)
+			event.MaxDelaySeconds = &delay
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
@@ -2,13 +2,17 @@ package repository
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"log"
 	"sync"
 	"time"
 
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/database"
+	domainBroadcast "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/broadcast"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/models"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/listing"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
 )
 
 var (
@@ -20,6 +24,9 @@ var (
 func GetCampaignRepository() CampaignRepository {
 	campaignRepoOnce.Do(func() {
 		campaignRepo = NewCampaignRepository(database.GetDB())
+		messagePayloadSchema.Ensure(database.GetDB())
+		CampaignTargetGroupsSchema.Ensure(database.GetDB())
+		campaignEventsSchema.Ensure(database.GetDB())
 	})
 	return campaignRepo
 }
@@ -28,6 +35,7 @@ type CampaignRepository interface {
 	CreateCampaign(campaign *models.Campaign) error
 	GetCampaignByDateAndNiche(scheduledDate, niche string) ([]models.Campaign, error)
 	GetAllCampaigns(userID string) ([]models.Campaign, error)
+	ListCampaigns(userID string, q listing.Query) ([]models.Campaign, utils.Page, error)
 	GetCampaignByID(id int) (*models.Campaign, error)
 	UpdateCampaignStatus(id int, status string) error
 	GetPendingCampaigns() ([]models.Campaign, error)
@@ -43,6 +51,13 @@ type CampaignRepository interface {
 	GetUserCampaignBroadcastStats(userID string) (shouldSend, doneSend, failedSend int, err error)
 	// New method for date range filtering
 	GetCampaignsByUserAndDateRange(userID string, startDate string, endDate string) ([]models.Campaign, error)
+	// Lifecycle controls, each audited in campaign_events
+	PauseCampaign(id int, actor string) (*models.CampaignEvent, error)
+	ResumeCampaign(id int, actor string) (*models.CampaignEvent, error)
+	CancelCampaign(id int, actor, reason string) (*models.CampaignEvent, error)
+	ThrottleCampaign(id int, actor string, minDelay, maxDelay int) (*models.CampaignEvent, error)
+	RecordCampaignEvent(event models.CampaignEvent) error
+	GetCampaignEvents(id int) ([]models.CampaignEvent, error)
 }
 
 type campaignRepository struct {
@@ -97,8 +112,9 @@ func (r *campaignRepository) CreateCampaign(campaign *models.Campaign) error {
 	
 	query := `
 		INSERT INTO campaigns(user_id, campaign_date, title, niche, target_status, message, image_url, 
+		 message_type, message_payload, target_groups,
 		 time_schedule, min_delay_seconds, max_delay_seconds, status, ai, ` + "`limit`" + `, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 	
 	// Default target_status to 'all' if not set
@@ -109,6 +125,7 @@ func (r *campaignRepository) CreateCampaign(campaign *models.Campaign) error {
 	
 	result, err := r.db.Exec(query, campaign.UserID, campaign.CampaignDate,
 		campaign.Title, campaign.Niche, targetStatus, campaign.Message, campaign.ImageURL,
+		campaignMessageType(campaign), domainBroadcast.EncodePayload(campaign.Payload), EncodeGroupTargets(campaign.TargetGroups),
 		campaign.TimeSchedule, campaign.MinDelaySeconds, campaign.MaxDelaySeconds, 
 		campaign.Status, campaign.AI, campaign.Limit, campaign.CreatedAt, campaign.UpdatedAt)
 		
@@ -125,6 +142,42 @@ func (r *campaignRepository) CreateCampaign(campaign *models.Campaign) error {
 	return nil
 }
 
+// CampaignTargetGroupsSchema adds the column holding a group campaign's groups
+var CampaignTargetGroupsSchema = database.RegisterSchema("campaigns.target_groups",
+	database.AddColumn("campaigns", "target_groups", "TEXT NULL"),
+)
+
+// EncodeGroupTargets stores a group campaign's groups as JSON, and a lead
+// campaign's as NULL
+func EncodeGroupTargets(targets []models.CampaignGroupTarget) *string {
+	if len(targets) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(targets)
+	if err != nil {
+		return nil
+	}
+	encoded := string(data)
+	return &encoded
+}
+
+// DecodeGroupTargets parses stored groups; empty or malformed values decode to nil
+func DecodeGroupTargets(value string) []models.CampaignGroupTarget {
+	if value == "" {
+		return nil
+	}
+	var targets []models.CampaignGroupTarget
+	if err := json.Unmarshal([]byte(value), &targets); err != nil {
+		return nil
+	}
+	return targets
+}
+
+// campaignMessageType stores the campaign's type, resolving legacy text-or-image campaigns
+func campaignMessageType(campaign *models.Campaign) string {
+	return domainBroadcast.ResolveMessageType(campaign.MessageType, campaign.ImageURL)
+}
+
 // GetCampaignByDateAndNiche gets campaigns by date and niche
 func (r *campaignRepository) GetCampaignByDateAndNiche(scheduledDate, niche string) ([]models.Campaign, error) {
 	query := `
@@ -162,7 +215,8 @@ func (r *campaignRepository) GetAllCampaigns(userID string) ([]models.Campaign,
 	query := `
 		SELECT id, user_id, title, niche, 
 			COALESCE(target_status, 'all') AS target_status,
-			message, COALESCE(image_url, '') AS image_url, campaign_date, 
+			message, COALESCE(image_url, '') AS image_url,
+			COALESCE(message_type, '') AS message_type, COALESCE(message_payload, '') AS message_payload, COALESCE(target_groups, '') AS target_groups, campaign_date, 
 			COALESCE(time_schedule, '') AS time_schedule,
 			COALESCE(min_delay_seconds, 10) AS min_delay_seconds,
 			COALESCE(max_delay_seconds, 30) AS max_delay_seconds,
@@ -178,15 +232,96 @@ func (r *campaignRepository) GetAllCampaigns(userID string) ([]models.Campaign,
 	}
 	defer rows.Close()
 	
+	return scanCampaigns(rows)
+}
+
+// CampaignListFields are the sort, filter and search fields of the campaign list
+var CampaignListFields = listing.Fields{
+	Columns: map[string]string{
+		"id":            "id",
+		"title":         "title",
+		"niche":         "niche",
+		"message":       "message",
+		"target_status": "COALESCE(target_status, 'all')",
+		"message_type":  "COALESCE(message_type, '')",
+		"status":        "status",
+		"campaign_date": "campaign_date",
+		"created_at":    "created_at",
+	},
+	Sort:        []string{"title", "status", "campaign_date", "created_at"},
+	Filter:      []string{"status", "niche", "target_status", "message_type"},
+	Search:      []string{"title", "niche", "message"},
+	Key:         "id",
+	DefaultSort: "-campaign_date",
+}
+
+// ListCampaigns returns one page of a user's campaigns
+func (r *campaignRepository) ListCampaigns(userID string, q listing.Query) ([]models.Campaign, utils.Page, error) {
+	where, args := q.Where()
+	var total int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM campaigns WHERE user_id = ?`+where, append([]any{userID}, args...)...).Scan(&total)
+	if err != nil {
+		return nil, utils.Page{}, err
+	}
+	
+	seek, args := q.Seek()
+	rows, err := r.db.Query(`
+		SELECT id, user_id, title, niche, 
+			COALESCE(target_status, 'all') AS target_status,
+			message, COALESCE(image_url, '') AS image_url,
+			COALESCE(message_type, '') AS message_type, COALESCE(message_payload, '') AS message_payload, COALESCE(target_groups, '') AS target_groups, campaign_date, 
+			COALESCE(time_schedule, '') AS time_schedule,
+			COALESCE(min_delay_seconds, 10) AS min_delay_seconds,
+			COALESCE(max_delay_seconds, 30) AS max_delay_seconds,
+			status, ai, COALESCE(`+"`limit`"+`, 0) AS campaign_limit, created_at, updated_at
+		FROM campaigns
+		WHERE user_id = ?`+seek, append([]any{userID}, args...)...)
+	if err != nil {
+		return nil, utils.Page{}, err
+	}
+	defer rows.Close()
+	
+	campaigns, err := scanCampaigns(rows)
+	if err != nil {
+		return nil, utils.Page{}, err
+	}
+	campaigns, page := listing.Trim(campaigns, q, total, campaignValue)
+	return campaigns, page, nil
+}
+
+// campaignValue returns a campaign's value of a CampaignListFields field
+func campaignValue(c models.Campaign, field string) any {
+	switch field {
+	case "title":
+		return c.Title
+	case "status":
+		return c.Status
+	case "campaign_date":
+		// DATE columns scan as RFC 3339 timestamps; the column compares by day
+		if len(c.CampaignDate) > 10 {
+			return c.CampaignDate[:10]
+		}
+		return c.CampaignDate
+	case "created_at":
+		return c.CreatedAt
+	}
+	return c.ID
+}
+
+// scanCampaigns reads the rows of the campaign list queries
+func scanCampaigns(rows *sql.Rows) ([]models.Campaign, error) {
 	var campaigns []models.Campaign
 	for rows.Next() {
 		var c models.Campaign
+		var payload, groups string
 		if err := rows.Scan(&c.ID, &c.UserID, &c.Title, &c.Niche, 
-			&c.TargetStatus, &c.Message, &c.ImageURL, &c.CampaignDate, 
+			&c.TargetStatus, &c.Message, &c.ImageURL, &c.MessageType, &payload, &groups, &c.CampaignDate, 
 			&c.TimeSchedule, &c.MinDelaySeconds, &c.MaxDelaySeconds,
 			&c.Status, &c.AI, &c.Limit, &c.CreatedAt, &c.UpdatedAt); err != nil {
 			return nil, err
 		}
+		c.Payload = domainBroadcast.DecodePayload(payload)
+		c.TargetGroups = DecodeGroupTargets(groups)
 		campaigns = append(campaigns, c)
 	}
 	
@@ -198,7 +333,8 @@ func (r *campaignRepository) GetCampaignByID(id int) (*models.Campaign, error) {
 	query := `
 		SELECT id, user_id, title, niche, 
 			COALESCE(target_status, 'all') AS target_status,
-			message, COALESCE(image_url, '') AS image_url, campaign_date, 
+			message, COALESCE(image_url, '') AS image_url,
+			COALESCE(message_type, '') AS message_type, COALESCE(message_payload, '') AS message_payload, COALESCE(target_groups, '') AS target_groups, campaign_date, 
 			COALESCE(time_schedule, '') AS time_schedule,
 			COALESCE(min_delay_seconds, 10) AS min_delay_seconds,
 			COALESCE(max_delay_seconds, 30) AS max_delay_seconds,
@@ -208,14 +344,17 @@ func (r *campaignRepository) GetCampaignByID(id int) (*models.Campaign, error) {
 	`
 	
 	var c models.Campaign
+	var payload, groups string
 	err := r.db.QueryRow(query, id).Scan(&c.ID, &c.UserID, &c.Title, &c.Niche, 
-		&c.TargetStatus, &c.Message, &c.ImageURL, &c.CampaignDate, 
+		&c.TargetStatus, &c.Message, &c.ImageURL, &c.MessageType, &payload, &groups, &c.CampaignDate, 
 		&c.TimeSchedule, &c.MinDelaySeconds, &c.MaxDelaySeconds,
 		&c.Status, &c.AI, &c.Limit, &c.CreatedAt, &c.UpdatedAt)
 	
 	if err != nil {
 		return nil, err
 	}
+	c.Payload = domainBroadcast.DecodePayload(payload)
+	c.TargetGroups = DecodeGroupTargets(groups)
 	
 	return &c, nil
 }
@@ -233,7 +372,8 @@ func (r *campaignRepository) GetPendingCampaigns() ([]models.Campaign, error) {
 	query := `
 		SELECT id, user_id, title, niche, 
 			COALESCE(target_status, 'all') AS target_status,
-			message, COALESCE(image_url, '') AS image_url, campaign_date, 
+			message, COALESCE(image_url, '') AS image_url,
+			COALESCE(message_type, '') AS message_type, COALESCE(message_payload, '') AS message_payload, COALESCE(target_groups, '') AS target_groups, campaign_date, 
 			COALESCE(time_schedule, '') AS time_schedule,
 			COALESCE(min_delay_seconds, 10) AS min_delay_seconds,
 			COALESCE(max_delay_seconds, 30) AS max_delay_seconds,
@@ -266,13 +406,16 @@ func (r *campaignRepository) GetPendingCampaigns() ([]models.Campaign, error) {
 	var campaigns []models.Campaign
 	for rows.Next() {
 		var c models.Campaign
+		var payload, groups string
 		if err := rows.Scan(&c.ID, &c.UserID, &c.Title, &c.Niche, 
-			&c.TargetStatus, &c.Message, &c.ImageURL, &c.CampaignDate, 
+			&c.TargetStatus, &c.Message, &c.ImageURL, &c.MessageType, &payload, &groups, &c.CampaignDate, 
 			&c.TimeSchedule, &c.MinDelaySeconds, &c.MaxDelaySeconds,
 			&c.Status, &c.CreatedAt, &c.UpdatedAt); err != nil {
 			log.Printf("❌ [Campaign Repository] Error scanning campaign: %v", err)
 			continue
 		}
+		c.Payload = domainBroadcast.DecodePayload(payload)
+		c.TargetGroups = DecodeGroupTargets(groups)
 		campaigns = append(campaigns, c)
 	}
 	
@@ -284,7 +427,8 @@ func (r *campaignRepository) GetPendingCampaignsByStatus(userID string, targetSt
 	query := `
 		SELECT id, user_id, title, niche, 
 			COALESCE(target_status, 'all') AS target_status,
-			message, COALESCE(image_url, '') AS image_url, campaign_date, 
+			message, COALESCE(image_url, '') AS image_url,
+			COALESCE(message_type, '') AS message_type, COALESCE(message_payload, '') AS message_payload, COALESCE(target_groups, '') AS target_groups, campaign_date, 
 			COALESCE(time_schedule, '') AS time_schedule,
 			COALESCE(min_delay_seconds, 10) AS min_delay_seconds,
 			COALESCE(max_delay_seconds, 30) AS max_delay_seconds,
@@ -310,12 +454,15 @@ func (r *campaignRepository) GetPendingCampaignsByStatus(userID string, targetSt
 	var campaigns []models.Campaign
 	for rows.Next() {
 		var c models.Campaign
+		var payload, groups string
 		if err := rows.Scan(&c.ID, &c.UserID, &c.Title, &c.Niche, 
-			&c.TargetStatus, &c.Message, &c.ImageURL, &c.CampaignDate, 
+			&c.TargetStatus, &c.Message, &c.ImageURL, &c.MessageType, &payload, &groups, &c.CampaignDate, 
 			&c.TimeSchedule, &c.MinDelaySeconds, &c.MaxDelaySeconds,
 			&c.Status, &c.AI, &c.Limit, &c.CreatedAt, &c.UpdatedAt); err != nil {
 			return nil, err
 		}
+		c.Payload = domainBroadcast.DecodePayload(payload)
+		c.TargetGroups = DecodeGroupTargets(groups)
 		campaigns = append(campaigns, c)
 	}
 	
@@ -337,7 +484,7 @@ func (r *campaignRepository) UpdateCampaign(campaign *models.Campaign) error {
 	query := `
 	UPDATE campaigns 
 		SET title = ?, niche = ?, target_status = ?, message = ?, 
-		    image_url = ?, campaign_date = ?, time_schedule = ?,
+		    image_url = ?, message_type = ?, message_payload = ?, target_groups = ?, campaign_date = ?, time_schedule = ?,
 		    min_delay_seconds = ?, max_delay_seconds = ?, 
 		    status = ?, ai = ?, ` + "`limit`" + ` = ?, updated_at = ?
 		WHERE id = ? AND user_id = ?
@@ -347,7 +494,8 @@ func (r *campaignRepository) UpdateCampaign(campaign *models.Campaign) error {
 	
 	result, err := r.db.Exec(query, 
 		campaign.Title, campaign.Niche, campaign.TargetStatus, campaign.Message,
-		campaign.ImageURL, campaign.CampaignDate, campaign.TimeSchedule,
+		campaign.ImageURL, campaignMessageType(campaign), domainBroadcast.EncodePayload(campaign.Payload),
+		EncodeGroupTargets(campaign.TargetGroups), campaign.CampaignDate, campaign.TimeSchedule,
 		campaign.MinDelaySeconds, campaign.MaxDelaySeconds,
 		campaign.Status, campaign.AI, campaign.Limit, campaign.UpdatedAt, campaign.ID, campaign.UserID)
 	
@@ -513,7 +661,8 @@ func (r *campaignRepository) GetCampaignsByUserAndDateRange(userID string, start
 	query := `
 		SELECT id, user_id, title, niche, 
 			COALESCE(target_status, 'all') AS target_status,
-			message, COALESCE(image_url, '') AS image_url, campaign_date, 
+			message, COALESCE(image_url, '') AS image_url,
+			COALESCE(message_type, '') AS message_type, COALESCE(message_payload, '') AS message_payload, COALESCE(target_groups, '') AS target_groups, campaign_date, 
 			COALESCE(time_schedule, '') AS time_schedule,
 			COALESCE(min_delay_seconds, 10) AS min_delay_seconds,
 			COALESCE(max_delay_seconds, 30) AS max_delay_seconds,
@@ -547,12 +696,15 @@ func (r *campaignRepository) GetCampaignsByUserAndDateRange(userID string, start
 	var campaigns []models.Campaign
 	for rows.Next() {
 		var c models.Campaign
+		var payload, groups string
 		if err := rows.Scan(&c.ID, &c.UserID, &c.Title, &c.Niche, 
-			&c.TargetStatus, &c.Message, &c.ImageURL, &c.CampaignDate, 
+			&c.TargetStatus, &c.Message, &c.ImageURL, &c.MessageType, &payload, &groups, &c.CampaignDate, 
 			&c.TimeSchedule, &c.MinDelaySeconds, &c.MaxDelaySeconds,
 			&c.Status, &c.AI, &c.Limit, &c.CreatedAt, &c.UpdatedAt); err != nil {
 			return nil, err
 		}
+		c.Payload = domainBroadcast.DecodePayload(payload)
+		c.TargetGroups = DecodeGroupTargets(groups)
 		campaigns = append(campaigns, c)
 	}
 	
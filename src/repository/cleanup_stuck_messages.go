@@ -1,13 +1,16 @@
 package repository
 
 import (
+	"context"
+	"fmt"
 	"time"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/database"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/scheduler"
 	"github.com/sirupsen/logrus"
 )
 
 // CleanupStuckMessages resets messages that have been stuck in processing for too long
-func CleanupStuckMessages() {
+func CleanupStuckMessages() error {
 	db := database.GetDB()
 	
 	// Reset messages stuck in processing for more than 5 minutes
@@ -22,25 +25,24 @@ func CleanupStuckMessages() {
 	`)
 	
 	if err != nil {
-		logrus.Errorf("Failed to cleanup stuck messages: %v", err)
-		return
+		return fmt.Errorf("failed to cleanup stuck messages: %w", err)
 	}
 	
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected > 0 {
 		logrus.Infof("Reset %d stuck messages back to pending", rowsAffected)
 	}
+	return nil
 }
 
-// StartCleanupWorker starts a background worker that cleans up stuck messages
+// StartCleanupWorker schedules the stuck message cleanup
 func StartCleanupWorker() {
-	ticker := time.NewTicker(5 * time.Minute)
-	defer ticker.Stop()
-	
-	for {
-		select {
-		case <-ticker.C:
-			CleanupStuckMessages()
-		}
-	}
+	scheduler.Default().MustRegister(scheduler.Job{
+		Name:      "stuck-message-cleanup",
+		Interval:  5 * time.Minute,
+		Singleton: true,
+		Run: func(ctx context.Context) error {
+			return CleanupStuckMessages()
+		},
+	})
 }
@@ -0,0 +1,515 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/database"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/models"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// Storage files searched for a data subject's messages, and the media
+// files received in them
+const (
+	dataSubjectChatStorage    = "chat_storage"
+	dataSubjectMessageRecords = "message_records"
+	dataSubjectMedia          = "media"
+)
+
+// MediaFiles reads and removes objects in the media store, which the
+// media library keeps and the repositories cannot reach
+type MediaFiles interface {
+	Read(ctx context.Context, key string) ([]byte, error)
+	Remove(ctx context.Context, sha string, keys ...string) error
+}
+
+// dataSubject is a phone number of one user's, in the forms it is stored in
+type dataSubject struct {
+	userID string
+	digits string
+	phones []string // as given, as digits and with a leading +
+	jids   []string
+}
+
+func newDataSubject(userID, phone string) dataSubject {
+	digits := SuppressionPhone(phone)
+	s := dataSubject{userID: userID, digits: digits, jids: []string{LeadChatJID(digits)}}
+	for _, form := range []string{phone, digits, "+" + digits} {
+		if form != "" && !slices.Contains(s.phones, form) {
+			s.phones = append(s.phones, form)
+		}
+	}
+	return s
+}
+
+// hash identifies the subject in the audit log without storing the phone
+func (s dataSubject) hash() string {
+	sum := sha256.Sum256([]byte(s.userID + ":" + s.digits))
+	return hex.EncodeToString(sum[:])
+}
+
+// pseudonym replaces the phone in rows kept after an erasure
+func (s dataSubject) pseudonym() string {
+	return "erased-" + s.hash()[:16]
+}
+
+// matchesJID reports whether a JID, with or without a device part, is the subject's
+func (s dataSubject) matchesJID(jid string) bool {
+	user, _, _ := strings.Cut(jid, "@")
+	user, _, _ = strings.Cut(user, ":")
+	return user != "" && user == s.digits
+}
+
+// LeadIdempotencyKey is the idempotency key of a lead webhook call. The
+// phone is stored as its subject hash, so the key holds no number and an
+// export or erasure of the number finds the cached response.
+func LeadIdempotencyKey(userID, phone, deviceID, niche string) string {
+	return leadIdempotencyPrefix(newDataSubject(userID, phone)) + deviceID + "_" + niche
+}
+
+func leadIdempotencyPrefix(s dataSubject) string {
+	return "lead:" + s.hash()[:32] + "_"
+}
+
+// subjectStore is a table holding data about a phone number
+type subjectStore struct {
+	table string
+	// match selects the subject's rows
+	match func(s dataSubject) (string, []any)
+	// keep, when set, pseudonymizes the rows on erasure instead of deleting
+	// them, so the counts they feed stay right
+	keep func(s dataSubject) (string, []any)
+	// retain keeps the rows on erasure, for records that must outlive it
+	retain bool
+}
+
+// userDevices selects the devices of the subject's user
+const userDevices = `device_id IN (SELECT id FROM user_devices WHERE user_id = ?)`
+
+// subjectStores lists every table that stores a phone number's data.
+// The suppression list is kept on erasure so the number is not imported or
+// messaged again; it holds nothing but the number and the reason.
+var subjectStores = []subjectStore{
+	{table: "leads", match: byUserPhone("phone")},
+	{table: "leads_ai", match: byUserPhone("phone")},
+	{table: "lead_consents", match: byUserPhone("phone")},
+	{table: "lead_events", match: byUserPhone("phone")},
+	{table: "lead_tags", match: byUserPhone("phone")},
+	{table: "lead_suppressions", match: byUserPhone("phone"), retain: true},
+	{table: "sequence_contacts", match: func(s dataSubject) (string, []any) {
+		in, args := inList(s.phones)
+		return `(user_id = ? OR sequence_id IN (SELECT id FROM sequences WHERE user_id = ?)) AND contact_phone IN ` + in,
+			append([]any{s.userID, s.userID}, args...)
+	}},
+	{table: "sequence_flow_positions", match: byUserPhone("contact_phone")},
+	{table: "broadcast_messages", match: byUserPhone("recipient_phone"), keep: func(s dataSubject) (string, []any) {
+		return `recipient_phone = ?, recipient_name = NULL, content = NULL, media_url = NULL,
+			message_payload = NULL, idempotency_key = NULL`, []any{s.pseudonym()}
+	}},
+	{table: "broadcast_dead_letters", match: byUserPhone("recipient_phone"), keep: func(s dataSubject) (string, []any) {
+		return `recipient_phone = ?, recipient_name = NULL`, []any{s.pseudonym()}
+	}},
+	{table: "whatsapp_chats", match: func(s dataSubject) (string, []any) {
+		in, args := inList(s.jids)
+		return userDevices + ` AND chat_jid IN ` + in, append([]any{s.userID}, args...)
+	}},
+	{table: "whatsapp_messages", match: func(s dataSubject) (string, []any) {
+		in, args := inList(s.jids)
+		return userDevices + ` AND (chat_jid IN ` + in + ` OR sender_jid IN ` + in + `)`,
+			append(append([]any{s.userID}, args...), args...)
+	}},
+	{table: "message_analytics", match: func(s dataSubject) (string, []any) {
+		in, args := inList(s.jids)
+		return `user_id = ? AND jid IN ` + in, append([]any{s.userID}, args...)
+	}},
+	// Cached lead webhook responses repeat the lead they created
+	{table: "idempotency_keys", match: func(s dataSubject) (string, []any) {
+		return `idem_key LIKE ?`, []any{leadIdempotencyPrefix(s) + "%"}
+	}},
+	// The validation cache is shared by every user; it holds only what
+	// WhatsApp reports about the number
+	{table: "number_validations", match: func(s dataSubject) (string, []any) {
+		in, args := inList(s.phones)
+		return `phone IN ` + in, args
+	}},
+}
+
+func byUserPhone(column string) func(s dataSubject) (string, []any) {
+	return func(s dataSubject) (string, []any) {
+		in, args := inList(s.phones)
+		return `user_id = ? AND ` + column + ` IN ` + in, append([]any{s.userID}, args...)
+	}
+}
+
+func inList(values []string) (string, []any) {
+	args := make([]any, len(values))
+	for i, value := range values {
+		args[i] = value
+	}
+	return `(` + strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ") + `)`, args
+}
+
+// DataSubjectRepository exports and erases what is stored about a phone
+// number, keeping an audit log of both
+type DataSubjectRepository struct {
+	db *sql.DB
+}
+
+var (
+	dataSubjectRepo     *DataSubjectRepository
+	dataSubjectRepoOnce sync.Once
+)
+
+// GetDataSubjectRepository returns the data subject repository
+func GetDataSubjectRepository() *DataSubjectRepository {
+	dataSubjectRepoOnce.Do(func() {
+		dataSubjectRepo = &DataSubjectRepository{db: database.GetDB()}
+		// The stores it exports and erases belong to other repositories,
+		// whose getters create them
+		GetLeadRepository()
+		GetSuppressionRepository()
+		GetSequenceFlowRepository()
+		GetDeadLetterRepository()
+		GetNumberValidationRepository()
+		GetBroadcastRepository()
+		GetMediaAssetRepository()
+		GetIdempotencyRepository()
+		MessageMediaSchema.Ensure(dataSubjectRepo.db)
+	})
+	dataSubjectSchema.Ensure(dataSubjectRepo.db)
+	return dataSubjectRepo
+}
+
+// dataSubjectSchema creates the audit log of exports and erasures
+var dataSubjectSchema = database.RegisterSchema("data_subject_requests", database.Statement(`
+			CREATE TABLE IF NOT EXISTS data_subject_requests (
+				id BIGINT AUTO_INCREMENT PRIMARY KEY,
+				user_id VARCHAR(36) NOT NULL,
+				actor VARCHAR(255) NOT NULL,
+				action VARCHAR(10) NOT NULL,
+				subject_hash VARCHAR(64) NOT NULL,
+				pseudonym VARCHAR(50) NOT NULL DEFAULT '',
+				affected TEXT,
+				created_at TIMESTAMP NOT NULL,
+				INDEX idx_data_subject_requests_user (user_id, created_at),
+				INDEX idx_data_subject_requests_subject (subject_hash)
+			)
+		`))
+
+// Export collects every row, storage record and received media file one
+// user keeps about a phone number and logs the export. The media content is
+// read through files.
+func (r *DataSubjectRepository) Export(userID, phone, actor string, files MediaFiles) (*models.DataSubjectData, error) {
+	s := newDataSubject(userID, phone)
+	data := &models.DataSubjectData{
+		Phone:      phone,
+		ExportedAt: time.Now().UTC(),
+		Tables:     map[string][]map[string]any{},
+		Files:      map[string][]map[string]any{},
+		Media:      []models.DataSubjectMedia{},
+	}
+	affected := map[string]int64{}
+
+	for _, store := range subjectStores {
+		where, args := store.match(s)
+		rows, err := selectRows(r.db, `SELECT * FROM `+store.table+` WHERE `+where, args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export %s: %w", store.table, err)
+		}
+		if len(rows) > 0 {
+			data.Tables[store.table] = rows
+			affected[store.table] = int64(len(rows))
+		}
+	}
+
+	chats, err := utils.FindStoredRecords(s.matchesJID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export chat storage: %w", err)
+	}
+	for _, record := range chats {
+		data.Files[dataSubjectChatStorage] = append(data.Files[dataSubjectChatStorage], map[string]any{
+			"message_id": record.MessageID, "jid": record.JID, "message_content": record.MessageContent,
+		})
+	}
+	for _, record := range utils.FindMessageRecords(r.matchesMessageRecord(s)) {
+		data.Files[dataSubjectMessageRecords] = append(data.Files[dataSubjectMessageRecords], map[string]any{
+			"id": record.ID, "jid": record.JID, "content": record.Content, "timestamp": record.Timestamp,
+			"from_me": record.FromMe, "status": record.Status, "device_id": record.DeviceID,
+		})
+	}
+	for file, records := range data.Files {
+		affected[file] = int64(len(records))
+	}
+
+	media, err := subjectMedia(r.db, s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export media: %w", err)
+	}
+	for i := range media {
+		content, err := files.Read(context.Background(), media[i].StorageKey)
+		if err != nil {
+			logrus.Warnf("Media %s of %s not exported: %v", media[i].SHA256, s.hash(), err)
+			continue
+		}
+		media[i].Content = content
+	}
+	data.Media = append(data.Media, media...)
+	if len(media) > 0 {
+		affected[dataSubjectMedia] = int64(len(media))
+	}
+
+	if err := r.record(&models.DataSubjectRequest{
+		UserID: userID, Actor: actor, Action: models.DataSubjectExport, SubjectHash: s.hash(), Affected: affected,
+	}); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// Erase deletes what one user keeps about a phone number, or replaces the
+// number with a pseudonym in rows that feed campaign and sequence counts,
+// and logs the erasure. Messages not yet sent to the number are cancelled.
+// The database changes are made in one transaction; the storage files are
+// cleaned after it commits, and received media is removed through files
+// once no other message or library asset uses it.
+func (r *DataSubjectRepository) Erase(userID, phone, actor string, files MediaFiles) (*models.DataSubjectRequest, error) {
+	s := newDataSubject(userID, phone)
+	request := &models.DataSubjectRequest{
+		UserID: userID, Actor: actor, Action: models.DataSubjectErase,
+		SubjectHash: s.hash(), Pseudonym: s.pseudonym(), Affected: map[string]int64{},
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	media, err := subjectMedia(tx, s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find media: %w", err)
+	}
+
+	where, args := byUserPhone("recipient_phone")(s)
+	if _, err := tx.Exec(`
+		UPDATE broadcast_messages
+		SET status = 'cancelled', error_message = 'Recipient data erased', processing_worker_id = NULL, updated_at = NOW()
+		WHERE `+where+` AND status IN ('pending', 'queued', 'processing')
+	`, args...); err != nil {
+		return nil, fmt.Errorf("failed to cancel messages: %w", err)
+	}
+
+	for _, store := range subjectStores {
+		if store.retain {
+			continue
+		}
+		where, args := store.match(s)
+		query := `DELETE FROM ` + store.table + ` WHERE ` + where
+		if store.keep != nil {
+			set, setArgs := store.keep(s)
+			query = `UPDATE ` + store.table + ` SET ` + set + ` WHERE ` + where
+			args = append(setArgs, args...)
+		}
+		result, err := tx.Exec(query, args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to erase %s: %w", store.table, err)
+		}
+		if affected, _ := result.RowsAffected(); affected > 0 {
+			request.Affected[store.table] = affected
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	if erased, err := utils.EraseStoredRecords(s.matchesJID); err != nil {
+		logrus.Errorf("Failed to erase %s from chat storage: %v", request.SubjectHash, err)
+	} else if erased > 0 {
+		request.Affected[dataSubjectChatStorage] = int64(erased)
+	}
+	if erased, err := utils.EraseMessageRecords(r.matchesMessageRecord(s)); err != nil {
+		logrus.Errorf("Failed to erase %s from message records: %v", request.SubjectHash, err)
+	} else if erased > 0 {
+		request.Affected[dataSubjectMessageRecords] = int64(erased)
+	}
+	if removed := r.removeUnusedMedia(media, files); removed > 0 {
+		request.Affected[dataSubjectMedia] = int64(removed)
+	}
+
+	if err := r.record(request); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// Requests returns a user's exports and erasures, newest first, only those
+// for phone when it is set
+func (r *DataSubjectRepository) Requests(userID, phone string) ([]models.DataSubjectRequest, error) {
+	query, args := `
+		SELECT id, user_id, actor, action, subject_hash, pseudonym, COALESCE(affected, ''), created_at
+		FROM data_subject_requests
+		WHERE user_id = ?`, []any{userID}
+	if phone != "" {
+		query += ` AND subject_hash = ?`
+		args = append(args, newDataSubject(userID, phone).hash())
+	}
+	rows, err := r.db.Query(query+` ORDER BY created_at DESC, id DESC`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var requests []models.DataSubjectRequest
+	for rows.Next() {
+		var request models.DataSubjectRequest
+		var affected string
+		if err := rows.Scan(&request.ID, &request.UserID, &request.Actor, &request.Action, &request.SubjectHash,
+			&request.Pseudonym, &affected, &request.CreatedAt); err != nil {
+			return nil, err
+		}
+		if affected != "" {
+			if err := json.Unmarshal([]byte(affected), &request.Affected); err != nil {
+				return nil, err
+			}
+		}
+		requests = append(requests, request)
+	}
+	return requests, rows.Err()
+}
+
+func (r *DataSubjectRepository) record(request *models.DataSubjectRequest) error {
+	affected, err := json.Marshal(request.Affected)
+	if err != nil {
+		return err
+	}
+	request.CreatedAt = time.Now().UTC()
+	result, err := r.db.Exec(`
+		INSERT INTO data_subject_requests (user_id, actor, action, subject_hash, pseudonym, affected, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, request.UserID, request.Actor, request.Action, request.SubjectHash, request.Pseudonym, string(affected), request.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record %s request: %w", request.Action, err)
+	}
+	request.ID, _ = result.LastInsertId()
+	return nil
+}
+
+// subjectMedia returns the media files received in the subject's messages,
+// once per file
+func subjectMedia(db interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}, s dataSubject) ([]models.DataSubjectMedia, error) {
+	in, args := inList(s.jids)
+	rows, err := db.Query(`
+		SELECT media_sha256, COALESCE(media_mimetype, ''), COALESCE(media_size, 0), COALESCE(media_file_name, ''),
+			media_storage_key, COALESCE(media_thumbnail_key, '')
+		FROM whatsapp_messages
+		WHERE `+userDevices+` AND (chat_jid IN `+in+` OR sender_jid IN `+in+`)
+			AND media_sha256 IS NOT NULL AND media_storage_key IS NOT NULL
+		ORDER BY id
+	`, append(append([]any{s.userID}, args...), args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var media []models.DataSubjectMedia
+	seen := map[string]bool{}
+	for rows.Next() {
+		var m models.DataSubjectMedia
+		if err := rows.Scan(&m.SHA256, &m.MimeType, &m.Size, &m.FileName, &m.StorageKey, &m.ThumbnailKey); err != nil {
+			return nil, err
+		}
+		if !seen[m.SHA256] {
+			seen[m.SHA256] = true
+			media = append(media, m)
+		}
+	}
+	return media, rows.Err()
+}
+
+// removeUnusedMedia removes the erased messages' media files that no other
+// message or library asset uses, and returns how many it removed. The store
+// is shared by content hash, so a file another user received stays.
+func (r *DataSubjectRepository) removeUnusedMedia(media []models.DataSubjectMedia, files MediaFiles) int {
+	removed := 0
+	for _, m := range media {
+		var remaining int
+		if err := r.db.QueryRow(`
+			SELECT (SELECT COUNT(*) FROM media_assets WHERE sha256 = ?) +
+				(SELECT COUNT(*) FROM whatsapp_messages WHERE media_sha256 = ?)
+		`, m.SHA256, m.SHA256).Scan(&remaining); err != nil {
+			logrus.Errorf("Failed to check use of media %s: %v", m.SHA256, err)
+			continue
+		}
+		if remaining > 0 {
+			continue
+		}
+		if _, err := r.db.Exec(`DELETE FROM media_uploads WHERE sha256 = ?`, m.SHA256); err != nil {
+			logrus.Warnf("Failed to drop cached uploads for %s: %v", m.SHA256, err)
+		}
+		if err := files.Remove(context.Background(), m.SHA256, m.StorageKey, m.ThumbnailKey); err != nil {
+			logrus.Errorf("Failed to remove media %s: %v", m.SHA256, err)
+			continue
+		}
+		removed++
+	}
+	return removed
+}
+
+// matchesMessageRecord selects the subject's analytics records, which are
+// kept by the owning user's email
+func (r *DataSubjectRepository) matchesMessageRecord(s dataSubject) func(utils.MessageRecord) bool {
+	email := ""
+	if user, err := GetUserRepository().GetUserByID(s.userID); err == nil && user != nil {
+		email = user.Email
+	}
+	return func(record utils.MessageRecord) bool {
+		return email != "" && record.UserEmail == email && s.matchesJID(record.JID)
+	}
+}
+
+// selectRows reads every column of the rows a query returns, with text
+// columns as strings
+func selectRows(db *sql.DB, query string, args ...any) ([]map[string]any, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	var result []map[string]any
+	for rows.Next() {
+		values := make([]any, len(columns))
+		pointers := make([]any, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+		row := make(map[string]any, len(columns))
+		for i, column := range columns {
+			if b, ok := values[i].([]byte); ok {
+				values[i] = string(b)
+			}
+			row[column] = values[i]
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
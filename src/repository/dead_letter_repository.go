@@ -0,0 +1,342 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/database"
+	domainBroadcast "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/broadcast"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+)
+
+// DeadLetterRepository is the unified store for permanently failed messages
+// from every broadcast path (SQL workers and both Redis managers)
+type DeadLetterRepository struct {
+	db *sql.DB
+}
+
+var (
+	deadLetterRepo     *DeadLetterRepository
+	deadLetterRepoOnce sync.Once
+)
+
+// GetDeadLetterRepository returns the dead letter repository
+func GetDeadLetterRepository() *DeadLetterRepository {
+	deadLetterRepoOnce.Do(func() {
+		deadLetterRepo = &DeadLetterRepository{db: database.GetDB()}
+	})
+	deadLetterSchema.Ensure(deadLetterRepo.db)
+	return deadLetterRepo
+}
+
+// deadLetterSchema creates the dead letter table
+var deadLetterSchema = database.RegisterSchema("broadcast_dead_letters",
+	database.Statement(`
+			CREATE TABLE IF NOT EXISTS broadcast_dead_letters (
+				message_id VARCHAR(36) PRIMARY KEY,
+				user_id VARCHAR(36),
+				device_id VARCHAR(255),
+				device_name VARCHAR(255),
+				campaign_id INT NULL,
+				sequence_id VARCHAR(36) NULL,
+				recipient_phone VARCHAR(50),
+				recipient_name VARCHAR(255),
+				message_type VARCHAR(50),
+				error_class VARCHAR(32) NOT NULL DEFAULT 'unknown',
+				error_code VARCHAR(40) NULL,
+				error_message TEXT,
+				source VARCHAR(20) NOT NULL DEFAULT 'sql',
+				retries INT NOT NULL DEFAULT 0,
+				failed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				INDEX idx_dead_letters_user_class (user_id, error_class),
+				INDEX idx_dead_letters_campaign (campaign_id),
+				INDEX idx_dead_letters_sequence (sequence_id),
+				INDEX idx_dead_letters_device (device_id)
+			)
+		`),
+	database.AddColumn("broadcast_dead_letters", "error_code", "VARCHAR(40) NULL"),
+)
+
+// Record stores a failed message under its error code's class. The message
+// details are copied from broadcast_messages so the entry survives trimming of queues.
+func (r *DeadLetterRepository) Record(messageID, errorMessage string, code pkgError.SendErrorCode, source string, retries int) error {
+	if messageID == "" {
+		return nil
+	}
+	if code == "" {
+		code = pkgError.ClassifySendError(errorMessage)
+	}
+	_, err := r.db.Exec(`
+		INSERT INTO broadcast_dead_letters (
+			message_id, user_id, device_id, device_name, campaign_id, sequence_id,
+			recipient_phone, recipient_name, message_type, error_class, error_code, error_message, source, retries
+		)
+		SELECT id, user_id, device_id, device_name, campaign_id, sequence_id,
+			recipient_phone, recipient_name, message_type, ?, ?, ?, ?, ?
+		FROM broadcast_messages WHERE id = ?
+		ON DUPLICATE KEY UPDATE
+			error_class = VALUES(error_class),
+			error_code = VALUES(error_code),
+			error_message = VALUES(error_message),
+			source = VALUES(source),
+			retries = GREATEST(retries, VALUES(retries)),
+			failed_at = NOW()
+	`, domainBroadcast.ErrorClassForCode(code), string(code), errorMessage, source, retries, messageID)
+	return err
+}
+
+// SyncFailedMessages records failed rows that were marked failed outside the
+// repository (cleanup jobs, legacy processors) and returns how many were added
+func (r *DeadLetterRepository) SyncFailedMessages(limit int) (int, error) {
+	rows, err := r.db.Query(`
+		SELECT bm.id, COALESCE(bm.error_message, ''), COALESCE(bm.error_code, ''), bm.retry_count
+		FROM broadcast_messages bm
+		LEFT JOIN broadcast_dead_letters dl ON dl.message_id = bm.id
+		WHERE bm.status = 'failed' AND dl.message_id IS NULL
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	type failed struct {
+		id, message, code string
+		retries           int
+	}
+	var missing []failed
+	for rows.Next() {
+		var f failed
+		if err := rows.Scan(&f.id, &f.message, &f.code, &f.retries); err == nil {
+			missing = append(missing, f)
+		}
+	}
+	rows.Close()
+
+	for _, f := range missing {
+		if err := r.Record(f.id, f.message, pkgError.SendErrorCode(f.code), domainBroadcast.DeadLetterSourceSQL, f.retries); err != nil {
+			return 0, err
+		}
+	}
+	return len(missing), nil
+}
+
+// deadLetterWhere builds the WHERE clause for a filter
+func deadLetterWhere(filter domainBroadcast.DeadLetterFilter) (string, []interface{}) {
+	conditions := []string{"dl.user_id = ?"}
+	args := []interface{}{filter.UserID}
+
+	if filter.CampaignID > 0 {
+		conditions = append(conditions, "dl.campaign_id = ?")
+		args = append(args, filter.CampaignID)
+	}
+	if filter.SequenceID != "" {
+		conditions = append(conditions, "dl.sequence_id = ?")
+		args = append(args, filter.SequenceID)
+	}
+	if filter.DeviceID != "" {
+		// device_id holds either the device UUID or its name
+		conditions = append(conditions, "(dl.device_id = ? OR dl.device_name = ?)")
+		args = append(args, filter.DeviceID, filter.DeviceID)
+	}
+	if filter.ErrorClass != "" {
+		conditions = append(conditions, "dl.error_class = ?")
+		args = append(args, filter.ErrorClass)
+	}
+	if filter.ErrorCode != "" {
+		conditions = append(conditions, "dl.error_code = ?")
+		args = append(args, filter.ErrorCode)
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// List returns dead letters matching the filter, newest first, and the total count
+func (r *DeadLetterRepository) List(filter domainBroadcast.DeadLetterFilter) ([]domainBroadcast.DeadLetter, int, error) {
+	where, args := deadLetterWhere(filter)
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM broadcast_dead_letters dl WHERE `+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	limit := filter.Limit
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	rows, err := r.db.Query(`
+		SELECT dl.message_id, COALESCE(dl.user_id, ''), COALESCE(dl.device_id, ''), COALESCE(dl.device_name, ''),
+			dl.campaign_id, dl.sequence_id, COALESCE(dl.recipient_phone, ''), COALESCE(dl.recipient_name, ''),
+			COALESCE(dl.message_type, ''), dl.error_class, COALESCE(dl.error_code, ''), COALESCE(dl.error_message, ''), dl.source, dl.retries, dl.failed_at
+		FROM broadcast_dead_letters dl
+		WHERE `+where+`
+		ORDER BY dl.failed_at DESC
+		LIMIT ? OFFSET ?
+	`, append(args, limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var letters []domainBroadcast.DeadLetter
+	for rows.Next() {
+		var dl domainBroadcast.DeadLetter
+		var campaignID sql.NullInt64
+		var sequenceID sql.NullString
+		if err := rows.Scan(&dl.MessageID, &dl.UserID, &dl.DeviceID, &dl.DeviceName, &campaignID, &sequenceID,
+			&dl.RecipientPhone, &dl.RecipientName, &dl.MessageType, &dl.ErrorClass, &dl.ErrorCode, &dl.ErrorMessage,
+			&dl.Source, &dl.Retries, &dl.FailedAt); err != nil {
+			return nil, 0, err
+		}
+		if campaignID.Valid {
+			id := int(campaignID.Int64)
+			dl.CampaignID = &id
+		}
+		if sequenceID.Valid {
+			dl.SequenceID = &sequenceID.String
+		}
+		letters = append(letters, dl)
+	}
+	return letters, total, rows.Err()
+}
+
+// CountByClass returns the number of dead letters per error class for a user
+func (r *DeadLetterRepository) CountByClass(userID string) (map[string]int, error) {
+	counts := make(map[string]int, len(domainBroadcast.ErrorClasses))
+	for _, class := range domainBroadcast.ErrorClasses {
+		counts[class] = 0
+	}
+
+	rows, err := r.db.Query(`
+		SELECT error_class, COUNT(*) FROM broadcast_dead_letters
+		WHERE user_id = ? GROUP BY error_class
+	`, userID)
+	if err != nil {
+		return counts, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var class string
+		var count int
+		if err := rows.Scan(&class, &count); err != nil {
+			return counts, err
+		}
+		counts[class] += count
+	}
+	return counts, rows.Err()
+}
+
+// CountByCode returns the number of dead letters per send error code for a user
+func (r *DeadLetterRepository) CountByCode(userID string) (map[string]int, error) {
+	counts := make(map[string]int)
+	rows, err := r.db.Query(`
+		SELECT COALESCE(error_code, ?), COUNT(*) FROM broadcast_dead_letters
+		WHERE user_id = ? GROUP BY 1
+	`, string(pkgError.SendErrUnknown), userID)
+	if err != nil {
+		return counts, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var code string
+		var count int
+		if err := rows.Scan(&code, &count); err != nil {
+			return counts, err
+		}
+		counts[code] += count
+	}
+	return counts, rows.Err()
+}
+
+// selectIDs resolves the dead letters to act on: explicit IDs, or every entry
+// matching the filter when no IDs are given
+func (r *DeadLetterRepository) selectIDs(filter domainBroadcast.DeadLetterFilter, ids []string) ([]string, error) {
+	where, args := deadLetterWhere(filter)
+	if len(ids) > 0 {
+		placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+		where += " AND dl.message_id IN (" + placeholders + ")"
+		for _, id := range ids {
+			args = append(args, id)
+		}
+	}
+
+	rows, err := r.db.Query(`SELECT dl.message_id FROM broadcast_dead_letters dl WHERE `+where+` LIMIT 10000`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var selected []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		selected = append(selected, id)
+	}
+	return selected, rows.Err()
+}
+
+// Requeue puts dead letters back to pending, optionally on another device,
+// and removes them from the store. It returns how many were requeued.
+func (r *DeadLetterRepository) Requeue(filter domainBroadcast.DeadLetterFilter, ids []string, targetDeviceID, targetDeviceName string) (int, error) {
+	selected, err := r.selectIDs(filter, ids)
+	if err != nil {
+		return 0, err
+	}
+
+	requeued := 0
+	for _, id := range selected {
+		var result sql.Result
+		if targetDeviceID != "" {
+			result, err = r.db.Exec(`
+				UPDATE broadcast_messages
+				SET status = 'pending', error_message = NULL, processing_worker_id = NULL,
+					device_id = ?, device_name = ?, scheduled_at = DATE_ADD(NOW(), INTERVAL 8 HOUR), updated_at = NOW()
+				WHERE id = ? AND status = 'failed'
+			`, targetDeviceID, targetDeviceName, id)
+		} else {
+			result, err = r.db.Exec(`
+				UPDATE broadcast_messages
+				SET status = 'pending', error_message = NULL, processing_worker_id = NULL,
+					scheduled_at = DATE_ADD(NOW(), INTERVAL 8 HOUR), updated_at = NOW()
+				WHERE id = ? AND status = 'failed'
+			`, id)
+		}
+		if err != nil {
+			return requeued, fmt.Errorf("failed to requeue message %s: %w", id, err)
+		}
+		if affected, _ := result.RowsAffected(); affected > 0 {
+			requeued++
+		}
+		// Drop the entry either way; a message no longer failed is not a dead letter
+		if _, err := r.db.Exec(`DELETE FROM broadcast_dead_letters WHERE message_id = ?`, id); err != nil {
+			return requeued, err
+		}
+	}
+	return requeued, nil
+}
+
+// Discard gives up on dead letters for good. The messages are marked
+// 'discarded' so they are not picked up by the failed-message sync again.
+func (r *DeadLetterRepository) Discard(filter domainBroadcast.DeadLetterFilter, ids []string) (int, error) {
+	selected, err := r.selectIDs(filter, ids)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, id := range selected {
+		if _, err := r.db.Exec(`
+			UPDATE broadcast_messages SET status = 'discarded', updated_at = NOW()
+			WHERE id = ? AND status = 'failed'
+		`, id); err != nil {
+			return 0, fmt.Errorf("failed to discard message %s: %w", id, err)
+		}
+		if _, err := r.db.Exec(`DELETE FROM broadcast_dead_letters WHERE message_id = ?`, id); err != nil {
+			return 0, err
+		}
+	}
+	return len(selected), nil
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+	"sync"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/database"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/models"
+	"github.com/sirupsen/logrus"
+)
+
+// HistoryPolicyRepository stores each device's history-sync policy
+type HistoryPolicyRepository struct {
+	db *sql.DB
+}
+
+var (
+	historyPolicyRepo     *HistoryPolicyRepository
+	historyPolicyRepoOnce sync.Once
+)
+
+// GetHistoryPolicyRepository returns the history policy repository
+func GetHistoryPolicyRepository() *HistoryPolicyRepository {
+	historyPolicyRepoOnce.Do(func() {
+		historyPolicyRepo = &HistoryPolicyRepository{db: database.GetDB()}
+	})
+	historyPolicySchema.Ensure(historyPolicyRepo.db)
+	return historyPolicyRepo
+}
+
+// historyPolicySchema creates the policy table
+var historyPolicySchema = database.RegisterSchema("device_history_policies", database.Statement(`
+			CREATE TABLE IF NOT EXISTS device_history_policies (
+				device_id VARCHAR(36) PRIMARY KEY,
+				keep_days INT NOT NULL DEFAULT 180,
+				chat_types VARCHAR(64) NOT NULL DEFAULT 'personal',
+				auto_leads BOOLEAN NOT NULL DEFAULT FALSE,
+				lead_niche VARCHAR(255) NOT NULL DEFAULT '',
+				lead_status VARCHAR(20) NOT NULL DEFAULT 'prospect',
+				lead_trigger VARCHAR(255) NOT NULL DEFAULT '',
+				updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+			)
+		`))
+
+// Get returns a device's policy, or the default policy when it has none
+func (r *HistoryPolicyRepository) Get(deviceID string) (models.HistoryPolicy, error) {
+	policy := models.HistoryPolicy{DeviceID: deviceID}
+	var chatTypes string
+	err := r.db.QueryRow(`
+		SELECT keep_days, chat_types, auto_leads, lead_niche, lead_status, lead_trigger, updated_at
+		FROM device_history_policies WHERE device_id = ?
+	`, deviceID).Scan(&policy.KeepDays, &chatTypes, &policy.AutoLeads, &policy.LeadNiche, &policy.LeadStatus,
+		&policy.LeadTrigger, &policy.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return models.DefaultHistoryPolicy(deviceID), nil
+	}
+	if err != nil {
+		return policy, err
+	}
+	policy.ChatTypes = strings.Split(chatTypes, ",")
+	return policy, nil
+}
+
+// Save stores a device's policy, replacing the one it had
+func (r *HistoryPolicyRepository) Save(policy models.HistoryPolicy) error {
+	_, err := r.db.Exec(`
+		INSERT INTO device_history_policies (device_id, keep_days, chat_types, auto_leads, lead_niche, lead_status, lead_trigger)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE keep_days = VALUES(keep_days), chat_types = VALUES(chat_types),
+			auto_leads = VALUES(auto_leads), lead_niche = VALUES(lead_niche), lead_status = VALUES(lead_status),
+			lead_trigger = VALUES(lead_trigger), updated_at = CURRENT_TIMESTAMP
+	`, policy.DeviceID, policy.KeepDays, strings.Join(policy.ChatTypes, ","), policy.AutoLeads, policy.LeadNiche,
+		policy.LeadStatus, policy.LeadTrigger)
+	return err
+}
+
+// HistoryPolicy returns a device's history policy, falling back to the
+// default when it cannot be read so history keeps flowing
+func HistoryPolicy(deviceID string) models.HistoryPolicy {
+	policy, err := GetHistoryPolicyRepository().Get(deviceID)
+	if err != nil {
+		logrus.Warnf("Failed to load history policy of device %s, using the default: %v", deviceID, err)
+		return models.DefaultHistoryPolicy(deviceID)
+	}
+	return policy
+}
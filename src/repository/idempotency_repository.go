@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/database"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/scheduler"
+	"github.com/sirupsen/logrus"
+)
+
+// IdempotencyLease is how long a running request holds its key without
+// renewing it. A key whose lease ran out, as after a crash, is taken over by
+// the next retry; the request holding it calls Renew well before that.
+const IdempotencyLease = 2 * time.Minute
+
+// IdempotencyRecord is a request made with an idempotency key and, once it
+// completed, the response it got
+type IdempotencyRecord struct {
+	Scope       string
+	Key         string
+	Fingerprint string
+	Done        bool
+	StatusCode  int
+	ContentType string
+	Body        []byte
+	CreatedAt   time.Time
+}
+
+// IdempotencyRepository stores the idempotency keys of API and webhook requests
+type IdempotencyRepository struct {
+	db *sql.DB
+}
+
+var (
+	idempotencyRepo     *IdempotencyRepository
+	idempotencyRepoOnce sync.Once
+)
+
+// GetIdempotencyRepository returns the idempotency repository
+func GetIdempotencyRepository() *IdempotencyRepository {
+	idempotencyRepoOnce.Do(func() {
+		idempotencyRepo = &IdempotencyRepository{db: database.GetDB()}
+	})
+	idempotencySchema.Ensure(idempotencyRepo.db)
+	return idempotencyRepo
+}
+
+// idempotencySchema creates the key table
+var idempotencySchema = database.RegisterSchema("idempotency_keys", database.Statement(`
+			CREATE TABLE IF NOT EXISTS idempotency_keys (
+				scope VARCHAR(64) NOT NULL,
+				idem_key VARCHAR(191) NOT NULL,
+				fingerprint VARCHAR(64) NOT NULL,
+				state VARCHAR(10) NOT NULL,
+				status_code INT NOT NULL DEFAULT 0,
+				content_type VARCHAR(100) NOT NULL DEFAULT '',
+				body MEDIUMTEXT,
+				created_at TIMESTAMP NOT NULL,
+				expires_at TIMESTAMP NOT NULL,
+				PRIMARY KEY (scope, idem_key),
+				INDEX idx_idempotency_expires (expires_at)
+			)
+		`))
+
+// Begin claims a key for a request for one lease. It returns nil when the
+// request holds the key now, otherwise the record of the request that holds
+// it. A running key expires with its lease and a completed one with its ttl.
+func (r *IdempotencyRepository) Begin(scope, key, fingerprint string) (*IdempotencyRecord, error) {
+	now := time.Now().UTC()
+	_, err := r.db.Exec(`
+		DELETE FROM idempotency_keys
+		WHERE scope = ? AND idem_key = ? AND expires_at < ?
+	`, scope, key, now)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := r.db.Exec(`
+		INSERT INTO idempotency_keys (scope, idem_key, fingerprint, state, created_at, expires_at)
+		VALUES (?, ?, ?, 'running', ?, ?)
+		ON DUPLICATE KEY UPDATE scope = scope
+	`, scope, key, fingerprint, now, now.Add(IdempotencyLease))
+	if err != nil {
+		return nil, err
+	}
+	if affected, _ := result.RowsAffected(); affected > 0 {
+		return nil, nil
+	}
+
+	record := IdempotencyRecord{Scope: scope, Key: key}
+	var state string
+	var body sql.NullString
+	err = r.db.QueryRow(`
+		SELECT fingerprint, state, status_code, content_type, body, created_at
+		FROM idempotency_keys WHERE scope = ? AND idem_key = ?
+	`, scope, key).Scan(&record.Fingerprint, &state, &record.StatusCode, &record.ContentType, &body, &record.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	record.Done = state == "done"
+	record.Body = []byte(body.String)
+	return &record, nil
+}
+
+// Renew extends the lease of a running request on its key
+func (r *IdempotencyRepository) Renew(scope, key string) error {
+	_, err := r.db.Exec(`
+		UPDATE idempotency_keys SET expires_at = ?
+		WHERE scope = ? AND idem_key = ? AND state = 'running'
+	`, time.Now().UTC().Add(IdempotencyLease), scope, key)
+	return err
+}
+
+// Complete stores the response of the request holding a key, to be replayed
+// to retries until ttl passes
+func (r *IdempotencyRepository) Complete(scope, key string, statusCode int, contentType string, body []byte, ttl time.Duration) error {
+	_, err := r.db.Exec(`
+		UPDATE idempotency_keys SET state = 'done', status_code = ?, content_type = ?, body = ?, expires_at = ?
+		WHERE scope = ? AND idem_key = ?
+	`, statusCode, contentType, string(body), time.Now().UTC().Add(ttl), scope, key)
+	return err
+}
+
+// Release gives a key up so a retry runs the request again
+func (r *IdempotencyRepository) Release(scope, key string) error {
+	_, err := r.db.Exec(`DELETE FROM idempotency_keys WHERE scope = ? AND idem_key = ?`, scope, key)
+	return err
+}
+
+// DeleteExpired removes the keys whose time is up
+func (r *IdempotencyRepository) DeleteExpired() (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM idempotency_keys WHERE expires_at < ?`, time.Now().UTC())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// StartIdempotencyKeyCleanup schedules the removal of expired idempotency keys
+func StartIdempotencyKeyCleanup() {
+	scheduler.Default().MustRegister(scheduler.Job{
+		Name:      "idempotency-key-cleanup",
+		Interval:  time.Hour,
+		Singleton: true,
+		Run: func(ctx context.Context) error {
+			deleted, err := GetIdempotencyRepository().DeleteExpired()
+			if deleted > 0 {
+				logrus.Infof("Removed %d expired idempotency keys", deleted)
+			}
+			return err
+		},
+	})
+}
@@ -7,6 +7,8 @@ import (
 	
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/database"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/models"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/listing"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
 	"github.com/sirupsen/logrus"
 )
 
@@ -14,6 +16,7 @@ type LeadAIRepository interface {
 	CreateLeadAI(lead *models.LeadAI) error
 	GetLeadAIByID(id int) (*models.LeadAI, error)
 	GetLeadAIByUser(userID string) ([]models.LeadAI, error)
+	ListLeadAIByUser(userID string, q listing.Query) ([]models.LeadAI, utils.Page, error)
 	GetPendingLeadAI(userID string) ([]models.LeadAI, error)
 	GetLeadAIByNiche(userID, niche string) ([]models.LeadAI, error)
 	GetLeadAIByNicheAndStatus(userID, niche, targetStatus string) ([]models.LeadAI, error)
@@ -105,6 +108,64 @@ func (r *leadAIRepository) GetLeadAIByUser(userID string) ([]models.LeadAI, erro
 	return r.getLeadAIList(query, userID)
 }
 
+// LeadAIListFields are the sort, filter and search fields of the AI lead list
+var LeadAIListFields = listing.Fields{
+	Columns: map[string]string{
+		"id":            "id",
+		"name":          "name",
+		"phone":         "phone",
+		"email":         "COALESCE(email, '')",
+		"niche":         "niche",
+		"source":        "COALESCE(source, '')",
+		"status":        "status",
+		"target_status": "COALESCE(target_status, '')",
+		"notes":         "COALESCE(notes, '')",
+		"created_at":    "created_at",
+	},
+	Sort:        []string{"name", "phone", "status", "created_at"},
+	Filter:      []string{"status", "target_status", "niche", "source"},
+	Search:      []string{"name", "phone", "email", "niche", "notes"},
+	Key:         "id",
+	DefaultSort: "-created_at",
+}
+
+// ListLeadAIByUser returns one page of a user's AI leads
+func (r *leadAIRepository) ListLeadAIByUser(userID string, q listing.Query) ([]models.LeadAI, utils.Page, error) {
+	where, args := q.Where()
+	var total int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM leads_ai WHERE user_id = ?`+where, append([]any{userID}, args...)...).Scan(&total)
+	if err != nil {
+		return nil, utils.Page{}, err
+	}
+	
+	seek, args := q.Seek()
+	leads, err := r.getLeadAIList(`
+		SELECT id, user_id, device_id, name, phone, email, niche, source, 
+		       status, target_status, notes, assigned_at, sent_at, created_at, updated_at
+		FROM leads_ai
+		WHERE user_id = ?`+seek, append([]any{userID}, args...)...)
+	if err != nil {
+		return nil, utils.Page{}, err
+	}
+	leads, page := listing.Trim(leads, q, total, leadAIValue)
+	return leads, page, nil
+}
+
+// leadAIValue returns an AI lead's value of a LeadAIListFields field
+func leadAIValue(lead models.LeadAI, field string) any {
+	switch field {
+	case "name":
+		return lead.Name
+	case "phone":
+		return lead.Phone
+	case "status":
+		return lead.Status
+	case "created_at":
+		return lead.CreatedAt
+	}
+	return lead.ID
+}
+
 func (r *leadAIRepository) GetPendingLeadAI(userID string) ([]models.LeadAI, error) {
 	query := `
 
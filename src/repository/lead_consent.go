@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/database"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/models"
+	"github.com/sirupsen/logrus"
+)
+
+// leadConsentsSchema creates the consent history
+var leadConsentsSchema = database.RegisterSchema("lead_consents", database.Statement(`
+			CREATE TABLE IF NOT EXISTS lead_consents (
+				id BIGINT AUTO_INCREMENT PRIMARY KEY,
+				user_id VARCHAR(36) NOT NULL,
+				phone VARCHAR(50) NOT NULL,
+				source VARCHAR(255) NOT NULL DEFAULT '',
+				channel VARCHAR(32) NOT NULL,
+				proof_text TEXT,
+				given_at TIMESTAMP NOT NULL,
+				recorded_at TIMESTAMP NOT NULL,
+				INDEX idx_lead_consents_lead (user_id, phone, given_at)
+			)
+		`))
+
+// RecordConsent adds a consent to a lead's history. GivenAt defaults to now.
+func (r *leadRepository) RecordConsent(consent *models.LeadConsent) error {
+	consent.RecordedAt = time.Now().UTC()
+	if consent.GivenAt.IsZero() {
+		consent.GivenAt = consent.RecordedAt
+	}
+	result, err := r.db.Exec(`
+		INSERT INTO lead_consents (user_id, phone, source, channel, proof_text, given_at, recorded_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, consent.UserID, consent.Phone, consent.Source, consent.Channel, consent.ProofText,
+		consent.GivenAt.UTC(), consent.RecordedAt)
+	if err != nil {
+		return err
+	}
+	consent.ID, _ = result.LastInsertId()
+	return nil
+}
+
+// LeadConsents returns the consents recorded for a phone, oldest first
+func (r *leadRepository) LeadConsents(userID, phone string) ([]models.LeadConsent, error) {
+	rows, err := r.db.Query(`
+		SELECT id, user_id, phone, source, channel, COALESCE(proof_text, ''), given_at, recorded_at
+		FROM lead_consents
+		WHERE user_id = ? AND phone = ?
+		ORDER BY given_at, id
+	`, userID, phone)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var consents []models.LeadConsent
+	for rows.Next() {
+		var consent models.LeadConsent
+		if err := rows.Scan(&consent.ID, &consent.UserID, &consent.Phone, &consent.Source, &consent.Channel,
+			&consent.ProofText, &consent.GivenAt, &consent.RecordedAt); err != nil {
+			return nil, err
+		}
+		consents = append(consents, consent)
+	}
+	return consents, rows.Err()
+}
+
+// recordLeadConsent records the consent a new lead was created with. The
+// lead is already stored, so a failure is logged rather than returned.
+func (r *leadRepository) recordLeadConsent(lead *models.Lead) {
+	if lead.Consent == nil {
+		return
+	}
+	lead.Consent.UserID, lead.Consent.Phone = lead.UserID, lead.Phone
+	if err := r.RecordConsent(lead.Consent); err != nil {
+		logrus.Warnf("Failed to record consent of lead %s: %v", lead.Phone, err)
+	}
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/database"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/models"
+	"github.com/sirupsen/logrus"
+)
+
+// LeadActorShareLink is the actor of changes made through a device share link
+const LeadActorShareLink = "share_link"
+
+// LeadActorFlow is the actor of changes a sequence flow makes
+func LeadActorFlow(sequenceID string) string {
+	return "flow:" + sequenceID
+}
+
+// leadEventsSchema creates the lead change log
+var leadEventsSchema = database.RegisterSchema("lead_events", database.Statement(`
+			CREATE TABLE IF NOT EXISTS lead_events (
+				id BIGINT AUTO_INCREMENT PRIMARY KEY,
+				user_id VARCHAR(36) NOT NULL,
+				phone VARCHAR(50) NOT NULL,
+				kind VARCHAR(20) NOT NULL,
+				actor VARCHAR(255) NOT NULL,
+				from_value VARCHAR(255) NOT NULL DEFAULT '',
+				to_value VARCHAR(255) NOT NULL DEFAULT '',
+				text TEXT,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				INDEX idx_lead_events_lead (user_id, phone, created_at)
+			)
+		`))
+
+// RecordEvent adds a change to a lead's timeline
+func (r *leadRepository) RecordEvent(event *models.LeadEvent) error {
+	result, err := r.db.Exec(`
+		INSERT INTO lead_events (user_id, phone, kind, actor, from_value, to_value, text)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, event.UserID, event.Phone, event.Kind, event.Actor, event.From, event.To, event.Text)
+	if err != nil {
+		return err
+	}
+	event.ID, _ = result.LastInsertId()
+	return nil
+}
+
+// RecordStatusChange logs a lead's target status change, if it changed.
+// Failures are logged rather than failing the update they describe.
+func (r *leadRepository) RecordStatusChange(userID, phone, actor, from, to string) {
+	if from == to {
+		return
+	}
+	err := r.RecordEvent(&models.LeadEvent{UserID: userID, Phone: phone, Kind: models.LeadTimelineStatusChange,
+		Actor: actor, From: from, To: to})
+	if err != nil {
+		logrus.Warnf("Failed to record status change of lead %s: %v", phone, err)
+	}
+}
+
+// SetTargetStatus changes the target status of a user's leads with a phone,
+// recording the change on their timeline
+func (r *leadRepository) SetTargetStatus(userID, phone, status, actor string) error {
+	var current sql.NullString
+	err := r.db.QueryRow(`SELECT target_status FROM leads WHERE phone = ? AND user_id = ? LIMIT 1`, phone, userID).
+		Scan(&current)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	found := err == nil
+	if _, err := r.db.Exec(`UPDATE leads SET target_status = ?, updated_at = NOW() WHERE phone = ? AND user_id = ?`,
+		status, phone, userID); err != nil {
+		return err
+	}
+	if found {
+		r.RecordStatusChange(userID, phone, actor, current.String, status)
+	}
+	return nil
+}
+
+// ListEvents returns the changes recorded for a user's lead, oldest first
+func (r *leadRepository) ListEvents(userID, phone string) ([]models.LeadEvent, error) {
+	rows, err := r.db.Query(`
+		SELECT id, user_id, phone, kind, actor, from_value, to_value, COALESCE(text, ''), created_at
+		FROM lead_events
+		WHERE user_id = ? AND phone = ?
+		ORDER BY created_at, id
+	`, userID, phone)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.LeadEvent{}
+	for rows.Next() {
+		var event models.LeadEvent
+		if err := rows.Scan(&event.ID, &event.UserID, &event.Phone, &event.Kind, &event.Actor,
+			&event.From, &event.To, &event.Text, &event.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
@@ -9,6 +9,8 @@ import (
 
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/database"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/models"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/listing"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
 	"github.com/sirupsen/logrus"
 )
 
@@ -25,6 +27,9 @@ func GetLeadRepository() *leadRepository {
 			db: database.GetDB(),
 		}
 	}
+	leadEventsSchema.Ensure(leadRepo.db)
+	leadConsentsSchema.Ensure(leadRepo.db)
+	LeadNumberSchema.Ensure(leadRepo.db)
 	return leadRepo
 }
 
@@ -59,6 +64,7 @@ func (r *leadRepository) CreateLead(lead *models.Lead) error {
 	
 	if err == nil {
 		lead.ID = fmt.Sprintf("%d", id)
+		r.recordLeadConsent(lead)
 	}
 		
 	return err
@@ -140,6 +146,8 @@ func (r *leadRepository) GetLeadsByDeviceNicheAndStatus(deviceID, niche, status
 		WHERE device_id = ?
 		AND (? = '' OR niche LIKE CONCAT('%', ?, '%'))
 		AND (? = '' OR target_status = ?)
+		-- Numbers known not to be on WhatsApp are never targeted
+		AND COALESCE(number_status, '') <> 'invalid'
 		ORDER BY created_at DESC
 	`
 	
@@ -235,7 +243,8 @@ func (r *leadRepository) GetNewLeadsForSequence(niche, sequenceID string) ([]mod
 // GetLeadsByDevice gets all leads for a specific user's device
 func (r *leadRepository) GetLeadsByDevice(userID, deviceID string) ([]models.Lead, error) {
 	query := `
-		SELECT id, device_id, user_id, name, phone, niche, journey, status, target_status, ` + "`trigger`" + `, created_at, updated_at
+		SELECT id, device_id, user_id, name, phone, niche, journey, status, target_status, ` + "`trigger`" + `, created_at, updated_at,
+			COALESCE(number_status, 'unchecked')
 		FROM leads
 		WHERE user_id = ? AND device_id = ?
 		ORDER BY created_at DESC
@@ -247,6 +256,73 @@ func (r *leadRepository) GetLeadsByDevice(userID, deviceID string) ([]models.Lea
 	}
 	defer rows.Close()
 	
+	return scanDeviceLeads(rows), nil
+}
+
+// LeadListFields are the sort, filter and search fields of a device's lead list
+var LeadListFields = listing.Fields{
+	Columns: map[string]string{
+		"id":            "id",
+		"name":          "name",
+		"phone":         "phone",
+		"niche":         "niche",
+		"journey":       "COALESCE(journey, '')",
+		"status":        "status",
+		"target_status": "COALESCE(target_status, '')",
+		"trigger":       "COALESCE(`trigger`, '')",
+		"number_status": "COALESCE(number_status, 'unchecked')",
+		"created_at":    "created_at",
+		"updated_at":    "updated_at",
+	},
+	Sort:        []string{"name", "phone", "created_at", "updated_at"},
+	Filter:      []string{"status", "target_status", "niche", "trigger", "number_status"},
+	Search:      []string{"name", "phone", "niche", "trigger", "journey"},
+	Key:         "id",
+	DefaultSort: "-created_at",
+}
+
+// ListLeadsByDevice returns one page of a device's leads
+func (r *leadRepository) ListLeadsByDevice(userID, deviceID string, q listing.Query) ([]models.Lead, utils.Page, error) {
+	where, args := q.Where()
+	var total int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM leads WHERE user_id = ? AND device_id = ?`+where,
+		append([]any{userID, deviceID}, args...)...).Scan(&total)
+	if err != nil {
+		return nil, utils.Page{}, err
+	}
+	
+	seek, args := q.Seek()
+	rows, err := r.db.Query(`
+		SELECT id, device_id, user_id, name, phone, niche, journey, status, target_status, `+"`trigger`"+`, created_at, updated_at,
+			COALESCE(number_status, 'unchecked')
+		FROM leads
+		WHERE user_id = ? AND device_id = ?`+seek, append([]any{userID, deviceID}, args...)...)
+	if err != nil {
+		return nil, utils.Page{}, err
+	}
+	defer rows.Close()
+	
+	leads, page := listing.Trim(scanDeviceLeads(rows), q, total, leadValue)
+	return leads, page, nil
+}
+
+// leadValue returns a lead's value of a LeadListFields field
+func leadValue(lead models.Lead, field string) any {
+	switch field {
+	case "name":
+		return lead.Name
+	case "phone":
+		return lead.Phone
+	case "created_at":
+		return lead.CreatedAt
+	case "updated_at":
+		return lead.UpdatedAt
+	}
+	return lead.ID
+}
+
+// scanDeviceLeads reads the rows of GetLeadsByDevice and ListLeadsByDevice
+func scanDeviceLeads(rows *sql.Rows) []models.Lead {
 	var leads []models.Lead
 	for rows.Next() {
 		var lead models.Lead
@@ -256,7 +332,7 @@ func (r *leadRepository) GetLeadsByDevice(userID, deviceID string) ([]models.Lea
 		
 		err := rows.Scan(&lead.ID, &lead.DeviceID, &lead.UserID, &lead.Name, &lead.Phone,
 			&lead.Niche, &journey, &lead.Status, &targetStatus, &trigger,
-			&lead.CreatedAt, &lead.UpdatedAt)
+			&lead.CreatedAt, &lead.UpdatedAt, &lead.NumberStatus)
 		if err != nil {
 			continue
 		}
@@ -279,7 +355,7 @@ func (r *leadRepository) GetLeadsByDevice(userID, deviceID string) ([]models.Lea
 		leads = append(leads, lead)
 	}
 	
-	return leads, nil
+	return leads
 }
 
 // UpdateLead updates an existing lead
@@ -307,8 +383,17 @@ func (r *leadRepository) UpdateLead(id string, lead *models.Lead) error {
 		lead.TargetStatus = "prospect"
 	}
 	
-	result, err := r.db.Exec(query, id, lead.DeviceID, lead.Name, lead.Phone,
-		lead.Niche, journey, status, lead.TargetStatus, lead.Trigger, lead.UpdatedAt)
+	// Read the current status so the change can go on the lead's timeline
+	var previousStatus sql.NullString
+	if err := r.db.QueryRow(`SELECT target_status FROM leads WHERE id = ?`, id).Scan(&previousStatus); err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("lead not found")
+		}
+		return err
+	}
+	
+	result, err := r.db.Exec(query, lead.DeviceID, lead.Name, lead.Phone,
+		lead.Niche, journey, status, lead.TargetStatus, lead.Trigger, lead.UpdatedAt, id)
 	if err != nil {
 		return err
 	}
@@ -318,6 +403,7 @@ func (r *leadRepository) UpdateLead(id string, lead *models.Lead) error {
 		return fmt.Errorf("lead not found")
 	}
 	
+	r.RecordStatusChange(lead.UserID, lead.Phone, lead.UserID, previousStatus.String, lead.TargetStatus)
 	return nil
 }
 
@@ -0,0 +1,224 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/models"
+)
+
+// GetLeadForUser returns one of a user's leads, or sql.ErrNoRows when the
+// user has no lead with that ID
+func (r *leadRepository) GetLeadForUser(userID, id string) (*models.Lead, error) {
+	rows, err := r.db.Query(`
+		SELECT id, device_id, user_id, name, phone, niche, journey, status, target_status, `+"`trigger`"+`, created_at, updated_at,
+			COALESCE(number_status, 'unchecked')
+		FROM leads
+		WHERE user_id = ? AND id = ?
+	`, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	leads := scanDeviceLeads(rows)
+	if len(leads) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return &leads[0], nil
+}
+
+// LeadTimeline merges everything recorded about a lead, matched by phone
+// across the user's devices, into one feed in chronological order: campaign
+// and sequence sends with their receipts, chat messages both ways, sequence
+// completions, status and tag changes and notes.
+func (r *leadRepository) LeadTimeline(lead *models.Lead) ([]models.LeadTimelineEntry, error) {
+	entries := []models.LeadTimelineEntry{{
+		ID:       "lead:" + lead.ID,
+		Kind:     models.LeadTimelineCreated,
+		At:       lead.CreatedAt,
+		DeviceID: lead.DeviceID,
+		Text:     lead.Name,
+	}}
+
+	for _, source := range []func(*models.Lead) ([]models.LeadTimelineEntry, error){
+		r.broadcastTimeline, r.chatTimeline, r.sequenceTimeline, r.eventTimeline,
+	} {
+		found, err := source(lead)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, found...)
+	}
+
+	slices.SortStableFunc(entries, func(a, b models.LeadTimelineEntry) int {
+		return a.At.Compare(b.At)
+	})
+	return entries, nil
+}
+
+// broadcastTimeline lists the lead's campaign and sequence sends, each
+// followed by its delivery and read receipts
+func (r *leadRepository) broadcastTimeline(lead *models.Lead) ([]models.LeadTimelineEntry, error) {
+	rows, err := r.db.Query(`
+		SELECT bm.id, bm.device_id, bm.campaign_id, COALESCE(bm.sequence_id, ''), COALESCE(c.title, s.name, ''),
+			COALESCE(ss.day_number, 0), COALESCE(bm.content, ''), bm.status, COALESCE(bm.error_message, ''),
+			bm.created_at, bm.sent_at, bm.delivered_at, bm.read_at
+		FROM broadcast_messages bm
+		LEFT JOIN campaigns c ON c.id = bm.campaign_id
+		LEFT JOIN sequences s ON s.id = bm.sequence_id
+		LEFT JOIN sequence_steps ss ON ss.id = bm.sequence_stepid
+		WHERE bm.user_id = ? AND bm.recipient_phone = ?
+	`, lead.UserID, lead.Phone)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []models.LeadTimelineEntry
+	for rows.Next() {
+		var send models.LeadTimelineEntry
+		var id string
+		var campaignID sql.NullInt64
+		var createdAt time.Time
+		var sentAt, deliveredAt, readAt sql.NullTime
+		if err := rows.Scan(&id, &send.DeviceID, &campaignID, &send.SequenceID, &send.Source, &send.Step,
+			&send.Text, &send.Status, &send.Error, &createdAt, &sentAt, &deliveredAt, &readAt); err != nil {
+			return nil, err
+		}
+
+		send.ID, send.Kind, send.At = "broadcast:"+id, models.LeadTimelineSequenceStep, createdAt
+		if campaignID.Valid {
+			campaign := int(campaignID.Int64)
+			send.CampaignID, send.Kind = &campaign, models.LeadTimelineCampaignSend
+		}
+		if sentAt.Valid {
+			send.At = sentAt.Time
+		}
+		entries = append(entries, send)
+
+		for _, receipt := range []struct {
+			kind string
+			at   sql.NullTime
+		}{{models.LeadTimelineDelivered, deliveredAt}, {models.LeadTimelineRead, readAt}} {
+			if !receipt.at.Valid {
+				continue
+			}
+			entries = append(entries, models.LeadTimelineEntry{
+				ID:         send.ID + ":" + receipt.kind,
+				Kind:       receipt.kind,
+				At:         receipt.at.Time,
+				DeviceID:   send.DeviceID,
+				Source:     send.Source,
+				CampaignID: send.CampaignID,
+				SequenceID: send.SequenceID,
+				Step:       send.Step,
+			})
+		}
+	}
+	return entries, rows.Err()
+}
+
+// chatTimeline lists the messages stored for the lead's chat on any of the
+// user's devices
+func (r *leadRepository) chatTimeline(lead *models.Lead) ([]models.LeadTimelineEntry, error) {
+	rows, err := r.db.Query(`
+		SELECT m.id, m.device_id, COALESCE(m.message_text, ''), COALESCE(m.message_type, 'text'), `+messageDirection+`, m.timestamp
+		FROM whatsapp_messages m
+		JOIN user_devices d ON d.id = m.device_id
+		WHERE d.user_id = ? AND m.chat_jid = ?
+	`, lead.UserID, LeadChatJID(lead.Phone))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []models.LeadTimelineEntry
+	for rows.Next() {
+		var entry models.LeadTimelineEntry
+		var id, timestamp int64
+		var messageType, direction string
+		if err := rows.Scan(&id, &entry.DeviceID, &entry.Text, &messageType, &direction, &timestamp); err != nil {
+			return nil, err
+		}
+		entry.ID, entry.At = fmt.Sprintf("message:%d", id), time.Unix(timestamp, 0)
+		entry.Kind = models.LeadTimelineMessageIn
+		if direction == "out" {
+			entry.Kind = models.LeadTimelineMessageOut
+		}
+		if entry.Text == "" && messageType != "text" {
+			entry.Text = "[" + messageType + "]"
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// sequenceTimeline lists the sequences the lead has completed
+func (r *leadRepository) sequenceTimeline(lead *models.Lead) ([]models.LeadTimelineEntry, error) {
+	rows, err := r.db.Query(`
+		SELECT sc.id, sc.sequence_id, s.name, sc.completed_at
+		FROM sequence_contacts sc
+		JOIN sequences s ON s.id = sc.sequence_id
+		WHERE s.user_id = ? AND sc.contact_phone = ? AND sc.completed_at IS NOT NULL
+	`, lead.UserID, lead.Phone)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []models.LeadTimelineEntry
+	for rows.Next() {
+		entry := models.LeadTimelineEntry{Kind: models.LeadTimelineSequenceCompleted}
+		var id string
+		if err := rows.Scan(&id, &entry.SequenceID, &entry.Source, &entry.At); err != nil {
+			return nil, err
+		}
+		entry.ID = "sequence:" + id
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// eventTimeline lists the status changes, tag changes and notes recorded
+// for the lead
+func (r *leadRepository) eventTimeline(lead *models.Lead) ([]models.LeadTimelineEntry, error) {
+	events, err := r.ListEvents(lead.UserID, lead.Phone)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]models.LeadTimelineEntry, 0, len(events))
+	for _, event := range events {
+		entry := models.LeadTimelineEntry{
+			ID:    fmt.Sprintf("event:%d", event.ID),
+			Kind:  event.Kind,
+			At:    event.CreatedAt,
+			Actor: event.Actor,
+			From:  event.From,
+			To:    event.To,
+		}
+		if event.Kind == models.LeadTimelineNote {
+			entry.Text = event.Text
+		} else {
+			entry.Tag = event.Text
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// LeadChatJID is the personal chat JID of a lead's phone number, however
+// the number was written
+func LeadChatJID(phone string) string {
+	digits := strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, phone)
+	return digits + "@s.whatsapp.net"
+}
@@ -0,0 +1,273 @@
+package repository
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/database"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// MediaAsset is a media file kept in the asset store. Content is stored once
+// per hash; each user who adds the same content gets their own row.
+type MediaAsset struct {
+	ID           string    `json:"id"`
+	UserID       string    `json:"user_id"`
+	SHA256       string    `json:"sha256"`
+	MimeType     string    `json:"mimetype"`
+	Size         int64     `json:"size"`
+	Width        int       `json:"width,omitempty"`
+	Height       int       `json:"height,omitempty"`
+	FileName     string    `json:"file_name,omitempty"`
+	SourceURL    string    `json:"source_url,omitempty"`
+	StorageKey   string    `json:"-"`
+	ThumbnailKey string    `json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// MediaUpload is a WhatsApp upload of some content by one device, reused for
+// every recipient until it expires
+type MediaUpload struct {
+	DeviceID      string
+	SHA256        string
+	MediaType     string
+	URL           string
+	DirectPath    string
+	MediaKey      []byte
+	FileEncSHA256 []byte
+	FileSHA256    []byte
+	FileLength    uint64
+	ExpiresAt     time.Time
+}
+
+// MediaAssetRepository stores media assets and cached WhatsApp uploads
+type MediaAssetRepository struct {
+	db *sql.DB
+}
+
+var (
+	mediaAssetRepo     *MediaAssetRepository
+	mediaAssetRepoOnce sync.Once
+)
+
+// GetMediaAssetRepository returns the media asset repository
+func GetMediaAssetRepository() *MediaAssetRepository {
+	mediaAssetRepoOnce.Do(func() {
+		mediaAssetRepo = &MediaAssetRepository{db: database.GetDB()}
+	})
+	mediaAssetSchema.Ensure(mediaAssetRepo.db)
+	return mediaAssetRepo
+}
+
+// mediaAssetSchema creates the media tables
+var mediaAssetSchema = database.RegisterSchema("media_assets",
+	database.Statement(`
+			CREATE TABLE IF NOT EXISTS media_assets (
+				id VARCHAR(36) PRIMARY KEY,
+				user_id VARCHAR(36) NOT NULL DEFAULT '',
+				sha256 CHAR(64) NOT NULL,
+				mimetype VARCHAR(100) NOT NULL,
+				size BIGINT NOT NULL DEFAULT 0,
+				width INT NOT NULL DEFAULT 0,
+				height INT NOT NULL DEFAULT 0,
+				file_name VARCHAR(255) NOT NULL DEFAULT '',
+				source_url TEXT NULL,
+				source_hash CHAR(64) NULL,
+				storage_key VARCHAR(255) NOT NULL,
+				thumbnail_key VARCHAR(255) NOT NULL DEFAULT '',
+				fetched_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				UNIQUE KEY uniq_media_assets_user_sha (user_id, sha256),
+				INDEX idx_media_assets_source (source_hash),
+				INDEX idx_media_assets_sha (sha256)
+			)`),
+	database.Statement(`
+			CREATE TABLE IF NOT EXISTS media_uploads (
+				device_id VARCHAR(255) NOT NULL,
+				sha256 CHAR(64) NOT NULL,
+				media_type VARCHAR(40) NOT NULL,
+				url TEXT NOT NULL,
+				direct_path TEXT NOT NULL,
+				media_key VARBINARY(64) NOT NULL,
+				file_enc_sha256 VARBINARY(64) NOT NULL,
+				file_sha256 VARBINARY(64) NOT NULL,
+				file_length BIGINT NOT NULL,
+				expires_at DATETIME NOT NULL,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				PRIMARY KEY (device_id, sha256, media_type),
+				INDEX idx_media_uploads_expires (expires_at)
+			)`),
+)
+
+// sourceHash indexes source URLs, which are too long for a plain index
+func sourceHash(sourceURL string) interface{} {
+	if sourceURL == "" {
+		return nil
+	}
+	sum := sha256.Sum256([]byte(sourceURL))
+	return hex.EncodeToString(sum[:])
+}
+
+const mediaAssetColumns = `id, user_id, sha256, mimetype, size, width, height, file_name,
+	COALESCE(source_url, ''), storage_key, thumbnail_key, created_at`
+
+func scanMediaAsset(row interface{ Scan(...interface{}) error }) (*MediaAsset, error) {
+	var asset MediaAsset
+	err := row.Scan(&asset.ID, &asset.UserID, &asset.SHA256, &asset.MimeType, &asset.Size, &asset.Width,
+		&asset.Height, &asset.FileName, &asset.SourceURL, &asset.StorageKey, &asset.ThumbnailKey, &asset.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &asset, nil
+}
+
+// SaveAsset records an asset. Adding content the user already has refreshes
+// its source and returns the existing row.
+func (r *MediaAssetRepository) SaveAsset(asset *MediaAsset) (*MediaAsset, error) {
+	if asset.ID == "" {
+		asset.ID = uuid.New().String()
+	}
+	_, err := r.db.Exec(`
+		INSERT INTO media_assets (id, user_id, sha256, mimetype, size, width, height, file_name,
+			source_url, source_hash, storage_key, thumbnail_key)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			source_url = COALESCE(VALUES(source_url), source_url),
+			source_hash = COALESCE(VALUES(source_hash), source_hash),
+			file_name = IF(file_name = '', VALUES(file_name), file_name),
+			thumbnail_key = IF(thumbnail_key = '', VALUES(thumbnail_key), thumbnail_key),
+			fetched_at = CURRENT_TIMESTAMP
+	`, asset.ID, asset.UserID, asset.SHA256, asset.MimeType, asset.Size, asset.Width, asset.Height,
+		asset.FileName, sql.NullString{String: asset.SourceURL, Valid: asset.SourceURL != ""}, sourceHash(asset.SourceURL), asset.StorageKey, asset.ThumbnailKey)
+	if err != nil {
+		return nil, err
+	}
+	return scanMediaAsset(r.db.QueryRow(`SELECT `+mediaAssetColumns+`
+		FROM media_assets WHERE user_id = ? AND sha256 = ?`, asset.UserID, asset.SHA256))
+}
+
+// GetAsset returns an asset by ID, or nil when it does not exist
+func (r *MediaAssetRepository) GetAsset(id string) (*MediaAsset, error) {
+	asset, err := scanMediaAsset(r.db.QueryRow(`SELECT `+mediaAssetColumns+` FROM media_assets WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return asset, err
+}
+
+// FindAssetBySource returns the most recent asset fetched from a URL within
+// maxAge, or nil. Content is shared by hash so any user's copy will do.
+func (r *MediaAssetRepository) FindAssetBySource(sourceURL string, maxAge time.Duration) (*MediaAsset, error) {
+	asset, err := scanMediaAsset(r.db.QueryRow(`SELECT `+mediaAssetColumns+`
+		FROM media_assets
+		WHERE source_hash = ? AND source_url = ?
+		AND fetched_at >= NOW() - INTERVAL ? SECOND
+		ORDER BY fetched_at DESC LIMIT 1`, sourceHash(sourceURL), sourceURL, int(maxAge.Seconds())))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return asset, err
+}
+
+// ListAssets returns a user's assets, newest first
+func (r *MediaAssetRepository) ListAssets(userID string, limit, offset int) ([]MediaAsset, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	rows, err := r.db.Query(`SELECT `+mediaAssetColumns+`
+		FROM media_assets WHERE user_id = ?
+		ORDER BY created_at DESC LIMIT ? OFFSET ?`, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	assets := []MediaAsset{}
+	for rows.Next() {
+		asset, err := scanMediaAsset(rows)
+		if err != nil {
+			return nil, err
+		}
+		assets = append(assets, *asset)
+	}
+	return assets, rows.Err()
+}
+
+// DeleteAsset removes a user's asset and reports whether other rows still
+// reference the same content
+func (r *MediaAssetRepository) DeleteAsset(id, userID string) (bool, bool, error) {
+	var sha string
+	err := r.db.QueryRow(`SELECT sha256 FROM media_assets WHERE id = ? AND user_id = ?`, id, userID).Scan(&sha)
+	if err == sql.ErrNoRows {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, err
+	}
+	if _, err := r.db.Exec(`DELETE FROM media_assets WHERE id = ?`, id); err != nil {
+		return false, false, err
+	}
+
+	// Received media shares the store with the library, so the content stays
+	// while a message still links to it
+	MessageMediaSchema.Ensure(r.db)
+	var remaining int
+	if err := r.db.QueryRow(`
+		SELECT (SELECT COUNT(*) FROM media_assets WHERE sha256 = ?) +
+			(SELECT COUNT(*) FROM whatsapp_messages WHERE media_sha256 = ?)
+	`, sha, sha).Scan(&remaining); err != nil {
+		return true, true, err
+	}
+	if remaining == 0 {
+		if _, err := r.db.Exec(`DELETE FROM media_uploads WHERE sha256 = ?`, sha); err != nil {
+			logrus.Warnf("Failed to drop cached uploads for %s: %v", sha, err)
+		}
+	}
+	return true, remaining > 0, nil
+}
+
+// GetUpload returns a device's unexpired upload of some content, or nil
+func (r *MediaAssetRepository) GetUpload(deviceID, sha, mediaType string) (*MediaUpload, error) {
+	upload := MediaUpload{DeviceID: deviceID, SHA256: sha, MediaType: mediaType}
+	err := r.db.QueryRow(`
+		SELECT url, direct_path, media_key, file_enc_sha256, file_sha256, file_length, expires_at
+		FROM media_uploads
+		WHERE device_id = ? AND sha256 = ? AND media_type = ? AND expires_at > UTC_TIMESTAMP()
+	`, deviceID, sha, mediaType).Scan(&upload.URL, &upload.DirectPath, &upload.MediaKey,
+		&upload.FileEncSHA256, &upload.FileSHA256, &upload.FileLength, &upload.ExpiresAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &upload, nil
+}
+
+// SaveUpload caches an upload; ExpiresAt is stored in UTC
+func (r *MediaAssetRepository) SaveUpload(upload *MediaUpload) error {
+	_, err := r.db.Exec(`
+		INSERT INTO media_uploads (device_id, sha256, media_type, url, direct_path, media_key,
+			file_enc_sha256, file_sha256, file_length, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			url = VALUES(url), direct_path = VALUES(direct_path), media_key = VALUES(media_key),
+			file_enc_sha256 = VALUES(file_enc_sha256), file_sha256 = VALUES(file_sha256),
+			file_length = VALUES(file_length), expires_at = VALUES(expires_at)
+	`, upload.DeviceID, upload.SHA256, upload.MediaType, upload.URL, upload.DirectPath, upload.MediaKey,
+		upload.FileEncSHA256, upload.FileSHA256, upload.FileLength, upload.ExpiresAt.UTC().Format("2006-01-02 15:04:05"))
+	return err
+}
+
+// PurgeExpiredUploads deletes expired cached uploads
+func (r *MediaAssetRepository) PurgeExpiredUploads() (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM media_uploads WHERE expires_at <= UTC_TIMESTAMP()`)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/database"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/fulltext"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/listing"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+)
+
+// messageTextIndex is the full-text index over stored conversation history
+var messageTextIndex = fulltext.Index{Table: "whatsapp_messages", Column: "message_text", Key: "id"}
+
+// highlightWidth is the length, in characters, of a search result's snippet
+const highlightWidth = 160
+
+// messageDirection is "out" for messages the device sent and "in" otherwise.
+// As in the chat view, a device without a known JID is taken to have sent
+// whatever did not come from the chat itself.
+const messageDirection = "CASE WHEN m.sender_jid = d.jid OR (d.jid IS NULL AND m.sender_jid <> m.chat_jid) THEN 'out' ELSE 'in' END"
+
+// MessageSearchFields are the sort and filter fields of a message search;
+// the text itself is matched through the full-text index, not the listing
+var MessageSearchFields = listing.Fields{
+	Columns: map[string]string{
+		"id":           "m.id",
+		"timestamp":    "m.timestamp",
+		"device_id":    "m.device_id",
+		"chat_jid":     "m.chat_jid",
+		"sender_jid":   "m.sender_jid",
+		"message_type": "m.message_type",
+		"direction":    messageDirection,
+	},
+	Sort:        []string{"timestamp"},
+	Filter:      []string{"device_id", "chat_jid", "sender_jid", "message_type", "direction"},
+	Key:         "id",
+	DefaultSort: "-timestamp",
+}
+
+// MessageSearch is the text and date range of a message search. Since and
+// Until are unix seconds; zero leaves that end of the range open.
+type MessageSearch struct {
+	Text  string
+	Since int64
+	Until int64
+}
+
+// MessageSearchResult is a stored message that matched a search
+type MessageSearchResult struct {
+	ID          int64  `json:"id"`
+	DeviceID    string `json:"device_id"`
+	ChatJID     string `json:"chat_jid"`
+	MessageID   string `json:"message_id"`
+	SenderJID   string `json:"sender_jid"`
+	Text        string `json:"text"`
+	MessageType string `json:"message_type"`
+	Direction   string `json:"direction"`
+	Timestamp   int64  `json:"timestamp"`
+	Highlight   string `json:"highlight,omitempty"`
+}
+
+// messageTextIndexSchema creates the full-text index, which the first
+// search ensures so that startup does not wait on indexing the messages
+var messageTextIndexSchema = database.RegisterSchema("whatsapp_messages.fulltext", func(db *sql.DB) error {
+	return messageTextIndex.Ensure(db, fulltext.DialectOf(db))
+})
+
+// SearchMessages returns one page of the messages stored for any of a
+// user's devices that match the search, newest first by default
+func (r *WhatsAppRepository) SearchMessages(userID string, q listing.Query, search MessageSearch) ([]MessageSearchResult, utils.Page, error) {
+	dialect := fulltext.DialectOf(r.db)
+	terms := fulltext.Terms(search.Text)
+
+	where := ""
+	args := []any{userID}
+	if len(terms) > 0 {
+		messageTextIndexSchema.Ensure(r.db)
+		cond, arg := messageTextIndex.Match(dialect, "m", terms)
+		where += " AND " + cond
+		args = append(args, arg)
+	}
+	if search.Since > 0 {
+		where += " AND m.timestamp >= ?"
+		args = append(args, search.Since)
+	}
+	if search.Until > 0 {
+		where += " AND m.timestamp < ?"
+		args = append(args, search.Until)
+	}
+
+	const from = `
+		FROM whatsapp_messages m
+		JOIN user_devices d ON d.id = m.device_id
+		WHERE d.user_id = ?`
+
+	filters, filterArgs := q.Where()
+	var total int
+	err := r.db.QueryRow(fulltext.Rebind(dialect, `SELECT COUNT(*)`+from+where+filters),
+		append(append([]any{}, args...), filterArgs...)...).Scan(&total)
+	if err != nil {
+		return nil, utils.Page{}, err
+	}
+
+	seek, seekArgs := q.Seek()
+	rows, err := r.db.Query(fulltext.Rebind(dialect, `
+		SELECT m.id, m.device_id, m.chat_jid, m.message_id, COALESCE(m.sender_jid, ''),
+			COALESCE(m.message_text, ''), COALESCE(m.message_type, 'text'), `+messageDirection+`, m.timestamp`+
+		from+where+seek), append(args, seekArgs...)...)
+	if err != nil {
+		return nil, utils.Page{}, err
+	}
+	defer rows.Close()
+
+	results := []MessageSearchResult{}
+	for rows.Next() {
+		var result MessageSearchResult
+		if err := rows.Scan(&result.ID, &result.DeviceID, &result.ChatJID, &result.MessageID, &result.SenderJID,
+			&result.Text, &result.MessageType, &result.Direction, &result.Timestamp); err != nil {
+			return nil, utils.Page{}, err
+		}
+		if len(terms) > 0 {
+			result.Highlight = fulltext.Highlight(result.Text, terms, highlightWidth)
+		}
+		results = append(results, result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, utils.Page{}, err
+	}
+
+	results, page := listing.Trim(results, q, total, messageSearchValue)
+	return results, page, nil
+}
+
+// messageSearchValue returns a result's value of a MessageSearchFields field
+func messageSearchValue(result MessageSearchResult, field string) any {
+	if field == "timestamp" {
+		return result.Timestamp
+	}
+	return result.ID
+}
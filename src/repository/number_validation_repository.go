@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/database"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/models"
+)
+
+// NumberValidationRepository caches the results of checking phone numbers
+// with WhatsApp and records them on the leads
+type NumberValidationRepository struct {
+	db *sql.DB
+}
+
+// PendingNumber is a lead whose number needs checking
+type PendingNumber struct {
+	LeadID string
+	Phone  string
+}
+
+var (
+	numberValidationRepo     *NumberValidationRepository
+	numberValidationRepoOnce sync.Once
+)
+
+// GetNumberValidationRepository returns the number validation repository
+func GetNumberValidationRepository() *NumberValidationRepository {
+	numberValidationRepoOnce.Do(func() {
+		numberValidationRepo = &NumberValidationRepository{db: database.GetDB()}
+	})
+	numberValidationSchema.Ensure(numberValidationRepo.db)
+	LeadNumberSchema.Ensure(numberValidationRepo.db)
+	return numberValidationRepo
+}
+
+// numberValidationSchema creates the validation cache
+var numberValidationSchema = database.RegisterSchema("number_validations", database.Statement(`
+			CREATE TABLE IF NOT EXISTS number_validations (
+				phone VARCHAR(50) PRIMARY KEY,
+				status VARCHAR(20) NOT NULL,
+				jid VARCHAR(100) NOT NULL DEFAULT '',
+				is_business BOOLEAN NOT NULL DEFAULT FALSE,
+				verified_name VARCHAR(255) NOT NULL DEFAULT '',
+				checked_at TIMESTAMP NOT NULL,
+				expires_at TIMESTAMP NOT NULL
+			)
+		`))
+
+// LeadNumberSchema adds the number status of leads, which campaign and
+// sequence targeting read
+var LeadNumberSchema = database.RegisterSchema("leads.number_status",
+	database.AddColumn("leads", "number_status", "VARCHAR(20) NULL"),
+	database.AddColumn("leads", "number_checked_at", "TIMESTAMP NULL"),
+	database.AddIndex("leads", "idx_leads_number_status", "device_id, number_status"),
+)
+
+// Get returns the cached results for the phones that have one, expired or not
+func (r *NumberValidationRepository) Get(phones []string) (map[string]models.NumberValidation, error) {
+	found := map[string]models.NumberValidation{}
+	if len(phones) == 0 {
+		return found, nil
+	}
+	args := make([]any, len(phones))
+	for i, phone := range phones {
+		args[i] = phone
+	}
+	rows, err := r.db.Query(`
+		SELECT phone, status, jid, is_business, verified_name, checked_at, expires_at
+		FROM number_validations
+		WHERE phone IN (`+strings.TrimSuffix(strings.Repeat("?, ", len(phones)), ", ")+`)`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var v models.NumberValidation
+		if err := rows.Scan(&v.Phone, &v.Status, &v.JID, &v.IsBusiness, &v.VerifiedName, &v.CheckedAt, &v.ExpiresAt); err != nil {
+			return nil, err
+		}
+		found[v.Phone] = v
+	}
+	return found, rows.Err()
+}
+
+// Save caches the result of checking a number
+func (r *NumberValidationRepository) Save(v models.NumberValidation) error {
+	_, err := r.db.Exec(`
+		INSERT INTO number_validations (phone, status, jid, is_business, verified_name, checked_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE status = VALUES(status), jid = VALUES(jid), is_business = VALUES(is_business),
+			verified_name = VALUES(verified_name), checked_at = VALUES(checked_at), expires_at = VALUES(expires_at)
+	`, v.Phone, v.Status, v.JID, v.IsBusiness, v.VerifiedName, v.CheckedAt, v.ExpiresAt)
+	return err
+}
+
+// PendingLeads returns up to limit leads of a device that were never checked,
+// or whose valid or invalid result was checked before the given time
+func (r *NumberValidationRepository) PendingLeads(deviceID string, limit int, validBefore, invalidBefore time.Time) ([]PendingNumber, error) {
+	rows, err := r.db.Query(`
+		SELECT id, phone FROM leads
+		WHERE device_id = ?
+		AND (number_status IS NULL
+			OR (number_status = 'valid' AND number_checked_at < ?)
+			OR (number_status = 'invalid' AND number_checked_at < ?))
+		ORDER BY created_at DESC
+		LIMIT ?
+	`, deviceID, validBefore, invalidBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pending []PendingNumber
+	for rows.Next() {
+		var p PendingNumber
+		if err := rows.Scan(&p.LeadID, &p.Phone); err != nil {
+			return nil, err
+		}
+		pending = append(pending, p)
+	}
+	return pending, rows.Err()
+}
+
+// MarkLead records a lead's number status. A normalized phone replaces the
+// lead's unless another lead of the device already has it.
+func (r *NumberValidationRepository) MarkLead(lead PendingNumber, status string, checkedAt time.Time) error {
+	_, err := r.db.Exec(`UPDATE leads SET phone = ?, number_status = ?, number_checked_at = ? WHERE id = ?`,
+		lead.Phone, status, checkedAt, lead.LeadID)
+	if err == nil {
+		return nil
+	}
+	_, err = r.db.Exec(`UPDATE leads SET number_status = ?, number_checked_at = ? WHERE id = ?`,
+		status, checkedAt, lead.LeadID)
+	return err
+}
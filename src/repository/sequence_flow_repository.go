@@ -0,0 +1,365 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/database"
+	domainSequence "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/sequence"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/models"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Flow position statuses
+const (
+	FlowPositionActive    = "active"    // being advanced right now
+	FlowPositionWaiting   = "waiting"   // parked on a wait node until wait_until
+	FlowPositionSending   = "sending"   // parked on a send node until the message is sent
+	FlowPositionCompleted = "completed" // reached an end node
+	FlowPositionFailed    = "failed"    // the pending message failed
+)
+
+// SequenceFlowPosition is where a contact currently sits in a sequence flow
+type SequenceFlowPosition struct {
+	ID               string
+	SequenceID       string
+	UserID           string
+	Phone            string
+	Name             string
+	DeviceID         string
+	DeviceName       string
+	NodeID           string
+	Status           string
+	WaitUntil        *time.Time
+	PendingMessageID string
+	LastError        string
+	EnteredAt        time.Time
+}
+
+// SequenceFlowRepository stores flow definitions, contact positions and lead tags
+type SequenceFlowRepository struct {
+	db *sql.DB
+}
+
+var (
+	sequenceFlowRepo     *SequenceFlowRepository
+	sequenceFlowRepoOnce sync.Once
+)
+
+// GetSequenceFlowRepository returns the sequence flow repository
+func GetSequenceFlowRepository() *SequenceFlowRepository {
+	sequenceFlowRepoOnce.Do(func() {
+		sequenceFlowRepo = &SequenceFlowRepository{db: database.GetDB()}
+	})
+	sequenceFlowSchema.Ensure(sequenceFlowRepo.db)
+	return sequenceFlowRepo
+}
+
+// sequenceFlowSchema creates the flow tables
+var sequenceFlowSchema = database.RegisterSchema("sequence_flows",
+	database.Statement(`CREATE TABLE IF NOT EXISTS sequence_flows (
+				sequence_id VARCHAR(36) PRIMARY KEY,
+				definition LONGTEXT NOT NULL,
+				version INT NOT NULL DEFAULT 1,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+			)`),
+	database.Statement(`CREATE TABLE IF NOT EXISTS sequence_flow_positions (
+				id VARCHAR(36) PRIMARY KEY,
+				sequence_id VARCHAR(36) NOT NULL,
+				user_id VARCHAR(36) NOT NULL,
+				contact_phone VARCHAR(50) NOT NULL,
+				contact_name VARCHAR(255),
+				device_id VARCHAR(255),
+				device_name VARCHAR(255),
+				node_id VARCHAR(100) NOT NULL,
+				status VARCHAR(20) NOT NULL DEFAULT 'active',
+				wait_until DATETIME NULL,
+				pending_message_id VARCHAR(36) NULL,
+				last_error TEXT,
+				entered_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+				UNIQUE KEY uniq_flow_contact (sequence_id, contact_phone),
+				INDEX idx_flow_positions_due (status, wait_until),
+				INDEX idx_flow_positions_message (pending_message_id)
+			)`),
+	database.Statement(`CREATE TABLE IF NOT EXISTS lead_tags (
+				user_id VARCHAR(36) NOT NULL,
+				phone VARCHAR(50) NOT NULL,
+				tag VARCHAR(100) NOT NULL,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				PRIMARY KEY (user_id, phone, tag)
+			)`),
+)
+
+// GetFlow returns the saved flow for a sequence, or nil when it has none
+func (r *SequenceFlowRepository) GetFlow(sequenceID string) (*domainSequence.Flow, error) {
+	var definition string
+	var updatedAt time.Time
+	err := r.db.QueryRow(`SELECT definition, updated_at FROM sequence_flows WHERE sequence_id = ?`, sequenceID).
+		Scan(&definition, &updatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var flow domainSequence.Flow
+	if err := json.Unmarshal([]byte(definition), &flow); err != nil {
+		return nil, fmt.Errorf("stored flow for sequence %s is corrupt: %w", sequenceID, err)
+	}
+	flow.SequenceID = sequenceID
+	flow.UpdatedAt = &updatedAt
+	return &flow, nil
+}
+
+// SaveFlow stores a flow definition for its sequence
+func (r *SequenceFlowRepository) SaveFlow(flow *domainSequence.Flow) error {
+	stored := *flow
+	stored.Converted = false
+	stored.UpdatedAt = nil
+	definition, err := json.Marshal(stored)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(`
+		INSERT INTO sequence_flows (sequence_id, definition, version)
+		VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE definition = VALUES(definition), version = VALUES(version)
+	`, flow.SequenceID, string(definition), flow.Version)
+	return err
+}
+
+// DeleteFlow removes a sequence's flow so it runs as a linear sequence again
+func (r *SequenceFlowRepository) DeleteFlow(sequenceID string) error {
+	_, err := r.db.Exec(`DELETE FROM sequence_flows WHERE sequence_id = ?`, sequenceID)
+	return err
+}
+
+const flowPositionColumns = `id, sequence_id, user_id, contact_phone, COALESCE(contact_name, ''),
+	COALESCE(device_id, ''), COALESCE(device_name, ''), node_id, status, wait_until,
+	COALESCE(pending_message_id, ''), COALESCE(last_error, ''), entered_at`
+
+func scanFlowPosition(row interface{ Scan(...interface{}) error }) (*SequenceFlowPosition, error) {
+	var pos SequenceFlowPosition
+	var waitUntil sql.NullTime
+	err := row.Scan(&pos.ID, &pos.SequenceID, &pos.UserID, &pos.Phone, &pos.Name,
+		&pos.DeviceID, &pos.DeviceName, &pos.NodeID, &pos.Status, &waitUntil,
+		&pos.PendingMessageID, &pos.LastError, &pos.EnteredAt)
+	if err != nil {
+		return nil, err
+	}
+	if waitUntil.Valid {
+		pos.WaitUntil = &waitUntil.Time
+	}
+	return &pos, nil
+}
+
+// GetPosition returns a contact's position in a sequence flow, or nil
+func (r *SequenceFlowRepository) GetPosition(sequenceID, phone string) (*SequenceFlowPosition, error) {
+	pos, err := scanFlowPosition(r.db.QueryRow(`SELECT `+flowPositionColumns+`
+		FROM sequence_flow_positions WHERE sequence_id = ? AND contact_phone = ?`, sequenceID, phone))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return pos, err
+}
+
+// StartPosition places a contact on a flow's start node. Contacts still in
+// the flow keep their position; it returns true only when a run should begin.
+func (r *SequenceFlowRepository) StartPosition(pos *SequenceFlowPosition) (bool, error) {
+	if pos.ID == "" {
+		pos.ID = uuid.New().String()
+	}
+	result, err := r.db.Exec(`
+		INSERT INTO sequence_flow_positions (
+			id, sequence_id, user_id, contact_phone, contact_name, device_id, device_name, node_id, status
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, 'active')
+		ON DUPLICATE KEY UPDATE
+			node_id = IF(status IN ('completed', 'failed'), VALUES(node_id), node_id),
+			wait_until = IF(status IN ('completed', 'failed'), NULL, wait_until),
+			pending_message_id = IF(status IN ('completed', 'failed'), NULL, pending_message_id),
+			last_error = IF(status IN ('completed', 'failed'), NULL, last_error),
+			entered_at = IF(status IN ('completed', 'failed'), NOW(), entered_at),
+			status = IF(status IN ('completed', 'failed'), 'active', status)
+	`, pos.ID, pos.SequenceID, pos.UserID, pos.Phone, pos.Name, pos.DeviceID, pos.DeviceName, pos.NodeID)
+	if err != nil {
+		return false, err
+	}
+
+	// MySQL reports 1 for an insert, 2 for a changed row and 0 for an unchanged one
+	affected, _ := result.RowsAffected()
+	if affected == 0 {
+		return false, nil
+	}
+	current, err := r.GetPosition(pos.SequenceID, pos.Phone)
+	if err != nil || current == nil {
+		return false, err
+	}
+	*pos = *current
+	return pos.Status == FlowPositionActive, nil
+}
+
+// SavePosition writes the mutable parts of a position
+func (r *SequenceFlowRepository) SavePosition(pos *SequenceFlowPosition) error {
+	var waitUntil, pendingMessageID interface{}
+	if pos.WaitUntil != nil {
+		waitUntil = *pos.WaitUntil
+	}
+	if pos.PendingMessageID != "" {
+		pendingMessageID = pos.PendingMessageID
+	}
+	_, err := r.db.Exec(`
+		UPDATE sequence_flow_positions
+		SET node_id = ?, status = ?, wait_until = ?, pending_message_id = ?, last_error = ?
+		WHERE id = ?
+	`, pos.NodeID, pos.Status, waitUntil, pendingMessageID, pos.LastError, pos.ID)
+	return err
+}
+
+// ClaimSentPosition takes the position waiting on a message that was just sent
+func (r *SequenceFlowRepository) ClaimSentPosition(messageID string) (*SequenceFlowPosition, error) {
+	result, err := r.db.Exec(`
+		UPDATE sequence_flow_positions SET status = 'active'
+		WHERE pending_message_id = ? AND status = 'sending'
+	`, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return nil, nil
+	}
+
+	pos, err := scanFlowPosition(r.db.QueryRow(`SELECT `+flowPositionColumns+`
+		FROM sequence_flow_positions WHERE pending_message_id = ? AND status = 'active'`, messageID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return pos, err
+}
+
+// ClaimDuePositions takes positions whose wait has elapsed
+func (r *SequenceFlowRepository) ClaimDuePositions(limit int) ([]*SequenceFlowPosition, error) {
+	rows, err := r.db.Query(`SELECT `+flowPositionColumns+`
+		FROM sequence_flow_positions
+		WHERE status = 'waiting' AND wait_until <= NOW()
+		ORDER BY wait_until
+		LIMIT ?`, limit)
+	if err != nil {
+		return nil, err
+	}
+	var due []*SequenceFlowPosition
+	for rows.Next() {
+		pos, err := scanFlowPosition(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		due = append(due, pos)
+	}
+	rows.Close()
+
+	claimed := due[:0]
+	for _, pos := range due {
+		result, err := r.db.Exec(`UPDATE sequence_flow_positions SET status = 'active' WHERE id = ? AND status = 'waiting'`, pos.ID)
+		if err != nil {
+			return claimed, err
+		}
+		if affected, _ := result.RowsAffected(); affected == 1 {
+			pos.Status = FlowPositionActive
+			claimed = append(claimed, pos)
+		}
+	}
+	return claimed, nil
+}
+
+// FailStalledSends marks positions whose pending message failed
+func (r *SequenceFlowRepository) FailStalledSends() (int64, error) {
+	result, err := r.db.Exec(`
+		UPDATE sequence_flow_positions p
+		INNER JOIN broadcast_messages bm ON bm.id = p.pending_message_id
+		SET p.status = 'failed', p.last_error = COALESCE(bm.error_message, 'message failed')
+		WHERE p.status = 'sending' AND bm.status IN ('failed', 'skipped')
+	`)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// CountPositionsByNode returns how many contacts sit on each node of a flow
+func (r *SequenceFlowRepository) CountPositionsByNode(sequenceID string) (map[string]int, error) {
+	rows, err := r.db.Query(`
+		SELECT node_id, COUNT(*) FROM sequence_flow_positions
+		WHERE sequence_id = ? AND status IN ('active', 'waiting', 'sending')
+		GROUP BY node_id
+	`, sequenceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var nodeID string
+		var count int
+		if err := rows.Scan(&nodeID, &count); err != nil {
+			return nil, err
+		}
+		counts[nodeID] = count
+	}
+	return counts, rows.Err()
+}
+
+// AddLeadTag tags a lead, recording the new tag on the lead's timeline
+func (r *SequenceFlowRepository) AddLeadTag(userID, phone, tag, actor string) error {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	result, err := r.db.Exec(`INSERT IGNORE INTO lead_tags (user_id, phone, tag) VALUES (?, ?, ?)`,
+		userID, phone, tag)
+	if err != nil {
+		return err
+	}
+	r.recordTagChange(result, models.LeadTimelineTagAdded, userID, phone, tag, actor)
+	return nil
+}
+
+// RemoveLeadTag removes a tag from a lead, recording the removal on the
+// lead's timeline
+func (r *SequenceFlowRepository) RemoveLeadTag(userID, phone, tag, actor string) error {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	result, err := r.db.Exec(`DELETE FROM lead_tags WHERE user_id = ? AND phone = ? AND tag = ?`,
+		userID, phone, tag)
+	if err != nil {
+		return err
+	}
+	r.recordTagChange(result, models.LeadTimelineTagRemoved, userID, phone, tag, actor)
+	return nil
+}
+
+// recordTagChange logs a tag change that touched a row; adding a tag the
+// lead already has, or removing one it lacks, is not a change
+func (r *SequenceFlowRepository) recordTagChange(result sql.Result, kind, userID, phone, tag, actor string) {
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return
+	}
+	err := GetLeadRepository().RecordEvent(&models.LeadEvent{UserID: userID, Phone: phone, Kind: kind,
+		Actor: actor, Text: tag})
+	if err != nil {
+		logrus.Warnf("Failed to record tag change of lead %s: %v", phone, err)
+	}
+}
+
+// HasLeadTag reports whether a lead carries a tag
+func (r *SequenceFlowRepository) HasLeadTag(userID, phone, tag string) (bool, error) {
+	var count int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM lead_tags WHERE user_id = ? AND phone = ? AND tag = ?`,
+		userID, phone, strings.ToLower(strings.TrimSpace(tag))).Scan(&count)
+	return count > 0, err
+}
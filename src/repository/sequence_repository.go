@@ -3,10 +3,14 @@ package repository
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/database"
+	domainBroadcast "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/broadcast"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/models"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/listing"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
@@ -23,10 +27,18 @@ func GetSequenceRepository() *sequenceRepository {
 		sequenceRepo = &sequenceRepository{
 			db: database.GetDB(),
 		}
+		messagePayloadSchema.Ensure(sequenceRepo.db)
 	}
+	SequenceContactSchema.Ensure(sequenceRepo.db)
 	return sequenceRepo
 }
 
+// SequenceContactSchema adds the run start of sequence contacts. Contacts
+// enrolled before it existed keep NULL.
+var SequenceContactSchema = database.RegisterSchema("sequence_contacts.enrolled_at",
+	database.AddColumn("sequence_contacts", "enrolled_at", "TIMESTAMP NULL"),
+)
+
 // CreateSequence creates a new sequence
 func (r *sequenceRepository) CreateSequence(sequence *models.Sequence) error {
 	sequence.ID = uuid.New().String()
@@ -71,6 +83,76 @@ func (r *sequenceRepository) GetSequences(userID string) ([]models.Sequence, err
 	}
 	defer rows.Close()
 	
+	sequences := scanSequences(rows)
+	logrus.Infof("Repository: Found %d sequences for user %s", len(sequences), userID)
+	return sequences, nil
+}
+
+// SequenceListFields are the sort, filter and search fields of the sequence list
+var SequenceListFields = listing.Fields{
+	Columns: map[string]string{
+		"id":          "id",
+		"name":        "name",
+		"description": "COALESCE(description, '')",
+		"niche":       "niche",
+		"status":      "status",
+		"created_at":  "created_at",
+		"updated_at":  "updated_at",
+	},
+	Sort:        []string{"name", "status", "created_at", "updated_at"},
+	Filter:      []string{"status", "niche"},
+	Search:      []string{"name", "description", "niche"},
+	Key:         "id",
+	DefaultSort: "-created_at",
+}
+
+// ListSequences returns one page of a user's sequences
+func (r *sequenceRepository) ListSequences(userID string, q listing.Query) ([]models.Sequence, utils.Page, error) {
+	where, args := q.Where()
+	var total int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM sequences WHERE user_id = ?`+where, append([]any{userID}, args...)...).Scan(&total)
+	if err != nil {
+		return nil, utils.Page{}, err
+	}
+	
+	seek, args := q.Seek()
+	rows, err := r.db.Query(`
+		SELECT id, user_id, device_id, name, description, niche, status, 
+		       COALESCE(start_trigger, '') AS start_trigger,
+		       COALESCE(end_trigger, '') AS end_trigger,
+		       total_days, is_active, 
+		       COALESCE(schedule_time, '09:00') AS schedule_time, 
+		       COALESCE(min_delay_seconds, 10) AS min_delay_seconds,
+		       COALESCE(max_delay_seconds, 30) AS max_delay_seconds,
+		       created_at, updated_at
+		FROM sequences
+		WHERE user_id = ?`+seek, append([]any{userID}, args...)...)
+	if err != nil {
+		return nil, utils.Page{}, err
+	}
+	defer rows.Close()
+	
+	sequences, page := listing.Trim(scanSequences(rows), q, total, sequenceValue)
+	return sequences, page, nil
+}
+
+// sequenceValue returns a sequence's value of a SequenceListFields field
+func sequenceValue(seq models.Sequence, field string) any {
+	switch field {
+	case "name":
+		return seq.Name
+	case "status":
+		return seq.Status
+	case "created_at":
+		return seq.CreatedAt
+	case "updated_at":
+		return seq.UpdatedAt
+	}
+	return seq.ID
+}
+
+// scanSequences reads the rows of the sequence list queries
+func scanSequences(rows *sql.Rows) []models.Sequence {
 	var sequences []models.Sequence
 	for rows.Next() {
 		var seq models.Sequence
@@ -85,10 +167,9 @@ func (r *sequenceRepository) GetSequences(userID string) ([]models.Sequence, err
 		}
 		sequences = append(sequences, seq)
 	}
-	
-	logrus.Infof("Repository: Found %d sequences for user %s", len(sequences), userID)
-	return sequences, nil
+	return sequences
 }
+
 // GetSequenceByID gets sequence by ID
 func (r *sequenceRepository) GetSequenceByID(sequenceID string) (*models.Sequence, error) {
 	query := `
@@ -165,11 +246,11 @@ func (r *sequenceRepository) CreateSequenceStep(step *models.SequenceStep) error
 	query := `
 		INSERT INTO sequence_steps(
 			id, sequence_id, day_number, message_type, content, 
-			media_url, caption, delay_days, time_schedule, ` + "`trigger`" + `,
+			media_url, caption, message_payload, delay_days, time_schedule, ` + "`trigger`" + `,
 			next_trigger, trigger_delay_hours, is_entry_point,
 			min_delay_seconds, max_delay_seconds
 		)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 	
 	// Use DayNumber
@@ -203,7 +284,7 @@ func (r *sequenceRepository) CreateSequenceStep(step *models.SequenceStep) error
 	
 	_, err := r.db.Exec(query, 
 		step.ID, step.SequenceID, dayNumber, step.MessageType, step.Content,
-		step.MediaURL, step.Caption, delayDays, step.TimeSchedule, step.Trigger,
+		step.MediaURL, step.Caption, domainBroadcast.EncodePayload(step.Payload), delayDays, step.TimeSchedule, step.Trigger,
 		step.NextTrigger, step.TriggerDelayHours, step.IsEntryPoint,
 		step.MinDelaySeconds, step.MaxDelaySeconds)
 		
@@ -250,6 +331,7 @@ func (r *sequenceRepository) GetSequenceSteps(sequenceID string) ([]models.Seque
 			COALESCE(content, '') as content, 
 			COALESCE(media_url, '') as media_url, 
 			COALESCE(caption, '') as caption, 
+			COALESCE(message_payload, '') as message_payload,
 			COALESCE(time_schedule, '') as time_schedule,
 			COALESCE(min_delay_seconds, 10) as min_delay_seconds,
 			COALESCE(max_delay_seconds, 30) as max_delay_seconds,
@@ -269,9 +351,10 @@ func (r *sequenceRepository) GetSequenceSteps(sequenceID string) ([]models.Seque
 	var steps []models.SequenceStep
 	for rows.Next() {
 		var step models.SequenceStep
+		var payload string
 		err := rows.Scan(&step.ID, &step.SequenceID, &step.DayNumber, 
 			&step.Trigger, &step.NextTrigger, &step.TriggerDelayHours, &step.IsEntryPoint,
-			&step.MessageType, &step.Content, &step.MediaURL, &step.Caption, 
+			&step.MessageType, &step.Content, &step.MediaURL, &step.Caption, &payload,
 			&step.TimeSchedule, &step.MinDelaySeconds, &step.MaxDelaySeconds, &step.DelayDays)
 		if err != nil {
 			logrus.Errorf("Error scanning sequence step: %v", err)
@@ -282,6 +365,7 @@ func (r *sequenceRepository) GetSequenceSteps(sequenceID string) ([]models.Seque
 			// Don't skip, return the error to understand what's wrong
 			return nil, fmt.Errorf("failed to scan sequence step: %v", err)
 		}
+		step.Payload = domainBroadcast.DecodePayload(payload)
 		steps = append(steps, step)
 		logrus.Debugf("Successfully scanned step: day=%d, trigger=%s", step.DayNumber, step.Trigger)
 	}
@@ -313,6 +397,38 @@ func (r *sequenceRepository) AddContactToSequence(contact *models.SequenceContac
 		
 	return err
 }
+// CountSequenceContacts counts the contacts of each of the given sequences
+// in one query; sequences without contacts are left out of the map
+func (r *sequenceRepository) CountSequenceContacts(sequenceIDs []string) (map[string]int, error) {
+	counts := make(map[string]int, len(sequenceIDs))
+	if len(sequenceIDs) == 0 {
+		return counts, nil
+	}
+	args := make([]any, len(sequenceIDs))
+	for i, id := range sequenceIDs {
+		args[i] = id
+	}
+	rows, err := r.db.Query(`
+		SELECT sequence_id, COUNT(*) FROM sequence_contacts
+		WHERE sequence_id IN (`+strings.TrimSuffix(strings.Repeat("?, ", len(args)), ", ")+`)
+		GROUP BY sequence_id
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	
+	for rows.Next() {
+		var sequenceID string
+		var count int
+		if err := rows.Scan(&sequenceID, &count); err != nil {
+			return nil, err
+		}
+		counts[sequenceID] = count
+	}
+	return counts, rows.Err()
+}
+
 // GetSequenceContacts gets all contacts in a sequence
 func (r *sequenceRepository) GetSequenceContacts(sequenceID string) ([]models.SequenceContact, error) {
 	query := `
@@ -330,6 +446,67 @@ func (r *sequenceRepository) GetSequenceContacts(sequenceID string) ([]models.Se
 	}
 	defer rows.Close()
 	
+	return scanSequenceContacts(rows), nil
+}
+
+// SequenceContactListFields are the sort, filter and search fields of a sequence's contact list
+var SequenceContactListFields = listing.Fields{
+	Columns: map[string]string{
+		"id":            "id",
+		"contact_phone": "contact_phone",
+		"contact_name":  "COALESCE(contact_name, '')",
+		"current_step":  "current_step",
+		"status":        "status",
+		"completed_at":  "COALESCE(completed_at, '')",
+	},
+	Sort:        []string{"contact_phone", "contact_name", "current_step", "completed_at"},
+	Filter:      []string{"status", "current_step"},
+	Search:      []string{"contact_phone", "contact_name"},
+	Key:         "id",
+	DefaultSort: "-completed_at",
+}
+
+// ListSequenceContacts returns one page of a sequence's contacts
+func (r *sequenceRepository) ListSequenceContacts(sequenceID string, q listing.Query) ([]models.SequenceContact, utils.Page, error) {
+	where, args := q.Where()
+	var total int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM sequence_contacts WHERE sequence_id = ?`+where, append([]any{sequenceID}, args...)...).Scan(&total)
+	if err != nil {
+		return nil, utils.Page{}, err
+	}
+	
+	seek, args := q.Seek()
+	rows, err := r.db.Query(`
+		SELECT id, sequence_id, contact_phone, contact_name, current_step, status, 
+			   completed_at
+		FROM sequence_contacts
+		WHERE sequence_id = ?`+seek, append([]any{sequenceID}, args...)...)
+	if err != nil {
+		return nil, utils.Page{}, err
+	}
+	defer rows.Close()
+	
+	contacts, page := listing.Trim(scanSequenceContacts(rows), q, total, sequenceContactValue)
+	return contacts, page, nil
+}
+
+// sequenceContactValue returns a contact's value of a SequenceContactListFields field
+func sequenceContactValue(contact models.SequenceContact, field string) any {
+	switch field {
+	case "contact_phone":
+		return contact.ContactPhone
+	case "contact_name":
+		return contact.ContactName
+	case "current_step":
+		return contact.CurrentStep
+	case "completed_at":
+		return contact.CompletedAt
+	}
+	return contact.ID
+}
+
+// scanSequenceContacts reads the rows of the sequence contact list queries
+func scanSequenceContacts(rows *sql.Rows) []models.SequenceContact {
 	var contacts []models.SequenceContact
 	for rows.Next() {
 		var contact models.SequenceContact
@@ -341,8 +518,7 @@ func (r *sequenceRepository) GetSequenceContacts(sequenceID string) ([]models.Se
 		}
 		contacts = append(contacts, contact)
 	}
-	
-	return contacts, nil
+	return contacts
 }
 
 // GetActiveSequenceContacts gets contacts ready for next message
@@ -501,6 +677,7 @@ func (r *sequenceRepository) UpdateSequenceStep(step *models.SequenceStep) error
 			content = ?,
 			media_url = ?,
 			caption = ?,
+			message_payload = ?,
 			time_schedule = ?,
 			` + "`trigger`" + ` = ?,
 			next_trigger = ?,
@@ -514,6 +691,7 @@ func (r *sequenceRepository) UpdateSequenceStep(step *models.SequenceStep) error
 	
 	_, err := r.db.Exec(query,
 		step.MessageType, step.Content, step.MediaURL, step.Caption,
+		domainBroadcast.EncodePayload(step.Payload),
 		step.TimeSchedule, step.Trigger, step.NextTrigger,
 		step.TriggerDelayHours, step.IsEntryPoint,
 		step.MinDelaySeconds, step.MaxDelaySeconds,
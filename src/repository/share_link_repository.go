@@ -0,0 +1,292 @@
+package repository
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/database"
+	"github.com/google/uuid"
+)
+
+// ShareLink grants access to one device's public views without logging in
+type ShareLink struct {
+	ID             string     `json:"id"`
+	UserID         string     `json:"user_id"`
+	DeviceID       string     `json:"device_id"`
+	Name           string     `json:"name"`
+	Scopes         []string   `json:"scopes"`
+	PasswordHash   string     `json:"-"`
+	HasPassword    bool       `json:"has_password"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty"`
+	LastAccessedAt *time.Time `json:"last_accessed_at,omitempty"`
+	AccessCount    int64      `json:"access_count"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// ShareLinkAccess is one request made with a share link. Outcome is
+// "allowed" or the reason the request was refused.
+type ShareLinkAccess struct {
+	ID        int64     `json:"id"`
+	LinkID    string    `json:"link_id"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	Outcome   string    `json:"outcome"`
+	Status    int       `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ShareLinkRepository stores device share links and their access log
+type ShareLinkRepository struct {
+	db *sql.DB
+
+	secretMu sync.Mutex
+	secret   []byte
+}
+
+var (
+	shareLinkRepo     *ShareLinkRepository
+	shareLinkRepoOnce sync.Once
+)
+
+// GetShareLinkRepository returns the share link repository
+func GetShareLinkRepository() *ShareLinkRepository {
+	shareLinkRepoOnce.Do(func() {
+		shareLinkRepo = &ShareLinkRepository{db: database.GetDB()}
+	})
+	shareLinkSchema.Ensure(shareLinkRepo.db)
+	return shareLinkRepo
+}
+
+// shareLinkSchema creates the share link tables
+var shareLinkSchema = database.RegisterSchema("share_links",
+	database.Statement(`
+			CREATE TABLE IF NOT EXISTS share_links (
+				id VARCHAR(36) PRIMARY KEY,
+				user_id VARCHAR(36) NOT NULL,
+				device_id VARCHAR(36) NOT NULL,
+				name VARCHAR(255) NOT NULL DEFAULT '',
+				scopes VARCHAR(255) NOT NULL,
+				password_hash VARCHAR(255) NOT NULL DEFAULT '',
+				expires_at DATETIME NOT NULL,
+				revoked_at DATETIME NULL,
+				last_accessed_at DATETIME NULL,
+				access_count BIGINT NOT NULL DEFAULT 0,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				INDEX idx_share_links_user_device (user_id, device_id)
+			)`),
+	database.Statement(`
+			CREATE TABLE IF NOT EXISTS share_link_access_log (
+				id BIGINT AUTO_INCREMENT PRIMARY KEY,
+				link_id VARCHAR(36) NOT NULL,
+				method VARCHAR(10) NOT NULL,
+				path VARCHAR(512) NOT NULL,
+				ip VARCHAR(64) NOT NULL DEFAULT '',
+				user_agent VARCHAR(512) NOT NULL DEFAULT '',
+				outcome VARCHAR(40) NOT NULL,
+				status INT NOT NULL DEFAULT 0,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				INDEX idx_share_link_access_link (link_id, created_at)
+			)`),
+	database.Statement(`
+			CREATE TABLE IF NOT EXISTS app_secrets (
+				name VARCHAR(64) PRIMARY KEY,
+				value VARCHAR(255) NOT NULL,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+			)`),
+)
+
+// SigningSecret returns the key share tokens are signed with: the
+// configured secret, or one generated once and kept in the database so every
+// instance and restart agrees on it
+func (r *ShareLinkRepository) SigningSecret() ([]byte, error) {
+	if config.ShareLinkSecret != "" {
+		return []byte(config.ShareLinkSecret), nil
+	}
+
+	r.secretMu.Lock()
+	defer r.secretMu.Unlock()
+	if r.secret != nil {
+		return r.secret, nil
+	}
+
+	generated := make([]byte, 32)
+	if _, err := rand.Read(generated); err != nil {
+		return nil, err
+	}
+	if _, err := r.db.Exec(`INSERT IGNORE INTO app_secrets (name, value) VALUES ('share_link', ?)`,
+		hex.EncodeToString(generated)); err != nil {
+		return nil, fmt.Errorf("failed to store share link secret: %w", err)
+	}
+	var value string
+	if err := r.db.QueryRow(`SELECT value FROM app_secrets WHERE name = 'share_link'`).Scan(&value); err != nil {
+		return nil, fmt.Errorf("failed to load share link secret: %w", err)
+	}
+	r.secret = []byte(value)
+	return r.secret, nil
+}
+
+// CreateLink stores a new link and fills in its ID
+func (r *ShareLinkRepository) CreateLink(link *ShareLink) error {
+	link.ID = uuid.New().String()
+	link.CreatedAt = time.Now()
+	link.HasPassword = link.PasswordHash != ""
+	_, err := r.db.Exec(`
+		INSERT INTO share_links (id, user_id, device_id, name, scopes, password_hash, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, link.ID, link.UserID, link.DeviceID, link.Name, strings.Join(link.Scopes, ","),
+		link.PasswordHash, link.ExpiresAt, link.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create share link: %w", err)
+	}
+	return nil
+}
+
+const shareLinkColumns = `id, user_id, device_id, name, scopes, password_hash, expires_at,
+	revoked_at, last_accessed_at, access_count, created_at`
+
+func scanShareLink(row interface{ Scan(...interface{}) error }) (*ShareLink, error) {
+	var link ShareLink
+	var scopes string
+	var revokedAt, lastAccessedAt sql.NullTime
+	if err := row.Scan(&link.ID, &link.UserID, &link.DeviceID, &link.Name, &scopes, &link.PasswordHash,
+		&link.ExpiresAt, &revokedAt, &lastAccessedAt, &link.AccessCount, &link.CreatedAt); err != nil {
+		return nil, err
+	}
+	if scopes != "" {
+		link.Scopes = strings.Split(scopes, ",")
+	}
+	link.HasPassword = link.PasswordHash != ""
+	if revokedAt.Valid {
+		link.RevokedAt = &revokedAt.Time
+	}
+	if lastAccessedAt.Valid {
+		link.LastAccessedAt = &lastAccessedAt.Time
+	}
+	return &link, nil
+}
+
+// GetLink returns a link by ID, or nil if it does not exist
+func (r *ShareLinkRepository) GetLink(id string) (*ShareLink, error) {
+	link, err := scanShareLink(r.db.QueryRow(`SELECT `+shareLinkColumns+` FROM share_links WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get share link: %w", err)
+	}
+	return link, nil
+}
+
+// ListLinks returns a user's links, newest first, optionally for one device
+func (r *ShareLinkRepository) ListLinks(userID, deviceID string) ([]ShareLink, error) {
+	query := `SELECT ` + shareLinkColumns + ` FROM share_links WHERE user_id = ?`
+	args := []interface{}{userID}
+	if deviceID != "" {
+		query += ` AND device_id = ?`
+		args = append(args, deviceID)
+	}
+	query += ` ORDER BY created_at DESC`
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list share links: %w", err)
+	}
+	defer rows.Close()
+
+	links := []ShareLink{}
+	for rows.Next() {
+		link, err := scanShareLink(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan share link: %w", err)
+		}
+		links = append(links, *link)
+	}
+	return links, rows.Err()
+}
+
+// RevokeLink revokes one of the user's links; it reports false if the link
+// does not exist or was already revoked
+func (r *ShareLinkRepository) RevokeLink(userID, id string) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE share_links SET revoked_at = NOW()
+		WHERE id = ? AND user_id = ? AND revoked_at IS NULL
+	`, id, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke share link: %w", err)
+	}
+	affected, _ := result.RowsAffected()
+	return affected > 0, nil
+}
+
+// LogAccess records a request made with a link and, when it was allowed,
+// bumps the link's access counters
+func (r *ShareLinkRepository) LogAccess(access ShareLinkAccess) error {
+	if len(access.Path) > 512 {
+		access.Path = access.Path[:512]
+	}
+	if len(access.UserAgent) > 512 {
+		access.UserAgent = access.UserAgent[:512]
+	}
+	_, err := r.db.Exec(`
+		INSERT INTO share_link_access_log (link_id, method, path, ip, user_agent, outcome, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, access.LinkID, access.Method, access.Path, access.IP, access.UserAgent, access.Outcome, access.Status)
+	if err != nil {
+		return fmt.Errorf("failed to log share link access: %w", err)
+	}
+	if access.Outcome == "allowed" {
+		_, err = r.db.Exec(`
+			UPDATE share_links SET last_accessed_at = NOW(), access_count = access_count + 1 WHERE id = ?
+		`, access.LinkID)
+	}
+	return err
+}
+
+// ListAccess returns the most recent requests made with a link
+func (r *ShareLinkRepository) ListAccess(linkID string, limit, offset int) ([]ShareLinkAccess, int, error) {
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM share_link_access_log WHERE link_id = ?`, linkID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count share link access: %w", err)
+	}
+
+	rows, err := r.db.Query(`
+		SELECT id, link_id, method, path, ip, user_agent, outcome, status, created_at
+		FROM share_link_access_log
+		WHERE link_id = ?
+		ORDER BY id DESC
+		LIMIT ? OFFSET ?
+	`, linkID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list share link access: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []ShareLinkAccess{}
+	for rows.Next() {
+		var entry ShareLinkAccess
+		if err := rows.Scan(&entry.ID, &entry.LinkID, &entry.Method, &entry.Path, &entry.IP,
+			&entry.UserAgent, &entry.Outcome, &entry.Status, &entry.CreatedAt); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan share link access: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, total, rows.Err()
+}
+
+// CountRecent counts a link's requests with the given outcome since a time
+func (r *ShareLinkRepository) CountRecent(linkID, outcome string, since time.Time) (int, error) {
+	var count int
+	err := r.db.QueryRow(`
+		SELECT COUNT(*) FROM share_link_access_log WHERE link_id = ? AND outcome = ? AND created_at >= ?
+	`, linkID, outcome, since).Scan(&count)
+	return count, err
+}
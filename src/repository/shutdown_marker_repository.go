@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/database"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/models"
+)
+
+// ShutdownMarkerRepository stores the shutdown marker of each replica
+type ShutdownMarkerRepository struct {
+	db *sql.DB
+}
+
+var (
+	shutdownMarkerRepo     *ShutdownMarkerRepository
+	shutdownMarkerRepoOnce sync.Once
+)
+
+// GetShutdownMarkerRepository returns the shutdown marker repository
+func GetShutdownMarkerRepository() *ShutdownMarkerRepository {
+	shutdownMarkerRepoOnce.Do(func() {
+		shutdownMarkerRepo = &ShutdownMarkerRepository{db: database.GetDB()}
+	})
+	shutdownMarkerSchema.Ensure(shutdownMarkerRepo.db)
+	return shutdownMarkerRepo
+}
+
+// shutdownMarkerSchema creates the marker table
+var shutdownMarkerSchema = database.RegisterSchema("shutdown_markers", database.Statement(`
+			CREATE TABLE IF NOT EXISTS shutdown_markers (
+				node_id VARCHAR(255) PRIMARY KEY,
+				state VARCHAR(20) NOT NULL,
+				started_at TIMESTAMP NOT NULL,
+				stopped_at TIMESTAMP NULL,
+				requeued TEXT,
+				interrupted TEXT,
+				devices TEXT
+			)
+		`))
+
+// Get returns a replica's marker, or nil when it never recorded one
+func (r *ShutdownMarkerRepository) Get(nodeID string) (*models.ShutdownMarker, error) {
+	marker := models.ShutdownMarker{NodeID: nodeID}
+	var stoppedAt sql.NullTime
+	var requeued, interrupted, devices sql.NullString
+	err := r.db.QueryRow(`
+		SELECT state, started_at, stopped_at, requeued, interrupted, devices
+		FROM shutdown_markers WHERE node_id = ?
+	`, nodeID).Scan(&marker.State, &marker.StartedAt, &stoppedAt, &requeued, &interrupted, &devices)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if stoppedAt.Valid {
+		marker.StoppedAt = &stoppedAt.Time
+	}
+	marker.Requeued = decodeIDs(requeued)
+	marker.Interrupted = decodeIDs(interrupted)
+	marker.Devices = decodeIDs(devices)
+	return &marker, nil
+}
+
+// MarkRunning records that a replica started, replacing its last marker
+func (r *ShutdownMarkerRepository) MarkRunning(nodeID string, startedAt time.Time) error {
+	_, err := r.db.Exec(`
+		INSERT INTO shutdown_markers (node_id, state, started_at, stopped_at, requeued, interrupted, devices)
+		VALUES (?, ?, ?, NULL, NULL, NULL, NULL)
+		ON DUPLICATE KEY UPDATE state = VALUES(state), started_at = VALUES(started_at), stopped_at = NULL,
+			requeued = NULL, interrupted = NULL, devices = NULL
+	`, nodeID, models.ShutdownStateRunning, startedAt)
+	return err
+}
+
+// MarkStopped records that a replica stopped cleanly and what it handed back
+func (r *ShutdownMarkerRepository) MarkStopped(marker models.ShutdownMarker) error {
+	_, err := r.db.Exec(`
+		UPDATE shutdown_markers
+		SET state = ?, stopped_at = ?, requeued = ?, interrupted = ?, devices = ?
+		WHERE node_id = ?
+	`, models.ShutdownStateStopped, marker.StoppedAt, encodeIDs(marker.Requeued), encodeIDs(marker.Interrupted),
+		encodeIDs(marker.Devices), marker.NodeID)
+	return err
+}
+
+func encodeIDs(ids []string) string {
+	if ids == nil {
+		ids = []string{}
+	}
+	data, _ := json.Marshal(ids)
+	return string(data)
+}
+
+func decodeIDs(value sql.NullString) []string {
+	ids := []string{}
+	if value.Valid && value.String != "" {
+		_ = json.Unmarshal([]byte(value.String), &ids)
+	}
+	return ids
+}
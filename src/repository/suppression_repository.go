@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/database"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/listing"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+)
+
+// Suppression is a phone a user never wants imported or messaged
+type Suppression struct {
+	Phone     string    `json:"phone"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SuppressionRepository stores each user's suppression list
+type SuppressionRepository struct {
+	db *sql.DB
+}
+
+var (
+	suppressionRepo     *SuppressionRepository
+	suppressionRepoOnce sync.Once
+)
+
+// GetSuppressionRepository returns the suppression list repository
+func GetSuppressionRepository() *SuppressionRepository {
+	suppressionRepoOnce.Do(func() {
+		suppressionRepo = &SuppressionRepository{db: database.GetDB()}
+	})
+	suppressionSchema.Ensure(suppressionRepo.db)
+	return suppressionRepo
+}
+
+// suppressionSchema creates the suppression list
+var suppressionSchema = database.RegisterSchema("lead_suppressions", database.Statement(`
+			CREATE TABLE IF NOT EXISTS lead_suppressions (
+				user_id VARCHAR(36) NOT NULL,
+				phone VARCHAR(50) NOT NULL,
+				reason VARCHAR(255) NOT NULL DEFAULT '',
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				PRIMARY KEY (user_id, phone)
+			)
+		`))
+
+// SuppressionPhone reduces a phone number or JID to the digits the list is keyed by
+func SuppressionPhone(phone string) string {
+	if at := strings.IndexByte(phone, '@'); at >= 0 {
+		phone = phone[:at]
+	}
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, phone)
+}
+
+// SuppressionFields are the sort and search fields of the suppression list
+var SuppressionFields = listing.Fields{
+	Columns: map[string]string{
+		"phone":      "phone",
+		"created_at": "created_at",
+	},
+	Sort:        []string{"created_at"},
+	Search:      []string{"phone"},
+	Key:         "phone",
+	DefaultSort: "-created_at",
+}
+
+// Add suppresses a phone; suppressing it again updates the reason
+func (r *SuppressionRepository) Add(userID, phone, reason string) error {
+	_, err := r.db.Exec(`
+		INSERT INTO lead_suppressions (user_id, phone, reason) VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE reason = VALUES(reason)
+	`, userID, SuppressionPhone(phone), reason)
+	return err
+}
+
+// Remove lifts the suppression of a phone, reporting whether it was suppressed
+func (r *SuppressionRepository) Remove(userID, phone string) (bool, error) {
+	result, err := r.db.Exec(`DELETE FROM lead_suppressions WHERE user_id = ? AND phone = ?`,
+		userID, SuppressionPhone(phone))
+	if err != nil {
+		return false, err
+	}
+	affected, _ := result.RowsAffected()
+	return affected > 0, nil
+}
+
+// List returns one page of a user's suppressed phones, newest first by default
+func (r *SuppressionRepository) List(userID string, q listing.Query) ([]Suppression, utils.Page, error) {
+	where, args := q.Where()
+	var total int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM lead_suppressions WHERE user_id = ?`+where,
+		append([]any{userID}, args...)...).Scan(&total)
+	if err != nil {
+		return nil, utils.Page{}, err
+	}
+
+	seek, args := q.Seek()
+	rows, err := r.db.Query(`SELECT phone, reason, created_at FROM lead_suppressions WHERE user_id = ?`+seek,
+		append([]any{userID}, args...)...)
+	if err != nil {
+		return nil, utils.Page{}, err
+	}
+	defer rows.Close()
+
+	suppressions := []Suppression{}
+	for rows.Next() {
+		var s Suppression
+		if err := rows.Scan(&s.Phone, &s.Reason, &s.CreatedAt); err != nil {
+			return nil, utils.Page{}, err
+		}
+		suppressions = append(suppressions, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, utils.Page{}, err
+	}
+
+	suppressions, page := listing.Trim(suppressions, q, total, suppressionValue)
+	return suppressions, page, nil
+}
+
+// suppressionValue returns a suppression's value of a SuppressionFields field
+func suppressionValue(s Suppression, field string) any {
+	if field == "created_at" {
+		return s.CreatedAt
+	}
+	return s.Phone
+}
+
+// SuppressedPhones returns the set of a user's suppressed phones
+func (r *SuppressionRepository) SuppressedPhones(userID string) (map[string]bool, error) {
+	rows, err := r.db.Query(`SELECT phone FROM lead_suppressions WHERE user_id = ?`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	phones := map[string]bool{}
+	for rows.Next() {
+		var phone string
+		if err := rows.Scan(&phone); err != nil {
+			return nil, err
+		}
+		phones[phone] = true
+	}
+	return phones, rows.Err()
+}
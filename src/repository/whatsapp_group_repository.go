@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+	"sync"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/database"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/models"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/listing"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+)
+
+// WhatsAppGroupRepository caches the metadata of the groups each device belongs to
+type WhatsAppGroupRepository struct {
+	db *sql.DB
+}
+
+var (
+	whatsAppGroupRepo     *WhatsAppGroupRepository
+	whatsAppGroupRepoOnce sync.Once
+)
+
+// GetWhatsAppGroupRepository returns the group cache repository
+func GetWhatsAppGroupRepository() *WhatsAppGroupRepository {
+	whatsAppGroupRepoOnce.Do(func() {
+		whatsAppGroupRepo = &WhatsAppGroupRepository{db: database.GetDB()}
+	})
+	whatsAppGroupSchema.Ensure(whatsAppGroupRepo.db)
+	return whatsAppGroupRepo
+}
+
+// whatsAppGroupSchema creates the group cache
+var whatsAppGroupSchema = database.RegisterSchema("whatsapp_groups", database.Statement(`
+			CREATE TABLE IF NOT EXISTS whatsapp_groups (
+				id BIGINT AUTO_INCREMENT PRIMARY KEY,
+				device_id VARCHAR(36) NOT NULL,
+				group_jid VARCHAR(100) NOT NULL,
+				name VARCHAR(255) NOT NULL DEFAULT '',
+				participants INT NOT NULL DEFAULT 0,
+				is_admin BOOLEAN NOT NULL DEFAULT FALSE,
+				is_announce BOOLEAN NOT NULL DEFAULT FALSE,
+				refreshed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				UNIQUE KEY uniq_whatsapp_groups_device_group (device_id, group_jid)
+			)
+		`))
+
+// WhatsAppGroupFields are the sort, filter and search fields of the group list
+var WhatsAppGroupFields = listing.Fields{
+	Columns: map[string]string{
+		"id":           "g.id",
+		"device_id":    "g.device_id",
+		"name":         "g.name",
+		"participants": "g.participants",
+		"refreshed_at": "g.refreshed_at",
+	},
+	Sort:        []string{"name", "participants", "refreshed_at"},
+	Filter:      []string{"device_id"},
+	Search:      []string{"name"},
+	Key:         "id",
+	DefaultSort: "name",
+}
+
+// whatsAppGroupRow is a cached group with its row ID, the list's cursor key
+type whatsAppGroupRow struct {
+	id int64
+	models.WhatsAppGroup
+}
+
+// ReplaceDeviceGroups makes the cache of a device's groups match the groups
+// it belongs to now, dropping the groups it left
+func (r *WhatsAppGroupRepository) ReplaceDeviceGroups(deviceID string, groups []models.WhatsAppGroup) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	jids := make([]any, 0, len(groups)+1)
+	jids = append(jids, deviceID)
+	for _, group := range groups {
+		if err := upsertGroup(tx, deviceID, group); err != nil {
+			return err
+		}
+		jids = append(jids, group.GroupJID)
+	}
+	query := `DELETE FROM whatsapp_groups WHERE device_id = ?`
+	if len(groups) > 0 {
+		query += ` AND group_jid NOT IN (` + strings.TrimSuffix(strings.Repeat("?, ", len(groups)), ", ") + `)`
+	}
+	if _, err := tx.Exec(query, jids...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SaveGroup updates the cache of one group
+func (r *WhatsAppGroupRepository) SaveGroup(deviceID string, group models.WhatsAppGroup) error {
+	return upsertGroup(r.db, deviceID, group)
+}
+
+// upsertGroup caches a group in the database or a transaction
+func upsertGroup(db interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}, deviceID string, group models.WhatsAppGroup) error {
+	_, err := db.Exec(`
+		INSERT INTO whatsapp_groups (device_id, group_jid, name, participants, is_admin, is_announce)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE name = VALUES(name), participants = VALUES(participants),
+			is_admin = VALUES(is_admin), is_announce = VALUES(is_announce), refreshed_at = CURRENT_TIMESTAMP
+	`, deviceID, group.GroupJID, group.Name, group.Participants, group.IsAdmin, group.IsAnnounce)
+	return err
+}
+
+// ListGroups returns one page of the cached groups of all a user's devices
+func (r *WhatsAppGroupRepository) ListGroups(userID string, q listing.Query) ([]models.WhatsAppGroup, utils.Page, error) {
+	const from = `
+		FROM whatsapp_groups g
+		JOIN user_devices d ON d.id = g.device_id
+		WHERE d.user_id = ?`
+
+	where, args := q.Where()
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*)`+from+where, append([]any{userID}, args...)...).Scan(&total); err != nil {
+		return nil, utils.Page{}, err
+	}
+
+	seek, args := q.Seek()
+	rows, err := r.db.Query(`
+		SELECT g.id, g.device_id, g.group_jid, g.name, g.participants, g.is_admin, g.is_announce, g.refreshed_at`+
+		from+seek, append([]any{userID}, args...)...)
+	if err != nil {
+		return nil, utils.Page{}, err
+	}
+	defer rows.Close()
+
+	var found []whatsAppGroupRow
+	for rows.Next() {
+		var row whatsAppGroupRow
+		if err := rows.Scan(&row.id, &row.DeviceID, &row.GroupJID, &row.Name, &row.Participants, &row.IsAdmin,
+			&row.IsAnnounce, &row.RefreshedAt); err != nil {
+			return nil, utils.Page{}, err
+		}
+		found = append(found, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, utils.Page{}, err
+	}
+
+	found, page := listing.Trim(found, q, total, whatsAppGroupValue)
+	groups := make([]models.WhatsAppGroup, len(found))
+	for i, row := range found {
+		groups[i] = row.WhatsAppGroup
+	}
+	return groups, page, nil
+}
+
+// whatsAppGroupValue returns a group's value of a WhatsAppGroupFields field
+func whatsAppGroupValue(row whatsAppGroupRow, field string) any {
+	switch field {
+	case "name":
+		return row.Name
+	case "participants":
+		return row.Participants
+	case "refreshed_at":
+		return row.RefreshedAt
+	}
+	return row.id
+}
+
+// GetGroup returns the cached metadata of one of a device's groups
+func (r *WhatsAppGroupRepository) GetGroup(deviceID, groupJID string) (*models.WhatsAppGroup, error) {
+	group := models.WhatsAppGroup{DeviceID: deviceID, GroupJID: groupJID}
+	err := r.db.QueryRow(`
+		SELECT name, participants, is_admin, is_announce, refreshed_at
+		FROM whatsapp_groups WHERE device_id = ? AND group_jid = ?
+	`, deviceID, groupJID).Scan(&group.Name, &group.Participants, &group.IsAdmin, &group.IsAnnounce, &group.RefreshedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/database"
+)
+
+// MessageMedia is the content of a received media message, kept in the
+// media store by hash
+type MessageMedia struct {
+	SHA256       string `json:"sha256"`
+	MimeType     string `json:"mimetype"`
+	Size         int64  `json:"size"`
+	FileName     string `json:"file_name,omitempty"`
+	StorageKey   string `json:"-"`
+	ThumbnailKey string `json:"-"`
+}
+
+// MessageMediaSchema adds the received media columns to whatsapp_messages
+var MessageMediaSchema = database.RegisterSchema("whatsapp_messages.media",
+	database.AddColumn("whatsapp_messages", "media_sha256", "CHAR(64) NULL"),
+	database.AddColumn("whatsapp_messages", "media_mimetype", "VARCHAR(100) NULL"),
+	database.AddColumn("whatsapp_messages", "media_size", "BIGINT NULL"),
+	database.AddColumn("whatsapp_messages", "media_file_name", "VARCHAR(255) NULL"),
+	database.AddColumn("whatsapp_messages", "media_storage_key", "VARCHAR(255) NULL"),
+	database.AddColumn("whatsapp_messages", "media_thumbnail_key", "VARCHAR(255) NULL"),
+)
+
+// SaveMessageMedia links downloaded media to a stored message
+func (r *WhatsAppRepository) SaveMessageMedia(deviceID, messageID string, media *MessageMedia) error {
+	_, err := r.db.Exec(`
+		UPDATE whatsapp_messages
+		SET media_sha256 = ?, media_mimetype = ?, media_size = ?, media_file_name = ?,
+			media_storage_key = ?, media_thumbnail_key = ?
+		WHERE device_id = ? AND message_id = ?
+	`, media.SHA256, media.MimeType, media.Size, media.FileName, media.StorageKey, media.ThumbnailKey,
+		deviceID, messageID)
+	if err != nil {
+		return fmt.Errorf("failed to save message media: %w", err)
+	}
+	return nil
+}
+
+// GetMessageMedia returns the media of a device's message, or nil when the
+// message has none stored
+func (r *WhatsAppRepository) GetMessageMedia(deviceID, messageID string) (*MessageMedia, error) {
+	var media MessageMedia
+	var fileName, thumbnailKey sql.NullString
+	err := r.db.QueryRow(`
+		SELECT media_sha256, media_mimetype, media_size, media_file_name, media_storage_key, media_thumbnail_key
+		FROM whatsapp_messages
+		WHERE device_id = ? AND message_id = ? AND media_storage_key IS NOT NULL
+	`, deviceID, messageID).Scan(&media.SHA256, &media.MimeType, &media.Size, &fileName,
+		&media.StorageKey, &thumbnailKey)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	media.FileName = fileName.String
+	media.ThumbnailKey = thumbnailKey.String
+	return &media, nil
+}
@@ -214,5 +214,6 @@ func GetWhatsAppRepository() *WhatsAppRepository {
 	if whatsappRepo == nil {
 		whatsappRepo = NewWhatsAppRepository(database.GetDB())
 	}
+	MessageMediaSchema.Ensure(whatsappRepo.db)
 	return whatsappRepo
 }
@@ -0,0 +1,528 @@
+// Package simulation runs the campaign, sequence and broadcast pipeline
+// in-process against SQLite, a fake clock and a fake WhatsApp transport so
+// that whole flows can be asserted from go test.
+package simulation
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/database"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/broadcast"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/models"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/clock"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/usecase"
+	"github.com/google/uuid"
+)
+
+// settleTimeout bounds how long the harness waits, in real time, for the
+// pipeline to go idle after each step of the fake clock
+const settleTimeout = 10 * time.Second
+
+var (
+	// scenarios share the process-wide database, clock and broadcast manager,
+	// so only one runs at a time
+	scenarioMu sync.Mutex
+
+	setupOnce sync.Once
+	setupDB   *sql.DB
+	setupErr  error
+)
+
+// Harness drives one scenario. The fake clock only moves through Advance and
+// AdvanceTo, which run the campaign trigger, the broadcast processor and
+// sequence enrollment on their production intervals and wait for workers,
+// hooks and webhooks to finish before moving on.
+type Harness struct {
+	t         testing.TB
+	DB        *sql.DB
+	Clock     *clock.Fake
+	Transport *Transport
+
+	trigger   *usecase.OptimizedCampaignTrigger
+	processor *usecase.UltraOptimizedBroadcastProcessor
+	enroller  *usecase.DirectBroadcastProcessor
+	manager   *broadcast.UltraScaleBroadcastManager
+	jobs      []*job
+
+	webhookMu        sync.Mutex
+	webhooks         []map[string]interface{}
+	expectedWebhooks int
+}
+
+// job is a periodic background task. due reports the earliest time the task
+// has work, so the clock can skip the ticks where it would do nothing.
+type job struct {
+	every   time.Duration
+	due     func() (time.Time, bool)
+	run     func()
+	lastRun time.Time
+}
+
+// next returns the first tick of the job at or after its due time
+func (j *job) next(now time.Time) (time.Time, bool) {
+	due, ok := j.due()
+	if !ok {
+		return time.Time{}, false
+	}
+	if due.Before(now) {
+		due = now
+	}
+	tick := due.Truncate(j.every)
+	if tick.Before(due) {
+		tick = tick.Add(j.every)
+	}
+	if !tick.After(j.lastRun) {
+		tick = j.lastRun.Add(j.every)
+	}
+	return tick, true
+}
+
+// New starts a scenario at start. Everything it changes is undone when the
+// test finishes.
+func New(t testing.TB, start time.Time) *Harness {
+	t.Helper()
+	scenarioMu.Lock()
+
+	db, err := setup()
+	if err != nil {
+		scenarioMu.Unlock()
+		t.Fatalf("simulation setup failed: %v", err)
+	}
+
+	h := &Harness{
+		t:         t,
+		DB:        db,
+		Clock:     clock.NewFake(start.UTC()),
+		Transport: NewTransport(),
+	}
+	h.Transport.onReply = func() {
+		h.webhookMu.Lock()
+		h.expectedWebhooks++
+		h.webhookMu.Unlock()
+	}
+	if err := h.reset(); err != nil {
+		scenarioMu.Unlock()
+		t.Fatalf("simulation reset failed: %v", err)
+	}
+
+	webhook := httptest.NewServer(http.HandlerFunc(h.receiveWebhook))
+	restoreClock := clock.Set(h.Clock)
+	previousWebhook, previousStorage := config.WhatsappWebhook, config.WhatsappChatStorage
+	config.WhatsappWebhook = []string{webhook.URL}
+	config.WhatsappChatStorage = false
+
+	broadcast.ShutdownBroadcastManager()
+	restoreSender := broadcast.SetMessageSenderFactory(func() broadcast.MessageSender { return h.Transport })
+
+	h.trigger = usecase.NewOptimizedCampaignTrigger(db)
+	h.processor = usecase.NewUltraOptimizedBroadcastProcessor()
+	h.enroller = usecase.NewDirectBroadcastProcessor(db)
+	h.manager = broadcast.GetUltraScaleBroadcastManager()
+	h.jobs = []*job{
+		{every: time.Minute, due: h.campaignDue, run: func() {
+			if err := h.trigger.ProcessCampaigns(); err != nil {
+				t.Errorf("campaign trigger failed: %v", err)
+			}
+		}},
+		{every: 5 * time.Minute, due: h.enrollmentDue, run: func() {
+			if _, err := h.enroller.ProcessDirectEnrollments(); err != nil {
+				t.Errorf("sequence enrollment failed: %v", err)
+			}
+		}},
+		{every: 5 * time.Second, due: h.messagesDue, run: h.processor.ProcessMessages},
+	}
+
+	t.Cleanup(func() {
+		h.Transport.close()
+		broadcast.ShutdownBroadcastManager()
+		// Workers stopped mid-delay wake up on the clock they slept on
+		for deadline := time.Now().Add(settleTimeout); broadcast.MessagesInFlight() > 0 && time.Now().Before(deadline); {
+			h.Clock.Advance(time.Hour)
+			time.Sleep(time.Millisecond)
+		}
+		h.trigger.Wait()
+		restoreSender()
+		config.WhatsappWebhook, config.WhatsappChatStorage = previousWebhook, previousStorage
+		restoreClock()
+		webhook.Close()
+		scenarioMu.Unlock()
+	})
+	return h
+}
+
+// setup opens the shared SQLite database once per test binary and installs
+// it as the application database
+func setup() (*sql.DB, error) {
+	setupOnce.Do(func() {
+		dir, err := os.MkdirTemp("", "wa-simulation-")
+		if err != nil {
+			setupErr = err
+			return
+		}
+		setupDB, err = OpenSQLite(filepath.Join(dir, "simulation.db"))
+		if err != nil {
+			setupErr = err
+			return
+		}
+		for _, statement := range schema {
+			if _, err := setupDB.Exec(statement); err != nil {
+				setupErr = fmt.Errorf("failed to create schema: %w", err)
+				return
+			}
+		}
+		database.SetDB(setupDB)
+		usecase.StartSequenceStepMaterializer()
+	})
+	return setupDB, setupErr
+}
+
+// reset empties every table, including the ones repositories created
+func (h *Harness) reset() error {
+	rows, err := h.DB.Query(`SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%'`)
+	if err != nil {
+		return err
+	}
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+		tables = append(tables, name)
+	}
+	rows.Close()
+
+	for _, table := range tables {
+		if _, err := h.DB.Exec(`DELETE FROM ` + table); err != nil {
+			return fmt.Errorf("failed to empty %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
+func (h *Harness) receiveWebhook(w http.ResponseWriter, r *http.Request) {
+	var payload map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		h.t.Errorf("invalid webhook payload: %v", err)
+	}
+	h.webhookMu.Lock()
+	h.webhooks = append(h.webhooks, payload)
+	h.webhookMu.Unlock()
+	w.WriteHeader(http.StatusOK)
+}
+
+// Now returns the simulated time
+func (h *Harness) Now() time.Time {
+	return h.Clock.Now()
+}
+
+// Advance runs the simulation for d
+func (h *Harness) Advance(d time.Duration) {
+	h.t.Helper()
+	h.AdvanceTo(h.Now().Add(d))
+}
+
+// AdvanceTo runs the simulation until end, stopping at every timer and
+// background tick in between
+func (h *Harness) AdvanceTo(end time.Time) {
+	h.t.Helper()
+	h.settle()
+	for {
+		next, ok := h.nextEvent()
+		if !ok || next.After(end) {
+			break
+		}
+		h.Clock.AdvanceTo(next)
+		h.runDueJobs()
+		h.settle()
+	}
+	h.Clock.AdvanceTo(end)
+	h.settle()
+}
+
+func (h *Harness) nextEvent() (time.Time, bool) {
+	now := h.Now()
+	next, ok := h.Clock.Next()
+	for _, j := range h.jobs {
+		if tick, due := j.next(now); due && (!ok || tick.Before(next)) {
+			next, ok = tick, true
+		}
+	}
+	return next, ok
+}
+
+func (h *Harness) runDueJobs() {
+	now := h.Now()
+	for _, j := range h.jobs {
+		if tick, due := j.next(now); due && !tick.After(now) {
+			j.lastRun = now
+			j.run()
+			h.trigger.Wait()
+		}
+	}
+}
+
+// settle waits until campaigns are queued, sent hooks and webhooks have run,
+// and every busy device worker is asleep on the fake clock
+func (h *Harness) settle() {
+	h.t.Helper()
+	h.trigger.Wait()
+
+	deadline := time.Now().Add(settleTimeout)
+	last, stable := "", 0
+	for stable < 3 {
+		if time.Now().After(deadline) {
+			h.t.Fatalf("simulation did not settle at %s: %d messages in flight, %d sent hooks running, state %s",
+				h.Now().Format(time.RFC3339), broadcast.MessagesInFlight(), repository.MessageSentHooksRunning(), last)
+		}
+		time.Sleep(time.Millisecond)
+
+		h.webhookMu.Lock()
+		webhooksPending := len(h.webhooks) < h.expectedWebhooks
+		h.webhookMu.Unlock()
+		workersBusy := broadcast.MessagesInFlight() > 0 && h.Clock.Sleepers() < h.manager.ActiveDeviceGroups()
+		if webhooksPending || workersBusy || repository.MessageSentHooksRunning() > 0 {
+			stable = 0
+			continue
+		}
+
+		if current := h.fingerprint(); current == last {
+			stable++
+		} else {
+			last, stable = current, 0
+		}
+	}
+}
+
+// fingerprint summarizes the state that background work changes
+func (h *Harness) fingerprint() string {
+	var parts []string
+	rows, err := h.DB.Query(`SELECT status, COUNT(*), COALESCE(MAX(updated_at), '') FROM broadcast_messages GROUP BY status ORDER BY status`)
+	if err == nil {
+		for rows.Next() {
+			var status, updated string
+			var count int
+			if rows.Scan(&status, &count, &updated) == nil {
+				parts = append(parts, fmt.Sprintf("%s=%d@%s", status, count, updated))
+			}
+		}
+		rows.Close()
+	}
+	var contacts, step int
+	h.DB.QueryRow(`SELECT COUNT(*), COALESCE(SUM(current_step), 0) FROM sequence_contacts`).Scan(&contacts, &step)
+	h.webhookMu.Lock()
+	webhooks := len(h.webhooks)
+	h.webhookMu.Unlock()
+	return fmt.Sprintf("%s|contacts=%d/%d|attempts=%d|webhooks=%d|timers=%d",
+		strings.Join(parts, ","), contacts, step, len(h.Transport.Attempts()), webhooks, h.Clock.Waiters())
+}
+
+func (h *Harness) campaignDue() (time.Time, bool) {
+	return h.queryTime(`
+		SELECT MIN(COALESCE(scheduled_at, STR_TO_DATE(CONCAT(campaign_date, ' ', COALESCE(time_schedule, '00:00:00')), '%Y-%m-%d %H:%i:%s')))
+		FROM campaigns WHERE status = 'pending'`)
+}
+
+// messagesDue matches the processor's window, which picks up messages up to
+// eight hours before their scheduled time
+func (h *Harness) messagesDue() (time.Time, bool) {
+	due, ok := h.queryTime(`SELECT MIN(scheduled_at) FROM broadcast_messages WHERE status = 'pending'`)
+	return due.Add(-8 * time.Hour), ok
+}
+
+func (h *Harness) enrollmentDue() (time.Time, bool) {
+	var count int
+	h.DB.QueryRow("SELECT COUNT(*) FROM leads WHERE `trigger` IS NOT NULL AND `trigger` != ''").Scan(&count)
+	return h.Now(), count > 0
+}
+
+func (h *Harness) queryTime(query string) (time.Time, bool) {
+	var at sql.NullTime
+	if err := h.DB.QueryRow(query).Scan(&at); err != nil {
+		h.t.Errorf("simulation query failed: %v", err)
+		return time.Time{}, false
+	}
+	return at.Time, at.Valid
+}
+
+// Webhooks returns the payloads the webhook endpoint received
+func (h *Harness) Webhooks() []map[string]interface{} {
+	h.webhookMu.Lock()
+	defer h.webhookMu.Unlock()
+	return append([]map[string]interface{}(nil), h.webhooks...)
+}
+
+// exec runs a seeding statement and fails the test on error
+func (h *Harness) exec(query string, args ...interface{}) {
+	h.t.Helper()
+	if _, err := h.DB.Exec(query, args...); err != nil {
+		h.t.Fatalf("simulation seed failed: %v", err)
+	}
+}
+
+// AddUser creates a user and returns its ID
+func (h *Harness) AddUser(email string) string {
+	h.t.Helper()
+	id := uuid.New().String()
+	h.exec(`INSERT INTO users (id, email, full_name, created_at, updated_at) VALUES (?, ?, ?, ?, ?)`,
+		id, email, email, h.Now(), h.Now())
+	return id
+}
+
+// AddDevice creates an online device for the user and returns its ID
+func (h *Harness) AddDevice(userID, name string) string {
+	h.t.Helper()
+	id := uuid.New().String()
+	// Devices are created a second apart so their listing order is stable
+	created := h.Now().Add(-time.Hour).Add(time.Duration(h.count("user_devices")) * time.Second)
+	h.exec(`INSERT INTO user_devices (id, user_id, device_name, phone, status, last_seen, created_at, updated_at)
+		VALUES (?, ?, ?, ?, 'online', ?, ?, ?)`, id, userID, name, "", h.Now(), created, created)
+	return id
+}
+
+// Lead is a contact seeded on a device
+type Lead struct {
+	Phone        string
+	Name         string
+	Niche        string
+	TargetStatus string
+	Trigger      string
+}
+
+// AddLead creates a lead owned by the device and returns its ID
+func (h *Harness) AddLead(deviceID string, lead Lead) string {
+	h.t.Helper()
+	var userID, deviceName string
+	if err := h.DB.QueryRow(`SELECT user_id, device_name FROM user_devices WHERE id = ?`, deviceID).Scan(&userID, &deviceName); err != nil {
+		h.t.Fatalf("unknown device %s: %v", deviceID, err)
+	}
+	if lead.TargetStatus == "" {
+		lead.TargetStatus = "prospect"
+	}
+	if lead.Name == "" {
+		lead.Name = lead.Phone
+	}
+	id := uuid.New().String()
+	h.exec("INSERT INTO leads (id, device_id, device_name, user_id, name, phone, niche, target_status, `trigger`, created_at, updated_at) "+
+		"VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		id, deviceID, deviceName, userID, lead.Name, lead.Phone, lead.Niche, lead.TargetStatus, lead.Trigger, h.Now(), h.Now())
+	return id
+}
+
+// Campaign is a campaign scheduled for At
+type Campaign struct {
+	Title        string
+	Message      string
+	Niche        string
+	TargetStatus string
+	At           time.Time
+	MinDelay     int
+	MaxDelay     int
+	Groups       []models.CampaignGroupTarget // posts to these groups instead of leads
+}
+
+// AddCampaign creates a pending campaign and returns its ID
+func (h *Harness) AddCampaign(userID string, campaign Campaign) int {
+	h.t.Helper()
+	if campaign.TargetStatus == "" {
+		campaign.TargetStatus = "prospect"
+	}
+	at := campaign.At.UTC()
+	result, err := h.DB.Exec(`INSERT INTO campaigns (user_id, title, niche, target_status, message, campaign_date, time_schedule,
+			scheduled_at, min_delay_seconds, max_delay_seconds, target_groups, status, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 'pending', ?, ?)`,
+		userID, campaign.Title, campaign.Niche, campaign.TargetStatus, campaign.Message,
+		at.Format("2006-01-02"), at.Format("15:04:05"), at, campaign.MinDelay, campaign.MaxDelay,
+		repository.EncodeGroupTargets(campaign.Groups), h.Now(), h.Now())
+	if err != nil {
+		h.t.Fatalf("simulation seed failed: %v", err)
+	}
+	id, _ := result.LastInsertId()
+	return int(id)
+}
+
+// Sequence is an active sequence whose first step is its entry point
+type Sequence struct {
+	Name     string
+	Niche    string
+	Trigger  string
+	MinDelay int
+	MaxDelay int
+	Steps    []Step
+}
+
+// Step is one day of a sequence
+type Step struct {
+	Trigger    string
+	Content    string
+	DelayHours int
+}
+
+// AddSequence creates the sequence with its steps and returns its ID
+func (h *Harness) AddSequence(userID string, sequence Sequence) string {
+	h.t.Helper()
+	id := uuid.New().String()
+	h.exec("INSERT INTO sequences (id, user_id, device_id, name, description, niche, `trigger`, is_active, status, min_delay_seconds, max_delay_seconds, created_at, updated_at) "+
+		"VALUES (?, ?, '', ?, '', ?, ?, true, 'active', ?, ?, ?, ?)",
+		id, userID, sequence.Name, sequence.Niche, sequence.Trigger, sequence.MinDelay, sequence.MaxDelay, h.Now(), h.Now())
+	for i, step := range sequence.Steps {
+		nextTrigger := ""
+		if i+1 < len(sequence.Steps) {
+			nextTrigger = sequence.Steps[i+1].Trigger
+		}
+		h.exec("INSERT INTO sequence_steps (id, sequence_id, day_number, `trigger`, next_trigger, trigger_delay_hours, is_entry_point, message_type, content, created_at, updated_at) "+
+			"VALUES (?, ?, ?, ?, ?, ?, ?, 'text', ?, ?, ?)",
+			uuid.New().String(), id, i+1, step.Trigger, nextTrigger, step.DelayHours, i == 0, step.Content, h.Now(), h.Now())
+	}
+	return id
+}
+
+func (h *Harness) count(table string) int {
+	var n int
+	h.DB.QueryRow(`SELECT COUNT(*) FROM ` + table).Scan(&n)
+	return n
+}
+
+// MessageStatuses counts the broadcast messages of a campaign by status
+func (h *Harness) MessageStatuses(campaignID int) map[string]int {
+	h.t.Helper()
+	return h.statuses(`SELECT status, COUNT(*) FROM broadcast_messages WHERE campaign_id = ? GROUP BY status`, campaignID)
+}
+
+// SequenceStatuses counts the broadcast messages of a sequence by status
+func (h *Harness) SequenceStatuses(sequenceID string) map[string]int {
+	h.t.Helper()
+	return h.statuses(`SELECT status, COUNT(*) FROM broadcast_messages WHERE sequence_id = ? GROUP BY status`, sequenceID)
+}
+
+func (h *Harness) statuses(query string, args ...interface{}) map[string]int {
+	h.t.Helper()
+	rows, err := h.DB.Query(query, args...)
+	if err != nil {
+		h.t.Fatalf("simulation query failed: %v", err)
+	}
+	defer rows.Close()
+	statuses := map[string]int{}
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			h.t.Fatalf("simulation query failed: %v", err)
+		}
+		statuses[status] = count
+	}
+	return statuses
+}
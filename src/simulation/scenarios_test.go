@@ -0,0 +1,731 @@
+package simulation
+
+import (
+	"context"
+	"os"
+	"sort"
+	"testing"
+	"time"
+
+	domainBroadcast "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/broadcast"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/broadcast"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/models"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mau.fi/whatsmeow/types"
+)
+
+var morning = time.Date(2026, 10, 19, 9, 55, 0, 0, time.UTC)
+
+func TestCampaignSendsLeadsAcrossDevicesWithDelays(t *testing.T) {
+	h := New(t, morning)
+	user := h.AddUser("owner@example.com")
+	phoneA := h.AddDevice(user, "phone-a")
+	phoneB := h.AddDevice(user, "phone-b")
+	h.AddLead(phoneA, Lead{Phone: "60111000001", Niche: "fitness"})
+	h.AddLead(phoneA, Lead{Phone: "60111000002", Niche: "fitness"})
+	h.AddLead(phoneB, Lead{Phone: "60111000003", Niche: "fitness"})
+	h.AddLead(phoneB, Lead{Phone: "60111000004", Niche: "cooking"})
+
+	at := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+	campaign := h.AddCampaign(user, Campaign{
+		Title: "Launch", Message: "Hello", Niche: "fitness", At: at, MinDelay: 30, MaxDelay: 30,
+	})
+
+	h.AdvanceTo(at.Add(-time.Second))
+	assert.Empty(t, h.Transport.Sent(), "nothing goes out before the schedule")
+
+	h.AdvanceTo(at.Add(5 * time.Minute))
+	sent := h.Transport.Sent()
+	require.Len(t, sent, 3)
+
+	byDevice := map[string][]time.Time{}
+	var phones []string
+	for _, send := range sent {
+		byDevice[send.Device] = append(byDevice[send.Device], send.At)
+		phones = append(phones, send.Phone)
+	}
+	sort.Strings(phones)
+	assert.Equal(t, []string{"60111000001", "60111000002", "60111000003"}, phones)
+	require.Len(t, byDevice["phone-a"], 2)
+	require.Len(t, byDevice["phone-b"], 1)
+	assert.Equal(t, 30*time.Second, byDevice["phone-a"][1].Sub(byDevice["phone-a"][0]), "device delay between sends")
+	assert.Equal(t, byDevice["phone-a"][0], byDevice["phone-b"][0], "devices send in parallel")
+	assert.False(t, byDevice["phone-a"][0].Before(at))
+
+	assert.Equal(t, map[string]int{"sent": 3}, h.MessageStatuses(campaign))
+}
+
+func TestSendFailuresFollowRetryPolicy(t *testing.T) {
+	h := New(t, morning)
+	user := h.AddUser("owner@example.com")
+	device := h.AddDevice(user, "phone-a")
+	h.AddLead(device, Lead{Phone: "60111000001", Niche: "fitness"})
+	h.AddLead(device, Lead{Phone: "60111000002", Niche: "fitness"})
+	h.Transport.FailPhone("60111000001", pkgError.SendErrNetwork, 1)
+	h.Transport.FailPhone("60111000002", pkgError.SendErrNotOnWhatsapp, 1)
+
+	campaign := h.AddCampaign(user, Campaign{
+		Title: "Launch", Message: "Hello", Niche: "fitness", At: morning, MinDelay: 10, MaxDelay: 10,
+	})
+	h.Advance(10 * time.Minute)
+
+	assert.Len(t, h.Transport.Attempts(), 3, "the network failure is retried once")
+	sent := h.Transport.Sent()
+	require.Len(t, sent, 1)
+	assert.Equal(t, "60111000001", sent[0].Phone)
+	assert.Equal(t, map[string]int{"sent": 1, "failed": 1}, h.MessageStatuses(campaign))
+
+	var code string
+	require.NoError(t, h.DB.QueryRow(`SELECT error_code FROM broadcast_messages WHERE recipient_phone = ?`, "60111000002").Scan(&code))
+	assert.Equal(t, string(pkgError.SendErrNotOnWhatsapp), code)
+	assert.Equal(t, 1, h.count("broadcast_dead_letters"))
+}
+
+func TestSequenceEnrollsOnTriggerAndQueuesNextStep(t *testing.T) {
+	h := New(t, morning)
+	user := h.AddUser("owner@example.com")
+	device := h.AddDevice(user, "phone-a")
+	sequence := h.AddSequence(user, Sequence{
+		Name: "Onboarding", Niche: "fitness", Trigger: "onboard", MinDelay: 5, MaxDelay: 5,
+		Steps: []Step{
+			{Trigger: "onboard_day1", Content: "Welcome", DelayHours: 24},
+			{Trigger: "onboard_day2", Content: "Day two", DelayHours: 24},
+		},
+	})
+	h.AddLead(device, Lead{Phone: "60111000001", Niche: "fitness", Trigger: "onboard_day1"})
+
+	h.Advance(10 * time.Minute)
+	assert.Equal(t, map[string]int{"pending": 1}, h.SequenceStatuses(sequence), "the entry step is queued on enrollment")
+	assert.Empty(t, h.Transport.Sent())
+
+	h.Advance(24 * time.Hour)
+	sent := h.Transport.Sent()
+	require.Len(t, sent, 1)
+	// Sends are varied per recipient, so compare the queued content
+	var content string
+	require.NoError(t, h.DB.QueryRow(`SELECT content FROM broadcast_messages WHERE id = ?`, sent[0].BroadcastID).Scan(&content))
+	assert.Equal(t, "Welcome", content)
+	assert.Equal(t, map[string]int{"sent": 1, "pending": 1}, h.SequenceStatuses(sequence), "the next step is queued after the send")
+
+	var step int
+	require.NoError(t, h.DB.QueryRow(`SELECT current_step FROM sequence_contacts WHERE sequence_id = ?`, sequence).Scan(&step))
+	assert.Equal(t, 2, step)
+}
+
+func TestSequenceListCountsEachSequencesContacts(t *testing.T) {
+	h := New(t, morning)
+	user := h.AddUser("owner@example.com")
+	device := h.AddDevice(user, "phone-a")
+	steps := []Step{{Trigger: "onboard_day1", Content: "Welcome", DelayHours: 24}}
+	onboarding := h.AddSequence(user, Sequence{Name: "Onboarding", Niche: "fitness", Trigger: "onboard", MinDelay: 5, MaxDelay: 5, Steps: steps})
+	idle := h.AddSequence(user, Sequence{Name: "Idle", Niche: "fitness", Trigger: "idle", MinDelay: 5, MaxDelay: 5,
+		Steps: []Step{{Trigger: "idle_day1", Content: "Hello", DelayHours: 24}}})
+	h.AddLead(device, Lead{Phone: "60111000001", Niche: "fitness", Trigger: "onboard_day1"})
+	h.AddLead(device, Lead{Phone: "60111000002", Niche: "fitness", Trigger: "onboard_day1"})
+	h.Advance(10 * time.Minute)
+
+	sequences, err := usecase.NewSequenceUsecase(nil, nil).GetSequences(user)
+	require.NoError(t, err)
+	counts := map[string]int{}
+	for _, seq := range sequences {
+		counts[seq.ID] = seq.ContactCount
+	}
+	assert.Equal(t, map[string]int{onboarding: 2, idle: 0}, counts)
+}
+
+func TestSequenceContactLeftOnSentStepIsCaughtUpOnce(t *testing.T) {
+	h := New(t, morning)
+	user := h.AddUser("owner@example.com")
+	device := h.AddDevice(user, "phone-a")
+	sequence := h.AddSequence(user, Sequence{
+		Name: "Onboarding", Niche: "fitness", Trigger: "onboard", MinDelay: 5, MaxDelay: 5,
+		Steps: []Step{
+			{Trigger: "onboard_day1", Content: "Welcome", DelayHours: 24},
+			{Trigger: "onboard_day2", Content: "Day two", DelayHours: 24},
+		},
+	})
+	h.AddLead(device, Lead{Phone: "60111000001", Niche: "fitness", Trigger: "onboard_day1"})
+	h.Advance(24*time.Hour + 10*time.Minute)
+	require.Len(t, h.Transport.Sent(), 1)
+
+	// A crash after the next step was queued leaves the contact on the sent step
+	h.exec(`UPDATE sequence_contacts SET sequence_stepid = (
+		SELECT id FROM sequence_steps WHERE sequence_id = ? AND day_number = 1
+	), current_step = 1 WHERE sequence_id = ?`, sequence, sequence)
+
+	advanced, err := usecase.NewSequenceStepMaterializer(h.DB).CatchUp(10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, advanced)
+	assert.Equal(t, map[string]int{"sent": 1, "pending": 1}, h.SequenceStatuses(sequence), "the queued step is not queued again")
+
+	var step int
+	require.NoError(t, h.DB.QueryRow(`SELECT current_step FROM sequence_contacts WHERE sequence_id = ?`, sequence).Scan(&step))
+	assert.Equal(t, 2, step)
+}
+
+func TestSentLastStepMovesContactToLinkedSequence(t *testing.T) {
+	h := New(t, morning)
+	user := h.AddUser("owner@example.com")
+	device := h.AddDevice(user, "phone-a")
+	cold := h.AddSequence(user, Sequence{
+		Name: "Cold", Niche: "fitness", Trigger: "cold", MinDelay: 5, MaxDelay: 5,
+		Steps: []Step{{Trigger: "cold_day1", Content: "Hello", DelayHours: 24}},
+	})
+	warm := h.AddSequence(user, Sequence{
+		Name: "Warm", Niche: "fitness", Trigger: "warm", MinDelay: 5, MaxDelay: 5,
+		Steps: []Step{{Trigger: "warmup", Content: "Still there?", DelayHours: 24}},
+	})
+	h.exec(`UPDATE sequence_steps SET next_trigger = 'warmup' WHERE sequence_id = ?`, cold)
+	h.AddLead(device, Lead{Phone: "60111000001", Niche: "fitness", Trigger: "cold_day1"})
+
+	h.Advance(24*time.Hour + 10*time.Minute)
+	require.Len(t, h.Transport.Sent(), 1)
+	assert.Equal(t, map[string]int{"sent": 1}, h.SequenceStatuses(cold))
+	assert.Equal(t, map[string]int{"pending": 1}, h.SequenceStatuses(warm), "the linked entry step is queued after the send")
+
+	status := func(sequenceID string) string {
+		var status string
+		require.NoError(t, h.DB.QueryRow(`SELECT status FROM sequence_contacts WHERE sequence_id = ?`, sequenceID).Scan(&status))
+		return status
+	}
+	assert.Equal(t, "completed", status(cold))
+	assert.Equal(t, "active", status(warm))
+}
+
+func TestReenrolledContactStartsANewRun(t *testing.T) {
+	h := New(t, morning)
+	user := h.AddUser("owner@example.com")
+	device := h.AddDevice(user, "phone-a")
+	sequence := h.AddSequence(user, Sequence{
+		Name: "Onboarding", Niche: "fitness", Trigger: "onboard", MinDelay: 5, MaxDelay: 5,
+		Steps: []Step{
+			{Trigger: "onboard_day1", Content: "Welcome", DelayHours: 24},
+			{Trigger: "onboard_day2", Content: "Day two", DelayHours: 24},
+		},
+	})
+	h.AddLead(device, Lead{Phone: "60111000001", Niche: "fitness", Trigger: "onboard_day1"})
+	h.Advance(48*time.Hour + 10*time.Minute)
+	require.Len(t, h.Transport.Sent(), 2)
+
+	position := func() (step int, status string) {
+		require.NoError(t, h.DB.QueryRow(`SELECT current_step, status FROM sequence_contacts WHERE sequence_id = ?`, sequence).Scan(&step, &status))
+		return step, status
+	}
+	_, status := position()
+	require.Equal(t, "completed", status)
+
+	materializer := usecase.NewSequenceStepMaterializer(h.DB)
+	lead := models.Lead{UserID: user, Phone: "60111000001", DeviceID: device, DeviceName: "phone-a"}
+	require.NoError(t, materializer.Enroll(sequence, lead))
+	assert.Equal(t, map[string]int{"sent": 2, "pending": 1}, h.SequenceStatuses(sequence), "the entry step is queued again")
+
+	advanced, err := materializer.CatchUp(10)
+	require.NoError(t, err)
+	assert.Equal(t, 0, advanced, "sends from the first run do not move the contact")
+	step, status := position()
+	assert.Equal(t, 1, step)
+	assert.Equal(t, "active", status)
+
+	h.Advance(24 * time.Hour)
+	assert.Len(t, h.Transport.Sent(), 3)
+	step, _ = position()
+	assert.Equal(t, 2, step)
+}
+
+func TestContactOnDeletedStepIsCompleted(t *testing.T) {
+	h := New(t, morning)
+	user := h.AddUser("owner@example.com")
+	device := h.AddDevice(user, "phone-a")
+	sequence := h.AddSequence(user, Sequence{
+		Name: "Onboarding", Niche: "fitness", Trigger: "onboard", MinDelay: 5, MaxDelay: 5,
+		Steps: []Step{
+			{Trigger: "onboard_day1", Content: "Welcome", DelayHours: 24},
+			{Trigger: "onboard_day2", Content: "Day two", DelayHours: 24},
+		},
+	})
+	h.AddLead(device, Lead{Phone: "60111000001", Niche: "fitness", Trigger: "onboard_day1"})
+	h.Advance(24*time.Hour + 10*time.Minute)
+	require.Len(t, h.Transport.Sent(), 1)
+
+	// The contact is left on the sent step, which is then deleted
+	h.exec(`UPDATE sequence_contacts SET sequence_stepid = (
+		SELECT id FROM sequence_steps WHERE sequence_id = ? AND day_number = 1
+	), current_step = 1 WHERE sequence_id = ?`, sequence, sequence)
+	h.exec(`DELETE FROM sequence_steps WHERE sequence_id = ? AND day_number = 1`, sequence)
+
+	materializer := usecase.NewSequenceStepMaterializer(h.DB)
+	advanced, err := materializer.CatchUp(10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, advanced)
+
+	var status string
+	require.NoError(t, h.DB.QueryRow(`SELECT status FROM sequence_contacts WHERE sequence_id = ?`, sequence).Scan(&status))
+	assert.Equal(t, "completed", status)
+
+	advanced, err = materializer.CatchUp(10)
+	require.NoError(t, err)
+	assert.Equal(t, 0, advanced, "the contact leaves the catch-up sweep")
+}
+
+func TestMigratePreQueuedKeepsOnePositionPerSequenceAndPhone(t *testing.T) {
+	h := New(t, morning)
+	user := h.AddUser("owner@example.com")
+	first := h.AddDevice(user, "phone-a")
+	second := h.AddDevice(user, "phone-b")
+	sequence := h.AddSequence(user, Sequence{
+		Name: "Onboarding", Niche: "fitness", Trigger: "onboard", MinDelay: 5, MaxDelay: 5,
+		Steps: []Step{
+			{Trigger: "onboard_day1", Content: "Welcome", DelayHours: 24},
+			{Trigger: "onboard_day2", Content: "Day two", DelayHours: 24},
+		},
+	})
+
+	// The old eager enrollment queued every step, here for the phone on two devices
+	broadcasts := repository.GetBroadcastRepository()
+	for i, device := range []string{first, second} {
+		for day := 1; day <= 2; day++ {
+			var stepID string
+			require.NoError(t, h.DB.QueryRow(`SELECT id FROM sequence_steps WHERE sequence_id = ? AND day_number = ?`,
+				sequence, day).Scan(&stepID))
+			require.NoError(t, broadcasts.QueueMessage(domainBroadcast.BroadcastMessage{
+				UserID: user, DeviceID: device, SequenceID: &sequence, SequenceStepID: &stepID,
+				RecipientPhone: "60111000001", Content: "Step",
+				ScheduledAt: morning.Add(time.Duration(24*day+i) * time.Hour),
+			}))
+		}
+	}
+
+	materializer := usecase.NewSequenceStepMaterializer(h.DB)
+	summary, err := materializer.MigratePreQueued(user, false)
+	require.NoError(t, err)
+	assert.Equal(t, usecase.MigrationSummary{Contacts: 1, KeptMessages: 1, RemovedMessages: 3}, *summary)
+	assert.Equal(t, map[string]int{"pending": 4}, h.SequenceStatuses(sequence), "a dry run changes nothing")
+
+	summary, err = materializer.MigratePreQueued(user, true)
+	require.NoError(t, err)
+	assert.True(t, summary.Applied)
+	assert.Equal(t, map[string]int{"pending": 1}, h.SequenceStatuses(sequence))
+
+	var contacts, step int
+	var deviceID string
+	require.NoError(t, h.DB.QueryRow(`SELECT COUNT(*), MAX(current_step), MAX(assigned_device_id) FROM sequence_contacts WHERE sequence_id = ?`,
+		sequence).Scan(&contacts, &step, &deviceID))
+	assert.Equal(t, 1, contacts)
+	assert.Equal(t, 1, step)
+	assert.Equal(t, first, deviceID, "the earliest row decides the device")
+}
+
+func TestGroupCampaignPostsToGroupsOfConnectedDevices(t *testing.T) {
+	h := New(t, morning)
+	user := h.AddUser("owner@example.com")
+	phoneA := h.AddDevice(user, "phone-a")
+	phoneB := h.AddDevice(user, "phone-b")
+	h.exec(`UPDATE user_devices SET status = 'offline' WHERE id = ?`, phoneB)
+	h.AddLead(phoneA, Lead{Phone: "60111000001", Niche: "fitness"})
+
+	at := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+	campaign := h.AddCampaign(user, Campaign{
+		Title: "Group post", Message: "Hello group", Niche: "fitness", At: at,
+		Groups: []models.CampaignGroupTarget{
+			{DeviceID: phoneA, GroupJID: "120363000000000001@g.us"},
+			{DeviceID: phoneB, GroupJID: "120363000000000002@g.us"},
+		},
+	})
+
+	h.AdvanceTo(at.Add(5 * time.Minute))
+	sent := h.Transport.Sent()
+	require.Len(t, sent, 1, "only the group of the connected device, and no leads")
+	assert.Equal(t, "120363000000000001@g.us", sent[0].Phone)
+	assert.Equal(t, "phone-a", sent[0].Device)
+	assert.Equal(t, map[string]int{"sent": 1}, h.MessageStatuses(campaign))
+}
+
+func TestCampaignLeavesOutSuppressedPhones(t *testing.T) {
+	h := New(t, morning)
+	user := h.AddUser("owner@example.com")
+	device := h.AddDevice(user, "phone-a")
+	h.AddLead(device, Lead{Phone: "60111000001", Niche: "fitness"})
+	h.AddLead(device, Lead{Phone: "60111000002", Niche: "fitness"})
+	require.NoError(t, repository.GetSuppressionRepository().Add(user, "+60 11-1000 002", "opted out"))
+
+	at := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+	campaign := h.AddCampaign(user, Campaign{Title: "Launch", Message: "Hello", Niche: "fitness", At: at})
+
+	h.AdvanceTo(at.Add(5 * time.Minute))
+	sent := h.Transport.Sent()
+	require.Len(t, sent, 1)
+	assert.Equal(t, "60111000001", sent[0].Phone)
+	assert.Equal(t, map[string]int{"sent": 1}, h.MessageStatuses(campaign))
+}
+
+func TestPlannerForecastsWhatTheCampaignTriggerQueues(t *testing.T) {
+	h := New(t, morning)
+	user := h.AddUser("owner@example.com")
+	phoneA := h.AddDevice(user, "phone-a")
+	phoneB := h.AddDevice(user, "phone-b")
+	h.exec(`UPDATE user_devices SET status = 'offline' WHERE id = ?`, phoneB)
+	h.AddLead(phoneA, Lead{Phone: "60111000001", Niche: "fitness"})
+	h.AddLead(phoneA, Lead{Phone: "60111000002", Niche: "fitness"})
+	h.AddLead(phoneA, Lead{Phone: "60111000003", Niche: "fitness"})
+	h.AddLead(phoneA, Lead{Phone: "60111000004", Niche: "fitness", TargetStatus: "customer"})
+	h.AddLead(phoneB, Lead{Phone: "60111000005", Niche: "fitness"})
+	h.exec(`UPDATE leads SET number_status = 'invalid' WHERE phone = ?`, "60111000002")
+	require.NoError(t, repository.GetSuppressionRepository().Add(user, "60111000003", "opted out"))
+
+	at := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+	campaign := h.AddCampaign(user, Campaign{Title: "Launch", Message: "Hello", Niche: "fitness", At: at})
+	// An empty target status means prospects to the trigger
+	h.exec(`UPDATE campaigns SET target_status = '' WHERE id = ?`, campaign)
+
+	planner := usecase.NewBroadcastPlanner()
+	day, err := planner.ParseDate("2026-10-19")
+	require.NoError(t, err)
+	timeline, err := planner.Forecast(user, day, 1)
+	require.NoError(t, err)
+	require.Len(t, timeline, 1)
+	forecast := map[string]int{}
+	for _, device := range timeline[0].Devices {
+		forecast[device.DeviceName] = device.CampaignMessages
+	}
+	assert.Equal(t, map[string]int{"phone-a": 1, "phone-b": 0}, forecast)
+
+	h.AdvanceTo(at.Add(5 * time.Minute))
+	sent := h.Transport.Sent()
+	require.Len(t, sent, 1, "the trigger queues what the planner forecast")
+	assert.Equal(t, "60111000001", sent[0].Phone)
+	assert.Equal(t, map[string]int{"sent": 1}, h.MessageStatuses(campaign))
+}
+
+func TestGroupImportCreatesEachLeadOnce(t *testing.T) {
+	h := New(t, morning)
+	user := h.AddUser("owner@example.com")
+	device := h.AddDevice(user, "phone-a")
+	h.AddLead(device, Lead{Phone: "60111000001", Niche: "fitness"})
+	require.NoError(t, repository.GetSuppressionRepository().Add(user, "60111000003", "opted out"))
+
+	group := types.NewJID("120363000000000001", types.GroupServer)
+	fetching, release := make(chan struct{}), make(chan struct{})
+	restore := whatsapp.SetGroupMembersSource(func(string, types.JID) (models.WhatsAppGroup, []models.WhatsAppGroupMember, error) {
+		close(fetching)
+		<-release
+		return models.WhatsAppGroup{GroupJID: group.String(), Name: "Runners"}, []models.WhatsAppGroupMember{
+			{Phone: "60111000001"}, // already a lead of the device
+			{Phone: "60111000002"},
+			{Phone: "60111000002"}, // listed twice
+			{Phone: "60111000003"}, // suppressed
+			{Phone: ""},            // hidden number
+		}, nil
+	})
+	defer restore()
+
+	options := whatsapp.GroupLeadOptions{Niche: "runners"}
+	started := make(chan error, 1)
+	go func() {
+		_, err := whatsapp.ImportGroupLeads(device, user, group, options)
+		started <- err
+	}()
+	<-fetching
+	_, err := whatsapp.ImportGroupLeads(device, user, group, options)
+	assert.ErrorIs(t, err, whatsapp.ErrGroupImportRunning, "the group is reserved while its members are fetched")
+	close(release)
+	require.NoError(t, <-started)
+
+	var job whatsapp.GroupImport
+	require.Eventually(t, func() bool {
+		job, _ = whatsapp.GetGroupImport(device, group.String())
+		return job.Status == whatsapp.GroupImportComplete
+	}, settleTimeout, 10*time.Millisecond)
+	assert.Equal(t, 5, job.Total)
+	assert.Equal(t, 1, job.Existing)
+	assert.Equal(t, 1, job.Suppressed)
+
+	leads := map[string]int{}
+	rows, err := h.DB.Query(`SELECT phone, COUNT(*) FROM leads WHERE device_id = ? GROUP BY phone`, device)
+	require.NoError(t, err)
+	defer rows.Close()
+	for rows.Next() {
+		var phone string
+		var count int
+		require.NoError(t, rows.Scan(&phone, &count))
+		leads[phone] = count
+	}
+	assert.Equal(t, map[string]int{"60111000001": 1, "60111000002": 1}, leads)
+}
+
+func TestRepliesAndReceiptsReachTheEventHandlers(t *testing.T) {
+	h := New(t, morning)
+	user := h.AddUser("owner@example.com")
+	device := h.AddDevice(user, "phone-a")
+	h.AddLead(device, Lead{Phone: "60111000001", Niche: "fitness"})
+	h.Transport.Receipts(2*time.Second, time.Minute)
+	h.Transport.ReplyFrom("60111000001", "Interested!", 90*time.Second)
+
+	h.AddCampaign(user, Campaign{Title: "Launch", Message: "Hello", Niche: "fitness", At: morning, MinDelay: 5, MaxDelay: 5})
+	h.Advance(5 * time.Minute)
+
+	require.Len(t, h.Transport.Sent(), 1)
+	receipts := h.Transport.ReceiptsSent()
+	require.Len(t, receipts, 2)
+	assert.Equal(t, h.Transport.Sent()[0].At.Add(2*time.Second), receipts[0].At)
+
+	var delivered, read int
+	require.NoError(t, h.DB.QueryRow(`SELECT COUNT(delivered_at), COUNT(read_at) FROM broadcast_messages`).Scan(&delivered, &read))
+	assert.Equal(t, 1, delivered, "the delivery receipt is stamped on the broadcast message")
+	assert.Equal(t, 1, read, "the read receipt is stamped on the broadcast message")
+
+	webhooks := h.Webhooks()
+	require.Len(t, webhooks, 1)
+	assert.Equal(t, "60111000001@s.whatsapp.net", webhooks[0]["from"])
+	message, _ := webhooks[0]["message"].(map[string]interface{})
+	assert.Equal(t, "Interested!", message["text"])
+}
+
+func TestPausedCampaignHoldsMessagesUntilResumedWithNewDelay(t *testing.T) {
+	h := New(t, morning)
+	user := h.AddUser("owner@example.com")
+	device := h.AddDevice(user, "phone-a")
+	for _, phone := range []string{"60111000001", "60111000002", "60111000003", "60111000004"} {
+		h.AddLead(device, Lead{Phone: phone, Niche: "fitness"})
+	}
+	campaign := h.AddCampaign(user, Campaign{
+		Title: "Launch", Message: "Hello", Niche: "fitness", At: morning, MinDelay: 60, MaxDelay: 60,
+	})
+	campaigns := repository.GetCampaignRepository()
+
+	h.AdvanceTo(morning.Add(30 * time.Second))
+	require.Len(t, h.Transport.Sent(), 1)
+	_, err := campaigns.PauseCampaign(campaign, user)
+	require.NoError(t, err)
+
+	h.Advance(10 * time.Minute)
+	assert.Len(t, h.Transport.Sent(), 1, "nothing goes out while paused")
+	assert.Equal(t, map[string]int{"sent": 1, "pending": 3}, h.MessageStatuses(campaign), "claimed messages are released")
+
+	_, err = campaigns.ThrottleCampaign(campaign, user, 5, 5)
+	require.NoError(t, err)
+	_, err = campaigns.ResumeCampaign(campaign, user)
+	require.NoError(t, err)
+	h.Advance(5 * time.Minute)
+
+	sent := h.Transport.Sent()
+	require.Len(t, sent, 4)
+	assert.Equal(t, 5*time.Second, sent[3].At.Sub(sent[2].At), "the new delay applies to the remaining messages")
+	assert.Equal(t, map[string]int{"sent": 4}, h.MessageStatuses(campaign))
+
+	events, err := campaigns.GetCampaignEvents(campaign)
+	require.NoError(t, err)
+	var actions []string
+	for _, event := range events {
+		actions = append(actions, event.Action+":"+event.FromStatus+">"+event.ToStatus)
+	}
+	assert.Equal(t, []string{"pause:processing>paused", "throttle:paused>paused", "resume:paused>triggered"}, actions)
+}
+
+func TestCancelledCampaignSendsNothingMore(t *testing.T) {
+	h := New(t, morning)
+	user := h.AddUser("owner@example.com")
+	device := h.AddDevice(user, "phone-a")
+	for _, phone := range []string{"60111000001", "60111000002", "60111000003"} {
+		h.AddLead(device, Lead{Phone: phone, Niche: "fitness"})
+	}
+	campaign := h.AddCampaign(user, Campaign{
+		Title: "Launch", Message: "Hello", Niche: "fitness", At: morning, MinDelay: 60, MaxDelay: 60,
+	})
+	campaigns := repository.GetCampaignRepository()
+
+	h.AdvanceTo(morning.Add(30 * time.Second))
+	event, err := campaigns.CancelCampaign(campaign, user, "wrong link")
+	require.NoError(t, err)
+	assert.Equal(t, 2, event.Messages)
+
+	h.Advance(10 * time.Minute)
+	assert.Len(t, h.Transport.Sent(), 1)
+	assert.Equal(t, map[string]int{"sent": 1, "cancelled": 2}, h.MessageStatuses(campaign))
+
+	_, err = campaigns.ResumeCampaign(campaign, user)
+	assert.ErrorIs(t, err, repository.ErrCampaignTransition)
+}
+
+func TestDrainHandsBackMessagesNotSent(t *testing.T) {
+	h := New(t, morning)
+	user := h.AddUser("owner@example.com")
+	device := h.AddDevice(user, "phone-a")
+	for _, phone := range []string{"60111000001", "60111000002", "60111000003"} {
+		h.AddLead(device, Lead{Phone: phone, Niche: "fitness"})
+	}
+	campaign := h.AddCampaign(user, Campaign{
+		Title: "Launch", Message: "Hello", Niche: "fitness", At: morning, MinDelay: 60, MaxDelay: 60,
+	})
+
+	h.AdvanceTo(morning.Add(30 * time.Second))
+	require.Len(t, h.Transport.Sent(), 1)
+
+	// The next send waits out its delay, so the drain hits its deadline
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	drained := broadcast.Drain(ctx)
+	assert.Len(t, drained.Unsent, 2)
+	assert.Empty(t, drained.Interrupted)
+
+	requeued, err := repository.GetBroadcastRepository().RequeueMessages(drained.Unsent)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), requeued)
+	assert.Equal(t, map[string]int{"sent": 1, "pending": 2}, h.MessageStatuses(campaign))
+}
+
+func TestResendAfterCrashCarriesTheFirstWhatsAppMessageID(t *testing.T) {
+	h := New(t, morning)
+	user := h.AddUser("owner@example.com")
+	device := h.AddDevice(user, "phone-a")
+	h.AddLead(device, Lead{Phone: "60111000001", Niche: "fitness"})
+	campaign := h.AddCampaign(user, Campaign{
+		Title: "Launch", Message: "Hello", Niche: "fitness", At: morning, MinDelay: 10, MaxDelay: 10,
+	})
+	h.Advance(time.Minute)
+	require.Len(t, h.Transport.Sent(), 1)
+
+	// A crash between the send and its ack leaves the row claimed until the
+	// stuck message cleanup returns it to pending
+	first := h.Transport.Sent()[0]
+	h.exec(`UPDATE broadcast_messages SET status = 'pending', processing_worker_id = NULL WHERE id = ?`, first.BroadcastID)
+	h.Advance(time.Minute)
+
+	sent := h.Transport.Sent()
+	require.Len(t, sent, 2)
+	assert.Equal(t, first.MessageID, sent[1].MessageID, "WhatsApp drops a resend with the ID it already has")
+	assert.NotContains(t, first.MessageID, "SIM-", "the ID is recorded before the send")
+	assert.Equal(t, map[string]int{"sent": 1}, h.MessageStatuses(campaign))
+}
+
+func TestIdempotencyKeyAllowsOneLiveMessagePerRecipient(t *testing.T) {
+	h := New(t, morning)
+	user := h.AddUser("owner@example.com")
+	device := h.AddDevice(user, "phone-a")
+	campaign := h.AddCampaign(user, Campaign{
+		Title: "Later", Message: "Hello", Niche: "fitness", At: morning.Add(24 * time.Hour),
+	})
+	broadcasts := repository.GetBroadcastRepository()
+	queue := func() {
+		require.NoError(t, broadcasts.QueueMessage(domainBroadcast.BroadcastMessage{
+			UserID: user, DeviceID: device, CampaignID: &campaign, RecipientPhone: "60111000001",
+			Content: "Hello", ScheduledAt: morning.Add(24 * time.Hour),
+		}))
+	}
+
+	queue()
+	queue()
+	assert.Equal(t, map[string]int{"pending": 1}, h.MessageStatuses(campaign))
+
+	// A failed send gives the key up for another attempt
+	h.exec(`UPDATE broadcast_messages SET status = 'failed' WHERE campaign_id = ?`, campaign)
+	queue()
+	queue()
+	assert.Equal(t, map[string]int{"pending": 1, "failed": 1}, h.MessageStatuses(campaign))
+}
+
+func TestEraseDataSubjectRemovesThePhoneEverywhere(t *testing.T) {
+	h := New(t, morning)
+	user := h.AddUser("owner@example.com")
+	device := h.AddDevice(user, "phone-a")
+	h.AddLead(device, Lead{Phone: "60111000001", Niche: "fitness"})
+	h.AddLead(device, Lead{Phone: "60111000002", Niche: "fitness"})
+	require.NoError(t, repository.GetLeadRepository().RecordConsent(&models.LeadConsent{
+		UserID: user, Phone: "60111000001", Channel: models.ConsentChannelWebhook, ProofText: "Ticked the box",
+	}))
+	h.exec(`INSERT INTO whatsapp_messages (device_id, chat_jid, message_id, sender_jid, message_text, timestamp)
+		VALUES (?, '60111000001@s.whatsapp.net', 'M1', '60111000001@s.whatsapp.net', 'Hi', 0)`, device)
+	// One photo only the erased number sent, one the other lead sent too
+	files := mediaFiles{"own": []byte("own photo"), "own.thumb": []byte("thumb"), "shared": []byte("shared photo")}
+	for _, m := range []struct{ chat, id, sha, key, thumb string }{
+		{"60111000001", "M2", "own-sha", "own", "own.thumb"},
+		{"60111000001", "M3", "shared-sha", "shared", ""},
+		{"60111000002", "M4", "shared-sha", "shared", ""},
+	} {
+		h.exec(`INSERT INTO whatsapp_messages (device_id, chat_jid, message_id, sender_jid, timestamp,
+			media_sha256, media_mimetype, media_size, media_storage_key, media_thumbnail_key)
+			VALUES (?, ?, ?, ?, 0, ?, 'image/jpeg', 9, ?, ?)`,
+			device, m.chat+"@s.whatsapp.net", m.id, m.chat+"@s.whatsapp.net", m.sha, m.key, m.thumb)
+	}
+
+	sent := h.AddCampaign(user, Campaign{Title: "Launch", Message: "Hello", Niche: "fitness", At: morning, MinDelay: 10, MaxDelay: 10})
+	h.Advance(time.Minute)
+	require.Len(t, h.Transport.Sent(), 2)
+	later := h.AddCampaign(user, Campaign{Title: "Follow-up", Message: "Again", Niche: "fitness", At: morning.Add(24 * time.Hour)})
+	for _, phone := range []string{"60111000001", "60111000002"} {
+		require.NoError(t, repository.GetBroadcastRepository().QueueMessage(domainBroadcast.BroadcastMessage{
+			UserID: user, DeviceID: device, CampaignID: &later, RecipientPhone: phone,
+			Content: "Again", ScheduledAt: h.Now().Add(time.Hour),
+		}))
+	}
+
+	// Lead webhook responses cached for both numbers
+	keys := repository.GetIdempotencyRepository()
+	for _, phone := range []string{"60111000001", "60111000002"} {
+		key := repository.LeadIdempotencyKey(user, phone, device, "fitness")
+		_, err := keys.Begin("anonymous", key, "fingerprint")
+		require.NoError(t, err)
+		require.NoError(t, keys.Complete("anonymous", key, 200, "application/json", []byte(`{"phone":"`+phone+`"}`), time.Hour))
+	}
+
+	subjects := repository.GetDataSubjectRepository()
+	data, err := subjects.Export(user, "+60111000001", user, files)
+	require.NoError(t, err)
+	assert.Len(t, data.Tables["leads"], 1)
+	assert.Len(t, data.Tables["idempotency_keys"], 1)
+	assert.Len(t, data.Tables["lead_consents"], 1)
+	assert.Len(t, data.Tables["broadcast_messages"], 2)
+	assert.Len(t, data.Tables["whatsapp_messages"], 3)
+	require.Len(t, data.Media, 2)
+	assert.Equal(t, []byte("own photo"), data.Media[0].Content)
+	assert.Equal(t, []byte("shared photo"), data.Media[1].Content)
+
+	erased, err := subjects.Erase(user, "+60111000001", user, files)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), erased.Affected["leads"])
+	assert.Equal(t, int64(2), erased.Affected["broadcast_messages"])
+	assert.Equal(t, int64(1), erased.Affected["idempotency_keys"])
+	assert.Equal(t, int64(1), erased.Affected["media"])
+	assert.Equal(t, mediaFiles{"shared": []byte("shared photo")}, files, "media another message uses is kept")
+
+	after, err := subjects.Export(user, "60111000001", user, files)
+	require.NoError(t, err)
+	assert.Empty(t, after.Tables)
+	assert.Empty(t, after.Media)
+	assert.Equal(t, 1, h.count("leads"), "the other lead is kept")
+	assert.Equal(t, 1, h.count("idempotency_keys"))
+	assert.Equal(t, map[string]int{"sent": 2}, h.MessageStatuses(sent), "sends stay counted under a pseudonym")
+	assert.Equal(t, map[string]int{"pending": 1, "cancelled": 1}, h.MessageStatuses(later))
+
+	h.Advance(2 * time.Hour)
+	require.Len(t, h.Transport.Sent(), 3)
+	assert.Equal(t, "60111000002", h.Transport.Sent()[2].Phone, "nothing more goes to the erased number")
+
+	requests, err := subjects.Requests(user, "60111000001")
+	require.NoError(t, err)
+	require.Len(t, requests, 3)
+	assert.Equal(t, models.DataSubjectErase, requests[1].Action)
+	assert.Equal(t, erased.Pseudonym, requests[1].Pseudonym)
+}
+
+// mediaFiles is a media store in memory, by object key
+type mediaFiles map[string][]byte
+
+func (f mediaFiles) Read(_ context.Context, key string) ([]byte, error) {
+	data, ok := f[key]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return data, nil
+}
+
+func (f mediaFiles) Remove(_ context.Context, _ string, keys ...string) error {
+	for _, key := range keys {
+		delete(f, key)
+	}
+	return nil
+}
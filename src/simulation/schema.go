@@ -0,0 +1,241 @@
+package simulation
+
+// schema creates the tables the simulated flows touch, written in MySQL and
+// passed through Translate like every other statement. Tables that
+// repositories create on first use are left to them.
+var schema = []string{`
+	CREATE TABLE IF NOT EXISTS users (
+		id VARCHAR(36) PRIMARY KEY,
+		email VARCHAR(255) NOT NULL,
+		full_name VARCHAR(255) NOT NULL DEFAULT '',
+		password_hash VARCHAR(255) NOT NULL DEFAULT '',
+		is_active BOOLEAN DEFAULT true,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		last_login DATETIME NULL
+	)`, `
+	CREATE TABLE IF NOT EXISTS user_devices (
+		id VARCHAR(36) PRIMARY KEY,
+		user_id VARCHAR(36) NOT NULL,
+		device_name VARCHAR(255) NOT NULL,
+		phone VARCHAR(50),
+		jid VARCHAR(255),
+		status VARCHAR(50) DEFAULT 'offline',
+		platform VARCHAR(50),
+		min_delay_seconds INT DEFAULT 5,
+		max_delay_seconds INT DEFAULT 15,
+		last_seen DATETIME DEFAULT CURRENT_TIMESTAMP,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`, `
+	CREATE TABLE IF NOT EXISTS leads (
+		id VARCHAR(36) PRIMARY KEY,
+		device_id VARCHAR(36),
+		device_name VARCHAR(255),
+		user_id VARCHAR(36),
+		name VARCHAR(255) NOT NULL DEFAULT '',
+		phone VARCHAR(50) NOT NULL,
+		email VARCHAR(255) NOT NULL DEFAULT '',
+		niche VARCHAR(255),
+		source VARCHAR(255) NOT NULL DEFAULT '',
+		journey TEXT,
+		notes TEXT NOT NULL DEFAULT '',
+		status VARCHAR(50) DEFAULT 'new',
+		target_status VARCHAR(50) DEFAULT 'prospect',
+		` + "`trigger`" + ` VARCHAR(1000),
+		platform VARCHAR(50) NULL,
+		number_status VARCHAR(20) NULL,
+		number_checked_at DATETIME NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`, `
+	CREATE TABLE IF NOT EXISTS campaigns (
+		id INT AUTO_INCREMENT PRIMARY KEY,
+		user_id VARCHAR(36) NOT NULL,
+		device_id VARCHAR(36),
+		title VARCHAR(255) NOT NULL,
+		niche VARCHAR(255),
+		target_status VARCHAR(50) DEFAULT 'all',
+		message TEXT NOT NULL,
+		image_url TEXT,
+		message_type VARCHAR(20) NULL,
+		message_payload TEXT NULL,
+		target_groups TEXT NULL,
+		campaign_date VARCHAR(10),
+		time_schedule VARCHAR(8),
+		scheduled_at DATETIME NULL,
+		min_delay_seconds INT DEFAULT 10,
+		max_delay_seconds INT DEFAULT 30,
+		status VARCHAR(50) DEFAULT 'pending',
+		ai VARCHAR(10) NULL,
+		` + "`limit`" + ` INT DEFAULT 0,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`, `
+	CREATE TABLE IF NOT EXISTS sequences (
+		id VARCHAR(36) PRIMARY KEY,
+		user_id VARCHAR(36) NOT NULL,
+		device_id VARCHAR(36),
+		name VARCHAR(255) NOT NULL,
+		description TEXT,
+		niche VARCHAR(255),
+		` + "`trigger`" + ` VARCHAR(255),
+		start_trigger VARCHAR(255),
+		end_trigger VARCHAR(255),
+		total_days INT DEFAULT 0,
+		is_active BOOLEAN DEFAULT true,
+		status VARCHAR(50) DEFAULT 'active',
+		min_delay_seconds INT DEFAULT 10,
+		max_delay_seconds INT DEFAULT 30,
+		schedule_time VARCHAR(8),
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`, `
+	CREATE TABLE IF NOT EXISTS sequence_steps (
+		id VARCHAR(36) PRIMARY KEY,
+		sequence_id VARCHAR(36) NOT NULL,
+		day_number INT NOT NULL DEFAULT 1,
+		` + "`trigger`" + ` VARCHAR(255),
+		next_trigger VARCHAR(255),
+		trigger_delay_hours INT DEFAULT 24,
+		is_entry_point BOOLEAN DEFAULT false,
+		message_type VARCHAR(20) DEFAULT 'text',
+		content TEXT,
+		media_url TEXT,
+		message_payload TEXT NULL,
+		min_delay_seconds INT NULL,
+		max_delay_seconds INT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`, `
+	CREATE TABLE IF NOT EXISTS sequence_contacts (
+		id VARCHAR(36) PRIMARY KEY,
+		sequence_id VARCHAR(36) NOT NULL,
+		contact_phone VARCHAR(50) NOT NULL,
+		contact_name VARCHAR(255),
+		current_step INT DEFAULT 0,
+		status VARCHAR(50) DEFAULT 'active',
+		current_trigger VARCHAR(255),
+		next_trigger_time DATETIME NULL,
+		assigned_device_id VARCHAR(36),
+		sequence_stepid VARCHAR(36),
+		user_id VARCHAR(36),
+		processing_device_id VARCHAR(36),
+		last_message_at DATETIME NULL,
+		completed_at DATETIME NULL,
+		enrolled_at DATETIME NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE KEY uniq_sequence_contact (sequence_id, contact_phone)
+	)`, `
+	CREATE TABLE IF NOT EXISTS broadcast_messages (
+		id VARCHAR(36) PRIMARY KEY,
+		user_id VARCHAR(36),
+		device_id VARCHAR(255) NOT NULL,
+		device_name VARCHAR(255),
+		campaign_id INT NULL,
+		sequence_id VARCHAR(36) NULL,
+		sequence_stepid VARCHAR(36) NULL,
+		recipient_phone VARCHAR(50) NOT NULL,
+		recipient_name VARCHAR(255),
+		message_type VARCHAR(20) DEFAULT 'text',
+		content TEXT,
+		media_url TEXT,
+		message_payload TEXT NULL,
+		status VARCHAR(20) DEFAULT 'pending',
+		error_message TEXT,
+		error_code VARCHAR(40) NULL,
+		retry_count INT NOT NULL DEFAULT 0,
+		scheduled_at DATETIME NULL,
+		sent_at DATETIME NULL,
+		whatsapp_message_id VARCHAR(64) NULL,
+		delivered_at DATETIME NULL,
+		read_at DATETIME NULL,
+		processing_worker_id VARCHAR(255) NULL,
+		processing_started_at DATETIME NULL,
+		group_id VARCHAR(255) NULL,
+		group_order INT NULL,
+		trace_context VARCHAR(64) NULL,
+		idempotency_key VARCHAR(191) NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE KEY uniq_broadcast_idempotency_key (idempotency_key),
+		INDEX idx_broadcast_status (status, scheduled_at)
+	)`, `
+	CREATE TABLE IF NOT EXISTS campaign_events (
+		id BIGINT AUTO_INCREMENT PRIMARY KEY,
+		campaign_id INT NOT NULL,
+		actor VARCHAR(255) NOT NULL,
+		action VARCHAR(20) NOT NULL,
+		from_status VARCHAR(50) NOT NULL DEFAULT '',
+		to_status VARCHAR(50) NOT NULL DEFAULT '',
+		reason VARCHAR(500) NOT NULL DEFAULT '',
+		min_delay_seconds INT NULL,
+		max_delay_seconds INT NULL,
+		messages INT NOT NULL DEFAULT 0,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		INDEX idx_campaign_events_campaign (campaign_id, created_at)
+	)`, `
+	CREATE TABLE IF NOT EXISTS lead_events (
+		id BIGINT AUTO_INCREMENT PRIMARY KEY,
+		user_id VARCHAR(36) NOT NULL,
+		phone VARCHAR(50) NOT NULL,
+		kind VARCHAR(20) NOT NULL,
+		actor VARCHAR(255) NOT NULL,
+		from_value VARCHAR(255) NOT NULL DEFAULT '',
+		to_value VARCHAR(255) NOT NULL DEFAULT '',
+		text TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		INDEX idx_lead_events_lead (user_id, phone, created_at)
+	)`, `
+	CREATE TABLE IF NOT EXISTS lead_suppressions (
+		user_id VARCHAR(36) NOT NULL,
+		phone VARCHAR(50) NOT NULL,
+		reason VARCHAR(255) NOT NULL DEFAULT '',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (user_id, phone)
+	)`, `
+	CREATE TABLE IF NOT EXISTS leads_ai (
+		id INT AUTO_INCREMENT PRIMARY KEY,
+		user_id VARCHAR(36) NOT NULL,
+		device_id VARCHAR(36),
+		name VARCHAR(255) NOT NULL,
+		phone VARCHAR(50) NOT NULL,
+		niche VARCHAR(255),
+		status VARCHAR(50) DEFAULT 'pending',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`, `
+	CREATE TABLE IF NOT EXISTS whatsapp_chats (
+		id INT AUTO_INCREMENT PRIMARY KEY,
+		device_id VARCHAR(255) NOT NULL,
+		chat_jid VARCHAR(255) NOT NULL,
+		chat_name VARCHAR(255) NOT NULL,
+		last_message_text TEXT,
+		UNIQUE KEY uniq_whatsapp_chat (device_id, chat_jid)
+	)`, `
+	CREATE TABLE IF NOT EXISTS whatsapp_messages (
+		id INT AUTO_INCREMENT PRIMARY KEY,
+		device_id VARCHAR(255) NOT NULL,
+		chat_jid VARCHAR(255) NOT NULL,
+		message_id VARCHAR(255) NOT NULL,
+		sender_jid VARCHAR(255),
+		message_text TEXT,
+		timestamp BIGINT NOT NULL,
+		media_sha256 CHAR(64) NULL,
+		media_mimetype VARCHAR(100) NULL,
+		media_size BIGINT NULL,
+		media_file_name VARCHAR(255) NULL,
+		media_storage_key VARCHAR(255) NULL,
+		media_thumbnail_key VARCHAR(255) NULL,
+		UNIQUE KEY uniq_whatsapp_message (device_id, message_id)
+	)`, `
+	CREATE TABLE IF NOT EXISTS message_analytics (
+		id VARCHAR(36) PRIMARY KEY,
+		user_id VARCHAR(36) NOT NULL,
+		device_id VARCHAR(36),
+		message_id VARCHAR(255) NOT NULL,
+		jid VARCHAR(255) NOT NULL,
+		content TEXT,
+		status VARCHAR(50) NOT NULL
+	)`,
+}
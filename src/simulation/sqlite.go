@@ -0,0 +1,383 @@
+package simulation
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/clock"
+	"github.com/mattn/go-sqlite3"
+)
+
+// driverName is a SQLite driver that accepts the MySQL dialect the
+// repositories are written in, with NOW() read from the installed clock
+const driverName = "sqlite3_mysql"
+
+// timeLayout is how DATETIME values are stored, matching MySQL's format so
+// string comparisons order them correctly
+const timeLayout = "2006-01-02 15:04:05"
+
+var registerDriver sync.Once
+
+// OpenSQLite opens a SQLite database at path that speaks enough of the MySQL
+// dialect for the broadcast, campaign and sequence code paths
+func OpenSQLite(path string) (*sql.DB, error) {
+	registerDriver.Do(func() {
+		sql.Register(driverName, &dialectDriver{inner: &sqlite3.SQLiteDriver{ConnectHook: registerFunctions}})
+	})
+	db, err := sql.Open(driverName, fmt.Sprintf("file:%s?_journal_mode=WAL&_busy_timeout=10000&_txlock=immediate", path))
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// registerFunctions adds the MySQL functions the queries use
+func registerFunctions(conn *sqlite3.SQLiteConn) error {
+	functions := []struct {
+		name string
+		impl interface{}
+		pure bool
+	}{
+		{"now", func() string { return clock.Now().UTC().Format(timeLayout) }, false},
+		{"utc_timestamp", func() string { return clock.Now().UTC().Format(timeLayout) }, false},
+		{"curdate", func() string { return clock.Now().UTC().Format("2006-01-02") }, false},
+		{"database", func() string { return "main" }, true},
+		{"concat", concat, true},
+		{"str_to_date", strToDate, true},
+		{"date_add_mysql", func(t, n interface{}, unit string) interface{} { return dateAdd(t, n, 1, unit) }, true},
+		{"date_sub_mysql", func(t, n interface{}, unit string) interface{} { return dateAdd(t, n, -1, unit) }, true},
+		{"timestampdiff", timestampDiff, true},
+		{"unix_timestamp", func(t interface{}) interface{} {
+			parsed, ok := parseTime(t)
+			if !ok {
+				return nil
+			}
+			return parsed.Unix()
+		}, true},
+	}
+	for _, f := range functions {
+		if err := conn.RegisterFunc(f.name, f.impl, f.pure); err != nil {
+			return fmt.Errorf("register %s: %w", f.name, err)
+		}
+	}
+	return nil
+}
+
+// concat follows MySQL: any NULL argument makes the result NULL
+func concat(args ...interface{}) interface{} {
+	var b strings.Builder
+	for _, arg := range args {
+		switch v := arg.(type) {
+		case nil:
+			return nil
+		case []byte:
+			b.Write(v)
+		default:
+			fmt.Fprint(&b, v)
+		}
+	}
+	return b.String()
+}
+
+var mysqlDateFormat = strings.NewReplacer("%Y", "2006", "%m", "01", "%d", "02", "%H", "15", "%i", "04", "%s", "05")
+
+func strToDate(value interface{}, format string) interface{} {
+	s, ok := value.(string)
+	if !ok {
+		return nil
+	}
+	layout := mysqlDateFormat.Replace(format)
+	t, err := time.Parse(layout, s)
+	if err != nil {
+		return nil
+	}
+	if !strings.Contains(layout, "15") {
+		return t.Format("2006-01-02")
+	}
+	return t.Format(timeLayout)
+}
+
+func dateAdd(value, amount interface{}, sign int64, unit string) interface{} {
+	t, ok := parseTime(value)
+	if !ok {
+		return nil
+	}
+	var n int64
+	switch v := amount.(type) {
+	case int64:
+		n = v
+	case float64:
+		n = int64(v)
+	case string:
+		if _, err := fmt.Sscan(v, &n); err != nil {
+			return nil
+		}
+	default:
+		return nil
+	}
+	n *= sign
+	switch strings.ToUpper(unit) {
+	case "SECOND":
+		t = t.Add(time.Duration(n) * time.Second)
+	case "MINUTE":
+		t = t.Add(time.Duration(n) * time.Minute)
+	case "HOUR":
+		t = t.Add(time.Duration(n) * time.Hour)
+	case "DAY":
+		t = t.AddDate(0, 0, int(n))
+	case "WEEK":
+		t = t.AddDate(0, 0, 7*int(n))
+	case "MONTH":
+		t = t.AddDate(0, int(n), 0)
+	case "YEAR":
+		t = t.AddDate(int(n), 0, 0)
+	default:
+		return nil
+	}
+	return t.Format(timeLayout)
+}
+
+func timestampDiff(unit string, from, to interface{}) interface{} {
+	start, ok := parseTime(from)
+	if !ok {
+		return nil
+	}
+	end, ok := parseTime(to)
+	if !ok {
+		return nil
+	}
+	d := end.Sub(start)
+	switch strings.ToUpper(unit) {
+	case "SECOND":
+		return int64(d / time.Second)
+	case "MINUTE":
+		return int64(d / time.Minute)
+	case "HOUR":
+		return int64(d / time.Hour)
+	case "DAY":
+		return int64(d / (24 * time.Hour))
+	}
+	return nil
+}
+
+func parseTime(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v.UTC(), true
+	case []byte:
+		return parseTime(string(v))
+	case string:
+		for _, layout := range []string{timeLayout, "2006-01-02", time.RFC3339Nano, "2006-01-02 15:04:05.999999999-07:00"} {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t.UTC(), true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+var (
+	createTablePattern   = regexp.MustCompile(`(?is)^\s*CREATE\s+TABLE`)
+	autoIncrementPattern = regexp.MustCompile(`(?i)\b(?:BIG)?INT(?:EGER)?(?:\(\d+\))?\s+(?:UNSIGNED\s+)?(?:NOT NULL\s+)?(?:AUTO_INCREMENT\s+PRIMARY\s+KEY|PRIMARY\s+KEY\s+AUTO_INCREMENT)`)
+	uniqueKeyPattern     = regexp.MustCompile("(?i)UNIQUE\\s+(?:KEY|INDEX)\\s+`?\\w+`?\\s*\\(")
+	indexPattern         = regexp.MustCompile("(?i),\\s*(?:FULLTEXT\\s+|SPATIAL\\s+)?(?:INDEX|KEY)\\s+`?\\w+`?\\s*\\([^)]*\\)")
+	onUpdatePattern      = regexp.MustCompile(`(?i)\s+ON\s+UPDATE\s+CURRENT_TIMESTAMP(?:\(\))?`)
+	tableOptionsPattern  = regexp.MustCompile(`(?is)\)\s*(?:ENGINE|DEFAULT\s+CHARSET|CHARSET|COLLATE)\b[^)]*$`)
+	enumPattern          = regexp.MustCompile(`(?i)\bENUM\s*\([^)]*\)`)
+
+	insertIgnorePattern  = regexp.MustCompile(`(?i)\bINSERT\s+IGNORE\b`)
+	onDuplicatePattern   = regexp.MustCompile(`(?i)\bON\s+DUPLICATE\s+KEY\s+UPDATE\b`)
+	noOpUpdatePattern    = regexp.MustCompile(`^\s*(\w+)\s*=\s*(\w+)\s*$`)
+	valuesRefPattern     = regexp.MustCompile("(?i)\\bVALUES\\s*\\(\\s*`?(\\w+)`?\\s*\\)")
+	ifPattern            = regexp.MustCompile(`(?i)\bIF\s*\(`)
+	greatestPattern      = regexp.MustCompile(`(?i)\bGREATEST\s*\(`)
+	leastPattern         = regexp.MustCompile(`(?i)\bLEAST\s*\(`)
+	currentTimePattern   = regexp.MustCompile(`(?i)\bCURRENT_TIMESTAMP\b(?:\s*\(\))?`)
+	dateAddPattern       = regexp.MustCompile(`(?i)\bDATE_ADD\s*\(`)
+	dateSubPattern       = regexp.MustCompile(`(?i)\bDATE_SUB\s*\(`)
+	intervalArgPattern   = regexp.MustCompile("(?i),\\s*INTERVAL\\s+(\\?|-?\\d+|[\\w.`]+)\\s+(\\w+)\\s*\\)")
+	nowIntervalPattern   = regexp.MustCompile(`(?i)\bNOW\(\)\s*([+-])\s*INTERVAL\s+(\?|\d+)\s+(\w+)`)
+	timestampDiffPattern = regexp.MustCompile(`(?i)\bTIMESTAMPDIFF\s*\(\s*(\w+)\s*,`)
+	positionPattern      = regexp.MustCompile("(?i)\\bposition\\s*\\(\\s*([\\w.`']+)\\s+in\\s+([\\w.`']+)\\s*\\)")
+	forUpdatePattern     = regexp.MustCompile(`(?i)\s+FOR\s+UPDATE\s*$`)
+	updateLimitPattern   = regexp.MustCompile(`(?is)^\s*UPDATE\s+(\w+)\s+SET\s+(.*?)\s+WHERE\s+(.*?)(\s+ORDER\s+BY\s+.*?)?\s+LIMIT\s+(\?|\d+)\s*$`)
+	deleteLimitPattern   = regexp.MustCompile(`(?is)^\s*DELETE\s+FROM\s+(\w+)\s+WHERE\s+(.*?)(\s+ORDER\s+BY\s+.*?)?\s+LIMIT\s+(\?|\d+)\s*$`)
+)
+
+// Translate rewrites a MySQL statement into SQLite. It covers the constructs
+// the application uses rather than the whole dialect.
+func Translate(query string) string {
+	if createTablePattern.MatchString(query) {
+		return translateCreateTable(query)
+	}
+
+	q := insertIgnorePattern.ReplaceAllString(query, "INSERT OR IGNORE")
+	if loc := onDuplicatePattern.FindStringIndex(q); loc != nil {
+		// MySQL reports no affected rows for an update that changes nothing,
+		// which callers use to spot a duplicate
+		if m := noOpUpdatePattern.FindStringSubmatch(q[loc[1]:]); m != nil && m[1] == m[2] {
+			q = q[:loc[0]] + "ON CONFLICT DO NOTHING"
+		} else {
+			q = q[:loc[0]] + "ON CONFLICT DO UPDATE SET" + valuesRefPattern.ReplaceAllString(q[loc[1]:], "excluded.$1")
+		}
+	}
+	q = ifPattern.ReplaceAllString(q, "IIF(")
+	q = greatestPattern.ReplaceAllString(q, "MAX(")
+	q = leastPattern.ReplaceAllString(q, "MIN(")
+	q = currentTimePattern.ReplaceAllString(q, "NOW()")
+	q = nowIntervalPattern.ReplaceAllString(q, "date_add_mysql(NOW(), ${1}($2), '$3')")
+	q = dateAddPattern.ReplaceAllString(q, "date_add_mysql(")
+	q = dateSubPattern.ReplaceAllString(q, "date_sub_mysql(")
+	q = intervalArgPattern.ReplaceAllString(q, ", $1, '$2')")
+	q = timestampDiffPattern.ReplaceAllString(q, "TIMESTAMPDIFF('$1',")
+	q = positionPattern.ReplaceAllString(q, "instr($2, $1)")
+	q = forUpdatePattern.ReplaceAllString(q, "")
+
+	// SQLite has no ORDER BY/LIMIT on UPDATE and DELETE; select the rows by rowid instead
+	if m := updateLimitPattern.FindStringSubmatch(q); m != nil {
+		q = fmt.Sprintf("UPDATE %s SET %s WHERE rowid IN (SELECT rowid FROM %s WHERE %s%s LIMIT %s)", m[1], m[2], m[1], m[3], m[4], m[5])
+	} else if m := deleteLimitPattern.FindStringSubmatch(q); m != nil {
+		q = fmt.Sprintf("DELETE FROM %s WHERE rowid IN (SELECT rowid FROM %s WHERE %s%s LIMIT %s)", m[1], m[1], m[2], m[3], m[4])
+	}
+	return q
+}
+
+func translateCreateTable(query string) string {
+	q := autoIncrementPattern.ReplaceAllString(query, "INTEGER PRIMARY KEY AUTOINCREMENT")
+	q = uniqueKeyPattern.ReplaceAllString(q, "UNIQUE (")
+	q = indexPattern.ReplaceAllString(q, "")
+	q = onUpdatePattern.ReplaceAllString(q, "")
+	q = enumPattern.ReplaceAllString(q, "TEXT")
+	q = tableOptionsPattern.ReplaceAllString(q, ")")
+	return q
+}
+
+// dialectDriver translates statements before handing them to SQLite
+type dialectDriver struct {
+	inner *sqlite3.SQLiteDriver
+}
+
+func (d *dialectDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.inner.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &dialectConn{conn.(*sqlite3.SQLiteConn)}, nil
+}
+
+type dialectConn struct {
+	inner *sqlite3.SQLiteConn
+}
+
+func (c *dialectConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *dialectConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	stmt, err := c.inner.PrepareContext(ctx, Translate(query))
+	if err != nil {
+		return nil, err
+	}
+	return &dialectStmt{stmt.(*sqlite3.SQLiteStmt)}, nil
+}
+
+func (c *dialectConn) Close() error { return c.inner.Close() }
+
+func (c *dialectConn) Begin() (driver.Tx, error) {
+	return c.inner.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *dialectConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	return c.inner.BeginTx(ctx, opts)
+}
+
+func (c *dialectConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return c.inner.ExecContext(ctx, Translate(query), convertArgs(args))
+}
+
+func (c *dialectConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	rows, err := c.inner.QueryContext(ctx, Translate(query), convertArgs(args))
+	if err != nil {
+		return nil, err
+	}
+	return &dialectRows{rows}, nil
+}
+
+type dialectStmt struct {
+	inner *sqlite3.SQLiteStmt
+}
+
+func (s *dialectStmt) Close() error  { return s.inner.Close() }
+func (s *dialectStmt) NumInput() int { return s.inner.NumInput() }
+
+func (s *dialectStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), namedValues(args))
+}
+
+func (s *dialectStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), namedValues(args))
+}
+
+func (s *dialectStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return s.inner.ExecContext(ctx, convertArgs(args))
+}
+
+func (s *dialectStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	rows, err := s.inner.QueryContext(ctx, convertArgs(args))
+	if err != nil {
+		return nil, err
+	}
+	return &dialectRows{rows}, nil
+}
+
+// dialectRows returns DATETIME-shaped text as time.Time, as the MySQL driver
+// does with parseTime, so computed columns scan into time fields
+type dialectRows struct {
+	driver.Rows
+}
+
+var datetimeValue = regexp.MustCompile(`^\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}$`)
+
+func (r *dialectRows) Next(dest []driver.Value) error {
+	if err := r.Rows.Next(dest); err != nil {
+		return err
+	}
+	for i, v := range dest {
+		if s, ok := v.(string); ok && datetimeValue.MatchString(s) {
+			if t, err := time.Parse(timeLayout, s); err == nil {
+				dest[i] = t
+			}
+		}
+	}
+	return nil
+}
+
+// convertArgs stores times in UTC in the same layout NOW() produces
+func convertArgs(args []driver.NamedValue) []driver.NamedValue {
+	converted := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		if t, ok := arg.Value.(time.Time); ok {
+			arg.Value = t.UTC().Format(timeLayout)
+		}
+		converted[i] = arg
+	}
+	return converted
+}
+
+func namedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, v := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+	return named
+}
@@ -0,0 +1,57 @@
+package simulation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranslate(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "insert ignore",
+			query: "INSERT IGNORE INTO leads (id) VALUES (?)",
+			want:  "INSERT OR IGNORE INTO leads (id) VALUES (?)",
+		},
+		{
+			name:  "upsert keeps insert values",
+			query: "INSERT INTO t (a, b) VALUES (?, ?) ON DUPLICATE KEY UPDATE b = IF(b > 0, b, VALUES(b))",
+			want:  "INSERT INTO t (a, b) VALUES (?, ?) ON CONFLICT DO UPDATE SET b = IIF(b > 0, b, excluded.b)",
+		},
+		{
+			name:  "upsert that changes nothing",
+			query: "INSERT INTO t (id, k) VALUES (?, ?) ON DUPLICATE KEY UPDATE id = id",
+			want:  "INSERT INTO t (id, k) VALUES (?, ?) ON CONFLICT DO NOTHING",
+		},
+		{
+			name:  "interval arithmetic",
+			query: "SELECT * FROM t WHERE at <= NOW() + INTERVAL 8 HOUR AND due < DATE_ADD(NOW(), INTERVAL ? SECOND)",
+			want:  "SELECT * FROM t WHERE at <= date_add_mysql(NOW(), +(8), 'HOUR') AND due < date_add_mysql(NOW(), ?, 'SECOND')",
+		},
+		{
+			name:  "position",
+			query: "SELECT 1 FROM leads l WHERE position(ss.trigger in l.trigger) > 0",
+			want:  "SELECT 1 FROM leads l WHERE instr(l.trigger, ss.trigger) > 0",
+		},
+		{
+			name:  "update with order and limit",
+			query: "UPDATE broadcast_messages SET status = 'processing' WHERE status = 'pending' ORDER BY scheduled_at LIMIT ?",
+			want:  "UPDATE broadcast_messages SET status = 'processing' WHERE rowid IN (SELECT rowid FROM broadcast_messages WHERE status = 'pending' ORDER BY scheduled_at LIMIT ?)",
+		},
+		{
+			name:  "create table",
+			query: "CREATE TABLE x (id INT AUTO_INCREMENT PRIMARY KEY, kind ENUM('a','b'), updated_at DATETIME ON UPDATE CURRENT_TIMESTAMP, INDEX idx_kind (kind)) ENGINE=InnoDB",
+			want:  "CREATE TABLE x (id INTEGER PRIMARY KEY AUTOINCREMENT, kind TEXT, updated_at DATETIME)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Translate(tt.query))
+		})
+	}
+}
@@ -0,0 +1,272 @@
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	domainBroadcast "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/broadcast"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/clock"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+	"google.golang.org/protobuf/proto"
+)
+
+// Send is one delivery attempt seen by the fake transport
+type Send struct {
+	Device      string
+	Phone       string
+	Type        string
+	Content     string
+	BroadcastID string
+	MessageID   string
+	At          time.Time
+	Err         error
+}
+
+// Receipt is a delivery or read receipt emitted by the fake transport
+type Receipt struct {
+	MessageID string
+	Phone     string
+	Type      types.ReceiptType
+	At        time.Time
+}
+
+type failureRule struct {
+	phone  string
+	device string
+	code   pkgError.SendErrorCode
+	times  int
+}
+
+type pendingReply struct {
+	phone string
+	text  string
+	after time.Duration
+}
+
+// Transport stands in for WhatsApp. It records every send, fails the ones
+// matching a failure rule, and plays receipts and replies back through the
+// regular event handler on the fake clock.
+type Transport struct {
+	mu        sync.Mutex
+	seq       int
+	attempts  []Send
+	receipts  []Receipt
+	failures  []*failureRule
+	replies   []pendingReply
+	delivered time.Duration
+	read      time.Duration
+	closed    bool
+
+	// onReply is called as a reply is delivered so the harness knows to
+	// wait for its webhook
+	onReply func()
+}
+
+// NewTransport returns a transport that accepts every message
+func NewTransport() *Transport {
+	return &Transport{}
+}
+
+// SendMessage implements broadcast.MessageSender
+func (t *Transport) SendMessage(deviceID string, msg *domainBroadcast.BroadcastMessage) error {
+	t.mu.Lock()
+	send := Send{
+		Device:      deviceID,
+		Phone:       msg.RecipientPhone,
+		Type:        msg.Type,
+		Content:     msg.Content,
+		BroadcastID: msg.ID,
+		At:          clock.Now(),
+	}
+	if send.Type == "" {
+		send.Type = "text"
+	}
+	if rule := t.matchFailure(deviceID, msg.RecipientPhone); rule != nil {
+		send.Err = pkgError.NewSendError(rule.code, nil, "simulated %s sending to %s", rule.code, msg.RecipientPhone)
+		t.attempts = append(t.attempts, send)
+		t.mu.Unlock()
+		return send.Err
+	}
+
+	// Like WhatsApp, the transport sends with the recorded ID when there is one
+	t.seq++
+	send.MessageID = msg.WhatsAppMessageID
+	if send.MessageID == "" {
+		send.MessageID = fmt.Sprintf("SIM-%d", t.seq)
+	}
+	t.attempts = append(t.attempts, send)
+	delivered, read, closed := t.delivered, t.read, t.closed
+	var reply *pendingReply
+	for i := range t.replies {
+		if t.replies[i].phone == msg.RecipientPhone {
+			r := t.replies[i]
+			reply = &r
+			t.replies = append(t.replies[:i], t.replies[i+1:]...)
+			break
+		}
+	}
+	t.mu.Unlock()
+
+	// Record the WhatsApp message ID as the real sender does, so receipts
+	// find their broadcast message
+	if err := repository.GetBroadcastRepository().SaveWhatsAppMessageID(msg.ID, send.MessageID); err != nil {
+		return err
+	}
+
+	// Timers are armed outside the lock: a fake clock runs due callbacks on
+	// the arming goroutine
+	if closed {
+		return nil
+	}
+	if delivered > 0 {
+		t.scheduleReceipt(send, types.ReceiptTypeDelivered, delivered)
+	}
+	if read > 0 {
+		t.scheduleReceipt(send, types.ReceiptTypeRead, read)
+	}
+	if reply != nil {
+		t.scheduleReply(*reply)
+	}
+	return nil
+}
+
+func (t *Transport) matchFailure(device, phone string) *failureRule {
+	for i, rule := range t.failures {
+		if rule.phone != "" && rule.phone != phone {
+			continue
+		}
+		if rule.device != "" && rule.device != device {
+			continue
+		}
+		rule.times--
+		if rule.times <= 0 {
+			t.failures = append(t.failures[:i], t.failures[i+1:]...)
+		}
+		return rule
+	}
+	return nil
+}
+
+func (t *Transport) scheduleReceipt(send Send, receiptType types.ReceiptType, after time.Duration) {
+	clock.AfterFunc(after, func() {
+		t.mu.Lock()
+		if t.closed {
+			t.mu.Unlock()
+			return
+		}
+		receipt := Receipt{MessageID: send.MessageID, Phone: send.Phone, Type: receiptType, At: clock.Now()}
+		t.receipts = append(t.receipts, receipt)
+		t.mu.Unlock()
+
+		sender := types.NewJID(send.Phone, types.DefaultUserServer)
+		whatsapp.HandleEvent(context.Background(), &events.Receipt{
+			MessageSource: types.MessageSource{Chat: sender, Sender: sender},
+			MessageIDs:    []types.MessageID{send.MessageID},
+			Timestamp:     receipt.At,
+			Type:          receiptType,
+		})
+	})
+}
+
+func (t *Transport) scheduleReply(reply pendingReply) {
+	clock.AfterFunc(reply.after, func() {
+		t.mu.Lock()
+		closed := t.closed
+		t.seq++
+		id := fmt.Sprintf("SIM-IN-%d", t.seq)
+		t.mu.Unlock()
+		if closed {
+			return
+		}
+		if t.onReply != nil {
+			t.onReply()
+		}
+
+		sender := types.NewJID(reply.phone, types.DefaultUserServer)
+		whatsapp.HandleEvent(context.Background(), &events.Message{
+			Info: types.MessageInfo{
+				MessageSource: types.MessageSource{Chat: sender, Sender: sender},
+				ID:            id,
+				Timestamp:     clock.Now(),
+			},
+			Message: &waE2E.Message{Conversation: proto.String(reply.text)},
+		})
+	})
+}
+
+// FailPhone makes the next times sends to phone fail with code
+func (t *Transport) FailPhone(phone string, code pkgError.SendErrorCode, times int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.failures = append(t.failures, &failureRule{phone: phone, code: code, times: times})
+}
+
+// FailDevice makes the next times sends from device fail with code
+func (t *Transport) FailDevice(device string, code pkgError.SendErrorCode, times int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.failures = append(t.failures, &failureRule{device: device, code: code, times: times})
+}
+
+// FailNext makes the next times sends fail with code whatever their recipient
+func (t *Transport) FailNext(code pkgError.SendErrorCode, times int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.failures = append(t.failures, &failureRule{code: code, times: times})
+}
+
+// Receipts makes every later send emit a delivery receipt after delivered
+// and a read receipt after read; zero leaves that receipt out
+func (t *Transport) Receipts(delivered, read time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.delivered = delivered
+	t.read = read
+}
+
+// ReplyFrom makes phone answer with text after the next message sent to it
+func (t *Transport) ReplyFrom(phone, text string, after time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.replies = append(t.replies, pendingReply{phone: phone, text: text, after: after})
+}
+
+// Sent returns the successful sends in the order they happened
+func (t *Transport) Sent() []Send {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var sent []Send
+	for _, send := range t.attempts {
+		if send.Err == nil {
+			sent = append(sent, send)
+		}
+	}
+	return sent
+}
+
+// Attempts returns every send, failed ones included
+func (t *Transport) Attempts() []Send {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Send(nil), t.attempts...)
+}
+
+// ReceiptsSent returns the receipts emitted so far
+func (t *Transport) ReceiptsSent() []Receipt {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Receipt(nil), t.receipts...)
+}
+
+func (t *Transport) close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closed = true
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/models"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/usecase"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/listing"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/ui/websocket"
 	"github.com/go-redis/redis/v8"
@@ -316,10 +317,7 @@ func (handler *App) Devices(c *fiber.Ctx) error {
 
 // LinkDevicePhone links a phone number to a device for the current user
 func (handler *App) LinkDevicePhone(c *fiber.Ctx) error {
-	var req struct {
-		DeviceID string `json:"device_id"`
-		Phone    string `json:"phone"`
-	}
+	var req linkDeviceRequest
 	
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(400).JSON(fiber.Map{
@@ -380,10 +378,7 @@ func (handler *App) DashboardView(c *fiber.Ctx) error {
 
 // HandleLogin processes login requests
 func (handler *App) HandleLogin(c *fiber.Ctx) error {
-	var loginReq struct {
-		Email    string `json:"email"`
-		Password string `json:"password"`
-	}
+	var loginReq loginRequest
 	
 	if err := c.BodyParser(&loginReq); err != nil {
 		log.Printf("Failed to parse login request: %v", err)
@@ -444,11 +439,7 @@ func (handler *App) HandleLogin(c *fiber.Ctx) error {
 }
 // HandleRegister processes registration requests
 func (handler *App) HandleRegister(c *fiber.Ctx) error {
-	var registerReq struct {
-		FullName string `json:"fullname"`
-		Email    string `json:"email"`
-		Password string `json:"password"`
-	}
+	var registerReq registerRequest
 	
 	if err := c.BodyParser(&registerReq); err != nil {
 		return c.Status(400).JSON(fiber.Map{
@@ -697,8 +688,17 @@ func (handler *App) GetDeviceLeads(c *fiber.Ctx) error {
 		})
 	}
 	
+	q, err := listing.Parse(c.Queries(), repository.LeadListFields)
+	if err != nil {
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: err.Error(),
+		})
+	}
+	
 	leadRepo := repository.GetLeadRepository()
-	leads, err := leadRepo.GetLeadsByDevice(session.UserID, deviceId)
+	leads, page, err := leadRepo.ListLeadsByDevice(session.UserID, deviceId, q)
 	if err != nil {
 		log.Printf("Error getting leads: %v", err)
 		// Return empty array instead of error
@@ -707,9 +707,23 @@ func (handler *App) GetDeviceLeads(c *fiber.Ctx) error {
 			Code:    "SUCCESS",
 			Message: "Leads retrieved successfully",
 			Results: []interface{}{},
+			Page:    &utils.Page{Limit: q.Limit},
 		})
 	}
 	
+	// Show what WhatsApp said about each checked number
+	phones := make([]string, 0, len(leads))
+	for _, lead := range leads {
+		phones = append(phones, lead.Phone)
+	}
+	if validations, err := repository.GetNumberValidationRepository().Get(phones); err == nil {
+		for i := range leads {
+			if v, ok := validations[leads[i].Phone]; ok {
+				leads[i].NumberValidation = &v
+			}
+		}
+	}
+	
 	// If include_broadcast_history is true, add broadcast history for each lead
 	if includeBroadcastHistory {
 		db := database.GetDB()
@@ -858,6 +872,7 @@ func (handler *App) GetDeviceLeads(c *fiber.Ctx) error {
 		Code:    "SUCCESS",
 		Message: "Leads retrieved successfully",
 		Results: leads,
+		Page:    &page,
 	})
 }
 
@@ -884,15 +899,7 @@ func (handler *App) CreateLead(c *fiber.Ctx) error {
 		})
 	}
 	
-	var request struct {
-		DeviceID     string `json:"device_id"`
-		Name         string `json:"name"`
-		Phone        string `json:"phone"`
-		Niche        string `json:"niche"`
-		Journey      string `json:"journey"`
-		TargetStatus string `json:"target_status"` // Changed from Status to TargetStatus
-		Trigger      string `json:"trigger"`
-	}
+	var request leadRequest
 	
 	if err := c.BodyParser(&request); err != nil {
 		return c.Status(400).JSON(utils.ResponseData{
@@ -902,6 +909,15 @@ func (handler *App) CreateLead(c *fiber.Ctx) error {
 		})
 	}
 	
+	consent, err := request.consent(models.ConsentChannelManual, "dashboard")
+	if err != nil {
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: err.Error(),
+		})
+	}
+	
 	leadRepo := repository.GetLeadRepository()
 	lead := &models.Lead{
 		UserID:       session.UserID,
@@ -915,6 +931,7 @@ func (handler *App) CreateLead(c *fiber.Ctx) error {
 		TargetStatus: request.TargetStatus, // Use TargetStatus directly
 		Trigger:      request.Trigger,
 		Notes:        request.Journey, // Map journey to notes field
+		Consent:      consent,
 	}
 	err = leadRepo.CreateLead(lead)
 	if err != nil {
@@ -957,15 +974,7 @@ func (handler *App) UpdateLead(c *fiber.Ctx) error {
 		})
 	}
 	
-	var request struct {
-		DeviceID     string `json:"device_id"`
-		Name         string `json:"name"`
-		Phone        string `json:"phone"`
-		Niche        string `json:"niche"`
-		Journey      string `json:"journey"`
-		TargetStatus string `json:"target_status"` // Changed from status to target_status
-		Trigger      string `json:"trigger"`
-	}
+	var request leadRequest
 	
 	if err := c.BodyParser(&request); err != nil {
 		return c.Status(400).JSON(utils.ResponseData{
@@ -1100,8 +1109,17 @@ func (handler *App) GetCampaigns(c *fiber.Ctx) error {
 		})
 	}
 	
+	q, err := listing.Parse(c.Queries(), repository.CampaignListFields)
+	if err != nil {
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: err.Error(),
+		})
+	}
+	
 	campaignRepo := repository.GetCampaignRepository()
-	campaigns, err := campaignRepo.GetCampaigns(user.ID)
+	campaigns, page, err := campaignRepo.ListCampaigns(user.ID, q)
 	if err != nil {
 		log.Printf("Error getting campaigns: %v", err)
 		// Return empty array instead of error
@@ -1110,20 +1128,17 @@ func (handler *App) GetCampaigns(c *fiber.Ctx) error {
 			Code:    "SUCCESS",
 			Message: "Campaigns retrieved successfully",
 			Results: []interface{}{},
+			Page:    &utils.Page{Limit: q.Limit},
 		})
 	}
 	
-	// Ensure campaigns is not nil
-	if campaigns == nil {
-		campaigns = []models.Campaign{}
-	}
-	
 	// Return campaigns as array for frontend
 	return c.JSON(utils.ResponseData{
 		Status:  200,
 		Code:    "SUCCESS",
 		Message: "Campaigns retrieved successfully",
 		Results: campaigns,
+		Page:    &page,
 	})
 }
 
@@ -1158,19 +1173,7 @@ func (handler *App) CreateCampaign(c *fiber.Ctx) error {
 		})
 	}
 	
-	var request struct {
-		CampaignDate    string  `json:"campaign_date"`
-		Title           string  `json:"title"`
-		Niche           string  `json:"niche"`
-		TargetStatus    string  `json:"target_status"`
-		Message         string  `json:"message"`
-		ImageURL        string  `json:"image_url"`
-		TimeSchedule    string  `json:"time_schedule"`
-		MinDelaySeconds int     `json:"min_delay_seconds"`
-		MaxDelaySeconds int     `json:"max_delay_seconds"`
-		AI              *string `json:"ai"`    // New field for AI campaigns
-		Limit           int     `json:"limit"` // New field for device limit
-	}
+	var request createCampaignRequest
 	
 	if err := c.BodyParser(&request); err != nil {
 		return c.Status(400).JSON(utils.ResponseData{
@@ -1189,6 +1192,25 @@ func (handler *App) CreateCampaign(c *fiber.Ctx) error {
 		})
 	}
 	
+	// Video, document, audio, location, poll and contact campaigns need their specific fields
+	messageType := domainBroadcast.ResolveMessageType(request.MessageType, request.ImageURL)
+	if err := domainBroadcast.ValidateMessage(messageType, request.Message, request.ImageURL, request.Payload); err != nil {
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: err.Error(),
+		})
+	}
+	
+	// Group campaigns post to groups of the user's devices instead of leads
+	if err := validateGroupTargets(user.ID, request.TargetGroups); err != nil {
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: err.Error(),
+		})
+	}
+	
 	// Validate and set target_status
 	targetStatus := request.TargetStatus
 	if targetStatus != "prospect" && targetStatus != "customer" && targetStatus != "all" {
@@ -1212,6 +1234,8 @@ func (handler *App) CreateCampaign(c *fiber.Ctx) error {
 		Niche:           request.Niche,
 		TargetStatus:    targetStatus,
 		ImageURL:        request.ImageURL,
+		MessageType:     messageType,
+		Payload:         request.Payload,
 		CampaignDate:    request.CampaignDate,
 		TimeSchedule:    request.TimeSchedule,
 		MinDelaySeconds: request.MinDelaySeconds,
@@ -1219,6 +1243,7 @@ func (handler *App) CreateCampaign(c *fiber.Ctx) error {
 		Status:          "pending",
 		AI:              request.AI,
 		Limit:           request.Limit,
+		TargetGroups:    request.TargetGroups,
 		CreatedAt:       time.Now(),
 		UpdatedAt:       time.Now(),
 	}
@@ -1232,10 +1257,19 @@ func (handler *App) CreateCampaign(c *fiber.Ctx) error {
 		})
 	}
 	
+	// Warn (but don't block) when the campaign pushes a device over its daily capacity
+	message := "Campaign created successfully"
+	warnings, err := usecase.NewBroadcastPlanner().CheckCampaign(user.ID, campaign.ID, campaign.CampaignDate)
+	if err != nil {
+		logrus.Warnf("Planner capacity check failed for campaign %d: %v", campaign.ID, err)
+	} else if len(warnings) > 0 {
+		message = fmt.Sprintf("Campaign created with capacity warnings: %s", strings.Join(warnings, "; "))
+	}
+	
 	return c.JSON(utils.ResponseData{
 		Status:  201,
 		Code:    "SUCCESS",
-		Message: "Campaign created successfully",
+		Message: message,
 		Results: campaign,
 	})
 }
@@ -1281,17 +1315,7 @@ func (handler *App) UpdateCampaign(c *fiber.Ctx) error {
 		})
 	}
 	
-	var request struct {
-		Title           string `json:"title"`
-		Niche           string `json:"niche"`
-		Message         string `json:"message"`
-		ImageURL        string `json:"image_url"`
-		TimeSchedule    string `json:"time_schedule"`
-		CampaignDate    string `json:"campaign_date"`
-		MinDelaySeconds int    `json:"min_delay_seconds"`
-		MaxDelaySeconds int    `json:"max_delay_seconds"`
-		Status          string `json:"status"`
-	}
+	var request updateCampaignRequest
 	
 	if err := c.BodyParser(&request); err != nil {
 		return c.Status(400).JSON(utils.ResponseData{
@@ -1307,6 +1331,23 @@ func (handler *App) UpdateCampaign(c *fiber.Ctx) error {
 		timeSchedule = request.TimeSchedule
 	}
 	
+	messageType := domainBroadcast.ResolveMessageType(request.MessageType, request.ImageURL)
+	if err := domainBroadcast.ValidateMessage(messageType, request.Message, request.ImageURL, request.Payload); err != nil {
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: err.Error(),
+		})
+	}
+	
+	if err := validateGroupTargets(user.ID, request.TargetGroups); err != nil {
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: err.Error(),
+		})
+	}
+	
 	campaignRepo := repository.GetCampaignRepository()
 	campaign := &models.Campaign{
 		ID:              campaignId,
@@ -1315,11 +1356,14 @@ func (handler *App) UpdateCampaign(c *fiber.Ctx) error {
 		Message:         request.Message,
 		Niche:           request.Niche,
 		ImageURL:        request.ImageURL,
+		MessageType:     messageType,
+		Payload:         request.Payload,
 		CampaignDate:    request.CampaignDate,
 		TimeSchedule:    timeSchedule,
 		MinDelaySeconds: request.MinDelaySeconds,
 		MaxDelaySeconds: request.MaxDelaySeconds,
 		Status:          request.Status,
+		TargetGroups:    request.TargetGroups,
 	}
 	err = campaignRepo.UpdateCampaign(campaign)
 	if err != nil {
@@ -1976,6 +2020,8 @@ func (handler *App) GetCampaignSummary(c *fiber.Ctx) error {
 	processingCampaigns := 0
 	sentCampaigns := 0
 	failedCampaigns := 0
+	pausedCampaigns := 0
+	cancelledCampaigns := 0
 	
 	for _, campaign := range campaigns {
 		switch campaign.Status {
@@ -1989,17 +2035,22 @@ func (handler *App) GetCampaignSummary(c *fiber.Ctx) error {
 			sentCampaigns++
 		case "failed":
 			failedCampaigns++
+		case "paused":
+			pausedCampaigns++
+		case "cancelled":
+			cancelledCampaigns++
 		}
 	}
 	
-	log.Printf("Campaign Status Breakdown - Total: %d, Pending: %d, Triggered: %d, Processing: %d, Sent: %d, Failed: %d",
-		totalCampaigns, pendingCampaigns, triggeredCampaigns, processingCampaigns, sentCampaigns, failedCampaigns)
+	log.Printf("Campaign Status Breakdown - Total: %d, Pending: %d, Triggered: %d, Processing: %d, Sent: %d, Failed: %d, Paused: %d, Cancelled: %d",
+		totalCampaigns, pendingCampaigns, triggeredCampaigns, processingCampaigns, sentCampaigns, failedCampaigns, pausedCampaigns, cancelledCampaigns)
 	
 	// Initialize totals based on broadcast_messages data
 	totalShouldSend := 0
 	totalDoneSend := 0
 	totalFailedSend := 0
 	totalPendingSend := 0
+	totalCancelledSend := 0
 	
 	// Get statistics FROM broadcast_messages table for filtered campaigns
 	mysqlURI := os.Getenv("MYSQL_URI")
@@ -2060,19 +2111,20 @@ func (handler *App) GetCampaignSummary(c *fiber.Ctx) error {
 				SELECT 
 					COUNT(CASE WHEN status = 'sent' AND (error_message IS NULL OR error_message = '') THEN 1 END) as done_send,
 					COUNT(CASE WHEN status = 'failed' THEN 1 END) as failed,
-					COUNT(CASE WHEN status IN ('pending', 'queued') THEN 1 END) as remaining
+					COUNT(CASE WHEN status IN ('pending', 'queued') THEN 1 END) as remaining,
+					COUNT(CASE WHEN status = 'cancelled' THEN 1 END) as cancelled
 				FROM broadcast_messages
 				WHERE campaign_id IN (%s)
 			`, strings.Join(placeholders, ","))
 			
-			err := db.QueryRow(query, args...).Scan(&totalDoneSend, &totalFailedSend, &totalPendingSend)
+			err := db.QueryRow(query, args...).Scan(&totalDoneSend, &totalFailedSend, &totalPendingSend, &totalCancelledSend)
 			if err != nil {
 				log.Printf("Error getting campaign broadcast stats: %v", err)
 			}
 		}
 	}
 	
-	totalRemainingSend := totalShouldSend - totalDoneSend - totalFailedSend
+	totalRemainingSend := totalShouldSend - totalDoneSend - totalFailedSend - totalCancelledSend
 	if totalRemainingSend < 0 {
 		totalRemainingSend = 0
 	}
@@ -2102,24 +2154,25 @@ func (handler *App) GetCampaignSummary(c *fiber.Ctx) error {
 			}
 			
 			// Get broadcast message stats
-			var doneSend, failedSend, pendingSend int
+			var doneSend, failedSend, pendingSend, cancelledSend int
 			if db != nil {
 				err := db.QueryRow(`
 					SELECT 
 						COUNT(CASE WHEN status = 'sent' AND (error_message IS NULL OR error_message = '') THEN 1 END) as done_send,
 						COUNT(CASE WHEN status = 'failed' THEN 1 END) as failed,
-						COUNT(CASE WHEN status IN ('pending', 'queued') THEN 1 END) as remaining
+						COUNT(CASE WHEN status IN ('pending', 'queued') THEN 1 END) as remaining,
+						COUNT(CASE WHEN status = 'cancelled' THEN 1 END) as cancelled
 					FROM broadcast_messages
 					WHERE campaign_id = ?
-				`, campaign.ID).Scan(&doneSend, &failedSend, &pendingSend)
+				`, campaign.ID).Scan(&doneSend, &failedSend, &pendingSend, &cancelledSend)
 				
 				if err != nil {
-					doneSend, failedSend, pendingSend = 0, 0, 0
+					doneSend, failedSend, pendingSend, cancelledSend = 0, 0, 0, 0
 				}
 			}
 			
 			// Calculate remaining based on leads that haven't been sent to
-			remainingSend := leadCount - doneSend - failedSend
+			remainingSend := leadCount - doneSend - failedSend - cancelledSend
 			if remainingSend < 0 {
 				remainingSend = 0
 			}
@@ -2138,6 +2191,9 @@ func (handler *App) GetCampaignSummary(c *fiber.Ctx) error {
 				"done_send":        doneSend,       // FROM broadcast_messages
 				"failed_send":      failedSend,     // FROM broadcast_messages
 				"remaining_send":   remainingSend,  // Calculated
+				"cancelled_send":   cancelledSend,  // FROM broadcast_messages
+				"min_delay_seconds": campaign.MinDelaySeconds,
+				"max_delay_seconds": campaign.MaxDelaySeconds,
 			}
 			
 			recentCampaigns = append(recentCampaigns, campaignData)
@@ -2152,12 +2208,15 @@ func (handler *App) GetCampaignSummary(c *fiber.Ctx) error {
 			"processing": processingCampaigns,
 			"sent": sentCampaigns,
 			"failed": failedCampaigns,
+			"paused": pausedCampaigns,
+			"cancelled": cancelledCampaigns,
 		},
 		"broadcast_stats": map[string]interface{}{
 			"total_should_send":    totalShouldSend,
 			"total_done_send":      totalDoneSend,
 			"total_failed_send":    totalFailedSend,
 			"total_remaining_send": totalRemainingSend,
+			"total_cancelled_send": totalCancelledSend,
 		},
 		"recent_campaigns": recentCampaigns,
 	}
@@ -2902,6 +2961,9 @@ func (handler *App) ImportLeads(c *fiber.Ctx) error {
 	targetStatusIndex := -1
 	statusIndex := -1
 	triggerIndex := -1
+	consentSourceIndex := -1
+	consentProofIndex := -1
+	consentAtIndex := -1
 	
 	for i, h := range headers {
 		switch h {
@@ -2917,6 +2979,12 @@ func (handler *App) ImportLeads(c *fiber.Ctx) error {
 			statusIndex = i
 		case "trigger":
 			triggerIndex = i
+		case "consent_source":
+			consentSourceIndex = i
+		case "consent_proof":
+			consentProofIndex = i
+		case "consent_at":
+			consentAtIndex = i
 		}
 	}
 	
@@ -2970,6 +3038,18 @@ func (handler *App) ImportLeads(c *fiber.Ctx) error {
 			targetStatus = "prospect"
 		}
 		
+		// Consent columns are optional; the file is the source when none is named
+		consent, err := consentRequest{
+			ConsentSource: getValue(consentSourceIndex),
+			ConsentProof:  getValue(consentProofIndex),
+			ConsentAt:     getValue(consentAtIndex),
+		}.consent(models.ConsentChannelImport, "CSV import "+file.Filename)
+		if err != nil {
+			errorCount++
+			log.Printf("Row %d: Skipping - %v", i, err)
+			continue
+		}
+		
 		lead := &models.Lead{
 			UserID:       session.UserID,
 			DeviceID:     deviceId, // Always use the current device ID
@@ -2979,9 +3059,10 @@ func (handler *App) ImportLeads(c *fiber.Ctx) error {
 			TargetStatus: targetStatus,
 			Notes:        "", // No longer importing notes
 			Trigger:      getValue(triggerIndex),
+			Consent:      consent,
 		}
 		
-		err := leadRepo.CreateLead(lead)
+		err = leadRepo.CreateLead(lead)
 		if err != nil {
 			errorCount++
 			log.Printf("Failed to import lead %s: %v", lead.Name, err)
@@ -3783,14 +3864,7 @@ func (handler *App) CreateLeadAI(c *fiber.Ctx) error {
 		})
 	}
 	
-	var request struct {
-		Name         string `json:"name"`
-		Phone        string `json:"phone"`
-		Email        string `json:"email"`
-		Niche        string `json:"niche"`
-		TargetStatus string `json:"target_status"`
-		Notes        string `json:"notes"`
-	}
+	var request leadAIRequest
 	
 	if err := c.BodyParser(&request); err != nil {
 		return c.Status(400).JSON(utils.ResponseData{
@@ -3864,8 +3938,17 @@ func (handler *App) GetLeadsAI(c *fiber.Ctx) error {
 		})
 	}
 	
+	q, err := listing.Parse(c.Queries(), repository.LeadAIListFields)
+	if err != nil {
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: err.Error(),
+		})
+	}
+	
 	leadAIRepo := repository.GetLeadAIRepository()
-	leads, err := leadAIRepo.GetLeadAIByUser(session.UserID)
+	leads, page, err := leadAIRepo.ListLeadAIByUser(session.UserID, q)
 	if err != nil {
 		return c.Status(500).JSON(utils.ResponseData{
 			Status:  500,
@@ -3879,6 +3962,7 @@ func (handler *App) GetLeadsAI(c *fiber.Ctx) error {
 		Code:    "SUCCESS",
 		Message: "AI leads fetched successfully",
 		Results: leads,
+		Page:    &page,
 	})
 }
 // UpdateLeadAI updates an existing AI lead
@@ -3911,14 +3995,7 @@ func (handler *App) UpdateLeadAI(c *fiber.Ctx) error {
 		})
 	}
 	
-	var request struct {
-		Name         string `json:"name"`
-		Phone        string `json:"phone"`
-		Email        string `json:"email"`
-		Niche        string `json:"niche"`
-		TargetStatus string `json:"target_status"`
-		Notes        string `json:"notes"`
-	}
+	var request leadAIRequest
 	if err := c.BodyParser(&request); err != nil {
 		return c.Status(400).JSON(utils.ResponseData{
 			Status:  400,
@@ -4136,14 +4213,18 @@ func (a App) GetAllTeamMembers(c *fiber.Ctx) error {
 	
 	members, err := repo.GetAllWithDeviceCount(ctx)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to get team members",
+		return c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: "Failed to get team members",
 		})
 	}
 	
-	return c.JSON(fiber.Map{
-		"success": true,
-		"data":    members,
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Team members retrieved successfully",
+		Results: members,
 	})
 }
 
@@ -4153,14 +4234,13 @@ func (a App) CreateTeamMember(c *fiber.Ctx) error {
 	db := database.GetDB()
 	repo := repository.NewTeamMemberRepository(db)
 	
-	var req struct {
-		Username string `json:"username"`
-		Password string `json:"password"`
-	}
+	var req createTeamMemberRequest
 	
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: "Invalid request body",
 		})
 	}
 	
@@ -4169,21 +4249,27 @@ func (a App) CreateTeamMember(c *fiber.Ctx) error {
 	req.Password = strings.TrimSpace(req.Password)
 	
 	if req.Username == "" || req.Password == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Username and password are required",
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: "Username and password are required",
 		})
 	}
 	
 	// Check if username already exists
 	existing, err := repo.GetByUsername(ctx, req.Username)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to check existing username",
+		return c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: "Failed to check existing username",
 		})
 	}
 	if existing != nil {
-		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
-			"error": "Username already exists",
+		return c.Status(409).JSON(utils.ResponseData{
+			Status:  409,
+			Code:    "CONFLICT",
+			Message: "Username already exists",
 		})
 	}
 	
@@ -4195,14 +4281,18 @@ func (a App) CreateTeamMember(c *fiber.Ctx) error {
 	}
 	
 	if err := repo.Create(ctx, member); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to create team member",
+		return c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: "Failed to create team member",
 		})
 	}
 	
-	return c.JSON(fiber.Map{
-		"success": true,
-		"data":    member,
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Team member created successfully",
+		Results: member,
 	})
 }
 
@@ -4215,33 +4305,37 @@ func (a App) UpdateTeamMember(c *fiber.Ctx) error {
 	// Get team member ID from params
 	memberID, err := uuid.Parse(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid team member ID",
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: "Invalid team member ID",
 		})
 	}
 	
-	var req struct {
-		Username string `json:"username"`
-		Password string `json:"password"`
-		IsActive bool   `json:"is_active"`
-	}
+	var req updateTeamMemberRequest
 	
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: "Invalid request body",
 		})
 	}
 	
 	// Get existing member
 	member, err := repo.GetByID(ctx, memberID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to get team member",
+		return c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: "Failed to get team member",
 		})
 	}
 	if member == nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Team member not found",
+		return c.Status(404).JSON(utils.ResponseData{
+			Status:  404,
+			Code:    "NOT_FOUND",
+			Message: "Team member not found",
 		})
 	}
 	
@@ -4256,14 +4350,18 @@ func (a App) UpdateTeamMember(c *fiber.Ctx) error {
 	
 	// Save updates
 	if err := repo.Update(ctx, member); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update team member",
+		return c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: "Failed to update team member",
 		})
 	}
 	
-	return c.JSON(fiber.Map{
-		"success": true,
-		"data":    member,
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Team member updated successfully",
+		Results: member,
 	})
 }
 
@@ -4276,21 +4374,26 @@ func (a App) DeleteTeamMember(c *fiber.Ctx) error {
 	// Get team member ID from params
 	memberID, err := uuid.Parse(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid team member ID",
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: "Invalid team member ID",
 		})
 	}
 	
 	// Delete team member
 	if err := repo.Delete(ctx, memberID); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to delete team member",
+		return c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: "Failed to delete team member",
 		})
 	}
 	
-	return c.JSON(fiber.Map{
-		"success": true,
-		"message": "Team member deleted successfully",
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Team member deleted successfully",
 	})
 }
 
@@ -4327,10 +4430,7 @@ func (a App) HandleTeamLogin(c *fiber.Ctx) error {
 	db := database.GetDB()
 	repo := repository.NewTeamMemberRepository(db)
 	
-	var req struct {
-		Username string `json:"username"`
-		Password string `json:"password"`
-	}
+	var req teamLoginRequest
 	
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -4986,7 +5086,7 @@ func (handler *App) GetSequenceDeviceLeads(c *fiber.Ctx) error {
 		leads = append(leads, lead)
 	}
 	
-	log.Printf("GetSequenceDeviceLeads - Found %d leads for sequence %d, device %s, status %s", 
+	log.Printf("GetSequenceDeviceLeads - Found %d leads for sequence %s, device %s, status %s", 
 		len(leads), sequenceId, deviceId, status)
 	
 	return c.JSON(utils.ResponseData{
@@ -5126,7 +5226,7 @@ func (handler *App) GetSequenceStepLeads(c *fiber.Ctx) error {
 		leads = append(leads, lead)
 	}
 	
-	log.Printf("GetSequenceStepLeads - Found %d leads for sequence %d, device %s, step %s, status %s", 
+	log.Printf("GetSequenceStepLeads - Found %d leads for sequence %s, device %s, step %s, status %s", 
 		len(leads), sequenceId, deviceId, stepId, status)
 	
 	return c.JSON(utils.ResponseData{
@@ -5226,9 +5326,7 @@ func (handler *App) UpdateDeviceJID(c *fiber.Ctx) error {
 	}
 	
 	// Parse request body
-	var request struct {
-		JID string `json:"jid"`
-	}
+	var request updateDeviceJIDRequest
 	
 	if err := c.BodyParser(&request); err != nil {
 		return c.Status(400).JSON(utils.ResponseData{
@@ -5282,10 +5380,7 @@ func (handler *App) ResendSequenceStepAllDevices(c *fiber.Ctx) error {
 	stepId := c.Params("stepId")
 	
 	// Get request body
-	var request struct {
-		ResendFailed    bool `json:"resend_failed"`
-		ResendRemaining bool `json:"resend_remaining"`
-	}
+	var request resendStepRequest
 	
 	if err := c.BodyParser(&request); err != nil {
 		return c.Status(400).JSON(utils.ResponseData{
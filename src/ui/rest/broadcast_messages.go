@@ -0,0 +1,53 @@
+package rest
+
+import (
+	"fmt"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/listing"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+// InitRestBroadcastMessages initializes the broadcast message list endpoint
+func InitRestBroadcastMessages(app *fiber.App) {
+	app.Get("/api/broadcast-messages", ListBroadcastMessages)
+}
+
+// ListBroadcastMessages returns a page of the user's queued and sent campaign and sequence messages
+func ListBroadcastMessages(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(utils.ResponseData{
+			Status:  401,
+			Code:    "UNAUTHORIZED",
+			Message: "Unauthorized",
+		})
+	}
+
+	q, err := listing.Parse(c.Queries(), repository.BroadcastMessageListFields)
+	if err != nil {
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: err.Error(),
+		})
+	}
+
+	messages, page, err := repository.GetBroadcastRepository().ListMessages(userID, q)
+	if err != nil {
+		return c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: fmt.Sprintf("Failed to list broadcast messages: %v", err),
+		})
+	}
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Broadcast messages retrieved",
+		Results: messages,
+		Page:    &page,
+	})
+}
@@ -0,0 +1,183 @@
+package rest
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/models"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+// InitRestCampaignControls initializes the campaign lifecycle endpoints
+func InitRestCampaignControls(app *fiber.App) {
+	app.Post("/api/campaigns/:id/pause", PauseCampaign)
+	app.Post("/api/campaigns/:id/resume", ResumeCampaign)
+	app.Post("/api/campaigns/:id/cancel", CancelCampaign)
+	app.Post("/api/campaigns/:id/throttle", ThrottleCampaign)
+	app.Get("/api/campaigns/:id/events", GetCampaignEvents)
+}
+
+type cancelCampaignRequest struct {
+	Reason string `json:"reason"`
+}
+
+type throttleCampaignRequest struct {
+	MinDelaySeconds int `json:"min_delay_seconds"`
+	MaxDelaySeconds int `json:"max_delay_seconds"`
+}
+
+// PauseCampaign stops workers from sending a campaign's remaining messages
+func PauseCampaign(c *fiber.Ctx) error {
+	return controlCampaign(c, "pause", func(id int, userID string) (*models.CampaignEvent, error) {
+		return repository.GetCampaignRepository().PauseCampaign(id, userID)
+	})
+}
+
+// ResumeCampaign lets workers send a paused campaign's messages again
+func ResumeCampaign(c *fiber.Ctx) error {
+	return controlCampaign(c, "resume", func(id int, userID string) (*models.CampaignEvent, error) {
+		return repository.GetCampaignRepository().ResumeCampaign(id, userID)
+	})
+}
+
+// CancelCampaign stops a campaign for good, marking its unsent messages cancelled
+func CancelCampaign(c *fiber.Ctx) error {
+	var request cancelCampaignRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&request); err != nil {
+			return c.Status(400).JSON(utils.ResponseData{
+				Status:  400,
+				Code:    "BAD_REQUEST",
+				Message: err.Error(),
+			})
+		}
+	}
+	if request.Reason == "" {
+		request.Reason = "cancelled by user"
+	}
+	return controlCampaign(c, "cancel", func(id int, userID string) (*models.CampaignEvent, error) {
+		return repository.GetCampaignRepository().CancelCampaign(id, userID, request.Reason)
+	})
+}
+
+// ThrottleCampaign changes the delay between a campaign's remaining sends
+func ThrottleCampaign(c *fiber.Ctx) error {
+	var request throttleCampaignRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: err.Error(),
+		})
+	}
+	if request.MinDelaySeconds < 1 || request.MaxDelaySeconds < request.MinDelaySeconds {
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: "min_delay_seconds must be at least 1 and no more than max_delay_seconds",
+		})
+	}
+	return controlCampaign(c, "throttle", func(id int, userID string) (*models.CampaignEvent, error) {
+		return repository.GetCampaignRepository().ThrottleCampaign(id, userID, request.MinDelaySeconds, request.MaxDelaySeconds)
+	})
+}
+
+// GetCampaignEvents returns the audit trail of a campaign's lifecycle
+func GetCampaignEvents(c *fiber.Ctx) error {
+	id, _, ok, err := ownedCampaignID(c)
+	if !ok {
+		return err
+	}
+
+	events, err := repository.GetCampaignRepository().GetCampaignEvents(id)
+	if err != nil {
+		return c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: fmt.Sprintf("Failed to get campaign events: %v", err),
+		})
+	}
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Campaign events retrieved",
+		Results: events,
+	})
+}
+
+// controlCampaign applies a lifecycle control to a campaign of the logged in
+// user and returns the audited event
+func controlCampaign(c *fiber.Ctx, action string, control func(id int, userID string) (*models.CampaignEvent, error)) error {
+	id, userID, ok, err := ownedCampaignID(c)
+	if !ok {
+		return err
+	}
+
+	event, err := control(id, userID)
+	if errors.Is(err, repository.ErrCampaignTransition) {
+		return c.Status(409).JSON(utils.ResponseData{
+			Status:  409,
+			Code:    "CONFLICT",
+			Message: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: fmt.Sprintf("Failed to %s campaign: %v", action, err),
+		})
+	}
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: fmt.Sprintf("Campaign is %s", event.ToStatus),
+		Results: event,
+	})
+}
+
+// ownedCampaignID reads the campaign ID from the path and checks the
+// campaign belongs to the logged in user. When it does not, ok is false and
+// the error response has been written.
+func ownedCampaignID(c *fiber.Ctx) (id int, userID string, ok bool, err error) {
+	userID, err = getUserID(c)
+	if err != nil {
+		return 0, "", false, c.Status(401).JSON(utils.ResponseData{
+			Status:  401,
+			Code:    "UNAUTHORIZED",
+			Message: "Unauthorized",
+		})
+	}
+
+	id, err = strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return 0, "", false, c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: "Invalid campaign ID",
+		})
+	}
+
+	campaign, err := repository.GetCampaignRepository().GetCampaignByID(id)
+	if err == sql.ErrNoRows || (err == nil && campaign.UserID != userID) {
+		return 0, "", false, c.Status(404).JSON(utils.ResponseData{
+			Status:  404,
+			Code:    "NOT_FOUND",
+			Message: "Campaign not found",
+		})
+	}
+	if err != nil {
+		return 0, "", false, c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: fmt.Sprintf("Failed to get campaign: %v", err),
+		})
+	}
+	return id, userID, true, nil
+}
@@ -0,0 +1,68 @@
+package rest
+
+import (
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/cluster"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/ui/rest/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+// deviceRoutes are the routes served from a device's live WhatsApp client;
+// in cluster mode they run on the replica that owns the device
+var deviceRoutes = []struct {
+	path  string
+	param string
+	query string
+}{
+	{path: "/api/devices/:deviceId/+", param: "deviceId"},
+	{path: "/api/debug/device-client/:deviceId", param: "deviceId"},
+	{path: "/api/workers/device/:deviceId", param: "deviceId"},
+	{path: "/api/workers/:deviceId/start", param: "deviceId"},
+	{path: "/api/workers/:deviceId/restart", param: "deviceId"},
+	{path: "/api/redis/cleanup-device/:deviceId", param: "deviceId"},
+	{path: "/device/:deviceId/whatsapp-web", param: "deviceId"},
+	{path: "/app/login", query: "deviceId"},
+	{path: "/app/logout", query: "deviceId"},
+	{path: "/app/reconnect", query: "deviceId"},
+	{path: "/app/qr", query: "device_id"},
+}
+
+// InitRestCluster initializes the cluster status endpoint and forwards
+// device routes to their owning replica. It must run before the device
+// routes are registered.
+func InitRestCluster(app *fiber.App) {
+	for _, route := range deviceRoutes {
+		app.All(route.path, middleware.ForwardToDeviceOwner(route.param, route.query))
+	}
+	app.Get("/api/cluster", GetClusterStatus)
+}
+
+// GetClusterStatus lists the live replicas and whether this one leads
+func GetClusterStatus(c *fiber.Ctx) error {
+	if _, err := getUserID(c); err != nil {
+		return c.Status(401).JSON(utils.ResponseData{
+			Status:  401,
+			Code:    "UNAUTHORIZED",
+			Message: "Unauthorized",
+		})
+	}
+
+	current := cluster.Current()
+	if current == nil {
+		return c.JSON(utils.ResponseData{
+			Status:  200,
+			Code:    "SUCCESS",
+			Message: "Cluster mode is disabled",
+			Results: fiber.Map{"enabled": false},
+		})
+	}
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Cluster status",
+		Results: fiber.Map{
+			"enabled": true,
+			"cluster": current.Status(),
+		},
+	})
+}
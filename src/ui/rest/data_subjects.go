@@ -0,0 +1,228 @@
+package rest
+
+import (
+	"fmt"
+	"time"
+
+	infraMedia "github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/media"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/models"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+// InitRestDataSubjects initializes the consent and data subject endpoints
+func InitRestDataSubjects(app *fiber.App) {
+	app.Get("/api/leads/:id/consents", GetLeadConsents)
+	app.Post("/api/leads/:id/consents", AddLeadConsent)
+	app.Get("/api/data-subjects/requests", ListDataSubjectRequests)
+	app.Get("/api/data-subjects/:phone/export", ExportDataSubject)
+	app.Delete("/api/data-subjects/:phone", EraseDataSubject)
+}
+
+// dataSubjectRequestsQuery narrows the audit log to one phone
+type dataSubjectRequestsQuery struct {
+	Phone string `query:"phone"`
+}
+
+// consent builds the consent a lead is created with through channel, with
+// fallbackSource as the source when the request names none
+func (r consentRequest) consent(channel, fallbackSource string) (*models.LeadConsent, error) {
+	consent := &models.LeadConsent{Channel: channel, Source: r.ConsentSource, ProofText: r.ConsentProof}
+	if consent.Source == "" {
+		consent.Source = fallbackSource
+	}
+	if r.ConsentAt != "" {
+		givenAt, err := time.Parse(time.RFC3339, r.ConsentAt)
+		if err != nil {
+			return nil, fmt.Errorf("consent_at must be an RFC 3339 time, such as 2024-05-01T10:00:00Z")
+		}
+		consent.GivenAt = givenAt
+	}
+	return consent, nil
+}
+
+// GetLeadConsents returns the consents recorded for a lead, oldest first
+func GetLeadConsents(c *fiber.Ctx) error {
+	lead, err := ownedLead(c)
+	if lead == nil {
+		return err
+	}
+
+	consents, err := repository.GetLeadRepository().LeadConsents(lead.UserID, lead.Phone)
+	if err != nil {
+		return c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: fmt.Sprintf("Failed to get consents: %v", err),
+		})
+	}
+	if consents == nil {
+		consents = []models.LeadConsent{}
+	}
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Consents retrieved",
+		Results: consents,
+	})
+}
+
+// AddLeadConsent records a consent a lead gave after it was created
+func AddLeadConsent(c *fiber.Ctx) error {
+	lead, err := ownedLead(c)
+	if lead == nil {
+		return err
+	}
+
+	var request consentRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: "Invalid request body",
+		})
+	}
+	consent, err := request.consent(models.ConsentChannelManual, "dashboard")
+	if err != nil {
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: err.Error(),
+		})
+	}
+	consent.UserID, consent.Phone = lead.UserID, lead.Phone
+
+	if err := repository.GetLeadRepository().RecordConsent(consent); err != nil {
+		return c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: fmt.Sprintf("Failed to record consent: %v", err),
+		})
+	}
+
+	return c.Status(201).JSON(utils.ResponseData{
+		Status:  201,
+		Code:    "SUCCESS",
+		Message: "Consent recorded",
+		Results: consent,
+	})
+}
+
+// ListDataSubjectRequests returns the user's exports and erasures, newest first
+func ListDataSubjectRequests(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(utils.ResponseData{
+			Status:  401,
+			Code:    "UNAUTHORIZED",
+			Message: "Unauthorized",
+		})
+	}
+
+	requests, err := repository.GetDataSubjectRepository().Requests(userID, c.Query("phone"))
+	if err != nil {
+		return c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: fmt.Sprintf("Failed to list data subject requests: %v", err),
+		})
+	}
+	if requests == nil {
+		requests = []models.DataSubjectRequest{}
+	}
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Data subject requests retrieved",
+		Results: requests,
+	})
+}
+
+// ExportDataSubject returns everything the user stores about a phone as a JSON download
+func ExportDataSubject(c *fiber.Ctx) error {
+	userID, phone, ok := dataSubjectParams(c)
+	if !ok {
+		return nil
+	}
+
+	library, err := infraMedia.GetLibrary()
+	if err != nil {
+		return mediaLibraryUnavailable(c, err)
+	}
+
+	data, err := repository.GetDataSubjectRepository().Export(userID, phone, userID, library)
+	if err != nil {
+		return c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: fmt.Sprintf("Failed to export data: %v", err),
+		})
+	}
+
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=data_subject_%s_%s.json",
+		repository.SuppressionPhone(phone), data.ExportedAt.Format("2006-01-02")))
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Data exported",
+		Results: data,
+	})
+}
+
+// EraseDataSubject deletes or pseudonymizes everything the user stores about
+// a phone and cancels messages not yet sent to it. The suppression list
+// keeps the number so it is not contacted again.
+func EraseDataSubject(c *fiber.Ctx) error {
+	userID, phone, ok := dataSubjectParams(c)
+	if !ok {
+		return nil
+	}
+
+	library, err := infraMedia.GetLibrary()
+	if err != nil {
+		return mediaLibraryUnavailable(c, err)
+	}
+
+	request, err := repository.GetDataSubjectRepository().Erase(userID, phone, userID, library)
+	if err != nil {
+		return c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: fmt.Sprintf("Failed to erase data: %v", err),
+		})
+	}
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Data erased",
+		Results: request,
+	})
+}
+
+// dataSubjectParams returns the user and the phone named in the path,
+// writing the error response when either is missing
+func dataSubjectParams(c *fiber.Ctx) (userID, phone string, ok bool) {
+	userID, err := getUserID(c)
+	if err != nil {
+		c.Status(401).JSON(utils.ResponseData{
+			Status:  401,
+			Code:    "UNAUTHORIZED",
+			Message: "Unauthorized",
+		})
+		return "", "", false
+	}
+	phone = c.Params("phone")
+	if repository.SuppressionPhone(phone) == "" {
+		c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: "phone must contain digits",
+		})
+		return "", "", false
+	}
+	return userID, phone, true
+}
@@ -0,0 +1,235 @@
+package rest
+
+import (
+	"fmt"
+	"strconv"
+
+	domainBroadcast "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/broadcast"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+// InitRestDeadLetters initializes dead-letter queue endpoints
+func InitRestDeadLetters(app *fiber.App) {
+	app.Get("/api/dead-letters", ListDeadLetters)
+	app.Get("/api/dead-letters/summary", GetDeadLetterSummary)
+	app.Post("/api/dead-letters/requeue", RequeueDeadLetters)
+	app.Post("/api/dead-letters/discard", DiscardDeadLetters)
+}
+
+// deadLetterBulkRequest selects dead letters either by ID or by filter.
+// With no IDs, every dead letter matching the filter is affected.
+type deadLetterBulkRequest struct {
+	IDs        []string `json:"ids"`
+	CampaignID int      `json:"campaign_id"`
+	SequenceID string   `json:"sequence_id"`
+	DeviceID   string   `json:"device_id"`
+	ErrorClass string   `json:"error_class"`
+	ErrorCode  string   `json:"error_code"`
+	// TargetDeviceID moves requeued messages to another of the user's devices
+	TargetDeviceID string `json:"target_device_id"`
+}
+
+func (r deadLetterBulkRequest) filter(userID string) domainBroadcast.DeadLetterFilter {
+	return domainBroadcast.DeadLetterFilter{
+		UserID:     userID,
+		CampaignID: r.CampaignID,
+		SequenceID: r.SequenceID,
+		DeviceID:   r.DeviceID,
+		ErrorClass: r.ErrorClass,
+		ErrorCode:  r.ErrorCode,
+	}
+}
+
+// ListDeadLetters returns failed messages filtered by campaign, sequence, device and error class
+func ListDeadLetters(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(utils.ResponseData{
+			Status:  401,
+			Code:    "UNAUTHORIZED",
+			Message: "Unauthorized",
+		})
+	}
+
+	errorClass := c.Query("error_class")
+	if errorClass != "" && !domainBroadcast.IsErrorClass(errorClass) {
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: fmt.Sprintf("Unknown error class: %s", errorClass),
+		})
+	}
+
+	campaignID, _ := strconv.Atoi(c.Query("campaign_id"))
+	filter := domainBroadcast.DeadLetterFilter{
+		UserID:     userID,
+		CampaignID: campaignID,
+		SequenceID: c.Query("sequence_id"),
+		DeviceID:   c.Query("device_id"),
+		ErrorClass: errorClass,
+		ErrorCode:  c.Query("error_code"),
+		Limit:      c.QueryInt("limit", 50),
+		Offset:     c.QueryInt("offset", 0),
+	}
+
+	letters, total, err := repository.GetDeadLetterRepository().List(filter)
+	if err != nil {
+		return c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: fmt.Sprintf("Failed to list dead letters: %v", err),
+		})
+	}
+	if letters == nil {
+		letters = []domainBroadcast.DeadLetter{}
+	}
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Dead letters retrieved successfully",
+		Results: map[string]interface{}{
+			"items": letters,
+			"total": total,
+		},
+	})
+}
+
+// GetDeadLetterSummary returns dead letter counts per error class and send error code
+func GetDeadLetterSummary(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(utils.ResponseData{
+			Status:  401,
+			Code:    "UNAUTHORIZED",
+			Message: "Unauthorized",
+		})
+	}
+
+	counts, err := repository.GetDeadLetterRepository().CountByClass(userID)
+	if err != nil {
+		return c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: fmt.Sprintf("Failed to count dead letters: %v", err),
+		})
+	}
+
+	byCode, err := repository.GetDeadLetterRepository().CountByCode(userID)
+	if err != nil {
+		return c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: fmt.Sprintf("Failed to count dead letters: %v", err),
+		})
+	}
+
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Dead letter summary retrieved successfully",
+		Results: map[string]interface{}{
+			"by_class": counts,
+			"by_code":  byCode,
+			"total":    total,
+		},
+	})
+}
+
+// RequeueDeadLetters puts failed messages back to pending, optionally on another device
+func RequeueDeadLetters(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(utils.ResponseData{
+			Status:  401,
+			Code:    "UNAUTHORIZED",
+			Message: "Unauthorized",
+		})
+	}
+
+	var request deadLetterBulkRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: "Invalid request body",
+		})
+	}
+
+	targetDeviceName := ""
+	if request.TargetDeviceID != "" {
+		device, err := repository.GetUserRepository().GetUserDevice(userID, request.TargetDeviceID)
+		if err != nil {
+			return c.Status(404).JSON(utils.ResponseData{
+				Status:  404,
+				Code:    "NOT_FOUND",
+				Message: "Target device not found or unauthorized",
+			})
+		}
+		targetDeviceName = device.DeviceName
+	}
+
+	requeued, err := repository.GetDeadLetterRepository().Requeue(request.filter(userID), request.IDs, request.TargetDeviceID, targetDeviceName)
+	if err != nil {
+		return c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: fmt.Sprintf("Failed to requeue dead letters: %v", err),
+		})
+	}
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: fmt.Sprintf("Requeued %d messages", requeued),
+		Results: map[string]interface{}{
+			"requeued": requeued,
+		},
+	})
+}
+
+// DiscardDeadLetters gives up on failed messages for good
+func DiscardDeadLetters(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(utils.ResponseData{
+			Status:  401,
+			Code:    "UNAUTHORIZED",
+			Message: "Unauthorized",
+		})
+	}
+
+	var request deadLetterBulkRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: "Invalid request body",
+		})
+	}
+
+	discarded, err := repository.GetDeadLetterRepository().Discard(request.filter(userID), request.IDs)
+	if err != nil {
+		return c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: fmt.Sprintf("Failed to discard dead letters: %v", err),
+		})
+	}
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: fmt.Sprintf("Discarded %d messages", discarded),
+		Results: map[string]interface{}{
+			"discarded": discarded,
+		},
+	})
+}
@@ -35,10 +35,7 @@ func (handler *App) CreateDevice(c *fiber.Ctx) error {
 	}
 	
 	// Parse request body
-	var req struct {
-		Name  string `json:"name"`
-		Phone string `json:"phone"`
-	}
+	var req createDeviceRequest
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(400).JSON(utils.ResponseData{
 			Status:  400,
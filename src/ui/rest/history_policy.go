@@ -0,0 +1,218 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
+	"github.com/gofiber/fiber/v2"
+	"go.mau.fi/whatsmeow/types"
+)
+
+// Messages a backfill may ask the phone for at once
+const (
+	defaultBackfillCount = 50
+	maxBackfillCount     = 500
+)
+
+// InitRestHistoryPolicy initializes the history-sync policy and chat backfill endpoints
+func InitRestHistoryPolicy(app *fiber.App) {
+	app.Get("/api/devices/:id/history-policy", GetHistoryPolicy)
+	app.Put("/api/devices/:id/history-policy", UpdateHistoryPolicy)
+	app.Post("/api/devices/:id/chats/:chatId/backfill", RequestChatBackfill)
+	app.Get("/api/devices/:id/chats/:chatId/backfill", GetChatBackfill)
+}
+
+// backfillRequest is how many older messages to ask the phone for
+type backfillRequest struct {
+	Count int `json:"count"`
+}
+
+// ownedDeviceParam checks the device in the path belongs to the user,
+// writing the error response when it does not
+func ownedDeviceParam(c *fiber.Ctx) (string, error) {
+	userID, err := getUserID(c)
+	if err != nil {
+		return "", c.Status(401).JSON(utils.ResponseData{
+			Status:  401,
+			Code:    "UNAUTHORIZED",
+			Message: "Unauthorized",
+		})
+	}
+	deviceID := c.Params("id")
+	if _, err := ownedDevice(userID, deviceID); err != nil {
+		return "", c.Status(404).JSON(utils.ResponseData{
+			Status:  404,
+			Code:    "NOT_FOUND",
+			Message: "Device not found",
+		})
+	}
+	return deviceID, nil
+}
+
+// GetHistoryPolicy returns a device's history-sync policy, or the default
+// one when it has not set its own
+func GetHistoryPolicy(c *fiber.Ctx) error {
+	deviceID, err := ownedDeviceParam(c)
+	if deviceID == "" {
+		return err
+	}
+
+	policy, err := repository.GetHistoryPolicyRepository().Get(deviceID)
+	if err != nil {
+		return c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: fmt.Sprintf("Failed to get history policy: %v", err),
+		})
+	}
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "History policy retrieved",
+		Results: policy,
+	})
+}
+
+// UpdateHistoryPolicy changes a device's history-sync policy. Fields left
+// out of the body keep their current value. The policy applies to history
+// received from then on; stored messages are not removed.
+func UpdateHistoryPolicy(c *fiber.Ctx) error {
+	deviceID, err := ownedDeviceParam(c)
+	if deviceID == "" {
+		return err
+	}
+
+	repo := repository.GetHistoryPolicyRepository()
+	policy, err := repo.Get(deviceID)
+	if err != nil {
+		return c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: fmt.Sprintf("Failed to get history policy: %v", err),
+		})
+	}
+	if err := c.BodyParser(&policy); err != nil {
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: "Invalid request body",
+		})
+	}
+	policy.DeviceID = deviceID
+	if err := policy.Validate(); err != nil {
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: err.Error(),
+		})
+	}
+
+	if err := repo.Save(policy); err != nil {
+		return c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: fmt.Sprintf("Failed to save history policy: %v", err),
+		})
+	}
+	policy, _ = repo.Get(deviceID)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "History policy updated",
+		Results: policy,
+	})
+}
+
+// RequestChatBackfill asks the device's phone for messages of a chat older
+// than the oldest one stored. The messages arrive asynchronously; poll
+// GetChatBackfill for progress.
+func RequestChatBackfill(c *fiber.Ctx) error {
+	deviceID, err := ownedDeviceParam(c)
+	if deviceID == "" {
+		return err
+	}
+
+	chat, err := types.ParseJID(normalizeJIDs(c.Params("chatId")))
+	if err != nil {
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: "Invalid chat JID",
+		})
+	}
+	request := backfillRequest{Count: defaultBackfillCount}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&request); err != nil {
+			return c.Status(400).JSON(utils.ResponseData{
+				Status:  400,
+				Code:    "BAD_REQUEST",
+				Message: "Invalid request body",
+			})
+		}
+	}
+	if request.Count < 1 || request.Count > maxBackfillCount {
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: fmt.Sprintf("count must be between 1 and %d", maxBackfillCount),
+		})
+	}
+
+	backfill, err := whatsapp.RequestBackfill(deviceID, chat, request.Count)
+	switch {
+	case errors.Is(err, whatsapp.ErrBackfillRunning):
+		return c.Status(409).JSON(utils.ResponseData{
+			Status:  409,
+			Code:    "CONFLICT",
+			Message: err.Error(),
+		})
+	case errors.Is(err, whatsapp.ErrNothingToBackfill):
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: err.Error(),
+		})
+	case err != nil:
+		return c.Status(503).JSON(utils.ResponseData{
+			Status:  503,
+			Code:    "SERVICE_UNAVAILABLE",
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(202).JSON(utils.ResponseData{
+		Status:  202,
+		Code:    "SUCCESS",
+		Message: "Backfill requested",
+		Results: backfill,
+	})
+}
+
+// GetChatBackfill returns the progress of a chat's last backfill
+func GetChatBackfill(c *fiber.Ctx) error {
+	deviceID, err := ownedDeviceParam(c)
+	if deviceID == "" {
+		return err
+	}
+
+	backfill, ok := whatsapp.GetBackfill(deviceID, normalizeJIDs(c.Params("chatId")))
+	if !ok {
+		return c.Status(404).JSON(utils.ResponseData{
+			Status:  404,
+			Code:    "NOT_FOUND",
+			Message: "No backfill of this chat",
+		})
+	}
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Backfill progress retrieved",
+		Results: backfill,
+	})
+}
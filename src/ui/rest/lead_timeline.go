@@ -0,0 +1,315 @@
+package rest
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/models"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/listing"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/transcript"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+// InitRestLeadTimeline initializes the lead timeline, note and transcript endpoints
+func InitRestLeadTimeline(app *fiber.App) {
+	app.Get("/api/leads/:id/timeline", GetLeadTimeline)
+	app.Post("/api/leads/:id/notes", AddLeadNote)
+	app.Get("/api/leads/:id/transcript", ExportLeadTranscript)
+}
+
+// leadTimelineFields are the sort and filter fields of a lead's timeline,
+// which is merged in memory
+var leadTimelineFields = listing.Fields{
+	Sort:        []string{"at"},
+	Filter:      []string{"kind", "device_id"},
+	Key:         "id",
+	DefaultSort: "at",
+}
+
+// leadTimelineQuery filters a lead's timeline by kind, device and date range
+type leadTimelineQuery struct {
+	Kind      string `query:"kind"`
+	DeviceID  string `query:"device_id"`
+	StartDate string `query:"start_date"`
+	EndDate   string `query:"end_date"`
+	listQuery
+}
+
+// leadNoteRequest is a note added to a lead's timeline
+type leadNoteRequest struct {
+	Text string `json:"text"`
+}
+
+// leadTranscriptQuery picks the format and period of a transcript export
+type leadTranscriptQuery struct {
+	Format    string `query:"format"` // pdf (default), html or json
+	StartDate string `query:"start_date"`
+	EndDate   string `query:"end_date"`
+}
+
+// ownedLead returns the user's lead named in the path, writing the error
+// response when there is none
+func ownedLead(c *fiber.Ctx) (*models.Lead, error) {
+	userID, err := getUserID(c)
+	if err != nil {
+		return nil, c.Status(401).JSON(utils.ResponseData{
+			Status:  401,
+			Code:    "UNAUTHORIZED",
+			Message: "Unauthorized",
+		})
+	}
+	lead, err := repository.GetLeadRepository().GetLeadForUser(userID, c.Params("id"))
+	if err == sql.ErrNoRows {
+		return nil, c.Status(404).JSON(utils.ResponseData{
+			Status:  404,
+			Code:    "NOT_FOUND",
+			Message: "Lead not found",
+		})
+	}
+	if err != nil {
+		return nil, c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: fmt.Sprintf("Failed to get lead: %v", err),
+		})
+	}
+	return lead, nil
+}
+
+// leadTimelineInRange returns the lead's timeline between the start_date and
+// end_date parameters, both inclusive, writing the error response and
+// returning nil entries when it cannot
+func leadTimelineInRange(c *fiber.Ctx, lead *models.Lead) (entries []models.LeadTimelineEntry, since, until int64, err error) {
+	if since, err = parseSearchDate(c.Query("start_date"), 0); err == nil {
+		until, err = parseSearchDate(c.Query("end_date"), 1)
+	}
+	if err != nil {
+		return nil, 0, 0, c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: err.Error(),
+		})
+	}
+
+	all, err := repository.GetLeadRepository().LeadTimeline(lead)
+	if err != nil {
+		return nil, 0, 0, c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: fmt.Sprintf("Failed to build lead timeline: %v", err),
+		})
+	}
+	entries = []models.LeadTimelineEntry{}
+	for _, entry := range all {
+		if (since > 0 && entry.At.Unix() < since) || (until > 0 && entry.At.Unix() >= until) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, since, until, nil
+}
+
+// GetLeadTimeline returns a page of everything that happened to a lead, oldest first by default
+func GetLeadTimeline(c *fiber.Ctx) error {
+	lead, err := ownedLead(c)
+	if lead == nil {
+		return err
+	}
+
+	q, err := listing.Parse(c.Queries(), leadTimelineFields)
+	if err != nil {
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: err.Error(),
+		})
+	}
+
+	entries, _, _, err := leadTimelineInRange(c, lead)
+	if entries == nil {
+		return err
+	}
+
+	entries, page := listing.Slice(entries, q, leadTimelineValue)
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Lead timeline retrieved",
+		Results: entries,
+		Page:    &page,
+	})
+}
+
+// leadTimelineValue returns an entry's value of a leadTimelineFields field
+func leadTimelineValue(entry models.LeadTimelineEntry, field string) any {
+	switch field {
+	case "at":
+		return entry.At
+	case "kind":
+		return entry.Kind
+	case "device_id":
+		return entry.DeviceID
+	}
+	return entry.ID
+}
+
+// AddLeadNote adds a note to a lead's timeline
+func AddLeadNote(c *fiber.Ctx) error {
+	lead, err := ownedLead(c)
+	if lead == nil {
+		return err
+	}
+
+	var request leadNoteRequest
+	if err := c.BodyParser(&request); err != nil || strings.TrimSpace(request.Text) == "" {
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: "A note needs text",
+		})
+	}
+
+	userID, _ := getUserID(c)
+	event := models.LeadEvent{UserID: lead.UserID, Phone: lead.Phone, Kind: models.LeadTimelineNote,
+		Actor: userID, Text: strings.TrimSpace(request.Text), CreatedAt: time.Now()}
+	if err := repository.GetLeadRepository().RecordEvent(&event); err != nil {
+		return c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: fmt.Sprintf("Failed to add note: %v", err),
+		})
+	}
+
+	return c.Status(201).JSON(utils.ResponseData{
+		Status:  201,
+		Code:    "SUCCESS",
+		Message: "Note added",
+		Results: event,
+	})
+}
+
+// ExportLeadTranscript downloads a lead's conversation and timeline over a
+// period as PDF, HTML or JSON
+func ExportLeadTranscript(c *fiber.Ctx) error {
+	lead, err := ownedLead(c)
+	if lead == nil {
+		return err
+	}
+
+	format := strings.ToLower(c.Query("format", "pdf"))
+	if format != "pdf" && format != "html" && format != "json" {
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: "format must be pdf, html or json",
+		})
+	}
+
+	entries, since, until, err := leadTimelineInRange(c, lead)
+	if entries == nil {
+		return err
+	}
+
+	t := leadTranscript(lead, entries, since, until)
+	filename := fmt.Sprintf("transcript-%s-%s.%s", lead.Phone, time.Now().Format("2006-01-02"), format)
+	c.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	switch format {
+	case "json":
+		return c.JSON(t)
+	case "html":
+		var buf bytes.Buffer
+		if err := transcript.HTML(&buf, t); err != nil {
+			return err
+		}
+		c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+		return c.Send(buf.Bytes())
+	}
+	var buf bytes.Buffer
+	if err := transcript.PDF(&buf, t); err != nil {
+		return err
+	}
+	c.Set(fiber.HeaderContentType, "application/pdf")
+	return c.Send(buf.Bytes())
+}
+
+// leadTranscript turns a lead's timeline into a transcript. Messages are
+// spoken by the lead or by the agent; everything else is an event line. An
+// open end of the period is taken from the first or last entry.
+func leadTranscript(lead *models.Lead, entries []models.LeadTimelineEntry, since, until int64) transcript.Transcript {
+	t := transcript.Transcript{
+		Title:     "Conversation transcript",
+		Subject:   fmt.Sprintf("%s (%s)", lead.Name, lead.Phone),
+		Generated: time.Now(),
+	}
+	if since > 0 {
+		t.From = time.Unix(since, 0)
+	} else if len(entries) > 0 {
+		t.From = entries[0].At
+	}
+	if until > 0 {
+		// until is the start of the day after the end date
+		t.To = time.Unix(until, 0).Add(-time.Second)
+	} else if len(entries) > 0 {
+		t.To = entries[len(entries)-1].At
+	}
+
+	name := lead.Name
+	if name == "" {
+		name = lead.Phone
+	}
+	for _, entry := range entries {
+		line := transcript.Line{At: entry.At, Text: leadTimelineText(entry)}
+		switch entry.Kind {
+		case models.LeadTimelineMessageIn:
+			line.Speaker = name
+		case models.LeadTimelineMessageOut, models.LeadTimelineCampaignSend, models.LeadTimelineSequenceStep:
+			line.Speaker = "Agent"
+		}
+		t.Lines = append(t.Lines, line)
+	}
+	return t
+}
+
+// leadTimelineText describes a timeline entry in a sentence, or gives the
+// message text of a message entry
+func leadTimelineText(entry models.LeadTimelineEntry) string {
+	switch entry.Kind {
+	case models.LeadTimelineCreated:
+		return "Lead created"
+	case models.LeadTimelineCampaignSend, models.LeadTimelineSequenceStep:
+		text := entry.Text
+		if entry.Status == "failed" {
+			text += fmt.Sprintf(" [not sent: %s]", entry.Error)
+		}
+		return text
+	case models.LeadTimelineDelivered:
+		return fmt.Sprintf("Delivered: %s", leadTimelineSource(entry))
+	case models.LeadTimelineRead:
+		return fmt.Sprintf("Read: %s", leadTimelineSource(entry))
+	case models.LeadTimelineSequenceCompleted:
+		return fmt.Sprintf("Completed sequence %q", entry.Source)
+	case models.LeadTimelineStatusChange:
+		return fmt.Sprintf("Status changed from %q to %q", entry.From, entry.To)
+	case models.LeadTimelineTagAdded:
+		return fmt.Sprintf("Tagged %q", entry.Tag)
+	case models.LeadTimelineTagRemoved:
+		return fmt.Sprintf("Tag %q removed", entry.Tag)
+	case models.LeadTimelineNote:
+		return "Note: " + entry.Text
+	}
+	return entry.Text
+}
+
+// leadTimelineSource names the campaign or sequence step a receipt is for
+func leadTimelineSource(entry models.LeadTimelineEntry) string {
+	if entry.CampaignID != nil {
+		return fmt.Sprintf("campaign %q", entry.Source)
+	}
+	return fmt.Sprintf("sequence %q day %d", entry.Source, entry.Step)
+}
@@ -0,0 +1,300 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	infraMedia "github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/media"
+	pkgMedia "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/media"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+// InitRestMediaAssets initializes media asset library endpoints
+func InitRestMediaAssets(app *fiber.App) {
+	app.Get("/api/media-assets", ListMediaAssets)
+	app.Post("/api/media-assets", CreateMediaAsset)
+	app.Get("/api/media-assets/:id", GetMediaAsset)
+	app.Get("/api/media-assets/:id/content", GetMediaAssetContent)
+	app.Get("/api/media-assets/:id/thumbnail", GetMediaAssetThumbnail)
+	app.Delete("/api/media-assets/:id", DeleteMediaAsset)
+}
+
+// mediaAssetView is an asset with the asset:// URL used to send it in
+// campaigns, sequences and broadcasts
+type mediaAssetView struct {
+	repository.MediaAsset
+	AssetURL     string `json:"asset_url"`
+	HasThumbnail bool   `json:"has_thumbnail"`
+}
+
+func newMediaAssetView(asset *repository.MediaAsset) mediaAssetView {
+	return mediaAssetView{
+		MediaAsset:   *asset,
+		AssetURL:     infraMedia.AssetURL(asset.ID),
+		HasThumbnail: asset.ThumbnailKey != "",
+	}
+}
+
+func mediaLibraryUnavailable(c *fiber.Ctx, err error) error {
+	return c.Status(503).JSON(utils.ResponseData{
+		Status:  503,
+		Code:    "SERVICE_UNAVAILABLE",
+		Message: fmt.Sprintf("Media library unavailable: %v", err),
+	})
+}
+
+// ListMediaAssets returns the user's media assets, newest first
+func ListMediaAssets(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(utils.ResponseData{
+			Status:  401,
+			Code:    "UNAUTHORIZED",
+			Message: "Unauthorized",
+		})
+	}
+
+	assets, err := repository.GetMediaAssetRepository().ListAssets(userID, c.QueryInt("limit", 100), c.QueryInt("offset", 0))
+	if err != nil {
+		return c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: fmt.Sprintf("Failed to list media assets: %v", err),
+		})
+	}
+
+	views := make([]mediaAssetView, 0, len(assets))
+	for i := range assets {
+		views = append(views, newMediaAssetView(&assets[i]))
+	}
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Media assets retrieved successfully",
+		Results: views,
+	})
+}
+
+// CreateMediaAsset adds media to the library from a multipart "file" upload
+// or by downloading a JSON {"url": ...}
+func CreateMediaAsset(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(utils.ResponseData{
+			Status:  401,
+			Code:    "UNAUTHORIZED",
+			Message: "Unauthorized",
+		})
+	}
+
+	library, err := infraMedia.GetLibrary()
+	if err != nil {
+		return mediaLibraryUnavailable(c, err)
+	}
+
+	var asset *repository.MediaAsset
+	if strings.HasPrefix(c.Get(fiber.HeaderContentType), fiber.MIMEMultipartForm) {
+		file, err := c.FormFile("file")
+		if err != nil {
+			return c.Status(400).JSON(utils.ResponseData{
+				Status:  400,
+				Code:    "BAD_REQUEST",
+				Message: "Missing file",
+			})
+		}
+		maxSize := config.Current().Media.FetchMaxSize
+		if maxSize > 0 && file.Size > maxSize {
+			return c.Status(413).JSON(utils.ResponseData{
+				Status:  413,
+				Code:    "PAYLOAD_TOO_LARGE",
+				Message: fmt.Sprintf("File exceeds the maximum size of %d bytes", maxSize),
+			})
+		}
+
+		reader, err := file.Open()
+		if err != nil {
+			return c.Status(400).JSON(utils.ResponseData{
+				Status:  400,
+				Code:    "BAD_REQUEST",
+				Message: fmt.Sprintf("Failed to read file: %v", err),
+			})
+		}
+		defer reader.Close()
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return c.Status(400).JSON(utils.ResponseData{
+				Status:  400,
+				Code:    "BAD_REQUEST",
+				Message: fmt.Sprintf("Failed to read file: %v", err),
+			})
+		}
+
+		asset, err = library.Add(c.Context(), userID, data, file.Header.Get(fiber.HeaderContentType), file.Filename, "")
+		if err != nil {
+			return c.Status(500).JSON(utils.ResponseData{
+				Status:  500,
+				Code:    "INTERNAL_ERROR",
+				Message: fmt.Sprintf("Failed to store media: %v", err),
+			})
+		}
+	} else {
+		var request struct {
+			URL string `json:"url"`
+		}
+		if err := c.BodyParser(&request); err != nil || request.URL == "" {
+			return c.Status(400).JSON(utils.ResponseData{
+				Status:  400,
+				Code:    "BAD_REQUEST",
+				Message: "Provide a multipart file or a JSON body with url",
+			})
+		}
+
+		asset, _, err = library.Import(c.Context(), userID, request.URL)
+		if err != nil {
+			status, code := 502, "MEDIA_FETCH_FAILED"
+			switch {
+			case errors.Is(err, pkgMedia.ErrBlockedAddress), errors.Is(err, pkgMedia.ErrUnsupportedScheme):
+				status, code = 400, "BAD_REQUEST"
+			case errors.Is(err, pkgMedia.ErrTooLarge):
+				status, code = 413, "PAYLOAD_TOO_LARGE"
+			}
+			return c.Status(status).JSON(utils.ResponseData{
+				Status:  status,
+				Code:    code,
+				Message: fmt.Sprintf("Failed to import media: %v", err),
+			})
+		}
+	}
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Media asset stored successfully",
+		Results: newMediaAssetView(asset),
+	})
+}
+
+// userMediaAsset loads an asset by the :id param, answering 404 unless it
+// belongs to the user. A nil asset means a response was already sent.
+func userMediaAsset(c *fiber.Ctx) (*repository.MediaAsset, error) {
+	userID, err := getUserID(c)
+	if err != nil {
+		return nil, c.Status(401).JSON(utils.ResponseData{
+			Status:  401,
+			Code:    "UNAUTHORIZED",
+			Message: "Unauthorized",
+		})
+	}
+
+	asset, err := repository.GetMediaAssetRepository().GetAsset(c.Params("id"))
+	if err != nil {
+		return nil, c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: fmt.Sprintf("Failed to get media asset: %v", err),
+		})
+	}
+	if asset == nil || asset.UserID != userID {
+		return nil, c.Status(404).JSON(utils.ResponseData{
+			Status:  404,
+			Code:    "NOT_FOUND",
+			Message: "Media asset not found",
+		})
+	}
+	return asset, nil
+}
+
+// GetMediaAsset returns a media asset's metadata
+func GetMediaAsset(c *fiber.Ctx) error {
+	asset, err := userMediaAsset(c)
+	if asset == nil {
+		return err
+	}
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Media asset retrieved successfully",
+		Results: newMediaAssetView(asset),
+	})
+}
+
+// GetMediaAssetContent serves a media asset's content
+func GetMediaAssetContent(c *fiber.Ctx) error {
+	asset, err := userMediaAsset(c)
+	if asset == nil {
+		return err
+	}
+	library, err := infraMedia.GetLibrary()
+	if err != nil {
+		return mediaLibraryUnavailable(c, err)
+	}
+
+	data, err := library.Content(c.Context(), asset)
+	if err != nil {
+		return c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: err.Error(),
+		})
+	}
+	c.Set(fiber.HeaderContentType, asset.MimeType)
+	c.Set(fiber.HeaderCacheControl, "private, max-age=86400")
+	if asset.FileName != "" {
+		c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("inline; filename=%q", asset.FileName))
+	}
+	return c.Send(data)
+}
+
+// GetMediaAssetThumbnail serves a media asset's JPEG thumbnail
+func GetMediaAssetThumbnail(c *fiber.Ctx) error {
+	asset, err := userMediaAsset(c)
+	if asset == nil {
+		return err
+	}
+	library, err := infraMedia.GetLibrary()
+	if err != nil {
+		return mediaLibraryUnavailable(c, err)
+	}
+
+	thumb := library.Thumbnail(c.Context(), asset)
+	if thumb == nil {
+		return c.Status(404).JSON(utils.ResponseData{
+			Status:  404,
+			Code:    "NOT_FOUND",
+			Message: "Media asset has no thumbnail",
+		})
+	}
+	c.Set(fiber.HeaderContentType, "image/jpeg")
+	c.Set(fiber.HeaderCacheControl, "private, max-age=86400")
+	return c.Send(thumb)
+}
+
+// DeleteMediaAsset removes a media asset from the user's library
+func DeleteMediaAsset(c *fiber.Ctx) error {
+	asset, err := userMediaAsset(c)
+	if asset == nil {
+		return err
+	}
+	library, err := infraMedia.GetLibrary()
+	if err != nil {
+		return mediaLibraryUnavailable(c, err)
+	}
+
+	if _, err := library.Delete(c.Context(), asset.UserID, asset.ID); err != nil {
+		return c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: fmt.Sprintf("Failed to delete media asset: %v", err),
+		})
+	}
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Media asset deleted successfully",
+	})
+}
@@ -0,0 +1,129 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	infraMedia "github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/media"
+	pkgMedia "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/media"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+// InitRestMessageMedia initializes the endpoints serving received media
+func InitRestMessageMedia(app *fiber.App) {
+	app.Get("/api/devices/:id/media/:messageId", GetMessageMedia)
+	app.Get("/api/devices/:id/media/:messageId/thumbnail", GetMessageMediaThumbnail)
+}
+
+// deviceMessageMedia loads the stored media of a message on one of the
+// user's devices. A nil media means a response was already sent.
+func deviceMessageMedia(c *fiber.Ctx) (*repository.MessageMedia, error) {
+	userID, err := getUserID(c)
+	if err != nil {
+		return nil, c.Status(401).JSON(utils.ResponseData{
+			Status:  401,
+			Code:    "UNAUTHORIZED",
+			Message: "Unauthorized",
+		})
+	}
+	deviceID := c.Params("id")
+	if _, err := ownedDevice(userID, deviceID); err != nil {
+		return nil, c.Status(404).JSON(utils.ResponseData{
+			Status:  404,
+			Code:    "NOT_FOUND",
+			Message: "Device not found",
+		})
+	}
+
+	media, err := repository.GetWhatsAppRepository().GetMessageMedia(deviceID, c.Params("messageId"))
+	if err != nil {
+		return nil, c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: fmt.Sprintf("Failed to get message media: %v", err),
+		})
+	}
+	if media == nil {
+		return nil, c.Status(404).JSON(utils.ResponseData{
+			Status:  404,
+			Code:    "NOT_FOUND",
+			Message: "Message has no stored media",
+		})
+	}
+	return media, nil
+}
+
+// GetMessageMedia serves a received message's media, honouring a single
+// byte Range so voice notes and videos can be streamed and seeked
+func GetMessageMedia(c *fiber.Ctx) error {
+	media, err := deviceMessageMedia(c)
+	if media == nil {
+		return err
+	}
+	library, err := infraMedia.GetLibrary()
+	if err != nil {
+		return mediaLibraryUnavailable(c, err)
+	}
+
+	data, err := library.Read(c.Context(), media.StorageKey)
+	if err != nil {
+		return c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: err.Error(),
+		})
+	}
+
+	size := int64(len(data))
+	c.Set(fiber.HeaderContentType, media.MimeType)
+	c.Set(fiber.HeaderCacheControl, "private, max-age=86400")
+	c.Set(fiber.HeaderAcceptRanges, "bytes")
+	if media.FileName != "" {
+		c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("inline; filename=%q", media.FileName))
+	}
+
+	start, end, ok, err := pkgMedia.ParseRange(c.Get(fiber.HeaderRange), size)
+	if errors.Is(err, pkgMedia.ErrRangeNotSatisfiable) {
+		c.Set(fiber.HeaderContentRange, "bytes */"+strconv.FormatInt(size, 10))
+		return c.SendStatus(fiber.StatusRequestedRangeNotSatisfiable)
+	}
+	if !ok {
+		return c.Send(data)
+	}
+	c.Set(fiber.HeaderContentRange, fmt.Sprintf("bytes %d-%d/%d", start, end, size))
+	return c.Status(fiber.StatusPartialContent).Send(data[start : end+1])
+}
+
+// GetMessageMediaThumbnail serves the JPEG thumbnail of a received message's media
+func GetMessageMediaThumbnail(c *fiber.Ctx) error {
+	media, err := deviceMessageMedia(c)
+	if media == nil {
+		return err
+	}
+	if media.ThumbnailKey == "" {
+		return c.Status(404).JSON(utils.ResponseData{
+			Status:  404,
+			Code:    "NOT_FOUND",
+			Message: "Message media has no thumbnail",
+		})
+	}
+	library, err := infraMedia.GetLibrary()
+	if err != nil {
+		return mediaLibraryUnavailable(c, err)
+	}
+
+	thumb, err := library.Read(c.Context(), media.ThumbnailKey)
+	if err != nil {
+		return c.Status(404).JSON(utils.ResponseData{
+			Status:  404,
+			Code:    "NOT_FOUND",
+			Message: "Message media has no thumbnail",
+		})
+	}
+	c.Set(fiber.HeaderContentType, "image/jpeg")
+	c.Set(fiber.HeaderCacheControl, "private, max-age=86400")
+	return c.Send(thumb)
+}
@@ -0,0 +1,116 @@
+package rest
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/listing"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+// InitRestMessageSearch initializes the conversation history search endpoint
+func InitRestMessageSearch(app *fiber.App) {
+	app.Get("/api/messages/search", SearchMessages)
+}
+
+// messageSearchQuery is the search text and date range of a message search;
+// sorting, filtering and paging come from listQuery and the listing fields
+type messageSearchQuery struct {
+	DeviceID    string `query:"device_id"`
+	ChatJID     string `query:"chat_jid"`
+	SenderJID   string `query:"sender_jid"`
+	MessageType string `query:"message_type"`
+	Direction   string `query:"direction"`
+	StartDate   string `query:"start_date"`
+	EndDate     string `query:"end_date"`
+	listQuery
+}
+
+// SearchMessages returns a page of the messages stored for any of the user's
+// devices that match q, with the matching words highlighted
+func SearchMessages(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(utils.ResponseData{
+			Status:  401,
+			Code:    "UNAUTHORIZED",
+			Message: "Unauthorized",
+		})
+	}
+
+	args := c.Queries()
+	for _, field := range []string{"chat_jid", "sender_jid"} {
+		if args[field] != "" {
+			args[field] = normalizeJIDs(args[field])
+		}
+	}
+
+	q, err := listing.Parse(args, repository.MessageSearchFields)
+	if err != nil {
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: err.Error(),
+		})
+	}
+
+	search := repository.MessageSearch{Text: strings.TrimSpace(args["q"])}
+	if search.Since, err = parseSearchDate(args["start_date"], 0); err == nil {
+		// The end date is inclusive, so the range runs to the start of the next day
+		search.Until, err = parseSearchDate(args["end_date"], 1)
+	}
+	if err != nil {
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: err.Error(),
+		})
+	}
+
+	results, page, err := repository.GetWhatsAppRepository().SearchMessages(userID, q, search)
+	if err != nil {
+		return c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: fmt.Sprintf("Failed to search messages: %v", err),
+		})
+	}
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Messages retrieved",
+		Results: results,
+		Page:    &page,
+	})
+}
+
+// normalizeJIDs turns the bare phone numbers of a comma-separated filter into
+// user JIDs, leaving full JIDs as they are
+func normalizeJIDs(raw string) string {
+	values := strings.Split(raw, ",")
+	for i, value := range values {
+		value = strings.TrimPrefix(strings.TrimSpace(value), "+")
+		if value != "" && !strings.Contains(value, "@") {
+			value += "@s.whatsapp.net"
+		}
+		values[i] = value
+	}
+	return strings.Join(values, ",")
+}
+
+// parseSearchDate reads a YYYY-MM-DD date in server local time as unix
+// seconds, moved on by days; an empty date is zero
+func parseSearchDate(raw string, days int) (int64, error) {
+	if raw == "" {
+		return 0, nil
+	}
+	date, err := time.ParseInLocation("2006-01-02", raw, time.Local)
+	if err != nil {
+		return 0, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", raw)
+	}
+	return date.AddDate(0, 0, days).Unix(), nil
+}
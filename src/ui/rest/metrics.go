@@ -0,0 +1,36 @@
+package rest
+
+import (
+	"bytes"
+	"crypto/subtle"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/metrics"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/usecase"
+	"github.com/gofiber/fiber/v2"
+)
+
+// InitRestMetrics initializes the Prometheus scrape endpoint. It is
+// registered before the session middleware since scrapers cannot log in;
+// set METRICS_TOKEN to require a bearer token instead.
+func InitRestMetrics(app *fiber.App) {
+	usecase.RegisterMetricsCollectors()
+	app.Get("/metrics", GetMetrics)
+}
+
+// GetMetrics writes every registered metric in the Prometheus text format
+func GetMetrics(c *fiber.Ctx) error {
+	if config.MetricsToken != "" {
+		expected := []byte("Bearer " + config.MetricsToken)
+		if subtle.ConstantTimeCompare([]byte(c.Get(fiber.HeaderAuthorization)), expected) != 1 {
+			return c.SendStatus(fiber.StatusUnauthorized)
+		}
+	}
+
+	var out bytes.Buffer
+	if err := metrics.Default.WriteText(&out); err != nil {
+		return c.Status(fiber.StatusInternalServerError).SendString(err.Error())
+	}
+	c.Set(fiber.HeaderContentType, metrics.ContentType)
+	return c.Send(out.Bytes())
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/cluster"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/proxy"
+)
+
+// ClusterForwardedHeader carries the signed proof of the replica that
+// forwarded a request, so the receiving replica serves it instead of
+// forwarding it again
+const ClusterForwardedHeader = "X-Cluster-Forwarded-By"
+
+// clusterForwardTimeout bounds a forwarded call; QR and connect calls wait
+// on WhatsApp, so it is generous
+const clusterForwardTimeout = 60 * time.Second
+
+// ForwardToDeviceOwner sends a device-specific request to the replica that
+// owns the device, taking the device ID from the route parameter param or,
+// when that is empty, from the query parameter query. The owner checks the
+// session again, so cookies and headers are passed through unchanged.
+func ForwardToDeviceOwner(param, query string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		current := cluster.Current()
+		if proof := c.Get(ClusterForwardedHeader); proof != "" {
+			if current != nil {
+				if _, ok := current.VerifyForward(proof); ok {
+					return c.Next()
+				}
+			}
+			// Not from a replica; a client can't make this one serve a
+			// device it doesn't own
+			c.Request().Header.Del(ClusterForwardedHeader)
+		}
+		if current == nil {
+			return c.Next()
+		}
+
+		deviceID := ""
+		if param != "" {
+			deviceID = c.Params(param)
+		}
+		if deviceID == "" && query != "" {
+			deviceID = c.Query(query)
+		}
+		if deviceID == "" {
+			return c.Next()
+		}
+
+		owner, self := current.Owner(deviceID)
+		if self || owner.Address == "" {
+			return c.Next()
+		}
+
+		c.Request().Header.Set(ClusterForwardedHeader, current.SignForward())
+		if err := proxy.DoTimeout(c, owner.Address+c.OriginalURL(), clusterForwardTimeout); err != nil {
+			return c.Status(fiber.StatusBadGateway).JSON(utils.ResponseData{
+				Status:  fiber.StatusBadGateway,
+				Code:    "BAD_GATEWAY",
+				Message: fmt.Sprintf("Failed to reach replica %s for device %s: %v", owner.ID, deviceID, err),
+			})
+		}
+		return nil
+	}
+}
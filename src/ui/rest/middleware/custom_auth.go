@@ -27,6 +27,16 @@ var PublicRoutes = []string{
 	"/webhook/lead/create", // Public webhook for lead creation
 }
 
+// IsPublicPath reports whether CustomAuth lets a path through without a session
+func IsPublicPath(path string) bool {
+	for _, publicRoute := range PublicRoutes {
+		if path == publicRoute || strings.HasPrefix(path, publicRoute + "/") {
+			return true
+		}
+	}
+	return false
+}
+
 // CustomAuth middleware for session-based authentication
 func CustomAuth() fiber.Handler {
 	return func(c *fiber.Ctx) error {
@@ -34,10 +44,8 @@ func CustomAuth() fiber.Handler {
 		path := c.Path()
 		
 		// Check exact matches and prefix matches for truly public routes
-		for _, publicRoute := range PublicRoutes {
-			if path == publicRoute || strings.HasPrefix(path, publicRoute + "/") {
-				return c.Next()
-			}
+		if IsPublicPath(path) {
+			return c.Next()
 		}
 		
 		// Allow all OPTIONS requests (for CORS)
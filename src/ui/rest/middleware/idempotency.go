@@ -0,0 +1,187 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// IdempotencyKeyHeader carries the client's key for a request; a retry with
+// the same key gets the first response back instead of running again
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotencyReplayedHeader marks a response replayed from an earlier request
+const IdempotencyReplayedHeader = "Idempotency-Replayed"
+
+// idempotencyLocal marks a request an idempotency middleware already handled
+const idempotencyLocal = "idempotencyKey"
+
+// maxIdempotencyKeyLength matches the key column
+const maxIdempotencyKeyLength = 191
+
+// IdempotencyConfig tunes the idempotency middleware
+type IdempotencyConfig struct {
+	// KeyFrom derives a key from a request sent without the header, as for
+	// webhooks whose senders cannot set one. Derived keys are matched on the
+	// key alone since retries may differ in the body.
+	KeyFrom func(c *fiber.Ctx) string
+	// TTL is how long a response is replayed; it defaults to 24 hours
+	TTL time.Duration
+}
+
+// Idempotency makes POST, PUT, PATCH and DELETE requests sent with an
+// Idempotency-Key header run once per key and caller. A retry gets the
+// stored response, a retry while the first request runs gets 409, and a
+// key reused for a different request gets 422. Responses to server errors
+// and refused calls are not stored, so those can be retried. When the key
+// store is unavailable the request runs as if it had no key.
+func Idempotency(config ...IdempotencyConfig) fiber.Handler {
+	cfg := IdempotencyConfig{}
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = 24 * time.Hour
+	}
+
+	return func(c *fiber.Ctx) error {
+		switch c.Method() {
+		case fiber.MethodPost, fiber.MethodPut, fiber.MethodPatch, fiber.MethodDelete:
+		default:
+			return c.Next()
+		}
+		if c.Locals(idempotencyLocal) != nil {
+			return c.Next()
+		}
+
+		key := c.Get(IdempotencyKeyHeader)
+		derived := false
+		if key == "" && cfg.KeyFrom != nil {
+			key = cfg.KeyFrom(c)
+			derived = true
+		}
+		if key == "" {
+			return c.Next()
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ResponseData{
+				Status:  fiber.StatusBadRequest,
+				Code:    "BAD_REQUEST",
+				Message: "Idempotency-Key must be at most 191 characters",
+			})
+		}
+		c.Locals(idempotencyLocal, key)
+
+		scope := idempotencyScope(c)
+		fingerprint := ""
+		if !derived {
+			fingerprint = hashParts(c.Method(), c.Path(), string(c.Body()))
+		}
+
+		repo := repository.GetIdempotencyRepository()
+		existing, err := repo.Begin(scope, key, fingerprint)
+		if err != nil {
+			logrus.Warnf("Idempotency key %s not checked: %v", key, err)
+			return c.Next()
+		}
+		if existing != nil {
+			if existing.Fingerprint != fingerprint {
+				return c.Status(fiber.StatusUnprocessableEntity).JSON(utils.ResponseData{
+					Status:  fiber.StatusUnprocessableEntity,
+					Code:    "IDEMPOTENCY_KEY_REUSED",
+					Message: "Idempotency-Key was already used for a different request",
+				})
+			}
+			if !existing.Done {
+				return c.Status(fiber.StatusConflict).JSON(utils.ResponseData{
+					Status:  fiber.StatusConflict,
+					Code:    "IDEMPOTENCY_KEY_IN_USE",
+					Message: "A request with this Idempotency-Key is still running",
+				})
+			}
+			c.Set(IdempotencyReplayedHeader, "true")
+			if existing.ContentType != "" {
+				c.Set(fiber.HeaderContentType, existing.ContentType)
+			}
+			return c.Status(existing.StatusCode).Send(existing.Body)
+		}
+
+		stopRenewing := renewIdempotencyKey(repo, scope, key)
+		handlerErr := c.Next()
+		stopRenewing()
+
+		status := c.Response().StatusCode()
+		if handlerErr != nil || !replayable(status) {
+			if err := repo.Release(scope, key); err != nil {
+				logrus.Warnf("Failed to release idempotency key %s: %v", key, err)
+			}
+			return handlerErr
+		}
+		contentType := string(c.Response().Header.ContentType())
+		if err := repo.Complete(scope, key, status, contentType, c.Response().Body(), cfg.TTL); err != nil {
+			logrus.Warnf("Failed to store response for idempotency key %s: %v", key, err)
+		}
+		return nil
+	}
+}
+
+// renewIdempotencyKey keeps a request's key leased until the returned stop
+// is called, so a long request is not run again by a retry
+func renewIdempotencyKey(repo *repository.IdempotencyRepository, scope, key string) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(repository.IdempotencyLease / 4)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := repo.Renew(scope, key); err != nil {
+					logrus.Warnf("Failed to renew idempotency key %s: %v", key, err)
+				}
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// replayable reports whether a response is final for its request; refused
+// and throttled calls and server errors may succeed when retried
+func replayable(status int) bool {
+	switch status {
+	case fiber.StatusUnauthorized, fiber.StatusForbidden, fiber.StatusTooManyRequests:
+		return false
+	}
+	return status < fiber.StatusInternalServerError
+}
+
+// idempotencyScope keeps one caller's keys apart from another's by the
+// credential the request carries, before the auth middleware resolves it
+func idempotencyScope(c *fiber.Ctx) string {
+	for _, credential := range []string{
+		c.Cookies("session_token"),
+		c.Get(fiber.HeaderAuthorization),
+		c.Get("X-Auth-Token"),
+		c.Cookies("team_session"),
+	} {
+		if credential != "" {
+			return hashParts(credential)
+		}
+	}
+	return "anonymous"
+}
+
+func hashParts(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,374 @@
+package rest
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainGroup "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/group"
+	domainMessage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/message"
+	domainNewsletter "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/newsletter"
+	domainSend "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/send"
+	domainSequence "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/sequence"
+	domainUser "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/user"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/models"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/openapi"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/ui/rest/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+// apiPrefixes select the JSON API out of the route table; the rest serves
+// HTML pages and static files
+var apiPrefixes = []string{"/api/", "/app/", "/send/", "/user/", "/message/", "/group", "/newsletter/", "/webhook/"}
+
+// Query parameters shared by several operations
+type dateRangeQuery struct {
+	StartDate string `query:"start_date"`
+	EndDate   string `query:"end_date"`
+}
+
+type pageQuery struct {
+	Limit  int `query:"limit"`
+	Offset int `query:"offset"`
+}
+
+// listQuery pages, sorts and searches the list endpoints built on
+// pkg/listing; sort takes a field name, prefixed with "-" for descending
+type listQuery struct {
+	Limit  int    `query:"limit"`
+	Cursor string `query:"cursor"`
+	Sort   string `query:"sort"`
+	Q      string `query:"q"`
+}
+
+type leadStatusQuery struct {
+	Status    string `query:"status"`
+	StartDate string `query:"start_date"`
+	EndDate   string `query:"end_date"`
+}
+
+type deviceQuery struct {
+	DeviceID string `query:"deviceId"`
+}
+
+// apiOperations describe what the route table cannot: request bodies,
+// query parameters and operation IDs for closures and handlers that serve
+// more than one route. Keys are "METHOD /path" as registered; an entry
+// without a matching route fails the build of the spec.
+var apiOperations = map[string]openapi.OperationInfo{
+	// Session
+	"POST /api/login":    {Body: loginRequest{}},
+	"POST /api/register": {Body: registerRequest{}},
+	"GET /api/health":    {ID: "healthCheck"},
+
+	// Devices
+	"GET /app/login":           {ID: "loginDevice", Query: deviceQuery{}},
+	"GET /app/login-with-code": {Query: domainUser.CheckRequest{}},
+	"GET /app/logout":          {Query: deviceQuery{}},
+	"GET /app/reconnect":       {Query: deviceQuery{}},
+	"GET /app/qr": {Query: struct {
+		DeviceID string `query:"device_id"`
+	}{}},
+	"GET /app/devices":                 {ID: "listAppDevices"},
+	"POST /app/link-device":            {Body: linkDeviceRequest{}},
+	"POST /api/devices":                {Body: createDeviceRequest{}},
+	"PUT /api/devices/:id/update-jid":  {Body: updateDeviceJIDRequest{}},
+	"POST /api/devices/:id/send":       {Body: webMessageRequest{}},
+	"POST /api/devices/merge-contacts": {Body: mergeContactsRequest{}},
+	"GET /api/devices/:deviceId/leads": {Query: struct {
+		IncludeBroadcastHistory bool   `query:"include_broadcast_history"`
+		Status                  string `query:"status"`
+		TargetStatus            string `query:"target_status"`
+		Niche                   string `query:"niche"`
+		Trigger                 string `query:"trigger"`
+		NumberStatus            string `query:"number_status"`
+		listQuery
+	}{}},
+	"GET /api/devices/:id/chats":              {Query: listQuery{}},
+	"GET /api/devices/:deviceId/leads/export": {Produces: "text/csv"},
+
+	// Device share links
+	"GET /api/public/device/:deviceId/info":             {ID: "publicDeviceInfo"},
+	"GET /api/public/device/:deviceId/devices":          {ID: "publicDeviceList"},
+	"GET /api/public/device/:deviceId/campaign-summary": {ID: "publicCampaignSummary", Query: dateRangeQuery{}},
+	"GET /api/public/device/:deviceId/sequence-summary": {ID: "publicSequenceSummary", Query: dateRangeQuery{}},
+	"GET /api/public/device/:deviceId/leads": {ID: "publicListLeads", Query: struct {
+		Niche  string `query:"niche"`
+		Status string `query:"status"`
+		Search string `query:"search"`
+	}{}},
+	"POST /api/public/device/:deviceId/lead":                               {ID: "publicCreateLead", Body: publicLeadRequest{}},
+	"PUT /api/public/device/:deviceId/lead/:leadId":                        {ID: "publicUpdateLead", Body: publicLeadRequest{}},
+	"DELETE /api/public/device/:deviceId/lead/:leadId":                     {ID: "publicDeleteLead"},
+	"POST /api/public/device/:deviceId/leads/import":                       {ID: "publicImportLeads", Body: []publicLeadImport{}},
+	"GET /api/public/device/:deviceId/campaigns":                           {ID: "publicDeviceCampaigns"},
+	"GET /api/public/device/:deviceId/sequences":                           {ID: "publicDeviceSequences"},
+	"GET /api/public/device/:deviceId/messages":                            {ID: "publicDeviceMessages"},
+	"GET /api/public/device/:deviceId/campaigns/:campaignId/device-report": {ID: "publicCampaignDeviceReport"},
+	"GET /api/public/device/:deviceId/sequences/:sequenceId/device-report": {ID: "publicSequenceDeviceReport", Query: dateRangeQuery{}},
+
+	// Send, user, message, group and newsletter
+	"POST /send/message":                       {Body: domainSend.MessageRequest{}},
+	"POST /send/image":                         {Body: domainSend.ImageRequest{}},
+	"POST /send/file":                          {Body: domainSend.FileRequest{}},
+	"POST /send/video":                         {Body: domainSend.VideoRequest{}},
+	"POST /send/contact":                       {Body: domainSend.ContactRequest{}},
+	"POST /send/link":                          {Body: domainSend.LinkRequest{}},
+	"POST /send/location":                      {Body: domainSend.LocationRequest{}},
+	"POST /send/audio":                         {Body: domainSend.AudioRequest{}},
+	"POST /send/poll":                          {Body: domainSend.PollRequest{}},
+	"POST /send/presence":                      {Body: domainSend.PresenceRequest{}},
+	"GET /user/check":                          {Query: domainUser.CheckRequest{}},
+	"POST /message/:message_id/reaction":       {Body: domainMessage.ReactionRequest{}},
+	"POST /message/:message_id/revoke":         {Body: domainMessage.RevokeRequest{}},
+	"POST /message/:message_id/delete":         {Body: domainMessage.DeleteRequest{}},
+	"POST /message/:message_id/update":         {Body: domainMessage.UpdateMessageRequest{}},
+	"POST /message/:message_id/read":           {Body: domainMessage.MarkAsReadRequest{}},
+	"POST /message/:message_id/star":           {Body: domainMessage.StarRequest{}},
+	"POST /message/:message_id/unstar":         {Body: domainMessage.StarRequest{}},
+	"POST /group":                              {Body: domainGroup.CreateGroupRequest{}},
+	"POST /group/join-with-link":               {Body: domainGroup.JoinGroupWithLinkRequest{}},
+	"POST /group/leave":                        {Body: domainGroup.LeaveGroupRequest{}},
+	"POST /group/participants":                 {Body: domainGroup.ParticipantRequest{}},
+	"POST /group/participants/remove":          {Body: domainGroup.ParticipantRequest{}},
+	"POST /group/participants/promote":         {Body: domainGroup.ParticipantRequest{}},
+	"POST /group/participants/demote":          {Body: domainGroup.ParticipantRequest{}},
+	"GET /group/participant-requests":          {Query: domainGroup.GetGroupRequestParticipantsRequest{}},
+	"POST /group/participant-requests/approve": {Body: domainGroup.GroupRequestParticipantsRequest{}},
+	"POST /group/participant-requests/reject":  {Body: domainGroup.GroupRequestParticipantsRequest{}},
+	"POST /newsletter/unfollow":                {Body: domainNewsletter.UnfollowRequest{}},
+
+	// Leads
+	"POST /api/leads":    {Body: leadRequest{}},
+	"PUT /api/leads/:id": {Body: leadRequest{}},
+	"POST /api/leads-ai": {Body: leadAIRequest{}},
+	"GET /api/leads-ai": {Query: struct {
+		Status       string `query:"status"`
+		TargetStatus string `query:"target_status"`
+		Niche        string `query:"niche"`
+		Source       string `query:"source"`
+		listQuery
+	}{}},
+	"PUT /api/leads-ai/:id":     {Body: leadAIRequest{}},
+	"POST /webhook/lead/create": {Body: WebhookLeadRequest{}},
+
+	// Campaigns
+	"GET /api/campaigns": {Query: struct {
+		Status       string `query:"status"`
+		Niche        string `query:"niche"`
+		TargetStatus string `query:"target_status"`
+		MessageType  string `query:"message_type"`
+		listQuery
+	}{}},
+	"POST /api/campaigns":                           {Body: createCampaignRequest{}},
+	"PUT /api/campaigns/:id":                        {Body: updateCampaignRequest{}},
+	"GET /api/campaigns/summary":                    {Query: dateRangeQuery{}},
+	"POST /api/campaigns/:id/cancel":                {Body: cancelCampaignRequest{}},
+	"POST /api/campaigns/:id/throttle":              {Body: throttleCampaignRequest{}},
+	"GET /api/campaigns/:id/device/:deviceId/leads": {Query: leadStatusQuery{}},
+
+	// Sequences
+	"GET /api/sequences": {Query: struct {
+		Status string `query:"status"`
+		Niche  string `query:"niche"`
+		listQuery
+	}{}},
+	"GET /api/sequences/:id/contacts": {Query: struct {
+		Status      string `query:"status"`
+		CurrentStep string `query:"current_step"`
+		listQuery
+	}{}},
+	"POST /api/sequences":              {Body: domainSequence.CreateSequenceRequest{}},
+	"PUT /api/sequences/:id":           {Body: domainSequence.UpdateSequenceRequest{}},
+	"POST /api/sequences/:id/contacts": {Body: sequenceContactsRequest{}},
+	"GET /api/sequences/summary": {Query: struct {
+		dateRangeQuery
+		Today string `query:"today"`
+	}{}},
+	"GET /api/sequences/:id/device-report":                       {Query: dateRangeQuery{}},
+	"GET /api/sequences/:id/device/:deviceId/leads":              {Query: leadStatusQuery{}},
+	"GET /api/sequences/:id/device/:deviceId/step/:stepId/leads": {Query: leadStatusQuery{}},
+	"GET /api/sequences/:id/progress":                            {Query: dateRangeQuery{}},
+	"GET /api/sequences/:id/progress-new":                        {Query: dateRangeQuery{}},
+	"GET /api/sequences/:id/report-new":                          {Query: dateRangeQuery{}},
+	"GET /api/sequences/:id/leads": {Query: struct {
+		DeviceID     string `query:"device_id"`
+		StatusFilter string `query:"status_filter"`
+		dateRangeQuery
+	}{}},
+	"POST /api/sequences/:id/step/:stepId/resend":    {Body: resendStepRequest{}},
+	"GET /api/sequences/:id/update-pending-messages": {ID: "previewSequencePendingMessages"},
+	"POST /api/sequences/:id/update-pending-messages": {ID: "updateSequencePendingMessages", Query: struct {
+		Confirm bool `query:"confirm"`
+	}{}},
+	"GET /api/sequences/:id/flow/export": {Produces: "application/json"},
+	"GET /api/openapi.yaml":              {Produces: "application/yaml"},
+
+	// Team
+	"POST /api/team-members":            {Body: createTeamMemberRequest{}},
+	"PUT /api/team-members/:id":         {Body: updateTeamMemberRequest{}},
+	"GET /api/team/test":                {ID: "teamTest"},
+	"POST /api/team/login":              {ID: "teamLogin", Body: teamLoginRequest{}},
+	"GET /api/team/member-info":         {ID: "teamMemberInfo"},
+	"POST /api/team/logout":             {ID: "teamLogout"},
+	"GET /api/team/devices":             {ID: "teamDevices"},
+	"GET /api/team/campaigns/analytics": {ID: "teamCampaignAnalytics"},
+	"GET /api/team/sequences/analytics": {ID: "teamSequenceAnalytics"},
+	"GET /api/team/campaigns/summary":   {ID: "teamCampaignSummary", Query: dateRangeQuery{}},
+	"GET /api/team/niches":              {ID: "teamNiches"},
+	"GET /api/team/sequences":           {ID: "teamSequences"},
+	"GET /api/team/sequences/summary":   {ID: "teamSequenceSummary", Query: dateRangeQuery{}},
+
+	// Workers and monitoring
+	"GET /api/workers/status": {Query: struct {
+		Filter string `query:"filter"`
+		ID     string `query:"id"`
+	}{}},
+	"POST /api/monitoring/expire-messages": {Query: struct {
+		Hours int `query:"hours"`
+	}{}},
+
+	// Dead letters, planner, media, share links
+	"GET /api/dead-letters": {Query: struct {
+		CampaignID int    `query:"campaign_id"`
+		SequenceID string `query:"sequence_id"`
+		DeviceID   string `query:"device_id"`
+		ErrorClass string `query:"error_class"`
+		ErrorCode  string `query:"error_code"`
+		pageQuery
+	}{}},
+	"POST /api/dead-letters/requeue": {Body: deadLetterBulkRequest{}},
+	"POST /api/dead-letters/discard": {Body: deadLetterBulkRequest{}},
+	"GET /api/planner": {Query: struct {
+		Start string `query:"start"`
+		Days  int    `query:"days"`
+	}{}},
+	"POST /api/planner/rebalance":         {Body: plannerRebalanceRequest{}},
+	"GET /api/media-assets":               {Query: pageQuery{}},
+	"GET /api/media-assets/:id/content":   {Produces: "application/octet-stream"},
+	"GET /api/media-assets/:id/thumbnail": {Produces: "image/jpeg"},
+	"POST /api/share-links":               {Body: createShareLinkRequest{}},
+	"GET /api/share-links": {Query: struct {
+		DeviceID string `query:"device_id"`
+	}{}},
+	"GET /api/share-links/:id/access":                 {Query: pageQuery{}},
+	"GET /api/devices/:id/media/:messageId":           {Produces: "application/octet-stream"},
+	"GET /api/devices/:id/media/:messageId/thumbnail": {Produces: "image/jpeg"},
+	"GET /api/broadcast-messages": {Query: struct {
+		DeviceID    string `query:"device_id"`
+		CampaignID  string `query:"campaign_id"`
+		SequenceID  string `query:"sequence_id"`
+		MessageType string `query:"message_type"`
+		Status      string `query:"status"`
+		listQuery
+	}{}},
+	"GET /api/messages/search":                     {Query: messageSearchQuery{}},
+	"GET /api/leads/:id/timeline":                  {Query: leadTimelineQuery{}},
+	"POST /api/leads/:id/notes":                    {Body: leadNoteRequest{}},
+	"GET /api/leads/:id/transcript":                {Query: leadTranscriptQuery{}, Produces: "application/pdf"},
+	"PUT /api/devices/:id/history-policy":          {Body: models.HistoryPolicy{}},
+	"POST /api/devices/:id/chats/:chatId/backfill": {Body: backfillRequest{}},
+	"GET /api/groups": {Query: struct {
+		DeviceID string `query:"device_id"`
+		listQuery
+	}{}},
+	"POST /api/devices/:id/groups/:groupId/import-leads": {Body: whatsapp.GroupLeadOptions{}},
+	"GET /api/suppressions":                              {Query: listQuery{}},
+	"POST /api/suppressions":                             {Body: addSuppressionRequest{}},
+	"POST /api/leads/:id/consents":                       {Body: consentRequest{}},
+	"GET /api/data-subjects/requests":                    {Query: dataSubjectRequestsQuery{}},
+}
+
+// BuildOpenAPI describes the JSON API registered on app. Routes registered
+// before the CustomAuth middleware, and the paths it exempts, need no
+// session.
+func BuildOpenAPI(app *fiber.App) (*openapi.Document, error) {
+	var routes []openapi.Route
+	method, authenticated := "", false
+	for _, route := range app.GetRoutes() {
+		if route.Method != method {
+			method, authenticated = route.Method, false
+		}
+		handler := handlerName(route)
+		if strings.Contains(handler, "middleware.CustomAuth") {
+			authenticated = true
+			continue
+		}
+		if !isAPIRoute(route, handler) {
+			continue
+		}
+		apiRoute := openapi.Route{
+			Method:  route.Method,
+			Path:    route.Path,
+			Handler: handler,
+			Public:  !authenticated || middleware.IsPublicPath(route.Path),
+		}
+		if apiRoute.Public && strings.HasPrefix(route.Path, "/api/public/") {
+			apiRoute.Security = []openapi.SecurityRequirement{{"shareToken": {}}}
+		}
+		routes = append(routes, apiRoute)
+	}
+
+	return openapi.Build(openapi.Spec{
+		Info: openapi.Info{
+			Title:       "WhatsApp API MultiDevice",
+			Description: "Generated from the REST route table. Run `go test ./ui/rest -run TestOpenAPISpec -update` to regenerate.",
+			Version:     config.AppVersion,
+		},
+		Servers:  []openapi.Server{{URL: "http://localhost:3000"}},
+		Security: []openapi.SecurityRequirement{{"sessionCookie": {}}, {"bearerAuth": {}}, {"authToken": {}}},
+		SecuritySchemes: map[string]openapi.SecurityScheme{
+			"sessionCookie": {Type: "apiKey", In: "cookie", Name: "session_token", Description: "Set by POST /api/login"},
+			"bearerAuth":    {Type: "http", Scheme: "bearer", Description: "A session token"},
+			"authToken":     {Type: "apiKey", In: "header", Name: "X-Auth-Token", Description: "A session token"},
+			"shareToken":    {Type: "apiKey", In: "header", Name: shareTokenHeader, Description: "A device share link token; also accepted as the t query parameter"},
+		},
+		Envelope:   utils.ResponseData{},
+		Operations: apiOperations,
+	}, routes)
+}
+
+// GetOpenAPISpec serves the spec of the running server
+func GetOpenAPISpec(c *fiber.Ctx) error {
+	doc, err := BuildOpenAPI(c.App())
+	if err == nil {
+		var spec []byte
+		if spec, err = doc.YAML(); err == nil {
+			c.Set(fiber.HeaderContentType, "application/yaml")
+			return c.Send(spec)
+		}
+	}
+	return c.Status(500).JSON(utils.ResponseData{
+		Status:  500,
+		Code:    "INTERNAL_ERROR",
+		Message: err.Error(),
+	})
+}
+
+func isAPIRoute(route fiber.Route, handler string) bool {
+	switch route.Method {
+	case fiber.MethodHead, fiber.MethodOptions, fiber.MethodConnect, fiber.MethodTrace:
+		return false
+	}
+	// Cluster forwarders pass requests on to the handlers registered after them
+	if strings.Contains(handler, "ForwardToDeviceOwner") || strings.ContainsAny(route.Path, "*+") {
+		return false
+	}
+	for _, prefix := range apiPrefixes {
+		if strings.HasPrefix(route.Path, prefix) || route.Path+"/" == prefix {
+			return true
+		}
+	}
+	return false
+}
+
+func handlerName(route fiber.Route) string {
+	if len(route.Handlers) == 0 {
+		return ""
+	}
+	handler := route.Handlers[len(route.Handlers)-1]
+	return runtime.FuncForPC(reflect.ValueOf(handler).Pointer()).Name()
+}
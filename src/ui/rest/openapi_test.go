@@ -0,0 +1,61 @@
+package rest
+
+import (
+	"database/sql"
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/database"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/openapi"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/ui/rest/middleware"
+	"github.com/gofiber/fiber/v2"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "rewrite docs/openapi.yaml and the generated API client")
+
+const (
+	specPath   = "../../../docs/openapi.yaml"
+	clientPath = "../../pkg/apiclient/client_gen.go"
+)
+
+// newRouteTable registers the routes the way cmd/rest.go does
+func newRouteTable(t *testing.T) *fiber.App {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	database.SetDB(db)
+
+	app := fiber.New()
+	InitPublicRoutes(app)
+	app.Use(middleware.CustomAuth())
+	InitRoutes(app, Services{})
+	return app
+}
+
+// TestOpenAPISpec fails when a route, request type or operation changes
+// without regenerating the spec and client:
+//
+//	go test ./ui/rest -run TestOpenAPISpec -update
+func TestOpenAPISpec(t *testing.T) {
+	doc, err := BuildOpenAPI(newRouteTable(t))
+	require.NoError(t, err)
+	spec, err := doc.YAML()
+	require.NoError(t, err)
+	client, err := openapi.GenerateClient(doc, "apiclient")
+	require.NoError(t, err)
+
+	if *update {
+		require.NoError(t, os.WriteFile(specPath, spec, 0644))
+		require.NoError(t, os.WriteFile(clientPath, client, 0644))
+	}
+
+	wantSpec, err := os.ReadFile(specPath)
+	require.NoError(t, err)
+	assert.Equal(t, string(wantSpec), string(spec), "docs/openapi.yaml is stale; rerun with -update")
+	wantClient, err := os.ReadFile(clientPath)
+	require.NoError(t, err)
+	assert.Equal(t, string(wantClient), string(client), "pkg/apiclient is stale; rerun with -update")
+}
@@ -0,0 +1,148 @@
+package rest
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/ui/rest/middleware"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/usecase"
+	"github.com/gofiber/fiber/v2"
+)
+
+// InitRestPlanner initializes broadcast planner endpoints
+func InitRestPlanner(app *fiber.App) {
+	app.Get("/api/planner", GetPlannerTimeline)
+	app.Post("/api/planner/rebalance", RebalancePlanner)
+}
+
+// plannerRange reads the start date and number of days from the query string
+func plannerRange(c *fiber.Ctx, planner *usecase.BroadcastPlanner) (time.Time, int, error) {
+	start := planner.Today()
+	if value := c.Query("start"); value != "" {
+		parsed, err := planner.ParseDate(value)
+		if err != nil {
+			return start, 0, fmt.Errorf("start must be YYYY-MM-DD")
+		}
+		start = parsed
+	}
+
+	days := c.QueryInt("days", planner.HorizonDays())
+	if days <= 0 || days > 90 {
+		return start, 0, fmt.Errorf("days must be between 1 and 90")
+	}
+	return start, days, nil
+}
+
+// GetPlannerTimeline returns the per-day, per-device capacity forecast
+func GetPlannerTimeline(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		return c.Status(401).JSON(utils.ResponseData{
+			Status:  401,
+			Code:    "UNAUTHORIZED",
+			Message: "Authentication required",
+		})
+	}
+
+	planner := usecase.NewBroadcastPlanner()
+	start, days, err := plannerRange(c, planner)
+	if err != nil {
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: err.Error(),
+		})
+	}
+
+	timeline, err := planner.Forecast(userID, start, days)
+	if err != nil {
+		return c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: fmt.Sprintf("Failed to build forecast: %v", err),
+		})
+	}
+
+	overCapacityDays := 0
+	for _, day := range timeline {
+		if day.OverCapacity {
+			overCapacityDays++
+		}
+	}
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Planner timeline retrieved successfully",
+		Results: map[string]any{
+			"start":              start.Format("2006-01-02"),
+			"days":               days,
+			"over_capacity_days": overCapacityDays,
+			"timeline":           timeline,
+		},
+	})
+}
+
+// RebalancePlanner proposes or applies load spreading across days or devices
+func RebalancePlanner(c *fiber.Ctx) error {
+	userID, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		return c.Status(401).JSON(utils.ResponseData{
+			Status:  401,
+			Code:    "UNAUTHORIZED",
+			Message: "Authentication required",
+		})
+	}
+
+	var request plannerRebalanceRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: "Invalid request body",
+		})
+	}
+	if request.Mode != usecase.PlannerModeDays && request.Mode != usecase.PlannerModeDevices {
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: "mode must be 'days' or 'devices'",
+		})
+	}
+
+	planner := usecase.NewBroadcastPlanner()
+	start, days, err := plannerRange(c, planner)
+	if err != nil {
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: err.Error(),
+		})
+	}
+
+	moves, err := planner.Rebalance(userID, start, days, request.Mode, request.Apply)
+	if err != nil {
+		return c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: fmt.Sprintf("Failed to rebalance: %v", err),
+		})
+	}
+
+	message := "Rebalance plan generated"
+	if request.Apply {
+		message = fmt.Sprintf("Applied %d rebalance moves", len(moves))
+	}
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: message,
+		Results: map[string]any{
+			"mode":    request.Mode,
+			"applied": request.Apply,
+			"moves":   moves,
+		},
+	})
+}
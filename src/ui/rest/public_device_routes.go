@@ -3,20 +3,27 @@ package rest
 import (
 	"database/sql"
 	"fmt"
+	"strconv"
 	"time"
 	
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/database"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/models"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/sharelink"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
 	"github.com/dustin/go-humanize"
 	"github.com/gofiber/fiber/v2"
 	"github.com/sirupsen/logrus"
 )
 
-// InitPublicDeviceRoutes initializes public device view routes
+// InitPublicDeviceRoutes initializes public device view routes. They need no
+// login but every request must carry a share link created by the owner.
 func InitPublicDeviceRoutes(app *fiber.App, db *sql.DB) {
-	// Public device view - no auth required
-	app.Get("/device/:deviceId", func(c *fiber.Ctx) error {
+	// Password form target for protected share links
+	app.Post("/share/unlock", UnlockShareLink)
+	
+	// Public device view - share link with stats scope required
+	app.Get("/device/:deviceId", requireSharePage(sharelink.ScopeStats), func(c *fiber.Ctx) error {
 		deviceID := c.Params("deviceId")
 		if deviceID == "" {
 			return c.Status(404).SendString("Device not found")
@@ -41,12 +48,13 @@ func InitPublicDeviceRoutes(app *fiber.App, db *sql.DB) {
 			"MaxFileSize":    humanize.Bytes(uint64(config.WhatsappSettingMaxFileSize)),
 			"MaxVideoSize":   humanize.Bytes(uint64(config.WhatsappSettingMaxVideoSize)),
 			"IsPublicView":   true,
+			"ShareToken":     c.Query("t"),
 			"BasicAuthToken": nil, // No auth for public view
 		})
 	})
 	
-	// Public leads view - no auth required
-	app.Get("/public/device/:deviceId/leads", func(c *fiber.Ctx) error {
+	// Public leads view - share link with leads scope required
+	app.Get("/public/device/:deviceId/leads", requireSharePage(sharelink.ScopeLeadsRead), func(c *fiber.Ctx) error {
 		deviceID := c.Params("deviceId")
 		if deviceID == "" {
 			return c.Status(404).SendString("Device not found")
@@ -68,6 +76,7 @@ func InitPublicDeviceRoutes(app *fiber.App, db *sql.DB) {
 			"DeviceName":     device.DeviceName,
 			"DevicePhone":    device.Phone,
 			"IsPublicView":   true,
+			"ShareToken":     c.Query("t"),
 		})
 	})
 }
@@ -83,42 +92,45 @@ func InitPublicDeviceAPI(app *fiber.App) {
 		db: database.GetDB(),
 	}
 	
-	// Public API endpoints - no auth required
+	// Public API endpoints - no login, but each needs a share link with the right scope
 	publicAPI := app.Group("/api/public/device/:deviceId")
+	stats := requireShareAPI(sharelink.ScopeStats)
+	leadsRead := requireShareAPI(sharelink.ScopeLeadsRead)
+	leadsWrite := requireShareAPI(sharelink.ScopeLeadsWrite)
 	
 	// Device info endpoint
-	publicAPI.Get("/devices", api.GetDevices)
+	publicAPI.Get("/devices", stats, api.GetDevices)
 	
 	// Campaign summary endpoint
-	publicAPI.Get("/campaign-summary", api.GetCampaignSummary)
+	publicAPI.Get("/campaign-summary", stats, api.GetCampaignSummary)
 	
 	// Sequence summary endpoint  
-	publicAPI.Get("/sequence-summary", api.GetSequenceSummary)
+	publicAPI.Get("/sequence-summary", stats, api.GetSequenceSummary)
 	
 	// Leads endpoint
-	publicAPI.Get("/leads", api.GetLeads)
+	publicAPI.Get("/leads", leadsRead, api.GetLeads)
 	
 	// Lead CRUD endpoints
-	publicAPI.Post("/lead", api.CreateLead)
-	publicAPI.Put("/lead/:leadId", api.UpdateLead)
-	publicAPI.Delete("/lead/:leadId", api.DeleteLead)
-	publicAPI.Post("/leads/import", api.ImportLeads)
+	publicAPI.Post("/lead", leadsWrite, api.CreateLead)
+	publicAPI.Put("/lead/:leadId", leadsWrite, api.UpdateLead)
+	publicAPI.Delete("/lead/:leadId", leadsWrite, api.DeleteLead)
+	publicAPI.Post("/leads/import", leadsWrite, api.ImportLeads)
 	
-	// Get device statistics
-	publicAPI.Get("/info", api.GetDeviceStats)
+	// Get device statistics - name and status only, so any valid link may read it
+	publicAPI.Get("/info", requireShareAPI(""), api.GetDeviceStats)
 	
 	// Get campaigns for device
-	publicAPI.Get("/campaigns", api.GetDeviceCampaigns)
+	publicAPI.Get("/campaigns", stats, api.GetDeviceCampaigns)
 	
 	// Get sequences for device
-	publicAPI.Get("/sequences", api.GetDeviceSequences)
+	publicAPI.Get("/sequences", stats, api.GetDeviceSequences)
 	
 	// Get messages for device
-	publicAPI.Get("/messages", api.GetDeviceMessages)
+	publicAPI.Get("/messages", stats, api.GetDeviceMessages)
 	
 	// Device report endpoints
-	publicAPI.Get("/campaigns/:campaignId/device-report", api.GetCampaignDeviceReport)
-	publicAPI.Get("/sequences/:sequenceId/device-report", api.GetSequenceDeviceReport)
+	publicAPI.Get("/campaigns/:campaignId/device-report", stats, api.GetCampaignDeviceReport)
+	publicAPI.Get("/sequences/:sequenceId/device-report", stats, api.GetSequenceDeviceReport)
 }
 
 func (api *PublicDeviceAPI) GetDeviceStats(c *fiber.Ctx) error {
@@ -190,7 +202,7 @@ func (api *PublicDeviceAPI) GetCampaignSummary(c *fiber.Ctx) error {
 		LEFT JOIN (
 			SELECT 
 				campaign_id,
-				COUNT(*) as total_contacts,
+				COUNT(CASE WHEN status <> 'cancelled' THEN 1 END) as total_contacts,
 				SUM(CASE WHEN status = 'sent' THEN 1 ELSE 0 END) as total_sent,
 				SUM(CASE WHEN status = 'failed' THEN 1 ELSE 0 END) as total_failed,
 				SUM(CASE WHEN status = 'pending' THEN 1 ELSE 0 END) as total_pending
@@ -260,9 +272,11 @@ func (api *PublicDeviceAPI) GetCampaignSummary(c *fiber.Ctx) error {
 			continue
 		}
 		
-		// Map status
+		// Map status; paused and cancelled campaigns keep theirs
 		status := "pending"
-		if campaign.Status == "completed" || campaign.TotalSent > 0 {
+		if campaign.Status == "paused" || campaign.Status == "cancelled" {
+			status = campaign.Status
+		} else if campaign.Status == "completed" || campaign.TotalSent > 0 {
 			status = "completed"
 		} else if campaign.Status == "failed" || campaign.TotalFailed > 0 {
 			status = "failed"
@@ -308,7 +322,7 @@ func (api *PublicDeviceAPI) GetCampaignSummary(c *fiber.Ctx) error {
 	
 	err = api.db.QueryRow(`
 		SELECT 
-			COUNT(*) as total_should_send,
+			COUNT(CASE WHEN status <> 'cancelled' THEN 1 END) as total_should_send,
 			SUM(CASE WHEN status = 'sent' THEN 1 ELSE 0 END) as total_done_send,
 			SUM(CASE WHEN status = 'failed' THEN 1 ELSE 0 END) as total_failed_send,
 			SUM(CASE WHEN status = 'pending' THEN 1 ELSE 0 END) as total_remaining_send
@@ -732,28 +746,32 @@ func (api *PublicDeviceAPI) CreateLead(c *fiber.Ctx) error {
 		return c.Status(404).JSON(fiber.Map{"error": "Device not found"})
 	}
 	
-	var lead struct {
-		Phone        string `json:"phone"`
-		Name         string `json:"name"`
-		Niche        string `json:"niche"`
-		TargetStatus string `json:"target_status"`
-	}
+	var lead publicLeadRequest
 	
 	if err := c.BodyParser(&lead); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
 	}
 	
-	// Insert lead
-	result, err := api.db.Exec(
-		"INSERT INTO leads (device_id, phone, name, niche, target_status) VALUES (?, ?, ?, ?, ?)",
-		device.ID, lead.Phone, lead.Name, lead.Niche, lead.TargetStatus,
-	)
-	
+	consent, err := lead.consent(models.ConsentChannelShareLink, "share link")
 	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	
+	// Insert lead
+	newLead := &models.Lead{
+		UserID:       device.UserID,
+		DeviceID:     device.ID,
+		Name:         lead.Name,
+		Phone:        lead.Phone,
+		Niche:        lead.Niche,
+		TargetStatus: lead.TargetStatus,
+		Consent:      consent,
+	}
+	if err := repository.GetLeadRepository().CreateLead(newLead); err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to create lead"})
 	}
 	
-	leadID, _ := result.LastInsertId()
+	leadID, _ := strconv.ParseInt(newLead.ID, 10, 64)
 	
 	return c.JSON(fiber.Map{
 		"code": "SUCCESS",
@@ -774,17 +792,24 @@ func (api *PublicDeviceAPI) UpdateLead(c *fiber.Ctx) error {
 		return c.Status(404).JSON(fiber.Map{"error": "Device not found"})
 	}
 	
-	var lead struct {
-		Phone        string `json:"phone"`
-		Name         string `json:"name"`
-		Niche        string `json:"niche"`
-		TargetStatus string `json:"target_status"`
-	}
+	var lead publicLeadRequest
 	
 	if err := c.BodyParser(&lead); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
 	}
 	
+	// Read the current status so the change can go on the lead's timeline
+	var userID string
+	var previousStatus sql.NullString
+	err = api.db.QueryRow("SELECT user_id, target_status FROM leads WHERE id = ? AND device_id = ?", leadID, device.ID).
+		Scan(&userID, &previousStatus)
+	if err == sql.ErrNoRows {
+		return c.Status(404).JSON(fiber.Map{"error": "Lead not found"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update lead"})
+	}
+	
 	// Update lead
 	_, err = api.db.Exec(
 		"UPDATE leads SET phone = ?, name = ?, niche = ?, target_status = ? WHERE id = ? AND device_id = ?",
@@ -794,6 +819,8 @@ func (api *PublicDeviceAPI) UpdateLead(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to update lead"})
 	}
+	repository.GetLeadRepository().RecordStatusChange(userID, lead.Phone, repository.LeadActorShareLink,
+		previousStatus.String, lead.TargetStatus)
 	
 	return c.JSON(fiber.Map{
 		"code": "SUCCESS",
@@ -837,24 +864,29 @@ func (api *PublicDeviceAPI) ImportLeads(c *fiber.Ctx) error {
 		return c.Status(404).JSON(fiber.Map{"error": "Device not found"})
 	}
 	
-	var leads []struct {
-		Phone  string `json:"phone"`
-		Name   string `json:"name"`
-		Niche  string `json:"niche"`
-		Status string `json:"status"`
-	}
+	var leads []publicLeadImport
 	
 	if err := c.BodyParser(&leads); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
 	}
 	
 	// Import leads
+	leadRepo := repository.GetLeadRepository()
 	imported := 0
 	for _, lead := range leads {
-		_, err := api.db.Exec(
-			"INSERT INTO leads (device_id, phone, name, niche, status) VALUES (?, ?, ?, ?, ?)",
-			device.ID, lead.Phone, lead.Name, lead.Niche, lead.Status,
-		)
+		consent, err := lead.consent(models.ConsentChannelImport, "share link import")
+		if err != nil {
+			continue
+		}
+		err = leadRepo.CreateLead(&models.Lead{
+			UserID:   device.UserID,
+			DeviceID: device.ID,
+			Name:     lead.Name,
+			Phone:    lead.Phone,
+			Niche:    lead.Niche,
+			Status:   lead.Status,
+			Consent:  consent,
+		})
 		if err == nil {
 			imported++
 		}
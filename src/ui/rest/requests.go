@@ -0,0 +1,177 @@
+package rest
+
+import (
+	domainBroadcast "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/broadcast"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/models"
+)
+
+// Request bodies of the dashboard API. They are named so the OpenAPI spec
+// can describe them.
+
+// loginRequest signs a user in to the dashboard
+type loginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// registerRequest creates a dashboard user
+type registerRequest struct {
+	FullName string `json:"fullname"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// createDeviceRequest adds a device to the user's account
+type createDeviceRequest struct {
+	Name  string `json:"name"`
+	Phone string `json:"phone"`
+}
+
+// linkDeviceRequest records the phone number a device is linked to
+type linkDeviceRequest struct {
+	DeviceID string `json:"device_id"`
+	Phone    string `json:"phone"`
+}
+
+// updateDeviceJIDRequest sets a device's WhatsApp JID
+type updateDeviceJIDRequest struct {
+	JID string `json:"jid"`
+}
+
+// mergeContactsRequest moves synced contacts from one device to another
+type mergeContactsRequest struct {
+	OldDeviceID string `json:"old_device_id"`
+	NewDeviceID string `json:"new_device_id"`
+}
+
+// webMessageRequest sends a message from the WhatsApp Web view
+type webMessageRequest struct {
+	ChatID   string `json:"chatId"`
+	Message  string `json:"message"`
+	ImageURL string `json:"imageUrl,omitempty"`
+	ImageB64 string `json:"imageB64,omitempty"`
+}
+
+// leadRequest creates or updates a lead
+type leadRequest struct {
+	DeviceID     string `json:"device_id"`
+	Name         string `json:"name"`
+	Phone        string `json:"phone"`
+	Niche        string `json:"niche"`
+	Journey      string `json:"journey"`
+	TargetStatus string `json:"target_status"`
+	Trigger      string `json:"trigger"`
+	consentRequest
+}
+
+// consentRequest is the consent a lead gave, recorded when the lead is created
+type consentRequest struct {
+	ConsentSource string `json:"consent_source,omitempty"` // where consent was given, such as a form or campaign page
+	ConsentProof  string `json:"consent_proof,omitempty"`  // the wording agreed to, or a reference to the proof
+	ConsentAt     string `json:"consent_at,omitempty"`     // RFC 3339 time consent was given; defaults to now
+}
+
+// publicLeadRequest creates or updates a lead through a device share link
+type publicLeadRequest struct {
+	Phone        string `json:"phone"`
+	Name         string `json:"name"`
+	Niche        string `json:"niche"`
+	TargetStatus string `json:"target_status"`
+	consentRequest
+}
+
+// publicLeadImport is one lead of a bulk import through a share link
+type publicLeadImport struct {
+	Phone  string `json:"phone"`
+	Name   string `json:"name"`
+	Niche  string `json:"niche"`
+	Status string `json:"status"`
+	consentRequest
+}
+
+// leadAIRequest creates or updates an AI lead
+type leadAIRequest struct {
+	Name         string `json:"name"`
+	Phone        string `json:"phone"`
+	Email        string `json:"email"`
+	Niche        string `json:"niche"`
+	TargetStatus string `json:"target_status"`
+	Notes        string `json:"notes"`
+}
+
+// createCampaignRequest schedules a campaign; AI campaigns set AI and Limit
+type createCampaignRequest struct {
+	CampaignDate    string                          `json:"campaign_date"`
+	Title           string                          `json:"title"`
+	Niche           string                          `json:"niche"`
+	TargetStatus    string                          `json:"target_status"`
+	Message         string                          `json:"message"`
+	ImageURL        string                          `json:"image_url"`
+	MessageType     string                          `json:"message_type"`
+	Payload         *domainBroadcast.MessagePayload `json:"payload"`
+	TimeSchedule    string                          `json:"time_schedule"`
+	MinDelaySeconds int                             `json:"min_delay_seconds"`
+	MaxDelaySeconds int                             `json:"max_delay_seconds"`
+	AI              *string                         `json:"ai"`
+	Limit           int                             `json:"limit"`
+	TargetGroups    []models.CampaignGroupTarget    `json:"target_groups"`
+}
+
+// updateCampaignRequest replaces a campaign's editable fields
+type updateCampaignRequest struct {
+	Title           string                          `json:"title"`
+	Niche           string                          `json:"niche"`
+	Message         string                          `json:"message"`
+	ImageURL        string                          `json:"image_url"`
+	MessageType     string                          `json:"message_type"`
+	Payload         *domainBroadcast.MessagePayload `json:"payload"`
+	TimeSchedule    string                          `json:"time_schedule"`
+	CampaignDate    string                          `json:"campaign_date"`
+	MinDelaySeconds int                             `json:"min_delay_seconds"`
+	MaxDelaySeconds int                             `json:"max_delay_seconds"`
+	Status          string                          `json:"status"`
+	TargetGroups    []models.CampaignGroupTarget    `json:"target_groups"`
+}
+
+// createTeamMemberRequest adds a team member login
+type createTeamMemberRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// updateTeamMemberRequest changes a team member login
+type updateTeamMemberRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	IsActive bool   `json:"is_active"`
+}
+
+// resendStepRequest picks which messages of a sequence step to send again
+type resendStepRequest struct {
+	ResendFailed    bool `json:"resend_failed"`
+	ResendRemaining bool `json:"resend_remaining"`
+}
+
+// teamLoginRequest signs a team member in
+type teamLoginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// sequenceContactsRequest enrolls phone numbers in a sequence
+type sequenceContactsRequest struct {
+	Contacts []string `json:"contacts"`
+}
+
+// plannerRebalanceRequest spreads planned broadcasts over days or devices.
+// Without Apply the planner only previews the result.
+type plannerRebalanceRequest struct {
+	Mode  string `json:"mode"`
+	Apply bool   `json:"apply"`
+}
+
+// addSuppressionRequest suppresses a phone
+type addSuppressionRequest struct {
+	Phone  string `json:"phone"`
+	Reason string `json:"reason"`
+}
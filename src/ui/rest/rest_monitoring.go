@@ -9,6 +9,7 @@ import (
 	
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/database"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
 	"github.com/gofiber/fiber/v2"
 	"github.com/go-redis/redis/v8"
 	"github.com/sirupsen/logrus"
@@ -46,11 +47,11 @@ func GetRedisMetrics(c *fiber.Ctx) error {
 	// Get Redis client
 	redisURL := config.RedisURL
 	if redisURL == "" {
-		return c.JSON(fiber.Map{
-			"code":    fiber.StatusOK,
-			"message": "Redis not configured",
-			"error":   false,
-			"data": RedisMetrics{
+		return c.JSON(utils.ResponseData{
+			Status:  200,
+			Code:    "SUCCESS",
+			Message: "Redis not configured",
+			Results: RedisMetrics{
 				Connected: false,
 			},
 		})
@@ -59,10 +60,10 @@ func GetRedisMetrics(c *fiber.Ctx) error {
 	// Parse Redis URL
 	opt, err := redis.ParseURL(redisURL)
 	if err != nil {
-		return c.JSON(fiber.Map{
-			"code":    fiber.StatusInternalServerError,
-			"message": "Failed to parse Redis URL",
-			"error":   true,
+		return c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: "Failed to parse Redis URL",
 		})
 	}
 	
@@ -73,11 +74,11 @@ func GetRedisMetrics(c *fiber.Ctx) error {
 	
 	// Check connection
 	if err := client.Ping(ctx).Err(); err != nil {
-		return c.JSON(fiber.Map{
-			"code":    fiber.StatusOK,
-			"message": "Redis not connected",
-			"error":   false,
-			"data": RedisMetrics{
+		return c.JSON(utils.ResponseData{
+			Status:  200,
+			Code:    "SUCCESS",
+			Message: "Redis not connected",
+			Results: RedisMetrics{
 				Connected: false,
 			},
 		})
@@ -170,11 +171,11 @@ func GetRedisMetrics(c *fiber.Ctx) error {
 		metrics.WorkerMetrics[deviceID] = metric
 	}
 	
-	return c.JSON(fiber.Map{
-		"code":    fiber.StatusOK,
-		"message": "Redis metrics retrieved",
-		"error":   false,
-		"data":    metrics,
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Redis metrics retrieved",
+		Results: metrics,
 	})
 }
 
@@ -182,20 +183,20 @@ func GetRedisMetrics(c *fiber.Ctx) error {
 func GetQueueMessages(c *fiber.Ctx) error {
 	queueName := c.Params("queue")
 	if queueName == "" {
-		return c.JSON(fiber.Map{
-			"code":    fiber.StatusBadRequest,
-			"message": "Queue name required",
-			"error":   true,
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: "Queue name required",
 		})
 	}
 	
 	// Get Redis client
 	redisURL := config.RedisURL
 	if redisURL == "" {
-		return c.JSON(fiber.Map{
-			"code":    fiber.StatusServiceUnavailable,
-			"message": "Redis not configured",
-			"error":   true,
+		return c.Status(503).JSON(utils.ResponseData{
+			Status:  503,
+			Code:    "SERVICE_UNAVAILABLE",
+			Message: "Redis not configured",
 		})
 	}
 	
@@ -208,10 +209,10 @@ func GetQueueMessages(c *fiber.Ctx) error {
 	// Get messages from queue
 	messages, err := client.LRange(ctx, queueName, 0, 99).Result() // Limit to 100 messages
 	if err != nil {
-		return c.JSON(fiber.Map{
-			"code":    fiber.StatusInternalServerError,
-			"message": "Failed to get queue messages",
-			"error":   true,
+		return c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: "Failed to get queue messages",
 		})
 	}
 	
@@ -224,11 +225,11 @@ func GetQueueMessages(c *fiber.Ctx) error {
 		}
 	}
 	
-	return c.JSON(fiber.Map{
-		"code":    fiber.StatusOK,
-		"message": "Queue messages retrieved",
-		"error":   false,
-		"data": map[string]interface{}{
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Queue messages retrieved",
+		Results: map[string]interface{}{
 			"queue_name": queueName,
 			"count":      len(parsedMessages),
 			"messages":   parsedMessages,
@@ -245,20 +246,20 @@ func ClearQueue(c *fiber.Ctx) error {
 	
 	queueName := c.Params("queue")
 	if queueName == "" {
-		return c.JSON(fiber.Map{
-			"code":    fiber.StatusBadRequest,
-			"message": "Queue name required",
-			"error":   true,
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: "Queue name required",
 		})
 	}
 	
 	// Get Redis client
 	redisURL := config.RedisURL
 	if redisURL == "" {
-		return c.JSON(fiber.Map{
-			"code":    fiber.StatusServiceUnavailable,
-			"message": "Redis not configured",
-			"error":   true,
+		return c.Status(503).JSON(utils.ResponseData{
+			Status:  503,
+			Code:    "SERVICE_UNAVAILABLE",
+			Message: "Redis not configured",
 		})
 	}
 	
@@ -271,19 +272,19 @@ func ClearQueue(c *fiber.Ctx) error {
 	// Delete the queue
 	err := client.Del(ctx, queueName).Err()
 	if err != nil {
-		return c.JSON(fiber.Map{
-			"code":    fiber.StatusInternalServerError,
-			"message": "Failed to clear queue",
-			"error":   true,
+		return c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: "Failed to clear queue",
 		})
 	}
 	
 	logrus.Warnf("Queue %s cleared by admin", queueName)
 	
-	return c.JSON(fiber.Map{
-		"code":    fiber.StatusOK,
-		"message": "Queue cleared successfully",
-		"error":   false,
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Queue cleared successfully",
 	})
 }
 
@@ -292,10 +293,10 @@ func checkAdminAuth(c *fiber.Ctx) error {
 	// Simple admin check - you can enhance this
 	adminToken := c.Get("X-Admin-Token")
 	if adminToken != "your-secure-admin-token" {
-		return c.JSON(fiber.Map{
-			"code":    fiber.StatusUnauthorized,
-			"message": "Admin authorization required",
-			"error":   true,
+		return c.Status(401).JSON(utils.ResponseData{
+			Status:  401,
+			Code:    "UNAUTHORIZED",
+			Message: "Admin authorization required",
 		})
 	}
 	return nil
@@ -326,20 +327,20 @@ func ExpireOldMessages(c *fiber.Ctx) error {
 	db := database.GetDB()
 	result, err := db.Exec(query)
 	if err != nil {
-		return c.JSON(fiber.Map{
-			"code":    fiber.StatusInternalServerError,
-			"message": "Failed to expire messages",
-			"error":   true,
+		return c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: "Failed to expire messages",
 		})
 	}
 	
 	rowsAffected, _ := result.RowsAffected()
 	
-	return c.JSON(fiber.Map{
-		"code":    fiber.StatusOK,
-		"message": fmt.Sprintf("Expired %d messages older than %d hours", rowsAffected, hours),
-		"error":   false,
-		"data": map[string]interface{}{
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: fmt.Sprintf("Expired %d messages older than %d hours", rowsAffected, hours),
+		Results: map[string]interface{}{
 			"expired_count": rowsAffected,
 			"hours":         hours,
 		},
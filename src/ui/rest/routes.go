@@ -0,0 +1,68 @@
+package rest
+
+import (
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/database"
+	domainApp "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/app"
+	domainGroup "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/group"
+	domainMessage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/message"
+	domainNewsletter "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/newsletter"
+	domainSend "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/send"
+	domainSequence "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/sequence"
+	domainUser "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/user"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Services are the usecases behind the REST handlers
+type Services struct {
+	App        domainApp.IAppUsecase
+	Send       domainSend.ISendUsecase
+	User       domainUser.IUserUsecase
+	Message    domainMessage.IMessageUsecase
+	Group      domainGroup.IGroupUsecase
+	Newsletter domainNewsletter.INewsletterUsecase
+	Sequence   domainSequence.ISequenceUsecase
+}
+
+// InitPublicRoutes registers the routes served without a session. They must
+// be registered before the auth middleware.
+func InitPublicRoutes(app *fiber.App) {
+	InitPublicDeviceRoutes(app, database.GetDB()) // Add public device view routes
+	InitPublicDeviceAPI(app)                      // Add public device API endpoints
+	InitRestMetrics(app)                          // Add Prometheus scrape endpoint
+}
+
+// InitRoutes registers the routes behind the auth middleware. The server
+// and the OpenAPI spec are both built from this route table.
+func InitRoutes(app *fiber.App, services Services) {
+	InitRestCluster(app) // Forward device routes to their replica; must come first
+	appRest := InitRestApp(app, services.App)
+	appRest.SetSendService(services.Send) // Set send service for WhatsApp Web
+	InitRestSend(app, services.Send)
+	InitRestUser(app, services.User)
+	InitRestMessage(app, services.Message)
+	InitRestGroup(app, services.Group)
+	InitRestNewsletter(app, services.Newsletter)
+	InitRestSequence(app, services.Sequence)
+	InitRestSequenceFlow(app)                    // Add visual-flow sequence endpoints
+	InitRestMonitoring(app)                      // Add monitoring endpoints
+	InitWorkerControlAPI(app)                    // Add worker control endpoints
+	InitTeamRoutes(app, database.GetDB())        // Add team member routes
+	InitRedisCleanupAPI(app)                     // Add Redis cleanup endpoints
+	InitWebhookLead(app)                         // Add webhook endpoint for creating leads
+	InitRestPlanner(app)                         // Add broadcast planner endpoints
+	InitRestDeadLetters(app)                     // Add dead-letter queue endpoints
+	InitRestBroadcastMessages(app)               // Add broadcast message list endpoint
+	InitRestCampaignControls(app)                // Add campaign pause/resume/cancel/throttle endpoints
+	InitRestMediaAssets(app)                     // Add media asset library endpoints
+	InitRestMessageMedia(app)                    // Add received message media endpoints
+	InitRestMessageSearch(app)                   // Add conversation history search endpoint
+	InitRestLeadTimeline(app)                    // Add lead timeline, note and transcript endpoints
+	InitRestShareLinks(app)                      // Add device share link management endpoints
+	InitRestHistoryPolicy(app)                   // Add history-sync policy and chat backfill endpoints
+	InitRestWhatsAppGroups(app)                  // Add group cache, member and group lead import endpoints
+	InitRestSuppressions(app)                    // Add suppression list endpoints
+	InitRestDataSubjects(app)                    // Add consent and data subject export/erase endpoints
+	InitRestScheduler(app)                       // Add scheduler status endpoint
+	InitRestSystemConfig(app)                    // Add configuration view and reload endpoints
+	app.Get("/api/openapi.yaml", GetOpenAPISpec) // Serve the spec of this route table
+}
@@ -0,0 +1,35 @@
+package rest
+
+import (
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/scheduler"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// InitRestScheduler initializes the background job status endpoint
+func InitRestScheduler(app *fiber.App) {
+	app.Get("/api/scheduler", GetSchedulerStatus)
+}
+
+// GetSchedulerStatus lists every scheduled job of this replica with its
+// last run, last error and next run
+func GetSchedulerStatus(c *fiber.Ctx) error {
+	if _, err := getUserID(c); err != nil {
+		return c.Status(401).JSON(utils.ResponseData{
+			Status:  401,
+			Code:    "UNAUTHORIZED",
+			Message: "Unauthorized",
+		})
+	}
+
+	s := scheduler.Default()
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Scheduler status",
+		Results: fiber.Map{
+			"owner": s.Owner(),
+			"jobs":  s.Status(),
+		},
+	})
+}
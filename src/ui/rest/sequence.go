@@ -3,8 +3,10 @@ package rest
 import (
 	"fmt"
 	
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/database"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/domains/sequence"
 	domainSequence "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/sequence"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/listing"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/usecase"
@@ -37,13 +39,14 @@ func InitRestSequence(app *fiber.App, service sequence.ISequenceUsecase) {
 	app.Post("/api/sequences/:id/pause", rest.PauseSequence)
 	app.Post("/api/sequences/:id/toggle", rest.ToggleSequence)
 	app.Post("/api/sequences/:id/flow-update", rest.FlowUpdate)
+	app.Post("/api/sequences/migrate-lazy", rest.MigrateLazySteps)
 	
 	// UI routes
 	app.Get("/sequences", rest.SequencesPage)
 	app.Get("/sequences/:id", rest.SequenceDetailPage)
 }
 
-// GetSequences gets all sequences for logged in user
+// GetSequences gets a page of the logged in user's sequences
 func (controller *Sequence) GetSequences(c *fiber.Ctx) error {
 	userID, err := getUserID(c)
 	if err != nil {
@@ -54,7 +57,16 @@ func (controller *Sequence) GetSequences(c *fiber.Ctx) error {
 		})
 	}
 	
-	sequences, err := controller.Service.GetSequences(userID)
+	q, err := listing.Parse(c.Queries(), repository.SequenceListFields)
+	if err != nil {
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: err.Error(),
+		})
+	}
+	
+	sequences, page, err := controller.Service.ListSequences(userID, q)
 	if err != nil {
 		logrus.Errorf("Failed to get sequences for user %s: %v", userID, err)
 		return c.Status(500).JSON(utils.ResponseData{
@@ -64,20 +76,12 @@ func (controller *Sequence) GetSequences(c *fiber.Ctx) error {
 		})
 	}
 	
-	// Count only active sequences
-	activeCount := 0
-	for _, seq := range sequences {
-		if seq.IsActive {
-			activeCount++
-		}
-	}
-	logrus.Infof("Found %d active sequences (total: %d) for user %s", activeCount, len(sequences), userID)
-	
 	return c.JSON(utils.ResponseData{
 		Status:  200,
 		Code:    "SUCCESS",
 		Message: "Sequences retrieved",
 		Results: sequences,
+		Page:    &page,
 	})
 }
 
@@ -196,9 +200,7 @@ func (controller *Sequence) DeleteSequence(c *fiber.Ctx) error {
 func (controller *Sequence) AddContacts(c *fiber.Ctx) error {
 	sequenceID := c.Params("id")
 	
-	var request struct {
-		Contacts []string `json:"contacts"`
-	}
+	var request sequenceContactsRequest
 	
 	if err := c.BodyParser(&request); err != nil {
 		return c.Status(400).JSON(utils.ResponseData{
@@ -224,11 +226,20 @@ func (controller *Sequence) AddContacts(c *fiber.Ctx) error {
 	})
 }
 
-// GetContacts gets all contacts in sequence
+// GetContacts gets a page of the contacts in a sequence
 func (controller *Sequence) GetContacts(c *fiber.Ctx) error {
 	sequenceID := c.Params("id")
 	
-	contacts, err := controller.Service.GetSequenceContacts(sequenceID)
+	q, err := listing.Parse(c.Queries(), repository.SequenceContactListFields)
+	if err != nil {
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: err.Error(),
+		})
+	}
+	
+	contacts, page, err := controller.Service.ListSequenceContacts(sequenceID, q)
 	if err != nil {
 		return c.Status(500).JSON(utils.ResponseData{
 			Status:  500,
@@ -242,6 +253,7 @@ func (controller *Sequence) GetContacts(c *fiber.Ctx) error {
 		Code:    "SUCCESS",
 		Message: "Contacts retrieved",
 		Results: contacts,
+		Page:    &page,
 	})
 }
 
@@ -571,3 +583,52 @@ func (controller *Sequence) FlowUpdate(c *fiber.Ctx) error {
 		},
 	})
 }
+
+// MigrateLazySteps converts messages pre-queued for whole sequence chains into
+// contact positions. Without apply=true it only reports what would change.
+func (controller *Sequence) MigrateLazySteps(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(utils.ResponseData{
+			Status:  401,
+			Code:    "UNAUTHORIZED",
+			Message: "Unauthorized",
+		})
+	}
+	
+	var request struct {
+		Apply bool `json:"apply"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&request); err != nil {
+			return c.Status(400).JSON(utils.ResponseData{
+				Status:  400,
+				Code:    "BAD_REQUEST",
+				Message: "Invalid request body",
+			})
+		}
+	}
+	
+	materializer := usecase.NewSequenceStepMaterializer(database.GetDB())
+	summary, err := materializer.MigratePreQueued(userID, request.Apply)
+	if err != nil {
+		logrus.Errorf("Lazy step migration failed: %v", err)
+		return c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "ERROR",
+			Message: fmt.Sprintf("Migration failed: %v", err),
+		})
+	}
+	
+	message := "Migration preview generated"
+	if request.Apply {
+		message = fmt.Sprintf("Removed %d pre-queued messages", summary.RemovedMessages)
+	}
+	
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: message,
+		Results: summary,
+	})
+}
@@ -0,0 +1,217 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/database"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/usecase"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// InitRestSequenceFlow initializes visual-flow sequence endpoints
+func InitRestSequenceFlow(app *fiber.App) {
+	app.Post("/api/sequences/flows/validate", ValidateSequenceFlow)
+	app.Post("/api/sequences/flows/convert", ConvertSequenceFlows)
+	app.Get("/api/sequences/:id/flow", GetSequenceFlow)
+	app.Put("/api/sequences/:id/flow", SaveSequenceFlow)
+	app.Get("/api/sequences/:id/flow/export", ExportSequenceFlow)
+}
+
+// ownedSequence checks the sequence exists and belongs to the current user.
+// When it returns false the error response has already been written.
+func ownedSequence(c *fiber.Ctx) (string, bool) {
+	userID, err := getUserID(c)
+	if err != nil {
+		c.Status(401).JSON(utils.ResponseData{
+			Status:  401,
+			Code:    "UNAUTHORIZED",
+			Message: "Unauthorized",
+		})
+		return "", false
+	}
+
+	sequence, err := repository.GetSequenceRepository().GetSequenceByID(c.Params("id"))
+	if err != nil || sequence == nil || sequence.UserID != userID {
+		c.Status(404).JSON(utils.ResponseData{
+			Status:  404,
+			Code:    "NOT_FOUND",
+			Message: "Sequence not found or unauthorized",
+		})
+		return "", false
+	}
+	return sequence.ID, true
+}
+
+// GetSequenceFlow returns a sequence's flow; linear sequences are returned converted
+func GetSequenceFlow(c *fiber.Ctx) error {
+	sequenceID, ok := ownedSequence(c)
+	if !ok {
+		return nil
+	}
+
+	flow, err := usecase.NewSequenceFlowEngine(database.GetDB()).GetFlow(sequenceID)
+	if err != nil {
+		return c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: fmt.Sprintf("Failed to load flow: %v", err),
+		})
+	}
+
+	counts, err := repository.GetSequenceFlowRepository().CountPositionsByNode(sequenceID)
+	if err != nil {
+		logrus.Warnf("Failed to count flow positions for %s: %v", sequenceID, err)
+	}
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Sequence flow retrieved successfully",
+		Results: map[string]interface{}{
+			"flow":      flow,
+			"contacts":  counts,
+			"problems":  usecase.ValidateFlow(flow),
+			"is_linear": flow.Converted,
+		},
+	})
+}
+
+// SaveSequenceFlow imports a flow definition for a sequence
+func SaveSequenceFlow(c *fiber.Ctx) error {
+	sequenceID, ok := ownedSequence(c)
+	if !ok {
+		return nil
+	}
+
+	flow, problems, err := usecase.ParseFlow(c.Body())
+	if err != nil {
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: err.Error(),
+		})
+	}
+	if len(problems) == 0 {
+		flow.SequenceID = sequenceID
+		problems, err = usecase.NewSequenceFlowEngine(database.GetDB()).SaveFlow(flow)
+		if err != nil {
+			return c.Status(500).JSON(utils.ResponseData{
+				Status:  500,
+				Code:    "INTERNAL_ERROR",
+				Message: err.Error(),
+			})
+		}
+	}
+	if len(problems) > 0 {
+		return c.Status(422).JSON(utils.ResponseData{
+			Status:  422,
+			Code:    "INVALID_FLOW",
+			Message: fmt.Sprintf("Flow has %d problems", len(problems)),
+			Results: problems,
+		})
+	}
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Sequence flow saved successfully",
+		Results: flow,
+	})
+}
+
+// ExportSequenceFlow downloads a sequence's flow in the import format
+func ExportSequenceFlow(c *fiber.Ctx) error {
+	sequenceID, ok := ownedSequence(c)
+	if !ok {
+		return nil
+	}
+
+	flow, err := usecase.NewSequenceFlowEngine(database.GetDB()).GetFlow(sequenceID)
+	if err != nil {
+		return c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: fmt.Sprintf("Failed to load flow: %v", err),
+		})
+	}
+
+	exported := *flow
+	exported.Converted = false
+	exported.UpdatedAt = nil
+	data, err := json.MarshalIndent(exported, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	c.Set("Content-Type", "application/json")
+	c.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="sequence-flow-%s.json"`, sequenceID))
+	return c.Send(data)
+}
+
+// ValidateSequenceFlow checks a flow definition without saving it
+func ValidateSequenceFlow(c *fiber.Ctx) error {
+	if _, err := getUserID(c); err != nil {
+		return c.Status(401).JSON(utils.ResponseData{
+			Status:  401,
+			Code:    "UNAUTHORIZED",
+			Message: "Unauthorized",
+		})
+	}
+
+	_, problems, err := usecase.ParseFlow(c.Body())
+	if err != nil {
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: err.Error(),
+		})
+	}
+
+	message := "Flow is valid"
+	if len(problems) > 0 {
+		message = fmt.Sprintf("Flow has %d problems", len(problems))
+	}
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: message,
+		Results: map[string]interface{}{
+			"valid":    len(problems) == 0,
+			"problems": problems,
+		},
+	})
+}
+
+// ConvertSequenceFlows saves converted flows for all of the user's linear sequences
+func ConvertSequenceFlows(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(utils.ResponseData{
+			Status:  401,
+			Code:    "UNAUTHORIZED",
+			Message: "Unauthorized",
+		})
+	}
+
+	converted, err := usecase.NewSequenceFlowEngine(database.GetDB()).ConvertUserSequences(userID)
+	if err != nil {
+		return c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: fmt.Sprintf("Failed to convert sequences: %v", err),
+		})
+	}
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: fmt.Sprintf("Converted %d sequences to flows", converted),
+		Results: map[string]interface{}{
+			"converted": converted,
+		},
+	})
+}
@@ -0,0 +1,532 @@
+package rest
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/models"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/sharelink"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
+	"github.com/gofiber/fiber/v2"
+	fiberUtils "github.com/gofiber/fiber/v2/utils"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	shareTokenHeader    = "X-Share-Token"
+	sharePasswordHeader = "X-Share-Password"
+	shareUnlockCookie   = "share_unlock_"
+	shareLinkLocal      = "shareLink"
+
+	// Wrong passwords allowed per link in shareUnlockWindow
+	shareUnlockAttempts = 5
+	shareUnlockWindow   = 15 * time.Minute
+)
+
+// Access log outcomes other than "allowed"
+const (
+	shareOutcomeAllowed          = "allowed"
+	shareOutcomeRevoked          = "revoked"
+	shareOutcomeExpired          = "expired"
+	shareOutcomeWrongDevice      = "wrong_device"
+	shareOutcomeMissingScope     = "missing_scope"
+	shareOutcomePasswordRequired = "password_required"
+	shareOutcomeWrongPassword    = "wrong_password"
+	shareOutcomeLocked           = "locked"
+)
+
+// InitRestShareLinks initializes the endpoints owners use to manage share links
+func InitRestShareLinks(app *fiber.App) {
+	app.Get("/api/share-links", ListShareLinks)
+	app.Post("/api/share-links", CreateShareLink)
+	app.Delete("/api/share-links/:id", RevokeShareLink)
+	app.Get("/api/share-links/:id/access", ListShareLinkAccess)
+}
+
+// shareLinkView is a link with its token and ready-to-send URLs
+type shareLinkView struct {
+	repository.ShareLink
+	Token    string `json:"token"`
+	URL      string `json:"url"`
+	LeadsURL string `json:"leads_url,omitempty"`
+	Active   bool   `json:"active"`
+}
+
+func newShareLinkView(c *fiber.Ctx, secret []byte, link repository.ShareLink) shareLinkView {
+	token := sharelink.Sign(secret, link.ID, link.ExpiresAt)
+	host := fmt.Sprintf("%s://%s", c.Protocol(), c.Hostname())
+	view := shareLinkView{
+		ShareLink: link,
+		Token:     token,
+		URL:       fmt.Sprintf("%s/device/%s?t=%s", host, link.DeviceID, url.QueryEscape(token)),
+		Active:    link.RevokedAt == nil && time.Now().Before(link.ExpiresAt),
+	}
+	if sharelink.Allows(link.Scopes, sharelink.ScopeLeadsRead) {
+		view.LeadsURL = fmt.Sprintf("%s/public/device/%s/leads?t=%s", host, link.DeviceID, url.QueryEscape(token))
+	}
+	return view
+}
+
+// createShareLinkRequest describes a new link. Expiry is given either as a
+// time or as a number of hours from now.
+type createShareLinkRequest struct {
+	DeviceID       string     `json:"device_id"`
+	Name           string     `json:"name"`
+	Scopes         []string   `json:"scopes"`
+	ExpiresAt      *time.Time `json:"expires_at"`
+	ExpiresInHours int        `json:"expires_in_hours"`
+	Password       string     `json:"password"`
+}
+
+// CreateShareLink creates a link to one of the user's devices
+func CreateShareLink(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(utils.ResponseData{
+			Status:  401,
+			Code:    "UNAUTHORIZED",
+			Message: "Unauthorized",
+		})
+	}
+
+	var request createShareLinkRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: "Invalid request body",
+		})
+	}
+
+	if _, err := ownedDevice(userID, request.DeviceID); err != nil {
+		return c.Status(404).JSON(utils.ResponseData{
+			Status:  404,
+			Code:    "NOT_FOUND",
+			Message: "Device not found",
+		})
+	}
+
+	if len(request.Scopes) == 0 {
+		request.Scopes = []string{sharelink.ScopeStats}
+	}
+	for _, scope := range request.Scopes {
+		if !sharelink.IsScope(scope) {
+			return c.Status(400).JSON(utils.ResponseData{
+				Status:  400,
+				Code:    "BAD_REQUEST",
+				Message: fmt.Sprintf("Unknown scope: %s (use %s, %s or %s)", scope, sharelink.ScopeStats, sharelink.ScopeLeadsRead, sharelink.ScopeLeadsWrite),
+			})
+		}
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(7 * 24 * time.Hour)
+	if request.ExpiresAt != nil {
+		expiresAt = *request.ExpiresAt
+	} else if request.ExpiresInHours > 0 {
+		expiresAt = now.Add(time.Duration(request.ExpiresInHours) * time.Hour)
+	}
+	maxExpiryDays := config.Current().ShareLinks.MaxExpiryDays
+	maxExpiry := now.AddDate(0, 0, maxExpiryDays)
+	if !expiresAt.After(now) || expiresAt.After(maxExpiry) {
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: fmt.Sprintf("Expiry must be in the future and at most %d days away", maxExpiryDays),
+		})
+	}
+
+	link := repository.ShareLink{
+		UserID:    userID,
+		DeviceID:  request.DeviceID,
+		Name:      strings.TrimSpace(request.Name),
+		Scopes:    request.Scopes,
+		ExpiresAt: expiresAt.Truncate(time.Second),
+	}
+	if request.Password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return c.Status(400).JSON(utils.ResponseData{
+				Status:  400,
+				Code:    "BAD_REQUEST",
+				Message: fmt.Sprintf("Invalid password: %v", err),
+			})
+		}
+		link.PasswordHash = string(hash)
+	}
+
+	repo := repository.GetShareLinkRepository()
+	secret, err := repo.SigningSecret()
+	if err == nil {
+		err = repo.CreateLink(&link)
+	}
+	if err != nil {
+		return c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Share link created",
+		Results: newShareLinkView(c, secret, link),
+	})
+}
+
+// ListShareLinks returns the user's links, optionally for one device
+func ListShareLinks(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(utils.ResponseData{
+			Status:  401,
+			Code:    "UNAUTHORIZED",
+			Message: "Unauthorized",
+		})
+	}
+
+	repo := repository.GetShareLinkRepository()
+	secret, err := repo.SigningSecret()
+	if err != nil {
+		return c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: err.Error(),
+		})
+	}
+	links, err := repo.ListLinks(userID, c.Query("device_id"))
+	if err != nil {
+		return c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: err.Error(),
+		})
+	}
+
+	views := make([]shareLinkView, 0, len(links))
+	for _, link := range links {
+		views = append(views, newShareLinkView(c, secret, link))
+	}
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Share links retrieved",
+		Results: views,
+	})
+}
+
+// RevokeShareLink revokes a link immediately; revoked links stay listed
+// with their access log
+func RevokeShareLink(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(utils.ResponseData{
+			Status:  401,
+			Code:    "UNAUTHORIZED",
+			Message: "Unauthorized",
+		})
+	}
+
+	revoked, err := repository.GetShareLinkRepository().RevokeLink(userID, c.Params("id"))
+	if err != nil {
+		return c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: err.Error(),
+		})
+	}
+	if !revoked {
+		return c.Status(404).JSON(utils.ResponseData{
+			Status:  404,
+			Code:    "NOT_FOUND",
+			Message: "Share link not found or already revoked",
+		})
+	}
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Share link revoked",
+	})
+}
+
+// ListShareLinkAccess returns the requests made with one of the user's links
+func ListShareLinkAccess(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(utils.ResponseData{
+			Status:  401,
+			Code:    "UNAUTHORIZED",
+			Message: "Unauthorized",
+		})
+	}
+
+	repo := repository.GetShareLinkRepository()
+	link, err := repo.GetLink(c.Params("id"))
+	if err != nil {
+		return c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: err.Error(),
+		})
+	}
+	if link == nil || link.UserID != userID {
+		return c.Status(404).JSON(utils.ResponseData{
+			Status:  404,
+			Code:    "NOT_FOUND",
+			Message: "Share link not found",
+		})
+	}
+
+	entries, total, err := repo.ListAccess(link.ID, c.QueryInt("limit", 100), c.QueryInt("offset", 0))
+	if err != nil {
+		return c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Share link access retrieved",
+		Results: fiber.Map{
+			"entries": entries,
+			"total":   total,
+		},
+	})
+}
+
+// ownedDevice returns the device if it belongs to the user
+func ownedDevice(userID, deviceID string) (*models.UserDevice, error) {
+	device, err := repository.GetUserRepository().GetDeviceByID(deviceID)
+	if err != nil {
+		return nil, err
+	}
+	if device.UserID != userID {
+		return nil, fmt.Errorf("device not found")
+	}
+	return device, nil
+}
+
+// shareDenial is why a request was refused
+type shareDenial struct {
+	status  int
+	outcome string
+	message string
+}
+
+// authorizeShare checks the share token on a public request against the
+// device in the path and the scope the route needs. The link is returned
+// whenever the token was valid, so refusals can be logged against it.
+func authorizeShare(c *fiber.Ctx, scope string) (*repository.ShareLink, *shareDenial) {
+	token := c.Get(shareTokenHeader)
+	if token == "" {
+		token = c.Query("t")
+	}
+	if token == "" {
+		return nil, &shareDenial{401, "", "A share link is required to view this page"}
+	}
+
+	repo := repository.GetShareLinkRepository()
+	secret, err := repo.SigningSecret()
+	if err != nil {
+		logrus.Errorf("Share link check failed: %v", err)
+		return nil, &shareDenial{500, "", "Share links are unavailable"}
+	}
+	linkID, _, verifyErr := sharelink.Verify(secret, token, time.Now())
+	if verifyErr != nil && verifyErr != sharelink.ErrExpired {
+		return nil, &shareDenial{401, "", "Invalid share link"}
+	}
+
+	link, err := repo.GetLink(linkID)
+	if err != nil {
+		logrus.Errorf("Share link check failed: %v", err)
+		return nil, &shareDenial{500, "", "Share links are unavailable"}
+	}
+	if link == nil {
+		return nil, &shareDenial{401, "", "Invalid share link"}
+	}
+	if link.RevokedAt != nil {
+		return link, &shareDenial{403, shareOutcomeRevoked, "This share link has been revoked"}
+	}
+	if verifyErr == sharelink.ErrExpired || !time.Now().Before(link.ExpiresAt) {
+		return link, &shareDenial{403, shareOutcomeExpired, "This share link has expired"}
+	}
+
+	// The public pages address the device by ID, and some calls by name
+	device, err := ownedDevice(link.UserID, link.DeviceID)
+	if err != nil {
+		return link, &shareDenial{403, shareOutcomeWrongDevice, "This share link is no longer valid"}
+	}
+	if target := c.Params("deviceId"); target != device.ID && target != device.DeviceName {
+		return link, &shareDenial{403, shareOutcomeWrongDevice, "This share link is for another device"}
+	}
+
+	if !sharelink.Allows(link.Scopes, scope) {
+		return link, &shareDenial{403, shareOutcomeMissingScope, "This share link does not allow this action"}
+	}
+
+	if link.HasPassword {
+		proof := c.Cookies(shareUnlockCookie + link.ID)
+		if proof == "" || !sharelink.CheckUnlockProof(secret, link.ID, link.PasswordHash, proof) {
+			password := c.Get(sharePasswordHeader)
+			if password == "" {
+				return link, &shareDenial{401, shareOutcomePasswordRequired, "This share link requires a password"}
+			}
+			if shareLinkLocked(link) {
+				return link, &shareDenial{429, shareOutcomeLocked, "Too many wrong passwords, please try again later"}
+			}
+			if bcrypt.CompareHashAndPassword([]byte(link.PasswordHash), []byte(password)) != nil {
+				return link, &shareDenial{401, shareOutcomeWrongPassword, "Wrong password"}
+			}
+		}
+	}
+	return link, nil
+}
+
+// logShareAccess records a request in the link's access log
+func logShareAccess(c *fiber.Ctx, link *repository.ShareLink, outcome string, status int) {
+	// The log is written after the handler returns, when Fiber has reused
+	// the buffers behind the request's strings, so they are copied
+	access := repository.ShareLinkAccess{
+		LinkID:    link.ID,
+		Method:    fiberUtils.CopyString(c.Method()),
+		Path:      fiberUtils.CopyString(c.Path()),
+		IP:        fiberUtils.CopyString(c.IP()),
+		UserAgent: fiberUtils.CopyString(c.Get(fiber.HeaderUserAgent)),
+		Outcome:   outcome,
+		Status:    status,
+	}
+	go func() {
+		if err := repository.GetShareLinkRepository().LogAccess(access); err != nil {
+			logrus.Warnf("%v", err)
+		}
+	}()
+}
+
+// requireShareAPI guards a public API route with a share link that grants scope
+func requireShareAPI(scope string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		link, denial := authorizeShare(c, scope)
+		if denial != nil {
+			if link != nil {
+				logShareAccess(c, link, denial.outcome, denial.status)
+			}
+			return c.Status(denial.status).JSON(fiber.Map{"error": denial.message})
+		}
+
+		c.Locals(shareLinkLocal, link)
+		err := c.Next()
+		logShareAccess(c, link, shareOutcomeAllowed, c.Response().StatusCode())
+		return err
+	}
+}
+
+// requireSharePage guards a public page with a share link that grants
+// scope, asking for the link's password when it has one
+func requireSharePage(scope string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		link, denial := authorizeShare(c, scope)
+		if denial != nil {
+			if link != nil {
+				logShareAccess(c, link, denial.outcome, denial.status)
+			}
+			if denial.outcome == shareOutcomePasswordRequired {
+				return c.Render("views/share_unlock", fiber.Map{
+					"LinkName": link.Name,
+					"Token":    c.Query("t"),
+					"Next":     c.OriginalURL(),
+				})
+			}
+			return c.Status(denial.status).SendString(denial.message)
+		}
+
+		c.Locals(shareLinkLocal, link)
+		err := c.Next()
+		logShareAccess(c, link, shareOutcomeAllowed, c.Response().StatusCode())
+		return err
+	}
+}
+
+// shareLinkLocked reports whether too many wrong passwords were tried for
+// the link recently
+func shareLinkLocked(link *repository.ShareLink) bool {
+	failures, err := repository.GetShareLinkRepository().CountRecent(link.ID, shareOutcomeWrongPassword, time.Now().Add(-shareUnlockWindow))
+	if err != nil {
+		logrus.Warnf("Failed to count share link password attempts: %v", err)
+		return false
+	}
+	return failures >= shareUnlockAttempts
+}
+
+// localRedirect returns next when it is a path on this site and "/"
+// otherwise. Browsers read a backslash as a slash, so "/\host" leaves the
+// site just like "//host" does.
+func localRedirect(next string) string {
+	parsed, err := url.Parse(next)
+	if err != nil || parsed.Scheme != "" || parsed.Host != "" || !strings.HasPrefix(next, "/") ||
+		strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		return "/"
+	}
+	return next
+}
+
+// UnlockShareLink checks a link's password and remembers it in a cookie
+// until the link expires
+func UnlockShareLink(c *fiber.Ctx) error {
+	token := c.FormValue("token")
+	next := localRedirect(c.FormValue("next"))
+
+	repo := repository.GetShareLinkRepository()
+	secret, err := repo.SigningSecret()
+	if err != nil {
+		logrus.Errorf("Share link unlock failed: %v", err)
+		return c.Status(500).SendString("Share links are unavailable")
+	}
+	linkID, _, err := sharelink.Verify(secret, token, time.Now())
+	if err != nil {
+		return c.Status(403).SendString("This share link is invalid or has expired")
+	}
+	link, err := repo.GetLink(linkID)
+	if err != nil || link == nil || link.RevokedAt != nil || !link.HasPassword {
+		return c.Status(403).SendString("This share link is invalid or has been revoked")
+	}
+
+	render := func(status int, message string) error {
+		return c.Status(status).Render("views/share_unlock", fiber.Map{
+			"LinkName": link.Name,
+			"Token":    token,
+			"Next":     next,
+			"Error":    message,
+		})
+	}
+
+	if shareLinkLocked(link) {
+		logShareAccess(c, link, shareOutcomeLocked, 429)
+		return render(429, "Too many wrong passwords, please try again later")
+	}
+	if bcrypt.CompareHashAndPassword([]byte(link.PasswordHash), []byte(c.FormValue("password"))) != nil {
+		logShareAccess(c, link, shareOutcomeWrongPassword, 401)
+		return render(401, "Wrong password")
+	}
+
+	c.Cookie(&fiber.Cookie{
+		Name:     shareUnlockCookie + link.ID,
+		Value:    sharelink.UnlockProof(secret, link.ID, link.PasswordHash),
+		Path:     "/",
+		Expires:  link.ExpiresAt,
+		HTTPOnly: true,
+		Secure:   c.Protocol() == "https",
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+	return c.Redirect(next)
+}
@@ -0,0 +1,29 @@
+package rest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalRedirect(t *testing.T) {
+	tests := []struct {
+		next string
+		want string
+	}{
+		{"/device/abc?tab=leads", "/device/abc?tab=leads"},
+		{"/", "/"},
+		{"", "/"},
+		{"device/abc", "/"},
+		{"//evil.com", "/"},
+		{"/\\evil.com", "/"},
+		{"https://evil.com", "/"},
+		{"javascript:alert(1)", "/"},
+		{"/\t/evil.com", "/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.next, func(t *testing.T) {
+			assert.Equal(t, tt.want, localRedirect(tt.next))
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package rest
+
+import (
+	"fmt"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/listing"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+// InitRestSuppressions initializes the suppression list endpoints
+func InitRestSuppressions(app *fiber.App) {
+	app.Get("/api/suppressions", ListSuppressions)
+	app.Post("/api/suppressions", AddSuppression)
+	app.Delete("/api/suppressions/:phone", RemoveSuppression)
+}
+
+// ListSuppressions returns the user's suppressed phones
+func ListSuppressions(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(utils.ResponseData{
+			Status:  401,
+			Code:    "UNAUTHORIZED",
+			Message: "Unauthorized",
+		})
+	}
+
+	q, err := listing.Parse(c.Queries(), repository.SuppressionFields)
+	if err != nil {
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: err.Error(),
+		})
+	}
+
+	suppressions, page, err := repository.GetSuppressionRepository().List(userID, q)
+	if err != nil {
+		return c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: fmt.Sprintf("Failed to list suppressions: %v", err),
+		})
+	}
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Suppressions retrieved",
+		Results: suppressions,
+		Page:    &page,
+	})
+}
+
+// AddSuppression keeps a phone out of group lead imports and lead campaigns
+func AddSuppression(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(utils.ResponseData{
+			Status:  401,
+			Code:    "UNAUTHORIZED",
+			Message: "Unauthorized",
+		})
+	}
+
+	var request addSuppressionRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: "Invalid request body",
+		})
+	}
+	if repository.SuppressionPhone(request.Phone) == "" {
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: "phone is required",
+		})
+	}
+
+	if err := repository.GetSuppressionRepository().Add(userID, request.Phone, request.Reason); err != nil {
+		return c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: fmt.Sprintf("Failed to suppress phone: %v", err),
+		})
+	}
+
+	return c.Status(201).JSON(utils.ResponseData{
+		Status:  201,
+		Code:    "SUCCESS",
+		Message: "Phone suppressed",
+		Results: repository.Suppression{Phone: repository.SuppressionPhone(request.Phone), Reason: request.Reason},
+	})
+}
+
+// RemoveSuppression lifts the suppression of a phone
+func RemoveSuppression(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(utils.ResponseData{
+			Status:  401,
+			Code:    "UNAUTHORIZED",
+			Message: "Unauthorized",
+		})
+	}
+
+	removed, err := repository.GetSuppressionRepository().Remove(userID, c.Params("phone"))
+	if err != nil {
+		return c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: fmt.Sprintf("Failed to remove suppression: %v", err),
+		})
+	}
+	if !removed {
+		return c.Status(404).JSON(utils.ResponseData{
+			Status:  404,
+			Code:    "NOT_FOUND",
+			Message: "Phone is not suppressed",
+		})
+	}
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Suppression removed",
+	})
+}
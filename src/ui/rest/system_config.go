@@ -0,0 +1,66 @@
+package rest
+
+import (
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// InitRestSystemConfig initializes the configuration view and reload endpoints
+func InitRestSystemConfig(app *fiber.App) {
+	app.Get("/api/system/config", GetSystemConfig)
+	app.Post("/api/system/config/reload", ReloadSystemConfig)
+}
+
+// GetSystemConfig shows the configuration this replica runs with, secrets
+// redacted, and which keys a reload can change
+func GetSystemConfig(c *fiber.Ctx) error {
+	if _, err := getUserID(c); err != nil {
+		return c.Status(401).JSON(utils.ResponseData{
+			Status:  401,
+			Code:    "UNAUTHORIZED",
+			Message: "Unauthorized",
+		})
+	}
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Configuration",
+		Results: fiber.Map{
+			"file":       config.ConfigFile(),
+			"config":     config.Current().Redacted(),
+			"reloadable": config.ReloadableKeys(),
+		},
+	})
+}
+
+// ReloadSystemConfig reloads the config file and environment of this
+// replica, as SIGHUP does. Other replicas keep theirs until reloaded.
+func ReloadSystemConfig(c *fiber.Ctx) error {
+	if _, err := getUserID(c); err != nil {
+		return c.Status(401).JSON(utils.ResponseData{
+			Status:  401,
+			Code:    "UNAUTHORIZED",
+			Message: "Unauthorized",
+		})
+	}
+
+	result, err := config.Reload()
+	if err != nil {
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "INVALID_CONFIG",
+			Message: err.Error(),
+		})
+	}
+	logrus.Infof("Configuration reloaded through the API: applied %v, restart required for %v", result.Applied, result.RestartRequired)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Configuration reloaded",
+		Results: result,
+	})
+}
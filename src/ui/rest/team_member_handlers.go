@@ -474,6 +474,8 @@ func (h *TeamMemberHandlers) GetTeamCampaignsSummary(c *fiber.Ctx) error {
 	processingCampaigns := 0
 	sentCampaigns := 0
 	failedCampaigns := 0
+	pausedCampaigns := 0
+	cancelledCampaigns := 0
 	
 	for _, campaign := range campaigns {
 		switch campaign.Status {
@@ -487,6 +489,10 @@ func (h *TeamMemberHandlers) GetTeamCampaignsSummary(c *fiber.Ctx) error {
 			sentCampaigns++
 		case "failed":
 			failedCampaigns++
+		case "paused":
+			pausedCampaigns++
+		case "cancelled":
+			cancelledCampaigns++
 		}
 	}
 	
@@ -562,6 +568,8 @@ func (h *TeamMemberHandlers) GetTeamCampaignsSummary(c *fiber.Ctx) error {
 			"processing": processingCampaigns,
 			"sent": sentCampaigns,
 			"failed": failedCampaigns,
+			"paused": pausedCampaigns,
+			"cancelled": cancelledCampaigns,
 		},
 		"broadcast_stats": map[string]interface{}{
 			"total_should_send":    totalShouldSend,
@@ -766,9 +774,9 @@ func campaignUsesDevice(campaignID int64, deviceID string) bool {
 func getCampaignDeviceStats(campaignID int64, deviceID string) (shouldSend, doneSend, failedSend int) {
 	db := database.GetDB()
 	
-	// Get total messages for this device
+	// Get total messages for this device; cancelled messages are no longer due
 	var total int
-	query := `SELECT COUNT(*) FROM broadcast_messages WHERE campaign_id = ? AND device_id = ?`
+	query := `SELECT COUNT(*) FROM broadcast_messages WHERE campaign_id = ? AND device_id = ? AND status <> 'cancelled'`
 	db.QueryRow(query, campaignID, deviceID).Scan(&total)
 	shouldSend = total
 	
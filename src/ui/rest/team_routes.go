@@ -31,10 +31,7 @@ func InitTeamRoutes(app *fiber.App, db *sql.DB) {
 	app.Post("/api/team/login", func(c *fiber.Ctx) error {
 		log.Println("Team login endpoint called")
 		
-		var loginReq struct {
-			Username string `json:"username"`
-			Password string `json:"password"`
-		}
+		var loginReq teamLoginRequest
 
 		if err := c.BodyParser(&loginReq); err != nil {
 			log.Printf("Failed to parse team login request: %v", err)
@@ -1,11 +1,13 @@
 package rest
 
 import (
+	"encoding/json"
 	"time"
 	
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/models"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/ui/rest/middleware"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
@@ -22,12 +24,28 @@ type WebhookLeadRequest struct {
 	Trigger      string `json:"trigger"`
 	DeviceName   string `json:"device_name"`  // New field
 	Platform     string `json:"platform"`     // New field
+	consentRequest
 }
 
 // InitWebhookLead initializes the webhook endpoint for creating leads
 func InitWebhookLead(app *fiber.App) {
-	// Public webhook endpoint (no auth middleware)
-	app.Post("/webhook/lead/create", CreateLeadWebhook)
+	// Public webhook endpoint (no auth middleware). Senders retry without an
+	// Idempotency-Key, so a repeat of the same lead within ten minutes gets
+	// the first response instead of creating the lead again.
+	app.Post("/webhook/lead/create", middleware.Idempotency(middleware.IdempotencyConfig{
+		KeyFrom: leadWebhookKey,
+		TTL:     10 * time.Minute,
+	}), CreateLeadWebhook)
+}
+
+// leadWebhookKey derives the idempotency key of a lead webhook call from the
+// lead's phone, owner, device and niche, see repository.LeadIdempotencyKey
+func leadWebhookKey(c *fiber.Ctx) string {
+	var request WebhookLeadRequest
+	if err := json.Unmarshal(c.Body(), &request); err != nil || request.Phone == "" || request.UserID == "" {
+		return ""
+	}
+	return repository.LeadIdempotencyKey(request.UserID, request.Phone, request.DeviceID, request.Niche)
 }
 
 // CreateLeadWebhook handles the webhook request to create a lead
@@ -48,11 +66,6 @@ func CreateLeadWebhook(c *fiber.Ctx) error {
 
 	// Log the incoming request for debugging
 	logrus.Info("Webhook Lead: Received request - ", request)
-	
-	// Create a unique key based on phone + user_id to prevent duplicates
-	// This helps if the webhook is called multiple times quickly
-	dedupeKey := request.Phone + "_" + request.UserID
-	logrus.Info("Webhook Lead: Processing request with dedupe key: ", dedupeKey)
 
 	// Basic validation - only check required fields
 	if request.Name == "" {
@@ -87,6 +100,15 @@ func CreateLeadWebhook(c *fiber.Ctx) error {
 		})
 	}
 
+	consent, err := request.consent(models.ConsentChannelWebhook, request.Platform)
+	if err != nil {
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "VALIDATION_ERROR",
+			Message: err.Error(),
+		})
+	}
+
 	// Determine device name first
 	deviceName := request.DeviceName
 	if deviceName == "" {
@@ -195,6 +217,7 @@ func CreateLeadWebhook(c *fiber.Ctx) error {
 		DeviceID:     device.ID, // Use the actual device ID (UUID)
 		UserID:       request.UserID,
 		Platform:     request.Platform, // Add platform field
+		Consent:      consent,
 		CreatedAt:    time.Now(),
 		UpdatedAt:    time.Now(),
 	}
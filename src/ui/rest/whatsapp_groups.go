@@ -0,0 +1,254 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/models"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/listing"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
+	"github.com/gofiber/fiber/v2"
+	"go.mau.fi/whatsmeow/types"
+)
+
+// InitRestWhatsAppGroups initializes the group cache, member and group lead import endpoints
+func InitRestWhatsAppGroups(app *fiber.App) {
+	app.Get("/api/groups", ListWhatsAppGroups)
+	app.Post("/api/devices/:id/groups/refresh", RefreshWhatsAppGroups)
+	app.Get("/api/devices/:id/groups/:groupId/members", GetWhatsAppGroupMembers)
+	app.Post("/api/devices/:id/groups/:groupId/import-leads", ImportWhatsAppGroupLeads)
+	app.Get("/api/devices/:id/groups/:groupId/import-leads", GetWhatsAppGroupImport)
+}
+
+// groupMembersView is a group with its participants
+type groupMembersView struct {
+	Group   models.WhatsAppGroup         `json:"group"`
+	Members []models.WhatsAppGroupMember `json:"members"`
+}
+
+// groupParam parses the group JID in the path; the @g.us suffix is optional
+func groupParam(c *fiber.Ctx) (types.JID, error) {
+	raw := c.Params("groupId")
+	if !strings.ContainsRune(raw, '@') {
+		raw += "@" + types.GroupServer
+	}
+	jid, err := types.ParseJID(raw)
+	if err == nil && (jid.Server != types.GroupServer || jid.User == "") {
+		err = fmt.Errorf("not a group JID")
+	}
+	return jid, err
+}
+
+// validateGroupTargets checks each group of a group campaign is cached for
+// one of the user's devices and that the device may post to it
+func validateGroupTargets(userID string, targets []models.CampaignGroupTarget) error {
+	repo := repository.GetWhatsAppGroupRepository()
+	for _, target := range targets {
+		if _, err := ownedDevice(userID, target.DeviceID); err != nil {
+			return fmt.Errorf("device %s not found", target.DeviceID)
+		}
+		group, err := repo.GetGroup(target.DeviceID, target.GroupJID)
+		if err != nil {
+			return fmt.Errorf("group %s is not a known group of device %s; refresh the device's groups first",
+				target.GroupJID, target.DeviceID)
+		}
+		if !group.CanPost() {
+			return fmt.Errorf("only admins can post to group %s", group.Name)
+		}
+	}
+	return nil
+}
+
+// ListWhatsAppGroups returns the cached groups of all the user's devices
+func ListWhatsAppGroups(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(utils.ResponseData{
+			Status:  401,
+			Code:    "UNAUTHORIZED",
+			Message: "Unauthorized",
+		})
+	}
+
+	q, err := listing.Parse(c.Queries(), repository.WhatsAppGroupFields)
+	if err != nil {
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: err.Error(),
+		})
+	}
+
+	groups, page, err := repository.GetWhatsAppGroupRepository().ListGroups(userID, q)
+	if err != nil {
+		return c.Status(500).JSON(utils.ResponseData{
+			Status:  500,
+			Code:    "INTERNAL_ERROR",
+			Message: fmt.Sprintf("Failed to list groups: %v", err),
+		})
+	}
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Groups retrieved",
+		Results: groups,
+		Page:    &page,
+	})
+}
+
+// RefreshWhatsAppGroups re-reads the groups a device belongs to into the cache
+func RefreshWhatsAppGroups(c *fiber.Ctx) error {
+	deviceID, err := ownedDeviceParam(c)
+	if deviceID == "" {
+		return err
+	}
+
+	groups, err := whatsapp.RefreshGroups(deviceID)
+	if err != nil {
+		return c.Status(503).JSON(utils.ResponseData{
+			Status:  503,
+			Code:    "SERVICE_UNAVAILABLE",
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: fmt.Sprintf("Refreshed %d groups", len(groups)),
+		Results: groups,
+	})
+}
+
+// GetWhatsAppGroupMembers returns the participants of one of a device's groups
+func GetWhatsAppGroupMembers(c *fiber.Ctx) error {
+	deviceID, err := ownedDeviceParam(c)
+	if deviceID == "" {
+		return err
+	}
+	groupJID, err := groupParam(c)
+	if err != nil {
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: "Invalid group JID",
+		})
+	}
+
+	group, members, err := whatsapp.GroupMembers(deviceID, groupJID)
+	if err != nil {
+		return c.Status(503).JSON(utils.ResponseData{
+			Status:  503,
+			Code:    "SERVICE_UNAVAILABLE",
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Group members retrieved",
+		Results: groupMembersView{Group: group, Members: members},
+	})
+}
+
+// ImportWhatsAppGroupLeads starts importing a group's participants as leads
+// of the device. Poll GetWhatsAppGroupImport for progress.
+func ImportWhatsAppGroupLeads(c *fiber.Ctx) error {
+	deviceID, err := ownedDeviceParam(c)
+	if deviceID == "" {
+		return err
+	}
+	userID, _ := getUserID(c)
+	groupJID, err := groupParam(c)
+	if err != nil {
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: "Invalid group JID",
+		})
+	}
+
+	var options whatsapp.GroupLeadOptions
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&options); err != nil {
+			return c.Status(400).JSON(utils.ResponseData{
+				Status:  400,
+				Code:    "BAD_REQUEST",
+				Message: "Invalid request body",
+			})
+		}
+	}
+	if options.Niche == "" {
+		options.Niche = "WHATSAPP_GROUP"
+	}
+	if options.TargetStatus == "" {
+		options.TargetStatus = "prospect"
+	}
+	if options.TargetStatus != "prospect" && options.TargetStatus != "customer" {
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: "target_status must be prospect or customer",
+		})
+	}
+
+	job, err := whatsapp.ImportGroupLeads(deviceID, userID, groupJID, options)
+	switch {
+	case errors.Is(err, whatsapp.ErrGroupImportRunning):
+		return c.Status(409).JSON(utils.ResponseData{
+			Status:  409,
+			Code:    "CONFLICT",
+			Message: err.Error(),
+		})
+	case err != nil:
+		return c.Status(503).JSON(utils.ResponseData{
+			Status:  503,
+			Code:    "SERVICE_UNAVAILABLE",
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(202).JSON(utils.ResponseData{
+		Status:  202,
+		Code:    "SUCCESS",
+		Message: "Group lead import started",
+		Results: job,
+	})
+}
+
+// GetWhatsAppGroupImport returns the progress of a group's last lead import
+func GetWhatsAppGroupImport(c *fiber.Ctx) error {
+	deviceID, err := ownedDeviceParam(c)
+	if deviceID == "" {
+		return err
+	}
+	groupJID, err := groupParam(c)
+	if err != nil {
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: "Invalid group JID",
+		})
+	}
+
+	job, ok := whatsapp.GetGroupImport(deviceID, groupJID.String())
+	if !ok {
+		return c.Status(404).JSON(utils.ResponseData{
+			Status:  404,
+			Code:    "NOT_FOUND",
+			Message: "No lead import of this group",
+		})
+	}
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Group lead import progress retrieved",
+		Results: job,
+	})
+}
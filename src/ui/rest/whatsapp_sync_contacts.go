@@ -100,10 +100,7 @@ func (handler *App) MergeDeviceContacts(c *fiber.Ctx) error {
 		})
 	}
 	
-	var request struct {
-		OldDeviceID string `json:"old_device_id"`
-		NewDeviceID string `json:"new_device_id"`
-	}
+	var request mergeContactsRequest
 	
 	if err := c.BodyParser(&request); err != nil {
 		return c.Status(400).JSON(utils.ResponseData{
@@ -2,9 +2,9 @@ package rest
 
 import (
 	"fmt"
-	"time"
 	"github.com/gofiber/fiber/v2"
 	"github.com/sirupsen/logrus"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/listing"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
@@ -97,6 +97,15 @@ func (handler *App) GetWhatsAppChats(c *fiber.Ctx) error {
 		})
 	}
 	
+	q, err := listing.Parse(c.Queries(), chatListFields)
+	if err != nil {
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: err.Error(),
+		})
+	}
+	
 	if !isOnline {
 		// When offline, return stored chats from database
 		storedChats, err := whatsapp.GetChatsFromDatabase(deviceId)
@@ -105,11 +114,13 @@ func (handler *App) GetWhatsAppChats(c *fiber.Ctx) error {
 			storedChats = []map[string]interface{}{}
 		}
 		
+		storedChats, page := listing.Slice(storedChats, q, chatValue)
 		return c.JSON(utils.ResponseData{
 			Status:  200,
 			Code:    "DEVICE_OFFLINE",
 			Message: "Device is offline. Showing stored chat history.",
 			Results: storedChats,
+			Page:    &page,
 		})
 	}
 	
@@ -123,15 +134,30 @@ func (handler *App) GetWhatsAppChats(c *fiber.Ctx) error {
 		})
 	}
 	
-	// Return chats directly (already formatted)
+	// Chats are already formatted
+	chats, page := listing.Slice(chats, q, chatValue)
 	return c.JSON(utils.ResponseData{
 		Status:  200,
 		Code:    "SUCCESS",
-		Message: fmt.Sprintf("Found %d personal chats", len(chats)),
+		Message: fmt.Sprintf("Found %d personal chats", page.Total),
 		Results: chats,
+		Page:    &page,
 	})
 }
 
+// chatListFields are the sort and search fields of the chat list; chats are
+// paged in memory
+var chatListFields = listing.Fields{
+	Sort:        []string{"timestamp", "name", "messageCount"},
+	Search:      []string{"name", "phone", "lastMessage"},
+	Key:         "id",
+	DefaultSort: "-timestamp",
+}
+
+func chatValue(chat map[string]interface{}, field string) any {
+	return chat[field]
+}
+
 // GetWhatsAppMessages gets messages for a specific chat
 func (handler *App) GetWhatsAppMessages(c *fiber.Ctx) error {
 	deviceId := c.Params("id")
@@ -317,22 +343,25 @@ func (handler *App) SyncWhatsAppDevice(c *fiber.Ctx) error {
 		})
 	}
 	
-	// Force a sync by getting the WhatsApp client
-	cm := whatsapp.GetClientManager()
-	client, err := cm.GetClient(deviceId)
-	if err == nil && client != nil && client.IsConnected() {
-		// Just verify connection, no presence needed
-		// Small delay to allow messages to process
-		time.Sleep(500 * time.Millisecond)
-		
-		logrus.Infof("Sync triggered for device %s", deviceId)
+	// WhatsApp pushes history on its own; a sync re-applies the device's
+	// history policy to what is stored, importing chats as leads if it asks
+	// to. Older messages of a chat are fetched with a backfill instead.
+	policy := repository.HistoryPolicy(deviceId)
+	if policy.AutoLeads {
+		go func() {
+			if err := whatsapp.AutoSaveChatsToLeads(deviceId, user.ID); err != nil {
+				logrus.Errorf("Failed to import leads of device %s: %v", deviceId, err)
+			}
+		}()
 	}
+	logrus.Infof("Sync triggered for device %s", deviceId)
 	
 	// Return success and let the client refresh
 	return c.JSON(utils.ResponseData{
 		Status:  200,
 		Code:    "SUCCESS",
 		Message: "Sync completed. Refreshing chats...",
+		Results: policy,
 	})
 }
 
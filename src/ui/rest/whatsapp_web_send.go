@@ -25,15 +25,7 @@ import (
 func (handler *App) SendWhatsAppWebMessage(c *fiber.Ctx) error {
 	deviceId := c.Params("id")
 	
-	// Message request structure
-	type MessageRequest struct {
-		ChatID   string `json:"chatId"`
-		Message  string `json:"message"`
-		ImageURL string `json:"imageUrl,omitempty"`
-		ImageB64 string `json:"imageB64,omitempty"`
-	}
-	
-	var request MessageRequest
+	var request webMessageRequest
 	if err := c.BodyParser(&request); err != nil {
 		return c.Status(400).JSON(utils.ResponseData{
 			Status:  400,
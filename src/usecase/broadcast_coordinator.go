@@ -1,11 +1,13 @@
 package usecase
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strings"
 	"time"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/database"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/scheduler"
 	"github.com/sirupsen/logrus"
 )
 
@@ -190,7 +192,7 @@ func (bc *BroadcastCoordinator) GetCurrentBroadcast(userID string) (broadcastTyp
 }
 
 // CleanupStaleLocks removes locks older than 2 hours
-func (bc *BroadcastCoordinator) CleanupStaleLocks() {
+func (bc *BroadcastCoordinator) CleanupStaleLocks() error {
 	db := database.GetDB()
 	
 	result, err := db.Exec(`
@@ -203,20 +205,20 @@ func (bc *BroadcastCoordinator) CleanupStaleLocks() {
 			logrus.Infof("Cleaned up %d stale broadcast locks", rowsAffected)
 		}
 	}
+	return err
 }
 
-// StartBroadcastCoordinator starts the coordinator background process
+// StartBroadcastCoordinator schedules the coordinator's stale lock cleanup
 func StartBroadcastCoordinator() {
 	coordinator := NewBroadcastCoordinator()
 	
 	// Cleanup stale locks periodically
-	ticker := time.NewTicker(30 * time.Minute)
-	defer ticker.Stop()
-	
-	for {
-		select {
-		case <-ticker.C:
-			coordinator.CleanupStaleLocks()
-		}
-	}
+	scheduler.Default().MustRegister(scheduler.Job{
+		Name:      "broadcast-lock-cleanup",
+		Interval:  30 * time.Minute,
+		Singleton: true,
+		Run: func(ctx context.Context) error {
+			return coordinator.CleanupStaleLocks()
+		},
+	})
 }
@@ -0,0 +1,824 @@
+package usecase
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/database"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/models"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// Planner rebalance modes
+const (
+	PlannerModeDays    = "days"
+	PlannerModeDevices = "devices"
+)
+
+// PlannerLoad is a block of messages one campaign or sequence puts on a device for a day
+type PlannerLoad struct {
+	Source          string  `json:"source"` // campaign or sequence
+	SourceID        string  `json:"source_id"`
+	Title           string  `json:"title"`
+	Date            string  `json:"date"` // YYYY-MM-DD in planner timezone
+	DeviceID        string  `json:"device_id"`
+	Messages        int     `json:"messages"`
+	AvgDelaySeconds float64 `json:"avg_delay_seconds"`
+	Queued          bool    `json:"queued"` // true when rows already exist in broadcast_messages
+}
+
+// PlannerDevice holds the delay settings the planner needs for a device
+type PlannerDevice struct {
+	ID              string `json:"id"`
+	Name            string `json:"name"`
+	Status          string `json:"status"`
+	Platform        string `json:"platform"`
+	MinDelaySeconds int    `json:"min_delay_seconds"`
+	MaxDelaySeconds int    `json:"max_delay_seconds"`
+}
+
+// PlannerDeviceDay is the forecast for one device on one day
+type PlannerDeviceDay struct {
+	DeviceID         string        `json:"device_id"`
+	DeviceName       string        `json:"device_name"`
+	CampaignMessages int           `json:"campaign_messages"`
+	SequenceMessages int           `json:"sequence_messages"`
+	TotalMessages    int           `json:"total_messages"`
+	EstimatedSeconds int           `json:"estimated_seconds"`
+	WindowSeconds    int           `json:"window_seconds"`
+	Capacity         int           `json:"capacity"`
+	Quota            int           `json:"quota"`
+	Utilization      float64       `json:"utilization"`
+	OverCapacity     bool          `json:"over_capacity"`
+	Loads            []PlannerLoad `json:"loads,omitempty"`
+}
+
+// PlannerDay is the forecast for every device on one day
+type PlannerDay struct {
+	Date          string             `json:"date"`
+	TotalMessages int                `json:"total_messages"`
+	Capacity      int                `json:"capacity"`
+	OverCapacity  bool               `json:"over_capacity"`
+	Devices       []PlannerDeviceDay `json:"devices"`
+}
+
+// PlannerMove is one load-spreading action proposed or applied by Rebalance
+type PlannerMove struct {
+	Mode       string `json:"mode"`
+	Source     string `json:"source"`
+	SourceID   string `json:"source_id"`
+	Title      string `json:"title"`
+	FromDate   string `json:"from_date"`
+	ToDate     string `json:"to_date"`
+	FromDevice string `json:"from_device"`
+	ToDevice   string `json:"to_device"`
+	Messages   int    `json:"messages"`
+}
+
+// BroadcastPlanner forecasts per-device daily load from campaigns and pre-queued
+// sequence steps and checks it against the send window and daily quota
+type BroadcastPlanner struct {
+	db          *sql.DB
+	location    *time.Location
+	windowStart int // minutes after midnight
+	windowEnd   int // minutes after midnight
+	dailyQuota  int
+	horizonDays int
+}
+
+// NewBroadcastPlanner creates a planner using the planner settings from config
+func NewBroadcastPlanner() *BroadcastPlanner {
+	loc, err := time.LoadLocation(config.PlannerTimezone)
+	if err != nil {
+		// Fallback to fixed UTC+8 if timezone data not available
+		loc = time.FixedZone("MYT", 8*60*60)
+	}
+
+	windowStart, err := parseClockMinutes(config.PlannerSendWindowStart)
+	if err != nil {
+		logrus.Warnf("Invalid planner send window start %q, using 08:00", config.PlannerSendWindowStart)
+		windowStart = 8 * 60
+	}
+	windowEnd, err := parseClockMinutes(config.PlannerSendWindowEnd)
+	if err != nil || windowEnd <= windowStart {
+		logrus.Warnf("Invalid planner send window end %q, using 22:00", config.PlannerSendWindowEnd)
+		windowEnd = 22 * 60
+	}
+
+	limits := config.Current().Planner
+	return &BroadcastPlanner{
+		db:          database.GetDB(),
+		location:    loc,
+		windowStart: windowStart,
+		windowEnd:   windowEnd,
+		dailyQuota:  limits.DailyDeviceQuota,
+		horizonDays: limits.HorizonDays,
+	}
+}
+
+// parseClockMinutes parses HH:MM or HH:MM:SS into minutes after midnight
+func parseClockMinutes(value string) (int, error) {
+	parts := strings.Split(strings.TrimSpace(value), ":")
+	if len(parts) < 2 {
+		return 0, fmt.Errorf("invalid time %q", value)
+	}
+	hour, err := strconv.Atoi(parts[0])
+	if err != nil || hour < 0 || hour > 24 {
+		return 0, fmt.Errorf("invalid hour in %q", value)
+	}
+	minute, err := strconv.Atoi(parts[1])
+	if err != nil || minute < 0 || minute > 59 {
+		return 0, fmt.Errorf("invalid minute in %q", value)
+	}
+	return hour*60 + minute, nil
+}
+
+// HorizonDays returns the default number of days to forecast
+func (bp *BroadcastPlanner) HorizonDays() int {
+	return bp.horizonDays
+}
+
+// Today returns the current date in the planner timezone
+func (bp *BroadcastPlanner) Today() time.Time {
+	now := time.Now().In(bp.location)
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, bp.location)
+}
+
+// ParseDate parses a YYYY-MM-DD date in the planner timezone
+func (bp *BroadcastPlanner) ParseDate(value string) (time.Time, error) {
+	return time.ParseInLocation("2006-01-02", value, bp.location)
+}
+
+// windowSeconds returns how long the send window is open each day
+func (bp *BroadcastPlanner) windowSeconds() int {
+	return (bp.windowEnd - bp.windowStart) * 60
+}
+
+// Forecast builds the per-day, per-device forecast for a user
+func (bp *BroadcastPlanner) Forecast(userID string, start time.Time, days int) ([]PlannerDay, error) {
+	if days <= 0 {
+		days = bp.horizonDays
+	}
+	devices, loads, err := bp.collect(userID, start, days)
+	if err != nil {
+		return nil, err
+	}
+	return bp.buildTimeline(start, days, devices, loads), nil
+}
+
+// CheckCampaign returns warnings for every device that is over capacity on the
+// campaign's date. The campaign must already be stored as pending.
+func (bp *BroadcastPlanner) CheckCampaign(userID string, campaignID int, campaignDate string) ([]string, error) {
+	date, err := bp.ParseDate(campaignDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid campaign date: %w", err)
+	}
+
+	timeline, err := bp.Forecast(userID, date, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceID := strconv.Itoa(campaignID)
+	var warnings []string
+	for _, day := range timeline {
+		for _, device := range day.Devices {
+			if !device.OverCapacity || !deviceHasLoad(device, "campaign", sourceID) {
+				continue
+			}
+			warnings = append(warnings, fmt.Sprintf(
+				"Device %s on %s: %d messages forecast (capacity %d, needs %s of a %s window)",
+				device.DeviceName, day.Date, device.TotalMessages, device.Capacity,
+				time.Duration(device.EstimatedSeconds)*time.Second,
+				time.Duration(device.WindowSeconds)*time.Second))
+		}
+	}
+	return warnings, nil
+}
+
+// Rebalance proposes load-spreading moves for over-capacity days and applies
+// them when apply is true. Mode "days" shifts pending campaigns to later days,
+// mode "devices" moves already queued rows to devices with spare capacity.
+func (bp *BroadcastPlanner) Rebalance(userID string, start time.Time, days int, mode string, apply bool) ([]PlannerMove, error) {
+	if days <= 0 {
+		days = bp.horizonDays
+	}
+	devices, loads, err := bp.collect(userID, start, days)
+	if err != nil {
+		return nil, err
+	}
+	timeline := bp.buildTimeline(start, days, devices, loads)
+
+	var moves []PlannerMove
+	switch mode {
+	case PlannerModeDays:
+		moves = planDayShifts(timeline, bp.dailyQuota)
+	case PlannerModeDevices:
+		moves = planDeviceShifts(timeline, devices, bp.dailyQuota)
+	default:
+		return nil, fmt.Errorf("unknown rebalance mode %q", mode)
+	}
+
+	if !apply {
+		return moves, nil
+	}
+
+	applied := make([]PlannerMove, 0, len(moves))
+	for _, move := range moves {
+		if err := bp.applyMove(userID, move, devices); err != nil {
+			logrus.Errorf("Planner failed to apply move %+v: %v", move, err)
+			continue
+		}
+		applied = append(applied, move)
+	}
+	return applied, nil
+}
+
+// buildTimeline turns raw loads into the day/device forecast
+func (bp *BroadcastPlanner) buildTimeline(start time.Time, days int, devices []PlannerDevice, loads []PlannerLoad) []PlannerDay {
+	byDeviceDay := make(map[string][]PlannerLoad)
+	for _, load := range loads {
+		key := load.Date + "|" + load.DeviceID
+		byDeviceDay[key] = append(byDeviceDay[key], load)
+	}
+
+	timeline := make([]PlannerDay, 0, days)
+	for i := 0; i < days; i++ {
+		date := start.AddDate(0, 0, i).Format("2006-01-02")
+		day := PlannerDay{Date: date, Devices: []PlannerDeviceDay{}}
+		for _, device := range devices {
+			deviceDay := forecastDeviceDay(device, byDeviceDay[date+"|"+device.ID], bp.windowSeconds(), bp.dailyQuota)
+			day.TotalMessages += deviceDay.TotalMessages
+			day.Capacity += deviceDay.Capacity
+			if deviceDay.OverCapacity {
+				day.OverCapacity = true
+			}
+			day.Devices = append(day.Devices, deviceDay)
+		}
+		timeline = append(timeline, day)
+	}
+	return timeline
+}
+
+// forecastDeviceDay sums the loads for one device and compares them to the
+// window and quota. Capacity is the number of messages that fit in the window
+// at the device-day's average delay, capped by the quota.
+func forecastDeviceDay(device PlannerDevice, loads []PlannerLoad, windowSeconds, quota int) PlannerDeviceDay {
+	result := PlannerDeviceDay{
+		DeviceID:      device.ID,
+		DeviceName:    device.Name,
+		WindowSeconds: windowSeconds,
+		Quota:         quota,
+		Loads:         loads,
+	}
+
+	var estimated float64
+	for _, load := range loads {
+		if load.Source == "campaign" {
+			result.CampaignMessages += load.Messages
+		} else {
+			result.SequenceMessages += load.Messages
+		}
+		estimated += float64(load.Messages) * load.AvgDelaySeconds
+	}
+	result.TotalMessages = result.CampaignMessages + result.SequenceMessages
+	result.EstimatedSeconds = int(estimated)
+
+	avgDelay := device.avgDelay()
+	if result.TotalMessages > 0 && estimated > 0 {
+		avgDelay = estimated / float64(result.TotalMessages)
+	}
+	result.Capacity = int(float64(windowSeconds) / avgDelay)
+	if quota > 0 && result.Capacity > quota {
+		result.Capacity = quota
+	}
+
+	if result.Capacity > 0 {
+		result.Utilization = float64(result.TotalMessages) / float64(result.Capacity)
+	}
+	result.OverCapacity = result.TotalMessages > result.Capacity
+	return result
+}
+
+// avgDelay returns the average of the device's delay range, falling back to worker defaults
+func (d PlannerDevice) avgDelay() float64 {
+	minDelay, maxDelay := d.MinDelaySeconds, d.MaxDelaySeconds
+	if minDelay <= 0 {
+		minDelay = config.Current().Worker.MinDelaySeconds
+	}
+	if maxDelay < minDelay {
+		maxDelay = minDelay
+	}
+	return float64(minDelay+maxDelay) / 2
+}
+
+// isAvailable reports whether the device can take moved messages
+func (d PlannerDevice) isAvailable() bool {
+	if d.Platform != "" {
+		return true
+	}
+	switch strings.ToLower(d.Status) {
+	case "online", "connected":
+		return true
+	}
+	return false
+}
+
+// deviceHasLoad reports whether the device-day carries load from the given source
+func deviceHasLoad(device PlannerDeviceDay, source, sourceID string) bool {
+	for _, load := range device.Loads {
+		if load.Source == source && load.SourceID == sourceID {
+			return true
+		}
+	}
+	return false
+}
+
+// planDayShifts moves whole pending (not yet queued) campaigns off over-capacity
+// days to the first later day where every device the campaign touches has room
+func planDayShifts(timeline []PlannerDay, quota int) []PlannerMove {
+	type slot struct {
+		messages int
+		seconds  float64
+		window   int
+		capacity int
+	}
+	slots := make(map[string]*slot)
+	for _, day := range timeline {
+		for _, device := range day.Devices {
+			slots[day.Date+"|"+device.DeviceID] = &slot{
+				messages: device.TotalMessages,
+				seconds:  float64(device.EstimatedSeconds),
+				window:   device.WindowSeconds,
+				capacity: device.Capacity,
+			}
+		}
+	}
+	fits := func(s *slot, messages int, seconds float64) bool {
+		if quota > 0 && s.messages+messages > quota {
+			return false
+		}
+		return s.seconds+seconds <= float64(s.window)
+	}
+
+	var moves []PlannerMove
+	moved := make(map[string]bool)
+	for i, day := range timeline {
+		for _, device := range day.Devices {
+			current := slots[day.Date+"|"+device.DeviceID]
+			if current.messages <= current.capacity {
+				continue
+			}
+
+			// Move the largest campaigns first so the fewest campaigns are shifted
+			candidates := make([]PlannerLoad, 0)
+			for _, load := range device.Loads {
+				if load.Source == "campaign" && !load.Queued && !moved[load.SourceID] {
+					candidates = append(candidates, load)
+				}
+			}
+			sort.Slice(candidates, func(a, b int) bool {
+				return candidates[a].Messages > candidates[b].Messages
+			})
+
+			for _, candidate := range candidates {
+				if current.messages <= current.capacity {
+					break
+				}
+				// Gather every device-day this campaign touches on the source day
+				var parts []PlannerLoad
+				for _, other := range day.Devices {
+					for _, load := range other.Loads {
+						if load.Source == "campaign" && load.SourceID == candidate.SourceID && !load.Queued {
+							parts = append(parts, load)
+						}
+					}
+				}
+
+				for target := i + 1; target < len(timeline); target++ {
+					targetDate := timeline[target].Date
+					ok := true
+					for _, part := range parts {
+						s := slots[targetDate+"|"+part.DeviceID]
+						if s == nil || !fits(s, part.Messages, float64(part.Messages)*part.AvgDelaySeconds) {
+							ok = false
+							break
+						}
+					}
+					if !ok {
+						continue
+					}
+
+					total := 0
+					for _, part := range parts {
+						seconds := float64(part.Messages) * part.AvgDelaySeconds
+						from := slots[day.Date+"|"+part.DeviceID]
+						to := slots[targetDate+"|"+part.DeviceID]
+						from.messages -= part.Messages
+						from.seconds -= seconds
+						to.messages += part.Messages
+						to.seconds += seconds
+						total += part.Messages
+					}
+					moved[candidate.SourceID] = true
+					moves = append(moves, PlannerMove{
+						Mode:     PlannerModeDays,
+						Source:   "campaign",
+						SourceID: candidate.SourceID,
+						Title:    candidate.Title,
+						FromDate: day.Date,
+						ToDate:   targetDate,
+						Messages: total,
+					})
+					break
+				}
+			}
+		}
+	}
+	return moves
+}
+
+// planDeviceShifts moves the surplus of queued rows on an over-capacity device
+// to available devices with spare capacity on the same day
+func planDeviceShifts(timeline []PlannerDay, devices []PlannerDevice, quota int) []PlannerMove {
+	deviceByID := make(map[string]PlannerDevice, len(devices))
+	for _, device := range devices {
+		deviceByID[device.ID] = device
+	}
+
+	var moves []PlannerMove
+	for _, day := range timeline {
+		spare := make(map[string]int)
+		for _, device := range day.Devices {
+			if deviceByID[device.DeviceID].isAvailable() && device.Capacity > device.TotalMessages {
+				spare[device.DeviceID] = device.Capacity - device.TotalMessages
+			}
+		}
+
+		for _, device := range day.Devices {
+			surplus := device.TotalMessages - device.Capacity
+			if surplus <= 0 {
+				continue
+			}
+
+			queued := 0
+			for _, load := range device.Loads {
+				if load.Queued {
+					queued += load.Messages
+				}
+			}
+			if surplus > queued {
+				surplus = queued
+			}
+
+			// Fill the devices with the most spare room first
+			targets := make([]string, 0, len(spare))
+			for id := range spare {
+				targets = append(targets, id)
+			}
+			sort.Slice(targets, func(a, b int) bool {
+				if spare[targets[a]] == spare[targets[b]] {
+					return targets[a] < targets[b]
+				}
+				return spare[targets[a]] > spare[targets[b]]
+			})
+
+			for _, target := range targets {
+				if surplus <= 0 {
+					break
+				}
+				count := spare[target]
+				if count > surplus {
+					count = surplus
+				}
+				if quota > 0 && count > quota {
+					count = quota
+				}
+				if count <= 0 {
+					continue
+				}
+				spare[target] -= count
+				surplus -= count
+				moves = append(moves, PlannerMove{
+					Mode:       PlannerModeDevices,
+					FromDate:   day.Date,
+					ToDate:     day.Date,
+					FromDevice: device.DeviceID,
+					ToDevice:   target,
+					Messages:   count,
+				})
+			}
+		}
+	}
+	return moves
+}
+
+// collect loads devices and every campaign/sequence load in the range
+func (bp *BroadcastPlanner) collect(userID string, start time.Time, days int) ([]PlannerDevice, []PlannerLoad, error) {
+	devices, err := bp.loadDevices(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// broadcast_messages rows reference devices by id or by name
+	deviceIDs := make(map[string]string, len(devices)*2)
+	for _, device := range devices {
+		deviceIDs[device.ID] = device.ID
+		if device.Name != "" {
+			deviceIDs[device.Name] = device.ID
+		}
+	}
+
+	end := start.AddDate(0, 0, days)
+	campaignLoads, err := bp.loadPendingCampaigns(userID, start, end, devices, deviceIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	queuedLoads, err := bp.loadQueuedMessages(userID, start, end, deviceIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return devices, append(campaignLoads, queuedLoads...), nil
+}
+
+// loadDevices gets the user's devices with their delay settings
+func (bp *BroadcastPlanner) loadDevices(userID string) ([]PlannerDevice, error) {
+	rows, err := bp.db.Query(`
+		SELECT id, device_name, COALESCE(status, ''), COALESCE(platform, ''),
+		       COALESCE(min_delay_seconds, 5), COALESCE(max_delay_seconds, 15)
+		FROM user_devices
+		WHERE user_id = ?
+		ORDER BY device_name
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load devices: %w", err)
+	}
+	defer rows.Close()
+
+	var devices []PlannerDevice
+	for rows.Next() {
+		var device PlannerDevice
+		if err := rows.Scan(&device.ID, &device.Name, &device.Status, &device.Platform,
+			&device.MinDelaySeconds, &device.MaxDelaySeconds); err != nil {
+			continue
+		}
+		devices = append(devices, device)
+	}
+	return devices, rows.Err()
+}
+
+// loadPendingCampaigns forecasts campaigns that have not been triggered yet
+// from the messages the campaign trigger would queue for them
+func (bp *BroadcastPlanner) loadPendingCampaigns(userID string, start, end time.Time, devices []PlannerDevice, deviceIDs map[string]string) ([]PlannerLoad, error) {
+	rows, err := bp.db.Query(`
+		SELECT id, user_id, title, COALESCE(niche, ''), COALESCE(target_status, 'all'),
+		       COALESCE(message, ''), COALESCE(image_url, ''), COALESCE(message_type, ''),
+		       COALESCE(target_groups, ''), campaign_date,
+		       COALESCE(min_delay_seconds, 0), COALESCE(max_delay_seconds, 0)
+		FROM campaigns
+		WHERE user_id = ?
+		AND status = 'pending'
+		AND campaign_date >= ? AND campaign_date < ?
+	`, userID, start.Format("2006-01-02"), end.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load campaigns: %w", err)
+	}
+
+	var campaigns []models.Campaign
+	for rows.Next() {
+		var c models.Campaign
+		var groups string
+		if err := rows.Scan(&c.ID, &c.UserID, &c.Title, &c.Niche, &c.TargetStatus, &c.Message, &c.ImageURL,
+			&c.MessageType, &groups, &c.CampaignDate, &c.MinDelaySeconds, &c.MaxDelaySeconds); err != nil {
+			continue
+		}
+		c.TargetGroups = repository.DecodeGroupTargets(groups)
+		campaigns = append(campaigns, c)
+	}
+	rows.Close()
+
+	deviceDelay := make(map[string]float64, len(devices))
+	for _, device := range devices {
+		deviceDelay[device.ID] = device.avgDelay()
+	}
+
+	var loads []PlannerLoad
+	for i := range campaigns {
+		c := &campaigns[i]
+		messages, err := campaignMessages(c)
+		if errors.Is(err, errNoConnectedDevices) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to forecast campaign %d: %w", c.ID, err)
+		}
+
+		// The trigger queues one message per recipient, whichever device
+		// comes first
+		counts := make(map[string]int)
+		recipients := make(map[string]bool, len(messages))
+		for _, msg := range messages {
+			deviceID, ok := deviceIDs[msg.DeviceID]
+			if !ok || recipients[msg.RecipientPhone] {
+				continue
+			}
+			recipients[msg.RecipientPhone] = true
+			counts[deviceID]++
+		}
+
+		for _, device := range devices {
+			count := counts[device.ID]
+			if count == 0 {
+				continue
+			}
+			avgDelay := float64(c.MinDelaySeconds+c.MaxDelaySeconds) / 2
+			if avgDelay <= 0 {
+				avgDelay = deviceDelay[device.ID]
+			}
+			loads = append(loads, PlannerLoad{
+				Source:          "campaign",
+				SourceID:        strconv.Itoa(c.ID),
+				Title:           c.Title,
+				Date:            plannerDate(c.CampaignDate),
+				DeviceID:        device.ID,
+				Messages:        count,
+				AvgDelaySeconds: avgDelay,
+			})
+		}
+	}
+	return loads, nil
+}
+
+// plannerDate keeps the YYYY-MM-DD part of a date column, which drivers
+// return either as a plain date or as a timestamp at midnight
+func plannerDate(value string) string {
+	if len(value) > len("2006-01-02") {
+		return value[:len("2006-01-02")]
+	}
+	return value
+}
+
+// loadQueuedMessages forecasts rows already in broadcast_messages, which covers
+// triggered campaigns and every pre-queued sequence step
+func (bp *BroadcastPlanner) loadQueuedMessages(userID string, start, end time.Time, deviceIDs map[string]string) ([]PlannerLoad, error) {
+	rows, err := bp.db.Query(`
+		SELECT bm.device_id,
+		       DATE(bm.scheduled_at) AS day,
+		       bm.campaign_id, COALESCE(bm.sequence_id, '') AS sequence_id,
+		       COALESCE(MAX(c.title), MAX(s.name), '') AS title,
+		       COUNT(*) AS messages,
+		       AVG((COALESCE(c.min_delay_seconds, ss.min_delay_seconds, s.min_delay_seconds, 10) +
+		            COALESCE(c.max_delay_seconds, ss.max_delay_seconds, s.max_delay_seconds, 30)) / 2) AS avg_delay
+		FROM broadcast_messages bm
+		LEFT JOIN campaigns c ON bm.campaign_id = c.id
+		LEFT JOIN sequences s ON bm.sequence_id = s.id
+		LEFT JOIN sequence_steps ss ON bm.sequence_stepid = ss.id
+		WHERE bm.user_id = ?
+		AND bm.status = 'pending'
+		AND bm.scheduled_at >= ? AND bm.scheduled_at < ?
+		GROUP BY bm.device_id, DATE(bm.scheduled_at), bm.campaign_id, bm.sequence_id
+	`, userID, start.Format("2006-01-02"), end.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load queued messages: %w", err)
+	}
+	defer rows.Close()
+
+	var loads []PlannerLoad
+	for rows.Next() {
+		var load PlannerLoad
+		var rawDevice string
+		var campaignID sql.NullInt64
+		if err := rows.Scan(&rawDevice, &load.Date, &campaignID, &load.SourceID, &load.Title,
+			&load.Messages, &load.AvgDelaySeconds); err != nil {
+			continue
+		}
+		load.Date = plannerDate(load.Date)
+		load.Source = "sequence"
+		if campaignID.Valid {
+			load.Source = "campaign"
+			load.SourceID = strconv.FormatInt(campaignID.Int64, 10)
+		}
+		deviceID, ok := deviceIDs[rawDevice]
+		if !ok {
+			continue
+		}
+		load.DeviceID = deviceID
+		load.Queued = true
+		loads = append(loads, load)
+	}
+	return loads, rows.Err()
+}
+
+// applyMove writes a single planner move to the database
+func (bp *BroadcastPlanner) applyMove(userID string, move PlannerMove, devices []PlannerDevice) error {
+	switch move.Mode {
+	case PlannerModeDays:
+		from, err := bp.ParseDate(move.FromDate)
+		if err != nil {
+			return err
+		}
+		to, err := bp.ParseDate(move.ToDate)
+		if err != nil {
+			return err
+		}
+		shiftDays := int(to.Sub(from).Hours() / 24)
+
+		var scheduledAt sql.NullTime
+		err = bp.db.QueryRow(`
+			SELECT scheduled_at FROM campaigns WHERE id = ? AND user_id = ? AND status = 'pending'
+		`, move.SourceID, userID).Scan(&scheduledAt)
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if scheduledAt.Valid {
+			scheduledAt.Time = scheduledAt.Time.AddDate(0, 0, shiftDays)
+		}
+
+		_, err = bp.db.Exec(`
+			UPDATE campaigns
+			SET campaign_date = ?, scheduled_at = ?, updated_at = NOW()
+			WHERE id = ? AND user_id = ? AND status = 'pending'
+		`, move.ToDate, scheduledAt, move.SourceID, userID)
+		if err == nil {
+			logrus.Infof("Planner moved campaign %s from %s to %s", move.SourceID, move.FromDate, move.ToDate)
+		}
+		return err
+
+	case PlannerModeDevices:
+		var from, to PlannerDevice
+		for _, device := range devices {
+			if device.ID == move.FromDevice {
+				from = device
+			}
+			if device.ID == move.ToDevice {
+				to = device
+			}
+		}
+		if from.ID == "" || to.ID == "" {
+			return fmt.Errorf("unknown device in move")
+		}
+
+		day, err := bp.ParseDate(move.FromDate)
+		if err != nil {
+			return err
+		}
+
+		// The latest rows of the day move; they are picked first since not
+		// every database can limit an UPDATE
+		rows, err := bp.db.Query(`
+			SELECT id FROM broadcast_messages
+			WHERE user_id = ?
+			AND (device_id = ? OR device_id = ?)
+			AND status = 'pending'
+			AND processing_worker_id IS NULL
+			AND scheduled_at >= ? AND scheduled_at < ?
+			ORDER BY scheduled_at DESC
+			LIMIT ?
+		`, userID, from.ID, from.Name,
+			day.Format("2006-01-02"), day.AddDate(0, 0, 1).Format("2006-01-02"), move.Messages)
+		if err != nil {
+			return err
+		}
+		var ids []interface{}
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err == nil {
+				ids = append(ids, id)
+			}
+		}
+		rows.Close()
+		if len(ids) == 0 {
+			return nil
+		}
+
+		// Keep rows addressed the same way (id or name) they were queued with
+		args := append([]interface{}{from.Name, to.Name, to.ID, to.Name}, ids...)
+		result, err := bp.db.Exec(`
+			UPDATE broadcast_messages
+			SET device_id = CASE WHEN device_id = ? THEN ? ELSE ? END,
+			    device_name = ?,
+			    updated_at = NOW()
+			WHERE id IN (`+strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ")+`)
+			AND status = 'pending'
+			AND processing_worker_id IS NULL
+		`, args...)
+		if err != nil {
+			return err
+		}
+		affected, _ := result.RowsAffected()
+		logrus.Infof("Planner moved %d messages on %s from device %s to %s", affected, move.FromDate, from.Name, to.Name)
+		return nil
+	}
+	return fmt.Errorf("unknown move mode %q", move.Mode)
+}
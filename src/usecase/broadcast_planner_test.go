@@ -0,0 +1,105 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseClockMinutes(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{name: "hours and minutes", value: "08:30", want: 510},
+		{name: "with seconds", value: "22:00:00", want: 1320},
+		{name: "missing minutes", value: "8", wantErr: true},
+		{name: "bad minute", value: "08:75", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseClockMinutes(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestForecastDeviceDay(t *testing.T) {
+	device := PlannerDevice{ID: "d1", Name: "Device 1", MinDelaySeconds: 10, MaxDelaySeconds: 30}
+
+	t.Run("empty day uses device delay for capacity", func(t *testing.T) {
+		got := forecastDeviceDay(device, nil, 3600, 1000)
+		assert.Equal(t, 0, got.TotalMessages)
+		assert.Equal(t, 180, got.Capacity) // 3600s / 20s
+		assert.False(t, got.OverCapacity)
+	})
+
+	t.Run("window bound", func(t *testing.T) {
+		loads := []PlannerLoad{
+			{Source: "campaign", SourceID: "1", Messages: 150, AvgDelaySeconds: 20},
+			{Source: "sequence", SourceID: "s1", Messages: 50, AvgDelaySeconds: 20, Queued: true},
+		}
+		got := forecastDeviceDay(device, loads, 3600, 1000)
+		assert.Equal(t, 150, got.CampaignMessages)
+		assert.Equal(t, 50, got.SequenceMessages)
+		assert.Equal(t, 4000, got.EstimatedSeconds)
+		assert.Equal(t, 180, got.Capacity)
+		assert.True(t, got.OverCapacity)
+	})
+
+	t.Run("quota bound", func(t *testing.T) {
+		loads := []PlannerLoad{{Source: "campaign", SourceID: "1", Messages: 120, AvgDelaySeconds: 1}}
+		got := forecastDeviceDay(device, loads, 3600, 100)
+		assert.Equal(t, 100, got.Capacity)
+		assert.True(t, got.OverCapacity)
+	})
+}
+
+func TestPlanDayShifts(t *testing.T) {
+	device := PlannerDevice{ID: "d1", Name: "Device 1", MinDelaySeconds: 10, MaxDelaySeconds: 10}
+	overloaded := []PlannerLoad{
+		{Source: "campaign", SourceID: "1", Title: "Big", Date: "2026-01-01", DeviceID: "d1", Messages: 300, AvgDelaySeconds: 10},
+		{Source: "campaign", SourceID: "2", Title: "Small", Date: "2026-01-01", DeviceID: "d1", Messages: 100, AvgDelaySeconds: 10},
+	}
+	timeline := []PlannerDay{
+		{Date: "2026-01-01", Devices: []PlannerDeviceDay{forecastDeviceDay(device, overloaded, 3600, 1000)}},
+		{Date: "2026-01-02", Devices: []PlannerDeviceDay{forecastDeviceDay(device, nil, 3600, 1000)}},
+	}
+
+	moves := planDayShifts(timeline, 1000)
+	if assert.Len(t, moves, 1) {
+		assert.Equal(t, "1", moves[0].SourceID)
+		assert.Equal(t, "2026-01-02", moves[0].ToDate)
+		assert.Equal(t, 300, moves[0].Messages)
+	}
+}
+
+func TestPlanDeviceShifts(t *testing.T) {
+	busy := PlannerDevice{ID: "d1", Name: "Busy", Status: "online", MinDelaySeconds: 10, MaxDelaySeconds: 10}
+	idle := PlannerDevice{ID: "d2", Name: "Idle", Status: "online", MinDelaySeconds: 10, MaxDelaySeconds: 10}
+	offline := PlannerDevice{ID: "d3", Name: "Offline", Status: "offline", MinDelaySeconds: 10, MaxDelaySeconds: 10}
+
+	loads := []PlannerLoad{{Source: "sequence", SourceID: "s1", DeviceID: "d1", Messages: 500, AvgDelaySeconds: 10, Queued: true}}
+	timeline := []PlannerDay{{
+		Date: "2026-01-01",
+		Devices: []PlannerDeviceDay{
+			forecastDeviceDay(busy, loads, 3600, 1000),
+			forecastDeviceDay(idle, nil, 3600, 1000),
+			forecastDeviceDay(offline, nil, 3600, 1000),
+		},
+	}}
+
+	moves := planDeviceShifts(timeline, []PlannerDevice{busy, idle, offline}, 1000)
+	if assert.Len(t, moves, 1) {
+		assert.Equal(t, "d1", moves[0].FromDevice)
+		assert.Equal(t, "d2", moves[0].ToDevice)
+		assert.Equal(t, 140, moves[0].Messages) // 500 - 360 capacity
+	}
+}
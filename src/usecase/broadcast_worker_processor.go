@@ -1,8 +1,14 @@
 package usecase
 
 import (
+	"context"
+	"fmt"
 	"time"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainBroadcast "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/broadcast"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/broadcast"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/cluster"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/scheduler"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
 	"github.com/sirupsen/logrus"
 )
@@ -19,29 +25,31 @@ func StartBroadcastWorkerProcessor() {
 	broadcastManager := broadcast.GetBroadcastManager()
 	
 	// Process messages every 5 seconds
-	ticker := time.NewTicker(5 * time.Second)
-	defer ticker.Stop()
-	
-	for {
-		select {
-		case <-ticker.C:
+	scheduler.Default().MustRegister(scheduler.Job{
+		Name:     "broadcast-worker-processor",
+		Interval: 5 * time.Second,
+		Run: func(ctx context.Context) error {
 			// Get all devices with pending messages
 			devices, err := broadcastRepo.GetDevicesWithPendingMessages()
 			if err != nil {
-				logrus.Errorf("Failed to get devices with pending messages: %v", err)
-				continue
+				return fmt.Errorf("failed to get devices with pending messages: %w", err)
 			}
 			
 			if len(devices) == 0 {
-				continue // No pending messages
+				return nil // No pending messages
 			}
 			
 			// logrus.Infof("Found %d devices with pending messages", len(devices))
 			
 			// Process each device
 			for _, deviceID := range devices {
+				// Another replica sends for devices it owns
+				if !cluster.Owns(deviceID) {
+					continue
+				}
+				
 				// Get pending messages for this device
-				messages, err := broadcastRepo.GetPendingMessagesAndLock(deviceID, 100)
+				messages, err := broadcastRepo.GetPendingMessagesAndLock(deviceID, config.Current().Worker.BatchSize)
 				if err != nil {
 					logrus.Errorf("Failed to get pending messages for device %s: %v", deviceID, err)
 					continue
@@ -67,8 +75,8 @@ func StartBroadcastWorkerProcessor() {
 					err := broadcastManager.QueueMessage(&msg)
 					if err != nil {
 						logrus.Errorf("Failed to queue message %s to worker pool: %v", msg.ID, err)
-						// Update status to failed if can't queue
-						broadcastRepo.UpdateMessageStatus(msg.ID, "failed", err.Error())
+						// Mark it failed with the error's class if it can't be queued
+						broadcastRepo.MarkMessageFailed(msg.ID, err, domainBroadcast.DeadLetterSourceSQL, msg.RetryCount)
 					} else {
 						logrus.Debugf("Message %s queued to worker pool for device %s", msg.ID, deviceID)
 					}
@@ -76,6 +84,7 @@ func StartBroadcastWorkerProcessor() {
 				
 				logrus.Infof("Queued %d messages to worker pool for device %s", len(messages), deviceID)
 			}
-		}
-	}
+			return nil
+		},
+	})
 }
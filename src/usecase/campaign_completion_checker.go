@@ -1,28 +1,28 @@
 package usecase
 
 import (
+	"context"
+	"fmt"
 	"time"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/database"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/scheduler"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
 	"github.com/sirupsen/logrus"
 )
 
-// StartCampaignCompletionChecker monitors campaigns and updates their status correctly
+// StartCampaignCompletionChecker schedules the check that marks finished campaigns
 func StartCampaignCompletionChecker() {
-	ticker := time.NewTicker(1 * time.Minute) // Check every minute
-	defer ticker.Stop()
-
-	logrus.Info("Campaign completion checker started")
-
-	for {
-		select {
-		case <-ticker.C:
-			checkCampaignCompletions()
-		}
-	}
+	scheduler.Default().MustRegister(scheduler.Job{
+		Name:      "campaign-completion-checker",
+		Interval:  1 * time.Minute, // Check every minute
+		Singleton: true,
+		Run: func(ctx context.Context) error {
+			return checkCampaignCompletions()
+		},
+	})
 }
 
-func checkCampaignCompletions() {
+func checkCampaignCompletions() error {
 	db := database.GetDB()
 	campaignRepo := repository.GetCampaignRepository()
 
@@ -35,8 +35,7 @@ func checkCampaignCompletions() {
 
 	rows, err := db.Query(query)
 	if err != nil {
-		logrus.Errorf("Failed to get triggered campaigns: %v", err)
-		return
+		return fmt.Errorf("failed to get triggered campaigns: %w", err)
 	}
 	defer rows.Close()
 
@@ -90,4 +89,5 @@ func checkCampaignCompletions() {
 			logrus.Debugf("Campaign '%s' still has %d pending messages", title, pendingCount)
 		}
 	}
+	return nil
 }
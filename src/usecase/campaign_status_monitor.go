@@ -1,28 +1,32 @@
 package usecase
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
 	"time"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/database"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/scheduler"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/models"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/clock"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
 	"github.com/sirupsen/logrus"
 )
 
-// StartCampaignStatusMonitor monitors campaign progress and updates status
+// StartCampaignStatusMonitor schedules the monitor that moves campaigns
+// through their statuses as their messages are sent
 func StartCampaignStatusMonitor() {
-	logrus.Info("Starting campaign status monitor...")
-	
-	ticker := time.NewTicker(10 * time.Second) // Check every 10 seconds
-	defer ticker.Stop()
-	
-	for {
-		select {
-		case <-ticker.C:
-			updateCampaignStatuses()
-		}
-	}
+	scheduler.Default().MustRegister(scheduler.Job{
+		Name:      "campaign-status-monitor",
+		Interval:  10 * time.Second, // Check every 10 seconds
+		Singleton: true,
+		Run: func(ctx context.Context) error {
+			return updateCampaignStatuses()
+		},
+	})
 }
 
-func updateCampaignStatuses() {
+func updateCampaignStatuses() error {
 	db := database.GetDB()
 	
 	// Find ALL campaigns that need status check
@@ -42,8 +46,7 @@ func updateCampaignStatuses() {
 		)
 	`)
 	if err != nil {
-		logrus.Errorf("Failed to get campaigns for status update: %v", err)
-		return
+		return fmt.Errorf("failed to get campaigns for status update: %w", err)
 	}
 	defer rows.Close()
 	
@@ -100,7 +103,7 @@ func updateCampaignStatuses() {
 			// No messages created yet but should have been triggered
 			var scheduledAt time.Time
 			err = db.QueryRow(`SELECT scheduled_at FROM campaigns WHERE id = ?`, campaignID).Scan(&scheduledAt)
-			if err == nil && scheduledAt.Before(time.Now()) {
+			if err == nil && scheduledAt.Before(clock.Now()) {
 				// Campaign should have been triggered but wasn't - mark as failed
 				newStatus = "failed"
 				logrus.Errorf("Campaign %d should have been triggered at %v but has no messages", 
@@ -127,17 +130,30 @@ func updateCampaignStatuses() {
 				campaignID, queued)
 		}
 		
-		// Update status if changed
+		// Update status if changed, unless a user paused or cancelled the campaign meanwhile
 		if newStatus != "" && newStatus != currentStatus {
-			_, err = db.Exec(`
+			var result sql.Result
+			result, err = db.Exec(`
 				UPDATE campaigns SET status = ?, updated_at = CURRENT_TIMESTAMP 
-				WHERE id = ?
-			`, newStatus, campaignID)
+				WHERE id = ? AND status = ?
+			`, newStatus, campaignID, currentStatus)
 			
 			if err != nil {
 				logrus.Errorf("Failed to update campaign %d status to %s: %v", 
 					campaignID, newStatus, err)
-			} else {
+			} else if changed, _ := result.RowsAffected(); changed > 0 {
+				err = repository.GetCampaignRepository().RecordCampaignEvent(models.CampaignEvent{
+					CampaignID: campaignID,
+					Actor:      repository.CampaignActorSystem,
+					Action:     repository.CampaignActionStatus,
+					FromStatus: currentStatus,
+					ToStatus:   newStatus,
+					Messages:   total,
+				})
+				if err != nil {
+					logrus.Errorf("Campaign %d: %v", campaignID, err)
+				}
+				
 				// Calculate progress percentage
 				processed := sent + failed + skipped
 				progress := 0
@@ -161,4 +177,5 @@ func updateCampaignStatuses() {
 			}
 		}
 	}
+	return nil
 }
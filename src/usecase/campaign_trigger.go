@@ -1,12 +1,15 @@
 package usecase
 
 import (
+	"context"
+	"fmt"
 	"time"
 
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/database"
 	domainBroadcast "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/broadcast"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/broadcast"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/models"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/tracing"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
 	"github.com/sirupsen/logrus"
 )
@@ -58,6 +61,11 @@ func (cts *CampaignTriggerService) ProcessCampaignTriggers() error {
 func (cts *CampaignTriggerService) executeCampaign(campaign *models.Campaign) {
 	logrus.Infof("Executing campaign: %s", campaign.Title)
 	
+	// Every queued message continues this trace through the worker to the send and its receipts
+	ctx, span := tracing.Start(context.Background(), "campaign.trigger", tracing.KindInternal,
+		tracing.Int("campaign.id", int64(campaign.ID)), tracing.String("user.id", campaign.UserID))
+	defer span.End()
+	
 	// Get ALL connected devices for the user FIRST
 	userRepo := repository.GetUserRepository()
 	devices, err := userRepo.GetUserDevices(campaign.UserID)
@@ -84,6 +92,7 @@ func (cts *CampaignTriggerService) executeCampaign(campaign *models.Campaign) {
 	
 	if len(connectedDevices) == 0 {
 		logrus.Errorf("No connected devices found for user %s", campaign.UserID)
+		span.RecordError(fmt.Errorf("no connected devices"))
 		// Update campaign status to failed immediately
 		campaignRepo := repository.GetCampaignRepository()
 		if err := campaignRepo.UpdateCampaignStatus(campaign.ID, "failed"); err != nil {
@@ -149,21 +158,22 @@ func (cts *CampaignTriggerService) executeCampaign(campaign *models.Campaign) {
 				CampaignID:     &campaign.ID,
 				RecipientPhone: lead.Phone,
 				RecipientName:  lead.Name,
-				Type:           "text", // Default to text, or determine from ImageURL
+				Type:           domainBroadcast.ResolveMessageType(campaign.MessageType, campaign.ImageURL),
 				Content:        campaign.Message,
 				MediaURL:       campaign.ImageURL,
+				Payload:        campaign.Payload,
 				ScheduledAt:    time.Now(),
 				Status:         "pending",
 				// MinDelay and MaxDelay removed - will be fetched from campaigns table during processing
 			}
 			
-			// If image URL is provided, set type to image
-			if campaign.ImageURL != "" {
-				msg.Type = "image"
-			}
-			
 			// Queue the message
+			_, enqueue := tracing.Start(ctx, "message.enqueue", tracing.KindProducer,
+				tracing.String("device.id", device.ID), tracing.String("message.type", msg.Type))
+			msg.TraceContext = enqueue.Traceparent()
 			err = broadcastRepo.QueueMessage(msg)
+			enqueue.RecordError(err)
+			enqueue.End()
 			if err != nil {
 				logrus.Errorf("Failed to queue message for %s: %v", lead.Phone, err)
 				failed++
@@ -172,6 +182,7 @@ func (cts *CampaignTriggerService) executeCampaign(campaign *models.Campaign) {
 			}
 		}
 	}
+	span.SetAttributes(tracing.Int("messages.queued", int64(successful)), tracing.Int("messages.failed", int64(failed)))
 	
 	// Update campaign status based on results
 	campaignRepo := repository.GetCampaignRepository()
@@ -322,6 +333,7 @@ func (cts *CampaignTriggerService) ProcessDailySequenceMessages() error {
 				Type:           nextStep.MessageType,
 				Content:        nextStep.Content,
 				MediaURL:       nextStep.MediaURL,
+				Payload:        nextStep.Payload,
 				ScheduledAt:    time.Now(),
 				MinDelay:       5,  // Default delays for sequences
 				MaxDelay:       15,
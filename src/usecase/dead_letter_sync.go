@@ -0,0 +1,39 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/scheduler"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
+	"github.com/sirupsen/logrus"
+)
+
+var startDeadLetterSyncOnce sync.Once
+
+// StartDeadLetterSync periodically records failed messages that reached the
+// failed state without going through the dead letter store (cleanup jobs,
+// manual updates), so every failure shows up in the dead letter API
+func StartDeadLetterSync() {
+	startDeadLetterSyncOnce.Do(func() {
+		scheduler.Default().MustRegister(scheduler.Job{
+			Name:      "dead-letter-sync",
+			Interval:  5 * time.Minute,
+			Singleton: true,
+			Run: func(ctx context.Context) error {
+				count, err := repository.GetDeadLetterRepository().SyncFailedMessages(1000)
+				if err != nil {
+					return fmt.Errorf("dead letter sync failed: %w", err)
+				}
+				if count > 0 {
+					logrus.Infof("Dead letter sync recorded %d failed messages", count)
+				}
+				return nil
+			},
+		})
+
+		logrus.Info("Dead letter sync started")
+	})
+}
@@ -3,12 +3,12 @@ package usecase
 import (
 	"database/sql"
 	"fmt"
-	"strings"
 	"sync"
 	"time"
 
 	domainBroadcast "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/broadcast"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/models"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/clock"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
 	"github.com/sirupsen/logrus"
 )
@@ -22,6 +22,8 @@ type DirectBroadcastProcessor struct {
 
 // NewDirectBroadcastProcessor creates new processor
 func NewDirectBroadcastProcessor(db *sql.DB) *DirectBroadcastProcessor {
+	repository.CampaignTargetGroupsSchema.Ensure(db)
+	repository.LeadNumberSchema.Ensure(db)
 	return &DirectBroadcastProcessor{
 		db:        db,
 		batchSize: 100,
@@ -44,6 +46,7 @@ func (p *DirectBroadcastProcessor) ProcessDirectEnrollments() (int, error) {
 			AND l.device_id IS NOT NULL 
 			AND l.user_id IS NOT NULL
 			AND position(ss.trigger in l.trigger) > 0
+			AND COALESCE(l.number_status, '') <> 'invalid'
 			AND NOT EXISTS (
 				SELECT 1 FROM broadcast_messages bm
 				WHERE bm.sequence_id = s.id 
@@ -95,9 +98,12 @@ func (p *DirectBroadcastProcessor) ProcessCampaigns() (int, error) {
 		SELECT c.id, c.user_id, c.title, c.message, c.niche, 
 			COALESCE(c.target_status, 'all') AS target_status, 
 			COALESCE(c.image_url, '') AS image_url, 
+			COALESCE(c.message_type, '') AS message_type, COALESCE(c.message_payload, '') AS message_payload,
 			c.min_delay_seconds, c.max_delay_seconds
 		FROM campaigns c
 		WHERE c.status = 'pending'
+		-- Group campaigns are queued by the campaign trigger only
+		AND (c.target_groups IS NULL OR c.target_groups = '')
 		AND (
 			(c.scheduled_at IS NOT NULL AND c.scheduled_at <= NOW())
 			OR
@@ -116,15 +122,18 @@ func (p *DirectBroadcastProcessor) ProcessCampaigns() (int, error) {
 	processedCount := 0
 	for rows.Next() {
 		var campaign models.Campaign
+		var payload string
 		err := rows.Scan(
 			&campaign.ID, &campaign.UserID, &campaign.Title, &campaign.Message,
 			&campaign.Niche, &campaign.TargetStatus, &campaign.ImageURL,
+			&campaign.MessageType, &payload,
 			&campaign.MinDelaySeconds, &campaign.MaxDelaySeconds,
 		)
 		if err != nil {
 			logrus.Errorf("Failed to scan campaign: %v", err)
 			continue
 		}
+		campaign.Payload = domainBroadcast.DecodePayload(payload)
 
 		logrus.Infof("Processing campaign: %s (ID: %d)", campaign.Title, campaign.ID)
 		
@@ -165,6 +174,8 @@ func (p *DirectBroadcastProcessor) processCampaignDirect(campaign *models.Campai
 		-- Device status check removed to allow campaigns to work with offline devices
 		AND l.niche LIKE CONCAT('%', ?, '%')
 		AND (? = 'all' OR l.target_status = ?)
+		-- Numbers known not to be on WhatsApp are never targeted
+		AND COALESCE(l.number_status, '') <> 'invalid'
 		AND NOT EXISTS (
 			SELECT 1 FROM broadcast_messages bm
 			WHERE bm.campaign_id = ?
@@ -200,13 +211,14 @@ func (p *DirectBroadcastProcessor) processCampaignDirect(campaign *models.Campai
 			CampaignID:     &campaign.ID,
 			RecipientPhone: phone,
 			RecipientName:  name,
-			Type:           "text",
+			Type:           domainBroadcast.ResolveMessageType(campaign.MessageType, campaign.ImageURL),
 			Message:        campaign.Message,
 			Content:        campaign.Message,
 			MediaURL:       campaign.ImageURL,
+			Payload:        campaign.Payload,
 			MinDelay:       campaign.MinDelaySeconds,
 			MaxDelay:       campaign.MaxDelaySeconds,
-			ScheduledAt:    time.Now().Add(5 * time.Minute).Add(8 * time.Hour),
+			ScheduledAt:    clock.Now().Add(5 * time.Minute).Add(8 * time.Hour),
 			Status:         "pending",
 		}
 		
@@ -219,179 +231,15 @@ func (p *DirectBroadcastProcessor) processCampaignDirect(campaign *models.Campai
 	
 	return enrolledCount, nil
 }
-// enrollDirectBroadcast creates all messages directly in broadcast_messages
+// enrollDirectBroadcast enrolls a lead at the entry step of a sequence
 func (p *DirectBroadcastProcessor) enrollDirectBroadcast(sequenceID string, lead models.Lead, trigger string) error {
-	tx, err := p.db.Begin()
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer tx.Rollback()
-
-	// Get broadcast repository
-	broadcastRepo := repository.GetBroadcastRepository()
-	
-	// Track all messages to create
-	var allMessages []domainBroadcast.BroadcastMessage
-	scheduledAt := time.Now().Add(24 * time.Hour).Add(5 * time.Minute) // First message in 24 hours + 5 minutes
-	currentSequenceID := sequenceID
-	originalSequenceID := sequenceID  // Keep track of the original sequence
-	processedSequences := make(map[string]bool)
-
-	// Process sequence chain (COLD → WARM → HOT)
-	for currentSequenceID != "" {
-		// Prevent infinite loops
-		if processedSequences[currentSequenceID] {
-			break
-		}
-		processedSequences[currentSequenceID] = true
-
-		// Get sequence info
-		var sequenceName string
-		var minDelay, maxDelay int
-		err := p.db.QueryRow(`
-			SELECT name, COALESCE(min_delay_seconds, 5), COALESCE(max_delay_seconds, 15)
-			FROM sequences WHERE id = ?
-		`, currentSequenceID).Scan(&sequenceName, &minDelay, &maxDelay)
-		
-		if err != nil {
-			logrus.Debugf("Failed to get sequence %s: %v", currentSequenceID, err)
-			break
-		}
-
-		// Get all steps for this sequence
-		stepsQuery := `
-			SELECT id, day_number, ` + "`trigger`" + `, next_trigger, trigger_delay_hours,
-				   message_type, content, media_url, 
-				   COALESCE(min_delay_seconds, ?) AS min_delay,
-				   COALESCE(max_delay_seconds, ?) AS max_delay
-			FROM sequence_steps
-			WHERE sequence_id = ?
-			ORDER BY day_number ASC
-		`
-		
-		rows, err := p.db.Query(stepsQuery, minDelay, maxDelay, currentSequenceID)
-		if err != nil {
-			return fmt.Errorf("failed to get steps: %w", err)
-		}
-
-		var lastStepNextTrigger string
-		for rows.Next() {
-			var step struct {
-				ID                string
-				DayNumber         int
-				Trigger           sql.NullString
-				NextTrigger       sql.NullString
-				TriggerDelayHours int
-				MessageType       string
-				Content           string
-				MediaURL          sql.NullString
-				MinDelay          int
-				MaxDelay          int
-			}
-			
-			err := rows.Scan(&step.ID, &step.DayNumber, &step.Trigger, 
-				&step.NextTrigger, &step.TriggerDelayHours,
-				&step.MessageType, &step.Content, &step.MediaURL,
-				&step.MinDelay, &step.MaxDelay)
-			if err != nil {
-				logrus.Warnf("Error scanning step: %v", err)
-				continue
-			}
-
-			// Skip if step ID is empty
-			if step.ID == "" {
-				logrus.Warnf("Skipping step with empty ID for sequence %s", currentSequenceID)
-				continue
-			}
-
-			// Validate sequence ID is not empty
-			if currentSequenceID == "" {
-				logrus.Errorf("Current sequence ID is empty - skipping message creation")
-				continue
-			}
-
-			// Create broadcast message with proper sequence references
-			sequenceIDToUse := currentSequenceID
-			if sequenceIDToUse == "" {
-				sequenceIDToUse = originalSequenceID
-			}
-			
-			msg := domainBroadcast.BroadcastMessage{
-				UserID:         lead.UserID,
-				DeviceID:       lead.DeviceName, // Use device_name for message sending
-				DeviceName:     lead.DeviceName,
-				SequenceID:     &sequenceIDToUse,
-				SequenceStepID: &step.ID,
-				RecipientPhone: lead.Phone,
-				RecipientName:  lead.Name,
-				Message:        step.Content,
-				Content:        step.Content,
-				Type:           step.MessageType,
-				MinDelay:       step.MinDelay,
-				MaxDelay:       step.MaxDelay,
-				ScheduledAt:    scheduledAt,
-				Status:         "pending",
-			}
-
-			// Handle media URL
-			if step.MediaURL.Valid && step.MediaURL.String != "" {
-				msg.MediaURL = step.MediaURL.String
-				msg.ImageURL = step.MediaURL.String
-			}
-
-			allMessages = append(allMessages, msg)
-
-			// Calculate next scheduled time
-			if step.TriggerDelayHours > 0 {
-				scheduledAt = scheduledAt.Add(time.Duration(step.TriggerDelayHours) * time.Hour)
-			} else {
-				scheduledAt = scheduledAt.Add(24 * time.Hour) // Default 24 hours
-			}
-
-			// Track last step's next trigger
-			if step.NextTrigger.Valid {
-				lastStepNextTrigger = step.NextTrigger.String
-			}
-
-			logrus.Debugf("Prepared message for %s - %s Step %d",
-				lead.Phone, sequenceName, step.DayNumber)
-		}
-		rows.Close()
-
-		// Find next linked sequence
-		currentSequenceID = ""
-		if lastStepNextTrigger != "" && !strings.Contains(lastStepNextTrigger, "_day") {
-			var nextSequenceID string
-			err := p.db.QueryRow(`
-				SELECT s.id FROM sequences s
-				INNER JOIN sequence_steps ss ON ss.sequence_id = s.id
-				WHERE ss.is_entry_point = true AND ss.` + "`trigger`" + ` = ?
-				LIMIT 1
-			`, lastStepNextTrigger).Scan(&nextSequenceID)
-			
-			if err == nil {
-				currentSequenceID = nextSequenceID
-				logrus.Debugf("Found linked sequence with trigger '%s'", lastStepNextTrigger)
-			}
-		}
-	}
-
-	// Insert all messages using repository
-	for _, msg := range allMessages {
-		err := broadcastRepo.QueueMessage(msg)
-		if err != nil {
-			logrus.Errorf("Failed to queue message for %s: %v", msg.RecipientPhone, err)
-			return fmt.Errorf("failed to queue message: %w", err)
-		}
-	}
-
-	// Commit transaction
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit: %w", err)
+	// Only the contact's position and the entry step are stored here; later
+	// steps and linked sequences are queued as each message is sent
+	if err := NewSequenceStepMaterializer(p.db).Enroll(sequenceID, lead); err != nil {
+		return fmt.Errorf("failed to enroll: %w", err)
 	}
 
-	logrus.Infof("✅ Direct enrollment successful for %s - Created %d messages",
-		lead.Phone, len(allMessages))
+	logrus.Infof("✅ Direct enrollment successful for %s - sequence %s", lead.Phone, sequenceID)
 
 	// Remove trigger from lead after successful enrollment
 	p.removeCompletedTrigger(lead.Phone, trigger)
@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/database"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/metrics"
+	"github.com/sirupsen/logrus"
+)
+
+// metricsQueryTTL bounds how often a scrape hits the database for the
+// queue and device collectors
+const metricsQueryTTL = 10 * time.Second
+
+var registerMetricsOnce sync.Once
+
+// RegisterMetricsCollectors adds the collectors whose values are read from
+// the database and the WhatsApp client manager at scrape time
+func RegisterMetricsCollectors() {
+	registerMetricsOnce.Do(func() {
+		metrics.NewGaugeFunc("broadcast_queue_depth",
+			"Messages waiting to be sent, by device and status.",
+			cachedSamples(queueDepthSamples), "device", "status")
+		metrics.NewGaugeFunc("whatsapp_devices",
+			"Registered devices by stored status and platform.",
+			cachedSamples(deviceStateSamples), "status", "platform")
+		metrics.NewGaugeFunc("whatsapp_clients",
+			"WhatsApp clients held in memory; connected and logged_in are subsets of total.",
+			clientStateSamples, "state")
+
+		metrics.NewGaugeFunc("db_connections", "Database pool connections by state.", func() []metrics.Sample {
+			stats := database.GetDB().Stats()
+			return []metrics.Sample{
+				{LabelValues: []string{"open"}, Value: float64(stats.OpenConnections)},
+				{LabelValues: []string{"in_use"}, Value: float64(stats.InUse)},
+				{LabelValues: []string{"idle"}, Value: float64(stats.Idle)},
+			}
+		}, "state")
+		metrics.NewGaugeFunc("db_max_open_connections", "Configured database pool size limit.", func() []metrics.Sample {
+			return []metrics.Sample{{Value: float64(database.GetDB().Stats().MaxOpenConnections)}}
+		})
+		metrics.NewCounterFunc("db_wait_count_total", "Connections waited for because the pool was exhausted.", func() []metrics.Sample {
+			return []metrics.Sample{{Value: float64(database.GetDB().Stats().WaitCount)}}
+		})
+		metrics.NewCounterFunc("db_wait_duration_seconds_total", "Time spent waiting for a pool connection.", func() []metrics.Sample {
+			return []metrics.Sample{{Value: database.GetDB().Stats().WaitDuration.Seconds()}}
+		})
+	})
+}
+
+// cachedSamples reuses the last result of query for metricsQueryTTL, and
+// keeps serving it if a later query fails
+func cachedSamples(query func(db *sql.DB) ([]metrics.Sample, error)) func() []metrics.Sample {
+	var (
+		mu      sync.Mutex
+		samples []metrics.Sample
+		fetched time.Time
+	)
+	return func() []metrics.Sample {
+		mu.Lock()
+		defer mu.Unlock()
+		if time.Since(fetched) < metricsQueryTTL {
+			return samples
+		}
+		fresh, err := query(database.GetDB())
+		if err != nil {
+			logrus.Warnf("Failed to collect metrics: %v", err)
+			return samples
+		}
+		samples, fetched = fresh, time.Now()
+		return samples
+	}
+}
+
+func queueDepthSamples(db *sql.DB) ([]metrics.Sample, error) {
+	return groupedCounts(db, `
+		SELECT COALESCE(device_name, ''), status, COUNT(*)
+		FROM broadcast_messages
+		WHERE status IN ('pending', 'queued', 'processing')
+		GROUP BY device_name, status
+	`)
+}
+
+func deviceStateSamples(db *sql.DB) ([]metrics.Sample, error) {
+	return groupedCounts(db, `
+		SELECT COALESCE(status, ''), COALESCE(NULLIF(platform, ''), 'whatsapp_web'), COUNT(*)
+		FROM user_devices
+		GROUP BY 1, 2
+	`)
+}
+
+// groupedCounts reads rows of two label values followed by a count
+func groupedCounts(db *sql.DB, query string) ([]metrics.Sample, error) {
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var samples []metrics.Sample
+	for rows.Next() {
+		var first, second string
+		var count int64
+		if err := rows.Scan(&first, &second, &count); err != nil {
+			return nil, err
+		}
+		samples = append(samples, metrics.Sample{LabelValues: []string{first, second}, Value: float64(count)})
+	}
+	return samples, rows.Err()
+}
+
+func clientStateSamples() []metrics.Sample {
+	var total, connected, loggedIn float64
+	for _, client := range whatsapp.GetClientManager().GetAllClients() {
+		total++
+		if client.IsConnected() {
+			connected++
+		}
+		if client.IsLoggedIn() {
+			loggedIn++
+		}
+	}
+	return []metrics.Sample{
+		{LabelValues: []string{"total"}, Value: total},
+		{LabelValues: []string{"connected"}, Value: connected},
+		{LabelValues: []string{"logged_in"}, Value: loggedIn},
+	}
+}
@@ -0,0 +1,193 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/scheduler"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/models"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/clock"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
+	"github.com/sirupsen/logrus"
+	"go.mau.fi/whatsmeow/types"
+)
+
+var startNumberValidationOnce sync.Once
+
+// StartNumberValidation checks the numbers of new leads with WhatsApp in the
+// background, so targeting can skip numbers that are not on it before a
+// broadcast fails on them. Every replica checks the leads of the devices it
+// holds, through the lead's own device.
+func StartNumberValidation() {
+	if !config.NumberValidationEnabled {
+		logrus.Info("Number validation is disabled")
+		return
+	}
+	startNumberValidationOnce.Do(func() {
+		scheduler.Default().MustRegister(scheduler.Job{
+			Name:     "number-validation",
+			Interval: time.Minute,
+			Jitter:   10 * time.Second,
+			Run: func(ctx context.Context) error {
+				count, err := ValidateLeadNumbers(ctx)
+				if err != nil {
+					return fmt.Errorf("number validation failed: %w", err)
+				}
+				if count > 0 {
+					logrus.Infof("Number validation checked %d leads", count)
+				}
+				return nil
+			},
+		})
+
+		logrus.Info("Number validation started")
+	})
+}
+
+// numberChecker asks WhatsApp which of the phones, given with a "+", are registered
+type numberChecker func(phones []string) ([]types.IsOnWhatsAppResponse, error)
+
+// ValidateLeadNumbers checks one batch of pending leads of each connected
+// device, returning how many leads were marked
+func ValidateLeadNumbers(ctx context.Context) (int, error) {
+	repo := repository.GetNumberValidationRepository()
+	marked := 0
+	for deviceID, client := range whatsapp.GetClientManager().GetAllClients() {
+		if ctx.Err() != nil {
+			break
+		}
+		if !client.IsLoggedIn() {
+			continue
+		}
+		count, err := validateDeviceNumbers(repo, deviceID, client.IsOnWhatsApp)
+		if err != nil {
+			logrus.Warnf("Number validation of device %s failed: %v", deviceID, err)
+			continue
+		}
+		marked += count
+	}
+	return marked, nil
+}
+
+// validateDeviceNumbers checks one batch of a device's pending leads, using
+// cached results where they have not expired
+func validateDeviceNumbers(repo *repository.NumberValidationRepository, deviceID string, check numberChecker) (int, error) {
+	now := clock.Now()
+	settings := config.Current().NumberValidation
+	pending, err := repo.PendingLeads(deviceID, settings.BatchSize,
+		now.AddDate(0, 0, -settings.ValidTTLDays),
+		now.AddDate(0, 0, -settings.InvalidTTLDays))
+	if err != nil || len(pending) == 0 {
+		return 0, err
+	}
+
+	phones := make([]string, 0, len(pending))
+	for i := range pending {
+		if phone, ok := models.NormalizePhone(pending[i].Phone, config.NumberDefaultCountryCode); ok {
+			pending[i].Phone = phone
+			phones = append(phones, phone)
+		}
+	}
+	cached, err := repo.Get(phones)
+	if err != nil {
+		return 0, err
+	}
+
+	plan := planNumberChecks(pending, cached, now)
+	if len(plan.query) > 0 {
+		responses, err := check(plan.query)
+		if err != nil {
+			return 0, fmt.Errorf("failed to check numbers: %w", err)
+		}
+		for _, v := range numberResults(plan.query, responses, now) {
+			if err := repo.Save(v); err != nil {
+				logrus.Warnf("Failed to cache validation of %s: %v", v.Phone, err)
+			}
+			plan.results[v.Phone] = v
+		}
+	}
+
+	marked := 0
+	for _, lead := range pending {
+		v, ok := plan.results[lead.Phone]
+		if !ok {
+			continue // WhatsApp did not answer for it; try again next run
+		}
+		if err := repo.MarkLead(lead, v.Status, v.CheckedAt); err != nil {
+			logrus.Warnf("Failed to mark number of lead %s: %v", lead.LeadID, err)
+			continue
+		}
+		marked++
+	}
+	return marked, nil
+}
+
+// numberPlan is what to do with a batch of pending leads: results already
+// known by phone, and the phones to ask WhatsApp about
+type numberPlan struct {
+	results map[string]models.NumberValidation
+	query   []string // with a "+", as IsOnWhatsApp takes them
+}
+
+// planNumberChecks sorts pending leads, whose phones are normalized where
+// they could be, into ones answered by an unexpired cached result, ones
+// that are not phone numbers at all, and ones to check
+func planNumberChecks(pending []repository.PendingNumber, cached map[string]models.NumberValidation, now time.Time) numberPlan {
+	plan := numberPlan{results: map[string]models.NumberValidation{}}
+	invalidTTLDays := config.Current().NumberValidation.InvalidTTLDays
+	queued := map[string]bool{}
+	for _, lead := range pending {
+		if _, ok := models.NormalizePhone(lead.Phone, ""); !ok {
+			plan.results[lead.Phone] = models.NumberValidation{Phone: lead.Phone, Status: models.NumberInvalid,
+				CheckedAt: now, ExpiresAt: now.AddDate(0, 0, invalidTTLDays)}
+			continue
+		}
+		if v, ok := cached[lead.Phone]; ok && !v.Expired(now) {
+			plan.results[lead.Phone] = v
+			continue
+		}
+		if !queued[lead.Phone] {
+			queued[lead.Phone] = true
+			plan.query = append(plan.query, "+"+lead.Phone)
+		}
+	}
+	return plan
+}
+
+// numberResults turns WhatsApp's answers into validations; phones it did
+// not answer for are left out
+func numberResults(query []string, responses []types.IsOnWhatsAppResponse, now time.Time) []models.NumberValidation {
+	asked := map[string]bool{}
+	for _, phone := range query {
+		asked[strings.TrimPrefix(phone, "+")] = true
+	}
+
+	settings := config.Current().NumberValidation
+	results := make([]models.NumberValidation, 0, len(responses))
+	for _, response := range responses {
+		phone := strings.TrimPrefix(response.Query, "+")
+		if !asked[phone] {
+			continue
+		}
+		v := models.NumberValidation{Phone: phone, CheckedAt: now}
+		if response.IsIn {
+			v.Status = models.NumberValid
+			v.JID = response.JID.String()
+			v.ExpiresAt = now.AddDate(0, 0, settings.ValidTTLDays)
+		} else {
+			v.Status = models.NumberInvalid
+			v.ExpiresAt = now.AddDate(0, 0, settings.InvalidTTLDays)
+		}
+		if response.VerifiedName != nil {
+			v.IsBusiness = true
+			v.VerifiedName = response.VerifiedName.Details.GetVerifiedName()
+		}
+		results = append(results, v)
+	}
+	return results
+}
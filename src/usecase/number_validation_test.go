@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/models"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
+	"github.com/stretchr/testify/assert"
+	"go.mau.fi/whatsmeow/proto/waVnameCert"
+	"go.mau.fi/whatsmeow/types"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestPlanNumberChecks(t *testing.T) {
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	pending := []repository.PendingNumber{
+		{LeadID: "1", Phone: "60111111111"}, // cached and fresh
+		{LeadID: "2", Phone: "60122222222"}, // cached but expired
+		{LeadID: "3", Phone: "60133333333"}, // never checked
+		{LeadID: "4", Phone: "60133333333"}, // same number on another lead
+		{LeadID: "5", Phone: "0123"},        // not a phone number
+	}
+	cached := map[string]models.NumberValidation{
+		"60111111111": {Phone: "60111111111", Status: models.NumberValid, ExpiresAt: now.Add(time.Hour)},
+		"60122222222": {Phone: "60122222222", Status: models.NumberInvalid, ExpiresAt: now.Add(-time.Hour)},
+	}
+
+	plan := planNumberChecks(pending, cached, now)
+
+	assert.Equal(t, []string{"+60122222222", "+60133333333"}, plan.query)
+	assert.Len(t, plan.results, 2)
+	assert.Equal(t, models.NumberValid, plan.results["60111111111"].Status)
+	assert.Equal(t, models.NumberInvalid, plan.results["0123"].Status)
+}
+
+func TestNumberResults(t *testing.T) {
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	business := &types.VerifiedName{Details: &waVnameCert.VerifiedNameCertificate_Details{
+		VerifiedName: proto.String("Acme Sdn Bhd"),
+	}}
+	responses := []types.IsOnWhatsAppResponse{
+		{Query: "+60111111111", IsIn: true, JID: types.NewJID("60111111111", types.DefaultUserServer)},
+		{Query: "+60122222222", IsIn: false},
+		{Query: "+60133333333", IsIn: true, JID: types.NewJID("60133333333", types.DefaultUserServer), VerifiedName: business},
+		{Query: "+60199999999", IsIn: true}, // not asked
+	}
+
+	results := numberResults([]string{"+60111111111", "+60122222222", "+60133333333", "+60144444444"}, responses, now)
+
+	assert.Len(t, results, 3)
+	assert.Equal(t, models.NumberValid, results[0].Status)
+	assert.Equal(t, "60111111111@s.whatsapp.net", results[0].JID)
+	assert.True(t, results[0].ExpiresAt.After(now))
+	assert.Equal(t, models.NumberInvalid, results[1].Status)
+	assert.Empty(t, results[1].JID)
+	assert.True(t, results[2].IsBusiness)
+	assert.Equal(t, "Acme Sdn Bhd", results[2].VerifiedName)
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/database"
+	domainBroadcast "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/broadcast"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/broadcast"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
 	"github.com/go-redis/redis/v8"
@@ -166,9 +167,8 @@ func (p *OptimizedBroadcastProcessor) processDeviceMessages(deviceID string) {
 		err := p.manager.SendMessage(msg)
 		if err != nil {
 			logrus.Errorf("Failed to queue message %s: %v", msg.ID, err)
-			// Direct update to failed
-			db := database.GetDB()
-			db.Exec(`UPDATE broadcast_messages SET status = 'failed', error_message = ?, updated_at = NOW() WHERE id = ?`, err.Error(), msg.ID)
+			// Mark failed and record the dead letter
+			repository.GetBroadcastRepository().MarkMessageFailed(msg.ID, err, domainBroadcast.DeadLetterSourceSQL, 0)
 		} else {
 			// Mark as queued - direct update like skipped
 			db := database.GetDB()
@@ -1,13 +1,17 @@
 package usecase
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
-	"time"
+	"sync"
 
 	domainBroadcast "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/broadcast"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/broadcast"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/models"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/clock"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/tracing"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
 	"github.com/sirupsen/logrus"
 )
@@ -16,10 +20,12 @@ import (
 type OptimizedCampaignTrigger struct {
 	broadcastManager broadcast.BroadcastManagerInterface
 	db               *sql.DB
+	running          sync.WaitGroup
 }
 
 // NewOptimizedCampaignTrigger creates an optimized trigger service
 func NewOptimizedCampaignTrigger(db *sql.DB) *OptimizedCampaignTrigger {
+	repository.CampaignTargetGroupsSchema.Ensure(db)
 	return &OptimizedCampaignTrigger{
 		broadcastManager: broadcast.GetBroadcastManager(),
 		db:               db,
@@ -35,7 +41,9 @@ func (oct *OptimizedCampaignTrigger) ProcessCampaigns() error {
 		SELECT c.id, c.user_id, c.title, c.message, c.niche, 
 			COALESCE(c.target_status, 'all') AS target_status, 
 			COALESCE(c.image_url, '') AS image_url, c.min_delay_seconds, c.max_delay_seconds,
-			c.campaign_date, c.time_schedule
+			c.campaign_date, c.time_schedule,
+			COALESCE(c.message_type, '') AS message_type, COALESCE(c.message_payload, '') AS message_payload,
+			COALESCE(c.target_groups, '') AS target_groups
 		FROM campaigns c
 		WHERE c.status = 'pending'
 		AND (
@@ -58,22 +66,30 @@ func (oct *OptimizedCampaignTrigger) ProcessCampaigns() error {
 	campaignCount := 0
 	for rows.Next() {
 		var campaign models.Campaign
+		var payload, groups string
 		err := rows.Scan(
 			&campaign.ID, &campaign.UserID, &campaign.Title, &campaign.Message,
 			&campaign.Niche, &campaign.TargetStatus, &campaign.ImageURL,
 			&campaign.MinDelaySeconds, &campaign.MaxDelaySeconds,
 			&campaign.CampaignDate, &campaign.TimeSchedule,
+			&campaign.MessageType, &payload, &groups,
 		)
 		if err != nil {
 			logrus.Errorf("Failed to scan campaign: %v", err)
 			continue
 		}
+		campaign.Payload = domainBroadcast.DecodePayload(payload)
+		campaign.TargetGroups = repository.DecodeGroupTargets(groups)
 		
 		campaignCount++
 		logrus.Infof("Processing campaign: %s (ID: %d)", campaign.Title, campaign.ID)
 		
 		// Execute campaign in goroutine
-		go oct.executeCampaign(&campaign)
+		oct.running.Add(1)
+		go func(campaign models.Campaign) {
+			defer oct.running.Done()
+			oct.executeCampaign(&campaign)
+		}(campaign)
 	}
 	
 	if campaignCount > 0 {
@@ -81,13 +97,91 @@ func (oct *OptimizedCampaignTrigger) ProcessCampaigns() error {
 	}
 	return nil
 }
+
+// Wait blocks until the campaigns started by ProcessCampaigns have been queued
+func (oct *OptimizedCampaignTrigger) Wait() {
+	oct.running.Wait()
+}
+
 // executeCampaign remains the same as original
 func (oct *OptimizedCampaignTrigger) executeCampaign(campaign *models.Campaign) {
 	logrus.Infof("Executing campaign: %s", campaign.Title)
+	ctx, span := tracing.Start(context.Background(), "campaign.trigger", tracing.KindInternal,
+		tracing.Int("campaign.id", int64(campaign.ID)), tracing.String("user.id", campaign.UserID))
+	defer span.End()
 	
-	// Get leads matching the campaign niche AND status
-	leadRepo := repository.GetLeadRepository()
+	messages, err := campaignMessages(campaign)
+	if err != nil {
+		logrus.Errorf("Failed to build messages of campaign %d: %v", campaign.ID, err)
+		span.RecordError(err)
+		return
+	}
 	
+	// Queue the campaign's messages
+	broadcastRepo := repository.GetBroadcastRepository()
+	successful := 0
+	failed := 0
+	
+	for _, msg := range messages {
+		// Check if message already exists for this campaign and recipient
+		var existingCount int
+		checkQuery := `
+			SELECT COUNT(*) FROM broadcast_messages 
+			WHERE campaign_id = ? 
+			AND recipient_phone = ? 
+			AND status IN ('pending', 'processing', 'queued', 'sent')
+		`
+		err := oct.db.QueryRow(checkQuery, campaign.ID, msg.RecipientPhone).Scan(&existingCount)
+		
+		if err == nil && existingCount > 0 {
+			logrus.Debugf("Message already exists for campaign %d and phone %s, skipping", campaign.ID, msg.RecipientPhone)
+			continue // Skip this recipient
+		}
+		
+		_, enqueue := tracing.Start(ctx, "message.enqueue", tracing.KindProducer,
+			tracing.String("device.name", msg.DeviceName), tracing.String("message.type", msg.Type))
+		msg.TraceContext = enqueue.Traceparent()
+		err = broadcastRepo.QueueMessage(msg)
+		enqueue.RecordError(err)
+		enqueue.End()
+		if err != nil {
+			logrus.Errorf("Failed to queue message for %s: %v", msg.RecipientPhone, err)
+			failed++
+		} else {
+			successful++
+		}
+	}
+	
+	span.SetAttributes(tracing.Int("messages.queued", int64(successful)), tracing.Int("messages.failed", int64(failed)))
+	
+	// Update campaign status to triggered after queueing
+	if successful > 0 {
+		// Only mark as triggered if we actually queued some messages; a campaign
+		// paused or cancelled while it was queueing keeps that status
+		_, err = oct.db.Exec("UPDATE campaigns SET status = 'triggered', updated_at = CURRENT_TIMESTAMP WHERE id = ? AND status = 'pending'", campaign.ID)
+		if err != nil {
+			logrus.Errorf("Failed to update campaign status to triggered: %v", err)
+		}
+		logrus.Infof("Campaign %s triggered: %d messages queued, %d failed", 
+			campaign.Title, successful, failed)
+	} else {
+		// No messages queued, mark as finished
+		_, err = oct.db.Exec("UPDATE campaigns SET status = 'finished', updated_at = CURRENT_TIMESTAMP WHERE id = ? AND status = 'pending'", campaign.ID)
+		if err != nil {
+			logrus.Errorf("Failed to update campaign status to finished: %v", err)
+		}
+		logrus.Infof("Campaign %s finished: No matching leads found", campaign.Title)
+	}
+}
+
+// errNoConnectedDevices means none of the user's devices can send a campaign
+var errNoConnectedDevices = errors.New("no connected devices")
+
+// campaignMessages builds the messages triggering a campaign queues. A group
+// campaign posts once to each of its groups; any other campaign messages the
+// leads matching its niche and status. The planner forecasts from the same
+// list, so both agree on who a campaign reaches.
+func campaignMessages(campaign *models.Campaign) ([]domainBroadcast.BroadcastMessage, error) {
 	targetStatus := campaign.TargetStatus
 	if targetStatus == "" {
 		targetStatus = "prospect"
@@ -97,8 +191,7 @@ func (oct *OptimizedCampaignTrigger) executeCampaign(campaign *models.Campaign)
 	userRepo := repository.GetUserRepository()
 	devices, err := userRepo.GetUserDevices(campaign.UserID)
 	if err != nil {
-		logrus.Errorf("Failed to get devices for user %s: %v", campaign.UserID, err)
-		return
+		return nil, fmt.Errorf("failed to get devices for user %s: %w", campaign.UserID, err)
 	}
 	
 	// Filter only connected devices
@@ -119,12 +212,28 @@ func (oct *OptimizedCampaignTrigger) executeCampaign(campaign *models.Campaign)
 	}
 	
 	if len(connectedDevices) == 0 {
-		logrus.Errorf("No connected devices found for user %s", campaign.UserID)
-		return
+		return nil, fmt.Errorf("%w for user %s", errNoConnectedDevices, campaign.UserID)
 	}
 	
 	logrus.Infof("Using %d connected devices for campaign distribution", len(connectedDevices))
 	
+	// A group campaign posts once to each of its groups; any other campaign
+	// messages the leads matching its niche and status
+	var messages []domainBroadcast.BroadcastMessage
+	if len(campaign.TargetGroups) > 0 {
+		messages = groupCampaignMessages(campaign, connectedDevices)
+	} else {
+		messages = leadCampaignMessages(campaign, targetStatus, connectedDevices)
+	}
+	return messages, nil
+}
+
+// leadCampaignMessages builds a message to each lead of the connected devices
+// that matches the campaign's niche and status, leaving out the user's
+// suppressed phones
+func leadCampaignMessages(campaign *models.Campaign, targetStatus string, connectedDevices []*models.UserDevice) []domainBroadcast.BroadcastMessage {
+	leadRepo := repository.GetLeadRepository()
+	
 	// Get leads from ALL connected devices
 	allLeads := []models.Lead{}
 	for _, device := range connectedDevices {
@@ -139,71 +248,75 @@ func (oct *OptimizedCampaignTrigger) executeCampaign(campaign *models.Campaign)
 		}
 	}
 	
-	leads := allLeads
-	
 	logrus.Infof("Total: Found %d leads matching niche: %s and status: %s across all devices", 
-		len(leads), campaign.Niche, targetStatus)
+		len(allLeads), campaign.Niche, targetStatus)
 	
-	// Queue messages for each lead
-	broadcastRepo := repository.GetBroadcastRepository()
-	successful := 0
-	failed := 0
+	suppressed, err := repository.GetSuppressionRepository().SuppressedPhones(campaign.UserID)
+	if err != nil {
+		logrus.Errorf("Failed to load suppression list of user %s: %v", campaign.UserID, err)
+	}
 	
-	for _, lead := range leads {
-		// Check if message already exists for this campaign and phone
-		var existingCount int
-		checkQuery := `
-			SELECT COUNT(*) FROM broadcast_messages 
-			WHERE campaign_id = ? 
-			AND recipient_phone = ? 
-			AND status IN ('pending', 'processing', 'queued', 'sent')
-		`
-		err := oct.db.QueryRow(checkQuery, campaign.ID, lead.Phone).Scan(&existingCount)
-		
-		if err == nil && existingCount > 0 {
-			logrus.Debugf("Message already exists for campaign %d and phone %s, skipping", campaign.ID, lead.Phone)
-			continue // Skip this lead
+	messages := make([]domainBroadcast.BroadcastMessage, 0, len(allLeads))
+	for _, lead := range allLeads {
+		if suppressed[repository.SuppressionPhone(lead.Phone)] {
+			logrus.Debugf("Lead %s is suppressed, skipping", lead.Phone)
+			continue
 		}
-		
 		// Use the device that owns this lead
-		msg := domainBroadcast.BroadcastMessage{
+		messages = append(messages, domainBroadcast.BroadcastMessage{
 			UserID:         campaign.UserID,
 			DeviceID:       lead.DeviceName, // Use device_name for message sending
 			DeviceName:     lead.DeviceName,
 			CampaignID:     &campaign.ID,
 			RecipientPhone: lead.Phone,
 			RecipientName:  lead.Name,
-			Type:           "text",
+			Type:           domainBroadcast.ResolveMessageType(campaign.MessageType, campaign.ImageURL),
 			Content:        campaign.Message,
 			MediaURL:       campaign.ImageURL,
-			ScheduledAt:    time.Now(),
+			Payload:        campaign.Payload,
+			ScheduledAt:    clock.Now(),
 			// MinDelay and MaxDelay removed - will be fetched from campaigns table during processing
-		}
-		
-		err = broadcastRepo.QueueMessage(msg)
-		if err != nil {
-			logrus.Errorf("Failed to queue message for %s: %v", lead.Phone, err)
-			failed++
-		} else {
-			successful++
-		}
+		})
+	}
+	return messages
+}
+
+// groupCampaignMessages builds a message to each of the campaign's groups,
+// sent by the connected device that belongs to it. Groups whose device is
+// not connected are skipped.
+func groupCampaignMessages(campaign *models.Campaign, connectedDevices []*models.UserDevice) []domainBroadcast.BroadcastMessage {
+	connected := map[string]*models.UserDevice{}
+	for _, device := range connectedDevices {
+		connected[device.ID] = device
 	}
 	
-	// Update campaign status to triggered after queueing
-	if successful > 0 {
-		// Only mark as triggered if we actually queued some messages
-		_, err = oct.db.Exec("UPDATE campaigns SET status = 'triggered', updated_at = CURRENT_TIMESTAMP WHERE id = ?", campaign.ID)
-		if err != nil {
-			logrus.Errorf("Failed to update campaign status to triggered: %v", err)
+	groupRepo := repository.GetWhatsAppGroupRepository()
+	messages := make([]domainBroadcast.BroadcastMessage, 0, len(campaign.TargetGroups))
+	for _, target := range campaign.TargetGroups {
+		device, ok := connected[target.DeviceID]
+		if !ok {
+			logrus.Warnf("Device %s of group %s is not connected, skipping", target.DeviceID, target.GroupJID)
+			continue
 		}
-		logrus.Infof("Campaign %s triggered: %d messages queued, %d failed", 
-			campaign.Title, successful, failed)
-	} else {
-		// No messages queued, mark as finished
-		_, err = oct.db.Exec("UPDATE campaigns SET status = 'finished', updated_at = CURRENT_TIMESTAMP WHERE id = ?", campaign.ID)
-		if err != nil {
-			logrus.Errorf("Failed to update campaign status to finished: %v", err)
+		name := target.GroupJID
+		if group, err := groupRepo.GetGroup(target.DeviceID, target.GroupJID); err == nil && group.Name != "" {
+			name = group.Name
 		}
-		logrus.Infof("Campaign %s finished: No matching leads found", campaign.Title)
+		messages = append(messages, domainBroadcast.BroadcastMessage{
+			UserID:         campaign.UserID,
+			DeviceID:       device.ID,
+			DeviceName:     device.DeviceName,
+			CampaignID:     &campaign.ID,
+			RecipientPhone: target.GroupJID,
+			RecipientName:  name,
+			Type:           domainBroadcast.ResolveMessageType(campaign.MessageType, campaign.ImageURL),
+			Content:        campaign.Message,
+			MediaURL:       campaign.ImageURL,
+			Payload:        campaign.Payload,
+			ScheduledAt:    clock.Now(),
+		})
 	}
-}
\ No newline at end of file
+	logrus.Infof("Campaign %s targets %d groups, %d on connected devices", campaign.Title,
+		len(campaign.TargetGroups), len(messages))
+	return messages
+}
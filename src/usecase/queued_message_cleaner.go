@@ -1,27 +1,27 @@
 package usecase
 
 import (
+	"context"
+	"fmt"
 	"time"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/database"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/scheduler"
 	"github.com/sirupsen/logrus"
 )
 
-// StartQueuedMessageCleaner handles stuck messages in queued state
+// StartQueuedMessageCleaner schedules the cleaner for messages stuck in queued state
 func StartQueuedMessageCleaner() {
-	logrus.Info("Starting queued message cleaner...")
-	
-	ticker := time.NewTicker(60 * time.Second) // Check every minute
-	defer ticker.Stop()
-	
-	for {
-		select {
-		case <-ticker.C:
-			cleanStuckMessages()
-		}
-	}
+	scheduler.Default().MustRegister(scheduler.Job{
+		Name:      "queued-message-cleaner",
+		Interval:  60 * time.Second, // Check every minute
+		Singleton: true,
+		Run: func(ctx context.Context) error {
+			return cleanStuckMessages()
+		},
+	})
 }
 
-func cleanStuckMessages() {
+func cleanStuckMessages() error {
 	db := database.GetDB()
 	var rowsAffected int64
 	
@@ -72,8 +72,7 @@ func cleanStuckMessages() {
 	`)
 	
 	if err != nil {
-		logrus.Errorf("Failed to clean stuck messages: %v", err)
-		return
+		return fmt.Errorf("failed to clean stuck messages: %w", err)
 	}
 	
 	rowsAffected, _ = result.RowsAffected()
@@ -91,4 +90,5 @@ func cleanStuckMessages() {
 	if err == nil && queuedCount > 0 {
 		logrus.Debugf("Currently %d messages in queue", queuedCount)
 	}
+	return nil
 }
@@ -9,6 +9,8 @@ import (
 	domainBroadcast "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/broadcast"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/models"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/listing"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
 	"github.com/sirupsen/logrus"
 	"go.mau.fi/whatsmeow"
@@ -26,10 +28,34 @@ func NewSequenceUsecase(waCli *whatsmeow.Client, sendService domainSend.ISendUse
 	}
 }
 
+// validateStepMessages checks the type-specific fields of video, document,
+// audio, location, poll and contact steps; text and image steps keep their
+// lenient legacy handling
+func validateStepMessages(steps []domainSequence.CreateSequenceStepRequest) error {
+	for _, step := range steps {
+		mediaURL := step.MediaURL
+		if mediaURL == "" {
+			mediaURL = step.ImageURL
+		}
+		messageType := domainBroadcast.ResolveMessageType(step.MessageType, mediaURL)
+		if messageType == domainBroadcast.MessageTypeText || messageType == domainBroadcast.MessageTypeImage {
+			continue
+		}
+		if err := domainBroadcast.ValidateMessage(messageType, step.Content, mediaURL, step.Payload); err != nil {
+			return fmt.Errorf("step %d: %w", step.DayNumber, err)
+		}
+	}
+	return nil
+}
+
 // CreateSequence creates a new sequence
 func (s *sequenceService) CreateSequence(request domainSequence.CreateSequenceRequest) (domainSequence.SequenceResponse, error) {
 	var response domainSequence.SequenceResponse
 	
+	if err := validateStepMessages(request.Steps); err != nil {
+		return response, err
+	}
+	
 	// Create sequence - no device_id needed as it will use all user's connected devices
 	sequence := &models.Sequence{
 		UserID:          request.UserID,
@@ -78,6 +104,7 @@ func (s *sequenceService) CreateSequence(request domainSequence.CreateSequenceRe
 			Content:           stepReq.Content,
 			MediaURL:          stepReq.MediaURL,
 			Caption:           stepReq.Caption,
+			Payload:           stepReq.Payload,
 			TimeSchedule:      stepReq.TimeSchedule,
 			MinDelaySeconds:   stepReq.MinDelaySeconds,
 			MaxDelaySeconds:   stepReq.MaxDelaySeconds,
@@ -121,87 +148,123 @@ func (s *sequenceService) GetSequences(userID string) ([]domainSequence.Sequence
 		return nil, err
 	}
 	
+	contactCounts := sequenceContactCounts(sequences)
 	var responses []domainSequence.SequenceResponse
 	for _, seq := range sequences {
-		// Get contact count
-		contacts, _ := repo.GetSequenceContacts(seq.ID)
-		
-		// Get steps
-		steps, err := repo.GetSequenceSteps(seq.ID)
-		if err != nil {
-			logrus.Errorf("Error getting steps for sequence %s: %v", seq.ID, err)
-			steps = []models.SequenceStep{} // Initialize empty slice to prevent nil
-		}
-		logrus.Debugf("Retrieved %d steps for sequence %s", len(steps), seq.ID)
-		
-		// Debug log the steps only for active sequences
-		if seq.IsActive {
-			for i, step := range steps {
-				logrus.Debugf("Step %d: Day=%d, Content='%s', Trigger='%s'", i+1, step.DayNumber, step.Content, step.Trigger)
-			}
-		}
-		
-		// Determine status string
-		statusStr := "INACTIVE"
-		if seq.IsActive {
-			statusStr = "ACTIVE"
-		}
-		
-		logrus.Debugf("Processing sequence: ID=%s, Name=%s, Status=%s, TimeSchedule=%s", seq.ID, seq.Name, statusStr, seq.TimeSchedule)
-		
-		response := domainSequence.SequenceResponse{
-			ID:              seq.ID,
-			Name:            seq.Name,
-			Description:     seq.Description,
-			UserID:          seq.UserID,
-			DeviceID:        seq.DeviceID,
-			Niche:           seq.Niche,
-			Status:          seq.Status,
-			Trigger:         seq.Trigger,
-			StartTrigger:    seq.StartTrigger,
-			EndTrigger:      seq.EndTrigger,
-			TotalDays:       seq.TotalDays,
-			IsActive:        seq.IsActive,
-			TimeSchedule:    seq.TimeSchedule,
-			MinDelaySeconds: seq.MinDelaySeconds,
-			MaxDelaySeconds: seq.MaxDelaySeconds,
-			ContactCount:    len(contacts),
-			ContactsCount:   len(contacts),
-			StepCount:       len(steps),
-			CreatedAt:       seq.CreatedAt,
-			UpdatedAt:       seq.UpdatedAt,
-			Steps:           []domainSequence.SequenceStepResponse{}, // Initialize steps array
-		}
-		
-		// Set default status if empty
-		if response.Status == "" {
-			response.Status = "inactive"
+		responses = append(responses, sequenceListResponse(seq, contactCounts[seq.ID]))
+	}
+	
+	return responses, nil
+}
+
+// ListSequences gets one page of a user's sequences
+func (s *sequenceService) ListSequences(userID string, q listing.Query) ([]domainSequence.SequenceResponse, utils.Page, error) {
+	sequences, page, err := repository.GetSequenceRepository().ListSequences(userID, q)
+	if err != nil {
+		return nil, page, err
+	}
+	
+	contactCounts := sequenceContactCounts(sequences)
+	responses := []domainSequence.SequenceResponse{}
+	for _, seq := range sequences {
+		responses = append(responses, sequenceListResponse(seq, contactCounts[seq.ID]))
+	}
+	
+	return responses, page, nil
+}
+
+// sequenceContactCounts counts the contacts of a page of sequences in one query
+func sequenceContactCounts(sequences []models.Sequence) map[string]int {
+	ids := make([]string, len(sequences))
+	for i, seq := range sequences {
+		ids[i] = seq.ID
+	}
+	counts, err := repository.GetSequenceRepository().CountSequenceContacts(ids)
+	if err != nil {
+		logrus.Errorf("Error counting sequence contacts: %v", err)
+	}
+	return counts
+}
+
+// sequenceListResponse builds the list entry of a sequence with its steps and contact count
+func sequenceListResponse(seq models.Sequence, contactCount int) domainSequence.SequenceResponse {
+	repo := repository.GetSequenceRepository()
+
+	// Get steps
+	steps, err := repo.GetSequenceSteps(seq.ID)
+	if err != nil {
+		logrus.Errorf("Error getting steps for sequence %s: %v", seq.ID, err)
+		steps = []models.SequenceStep{} // Initialize empty slice to prevent nil
+	}
+	logrus.Debugf("Retrieved %d steps for sequence %s", len(steps), seq.ID)
+	
+	// Debug log the steps only for active sequences
+	if seq.IsActive {
+		for i, step := range steps {
+			logrus.Debugf("Step %d: Day=%d, Content='%s', Trigger='%s'", i+1, step.DayNumber, step.Content, step.Trigger)
 		}
-		
-		// Add steps to response
-		for _, step := range steps {
-			stepResp := domainSequence.SequenceStepResponse{
-				ID:                step.ID,
-				SequenceID:        step.SequenceID,
-				DayNumber:         step.DayNumber,
-				Trigger:           step.Trigger,
-				NextTrigger:       step.NextTrigger,
-				TriggerDelayHours: step.TriggerDelayHours,
-				IsEntryPoint:      step.IsEntryPoint,
-				MessageType:       step.MessageType,
-				TimeSchedule:      step.TimeSchedule,
-				Content:           step.Content,
-				MediaURL:          step.MediaURL,
-				Caption:           step.Caption,
-				MinDelaySeconds:   step.MinDelaySeconds,
-				MaxDelaySeconds:   step.MaxDelaySeconds,
-			}
-			response.Steps = append(response.Steps, stepResp)
+	}
+	
+	// Determine status string
+	statusStr := "INACTIVE"
+	if seq.IsActive {
+		statusStr = "ACTIVE"
+	}
+	
+	logrus.Debugf("Processing sequence: ID=%s, Name=%s, Status=%s, TimeSchedule=%s", seq.ID, seq.Name, statusStr, seq.TimeSchedule)
+	
+	response := domainSequence.SequenceResponse{
+		ID:              seq.ID,
+		Name:            seq.Name,
+		Description:     seq.Description,
+		UserID:          seq.UserID,
+		DeviceID:        seq.DeviceID,
+		Niche:           seq.Niche,
+		Status:          seq.Status,
+		Trigger:         seq.Trigger,
+		StartTrigger:    seq.StartTrigger,
+		EndTrigger:      seq.EndTrigger,
+		TotalDays:       seq.TotalDays,
+		IsActive:        seq.IsActive,
+		TimeSchedule:    seq.TimeSchedule,
+		MinDelaySeconds: seq.MinDelaySeconds,
+		MaxDelaySeconds: seq.MaxDelaySeconds,
+		ContactCount:    contactCount,
+		ContactsCount:   contactCount,
+		StepCount:       len(steps),
+		CreatedAt:       seq.CreatedAt,
+		UpdatedAt:       seq.UpdatedAt,
+		Steps:           []domainSequence.SequenceStepResponse{}, // Initialize steps array
+	}
+	
+	// Set default status if empty
+	if response.Status == "" {
+		response.Status = "inactive"
+	}
+	
+	// Add steps to response
+	for _, step := range steps {
+		stepResp := domainSequence.SequenceStepResponse{
+			ID:                step.ID,
+			SequenceID:        step.SequenceID,
+			DayNumber:         step.DayNumber,
+			Trigger:           step.Trigger,
+			NextTrigger:       step.NextTrigger,
+			TriggerDelayHours: step.TriggerDelayHours,
+			IsEntryPoint:      step.IsEntryPoint,
+			MessageType:       step.MessageType,
+			TimeSchedule:      step.TimeSchedule,
+			Content:           step.Content,
+			MediaURL:          step.MediaURL,
+			Caption:           step.Caption,
+			Payload:           step.Payload,
+			MinDelaySeconds:   step.MinDelaySeconds,
+			MaxDelaySeconds:   step.MaxDelaySeconds,
 		}
-		responses = append(responses, response)
+		response.Steps = append(response.Steps, stepResp)
 	}
 	
-	return responses, nil
+	return response
 }
 
 // GetSequenceByID gets sequence details by ID
@@ -265,6 +328,7 @@ func (s *sequenceService) GetSequenceByID(sequenceID string) (domainSequence.Seq
 			Content:           step.Content,
 			MediaURL:          step.MediaURL,
 			Caption:           step.Caption,
+			Payload:           step.Payload,
 			TimeSchedule:      step.TimeSchedule,
 			MinDelaySeconds:   step.MinDelaySeconds,
 			MaxDelaySeconds:   step.MaxDelaySeconds,
@@ -284,6 +348,10 @@ func (s *sequenceService) GetSequenceByID(sequenceID string) (domainSequence.Seq
 }
 // UpdateSequence updates a sequence
 func (s *sequenceService) UpdateSequence(sequenceID string, request domainSequence.UpdateSequenceRequest) error {
+	if err := validateStepMessages(request.Steps); err != nil {
+		return err
+	}
+	
 	repo := repository.GetSequenceRepository()
 	
 	sequence, err := repo.GetSequenceByID(sequenceID)
@@ -352,6 +420,7 @@ func (s *sequenceService) UpdateSequence(sequenceID string, request domainSequen
 				existingStep.Content = stepReq.Content
 				existingStep.MediaURL = stepReq.MediaURL
 				existingStep.Caption = stepReq.Caption
+				existingStep.Payload = stepReq.Payload
 				existingStep.TimeSchedule = stepReq.TimeSchedule
 				existingStep.Trigger = stepReq.Trigger
 				existingStep.NextTrigger = stepReq.NextTrigger
@@ -377,6 +446,7 @@ func (s *sequenceService) UpdateSequence(sequenceID string, request domainSequen
 					Content:           stepReq.Content,
 					MediaURL:          stepReq.MediaURL,
 					Caption:           stepReq.Caption,
+					Payload:           stepReq.Payload,
 					TimeSchedule:      stepReq.TimeSchedule,
 					MinDelaySeconds:   stepReq.MinDelaySeconds,
 					MaxDelaySeconds:   stepReq.MaxDelaySeconds,
@@ -447,25 +517,44 @@ func (s *sequenceService) GetSequenceContacts(sequenceID string) ([]domainSequen
 	
 	var responses []domainSequence.SequenceContactResponse
 	for _, contact := range contacts {
-		response := domainSequence.SequenceContactResponse{
-			ID:            contact.ID,
-			ContactPhone:  contact.ContactPhone,
-			ContactName:   contact.ContactName,
-			CurrentStep:   contact.CurrentStep,
-			Status:        contact.Status,
-			AddedAt:       contact.CompletedAt,
-		}
-		
-		if contact.CompletedAt != nil {
-			response.CompletedAt = contact.CompletedAt
-		}
-		
-		responses = append(responses, response)
+		responses = append(responses, sequenceContactResponse(contact))
 	}
 	
 	return responses, nil
 }
 
+// ListSequenceContacts gets one page of the contacts in a sequence
+func (s *sequenceService) ListSequenceContacts(sequenceID string, q listing.Query) ([]domainSequence.SequenceContactResponse, utils.Page, error) {
+	contacts, page, err := repository.GetSequenceRepository().ListSequenceContacts(sequenceID, q)
+	if err != nil {
+		return nil, page, err
+	}
+	
+	responses := []domainSequence.SequenceContactResponse{}
+	for _, contact := range contacts {
+		responses = append(responses, sequenceContactResponse(contact))
+	}
+	
+	return responses, page, nil
+}
+
+func sequenceContactResponse(contact models.SequenceContact) domainSequence.SequenceContactResponse {
+	response := domainSequence.SequenceContactResponse{
+		ID:            contact.ID,
+		ContactPhone:  contact.ContactPhone,
+		ContactName:   contact.ContactName,
+		CurrentStep:   contact.CurrentStep,
+		Status:        contact.Status,
+		AddedAt:       contact.CompletedAt,
+	}
+	
+	if contact.CompletedAt != nil {
+		response.CompletedAt = contact.CompletedAt
+	}
+	
+	return response
+}
+
 // StartSequence starts a sequence
 func (s *sequenceService) StartSequence(sequenceID string) error {
 	repo := repository.GetSequenceRepository()
@@ -0,0 +1,349 @@
+package usecase
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net/url"
+	"strings"
+	"time"
+
+	domainBroadcast "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/broadcast"
+	domainSequence "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/sequence"
+)
+
+// flowLeadFields maps the lead fields a condition node may check to their leads column
+var flowLeadFields = map[string]string{
+	"name":          "name",
+	"email":         "email",
+	"niche":         "niche",
+	"source":        "source",
+	"status":        "status",
+	"target_status": "target_status",
+	"platform":      "platform",
+	"notes":         "notes",
+}
+
+// ParseFlow decodes and validates a flow definition in the import/export format
+func ParseFlow(data []byte) (*domainSequence.Flow, []domainSequence.FlowValidationError, error) {
+	var flow domainSequence.Flow
+	if err := json.Unmarshal(data, &flow); err != nil {
+		return nil, nil, fmt.Errorf("invalid flow JSON: %w", err)
+	}
+	if flow.Version == 0 {
+		flow.Version = domainSequence.FlowVersion
+	}
+	flow.Converted = false
+	return &flow, ValidateFlow(&flow), nil
+}
+
+// ValidateFlow checks a flow for structural problems: unknown nodes and
+// targets, missing settings, cycles and nodes that cannot be reached
+func ValidateFlow(flow *domainSequence.Flow) []domainSequence.FlowValidationError {
+	var problems []domainSequence.FlowValidationError
+	add := func(nodeID, format string, args ...interface{}) {
+		problems = append(problems, domainSequence.FlowValidationError{NodeID: nodeID, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if flow.Version > domainSequence.FlowVersion {
+		add("", "unsupported flow version %d", flow.Version)
+	}
+	if len(flow.Nodes) == 0 {
+		add("", "flow has no nodes")
+		return problems
+	}
+
+	ids := make(map[string]bool, len(flow.Nodes))
+	for _, node := range flow.Nodes {
+		if node.ID == "" {
+			add("", "node of type %q has no id", node.Type)
+			continue
+		}
+		if ids[node.ID] {
+			add(node.ID, "duplicate node id")
+		}
+		ids[node.ID] = true
+	}
+	if flow.Start == "" {
+		add("", "flow has no start node")
+	} else if !ids[flow.Start] {
+		add("", "start node %q does not exist", flow.Start)
+	}
+
+	for i := range flow.Nodes {
+		node := &flow.Nodes[i]
+		for _, problem := range validateFlowNode(node) {
+			add(node.ID, "%s", problem)
+		}
+		for _, target := range node.Targets() {
+			if target == "" {
+				add(node.ID, "missing next node")
+			} else if !ids[target] {
+				add(node.ID, "next node %q does not exist", target)
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		// Graph checks assume every edge points at a real node
+		return problems
+	}
+
+	for _, nodeID := range findFlowCycles(flow) {
+		add(nodeID, "node is part of a cycle")
+	}
+
+	reachable := make(map[string]bool)
+	queue := []string{flow.Start}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if reachable[id] {
+			continue
+		}
+		reachable[id] = true
+		queue = append(queue, flow.Node(id).Targets()...)
+	}
+	for _, node := range flow.Nodes {
+		if !reachable[node.ID] {
+			add(node.ID, "node is unreachable from start")
+		}
+	}
+
+	return problems
+}
+
+// validateFlowNode checks the settings of a single node
+func validateFlowNode(node *domainSequence.FlowNode) []string {
+	var problems []string
+	switch node.Type {
+	case domainSequence.FlowNodeSend:
+		if node.StepID == "" {
+			messageType := domainBroadcast.ResolveMessageType(node.MessageType, node.MediaURL)
+			if messageType == domainBroadcast.MessageTypeText || messageType == domainBroadcast.MessageTypeImage {
+				if node.Content == "" && node.MediaURL == "" {
+					problems = append(problems, "send node needs content, media_url or step_id")
+				}
+			} else if err := domainBroadcast.ValidateMessage(messageType, node.Content, node.MediaURL, node.Payload); err != nil {
+				problems = append(problems, err.Error())
+			}
+		}
+		if node.MinDelaySeconds < 0 || node.MaxDelaySeconds < 0 || (node.MaxDelaySeconds > 0 && node.MaxDelaySeconds < node.MinDelaySeconds) {
+			problems = append(problems, "invalid delay range")
+		}
+	case domainSequence.FlowNodeWait:
+		if node.DelayHours < 0 || node.DelayMinutes < 0 || node.DelayHours*60+node.DelayMinutes <= 0 {
+			problems = append(problems, "wait node needs a positive delay")
+		}
+	case domainSequence.FlowNodeWaitUntil:
+		if _, err := parseClockMinutes(node.TimeOfDay); err != nil {
+			problems = append(problems, "time_of_day must be HH:MM")
+		}
+	case domainSequence.FlowNodeCondition:
+		problems = append(problems, validateFlowCondition(node.Condition)...)
+	case domainSequence.FlowNodeSplit:
+		if len(node.Branches) < 2 {
+			problems = append(problems, "split node needs at least two branches")
+		}
+		total := 0
+		for _, branch := range node.Branches {
+			if branch.Percent <= 0 {
+				problems = append(problems, "split percentages must be positive")
+				break
+			}
+			total += branch.Percent
+		}
+		if total != 100 {
+			problems = append(problems, fmt.Sprintf("split percentages add up to %d, not 100", total))
+		}
+	case domainSequence.FlowNodeAddTag, domainSequence.FlowNodeRemoveTag:
+		if strings.TrimSpace(node.Tag) == "" {
+			problems = append(problems, "tag is required")
+		}
+	case domainSequence.FlowNodeLeadStatus:
+		if strings.TrimSpace(node.Status) == "" {
+			problems = append(problems, "status is required")
+		}
+	case domainSequence.FlowNodeWebhook:
+		parsed, err := url.Parse(node.URL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			problems = append(problems, "webhook url must be an absolute http(s) URL")
+		}
+	case domainSequence.FlowNodeEnd:
+		if node.Next != "" {
+			problems = append(problems, "end node cannot have a next node")
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("unknown node type %q", node.Type))
+	}
+	return problems
+}
+
+// validateFlowCondition checks a condition node's settings
+func validateFlowCondition(condition *domainSequence.FlowCondition) []string {
+	if condition == nil {
+		return []string{"condition node needs a condition"}
+	}
+	switch condition.Kind {
+	case domainSequence.FlowConditionReplied, domainSequence.FlowConditionRead:
+		if condition.WithinHours < 0 {
+			return []string{"within_hours cannot be negative"}
+		}
+	case domainSequence.FlowConditionTag:
+		if strings.TrimSpace(condition.Tag) == "" {
+			return []string{"tag condition needs a tag"}
+		}
+	case domainSequence.FlowConditionField:
+		if _, ok := flowLeadFields[condition.Field]; !ok {
+			return []string{fmt.Sprintf("unknown lead field %q", condition.Field)}
+		}
+		switch condition.Operator {
+		case "equals", "not_equals", "contains", "empty", "not_empty":
+		default:
+			return []string{fmt.Sprintf("unknown operator %q", condition.Operator)}
+		}
+	default:
+		return []string{fmt.Sprintf("unknown condition kind %q", condition.Kind)}
+	}
+	return nil
+}
+
+// findFlowCycles returns the nodes that close a cycle
+func findFlowCycles(flow *domainSequence.Flow) []string {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int, len(flow.Nodes))
+	var cycles []string
+
+	var visit func(id string)
+	visit = func(id string) {
+		state[id] = visiting
+		for _, target := range flow.Node(id).Targets() {
+			switch state[target] {
+			case visiting:
+				cycles = append(cycles, target)
+			case unvisited:
+				visit(target)
+			}
+		}
+		state[id] = done
+	}
+
+	for _, node := range flow.Nodes {
+		if state[node.ID] == unvisited {
+			visit(node.ID)
+		}
+	}
+	return cycles
+}
+
+// ConvertLinearSequence builds the flow equivalent of a linear sequence:
+// the first-message delay, then each step followed by its trigger delay,
+// ending with a hand-off to the linked sequence if there is one
+func ConvertLinearSequence(sequenceID, name string, steps []materializedStep, nextSequenceID string) *domainSequence.Flow {
+	flow := &domainSequence.Flow{
+		Version:    domainSequence.FlowVersion,
+		SequenceID: sequenceID,
+		Name:       name,
+		Start:      "end",
+		Converted:  true,
+	}
+
+	end := domainSequence.FlowNode{ID: "end", Type: domainSequence.FlowNodeEnd, Label: "End", NextSequenceID: nextSequenceID}
+	if len(steps) == 0 {
+		flow.Nodes = []domainSequence.FlowNode{end}
+		return flow
+	}
+
+	// Matches the first message delay used by enrollment
+	flow.Start = "wait_start"
+	flow.Nodes = append(flow.Nodes, domainSequence.FlowNode{
+		ID: "wait_start", Type: domainSequence.FlowNodeWait, Label: "Wait before first message",
+		DelayHours: 24, DelayMinutes: 5, Next: fmt.Sprintf("send_%d", steps[0].DayNumber),
+	})
+
+	for i, step := range steps {
+		next := end.ID
+		if i < len(steps)-1 {
+			next = fmt.Sprintf("wait_%d", step.DayNumber)
+		}
+		flow.Nodes = append(flow.Nodes, domainSequence.FlowNode{
+			ID:              fmt.Sprintf("send_%d", step.DayNumber),
+			Type:            domainSequence.FlowNodeSend,
+			Label:           fmt.Sprintf("Day %d", step.DayNumber),
+			StepID:          step.ID,
+			MessageType:     step.MessageType,
+			Content:         step.Content,
+			MediaURL:        step.MediaURL,
+			Payload:         step.Payload,
+			MinDelaySeconds: step.MinDelay,
+			MaxDelaySeconds: step.MaxDelay,
+			Next:            next,
+		})
+		if i < len(steps)-1 {
+			flow.Nodes = append(flow.Nodes, domainSequence.FlowNode{
+				ID:         next,
+				Type:       domainSequence.FlowNodeWait,
+				DelayHours: int(step.delay() / time.Hour),
+				Next:       fmt.Sprintf("send_%d", steps[i+1].DayNumber),
+			})
+		}
+	}
+
+	flow.Nodes = append(flow.Nodes, end)
+	return flow
+}
+
+// pickSplitBranch chooses a split branch for a lead. The choice is stable for
+// the same lead and node so retries never switch branches.
+func pickSplitBranch(branches []domainSequence.FlowSplitBranch, phone, nodeID string) string {
+	if len(branches) == 0 {
+		return ""
+	}
+	hash := fnv.New32a()
+	hash.Write([]byte(phone + "|" + nodeID))
+	roll := int(hash.Sum32() % 100)
+
+	for _, branch := range branches {
+		if roll < branch.Percent {
+			return branch.Next
+		}
+		roll -= branch.Percent
+	}
+	return branches[len(branches)-1].Next
+}
+
+// nextTimeOfDay returns the next moment at or after now when the local clock reads clock
+func nextTimeOfDay(now time.Time, clock string, location *time.Location) (time.Time, error) {
+	minutes, err := parseClockMinutes(clock)
+	if err != nil {
+		return now, err
+	}
+	local := now.In(location)
+	candidate := time.Date(local.Year(), local.Month(), local.Day(), minutes/60, minutes%60, 0, 0, location)
+	if candidate.Before(local) {
+		candidate = candidate.AddDate(0, 0, 1)
+	}
+	return candidate, nil
+}
+
+// matchFieldCondition compares a lead field value against a condition
+func matchFieldCondition(condition *domainSequence.FlowCondition, value string) bool {
+	value = strings.TrimSpace(value)
+	switch condition.Operator {
+	case "equals":
+		return strings.EqualFold(value, condition.Value)
+	case "not_equals":
+		return !strings.EqualFold(value, condition.Value)
+	case "contains":
+		return strings.Contains(strings.ToLower(value), strings.ToLower(condition.Value))
+	case "empty":
+		return value == ""
+	case "not_empty":
+		return value != ""
+	}
+	return false
+}
@@ -0,0 +1,657 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/database"
+	domainBroadcast "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/broadcast"
+	domainSequence "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/sequence"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/scheduler"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/models"
+	pkgMedia "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/media"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// maxFlowHops bounds how many nodes a single run may pass through; flows are
+// acyclic so this only guards against a corrupt definition
+const maxFlowHops = 200
+
+// SequenceFlowEngine moves contacts through graph-based sequence flows
+type SequenceFlowEngine struct {
+	db         *sql.DB
+	repo       *repository.SequenceFlowRepository
+	location   *time.Location
+	httpClient *http.Client
+}
+
+// NewSequenceFlowEngine creates a new flow engine
+func NewSequenceFlowEngine(db *sql.DB) *SequenceFlowEngine {
+	loc, err := time.LoadLocation(config.PlannerTimezone)
+	if err != nil {
+		// Fallback to fixed UTC+8 if timezone data not available
+		loc = time.FixedZone("MYT", 8*60*60)
+	}
+	return &SequenceFlowEngine{
+		db:         db,
+		repo:       repository.GetSequenceFlowRepository(),
+		location:   loc,
+		httpClient: pkgMedia.NewPublicClient(10 * time.Second), // webhook URLs are user-supplied
+	}
+}
+
+// GetFlow returns the saved flow of a sequence, or the conversion of its
+// linear steps when it has none
+func (e *SequenceFlowEngine) GetFlow(sequenceID string) (*domainSequence.Flow, error) {
+	flow, err := e.repo.GetFlow(sequenceID)
+	if err != nil || flow != nil {
+		return flow, err
+	}
+	return e.convert(sequenceID)
+}
+
+// convert builds the flow equivalent of a sequence's linear steps
+func (e *SequenceFlowEngine) convert(sequenceID string) (*domainSequence.Flow, error) {
+	var name string
+	if err := e.db.QueryRow(`SELECT name FROM sequences WHERE id = ?`, sequenceID).Scan(&name); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("sequence not found")
+		}
+		return nil, err
+	}
+
+	materializer := NewSequenceStepMaterializer(e.db)
+	steps, err := materializer.steps(sequenceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load steps: %w", err)
+	}
+
+	nextSequenceID := ""
+	if len(steps) > 0 {
+		last := steps[len(steps)-1]
+		if next, err := materializer.nextStep(&last); err == nil && next != nil && next.SequenceID != sequenceID {
+			nextSequenceID = next.SequenceID
+		}
+	}
+	return ConvertLinearSequence(sequenceID, name, steps, nextSequenceID), nil
+}
+
+// SaveFlow validates and stores a flow, then moves the sequence's linear
+// contacts onto the send nodes that carry their current step
+func (e *SequenceFlowEngine) SaveFlow(flow *domainSequence.Flow) ([]domainSequence.FlowValidationError, error) {
+	if problems := ValidateFlow(flow); len(problems) > 0 {
+		return problems, nil
+	}
+	if err := e.repo.SaveFlow(flow); err != nil {
+		return nil, fmt.Errorf("failed to save flow: %w", err)
+	}
+	if moved, err := e.adoptLinearContacts(flow); err != nil {
+		logrus.Warnf("Failed to move linear contacts into flow %s: %v", flow.SequenceID, err)
+	} else if moved > 0 {
+		logrus.Infof("Moved %d linear contacts into flow for sequence %s", moved, flow.SequenceID)
+	}
+	return nil, nil
+}
+
+// ConvertUserSequences saves a converted flow for each of a user's linear sequences
+func (e *SequenceFlowEngine) ConvertUserSequences(userID string) (int, error) {
+	rows, err := e.db.Query(`
+		SELECT s.id FROM sequences s
+		WHERE s.user_id = ? AND NOT EXISTS (SELECT 1 FROM sequence_flows f WHERE f.sequence_id = s.id)
+	`, userID)
+	if err != nil {
+		return 0, err
+	}
+	var sequenceIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err == nil {
+			sequenceIDs = append(sequenceIDs, id)
+		}
+	}
+	rows.Close()
+
+	converted := 0
+	for _, sequenceID := range sequenceIDs {
+		flow, err := e.convert(sequenceID)
+		if err != nil {
+			logrus.Warnf("Failed to convert sequence %s: %v", sequenceID, err)
+			continue
+		}
+		problems, err := e.SaveFlow(flow)
+		if err != nil {
+			return converted, err
+		}
+		if len(problems) > 0 {
+			logrus.Warnf("Converted flow for sequence %s is invalid: %s", sequenceID, problems[0].Message)
+			continue
+		}
+		converted++
+	}
+	return converted, nil
+}
+
+// adoptLinearContacts moves active sequence_contacts rows onto the flow
+func (e *SequenceFlowEngine) adoptLinearContacts(flow *domainSequence.Flow) (int, error) {
+	nodeByStep := make(map[string]string)
+	for _, node := range flow.Nodes {
+		if node.Type == domainSequence.FlowNodeSend && node.StepID != "" {
+			nodeByStep[node.StepID] = node.ID
+		}
+	}
+	if len(nodeByStep) == 0 {
+		return 0, nil
+	}
+
+	rows, err := e.db.Query(`
+		SELECT sc.id, sc.contact_phone, COALESCE(sc.contact_name, ''), COALESCE(sc.user_id, ''),
+			COALESCE(sc.assigned_device_id, ''), sc.sequence_stepid,
+			COALESCE(bm.id, ''), COALESCE(bm.status, ''), COALESCE(NULLIF(bm.device_name, ''), bm.device_id, '')
+		FROM sequence_contacts sc
+		LEFT JOIN broadcast_messages bm
+			ON bm.sequence_stepid = sc.sequence_stepid AND bm.recipient_phone = sc.contact_phone
+			AND bm.status IN ('pending', 'queued', 'processing', 'sent')
+		WHERE sc.sequence_id = ? AND sc.status = 'active' AND sc.sequence_stepid IS NOT NULL
+	`, flow.SequenceID)
+	if err != nil {
+		return 0, err
+	}
+
+	type linearContact struct {
+		contactID     string
+		pos           repository.SequenceFlowPosition
+		messageStatus string
+	}
+	var contacts []linearContact
+	for rows.Next() {
+		var c linearContact
+		var stepID string
+		if err := rows.Scan(&c.contactID, &c.pos.Phone, &c.pos.Name, &c.pos.UserID, &c.pos.DeviceID, &stepID,
+			&c.pos.PendingMessageID, &c.messageStatus, &c.pos.DeviceName); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		nodeID, ok := nodeByStep[stepID]
+		if !ok {
+			// Step no longer in the flow; the contact finishes on the linear path
+			continue
+		}
+		c.pos.SequenceID = flow.SequenceID
+		c.pos.NodeID = nodeID
+		contacts = append(contacts, c)
+	}
+	rows.Close()
+
+	moved := 0
+	seen := make(map[string]bool)
+	for _, c := range contacts {
+		if seen[c.contactID] {
+			continue
+		}
+		seen[c.contactID] = true
+
+		pos := c.pos
+		started, err := e.repo.StartPosition(&pos)
+		if err != nil {
+			return moved, err
+		}
+		if !started {
+			// Already moving through the flow
+			continue
+		}
+
+		switch c.messageStatus {
+		case "sent":
+			// The step went out; continue from the node after it
+			pos.NodeID = flow.Node(c.pos.NodeID).Next
+			pos.Status = repository.FlowPositionActive
+		case "":
+			pos.Status = repository.FlowPositionActive
+		default:
+			pos.Status = repository.FlowPositionSending
+			pos.PendingMessageID = c.pos.PendingMessageID
+		}
+		if err := e.repo.SavePosition(&pos); err != nil {
+			return moved, err
+		}
+
+		// 'flow' hands the contact over so the linear materializer ignores it
+		if _, err := e.db.Exec(`UPDATE sequence_contacts SET status = 'flow' WHERE id = ?`, c.contactID); err != nil {
+			return moved, err
+		}
+		if pos.Status == repository.FlowPositionActive {
+			e.run(flow, &pos)
+		}
+		moved++
+	}
+	return moved, nil
+}
+
+// Enroll starts a contact on a sequence's flow. It returns false when the
+// sequence has no flow and should run as a linear sequence.
+func (e *SequenceFlowEngine) Enroll(sequenceID string, lead models.Lead) (bool, error) {
+	flow, err := e.repo.GetFlow(sequenceID)
+	if err != nil {
+		return false, fmt.Errorf("failed to load flow: %w", err)
+	}
+	if flow == nil {
+		return false, nil
+	}
+
+	pos := &repository.SequenceFlowPosition{
+		SequenceID: sequenceID,
+		UserID:     lead.UserID,
+		Phone:      lead.Phone,
+		Name:       lead.Name,
+		DeviceID:   lead.DeviceID,
+		DeviceName: lead.DeviceName,
+		NodeID:     flow.Start,
+	}
+	started, err := e.repo.StartPosition(pos)
+	if err != nil {
+		return true, fmt.Errorf("failed to store flow position: %w", err)
+	}
+	if started {
+		e.run(flow, pos)
+	}
+	return true, nil
+}
+
+// HandleMessageSent continues the flow waiting on a sent message
+func (e *SequenceFlowEngine) HandleMessageSent(messageID string) error {
+	pos, err := e.repo.ClaimSentPosition(messageID)
+	if err != nil || pos == nil {
+		return err
+	}
+
+	flow, err := e.repo.GetFlow(pos.SequenceID)
+	if err != nil {
+		return err
+	}
+	if flow == nil {
+		return e.fail(pos, "flow was removed")
+	}
+
+	node := flow.Node(pos.NodeID)
+	if node == nil {
+		return e.fail(pos, fmt.Sprintf("node %s no longer exists", pos.NodeID))
+	}
+	pos.NodeID = node.Next
+	pos.PendingMessageID = ""
+	e.run(flow, pos)
+	return nil
+}
+
+// Sweep resumes contacts whose wait has elapsed, catches sends the hook
+// missed and fails contacts whose message failed
+func (e *SequenceFlowEngine) Sweep(limit int) (int, error) {
+	if failed, err := e.repo.FailStalledSends(); err != nil {
+		logrus.Warnf("Failed to check stalled flow sends: %v", err)
+	} else if failed > 0 {
+		logrus.Infof("Marked %d flow contacts failed after their message failed", failed)
+	}
+
+	rows, err := e.db.Query(`
+		SELECT p.pending_message_id FROM sequence_flow_positions p
+		INNER JOIN broadcast_messages bm ON bm.id = p.pending_message_id
+		WHERE p.status = 'sending' AND bm.status = 'sent'
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return 0, err
+	}
+	var sent []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err == nil {
+			sent = append(sent, id)
+		}
+	}
+	rows.Close()
+
+	advanced := 0
+	for _, id := range sent {
+		if err := e.HandleMessageSent(id); err != nil {
+			logrus.Warnf("Failed to advance flow for message %s: %v", id, err)
+			continue
+		}
+		advanced++
+	}
+
+	due, err := e.repo.ClaimDuePositions(limit)
+	if err != nil {
+		return advanced, err
+	}
+	flows := make(map[string]*domainSequence.Flow)
+	for _, pos := range due {
+		flow, ok := flows[pos.SequenceID]
+		if !ok {
+			if flow, err = e.repo.GetFlow(pos.SequenceID); err != nil {
+				logrus.Warnf("Failed to load flow %s: %v", pos.SequenceID, err)
+				continue
+			}
+			flows[pos.SequenceID] = flow
+		}
+		if flow == nil {
+			e.fail(pos, "flow was removed")
+			continue
+		}
+		e.run(flow, pos)
+		advanced++
+	}
+	return advanced, nil
+}
+
+// run advances a claimed position until it parks on a wait or send node or finishes
+func (e *SequenceFlowEngine) run(flow *domainSequence.Flow, pos *repository.SequenceFlowPosition) {
+	for hop := 0; hop < maxFlowHops; hop++ {
+		node := flow.Node(pos.NodeID)
+		if node == nil {
+			e.fail(pos, fmt.Sprintf("node %q does not exist", pos.NodeID))
+			return
+		}
+
+		next, park, err := e.execute(flow, node, pos)
+		if err != nil {
+			e.fail(pos, fmt.Sprintf("%s: %v", node.ID, err))
+			return
+		}
+		if park {
+			if err := e.repo.SavePosition(pos); err != nil {
+				logrus.Errorf("Failed to save flow position for %s: %v", pos.Phone, err)
+			}
+			return
+		}
+		if next == "" {
+			return
+		}
+		pos.NodeID = next
+		pos.WaitUntil = nil
+	}
+	e.fail(pos, "too many steps in a single run")
+}
+
+// execute runs one node and returns the next node, or park=true when the
+// position should stop here for now
+func (e *SequenceFlowEngine) execute(flow *domainSequence.Flow, node *domainSequence.FlowNode, pos *repository.SequenceFlowPosition) (string, bool, error) {
+	now := time.Now()
+	switch node.Type {
+	case domainSequence.FlowNodeSend:
+		return e.send(flow, node, pos)
+
+	case domainSequence.FlowNodeWait, domainSequence.FlowNodeWaitUntil:
+		if pos.WaitUntil != nil {
+			if !now.Before(*pos.WaitUntil) {
+				return node.Next, false, nil
+			}
+			pos.Status = repository.FlowPositionWaiting
+			return "", true, nil
+		}
+		var until time.Time
+		if node.Type == domainSequence.FlowNodeWait {
+			until = now.Add(time.Duration(node.DelayHours)*time.Hour + time.Duration(node.DelayMinutes)*time.Minute)
+		} else {
+			var err error
+			if until, err = nextTimeOfDay(now, node.TimeOfDay, e.location); err != nil {
+				return "", false, err
+			}
+		}
+		pos.WaitUntil = &until
+		pos.Status = repository.FlowPositionWaiting
+		return "", true, nil
+
+	case domainSequence.FlowNodeCondition:
+		matched, err := e.evaluate(node.Condition, pos)
+		if err != nil {
+			return "", false, err
+		}
+		if matched {
+			return node.TrueNext, false, nil
+		}
+		return node.FalseNext, false, nil
+
+	case domainSequence.FlowNodeSplit:
+		return pickSplitBranch(node.Branches, pos.Phone, node.ID), false, nil
+
+	case domainSequence.FlowNodeAddTag:
+		return node.Next, false, e.repo.AddLeadTag(pos.UserID, pos.Phone, node.Tag, repository.LeadActorFlow(pos.SequenceID))
+
+	case domainSequence.FlowNodeRemoveTag:
+		return node.Next, false, e.repo.RemoveLeadTag(pos.UserID, pos.Phone, node.Tag, repository.LeadActorFlow(pos.SequenceID))
+
+	case domainSequence.FlowNodeLeadStatus:
+		err := repository.GetLeadRepository().SetTargetStatus(pos.UserID, pos.Phone, node.Status,
+			repository.LeadActorFlow(pos.SequenceID))
+		return node.Next, false, err
+
+	case domainSequence.FlowNodeWebhook:
+		// A failing webhook is logged but never blocks the lead
+		if err := e.callWebhook(node, pos); err != nil {
+			logrus.Warnf("Flow webhook %s failed for %s: %v", node.ID, pos.Phone, err)
+		}
+		return node.Next, false, nil
+
+	case domainSequence.FlowNodeEnd:
+		pos.Status = repository.FlowPositionCompleted
+		pos.WaitUntil = nil
+		pos.PendingMessageID = ""
+		if err := e.repo.SavePosition(pos); err != nil {
+			return "", false, err
+		}
+		if node.NextSequenceID != "" {
+			lead := models.Lead{
+				UserID:     pos.UserID,
+				Phone:      pos.Phone,
+				Name:       pos.Name,
+				DeviceID:   pos.DeviceID,
+				DeviceName: pos.DeviceName,
+			}
+			if err := NewSequenceStepMaterializer(e.db).Enroll(node.NextSequenceID, lead); err != nil {
+				logrus.Warnf("Failed to continue %s into sequence %s: %v", pos.Phone, node.NextSequenceID, err)
+			}
+		}
+		return "", false, nil
+	}
+	return "", false, fmt.Errorf("unknown node type %q", node.Type)
+}
+
+// send queues the message for a send node and parks the position until it is sent
+func (e *SequenceFlowEngine) send(flow *domainSequence.Flow, node *domainSequence.FlowNode, pos *repository.SequenceFlowPosition) (string, bool, error) {
+	messageType, content, mediaURL, payload := node.MessageType, node.Content, node.MediaURL, node.Payload
+	minDelay, maxDelay := node.MinDelaySeconds, node.MaxDelaySeconds
+	var stepID *string
+
+	if node.StepID != "" {
+		// Linked steps are read now so edits to the step apply immediately
+		step, err := NewSequenceStepMaterializer(e.db).loadStep(`ss.id = ?`, node.StepID)
+		if err != nil {
+			return "", false, err
+		}
+		if step != nil {
+			messageType, content, mediaURL, payload = step.MessageType, step.Content, step.MediaURL, step.Payload
+			minDelay, maxDelay = step.MinDelay, step.MaxDelay
+			stepID = &step.ID
+		}
+	}
+	messageType = domainBroadcast.ResolveMessageType(messageType, mediaURL)
+	if minDelay <= 0 {
+		minDelay = 5
+	}
+	if maxDelay < minDelay {
+		maxDelay = minDelay
+	}
+
+	// Park before queueing so a fast send always finds the position
+	messageID := uuid.New().String()
+	pos.Status = repository.FlowPositionSending
+	pos.PendingMessageID = messageID
+	if err := e.repo.SavePosition(pos); err != nil {
+		return "", false, err
+	}
+
+	sequenceID := flow.SequenceID
+	msg := domainBroadcast.BroadcastMessage{
+		ID:             messageID,
+		UserID:         pos.UserID,
+		DeviceID:       pos.DeviceName, // Use device_name for message sending
+		DeviceName:     pos.DeviceName,
+		SequenceID:     &sequenceID,
+		SequenceStepID: stepID,
+		RecipientPhone: pos.Phone,
+		RecipientName:  pos.Name,
+		Message:        content,
+		Content:        content,
+		Type:           messageType,
+		MediaURL:       mediaURL,
+		ImageURL:       mediaURL,
+		Payload:        payload,
+		MinDelay:       minDelay,
+		MaxDelay:       maxDelay,
+		ScheduledAt:    time.Now(),
+		Status:         "pending",
+	}
+	if err := repository.GetBroadcastRepository().QueueMessage(msg); err != nil {
+		return "", false, err
+	}
+
+	var exists int
+	err := e.db.QueryRow(`SELECT COUNT(*) FROM broadcast_messages WHERE id = ?`, messageID).Scan(&exists)
+	if err != nil {
+		return "", false, err
+	}
+	if exists == 0 {
+		// The step was already queued or sent to this contact; don't wait on it
+		pos.Status = repository.FlowPositionActive
+		pos.PendingMessageID = ""
+		return node.Next, false, nil
+	}
+	return "", true, nil
+}
+
+// evaluate checks a condition node against the lead
+func (e *SequenceFlowEngine) evaluate(condition *domainSequence.FlowCondition, pos *repository.SequenceFlowPosition) (bool, error) {
+	if condition == nil {
+		return false, fmt.Errorf("missing condition")
+	}
+	since := pos.EnteredAt
+	if condition.WithinHours > 0 {
+		since = time.Now().Add(-time.Duration(condition.WithinHours) * time.Hour)
+	}
+	jidPattern := strings.TrimPrefix(pos.Phone, "+") + "@%"
+
+	var count int
+	switch condition.Kind {
+	case domainSequence.FlowConditionReplied:
+		// Only a reply to one of the flow owner's devices counts; another
+		// user may be chatting with the same phone
+		err := e.db.QueryRow(`
+			SELECT COUNT(*) FROM whatsapp_messages
+			WHERE device_id IN (SELECT id FROM user_devices WHERE user_id = ?)
+				AND chat_jid LIKE ? AND sender_jid LIKE ? AND timestamp >= ?
+		`, pos.UserID, jidPattern, jidPattern, since.Unix()).Scan(&count)
+		return count > 0, err
+
+	case domainSequence.FlowConditionRead:
+		err := e.db.QueryRow(`
+			SELECT COUNT(*) FROM message_analytics
+			WHERE user_id = ? AND jid LIKE ? AND is_from_me = true AND `+"`status`"+` = 'read' AND created_at >= ?
+		`, pos.UserID, jidPattern, since).Scan(&count)
+		return count > 0, err
+
+	case domainSequence.FlowConditionTag:
+		return e.repo.HasLeadTag(pos.UserID, pos.Phone, condition.Tag)
+
+	case domainSequence.FlowConditionField:
+		column, ok := flowLeadFields[condition.Field]
+		if !ok {
+			return false, fmt.Errorf("unknown lead field %q", condition.Field)
+		}
+		var value sql.NullString
+		err := e.db.QueryRow(`SELECT `+"`"+column+"`"+` FROM leads WHERE phone = ? AND user_id = ? LIMIT 1`,
+			pos.Phone, pos.UserID).Scan(&value)
+		if err != nil && err != sql.ErrNoRows {
+			return false, err
+		}
+		return matchFieldCondition(condition, value.String), nil
+	}
+	return false, fmt.Errorf("unknown condition kind %q", condition.Kind)
+}
+
+// callWebhook posts the lead and flow position to a webhook node's URL
+func (e *SequenceFlowEngine) callWebhook(node *domainSequence.FlowNode, pos *repository.SequenceFlowPosition) error {
+	payload, err := json.Marshal(map[string]interface{}{
+		"event":       "sequence_flow",
+		"sequence_id": pos.SequenceID,
+		"node_id":     node.ID,
+		"phone":       pos.Phone,
+		"name":        pos.Name,
+		"user_id":     pos.UserID,
+		"device_id":   pos.DeviceID,
+		"timestamp":   time.Now().Unix(),
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := e.httpClient.Post(node.URL, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// fail stops a position with an error
+func (e *SequenceFlowEngine) fail(pos *repository.SequenceFlowPosition, reason string) error {
+	logrus.Warnf("Flow for %s in sequence %s failed: %s", pos.Phone, pos.SequenceID, reason)
+	pos.Status = repository.FlowPositionFailed
+	pos.LastError = reason
+	return e.repo.SavePosition(pos)
+}
+
+var startFlowEngineOnce sync.Once
+
+// StartSequenceFlowEngine hooks the flow engine into message sends and
+// resumes waiting contacts every minute
+func StartSequenceFlowEngine() {
+	startFlowEngineOnce.Do(func() {
+		engine := NewSequenceFlowEngine(database.GetDB())
+
+		repository.OnMessageSent(func(messageID string) {
+			if err := engine.HandleMessageSent(messageID); err != nil {
+				logrus.Warnf("Failed to advance sequence flow for %s: %v", messageID, err)
+			}
+		})
+
+		scheduler.Default().MustRegister(scheduler.Job{
+			Name:      "sequence-flow-sweep",
+			Interval:  time.Minute,
+			Singleton: true,
+			Run: func(ctx context.Context) error {
+				count, err := engine.Sweep(500)
+				if err != nil {
+					return fmt.Errorf("sequence flow sweep failed: %w", err)
+				}
+				if count > 0 {
+					logrus.Infof("Sequence flow sweep advanced %d contacts", count)
+				}
+				return nil
+			},
+		})
+
+		logrus.Info("Sequence flow engine started")
+	})
+}
@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	domainSequence "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/sequence"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateFlow(t *testing.T) {
+	valid := func() *domainSequence.Flow {
+		return &domainSequence.Flow{
+			Version: domainSequence.FlowVersion,
+			Start:   "hello",
+			Nodes: []domainSequence.FlowNode{
+				{ID: "hello", Type: domainSequence.FlowNodeSend, Content: "Hi", Next: "wait"},
+				{ID: "wait", Type: domainSequence.FlowNodeWait, DelayHours: 24, Next: "replied"},
+				{ID: "replied", Type: domainSequence.FlowNodeCondition,
+					Condition: &domainSequence.FlowCondition{Kind: domainSequence.FlowConditionReplied},
+					TrueNext:  "tag", FalseNext: "split"},
+				{ID: "tag", Type: domainSequence.FlowNodeAddTag, Tag: "engaged", Next: "end"},
+				{ID: "split", Type: domainSequence.FlowNodeSplit, Branches: []domainSequence.FlowSplitBranch{
+					{Percent: 50, Next: "end"}, {Percent: 50, Next: "morning"},
+				}},
+				{ID: "morning", Type: domainSequence.FlowNodeWaitUntil, TimeOfDay: "09:00", Next: "end"},
+				{ID: "end", Type: domainSequence.FlowNodeEnd},
+			},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(f *domainSequence.Flow)
+		want   []string // node IDs with problems; empty means valid
+	}{
+		{name: "valid", modify: func(f *domainSequence.Flow) {}},
+		{name: "missing target", modify: func(f *domainSequence.Flow) {
+			f.Node("tag").Next = "nowhere"
+		}, want: []string{"tag"}},
+		{name: "cycle", modify: func(f *domainSequence.Flow) {
+			f.Node("morning").Next = "wait"
+		}, want: []string{"wait"}},
+		{name: "unreachable", modify: func(f *domainSequence.Flow) {
+			f.Nodes = append(f.Nodes, domainSequence.FlowNode{ID: "orphan", Type: domainSequence.FlowNodeEnd})
+		}, want: []string{"orphan"}},
+		{name: "split not 100", modify: func(f *domainSequence.Flow) {
+			f.Node("split").Branches[0].Percent = 40
+		}, want: []string{"split"}},
+		{name: "bad time of day", modify: func(f *domainSequence.Flow) {
+			f.Node("morning").TimeOfDay = "9am"
+		}, want: []string{"morning"}},
+		{name: "unknown field", modify: func(f *domainSequence.Flow) {
+			f.Node("replied").Condition = &domainSequence.FlowCondition{Kind: domainSequence.FlowConditionField, Field: "salary", Operator: "equals"}
+		}, want: []string{"replied"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flow := valid()
+			tt.modify(flow)
+
+			var got []string
+			for _, problem := range ValidateFlow(flow) {
+				got = append(got, problem.NodeID)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestConvertLinearSequence(t *testing.T) {
+	steps := []materializedStep{
+		{ID: "s1", DayNumber: 1, Content: "Day one", MessageType: "text", TriggerDelayHours: 48},
+		{ID: "s2", DayNumber: 2, Content: "Day two", MessageType: "text"},
+	}
+
+	flow := ConvertLinearSequence("seq", "Cold", steps, "warm")
+	assert.Empty(t, ValidateFlow(flow))
+	assert.True(t, flow.Converted)
+	assert.Equal(t, "wait_start", flow.Start)
+	assert.Equal(t, "s1", flow.Node("send_1").StepID)
+	assert.Equal(t, 48, flow.Node("wait_1").DelayHours)
+	assert.Equal(t, "end", flow.Node("send_2").Next)
+	assert.Equal(t, "warm", flow.Node("end").NextSequenceID)
+
+	empty := ConvertLinearSequence("seq", "Empty", nil, "")
+	assert.Empty(t, ValidateFlow(empty))
+}
+
+func TestPickSplitBranch(t *testing.T) {
+	branches := []domainSequence.FlowSplitBranch{{Percent: 30, Next: "a"}, {Percent: 70, Next: "b"}}
+
+	// Stable for the same lead
+	assert.Equal(t, pickSplitBranch(branches, "60123", "split"), pickSplitBranch(branches, "60123", "split"))
+
+	counts := map[string]int{}
+	for i := 0; i < 2000; i++ {
+		counts[pickSplitBranch(branches, "60"+time.Duration(i).String(), "split")]++
+	}
+	assert.InDelta(t, 600, counts["a"], 120)
+	assert.InDelta(t, 1400, counts["b"], 120)
+}
+
+func TestNextTimeOfDay(t *testing.T) {
+	loc := time.FixedZone("MYT", 8*60*60)
+	now := time.Date(2026, 1, 1, 10, 0, 0, 0, loc)
+
+	later, err := nextTimeOfDay(now, "18:30", loc)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 1, 1, 18, 30, 0, 0, loc), later)
+
+	tomorrow, err := nextTimeOfDay(now, "09:00", loc)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 1, 2, 9, 0, 0, 0, loc), tomorrow)
+}
@@ -63,6 +63,14 @@ func (s *SequenceFlowUpdater) FlowUpdate(sequenceID string) (int, int, error) {
 		FROM broadcast_messages bm
 		JOIN sequence_steps ss ON bm.sequence_stepid = ss.id
 		WHERE bm.sequence_id = ?
+			-- Contacts tracked by position pick up new steps when their next message is created
+			AND NOT EXISTS (
+				SELECT 1 FROM sequence_contacts sc
+				WHERE sc.sequence_id = bm.sequence_id
+					AND sc.contact_phone = bm.recipient_phone
+					AND sc.sequence_stepid IS NOT NULL
+					AND sc.status IN ('active', 'paused')
+			)
 		GROUP BY bm.device_name, bm.recipient_phone, bm.recipient_name, bm.user_id
 	`
 	
@@ -127,6 +135,7 @@ func (s *SequenceFlowUpdater) FlowUpdate(sequenceID string) (int, int, error) {
 				COALESCE(content, '') as content,
 				COALESCE(message_text, '') as message_text,
 				media_url,
+				COALESCE(message_payload, '') as message_payload,
 				COALESCE(min_delay_seconds, 5) as min_delay,
 				COALESCE(max_delay_seconds, 15) as max_delay
 			FROM sequence_steps
@@ -157,12 +166,13 @@ func (s *SequenceFlowUpdater) FlowUpdate(sequenceID string) (int, int, error) {
 				Content     string
 				MessageText string
 				MediaURL    sql.NullString
+				Payload     string
 				MinDelay    int
 				MaxDelay    int
 			}
 			
 			err := stepRows.Scan(&step.ID, &step.DayNumber, &step.MessageType, 
-				&step.Content, &step.MessageText, &step.MediaURL, &step.MinDelay, &step.MaxDelay)
+				&step.Content, &step.MessageText, &step.MediaURL, &step.Payload, &step.MinDelay, &step.MaxDelay)
 			if err != nil {
 				logrus.Warnf("⚠️ Error scanning step: %v", err)
 				continue
@@ -205,6 +215,7 @@ func (s *SequenceFlowUpdater) FlowUpdate(sequenceID string) (int, int, error) {
 				Message:        messageContent,
 				Content:        messageContent,
 				Type:           step.MessageType,
+				Payload:        domainBroadcast.DecodePayload(step.Payload),
 				MinDelay:       step.MinDelay,
 				MaxDelay:       step.MaxDelay,
 				ScheduledAt:    scheduleDate,
@@ -0,0 +1,628 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/database"
+	domainBroadcast "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/broadcast"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/scheduler"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/models"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/clock"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// SequenceStepMaterializer keeps only the contact's position in sequence_contacts
+// and creates the next broadcast message once the previous one is confirmed sent.
+// Step content is read when the message is created, so step edits apply immediately.
+type SequenceStepMaterializer struct {
+	db *sql.DB
+}
+
+// NewSequenceStepMaterializer creates a new materializer
+func NewSequenceStepMaterializer(db *sql.DB) *SequenceStepMaterializer {
+	repository.SequenceContactSchema.Ensure(db)
+	return &SequenceStepMaterializer{db: db}
+}
+
+// materializedStep is the subset of sequence_steps needed to queue a message
+type materializedStep struct {
+	ID                string
+	SequenceID        string
+	DayNumber         int
+	Trigger           string
+	NextTrigger       string
+	TriggerDelayHours int
+	MessageType       string
+	Content           string
+	MediaURL          string
+	Payload           *domainBroadcast.MessagePayload
+	MinDelay          int
+	MaxDelay          int
+}
+
+// contactPosition identifies where a contact is and which device sends to it
+type contactPosition struct {
+	UserID     string
+	Phone      string
+	Name       string
+	DeviceID   string // device UUID, stored on the contact
+	DeviceName string // device name, used on broadcast_messages
+	EnrolledAt time.Time // start of the contact's current run, zero for older rows
+}
+
+// MigrationSummary reports what MigratePreQueued found or changed
+type MigrationSummary struct {
+	Contacts        int  `json:"contacts"`
+	KeptMessages    int  `json:"kept_messages"`
+	RemovedMessages int  `json:"removed_messages"`
+	Applied         bool `json:"applied"`
+}
+
+// delay returns how long after this step is sent the next one is due
+func (s materializedStep) delay() time.Duration {
+	if s.TriggerDelayHours > 0 {
+		return time.Duration(s.TriggerDelayHours) * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// chainsTo reports whether the step's next_trigger links to another sequence
+func (s materializedStep) chainsTo() string {
+	if s.NextTrigger == "" || strings.Contains(s.NextTrigger, "_day") {
+		return ""
+	}
+	return s.NextTrigger
+}
+
+const materializedStepColumns = `
+	ss.id, ss.sequence_id, COALESCE(ss.day_number, 0), COALESCE(ss.` + "`trigger`" + `, ''),
+	COALESCE(ss.next_trigger, ''), COALESCE(ss.trigger_delay_hours, 0),
+	COALESCE(ss.message_type, 'text'), COALESCE(ss.content, ''), COALESCE(ss.media_url, ''),
+	COALESCE(ss.message_payload, ''),
+	COALESCE(ss.min_delay_seconds, s.min_delay_seconds, 5),
+	COALESCE(ss.max_delay_seconds, s.max_delay_seconds, 15)`
+
+// loadStep loads a single step matching the given condition
+func (m *SequenceStepMaterializer) loadStep(condition string, args ...interface{}) (*materializedStep, error) {
+	query := `SELECT ` + materializedStepColumns + `
+		FROM sequence_steps ss
+		INNER JOIN sequences s ON s.id = ss.sequence_id
+		WHERE ` + condition + ` LIMIT 1`
+
+	var step materializedStep
+	var payload string
+	err := m.db.QueryRow(query, args...).Scan(&step.ID, &step.SequenceID, &step.DayNumber, &step.Trigger,
+		&step.NextTrigger, &step.TriggerDelayHours, &step.MessageType, &step.Content, &step.MediaURL, &payload,
+		&step.MinDelay, &step.MaxDelay)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	step.Payload = domainBroadcast.DecodePayload(payload)
+	return &step, nil
+}
+
+// steps returns every step of a sequence in day order
+func (m *SequenceStepMaterializer) steps(sequenceID string) ([]materializedStep, error) {
+	rows, err := m.db.Query(`SELECT `+materializedStepColumns+`
+		FROM sequence_steps ss
+		INNER JOIN sequences s ON s.id = ss.sequence_id
+		WHERE ss.sequence_id = ?
+		ORDER BY ss.day_number ASC`, sequenceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var steps []materializedStep
+	for rows.Next() {
+		var step materializedStep
+		var payload string
+		if err := rows.Scan(&step.ID, &step.SequenceID, &step.DayNumber, &step.Trigger,
+			&step.NextTrigger, &step.TriggerDelayHours, &step.MessageType, &step.Content, &step.MediaURL, &payload,
+			&step.MinDelay, &step.MaxDelay); err != nil {
+			return nil, err
+		}
+		step.Payload = domainBroadcast.DecodePayload(payload)
+		steps = append(steps, step)
+	}
+	return steps, rows.Err()
+}
+
+// entryStep returns the first step of a sequence
+func (m *SequenceStepMaterializer) entryStep(sequenceID string) (*materializedStep, error) {
+	return m.loadStep(`ss.sequence_id = ? ORDER BY ss.is_entry_point DESC, ss.day_number ASC`, sequenceID)
+}
+
+// nextStep returns the step after the given one within the same sequence, or
+// the entry step of the linked sequence when the sequence is finished
+func (m *SequenceStepMaterializer) nextStep(current *materializedStep) (*materializedStep, error) {
+	step, err := m.loadStep(`ss.sequence_id = ? AND ss.day_number > ? ORDER BY ss.day_number ASC`,
+		current.SequenceID, current.DayNumber)
+	if err != nil || step != nil {
+		return step, err
+	}
+
+	trigger := current.chainsTo()
+	if trigger == "" {
+		return nil, nil
+	}
+	return m.loadStep(`s.is_active = true AND ss.is_entry_point = true AND ss.`+"`trigger`"+` = ? AND ss.sequence_id != ?`,
+		trigger, current.SequenceID)
+}
+
+// queueStep creates the broadcast message for a step
+func (m *SequenceStepMaterializer) queueStep(pos contactPosition, step *materializedStep, scheduledAt time.Time) error {
+	sequenceID := step.SequenceID
+	stepID := step.ID
+	msg := domainBroadcast.BroadcastMessage{
+		UserID:         pos.UserID,
+		DeviceID:       pos.DeviceName, // Use device_name for message sending
+		DeviceName:     pos.DeviceName,
+		SequenceID:     &sequenceID,
+		SequenceStepID: &stepID,
+		RecipientPhone: pos.Phone,
+		RecipientName:  pos.Name,
+		Message:        step.Content,
+		Content:        step.Content,
+		Type:           step.MessageType,
+		Payload:        step.Payload,
+		MinDelay:       step.MinDelay,
+		MaxDelay:       step.MaxDelay,
+		ScheduledAt:    scheduledAt,
+		Status:         "pending",
+		EnrolledAt:     pos.EnrolledAt,
+	}
+	if step.MediaURL != "" {
+		msg.MediaURL = step.MediaURL
+		msg.ImageURL = step.MediaURL
+	}
+
+	return repository.GetBroadcastRepository().QueueMessage(msg)
+}
+
+// upsertPosition stores the contact at the given step. Contacts that are
+// already active or paused keep their position; any other contact starts a
+// new run.
+func (m *SequenceStepMaterializer) upsertPosition(pos contactPosition, step *materializedStep, dueAt time.Time) error {
+	_, err := m.db.Exec(`
+		INSERT INTO sequence_contacts (
+			id, sequence_id, contact_phone, contact_name, current_step, status,
+			current_trigger, next_trigger_time, assigned_device_id, sequence_stepid, user_id, enrolled_at
+		) VALUES (?, ?, ?, ?, ?, 'active', ?, ?, ?, ?, ?, NOW())
+		ON DUPLICATE KEY UPDATE
+			current_step = IF(status IN ('active', 'paused'), current_step, VALUES(current_step)),
+			current_trigger = IF(status IN ('active', 'paused'), current_trigger, VALUES(current_trigger)),
+			next_trigger_time = IF(status IN ('active', 'paused'), next_trigger_time, VALUES(next_trigger_time)),
+			sequence_stepid = IF(status IN ('active', 'paused'), sequence_stepid, VALUES(sequence_stepid)),
+			completed_at = IF(status IN ('active', 'paused'), completed_at, NULL),
+			enrolled_at = IF(status IN ('active', 'paused'), enrolled_at, NOW()),
+			status = IF(status IN ('active', 'paused'), status, 'active')
+	`, uuid.New().String(), step.SequenceID, pos.Phone, pos.Name, step.DayNumber,
+		step.Trigger, dueAt, pos.DeviceID, step.ID, pos.UserID)
+	return err
+}
+
+// Enroll stores the lead's position at the sequence entry step and queues only
+// that step. Sequences with a saved flow are handed to the flow engine.
+func (m *SequenceStepMaterializer) Enroll(sequenceID string, lead models.Lead) error {
+	if handled, err := NewSequenceFlowEngine(m.db).Enroll(sequenceID, lead); handled || err != nil {
+		return err
+	}
+
+	step, err := m.entryStep(sequenceID)
+	if err != nil {
+		return fmt.Errorf("failed to load entry step: %w", err)
+	}
+	if step == nil {
+		return fmt.Errorf("sequence %s has no steps", sequenceID)
+	}
+
+	pos := contactPosition{
+		UserID:     lead.UserID,
+		Phone:      lead.Phone,
+		Name:       lead.Name,
+		DeviceID:   lead.DeviceID,
+		DeviceName: lead.DeviceName,
+	}
+	scheduledAt := clock.Now().Add(24 * time.Hour).Add(5 * time.Minute) // First message in 24 hours + 5 minutes
+	return m.enrollAt(pos, step, scheduledAt)
+}
+
+// enrollAt stores the contact at step and queues the step when the contact
+// actually sits on it; an existing enrollment further along is left alone
+func (m *SequenceStepMaterializer) enrollAt(pos contactPosition, step *materializedStep, dueAt time.Time) error {
+	if err := m.upsertPosition(pos, step, dueAt); err != nil {
+		return fmt.Errorf("failed to store contact position: %w", err)
+	}
+
+	var currentStepID, status string
+	var enrolledAt sql.NullTime
+	err := m.db.QueryRow(`
+		SELECT COALESCE(sequence_stepid, ''), status, enrolled_at FROM sequence_contacts
+		WHERE sequence_id = ? AND contact_phone = ?
+	`, step.SequenceID, pos.Phone).Scan(&currentStepID, &status, &enrolledAt)
+	if err != nil {
+		return fmt.Errorf("failed to read contact position: %w", err)
+	}
+	if currentStepID != step.ID || status != "active" {
+		return nil
+	}
+
+	pos.EnrolledAt = enrolledAt.Time
+	return m.queueStep(pos, step, dueAt)
+}
+
+// HandleMessageSent advances the contact behind a sent sequence message and
+// queues its next step. Messages that do not match the contact's current
+// position (campaigns, legacy pre-queued rows, repeats, sends from an earlier
+// run of a re-enrolled contact) are ignored.
+func (m *SequenceStepMaterializer) HandleMessageSent(messageID string) error {
+	var sequenceID, stepID string
+	var pos contactPosition
+	var sentAt time.Time
+	err := m.db.QueryRow(`
+		SELECT bm.sequence_id, bm.sequence_stepid, bm.recipient_phone, COALESCE(bm.recipient_name, ''),
+			bm.user_id, COALESCE(NULLIF(bm.device_name, ''), bm.device_id), COALESCE(bm.sent_at, NOW())
+		FROM broadcast_messages bm
+		WHERE bm.id = ? AND bm.status = 'sent'
+			AND bm.sequence_id IS NOT NULL AND bm.sequence_stepid IS NOT NULL
+	`, messageID).Scan(&sequenceID, &stepID, &pos.Phone, &pos.Name, &pos.UserID, &pos.DeviceName, &sentAt)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load sent message: %w", err)
+	}
+
+	var contactID string
+	var enrolledAt sql.NullTime
+	err = m.db.QueryRow(`
+		SELECT id, COALESCE(assigned_device_id, ''), enrolled_at FROM sequence_contacts
+		WHERE sequence_id = ? AND contact_phone = ? AND status = 'active' AND sequence_stepid = ?
+			AND (enrolled_at IS NULL OR enrolled_at <= ?)
+	`, sequenceID, pos.Phone, stepID, sentAt).Scan(&contactID, &pos.DeviceID, &enrolledAt)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load contact position: %w", err)
+	}
+	pos.EnrolledAt = enrolledAt.Time
+
+	current, err := m.loadStep(`ss.id = ?`, stepID)
+	if err != nil {
+		return fmt.Errorf("failed to load current step: %w", err)
+	}
+	if current == nil {
+		// The step was deleted after the send, so the contact cannot move
+		// on; ending its run keeps it out of the catch-up sweep
+		_, err = m.db.Exec(`
+			UPDATE sequence_contacts SET status = 'completed', completed_at = NOW()
+			WHERE id = ? AND sequence_stepid = ? AND status = 'active'
+		`, contactID, stepID)
+		if err == nil {
+			logrus.Infof("Sequence step %s is gone, completed contact %s", stepID, pos.Phone)
+		}
+		return err
+	}
+
+	next, err := m.nextStep(current)
+	if err != nil {
+		return fmt.Errorf("failed to find next step: %w", err)
+	}
+	dueAt := sentAt.Add(current.delay())
+
+	if next == nil {
+		_, err = m.db.Exec(`
+			UPDATE sequence_contacts SET status = 'completed', completed_at = NOW()
+			WHERE id = ? AND sequence_stepid = ? AND status = 'active'
+		`, contactID, stepID)
+		if err == nil {
+			logrus.Infof("Sequence chain completed for %s", pos.Phone)
+		}
+		return err
+	}
+
+	// The next step is queued before the contact moves on, so a failure
+	// leaves the contact on the sent step for CatchUp to retry. Queueing
+	// again is harmless since QueueMessage skips a step already queued.
+	if next.SequenceID == current.SequenceID {
+		if err := m.queueStep(pos, next, dueAt); err != nil {
+			return fmt.Errorf("failed to queue next step: %w", err)
+		}
+		if _, err := m.db.Exec(`
+			UPDATE sequence_contacts
+			SET sequence_stepid = ?, current_step = ?, current_trigger = ?, next_trigger_time = ?
+			WHERE id = ? AND sequence_stepid = ? AND status = 'active'
+		`, next.ID, next.DayNumber, next.Trigger, dueAt, contactID, stepID); err != nil {
+			return fmt.Errorf("failed to advance contact: %w", err)
+		}
+	} else {
+		lead := models.Lead{UserID: pos.UserID, Phone: pos.Phone, Name: pos.Name, DeviceID: pos.DeviceID, DeviceName: pos.DeviceName}
+		handled, err := NewSequenceFlowEngine(m.db).Enroll(next.SequenceID, lead)
+		if err != nil {
+			return err
+		}
+		if !handled {
+			if err := m.enrollAt(pos, next, dueAt); err != nil {
+				return fmt.Errorf("failed to move contact to linked sequence: %w", err)
+			}
+		}
+		if _, err := m.db.Exec(`
+			UPDATE sequence_contacts SET status = 'completed', completed_at = NOW()
+			WHERE id = ? AND sequence_stepid = ? AND status = 'active'
+		`, contactID, stepID); err != nil {
+			return fmt.Errorf("failed to complete contact: %w", err)
+		}
+		if handled {
+			return nil
+		}
+	}
+
+	logrus.Debugf("Materialized step %d of sequence %s for %s at %s",
+		next.DayNumber, next.SequenceID, pos.Phone, dueAt.Format(time.RFC3339))
+	return nil
+}
+
+// CatchUp advances contacts whose current step was sent while no hook was
+// listening, e.g. by another process or before a restart
+func (m *SequenceStepMaterializer) CatchUp(limit int) (int, error) {
+	rows, err := m.db.Query(`
+		SELECT bm.id
+		FROM sequence_contacts sc
+		INNER JOIN broadcast_messages bm
+			ON bm.sequence_id = sc.sequence_id
+			AND bm.sequence_stepid = sc.sequence_stepid
+			AND bm.recipient_phone = sc.contact_phone
+		WHERE sc.status = 'active' AND bm.status = 'sent'
+			AND (sc.enrolled_at IS NULL OR bm.sent_at >= sc.enrolled_at)
+		ORDER BY bm.sent_at, bm.id
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	var messageIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err == nil {
+			messageIDs = append(messageIDs, id)
+		}
+	}
+	rows.Close()
+
+	advanced := 0
+	for _, id := range messageIDs {
+		if err := m.HandleMessageSent(id); err != nil {
+			logrus.Warnf("Failed to advance sequence contact for message %s: %v", id, err)
+			continue
+		}
+		advanced++
+	}
+	return advanced, nil
+}
+
+// preQueuedRow is a pending sequence message created by the old eager enrollment
+type preQueuedRow struct {
+	ID          string
+	SequenceID  string
+	StepID      string
+	Phone       string
+	DeviceID    string
+	ScheduledAt time.Time
+}
+
+// planPreQueuedPrune decides which pre-queued rows to keep. For every
+// recipient only the earliest row of a chain survives; later rows of the same
+// sequence, or of sequences linked after it, are dropped. Rows are grouped by
+// phone alone since a contact has one position per sequence, whichever
+// device sends to it.
+func planPreQueuedPrune(rows []preQueuedRow, successors map[string]string) (keep, drop []preQueuedRow) {
+	grouped := make(map[string][]preQueuedRow)
+	var keys []string
+	for _, row := range rows {
+		key := row.Phone
+		if _, ok := grouped[key]; !ok {
+			keys = append(keys, key)
+		}
+		grouped[key] = append(grouped[key], row)
+	}
+
+	for _, key := range keys {
+		group := grouped[key]
+		sort.SliceStable(group, func(i, j int) bool {
+			return group[i].ScheduledAt.Before(group[j].ScheduledAt)
+		})
+
+		covered := make(map[string]bool)
+		for _, row := range group {
+			if covered[row.SequenceID] {
+				drop = append(drop, row)
+				continue
+			}
+			keep = append(keep, row)
+			for seq := row.SequenceID; seq != "" && !covered[seq]; seq = successors[seq] {
+				covered[seq] = true
+			}
+		}
+	}
+	return keep, drop
+}
+
+// sequenceSuccessors maps each sequence to the sequence its last step links to
+func (m *SequenceStepMaterializer) sequenceSuccessors() (map[string]string, error) {
+	rows, err := m.db.Query(`
+		SELECT last.sequence_id, next_entry.sequence_id
+		FROM sequence_steps last
+		INNER JOIN sequence_steps next_entry
+			ON next_entry.is_entry_point = true
+			AND next_entry.` + "`trigger`" + ` = last.next_trigger
+			AND next_entry.sequence_id != last.sequence_id
+		WHERE last.next_trigger IS NOT NULL AND last.next_trigger != ''
+			AND last.next_trigger NOT LIKE '%_day%'
+			AND last.day_number = (
+				SELECT MAX(day_number) FROM sequence_steps WHERE sequence_id = last.sequence_id
+			)
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	successors := make(map[string]string)
+	for rows.Next() {
+		var from, to string
+		if err := rows.Scan(&from, &to); err != nil {
+			return nil, err
+		}
+		if _, ok := successors[from]; !ok {
+			successors[from] = to
+		}
+	}
+	return successors, rows.Err()
+}
+
+// MigratePreQueued converts messages queued by the old eager enrollment into
+// contact positions. With apply false it only reports what would change.
+func (m *SequenceStepMaterializer) MigratePreQueued(userID string, apply bool) (*MigrationSummary, error) {
+	successors, err := m.sequenceSuccessors()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load sequence links: %w", err)
+	}
+
+	query := `
+		SELECT id, sequence_id, sequence_stepid, recipient_phone, device_id, scheduled_at
+		FROM broadcast_messages
+		WHERE status = 'pending' AND processing_worker_id IS NULL
+			AND sequence_id IS NOT NULL AND sequence_stepid IS NOT NULL`
+	args := []interface{}{}
+	if userID != "" {
+		query += ` AND user_id = ?`
+		args = append(args, userID)
+	}
+
+	rows, err := m.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pending sequence messages: %w", err)
+	}
+	var pending []preQueuedRow
+	for rows.Next() {
+		var row preQueuedRow
+		var scheduledAt sql.NullTime
+		if err := rows.Scan(&row.ID, &row.SequenceID, &row.StepID, &row.Phone, &row.DeviceID, &scheduledAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		row.ScheduledAt = scheduledAt.Time
+		pending = append(pending, row)
+	}
+	rows.Close()
+
+	keep, drop := planPreQueuedPrune(pending, successors)
+	summary := &MigrationSummary{
+		Contacts:        len(keep),
+		KeptMessages:    len(keep),
+		RemovedMessages: len(drop),
+		Applied:         apply,
+	}
+	if !apply {
+		return summary, nil
+	}
+
+	for _, row := range drop {
+		if _, err := m.db.Exec(`
+			DELETE FROM broadcast_messages
+			WHERE id = ? AND status = 'pending' AND processing_worker_id IS NULL
+		`, row.ID); err != nil {
+			return summary, fmt.Errorf("failed to remove message %s: %w", row.ID, err)
+		}
+	}
+
+	for _, row := range keep {
+		step, err := m.loadStep(`ss.id = ?`, row.StepID)
+		if err != nil || step == nil {
+			logrus.Warnf("Skipping contact position for %s: step %s not found", row.Phone, row.StepID)
+			continue
+		}
+
+		var pos contactPosition
+		err = m.db.QueryRow(`
+			SELECT bm.user_id, bm.recipient_phone, COALESCE(bm.recipient_name, ''),
+				COALESCE(d.id, ''), COALESCE(NULLIF(bm.device_name, ''), bm.device_id)
+			FROM broadcast_messages bm
+			LEFT JOIN user_devices d ON d.id = bm.device_id OR d.device_name = bm.device_id
+			WHERE bm.id = ?
+			LIMIT 1
+		`, row.ID).Scan(&pos.UserID, &pos.Phone, &pos.Name, &pos.DeviceID, &pos.DeviceName)
+		if err != nil {
+			logrus.Warnf("Skipping contact position for %s: %v", row.Phone, err)
+			continue
+		}
+
+		if _, err := m.db.Exec(`
+			INSERT INTO sequence_contacts (
+				id, sequence_id, contact_phone, contact_name, current_step, status,
+				current_trigger, next_trigger_time, assigned_device_id, sequence_stepid, user_id
+			) VALUES (?, ?, ?, ?, ?, 'active', ?, ?, ?, ?, ?)
+			ON DUPLICATE KEY UPDATE
+				current_step = VALUES(current_step),
+				current_trigger = VALUES(current_trigger),
+				next_trigger_time = VALUES(next_trigger_time),
+				sequence_stepid = VALUES(sequence_stepid),
+				status = 'active',
+				completed_at = NULL
+		`, uuid.New().String(), step.SequenceID, pos.Phone, pos.Name, step.DayNumber,
+			step.Trigger, row.ScheduledAt, pos.DeviceID, step.ID, pos.UserID); err != nil {
+			return summary, fmt.Errorf("failed to store contact position for %s: %w", row.Phone, err)
+		}
+	}
+
+	logrus.Infof("Migrated pre-queued sequence messages: kept %d, removed %d", len(keep), len(drop))
+	return summary, nil
+}
+
+var startMaterializerOnce sync.Once
+
+// StartSequenceStepMaterializer hooks the materializer into message sends and
+// runs a periodic catch-up sweep
+func StartSequenceStepMaterializer() {
+	startMaterializerOnce.Do(func() {
+		materializer := NewSequenceStepMaterializer(database.GetDB())
+
+		repository.OnMessageSent(func(messageID string) {
+			if err := materializer.HandleMessageSent(messageID); err != nil {
+				logrus.Warnf("Failed to materialize next sequence step for %s: %v", messageID, err)
+			}
+		})
+
+		scheduler.Default().MustRegister(scheduler.Job{
+			Name:      "sequence-step-catchup",
+			Interval:  5 * time.Minute,
+			Singleton: true,
+			Run: func(ctx context.Context) error {
+				count, err := materializer.CatchUp(500)
+				if err != nil {
+					return fmt.Errorf("sequence step catch-up failed: %w", err)
+				}
+				if count > 0 {
+					logrus.Infof("Sequence step catch-up advanced %d contacts", count)
+				}
+				return nil
+			},
+		})
+
+		logrus.Info("Sequence step materializer started")
+	})
+}
@@ -0,0 +1,42 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanPreQueuedPrune(t *testing.T) {
+	base := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	at := func(days int) time.Time { return base.Add(time.Duration(days) * 24 * time.Hour) }
+	successors := map[string]string{"cold": "warm", "warm": "hot"}
+
+	rows := []preQueuedRow{
+		{ID: "c2", SequenceID: "cold", Phone: "601", DeviceID: "d1", ScheduledAt: at(1)},
+		{ID: "c1", SequenceID: "cold", Phone: "601", DeviceID: "d1", ScheduledAt: at(0)},
+		{ID: "w1", SequenceID: "warm", Phone: "601", DeviceID: "d1", ScheduledAt: at(2)},
+		{ID: "h1", SequenceID: "hot", Phone: "601", DeviceID: "d1", ScheduledAt: at(3)},
+		// Same phone on another device shares the one COLD position
+		{ID: "o1", SequenceID: "cold", Phone: "601", DeviceID: "d2", ScheduledAt: at(5)},
+		// but an unlinked sequence on that device is its own enrollment
+		{ID: "o2", SequenceID: "other", Phone: "601", DeviceID: "d2", ScheduledAt: at(6)},
+		// Already past COLD: WARM is the earliest row and HOT follows it
+		{ID: "w2", SequenceID: "warm", Phone: "602", DeviceID: "d1", ScheduledAt: at(0)},
+		{ID: "h2", SequenceID: "hot", Phone: "602", DeviceID: "d1", ScheduledAt: at(1)},
+		// Unlinked sequence stays
+		{ID: "x1", SequenceID: "other", Phone: "602", DeviceID: "d1", ScheduledAt: at(2)},
+	}
+
+	keep, drop := planPreQueuedPrune(rows, successors)
+
+	ids := func(rows []preQueuedRow) []string {
+		var out []string
+		for _, row := range rows {
+			out = append(out, row.ID)
+		}
+		return out
+	}
+	assert.ElementsMatch(t, []string{"c1", "o2", "w2", "x1"}, ids(keep))
+	assert.ElementsMatch(t, []string{"c2", "w1", "h1", "o1", "h2"}, ids(drop))
+}
@@ -1,10 +1,13 @@
 package usecase
 
 import (
+	"context"
 	"database/sql"
 	"sync"
 	"time"
 
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/scheduler"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/clock"
 	"github.com/sirupsen/logrus"
 )
 
@@ -35,11 +38,11 @@ func (s *SequenceTriggerProcessor) ProcessSequenceTriggers() error {
 	defer sequenceProcessorMutex.Unlock()
 	
 	// Also prevent processing too frequently
-	if time.Since(lastSequenceProcess) < 4*time.Minute {
+	if clock.Since(lastSequenceProcess) < 4*time.Minute {
 		logrus.Debug("Sequence processor ran recently, skipping")
 		return nil
 	}
-	lastSequenceProcess = time.Now()
+	lastSequenceProcess = clock.Now()
 	
 	start := time.Now()
 	
@@ -72,22 +75,21 @@ func (s *SequenceTriggerProcessor) ProcessSequenceTriggers() error {
 	return nil
 }
 
-// StartProcessing starts the sequence trigger processing
+// sequenceTriggerJob names the scheduled run of the processor
+const sequenceTriggerJob = "sequence-trigger-processor"
+
+// StartProcessing schedules the sequence trigger processing, running it once
+// straight away
 func (s *SequenceTriggerProcessor) StartProcessing() {
 	logrus.Info("Starting Direct Broadcast Processor (Sequences + Campaigns)...")
 	
-	// Process immediately on startup
-	if err := s.ProcessSequenceTriggers(); err != nil {
-		logrus.Errorf("Initial processing error: %v", err)
-	}
-	
-	// Then run periodically
-	ticker := time.NewTicker(5 * time.Minute)
-	defer ticker.Stop()
-	
-	for range ticker.C {
-		if err := s.ProcessSequenceTriggers(); err != nil {
-			logrus.Errorf("Processing error: %v", err)
-		}
-	}
+	scheduler.Default().MustRegister(scheduler.Job{
+		Name:       sequenceTriggerJob,
+		Interval:   5 * time.Minute,
+		Singleton:  true,
+		RunOnStart: true,
+		Run: func(ctx context.Context) error {
+			return s.ProcessSequenceTriggers()
+		},
+	})
 }
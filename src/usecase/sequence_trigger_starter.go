@@ -1,6 +1,7 @@
 package usecase
 
 import (
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/scheduler"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
 	"github.com/sirupsen/logrus"
 )
@@ -20,14 +21,14 @@ func StartSequenceTriggerProcessor() {
 	sequenceTriggerProcessor = NewSequenceTriggerProcessor(db)
 	
 	// Start processing
-	go sequenceTriggerProcessor.StartProcessing()
+	sequenceTriggerProcessor.StartProcessing()
 	
 	logrus.Info("Direct Broadcast sequence processor started successfully")
 }
 
 // StopSequenceTriggerProcessor stops the sequence trigger processor
 func StopSequenceTriggerProcessor() {
-	// Processing runs in a goroutine, no specific stop method needed
+	scheduler.Default().Unregister(sequenceTriggerJob)
 	logrus.Info("Sequence trigger processor stopped")
 }
 
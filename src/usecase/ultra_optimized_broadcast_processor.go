@@ -1,10 +1,15 @@
 package usecase
 
 import (
+	"context"
 	"fmt"
 	"time"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/database"
+	domainBroadcast "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/broadcast"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/broadcast"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/cluster"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/scheduler"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/repository"
 	"github.com/sirupsen/logrus"
 )
@@ -12,33 +17,37 @@ import (
 // UltraOptimizedBroadcastProcessor uses broadcast-specific worker pools
 type UltraOptimizedBroadcastProcessor struct {
 	manager *broadcast.UltraScaleBroadcastManager
-	ticker  *time.Ticker
 }
 
-// StartUltraOptimizedBroadcastProcessor starts the ultra-optimized processor
-func StartUltraOptimizedBroadcastProcessor() {
-	processor := &UltraOptimizedBroadcastProcessor{
+// NewUltraOptimizedBroadcastProcessor creates a processor that queues to the shared broadcast manager
+func NewUltraOptimizedBroadcastProcessor() *UltraOptimizedBroadcastProcessor {
+	return &UltraOptimizedBroadcastProcessor{
 		manager: broadcast.GetUltraScaleBroadcastManager(),
-		ticker:  time.NewTicker(5 * time.Second), // Check every 5 seconds
 	}
+}
+
+// StartUltraOptimizedBroadcastProcessor schedules the ultra-optimized processor
+func StartUltraOptimizedBroadcastProcessor() {
+	processor := NewUltraOptimizedBroadcastProcessor()
 	
 	logrus.Info("🚀 Ultra-optimized broadcast processor starting...")
-	logrus.Info("✅ UltraOptimizedBroadcastProcessor initialized successfully")
 	logrus.Info("⏰ Will check for messages every 5 seconds")
 	
-	// Process immediately on start
-	logrus.Info("🔄 Running initial message check...")
-	processor.processMessages()
-	
-	// Then process periodically
-	logrus.Info("♻️ Starting periodic processing loop...")
-	for range processor.ticker.C {
-		logrus.Debug("⏰ Ticker fired - checking for messages...")
-		processor.processMessages()
-	}
+	// Every replica queues the messages of the devices it serves
+	scheduler.Default().MustRegister(scheduler.Job{
+		Name:       "broadcast-processor",
+		Interval:   5 * time.Second,
+		RunOnStart: true,
+		Run: func(ctx context.Context) error {
+			processor.ProcessMessages()
+			return nil
+		},
+	})
 }
 
-func (p *UltraOptimizedBroadcastProcessor) processMessages() {
+// ProcessMessages claims the due pending messages of every device and queues
+// them to their broadcast pools
+func (p *UltraOptimizedBroadcastProcessor) ProcessMessages() {
 	startTime := time.Now()
 	logrus.Debug("📥 UltraOptimizedBroadcastProcessor.processMessages() started")
 	
@@ -138,8 +147,13 @@ func (p *UltraOptimizedBroadcastProcessor) processMessages() {
 	for i, deviceID := range devices {
 		logrus.Debugf("🔐 Processing device %d/%d: %s", i+1, len(devices), deviceID)
 		
+		// Another replica sends for devices it owns
+		if !cluster.Owns(deviceID) {
+			continue
+		}
+		
 		// Use GetPendingMessagesAndLock to atomically claim messages
-		messages, err := broadcastRepo.GetPendingMessagesAndLock(deviceID, 100)
+		messages, err := broadcastRepo.GetPendingMessagesAndLock(deviceID, config.Current().Worker.BatchSize)
 		if err != nil {
 			logrus.Errorf("❌ Failed to get pending messages for device %s: %v", deviceID, err)
 			continue
@@ -183,7 +197,7 @@ func (p *UltraOptimizedBroadcastProcessor) processMessages() {
 				db := database.GetDB()
 				db.Exec(`UPDATE campaigns SET status = 'processing', 
 						 updated_at = NOW() 
-						 WHERE id = ?`, *msg.CampaignID)
+						 WHERE id = ? AND status NOT IN ('paused', 'cancelled')`, *msg.CampaignID)
 				logrus.Infof("📊 Updated campaign %d status to 'processing'", *msg.CampaignID)
 			}
 			
@@ -214,10 +228,8 @@ func (p *UltraOptimizedBroadcastProcessor) processMessages() {
 				err = p.manager.QueueMessageToBroadcast(broadcastType, broadcastID, &msg)
 				if err != nil {
 					logrus.Errorf("❌ Failed to queue message %s: %v", msg.ID, err)
-					// Update to failed
-					db := database.GetDB()
-					db.Exec(`UPDATE broadcast_messages SET status = 'failed', error_message = ? WHERE id = ?`, 
-						err.Error(), msg.ID)
+					// Mark failed and record the dead letter
+					repository.GetBroadcastRepository().MarkMessageFailed(msg.ID, err, domainBroadcast.DeadLetterSourceSQL, 0)
 				} else {
 					messageCount++
 					logrus.Debugf("✅ Successfully queued message %s", msg.ID)